@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lhommenul/brique/core/models"
+)
+
+// AnnouncementDTO is the Data Transfer Object for help announcements
+type AnnouncementDTO struct {
+	ID           int64  `json:"id"`
+	Kind         string `json:"kind"`
+	Title        string `json:"title"`
+	Body         string `json:"body"`
+	AuthorName   string `json:"authorName"`
+	Hops         int64  `json:"hops"`
+	MaxHops      int64  `json:"maxHops"`
+	ReceivedFrom string `json:"receivedFrom"`
+	Own          bool   `json:"own"`
+	CreatedAt    string `json:"createdAt"`
+	ExpiresAt    string `json:"expiresAt"`
+}
+
+// GetAnnouncements returns the running help announcements, newest first. An
+// empty kind returns both offers and requests.
+func (a *App) GetAnnouncements(kind string) ([]AnnouncementDTO, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	announcements, err := a.gossipService.GetAnnouncements(a.ctx, models.AnnouncementKind(kind))
+	if err != nil {
+		return nil, err
+	}
+
+	dtos := make([]AnnouncementDTO, len(announcements))
+	for i, announcement := range announcements {
+		dtos[i] = announcementToDTO(announcement)
+	}
+	return dtos, nil
+}
+
+// PublishAnnouncement publishes a help announcement ("offer" or "request")
+// lasting ttlDays days and going through at most maxHops peers. Zero values
+// use the defaults.
+func (a *App) PublishAnnouncement(kind, title, body string, ttlDays, maxHops int64) (*AnnouncementDTO, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	announcement := models.Announcement{
+		Kind:    models.AnnouncementKind(kind),
+		Title:   title,
+		Body:    body,
+		MaxHops: maxHops,
+	}
+	if err := a.gossipService.PublishAnnouncement(a.ctx, &announcement, time.Duration(ttlDays)*24*time.Hour); err != nil {
+		a.events.Error("Erreur de publication", err.Error())
+		return nil, err
+	}
+
+	a.events.Success("Annonce publiée", fmt.Sprintf("'%s' sera transmise aux pairs", announcement.Title))
+	dto := announcementToDTO(announcement)
+	return &dto, nil
+}
+
+// WithdrawAnnouncement cancels an announcement of this instance
+func (a *App) WithdrawAnnouncement(id int64) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if err := a.gossipService.WithdrawAnnouncement(a.ctx, id); err != nil {
+		a.events.Error("Erreur", err.Error())
+		return err
+	}
+
+	a.events.Success("Annonce retirée", "Le retrait sera transmis aux pairs")
+	return nil
+}
+
+func announcementToDTO(announcement models.Announcement) AnnouncementDTO {
+	return AnnouncementDTO{
+		ID:           announcement.ID,
+		Kind:         string(announcement.Kind),
+		Title:        announcement.Title,
+		Body:         announcement.Body,
+		AuthorName:   announcement.AuthorName,
+		Hops:         announcement.Hops,
+		MaxHops:      announcement.MaxHops,
+		ReceivedFrom: announcement.ReceivedFrom,
+		Own:          announcement.Own,
+		CreatedAt:    announcement.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		ExpiresAt:    announcement.ExpiresAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
@@ -1,16 +1,16 @@
 package main
 
 import (
+	"context"
 	"encoding/base64"
-	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
-	"path/filepath"
 	"time"
 
 	"github.com/lhommenul/brique/core/models"
+	"github.com/lhommenul/brique/core/services"
 	"github.com/skip2/go-qrcode"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
@@ -28,6 +28,48 @@ type ItemDTO struct {
 	Notes        string  `json:"notes"`
 	CreatedAt    string  `json:"createdAt"`
 	UpdatedAt    string  `json:"updatedAt"`
+
+	Attributes     []AttributeDTO `json:"attributes"`
+	Tags           []string       `json:"tags"`
+	ProductModelID *int64         `json:"productModelId"`
+	Warranty       *WarrantyDTO   `json:"warranty"`
+	Loan           *LoanDTO       `json:"loan"`
+}
+
+// ProductModelDTO is the Data Transfer Object for product models
+type ProductModelDTO struct {
+	ID      int64                  `json:"id"`
+	Brand   string                 `json:"brand"`
+	Model   string                 `json:"model"`
+	Aliases []ProductModelAliasDTO `json:"aliases"`
+}
+
+// ProductModelAliasDTO is the Data Transfer Object for product model aliases
+type ProductModelAliasDTO struct {
+	Brand string `json:"brand"`
+	Model string `json:"model"`
+}
+
+// CategoryDTO is the Data Transfer Object for categories
+type CategoryDTO struct {
+	ID       int64    `json:"id"`
+	Name     string   `json:"name"`
+	ParentID *int64   `json:"parentId"`
+	Synonyms []string `json:"synonyms"`
+}
+
+// AttributeDTO is the Data Transfer Object for custom item attributes
+type AttributeDTO struct {
+	Key   string `json:"key"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// AttributeDefinitionDTO is the Data Transfer Object for template attributes
+type AttributeDefinitionDTO struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+	Type  string `json:"type"`
 }
 
 // AssetDTO is the Data Transfer Object for assets
@@ -40,6 +82,23 @@ type AssetDTO struct {
 	FileSize  int64  `json:"fileSize"`
 	FileHash  string `json:"fileHash"`
 	CreatedAt string `json:"createdAt"`
+
+	VersionLabel   string  `json:"versionLabel"`
+	ReleaseDate    *string `json:"releaseDate"`
+	SupersedesID   *int64  `json:"supersedesId"`
+	SupersededByID *int64  `json:"supersededById"`
+	IsCurrent      bool    `json:"isCurrent"`
+
+	ProductModelID *int64 `json:"productModelId"` // Set for documentation shared by a product model
+}
+
+// AssetTypeDTO is the Data Transfer Object for asset types
+type AssetTypeDTO struct {
+	Name            string   `json:"name"`
+	Label           string   `json:"label"`
+	MimePatterns    []string `json:"mimePatterns"`
+	CountsForHealth bool     `json:"countsForHealth"`
+	IsBuiltin       bool     `json:"isBuiltin"`
 }
 
 // ItemWithAssetsDTO is the Data Transfer Object for items with assets
@@ -51,6 +110,9 @@ type ItemWithAssetsDTO struct {
 
 // GetAllItems returns all items in the inventory
 func (a *App) GetAllItems() ([]ItemDTO, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
 	items, err := a.backpackService.GetAllItems(a.ctx)
 	if err != nil {
 		a.events.Error("Erreur de chargement", "Impossible de charger les items")
@@ -67,6 +129,9 @@ func (a *App) GetAllItems() ([]ItemDTO, error) {
 
 // GetItem returns a single item by ID
 func (a *App) GetItem(id int64) (*ItemDTO, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
 	item, err := a.backpackService.GetItem(a.ctx, id)
 	if err != nil {
 		return nil, err
@@ -78,27 +143,23 @@ func (a *App) GetItem(id int64) (*ItemDTO, error) {
 
 // GetItemWithAssets returns an item with all its assets and health status
 func (a *App) GetItemWithAssets(id int64) (*ItemWithAssetsDTO, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
 	itemWithAssets, err := a.backpackService.GetItemWithAssets(a.ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	itemDTO := itemToDTO(&itemWithAssets.Item)
-
-	assetDTOs := make([]AssetDTO, len(itemWithAssets.Assets))
-	for i, asset := range itemWithAssets.Assets {
-		assetDTOs[i] = assetToDTO(&asset)
-	}
-
-	return &ItemWithAssetsDTO{
-		Item:   itemDTO,
-		Assets: assetDTOs,
-		Health: string(itemWithAssets.Health),
-	}, nil
+	dto := itemWithAssetsToDTO(itemWithAssets)
+	return &dto, nil
 }
 
 // CreateItem creates a new item
 func (a *App) CreateItem(name, category, brand, model, serialNumber, notes string) (*ItemDTO, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
 	item := &models.Item{
 		Name:         name,
 		Category:     category,
@@ -120,6 +181,9 @@ func (a *App) CreateItem(name, category, brand, model, serialNumber, notes strin
 
 // UpdateItem updates an existing item
 func (a *App) UpdateItem(id int64, name, category, brand, model, serialNumber, notes string) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
 	item := &models.Item{
 		ID:           id,
 		Name:         name,
@@ -139,8 +203,155 @@ func (a *App) UpdateItem(id int64, name, category, brand, model, serialNumber, n
 	return nil
 }
 
+// SetItemAttributes replaces the custom attributes of an item
+func (a *App) SetItemAttributes(itemID int64, attributes []AttributeDTO) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	item, err := a.backpackService.GetItem(a.ctx, itemID)
+	if err != nil {
+		a.events.Error("Erreur de mise à jour", "Item introuvable")
+		return err
+	}
+
+	item.Attributes = make([]models.Attribute, len(attributes))
+	for i, attr := range attributes {
+		item.Attributes[i] = models.Attribute{
+			Key:   attr.Key,
+			Type:  models.AttributeType(attr.Type),
+			Value: attr.Value,
+		}
+	}
+
+	if err := a.backpackService.UpdateItem(a.ctx, item); err != nil {
+		a.events.Error("Attributs invalides", err.Error())
+		return err
+	}
+
+	a.events.Success("Attributs enregistrés", fmt.Sprintf("Les caractéristiques de '%s' ont été mises à jour", item.Name))
+	return nil
+}
+
+// GetAttributeTemplate returns the custom attributes suggested for a category
+func (a *App) GetAttributeTemplate(category string) ([]AttributeDefinitionDTO, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	template := a.backpackService.GetAttributeTemplate(category)
+
+	dtos := make([]AttributeDefinitionDTO, len(template.Attributes))
+	for i, def := range template.Attributes {
+		dtos[i] = AttributeDefinitionDTO{
+			Key:   def.Key,
+			Label: def.Label,
+			Type:  string(def.Type),
+		}
+	}
+
+	return dtos, nil
+}
+
+// SetItemTags replaces the tags of an item
+func (a *App) SetItemTags(itemID int64, tags []string) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	item, err := a.backpackService.GetItem(a.ctx, itemID)
+	if err != nil {
+		a.events.Error("Erreur de mise à jour", "Item introuvable")
+		return err
+	}
+
+	item.Tags = tags
+	if item.Tags == nil {
+		item.Tags = []string{}
+	}
+
+	if err := a.backpackService.UpdateItem(a.ctx, item); err != nil {
+		a.events.Error("Erreur de mise à jour", "Impossible d'enregistrer les tags")
+		return err
+	}
+
+	a.events.Success("Tags enregistrés", fmt.Sprintf("Les tags de '%s' ont été mis à jour", item.Name))
+	return nil
+}
+
+// GetCategories returns every category with its parent and synonyms
+func (a *App) GetCategories() ([]CategoryDTO, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	categories, err := a.backpackService.GetCategories(a.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	dtos := make([]CategoryDTO, len(categories))
+	for i, c := range categories {
+		dtos[i] = CategoryDTO{
+			ID:       c.ID,
+			Name:     c.Name,
+			ParentID: c.ParentID,
+			Synonyms: c.Synonyms,
+		}
+	}
+
+	return dtos, nil
+}
+
+// GetTags returns every tag in use
+func (a *App) GetTags() ([]string, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.backpackService.GetTags(a.ctx)
+}
+
+// GetProductModels returns the product model catalogue
+func (a *App) GetProductModels() ([]ProductModelDTO, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	productModels, err := a.backpackService.GetProductModels(a.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	dtos := make([]ProductModelDTO, len(productModels))
+	for i, m := range productModels {
+		dtos[i] = ProductModelDTO{
+			ID:      m.ID,
+			Brand:   m.Brand,
+			Model:   m.Model,
+			Aliases: make([]ProductModelAliasDTO, len(m.Aliases)),
+		}
+		for j, alias := range m.Aliases {
+			dtos[i].Aliases[j] = ProductModelAliasDTO{Brand: alias.Brand, Model: alias.Model}
+		}
+	}
+
+	return dtos, nil
+}
+
+// AddProductModelAlias adds another brand and model name to a product model
+func (a *App) AddProductModelAlias(productModelID int64, brand, model string) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if err := a.backpackService.AddProductModelAlias(a.ctx, productModelID, brand, model); err != nil {
+		a.events.Error("Erreur d'ajout", err.Error())
+		return err
+	}
+
+	a.events.Success("Alias ajouté", fmt.Sprintf("%s %s désigne désormais ce modèle", brand, model))
+	return nil
+}
+
 // DeleteItem deletes an item
 func (a *App) DeleteItem(id int64) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
 	// Get item name before deletion for notification
 	item, err := a.backpackService.GetItem(a.ctx, id)
 	if err != nil {
@@ -159,6 +370,9 @@ func (a *App) DeleteItem(id int64) error {
 
 // SearchItems searches for items
 func (a *App) SearchItems(query string) ([]ItemDTO, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
 	items, err := a.backpackService.SearchItems(a.ctx, query)
 	if err != nil {
 		return nil, err
@@ -174,6 +388,9 @@ func (a *App) SearchItems(query string) ([]ItemDTO, error) {
 
 // GetAssets returns all assets for an item
 func (a *App) GetAssets(itemID int64) ([]AssetDTO, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
 	assets, err := a.backpackService.GetItemAssets(a.ctx, itemID)
 	if err != nil {
 		return nil, err
@@ -189,6 +406,9 @@ func (a *App) GetAssets(itemID int64) ([]AssetDTO, error) {
 
 // AddAsset adds an asset to an item
 func (a *App) AddAsset(itemID int64, assetType, name, sourcePath string) (*AssetDTO, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
 	// Emit progress start
 	progressID := fmt.Sprintf("asset-upload-%d", itemID)
 	a.events.EmitProgress(ProgressData{
@@ -216,6 +436,9 @@ func (a *App) AddAsset(itemID int64, assetType, name, sourcePath string) (*Asset
 
 // DeleteAsset deletes an asset
 func (a *App) DeleteAsset(assetID int64) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
 	if err := a.backpackService.DeleteAsset(a.ctx, assetID); err != nil {
 		a.events.Error("Erreur de suppression", "Impossible de supprimer le fichier")
 		return err
@@ -225,6 +448,140 @@ func (a *App) DeleteAsset(assetID int64) error {
 	return nil
 }
 
+// AddAssetRevision adds a new revision that supersedes an asset
+func (a *App) AddAssetRevision(previousID int64, name, sourcePath, versionLabel, releaseDate string) (*AssetDTO, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	var date *time.Time
+	if releaseDate != "" {
+		parsed, err := time.Parse("2006-01-02", releaseDate)
+		if err != nil {
+			a.events.Error("Date invalide", "La date de publication doit être au format AAAA-MM-JJ")
+			return nil, err
+		}
+		date = &parsed
+	}
+
+	asset, err := a.backpackService.AddAssetRevision(a.ctx, previousID, name, sourcePath, versionLabel, date)
+	if err != nil {
+		a.events.Error("Erreur d'ajout", "Impossible d'ajouter la nouvelle révision")
+		return nil, err
+	}
+
+	a.events.Success("Révision ajoutée", fmt.Sprintf("'%s' remplace la révision précédente", asset.Name))
+	dto := assetToDTO(asset)
+	return &dto, nil
+}
+
+// ShareAsset shares an asset, with its revisions, with every item of the same product model
+func (a *App) ShareAsset(assetID int64) (*AssetDTO, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	asset, err := a.backpackService.ShareAsset(a.ctx, assetID)
+	if err != nil {
+		a.events.Error("Erreur de partage", err.Error())
+		return nil, err
+	}
+
+	a.events.Success("Fichier partagé", fmt.Sprintf("'%s' est disponible pour tous les items du même modèle", asset.Name))
+	dto := assetToDTO(asset)
+	return &dto, nil
+}
+
+// GetAssetHistory returns every revision of an asset, newest first
+func (a *App) GetAssetHistory(assetID int64) ([]AssetDTO, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	history, err := a.backpackService.GetAssetHistory(a.ctx, assetID)
+	if err != nil {
+		return nil, err
+	}
+
+	dtos := make([]AssetDTO, len(history))
+	for i, asset := range history {
+		dtos[i] = assetToDTO(&asset)
+	}
+
+	return dtos, nil
+}
+
+// SetCurrentRevision marks an asset as the current revision of its chain
+func (a *App) SetCurrentRevision(assetID int64) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if err := a.backpackService.SetCurrentRevision(a.ctx, assetID); err != nil {
+		a.events.Error("Erreur de mise à jour", "Impossible de changer la révision courante")
+		return err
+	}
+
+	a.events.Success("Révision courante", "La révision courante a été mise à jour")
+	return nil
+}
+
+// GetAssetTypes returns every registered asset type
+func (a *App) GetAssetTypes() ([]AssetTypeDTO, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	types, err := a.backpackService.GetAssetTypes(a.ctx)
+	if err != nil {
+		a.events.Error("Erreur de chargement", "Impossible de charger les types de fichiers")
+		return nil, err
+	}
+
+	dtos := make([]AssetTypeDTO, len(types))
+	for i, t := range types {
+		dtos[i] = AssetTypeDTO{
+			Name:            string(t.Name),
+			Label:           t.Label,
+			MimePatterns:    t.MimePatterns,
+			CountsForHealth: t.CountsForHealth,
+			IsBuiltin:       t.IsBuiltin,
+		}
+	}
+
+	return dtos, nil
+}
+
+// SaveAssetType creates or updates an asset type
+func (a *App) SaveAssetType(name, label string, mimePatterns []string, countsForHealth bool) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	def := &models.AssetTypeDefinition{
+		Name:            models.AssetType(name),
+		Label:           label,
+		MimePatterns:    mimePatterns,
+		CountsForHealth: countsForHealth,
+	}
+
+	if err := a.backpackService.SaveAssetType(a.ctx, def); err != nil {
+		a.events.Error("Erreur d'enregistrement", err.Error())
+		return err
+	}
+
+	a.events.Success("Type enregistré", fmt.Sprintf("Le type '%s' a été enregistré", label))
+	return nil
+}
+
+// DeleteAssetType deletes a user-defined asset type
+func (a *App) DeleteAssetType(name string) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if err := a.backpackService.DeleteAssetType(a.ctx, models.AssetType(name)); err != nil {
+		a.events.Error("Erreur de suppression", err.Error())
+		return err
+	}
+
+	a.events.Success("Type supprimé", fmt.Sprintf("Le type '%s' a été supprimé", name))
+	return nil
+}
+
 // QRCodeData represents the data structure embedded in the QR code
 type QRCodeData struct {
 	ID           int64  `json:"id"`
@@ -237,6 +594,9 @@ type QRCodeData struct {
 
 // GenerateQRCode generates a QR code for an item and returns it as base64 PNG
 func (a *App) GenerateQRCode(itemID int64) (string, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
 	// Get item details
 	item, err := a.backpackService.GetItem(a.ctx, itemID)
 	if err != nil {
@@ -274,60 +634,8 @@ func (a *App) GenerateQRCode(itemID int64) (string, error) {
 	return base64Str, nil
 }
 
-// ExportData represents the full export structure
-type ExportData struct {
-	ExportDate string                  `json:"exportDate"`
-	Version    string                  `json:"version"`
-	Items      []ItemWithAssetsDTO     `json:"items"`
-	Stats      map[string]interface{}  `json:"stats"`
-}
-
 // ExportToJSON exports all inventory data to a JSON file
 func (a *App) ExportToJSON() error {
-	// Get all items with their assets
-	items, err := a.backpackService.GetAllItems(a.ctx)
-	if err != nil {
-		a.events.Error("Erreur d'export", "Impossible de charger les items")
-		return err
-	}
-
-	exportItems := make([]ItemWithAssetsDTO, 0, len(items))
-	for _, item := range items {
-		itemWithAssets, err := a.backpackService.GetItemWithAssets(a.ctx, item.ID)
-		if err != nil {
-			continue
-		}
-
-		itemDTO := itemToDTO(&itemWithAssets.Item)
-		assetDTOs := make([]AssetDTO, len(itemWithAssets.Assets))
-		for i, asset := range itemWithAssets.Assets {
-			assetDTOs[i] = assetToDTO(&asset)
-		}
-
-		exportItems = append(exportItems, ItemWithAssetsDTO{
-			Item:   itemDTO,
-			Assets: assetDTOs,
-			Health: string(itemWithAssets.Health),
-		})
-	}
-
-	// Create export data
-	exportData := ExportData{
-		ExportDate: time.Now().Format(time.RFC3339),
-		Version:    "1.0",
-		Items:      exportItems,
-		Stats: map[string]interface{}{
-			"totalItems": len(items),
-		},
-	}
-
-	// Convert to JSON
-	jsonData, err := json.MarshalIndent(exportData, "", "  ")
-	if err != nil {
-		a.events.Error("Erreur d'export", "Impossible de générer le JSON")
-		return err
-	}
-
 	// Show save dialog
 	defaultFilename := fmt.Sprintf("brique-export-%s.json", time.Now().Format("2006-01-02"))
 	savePath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
@@ -343,13 +651,15 @@ func (a *App) ExportToJSON() error {
 		return fmt.Errorf("export cancelled")
 	}
 
-	// Write to file
-	if err := os.WriteFile(savePath, jsonData, 0644); err != nil {
-		a.events.Error("Erreur d'export", "Impossible d'écrire le fichier")
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	count, err := a.exportToFile(savePath, a.backpackService.ExportJSON)
+	if err != nil {
 		return err
 	}
 
-	a.events.Success("Export réussi", fmt.Sprintf("Inventaire exporté: %d items", len(items)))
+	a.events.Success("Export réussi", fmt.Sprintf("Inventaire exporté: %d items", count))
 	return nil
 }
 
@@ -368,54 +678,23 @@ func (a *App) ImportFromJSON() error {
 		return fmt.Errorf("import cancelled")
 	}
 
-	// Read file
-	jsonData, err := os.ReadFile(filepath)
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	file, err := os.Open(filepath)
 	if err != nil {
 		a.events.Error("Erreur d'import", "Impossible de lire le fichier")
 		return err
 	}
+	defer file.Close()
 
-	// Parse JSON
-	var exportData ExportData
-	if err := json.Unmarshal(jsonData, &exportData); err != nil {
-		a.events.Error("Erreur d'import", "Format JSON invalide")
+	// Items already in the inventory are skipped
+	imported, skipped, err := a.backpackService.ImportJSON(a.ctx, file)
+	if err != nil {
+		a.events.Error("Erreur d'import", "L'import a échoué, aucun item n'a été importé")
 		return err
 	}
 
-	// Import items
-	imported := 0
-	skipped := 0
-	for _, itemDTO := range exportData.Items {
-		// Check if item already exists (by brand+model+serial)
-		existing, _ := a.backpackService.SearchItems(a.ctx, itemDTO.Item.SerialNumber)
-		if len(existing) > 0 {
-			// Skip if serial number already exists
-			skipped++
-			continue
-		}
-
-		// Create item
-		item := &models.Item{
-			Name:         itemDTO.Item.Name,
-			Category:     itemDTO.Item.Category,
-			Brand:        itemDTO.Item.Brand,
-			Model:        itemDTO.Item.Model,
-			SerialNumber: itemDTO.Item.SerialNumber,
-			PhotoPath:    itemDTO.Item.PhotoPath,
-			Notes:        itemDTO.Item.Notes,
-		}
-
-		if err := a.backpackService.CreateItem(a.ctx, item); err != nil {
-			skipped++
-			continue
-		}
-
-		imported++
-
-		// Note: Assets are not imported because they reference file paths
-		// that may not exist on this machine
-	}
-
 	message := fmt.Sprintf("Import réussi: %d items importés, %d ignorés", imported, skipped)
 	if imported > 0 {
 		a.events.Success("Import réussi", message)
@@ -428,13 +707,6 @@ func (a *App) ImportFromJSON() error {
 
 // ExportToCSV exports inventory data to a CSV file
 func (a *App) ExportToCSV() error {
-	// Get all items
-	items, err := a.backpackService.GetAllItems(a.ctx)
-	if err != nil {
-		a.events.Error("Erreur d'export", "Impossible de charger les items")
-		return err
-	}
-
 	// Show save dialog
 	defaultFilename := fmt.Sprintf("brique-export-%s.csv", time.Now().Format("2006-01-02"))
 	savePath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
@@ -450,50 +722,36 @@ func (a *App) ExportToCSV() error {
 		return fmt.Errorf("export cancelled")
 	}
 
-	// Create file
-	file, err := os.Create(savePath)
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	count, err := a.exportToFile(savePath, a.backpackService.ExportCSV)
 	if err != nil {
-		a.events.Error("Erreur d'export", "Impossible de créer le fichier")
 		return err
 	}
-	defer file.Close()
 
-	// Create CSV writer
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	a.events.Success("Export réussi", fmt.Sprintf("Inventaire exporté: %d items", count))
+	return nil
+}
 
-	// Write header
-	header := []string{"ID", "Nom", "Catégorie", "Marque", "Modèle", "Numéro de série", "Date d'achat", "Notes", "Date de création"}
-	if err := writer.Write(header); err != nil {
-		return err
+// exportToFile writes an export to the given path
+func (a *App) exportToFile(path string, export func(context.Context, io.Writer) (int, error)) (int, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		a.events.Error("Erreur d'export", "Impossible de créer le fichier")
+		return 0, err
 	}
 
-	// Write data
-	for _, item := range items {
-		purchaseDate := ""
-		if item.PurchaseDate != nil {
-			purchaseDate = item.PurchaseDate.Format("2006-01-02")
-		}
-
-		row := []string{
-			fmt.Sprintf("%d", item.ID),
-			item.Name,
-			item.Category,
-			item.Brand,
-			item.Model,
-			item.SerialNumber,
-			purchaseDate,
-			item.Notes,
-			item.CreatedAt.Format("2006-01-02 15:04:05"),
-		}
-
-		if err := writer.Write(row); err != nil {
-			return err
-		}
+	count, err := export(a.ctx, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		a.events.Error("Erreur d'export", "Impossible d'écrire le fichier")
+		return 0, err
 	}
 
-	a.events.Success("Export réussi", fmt.Sprintf("Inventaire exporté: %d items", len(items)))
-	return nil
+	return count, nil
 }
 
 // PeerDTO is the Data Transfer Object for peers
@@ -529,6 +787,9 @@ type SyncLogDTO struct {
 
 // GetPeers returns all discovered peers
 func (a *App) GetPeers() ([]PeerDTO, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
 	peers, err := a.gossipService.GetPeers(a.ctx)
 	if err != nil {
 		a.events.Error("Erreur", "Impossible de charger les pairs")
@@ -545,7 +806,10 @@ func (a *App) GetPeers() ([]PeerDTO, error) {
 
 // SyncWithPeer synchronizes with a specific peer
 func (a *App) SyncWithPeer(peerID string) (*SyncResultDTO, error) {
-	result, err := a.SyncWithPeerHTTP(peerID)
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	result, err := a.syncWithPeer(peerID)
 	if err != nil {
 		return nil, err
 	}
@@ -560,6 +824,9 @@ func (a *App) SyncWithPeer(peerID string) (*SyncResultDTO, error) {
 
 // SetPeerTrusted sets whether a peer is trusted
 func (a *App) SetPeerTrusted(peerID string, trusted bool) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
 	if err := a.gossipService.SetPeerTrust(a.ctx, peerID, trusted); err != nil {
 		a.events.Error("Erreur", "Impossible de modifier la confiance du pair")
 		return err
@@ -576,6 +843,9 @@ func (a *App) SetPeerTrusted(peerID string, trusted bool) error {
 
 // AddPeer adds a peer manually
 func (a *App) AddPeer(name, address string, trusted bool) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
 	// Generate a unique ID for the peer
 	peerID := fmt.Sprintf("%s.manual", address)
 
@@ -598,6 +868,9 @@ func (a *App) AddPeer(name, address string, trusted bool) error {
 
 // RemovePeer removes a peer from the list
 func (a *App) RemovePeer(peerID string) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
 	if err := a.gossipService.RemovePeer(a.ctx, peerID); err != nil {
 		a.events.Error("Erreur", "Impossible de supprimer le pair")
 		return err
@@ -609,6 +882,9 @@ func (a *App) RemovePeer(peerID string) error {
 
 // GetSyncHistory returns recent synchronization history
 func (a *App) GetSyncHistory(limit int) ([]SyncLogDTO, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
 	logs, err := a.gossipService.GetRecentSyncHistory(a.ctx, limit)
 	if err != nil {
 		a.events.Error("Erreur", "Impossible de charger l'historique")
@@ -662,6 +938,9 @@ func peerToDTO(peer *models.Peer) PeerDTO {
 
 // CreateBackup creates a backup of the database and assets directory
 func (a *App) CreateBackup() error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
 	a.events.EmitProgress(ProgressData{
 		ID:        "backup",
 		Operation: "Création du backup",
@@ -669,133 +948,18 @@ func (a *App) CreateBackup() error {
 		Total:     100,
 	})
 
-	// Get config paths
-	dataDir := a.cfg.DataDir
-	dbPath := filepath.Join(dataDir, "brique.db")
-	assetsDir := filepath.Join(dataDir, "assets")
-
-	// Create backups directory
-	backupsDir := filepath.Join(dataDir, "backups")
-	if err := os.MkdirAll(backupsDir, 0755); err != nil {
-		a.events.EmitProgressComplete("backup")
-		a.events.Error("Erreur de backup", "Impossible de créer le dossier de backup")
-		return err
-	}
-
-	// Create backup directory with timestamp
-	timestamp := time.Now().Format("2006-01-02_15-04-05")
-	backupDir := filepath.Join(backupsDir, fmt.Sprintf("backup_%s", timestamp))
-	if err := os.MkdirAll(backupDir, 0755); err != nil {
-		a.events.EmitProgressComplete("backup")
-		a.events.Error("Erreur de backup", "Impossible de créer le dossier de backup")
-		return err
-	}
-
-	// Update progress
-	a.events.EmitProgress(ProgressData{
-		ID:        "backup",
-		Operation: "Copie de la base de données",
-		Current:   30,
-		Total:     100,
-	})
-
-	// Backup database
-	dbBackupPath := filepath.Join(backupDir, "brique.db")
-	if err := copyFile(dbPath, dbBackupPath); err != nil {
-		a.events.EmitProgressComplete("backup")
-		a.events.Error("Erreur de backup", "Impossible de copier la base de données")
-		return err
-	}
-
-	// Update progress
-	a.events.EmitProgress(ProgressData{
-		ID:        "backup",
-		Operation: "Copie des assets",
-		Current:   60,
-		Total:     100,
-	})
-
-	// Backup assets directory
-	assetsBackupDir := filepath.Join(backupDir, "assets")
-	if err := copyDir(assetsDir, assetsBackupDir); err != nil {
-		// Not a critical error if assets dir doesn't exist or is empty
-		a.events.Warning("Backup partiel", "Assets non copiés")
-	}
-
-	// Create a metadata file
-	metadata := map[string]string{
-		"timestamp": timestamp,
-		"version":   "1.0",
-	}
-	metadataJSON, _ := json.MarshalIndent(metadata, "", "  ")
-	os.WriteFile(filepath.Join(backupDir, "metadata.json"), metadataJSON, 0644)
-
-	// Complete progress
+	backup, err := a.backpackService.CreateBackup(a.ctx, a.cfg.BackupsDir)
 	a.events.EmitProgressComplete("backup")
-	a.events.Success("Backup créé", fmt.Sprintf("Backup enregistré: %s", backupDir))
-
-	return nil
-}
-
-// Helper function to copy a file
-func copyFile(src, dst string) error {
-	sourceFile, err := os.Open(src)
-	if err != nil {
-		return err
-	}
-	defer sourceFile.Close()
-
-	destFile, err := os.Create(dst)
 	if err != nil {
+		a.events.Error("Erreur de backup", err.Error())
 		return err
 	}
-	defer destFile.Close()
 
-	_, err = io.Copy(destFile, sourceFile)
-	return err
-}
-
-// Helper function to copy a directory recursively
-func copyDir(src, dst string) error {
-	// Check if source exists
-	srcInfo, err := os.Stat(src)
-	if err != nil {
-		return err
-	}
-
-	if !srcInfo.IsDir() {
-		return fmt.Errorf("source is not a directory")
-	}
-
-	// Create destination directory
-	if err := os.MkdirAll(dst, srcInfo.Mode()); err != nil {
-		return err
-	}
-
-	// Read source directory
-	entries, err := os.ReadDir(src)
-	if err != nil {
-		return err
-	}
-
-	// Copy each entry
-	for _, entry := range entries {
-		srcPath := filepath.Join(src, entry.Name())
-		dstPath := filepath.Join(dst, entry.Name())
-
-		if entry.IsDir() {
-			// Recursively copy subdirectory
-			if err := copyDir(srcPath, dstPath); err != nil {
-				return err
-			}
-		} else {
-			// Copy file
-			if err := copyFile(srcPath, dstPath); err != nil {
-				return err
-			}
-		}
+	if _, err := services.PruneBackups(a.ctx, a.cfg.BackupsDir, a.cfg.BackupRetention); err != nil {
+		a.events.Warning("Backup créé", "Les anciens backups n'ont pas pu être supprimés")
 	}
 
+	a.events.Success("Backup créé", fmt.Sprintf("Backup enregistré: %s", backup.Path))
 	return nil
 }
 
@@ -820,11 +984,47 @@ func itemToDTO(item *models.Item) ItemDTO {
 		dto.PurchaseDate = &dateStr
 	}
 
+	dto.Attributes = make([]AttributeDTO, len(item.Attributes))
+	for i, attr := range item.Attributes {
+		dto.Attributes[i] = AttributeDTO{
+			Key:   attr.Key,
+			Type:  string(attr.Type),
+			Value: attr.Value,
+		}
+	}
+
+	dto.ProductModelID = item.ProductModelID
+	if item.Warranty != nil {
+		warranty := warrantyToDTO(item.Warranty)
+		dto.Warranty = &warranty
+	}
+	if item.Loan != nil {
+		loan := loanToDTO(*item.Loan)
+		dto.Loan = &loan
+	}
+	dto.Tags = item.Tags
+	if dto.Tags == nil {
+		dto.Tags = []string{}
+	}
+
 	return dto
 }
 
+func itemWithAssetsToDTO(itemWithAssets *models.ItemWithAssets) ItemWithAssetsDTO {
+	assetDTOs := make([]AssetDTO, len(itemWithAssets.Assets))
+	for i, asset := range itemWithAssets.Assets {
+		assetDTOs[i] = assetToDTO(&asset)
+	}
+
+	return ItemWithAssetsDTO{
+		Item:   itemToDTO(&itemWithAssets.Item),
+		Assets: assetDTOs,
+		Health: string(itemWithAssets.Health),
+	}
+}
+
 func assetToDTO(asset *models.Asset) AssetDTO {
-	return AssetDTO{
+	dto := AssetDTO{
 		ID:        asset.ID,
 		ItemID:    asset.ItemID,
 		Type:      string(asset.Type),
@@ -833,5 +1033,19 @@ func assetToDTO(asset *models.Asset) AssetDTO {
 		FileSize:  asset.FileSize,
 		FileHash:  asset.FileHash,
 		CreatedAt: asset.CreatedAt.Format("2006-01-02T15:04:05Z"),
+
+		VersionLabel:   asset.VersionLabel,
+		SupersedesID:   asset.SupersedesID,
+		SupersededByID: asset.SupersededByID,
+		IsCurrent:      asset.IsCurrent,
+
+		ProductModelID: asset.ProductModelID,
 	}
+
+	if asset.ReleaseDate != nil {
+		dateStr := asset.ReleaseDate.Format("2006-01-02")
+		dto.ReleaseDate = &dateStr
+	}
+
+	return dto
 }
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/lhommenul/brique/core/models"
+	"github.com/lhommenul/brique/core/services"
+)
+
+// backpackClient is what the commands need to manage the inventory. It is
+// served either by the local services or by a brique-server (--remote).
+type backpackClient interface {
+	// Items
+	CreateItem(ctx context.Context, item *models.Item) error
+	UpdateItem(ctx context.Context, item *models.Item) error
+	DeleteItem(ctx context.Context, id int64) error
+	GetItem(ctx context.Context, id int64) (*models.Item, error)
+	GetItemWithAssets(ctx context.Context, itemID int64) (*models.ItemWithAssets, error)
+	FilterItems(ctx context.Context, filter models.ItemFilter) ([]models.Item, error)
+	GetAttributeTemplate(ctx context.Context, category string) (models.AttributeTemplate, error)
+	GetAttributeTemplates(ctx context.Context) ([]models.AttributeTemplate, error)
+	GetTags(ctx context.Context) ([]string, error)
+
+	// Export, import and backup
+	ExportJSON(ctx context.Context, w io.Writer) (int, error)
+	ExportCSV(ctx context.Context, w io.Writer) (int, error)
+	ImportJSON(ctx context.Context, r io.Reader) (imported int, skipped int, err error)
+	ImportCSV(ctx context.Context, r io.Reader, options models.CSVImportOptions) (*models.ImportReport, error)
+
+	// Encryption at rest
+	EncryptionStatus(ctx context.Context) (*models.EncryptionStatus, error)
+
+	// Backups, in the backups directory of the configuration
+	CreateBackup(ctx context.Context) (*models.Backup, error)
+	ListBackups(ctx context.Context) ([]models.Backup, error)
+	VerifyBackup(ctx context.Context, name string) (*models.Backup, error)
+	PruneBackups(ctx context.Context) ([]models.Backup, error)
+	BackupStatus(ctx context.Context) (*models.BackupScheduleStatus, error)
+
+	// Assets and photos
+	AddAsset(ctx context.Context, itemID int64, assetType models.AssetType, name string, sourcePath string) (*models.Asset, error)
+	AddProductModelAsset(ctx context.Context, productModelID int64, assetType models.AssetType, name string, sourcePath string) (*models.Asset, error)
+	AddAssetRevision(ctx context.Context, previousID int64, name string, sourcePath string, versionLabel string, releaseDate *time.Time) (*models.Asset, error)
+	DeleteAsset(ctx context.Context, assetID int64) error
+	GetItemAssets(ctx context.Context, itemID int64) ([]models.Asset, error)
+	GetAssetHistory(ctx context.Context, assetID int64) ([]models.Asset, error)
+	SetCurrentRevision(ctx context.Context, assetID int64) error
+	SetAssetVersion(ctx context.Context, assetID int64, versionLabel string, releaseDate *time.Time) error
+	ShareAsset(ctx context.Context, assetID int64) (*models.Asset, error)
+	SaveAsset(ctx context.Context, assetID int64, w io.Writer) (*models.Asset, error)
+	AddPhoto(ctx context.Context, itemID int64, sourcePath string) (*models.Asset, error)
+	GetItemPhotos(ctx context.Context, itemID int64) ([]models.Asset, error)
+	ImportLegacyPhotos(ctx context.Context) (int, error)
+
+	// Asset types
+	GetAssetTypes(ctx context.Context) ([]models.AssetTypeDefinition, error)
+	SaveAssetType(ctx context.Context, def *models.AssetTypeDefinition) error
+	DeleteAssetType(ctx context.Context, name models.AssetType) error
+
+	// Categories
+	GetCategories(ctx context.Context) ([]models.Category, error)
+	CreateCategory(ctx context.Context, name, parent string) (*models.Category, error)
+	RenameCategory(ctx context.Context, name, newName string) error
+	SetCategoryParent(ctx context.Context, name, parent string) error
+	DeleteCategory(ctx context.Context, name string) error
+	MergeCategory(ctx context.Context, from, into string) error
+	AddCategorySynonym(ctx context.Context, name, synonym string) error
+	RemoveCategorySynonym(ctx context.Context, synonym string) error
+
+	// Product models
+	GetProductModels(ctx context.Context) ([]models.ProductModel, error)
+	GetProductModel(ctx context.Context, id int64) (*models.ProductModelWithAssets, error)
+	AddProductModelAlias(ctx context.Context, id int64, brand, model string) error
+	RemoveProductModelAlias(ctx context.Context, brand, model string) error
+
+	// Repairs
+	AddRepairEvent(ctx context.Context, event *models.RepairEvent) error
+	GetItemRepairs(ctx context.Context, itemID int64) ([]models.RepairEvent, error)
+	DeleteRepairEvent(ctx context.Context, id int64) error
+
+	// Maintenance
+	AddMaintenanceSchedule(ctx context.Context, schedule *models.MaintenanceSchedule) error
+	GetItemMaintenance(ctx context.Context, itemID int64) ([]models.MaintenanceTask, error)
+	GetDueMaintenance(ctx context.Context, within time.Duration) ([]models.MaintenanceTask, error)
+	CompleteMaintenance(ctx context.Context, scheduleID int64, doneAt time.Time) (*models.MaintenanceTask, error)
+	DeleteMaintenanceSchedule(ctx context.Context, scheduleID int64) error
+	RecordUsage(ctx context.Context, itemID int64, unit string, value int64) error
+
+	// Warranties
+	SetItemWarranty(ctx context.Context, itemID int64, warranty *models.Warranty) error
+	GetExpiringWarranties(ctx context.Context, within time.Duration) ([]models.Item, error)
+
+	// Loans
+	LendItem(ctx context.Context, loan *models.Loan) error
+	ReturnLoan(ctx context.Context, id int64, returnedAt time.Time) (*models.Loan, error)
+	GetLoans(ctx context.Context, includeReturned bool) ([]models.Loan, error)
+	GetOverdueLoans(ctx context.Context) ([]models.Loan, error)
+
+	// Spare parts
+	CreatePart(ctx context.Context, part *models.Part) error
+	GetPart(ctx context.Context, id int64) (*models.Part, error)
+	GetParts(ctx context.Context) ([]models.Part, error)
+	GetLowStockParts(ctx context.Context) ([]models.Part, error)
+	GetItemParts(ctx context.Context, itemID int64) ([]models.Part, error)
+	AdjustPartStock(ctx context.Context, id int64, delta int64) (*models.Part, error)
+	DeletePart(ctx context.Context, id int64) error
+
+	// Documentation health
+	HealthReport(ctx context.Context) ([]models.HealthReport, error)
+	GetHealthRules(ctx context.Context) ([]models.HealthRule, error)
+}
+
+// gossipClient is what the commands need to work with peers
+type gossipClient interface {
+	GetPeers(ctx context.Context) ([]models.Peer, error)
+	AddPeer(ctx context.Context, peer *models.Peer) error
+	RemovePeer(ctx context.Context, peerID string) error
+	SetPeerTrust(ctx context.Context, peerID string, trusted bool) error
+	SyncPeer(ctx context.Context, peerID string) (*models.SyncResult, error)
+	SearchPeers(ctx context.Context, query models.SearchQuery) ([]models.SearchResult, error)
+	ImportPeerItem(ctx context.Context, peerID string, itemID int64) (*models.ItemWithAssets, error)
+
+	GetAnnouncements(ctx context.Context, kind models.AnnouncementKind) ([]models.Announcement, error)
+	PublishAnnouncement(ctx context.Context, announcement *models.Announcement, ttl time.Duration) error
+	WithdrawAnnouncement(ctx context.Context, id int64) error
+}
+
+// localBackpack serves the commands from the local database
+type localBackpack struct {
+	*services.BackpackService
+	backupsDir string
+	retention  models.BackupRetention
+	scheduler  *services.BackupScheduler // Not running: for the status of the destinations
+}
+
+func (l localBackpack) CreateBackup(ctx context.Context) (*models.Backup, error) {
+	return l.BackpackService.CreateBackup(ctx, l.backupsDir)
+}
+
+func (l localBackpack) ListBackups(ctx context.Context) ([]models.Backup, error) {
+	return services.ListBackups(l.backupsDir)
+}
+
+func (l localBackpack) VerifyBackup(ctx context.Context, name string) (*models.Backup, error) {
+	return services.VerifyBackup(ctx, l.backupsDir, name)
+}
+
+func (l localBackpack) PruneBackups(ctx context.Context) ([]models.Backup, error) {
+	return services.PruneBackups(ctx, l.backupsDir, l.retention)
+}
+
+func (l localBackpack) BackupStatus(ctx context.Context) (*models.BackupScheduleStatus, error) {
+	status := l.scheduler.Status()
+	return &status, nil
+}
+
+func (l localBackpack) GetAttributeTemplate(ctx context.Context, category string) (models.AttributeTemplate, error) {
+	return l.BackpackService.GetAttributeTemplate(category), nil
+}
+
+func (l localBackpack) GetAttributeTemplates(ctx context.Context) ([]models.AttributeTemplate, error) {
+	return l.BackpackService.GetAttributeTemplates(), nil
+}
+
+func (l localBackpack) GetHealthRules(ctx context.Context) ([]models.HealthRule, error) {
+	return l.BackpackService.GetHealthRules(), nil
+}
+
+// localGossip works with peers from the local database
+type localGossip struct {
+	*services.GossipService
+	backpack *services.BackpackService
+}
+
+func (l localGossip) ImportPeerItem(ctx context.Context, peerID string, itemID int64) (*models.ItemWithAssets, error) {
+	return l.GossipService.ImportPeerItem(ctx, l.backpack, peerID, itemID)
+}
+
+func (l localGossip) SyncPeer(ctx context.Context, peerID string) (*models.SyncResult, error) {
+	peers, err := l.GetPeers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, peer := range peers {
+		if peer.ID == peerID {
+			return l.GossipService.SyncPeer(ctx, l.backpack, &peer)
+		}
+	}
+	return nil, fmt.Errorf("peer not found: %s", peerID)
+}
@@ -3,12 +3,17 @@ package main
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"math"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/lhommenul/brique/core/db"
 	"github.com/lhommenul/brique/core/models"
@@ -20,9 +25,18 @@ import (
 var (
 	cfg             *config.Config
 	database        *db.Database
-	backpackService *services.BackpackService
-	gossipService   *services.GossipService
+	backpackService backpackClient
+	gossipService   gossipClient
 	logger          *slog.Logger
+
+	// remoteURL is the brique-server the commands go through, if any
+	remoteURL string
+
+	// profileName is the inventory given by --profile, if any
+	profileName string
+
+	// passphrase unlocked the local data encrypted at rest, if any
+	passphrase string
 )
 
 func main() {
@@ -32,12 +46,24 @@ func main() {
 		Long: `Brique est une application offline-first pour gérer votre inventaire
 d'objets et leurs documentations de réparation.`,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutputFormat(); err != nil {
+				return err
+			}
+			// Arguments are valid past this point: failures don't need the usage
+			cmd.SilenceUsage = true
 			return initApp()
 		},
 		PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
 			return closeApp()
 		},
+		SilenceErrors: true,
 	}
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", outputTable, "Output format: table, json or yaml")
+	rootCmd.PersistentFlags().StringVar(&remoteURL, "remote", "", "Work on the brique-server at this URL (e.g. http://host:8080) instead of the local data")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "Work on this profile's inventory instead of the configured one")
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return usageError{err}
+	})
 
 	// Item commands
 	itemCmd := &cobra.Command{
@@ -50,12 +76,18 @@ d'objets et leurs documentations de réparation.`,
 		Short: "Add a new item to the inventory",
 		RunE:  runItemAdd,
 	}
+	addItemFieldFlags(itemAddCmd)
+	itemAddCmd.Flags().StringArrayP("attr", "a", nil, "Custom attribute as key=value or key:type=value (repeatable)")
+	itemAddCmd.Flags().StringArray("tag", nil, "Tag to put on the item (repeatable)")
 
 	itemListCmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all items in the inventory",
 		RunE:  runItemList,
 	}
+	itemListCmd.Flags().StringArrayP("attr", "a", nil, "Only list items matching an attribute filter, e.g. voltage>=220 (repeatable)")
+	itemListCmd.Flags().StringP("category", "c", "", "Only list items of a category or one of its subcategories")
+	itemListCmd.Flags().StringArray("tag", nil, "Only list items carrying a tag (repeatable)")
 
 	itemGetCmd := &cobra.Command{
 		Use:   "get <id>",
@@ -70,6 +102,10 @@ d'objets et leurs documentations de réparation.`,
 		Args:  cobra.ExactArgs(1),
 		RunE:  runItemUpdate,
 	}
+	addItemFieldFlags(itemUpdateCmd)
+	itemUpdateCmd.Flags().StringArrayP("attr", "a", nil, "Set a custom attribute as key=value or key:type=value, empty value removes it (repeatable)")
+	itemUpdateCmd.Flags().StringArray("tag", nil, "Add a tag (repeatable)")
+	itemUpdateCmd.Flags().StringArray("untag", nil, "Remove a tag (repeatable)")
 
 	itemDeleteCmd := &cobra.Command{
 		Use:   "delete <id>",
@@ -77,15 +113,24 @@ d'objets et leurs documentations de réparation.`,
 		Args:  cobra.ExactArgs(1),
 		RunE:  runItemDelete,
 	}
+	itemDeleteCmd.Flags().BoolP("yes", "y", false, "Delete without asking for confirmation")
 
 	itemSearchCmd := &cobra.Command{
 		Use:   "search <query>",
-		Short: "Search items by name, brand, or category",
+		Short: "Search items by name, brand, category or attribute value",
 		Args:  cobra.ExactArgs(1),
 		RunE:  runItemSearch,
 	}
+	itemSearchCmd.Flags().StringP("category", "c", "", "Only keep items of a category or one of its subcategories")
+	itemSearchCmd.Flags().StringArray("tag", nil, "Only keep items carrying a tag (repeatable)")
+
+	itemTemplatesCmd := &cobra.Command{
+		Use:   "templates",
+		Short: "Show the custom attributes suggested per category",
+		RunE:  runItemTemplates,
+	}
 
-	itemCmd.AddCommand(itemAddCmd, itemListCmd, itemGetCmd, itemUpdateCmd, itemDeleteCmd, itemSearchCmd)
+	itemCmd.AddCommand(itemAddCmd, itemListCmd, itemGetCmd, itemUpdateCmd, itemDeleteCmd, itemSearchCmd, itemTemplatesCmd)
 
 	// Asset commands
 	assetCmd := &cobra.Command{
@@ -99,8 +144,11 @@ d'objets et leurs documentations de réparation.`,
 		Args:  cobra.ExactArgs(2),
 		RunE:  runAssetAdd,
 	}
-	assetAddCmd.Flags().StringP("type", "t", "manual", "Asset type (manual, service_manual, exploded_view, stl, firmware, driver, schematic, other)")
+	assetAddCmd.Flags().StringP("type", "t", "manual", "Asset type (see 'brique asset-type list')")
 	assetAddCmd.Flags().StringP("name", "n", "", "Asset name (defaults to filename)")
+	assetAddCmd.Flags().String("version-label", "", "Version of the document (e.g. v1.2)")
+	assetAddCmd.Flags().String("release-date", "", "Release date of this version (YYYY-MM-DD)")
+	assetAddCmd.Flags().Bool("shared", false, "Share the asset with every item of the same product model")
 
 	assetListCmd := &cobra.Command{
 		Use:   "list <item-id>",
@@ -115,658 +163,3704 @@ d'objets et leurs documentations de réparation.`,
 		Args:  cobra.ExactArgs(1),
 		RunE:  runAssetDelete,
 	}
+	assetDeleteCmd.Flags().BoolP("yes", "y", false, "Delete without asking for confirmation")
 
-	assetCmd.AddCommand(assetAddCmd, assetListCmd, assetDeleteCmd)
+	assetReviseCmd := &cobra.Command{
+		Use:   "revise <asset-id> <file>",
+		Short: "Add a new revision that supersedes an asset",
+		Args:  cobra.ExactArgs(2),
+		RunE:  runAssetRevise,
+	}
+	assetReviseCmd.Flags().StringP("name", "n", "", "Revision name (defaults to the previous name)")
+	assetReviseCmd.Flags().String("version-label", "", "Version of the document (e.g. v1.3)")
+	assetReviseCmd.Flags().String("release-date", "", "Release date of this version (YYYY-MM-DD)")
 
-	// Peer commands
-	peerCmd := &cobra.Command{
-		Use:   "peer",
-		Short: "Manage peers for synchronization",
+	assetHistoryCmd := &cobra.Command{
+		Use:   "history <asset-id>",
+		Short: "Show every revision of an asset",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runAssetHistory,
 	}
 
-	peerListCmd := &cobra.Command{
-		Use:   "list",
-		Short: "List all discovered peers",
-		RunE:  runPeerList,
+	assetSetCurrentCmd := &cobra.Command{
+		Use:   "set-current <asset-id>",
+		Short: "Mark an asset as the current revision of its chain",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runAssetSetCurrent,
 	}
 
-	peerAddCmd := &cobra.Command{
-		Use:   "add <name> <address>",
-		Short: "Add a peer manually",
+	assetShareCmd := &cobra.Command{
+		Use:   "share <asset-id>",
+		Short: "Share an asset and its revisions with every item of the same product model",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runAssetShare,
+	}
+
+	assetSaveCmd := &cobra.Command{
+		Use:   "save <asset-id> <file>",
+		Short: "Write the file of an asset, decrypted, to a file",
 		Args:  cobra.ExactArgs(2),
-		RunE:  runPeerAdd,
+		RunE:  runAssetSave,
 	}
-	peerAddCmd.Flags().BoolP("trusted", "t", false, "Mark peer as trusted")
 
-	peerRemoveCmd := &cobra.Command{
-		Use:   "remove <id>",
-		Short: "Remove a peer",
-		Args:  cobra.ExactArgs(1),
-		RunE:  runPeerRemove,
+	assetCmd.AddCommand(assetAddCmd, assetListCmd, assetDeleteCmd, assetReviseCmd, assetHistoryCmd, assetSetCurrentCmd, assetShareCmd, assetSaveCmd)
+
+	// Photo commands
+	photoCmd := &cobra.Command{
+		Use:   "photo",
+		Short: "Manage item photos",
 	}
 
-	peerSyncCmd := &cobra.Command{
-		Use:   "sync <id>",
-		Short: "Synchronize with a peer",
-		Args:  cobra.ExactArgs(1),
-		RunE:  runPeerSync,
+	photoAddCmd := &cobra.Command{
+		Use:   "add <item-id> <file>",
+		Short: "Add a JPEG or PNG photo to an item",
+		Args:  cobra.ExactArgs(2),
+		RunE:  runPhotoAdd,
 	}
 
-	peerTrustCmd := &cobra.Command{
-		Use:   "trust <id>",
-		Short: "Mark a peer as trusted",
+	photoListCmd := &cobra.Command{
+		Use:   "list <item-id>",
+		Short: "List the photos of an item",
 		Args:  cobra.ExactArgs(1),
-		RunE:  runPeerTrust,
+		RunE:  runPhotoList,
 	}
 
-	peerUntrustCmd := &cobra.Command{
-		Use:   "untrust <id>",
-		Short: "Remove trust from a peer",
-		Args:  cobra.ExactArgs(1),
-		RunE:  runPeerUntrust,
+	photoImportLegacyCmd := &cobra.Command{
+		Use:   "import-legacy",
+		Short: "Move photos referenced by a file path into the managed asset store",
+		RunE:  runPhotoImportLegacy,
 	}
 
-	peerCmd.AddCommand(peerListCmd, peerAddCmd, peerRemoveCmd, peerSyncCmd, peerTrustCmd, peerUntrustCmd)
+	photoCmd.AddCommand(photoAddCmd, photoListCmd, photoImportLegacyCmd)
 
-	rootCmd.AddCommand(itemCmd, assetCmd, peerCmd)
+	// Asset type commands
+	assetTypeCmd := &cobra.Command{
+		Use:   "asset-type",
+		Short: "Manage asset types",
+	}
 
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+	assetTypeListCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List all asset types",
+		RunE:  runAssetTypeList,
 	}
-}
 
-func initApp() error {
-	// Setup logger
-	logger = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelInfo,
-	}))
+	assetTypeAddCmd := &cobra.Command{
+		Use:   "add <name> <label>",
+		Short: "Add or update an asset type",
+		Args:  cobra.ExactArgs(2),
+		RunE:  runAssetTypeAdd,
+	}
+	assetTypeAddCmd.Flags().StringSliceP("mime", "m", nil, "Accepted MIME patterns (e.g. application/pdf,image/*)")
+	assetTypeAddCmd.Flags().Bool("no-health", false, "Do not count these assets towards documentation health")
 
-	// Load configuration
-	var err error
-	cfg, err = config.Load()
-	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+	assetTypeRemoveCmd := &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Remove an unused asset type",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runAssetTypeRemove,
 	}
 
-	logger.Info("Configuration loaded", "data_dir", cfg.DataDir)
+	assetTypeCmd.AddCommand(assetTypeListCmd, assetTypeAddCmd, assetTypeRemoveCmd)
 
-	// Initialize database
-	database, err = db.NewDatabase(cfg.DatabasePath, logger)
-	if err != nil {
-		return fmt.Errorf("failed to initialize database: %w", err)
+	// Category commands
+	categoryCmd := &cobra.Command{
+		Use:   "category",
+		Short: "Manage item categories",
 	}
 
-	// Create queries
-	queries := db.New(database.DB)
+	categoryListCmd := &cobra.Command{
+		Use:   "list",
+		Short: "Show the category tree with synonyms",
+		RunE:  runCategoryList,
+	}
 
-	// Create backpack service
-	backpackService = services.NewBackpackService(queries, cfg.AssetsDir)
+	categoryAddCmd := &cobra.Command{
+		Use:   "add <name>",
+		Short: "Add a category",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runCategoryAdd,
+	}
+	categoryAddCmd.Flags().StringP("parent", "p", "", "Parent category")
 
-	// Create gossip service
-	instanceName := fmt.Sprintf("Brique-CLI-%s", os.Getenv("USER"))
-	if instanceName == "Brique-CLI-" {
-		instanceName = "Brique-CLI"
+	categoryMoveCmd := &cobra.Command{
+		Use:   "move <name> [parent]",
+		Short: "Move a category under another one, or to the top level",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE:  runCategoryMove,
 	}
-	gossipService = services.NewGossipService(queries, instanceName, "localhost:9090")
 
-	logger.Info("Application initialized successfully")
+	categoryRenameCmd := &cobra.Command{
+		Use:   "rename <name> <new-name>",
+		Short: "Rename a category, keeping the old name as a synonym",
+		Args:  cobra.ExactArgs(2),
+		RunE:  runCategoryRename,
+	}
 
-	return nil
-}
+	categoryAliasCmd := &cobra.Command{
+		Use:   "alias <name> <synonym>",
+		Short: "Add a synonym to a category",
+		Args:  cobra.ExactArgs(2),
+		RunE:  runCategoryAlias,
+	}
 
-func closeApp() error {
-	if database != nil {
-		return database.Close()
+	categoryUnaliasCmd := &cobra.Command{
+		Use:   "unalias <synonym>",
+		Short: "Remove a category synonym",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runCategoryUnalias,
 	}
-	return nil
-}
 
-// Item commands implementation
+	categoryMergeCmd := &cobra.Command{
+		Use:   "merge <from> <into>",
+		Short: "Merge a category and its items into another one",
+		Args:  cobra.ExactArgs(2),
+		RunE:  runCategoryMerge,
+	}
 
-func runItemAdd(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
-	reader := bufio.NewReader(os.Stdin)
+	categoryRemoveCmd := &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Remove an unused category",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runCategoryRemove,
+	}
 
-	fmt.Println("\n=== Add New Item ===\n")
+	categoryCmd.AddCommand(categoryListCmd, categoryAddCmd, categoryMoveCmd, categoryRenameCmd, categoryAliasCmd, categoryUnaliasCmd, categoryMergeCmd, categoryRemoveCmd)
 
-	fmt.Print("Name: ")
-	name, _ := reader.ReadString('\n')
-	name = strings.TrimSpace(name)
+	// Tag commands
+	tagCmd := &cobra.Command{
+		Use:   "tag",
+		Short: "Manage item tags",
+	}
 
-	fmt.Print("Category: ")
-	category, _ := reader.ReadString('\n')
-	category = strings.TrimSpace(category)
+	tagListCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the tags in use",
+		RunE:  runTagList,
+	}
 
-	fmt.Print("Brand: ")
-	brand, _ := reader.ReadString('\n')
-	brand = strings.TrimSpace(brand)
+	tagCmd.AddCommand(tagListCmd)
 
-	fmt.Print("Model: ")
-	model, _ := reader.ReadString('\n')
-	model = strings.TrimSpace(model)
+	// Product model commands
+	modelCmd := &cobra.Command{
+		Use:   "model",
+		Short: "Manage the product model catalogue and its shared documentation",
+	}
 
-	fmt.Print("Serial Number (optional): ")
-	serialNumber, _ := reader.ReadString('\n')
-	serialNumber = strings.TrimSpace(serialNumber)
+	modelListCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List product models with their aliases",
+		RunE:  runModelList,
+	}
 
-	fmt.Print("Notes (optional): ")
-	notes, _ := reader.ReadString('\n')
-	notes = strings.TrimSpace(notes)
+	modelShowCmd := &cobra.Command{
+		Use:   "show <id>",
+		Short: "Show a product model and its shared documentation",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runModelShow,
+	}
 
-	item := &models.Item{
-		Name:         name,
-		Category:     category,
-		Brand:        brand,
-		Model:        model,
-		SerialNumber: serialNumber,
-		Notes:        notes,
+	modelAliasCmd := &cobra.Command{
+		Use:   "alias <id> <brand> <model>",
+		Short: "Add another brand and model name, merging the product model of that name if any",
+		Args:  cobra.ExactArgs(3),
+		RunE:  runModelAlias,
 	}
 
-	if err := backpackService.CreateItem(ctx, item); err != nil {
-		return fmt.Errorf("failed to create item: %w", err)
+	modelUnaliasCmd := &cobra.Command{
+		Use:   "unalias <brand> <model>",
+		Short: "Remove a product model alias",
+		Args:  cobra.ExactArgs(2),
+		RunE:  runModelUnalias,
 	}
 
-	fmt.Printf("\n✓ Item created successfully with ID: %d\n", item.ID)
+	modelCmd.AddCommand(modelListCmd, modelShowCmd, modelAliasCmd, modelUnaliasCmd)
 
-	return nil
-}
+	// Repair log commands
+	repairCmd := &cobra.Command{
+		Use:   "repair",
+		Short: "Keep the repair history of items",
+	}
 
-func runItemList(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
+	repairAddCmd := &cobra.Command{
+		Use:   "add <item-id>",
+		Short: "Record a repair of an item",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runRepairAdd,
+	}
+	repairAddCmd.Flags().StringP("diagnosis", "d", "", "What was wrong")
+	repairAddCmd.Flags().StringP("action", "a", "", "What was done")
+	repairAddCmd.Flags().StringP("parts", "p", "", "Parts replaced")
+	repairAddCmd.Flags().String("cost", "", "Cost of the repair (e.g. 12.50)")
+	repairAddCmd.Flags().String("by", "", "Who performed the repair")
+	repairAddCmd.Flags().String("date", "", "Date of the repair (YYYY-MM-DD, defaults to today)")
+	repairAddCmd.Flags().Int64Slice("asset", nil, "ID of an asset documenting the repair (repeatable)")
 
-	items, err := backpackService.GetAllItems(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to get items: %w", err)
+	repairListCmd := &cobra.Command{
+		Use:   "list <item-id>",
+		Short: "List the repairs of an item, most recent first",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runRepairList,
 	}
 
-	if len(items) == 0 {
-		fmt.Println("No items in inventory.")
-		return nil
+	repairDeleteCmd := &cobra.Command{
+		Use:   "delete <repair-id>",
+		Short: "Delete a repair from the history",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runRepairDelete,
 	}
 
-	fmt.Printf("\n=== Inventory (%d items) ===\n\n", len(items))
+	repairCmd.AddCommand(repairAddCmd, repairListCmd, repairDeleteCmd)
 
-	for _, item := range items {
-		fmt.Printf("ID: %d | %s\n", item.ID, item.Name)
-		fmt.Printf("  Category: %s | Brand: %s | Model: %s\n", item.Category, item.Brand, item.Model)
-		if item.SerialNumber != "" {
-			fmt.Printf("  Serial: %s\n", item.SerialNumber)
-		}
-		fmt.Println()
+	// Maintenance commands
+	maintenanceCmd := &cobra.Command{
+		Use:   "maintenance",
+		Short: "Schedule preventive maintenance and see what is due",
 	}
 
-	return nil
-}
+	maintenanceAddCmd := &cobra.Command{
+		Use:   "add <item-id> <task>",
+		Short: "Schedule a recurring maintenance task",
+		Args:  cobra.ExactArgs(2),
+		RunE:  runMaintenanceAdd,
+	}
+	maintenanceAddCmd.Flags().Int64("every-days", 0, "Interval in days")
+	maintenanceAddCmd.Flags().Int64("every-usage", 0, "Interval in units of the usage counter")
+	maintenanceAddCmd.Flags().String("unit", "hours", "Usage counter of the usage interval")
 
-func runItemGet(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
+	maintenanceListCmd := &cobra.Command{
+		Use:   "list <item-id>",
+		Short: "List the maintenance schedules of an item",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runMaintenanceList,
+	}
 
-	id, err := strconv.ParseInt(args[0], 10, 64)
-	if err != nil {
-		return fmt.Errorf("invalid item ID: %w", err)
+	maintenanceDueCmd := &cobra.Command{
+		Use:   "due",
+		Short: "List overdue maintenance tasks",
+		RunE:  runMaintenanceDue,
 	}
+	maintenanceDueCmd.Flags().Int64("within", 0, "Also list tasks due in the next days")
 
-	itemWithAssets, err := backpackService.GetItemWithAssets(ctx, id)
-	if err != nil {
-		return fmt.Errorf("failed to get item: %w", err)
+	maintenanceDoneCmd := &cobra.Command{
+		Use:   "done <schedule-id>",
+		Short: "Mark a maintenance task as done",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runMaintenanceDone,
 	}
+	maintenanceDoneCmd.Flags().String("date", "", "Date it was done (YYYY-MM-DD, defaults to now)")
 
-	item := itemWithAssets.Item
+	maintenanceUsageCmd := &cobra.Command{
+		Use:   "usage <item-id> <value>",
+		Short: "Record the usage counter of an item",
+		Args:  cobra.ExactArgs(2),
+		RunE:  runMaintenanceUsage,
+	}
+	maintenanceUsageCmd.Flags().String("unit", "hours", "Usage counter to set")
 
-	fmt.Printf("\n=== Item #%d ===\n\n", item.ID)
-	fmt.Printf("Name:         %s\n", item.Name)
-	fmt.Printf("Category:     %s\n", item.Category)
-	fmt.Printf("Brand:        %s\n", item.Brand)
-	fmt.Printf("Model:        %s\n", item.Model)
-	if item.SerialNumber != "" {
-		fmt.Printf("Serial:       %s\n", item.SerialNumber)
+	maintenanceRemoveCmd := &cobra.Command{
+		Use:   "remove <schedule-id>",
+		Short: "Remove a maintenance schedule",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runMaintenanceRemove,
 	}
-	if item.PurchaseDate != nil {
-		fmt.Printf("Purchase:     %s\n", item.PurchaseDate.Format("2006-01-02"))
+
+	maintenanceCmd.AddCommand(maintenanceAddCmd, maintenanceListCmd, maintenanceDueCmd, maintenanceDoneCmd, maintenanceUsageCmd, maintenanceRemoveCmd)
+
+	// Warranty commands
+	warrantyCmd := &cobra.Command{
+		Use:   "warranty",
+		Short: "Track the warranties of items",
 	}
-	if item.Notes != "" {
-		fmt.Printf("Notes:        %s\n", item.Notes)
+
+	warrantySetCmd := &cobra.Command{
+		Use:   "set <item-id>",
+		Short: "Set the warranty of an item",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runWarrantySet,
 	}
-	fmt.Printf("Created:      %s\n", item.CreatedAt.Format("2006-01-02 15:04:05"))
-	fmt.Printf("Updated:      %s\n", item.UpdatedAt.Format("2006-01-02 15:04:05"))
+	warrantySetCmd.Flags().Int64P("months", "m", 0, "Duration of the warranty in months")
+	warrantySetCmd.Flags().StringP("provider", "p", "", "Manufacturer, retailer or insurer")
+	warrantySetCmd.Flags().Int64("receipt", 0, "ID of the asset holding the proof of purchase")
+	warrantySetCmd.Flags().String("purchased", "", "Purchase date of the item (YYYY-MM-DD), where the warranty starts")
 
-	// Display health and assets
-	fmt.Printf("\nDocumentation Health: %s\n", getHealthEmoji(itemWithAssets.Health))
+	warrantyExpiringCmd := &cobra.Command{
+		Use:   "expiring",
+		Short: "List items whose warranty expires soon",
+		RunE:  runWarrantyExpiring,
+	}
+	warrantyExpiringCmd.Flags().Int64("within", 30, "Number of days to look ahead")
 
-	if len(itemWithAssets.Assets) > 0 {
-		fmt.Printf("\nAssets (%d files):\n", len(itemWithAssets.Assets))
-		for _, asset := range itemWithAssets.Assets {
-			fmt.Printf("  [%d] %s (%s) - %s\n", asset.ID, asset.Name, asset.Type, formatFileSize(asset.FileSize))
-		}
-	} else {
-		fmt.Println("\nNo assets attached.")
+	warrantyRemoveCmd := &cobra.Command{
+		Use:   "remove <item-id>",
+		Short: "Remove the warranty of an item",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runWarrantyRemove,
 	}
 
-	fmt.Println()
+	warrantyCmd.AddCommand(warrantySetCmd, warrantyExpiringCmd, warrantyRemoveCmd)
 
-	return nil
-}
+	// Help announcement commands
+	announcementCmd := &cobra.Command{
+		Use:   "announcement",
+		Short: "Offer or ask for help through the peers",
+	}
 
-func runItemUpdate(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
-	reader := bufio.NewReader(os.Stdin)
+	announcementPostCmd := &cobra.Command{
+		Use:   "post <offer|request> <title>",
+		Short: "Publish a signed announcement, spread by the peers on sync",
+		Args:  cobra.ExactArgs(2),
+		RunE:  runAnnouncementPost,
+	}
+	announcementPostCmd.Flags().StringP("body", "b", "", "Details of the announcement")
+	announcementPostCmd.Flags().Int64("days", 14, "Number of days before the announcement expires")
+	announcementPostCmd.Flags().Int64("hops", 3, "Number of peers the announcement may go through")
 
-	id, err := strconv.ParseInt(args[0], 10, 64)
-	if err != nil {
-		return fmt.Errorf("invalid item ID: %w", err)
+	announcementListCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List running announcements",
+		RunE:  runAnnouncementList,
 	}
+	announcementListCmd.Flags().String("kind", "", "Only list offers or requests")
 
-	// Get existing item
-	item, err := backpackService.GetItem(ctx, id)
-	if err != nil {
-		return fmt.Errorf("failed to get item: %w", err)
+	announcementWithdrawCmd := &cobra.Command{
+		Use:   "withdraw <announcement-id>",
+		Short: "Withdraw an announcement published here",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runAnnouncementWithdraw,
 	}
 
-	fmt.Printf("\n=== Update Item #%d ===\n\n", id)
-	fmt.Println("Press Enter to keep current value, or type new value:")
-	fmt.Println()
+	announcementCmd.AddCommand(announcementPostCmd, announcementListCmd, announcementWithdrawCmd)
 
-	fmt.Printf("Name [%s]: ", item.Name)
-	if input, _ := reader.ReadString('\n'); strings.TrimSpace(input) != "" {
-		item.Name = strings.TrimSpace(input)
+	// Loan commands
+	loanCmd := &cobra.Command{
+		Use:   "loan",
+		Short: "Keep track of items lent to neighbours",
 	}
 
-	fmt.Printf("Category [%s]: ", item.Category)
-	if input, _ := reader.ReadString('\n'); strings.TrimSpace(input) != "" {
-		item.Category = strings.TrimSpace(input)
+	loanOutCmd := &cobra.Command{
+		Use:   "out <item-id> [borrower]",
+		Short: "Record that an item is lent",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE:  runLoanOut,
 	}
+	loanOutCmd.Flags().String("peer", "", "ID of the borrower's instance, which receives the loan on sync")
+	loanOutCmd.Flags().String("due", "", "Expected return date (YYYY-MM-DD)")
+	loanOutCmd.Flags().Int64("days", 0, "Expected return in a number of days")
+	loanOutCmd.Flags().StringP("notes", "n", "", "Notes on the loan")
 
-	fmt.Printf("Brand [%s]: ", item.Brand)
-	if input, _ := reader.ReadString('\n'); strings.TrimSpace(input) != "" {
-		item.Brand = strings.TrimSpace(input)
+	loanReturnCmd := &cobra.Command{
+		Use:   "return <loan-id>",
+		Short: "Record that a lent item came back",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runLoanReturn,
 	}
 
-	fmt.Printf("Model [%s]: ", item.Model)
-	if input, _ := reader.ReadString('\n'); strings.TrimSpace(input) != "" {
-		item.Model = strings.TrimSpace(input)
+	loanListCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List items lent and borrowed",
+		RunE:  runLoanList,
 	}
+	loanListCmd.Flags().Bool("all", false, "Include returned loans")
+	loanListCmd.Flags().Bool("overdue", false, "Only list overdue loans")
 
-	fmt.Printf("Serial Number [%s]: ", item.SerialNumber)
-	if input, _ := reader.ReadString('\n'); strings.TrimSpace(input) != "" {
-		item.SerialNumber = strings.TrimSpace(input)
+	loanCmd.AddCommand(loanOutCmd, loanReturnCmd, loanListCmd)
+
+	// Spare parts commands
+	partCmd := &cobra.Command{
+		Use:   "part",
+		Short: "Keep an inventory of spare parts",
 	}
 
-	fmt.Printf("Notes [%s]: ", item.Notes)
-	if input, _ := reader.ReadString('\n'); strings.TrimSpace(input) != "" {
-		item.Notes = strings.TrimSpace(input)
+	partAddCmd := &cobra.Command{
+		Use:   "add <name>",
+		Short: "Add a spare part to the inventory",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runPartAdd,
+	}
+	partAddCmd.Flags().StringP("ref", "r", "", "Manufacturer reference")
+	partAddCmd.Flags().Int64P("qty", "q", 0, "Quantity on hand")
+	partAddCmd.Flags().Int64("min", 0, "Minimum quantity before the part is low on stock")
+	partAddCmd.Flags().StringP("location", "l", "", "Where the part is stored")
+	partAddCmd.Flags().StringP("notes", "n", "", "Notes")
+	partAddCmd.Flags().Int64Slice("item", nil, "ID of a compatible item (repeatable)")
+	partAddCmd.Flags().Int64Slice("model", nil, "ID of a compatible product model (repeatable)")
+	partAddCmd.Flags().Int64Slice("stl", nil, "ID of an STL asset to print the part (repeatable)")
+
+	partListCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List spare parts",
+		RunE:  runPartList,
 	}
+	partListCmd.Flags().Bool("low", false, "Only list parts low on stock")
+	partListCmd.Flags().Int64("item", 0, "Only list parts that fit this item")
 
-	if err := backpackService.UpdateItem(ctx, item); err != nil {
-		return fmt.Errorf("failed to update item: %w", err)
+	partShowCmd := &cobra.Command{
+		Use:   "show <part-id>",
+		Short: "Show a spare part",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runPartShow,
 	}
 
-	fmt.Printf("\n✓ Item #%d updated successfully\n", id)
+	partStockCmd := &cobra.Command{
+		Use:   "stock <part-id> <delta>",
+		Short: "Adjust the quantity on hand of a part (e.g. -1 after using one)",
+		Args:  cobra.ExactArgs(2),
+		RunE:  runPartStock,
+	}
 
-	return nil
-}
+	partRemoveCmd := &cobra.Command{
+		Use:   "remove <part-id>",
+		Short: "Remove a spare part from the inventory",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runPartRemove,
+	}
 
-func runItemDelete(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
-	reader := bufio.NewReader(os.Stdin)
+	partCmd.AddCommand(partAddCmd, partListCmd, partShowCmd, partStockCmd, partRemoveCmd)
 
-	id, err := strconv.ParseInt(args[0], 10, 64)
-	if err != nil {
-		return fmt.Errorf("invalid item ID: %w", err)
+	// Peer commands
+	peerCmd := &cobra.Command{
+		Use:   "peer",
+		Short: "Manage peers for synchronization",
 	}
 
-	// Get item to show what will be deleted
-	item, err := backpackService.GetItem(ctx, id)
-	if err != nil {
-		return fmt.Errorf("failed to get item: %w", err)
+	peerListCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List all discovered peers",
+		RunE:  runPeerList,
 	}
 
-	fmt.Printf("\n=== Delete Item #%d ===\n\n", id)
-	fmt.Printf("Name: %s\n", item.Name)
-	fmt.Printf("Brand: %s %s\n", item.Brand, item.Model)
-	fmt.Println()
-	fmt.Print("Are you sure you want to delete this item? (yes/no): ")
-
-	confirmation, _ := reader.ReadString('\n')
-	confirmation = strings.ToLower(strings.TrimSpace(confirmation))
+	peerAddCmd := &cobra.Command{
+		Use:   "add <name> <address>",
+		Short: "Add a peer manually",
+		Args:  cobra.ExactArgs(2),
+		RunE:  runPeerAdd,
+	}
+	peerAddCmd.Flags().BoolP("trusted", "t", false, "Mark peer as trusted")
 
-	if confirmation != "yes" && confirmation != "y" {
-		fmt.Println("Deletion cancelled.")
-		return nil
+	peerRemoveCmd := &cobra.Command{
+		Use:   "remove <id>",
+		Short: "Remove a peer",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runPeerRemove,
 	}
+	peerRemoveCmd.Flags().BoolP("yes", "y", false, "Remove without asking for confirmation")
 
-	if err := backpackService.DeleteItem(ctx, id); err != nil {
-		return fmt.Errorf("failed to delete item: %w", err)
+	peerSyncCmd := &cobra.Command{
+		Use:   "sync <id>",
+		Short: "Synchronize with a peer",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runPeerSync,
 	}
 
-	fmt.Printf("\n✓ Item #%d deleted successfully\n", id)
+	peerTrustCmd := &cobra.Command{
+		Use:   "trust <id>",
+		Short: "Mark a peer as trusted",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runPeerTrust,
+	}
 
-	return nil
-}
+	peerUntrustCmd := &cobra.Command{
+		Use:   "untrust <id>",
+		Short: "Remove trust from a peer",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runPeerUntrust,
+	}
 
-func runItemSearch(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
-	query := args[0]
+	peerSearchCmd := &cobra.Command{
+		Use:   "search",
+		Short: "Search trusted peers for documentation",
+		RunE:  runPeerSearch,
+	}
+	peerSearchCmd.Flags().StringP("brand", "b", "", "Brand to search for")
+	peerSearchCmd.Flags().StringP("model", "m", "", "Model to search for")
+	peerSearchCmd.Flags().StringP("type", "t", "", "Only keep items with an asset of this type")
 
-	items, err := backpackService.SearchItems(ctx, query)
-	if err != nil {
-		return fmt.Errorf("failed to search items: %w", err)
+	peerFetchCmd := &cobra.Command{
+		Use:   "fetch <peer-id> <item-id>",
+		Short: "Copy an item and its assets from a peer",
+		Args:  cobra.ExactArgs(2),
+		RunE:  runPeerFetch,
 	}
 
-	if len(items) == 0 {
-		fmt.Printf("\nNo items found matching '%s'.\n", query)
-		return nil
+	peerCmd.AddCommand(peerListCmd, peerAddCmd, peerRemoveCmd, peerSyncCmd, peerTrustCmd, peerUntrustCmd, peerSearchCmd, peerFetchCmd)
+
+	// Health commands
+	healthCmd := &cobra.Command{
+		Use:   "health",
+		Short: "Check documentation completeness",
 	}
 
-	fmt.Printf("\n=== Search Results for '%s' (%d items) ===\n\n", query, len(items))
+	healthReportCmd := &cobra.Command{
+		Use:   "report",
+		Short: "List items missing documents required by their category",
+		RunE:  runHealthReport,
+	}
 
-	for _, item := range items {
-		fmt.Printf("ID: %d | %s\n", item.ID, item.Name)
-		fmt.Printf("  Category: %s | Brand: %s | Model: %s\n", item.Category, item.Brand, item.Model)
-		if item.SerialNumber != "" {
-			fmt.Printf("  Serial: %s\n", item.SerialNumber)
-		}
-		fmt.Println()
+	healthRulesCmd := &cobra.Command{
+		Use:   "rules",
+		Short: "Show the documentation rules per category",
+		RunE:  runHealthRules,
 	}
 
-	return nil
-}
+	healthCmd.AddCommand(healthReportCmd, healthRulesCmd)
 
-// Asset commands implementation
+	// Import commands
+	importCmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import items from other tools",
+	}
 
-func runAssetAdd(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
+	importCSVCmd := &cobra.Command{
+		Use:   "csv <file>",
+		Short: "Import items from a CSV file, skipping those already in the inventory",
+		Long: `Import items from a CSV file with a header row.
 
-	itemID, err := strconv.ParseInt(args[0], 10, 64)
-	if err != nil {
-		return fmt.Errorf("invalid item ID: %w", err)
+Columns are recognized by their header (name/nom, category/catégorie, brand/marque,
+model/modèle, serial_number/numéro de série, purchase_date/date d'achat, notes, tags),
+as written by the CSV export. Other columns named like attribute keys become custom
+attributes. Use --map to read a field from another column.
+
+Rows with the brand, model and serial number of an existing item are skipped.
+Exports encrypted with --encrypt are decrypted with the passphrase.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runImportCSV,
 	}
+	importCSVCmd.Flags().StringArray("map", nil, "Read a field from a column, as field=column, e.g. serial_number=\"S/N\" or attr.voltage=Tension (repeatable)")
+	importCSVCmd.Flags().Bool("dry-run", false, "Show what would be imported without importing it")
 
-	filePath := args[1]
+	importJSONCmd := &cobra.Command{
+		Use:   "json <file>",
+		Short: "Import the items of a JSON export, skipping those already in the inventory",
+		Long: `Import the items of a JSON export, skipping those already in the inventory.
 
-	// Check if file exists
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return fmt.Errorf("file does not exist: %s", filePath)
+Exports encrypted with --encrypt are decrypted with the passphrase.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runImportJSON,
+	}
+
+	importCmd.AddCommand(importCSVCmd, importJSONCmd)
+
+	// Export commands
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the inventory",
+	}
+
+	exportJSONCmd := &cobra.Command{
+		Use:   "json [file]",
+		Short: "Export the items with their assets as JSON, to stdout without file",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  runExport,
+	}
+
+	exportCSVCmd := &cobra.Command{
+		Use:   "csv [file]",
+		Short: "Export the items as CSV, to stdout without file",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  runExport,
+	}
+
+	for _, cmd := range []*cobra.Command{exportJSONCmd, exportCSVCmd} {
+		cmd.Flags().Bool("encrypt", false, "Encrypt the export with a passphrase (BRIQUE_PASSPHRASE, passphrase_file or typed in)")
+	}
+
+	exportCmd.AddCommand(exportJSONCmd, exportCSVCmd)
+
+	// Backup commands
+	backupCmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Back up the database and assets",
+	}
+
+	backupCreateCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Snapshot the database and assets, then delete the backups beyond the retention policy",
+		Long: `Snapshot the database and assets into the backups directory.
+
+Asset files already saved by an earlier backup are not copied again. Backups beyond
+backup_retention (keep_last, keep_daily) in config.yaml are then deleted.`,
+		Args: cobra.NoArgs,
+		RunE: runBackupCreate,
+	}
+
+	backupListCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List backups, newest first",
+		Args:  cobra.NoArgs,
+		RunE:  runBackupList,
+	}
+
+	backupVerifyCmd := &cobra.Command{
+		Use:   "verify <backup>",
+		Short: "Check a backup against the hashes of its manifest",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runBackupVerify,
+	}
+
+	backupPruneCmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Delete the backups beyond the retention policy",
+		Args:  cobra.NoArgs,
+		RunE:  runBackupPrune,
+	}
+
+	backupStatusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the scheduled backups of each destination",
+		Long: `Show the last and next scheduled backup of each destination of backup_schedule.
+
+Backups are scheduled by brique-server: use --remote to also see its failed backups.`,
+		Args: cobra.NoArgs,
+		RunE: runBackupStatus,
+	}
+
+	backupCmd.AddCommand(backupCreateCmd, backupListCmd, backupVerifyCmd, backupPruneCmd, backupStatusCmd)
+
+	restoreCmd := &cobra.Command{
+		Use:   "restore <backup>",
+		Short: "Replace the database and assets with those of a verified backup",
+		Long: `Replace the database and assets with those of a backup, given by name or path.
+
+The backup is verified first, and the current data is backed up before being
+replaced. Stop the GUI and brique-server using the same data beforehand.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runRestore,
+	}
+	restoreCmd.Flags().BoolP("yes", "y", false, "Skip confirmation prompt")
+
+	// Encryption commands
+	encryptionCmd := &cobra.Command{
+		Use:   "encryption",
+		Short: "Encrypt serial numbers, notes and asset files at rest",
+		Long: `Encrypt the serial numbers, notes and asset files of the local data with a key
+protected by a passphrase (Argon2id, XChaCha20-Poly1305).
+
+Once enabled, the passphrase is required to start the CLI, the GUI and brique-server:
+it is read from BRIQUE_PASSPHRASE, from the file given by passphrase_file in
+config.yaml, or typed in. Stop the GUI and brique-server using the same data before
+enabling, rotating or disabling encryption.`,
+	}
+
+	encryptionStatusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show whether the data is encrypted at rest",
+		Args:  cobra.NoArgs,
+		RunE:  runEncryptionStatus,
+	}
+
+	encryptionEnableCmd := &cobra.Command{
+		Use:   "enable",
+		Short: "Choose a passphrase and encrypt the data with a new key",
+		Args:  cobra.NoArgs,
+		RunE:  runEncryptionEnable,
+	}
+
+	encryptionRotateCmd := &cobra.Command{
+		Use:   "rotate",
+		Short: "Re-encrypt the data with a new key, and delete the former one",
+		Args:  cobra.NoArgs,
+		RunE:  runEncryptionRotate,
+	}
+	encryptionRotateCmd.Flags().Bool("new-passphrase", false, "Choose a new passphrase for the new key")
+
+	encryptionPassphraseCmd := &cobra.Command{
+		Use:   "passphrase",
+		Short: "Change the passphrase, keeping the key and the data as they are",
+		Args:  cobra.NoArgs,
+		RunE:  runEncryptionPassphrase,
+	}
+
+	encryptionDisableCmd := &cobra.Command{
+		Use:   "disable",
+		Short: "Decrypt the data and delete the keys",
+		Args:  cobra.NoArgs,
+		RunE:  runEncryptionDisable,
+	}
+	encryptionDisableCmd.Flags().BoolP("yes", "y", false, "Skip confirmation prompt")
+
+	encryptionCmd.AddCommand(encryptionStatusCmd, encryptionEnableCmd, encryptionRotateCmd, encryptionPassphraseCmd, encryptionDisableCmd)
+
+	// Config commands
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Show, change and check the configuration",
+		Long: `Show, change and check the configuration, read from config.yaml in the data
+directory and from BRIQUE_* environment variables (e.g. BRIQUE_PORT,
+BRIQUE_GOSSIP_PEER_TIMEOUT), which take precedence.`,
+		// The configuration may be invalid: it is loaded by each command
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutputFormat(); err != nil {
+				return err
+			}
+			cmd.SilenceUsage = true
+			return nil
+		},
+	}
+
+	configShowCmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show the configuration in effect, defaults included",
+		Args:  cobra.NoArgs,
+		RunE:  runConfigShow,
+	}
+
+	configSetCmd := &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Change a setting in config.yaml",
+		Long: `Change a setting in config.yaml, keeping the rest of the file as it is.
+
+Nested settings are given with dots, and values are read as YAML:
+  brique config set port 8081
+  brique config set gossip.peer_timeout 1m
+  brique config set backup_schedule.destinations "[/media/usb/brique]"
+
+The file is only written if the resulting configuration is valid.`,
+		Args: cobra.ExactArgs(2),
+		RunE: runConfigSet,
+	}
+
+	configValidateCmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Check the configuration, reporting every invalid setting",
+		Args:  cobra.NoArgs,
+		RunE:  runConfigValidate,
+	}
+
+	configCmd.AddCommand(configShowCmd, configSetCmd, configValidateCmd)
+
+	// Profile commands
+	profileCmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Manage the profiles, each with its own inventory",
+		Long: `Manage the profiles. Each profile has its own database, assets and backups in
+the data directory, and its own identity for the peers. The default profile is
+the inventory at the root of the data directory.
+
+Commands work on the profile given by --profile, else on the one configured by
+BRIQUE_PROFILE or the profile setting.`,
+		// The profile in use may not exist yet: it is loaded by each command
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutputFormat(); err != nil {
+				return err
+			}
+			if remoteURL != "" {
+				return usageError{errors.New("profiles are managed on the data of this machine: run it on the server")}
+			}
+			cmd.SilenceUsage = true
+			return nil
+		},
+	}
+
+	profileListCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the profiles",
+		Args:  cobra.NoArgs,
+		RunE:  runProfileList,
+	}
+
+	profileCreateCmd := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create an empty profile",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runProfileCreate,
+	}
+
+	profileUseCmd := &cobra.Command{
+		Use:   "use <name>",
+		Short: "Make a profile the one used without --profile",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runProfileUse,
+	}
+
+	profileDeleteCmd := &cobra.Command{
+		Use:   "delete <name>",
+		Short: "Delete a profile with its inventory, assets and backups",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runProfileDelete,
+	}
+	profileDeleteCmd.Flags().BoolP("yes", "y", false, "Skip confirmation prompt")
+
+	profileCmd.AddCommand(profileListCmd, profileCreateCmd, profileUseCmd, profileDeleteCmd)
+
+	rootCmd.AddCommand(itemCmd, assetCmd, photoCmd, assetTypeCmd, categoryCmd, tagCmd, modelCmd, repairCmd, maintenanceCmd, warrantyCmd, partCmd, announcementCmd, loanCmd, peerCmd, healthCmd, importCmd, exportCmd, backupCmd, restoreCmd, encryptionCmd, configCmd, profileCmd)
+
+	markUsageErrors(rootCmd)
+	if err := rootCmd.Execute(); err != nil {
+		printError(err)
+		os.Exit(exitCode(err))
+	}
+}
+
+func initApp() error {
+	// Setup logger, on stderr to keep stdout for results, at the configured
+	// level once the configuration is loaded
+	var level slog.LevelVar
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level: &level,
+	}))
+
+	if remoteURL != "" && profileName != "" {
+		return usageError{errors.New("--profile selects a local inventory: choose the profile of the server when starting it")}
+	}
+
+	// Load configuration
+	var err error
+	cfg, err = config.LoadProfile(profileName)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	minLevel := cfg.Level()
+	if structuredOutput() {
+		minLevel = max(minLevel, slog.LevelWarn)
+	}
+	level.Set(minLevel)
+
+	logger.Info("Configuration loaded", "data_dir", cfg.DataDir)
+
+	// The --remote flag takes precedence over BRIQUE_REMOTE and the config file
+	if remoteURL == "" {
+		remoteURL = cfg.Remote
+	}
+	if remoteURL != "" {
+		client, err := newRemoteClient(remoteURL)
+		if err != nil {
+			return err
+		}
+		backpackService = client
+		gossipService = client
+
+		logger.Info("Using remote server", "url", client.baseURL)
+		return nil
+	}
+
+	// Initialize database
+	database, err = db.NewDatabase(cfg.DatabasePath, logger)
+	if err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+
+	// Create backpack service
+	backpack := services.NewBackpackService(database, cfg.AssetsDir)
+	backpack.SetLogger(logger)
+	backpack.SetHealthRules(cfg.HealthRules)
+	backpack.SetAttributeTemplates(cfg.AttributeTemplates)
+
+	// Unlock the data encrypted at rest
+	if err := unlockEncryption(backpack); err != nil {
+		return err
+	}
+
+	// Finish file operations interrupted by a previous crash
+	if err := backpack.ProcessFileOutbox(context.Background()); err != nil {
+		logger.Warn("Failed to process file outbox", "error", err)
+	}
+
+	// Link items created before product models existed
+	if _, err := backpack.LinkProductModels(context.Background()); err != nil {
+		logger.Warn("Failed to link product models", "error", err)
+	}
+	backpackService = localBackpack{
+		BackpackService: backpack,
+		backupsDir:      cfg.BackupsDir,
+		retention:       cfg.BackupRetention,
+		scheduler:       services.NewBackupScheduler(backpack, cfg.BackupSchedule.Interval, cfg.BackupSchedule.Destinations, cfg.BackupRetention, logger),
+	}
+
+	// Create gossip service
+	gossip := services.NewGossipService(database, cfg.InstanceName, fmt.Sprintf("localhost:%d", cfg.Gossip.Port))
+	gossip.SetKeyring(backpack.Keyring())
+	gossip.SetLogger(logger)
+	gossip.SetPeerTimeout(cfg.Gossip.PeerTimeout)
+	gossipService = localGossip{gossip, backpack}
+
+	logger.Info("Application initialized successfully")
+
+	return nil
+}
+
+// unlockEncryption unlocks the data encrypted at rest, if any, with
+// BRIQUE_PASSPHRASE, the passphrase file or the passphrase typed in
+func unlockEncryption(backpack *services.BackpackService) error {
+	ctx := context.Background()
+
+	status, err := backpack.EncryptionStatus(ctx)
+	if err != nil || !status.Enabled {
+		return err
+	}
+
+	passphrase, err = cfg.Passphrase()
+	if err != nil {
+		return err
+	}
+	if passphrase == "" {
+		passphrase, err = readPassphrase("Passphrase: ")
+		if err != nil {
+			return err
+		}
+	}
+
+	return backpack.UnlockEncryption(ctx, passphrase)
+}
+
+func closeApp() error {
+	if database != nil {
+		return database.Close()
+	}
+	return nil
+}
+
+// Item commands implementation
+
+func runItemAdd(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	item := &models.Item{}
+	if err := applyItemFieldFlags(cmd, item); err != nil {
+		return err
+	}
+
+	attributes, err := parseAttributeFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	// Without any field given as flag, ask for them
+	if !itemFieldFlagsChanged(cmd) && !structuredOutput() {
+		reader := bufio.NewReader(os.Stdin)
+
+		fmt.Print("\n=== Add New Item ===\n\n")
+
+		fmt.Print("Name: ")
+		name, _ := reader.ReadString('\n')
+		item.Name = strings.TrimSpace(name)
+
+		fmt.Print("Category: ")
+		category, _ := reader.ReadString('\n')
+		item.Category = strings.TrimSpace(category)
+
+		fmt.Print("Brand: ")
+		brand, _ := reader.ReadString('\n')
+		item.Brand = strings.TrimSpace(brand)
+
+		fmt.Print("Model: ")
+		model, _ := reader.ReadString('\n')
+		item.Model = strings.TrimSpace(model)
+
+		fmt.Print("Serial Number (optional): ")
+		serialNumber, _ := reader.ReadString('\n')
+		item.SerialNumber = strings.TrimSpace(serialNumber)
+
+		fmt.Print("Notes (optional): ")
+		notes, _ := reader.ReadString('\n')
+		item.Notes = strings.TrimSpace(notes)
+
+		// Ask for the attributes suggested by the category that were not given as flags
+		template, err := backpackService.GetAttributeTemplate(ctx, item.Category)
+		if err != nil {
+			return fmt.Errorf("failed to get attribute template: %w", err)
+		}
+		for _, def := range template.Attributes {
+			if hasAttribute(attributes, def.Key) {
+				continue
+			}
+
+			fmt.Printf("%s (optional): ", def.Label)
+			value, _ := reader.ReadString('\n')
+			if value = strings.TrimSpace(value); value != "" {
+				attributes = append(attributes, models.Attribute{Key: def.Key, Type: def.Type, Value: value})
+			}
+		}
+	} else if item.Name == "" {
+		return usageError{errors.New("the item name is required (use --name)")}
+	}
+
+	item.Attributes = attributes
+	item.Tags, _ = cmd.Flags().GetStringArray("tag")
+
+	if err := backpackService.CreateItem(ctx, item); err != nil {
+		return fmt.Errorf("failed to create item: %w", err)
+	}
+
+	return printResult(item, func() {
+		fmt.Printf("\n✓ Item created successfully with ID: %d\n", item.ID)
+	})
+}
+
+func runItemList(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	filter, err := parseItemFilterFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	items, err := backpackService.FilterItems(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to get items: %w", err)
+	}
+
+	return printResult(items, func() {
+		if len(items) == 0 {
+			fmt.Println("No items in inventory.")
+			return
+		}
+
+		fmt.Printf("\n=== Inventory (%d items) ===\n\n", len(items))
+		printItems(items)
+	})
+}
+
+func runItemGet(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	id, err := parseID(args[0], "item")
+	if err != nil {
+		return err
+	}
+
+	itemWithAssets, err := backpackService.GetItemWithAssets(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get item: %w", err)
+	}
+
+	return printResult(itemWithAssets, func() {
+		item := itemWithAssets.Item
+
+		fmt.Printf("\n=== Item #%d ===\n\n", item.ID)
+		fmt.Printf("Name:         %s\n", item.Name)
+		fmt.Printf("Category:     %s\n", item.Category)
+		fmt.Printf("Brand:        %s\n", item.Brand)
+		fmt.Printf("Model:        %s\n", item.Model)
+		if item.SerialNumber != "" {
+			fmt.Printf("Serial:       %s\n", item.SerialNumber)
+		}
+		if item.PurchaseDate != nil {
+			fmt.Printf("Purchase:     %s\n", item.PurchaseDate.Format("2006-01-02"))
+		}
+		if item.Warranty != nil {
+			fmt.Printf("Warranty:     %s\n", formatWarranty(item.Warranty))
+		}
+		if item.Loan != nil {
+			fmt.Printf("Lent:         %s\n", formatLoan(item.Loan))
+		}
+		if item.Notes != "" {
+			fmt.Printf("Notes:        %s\n", item.Notes)
+		}
+		fmt.Printf("Created:      %s\n", item.CreatedAt.Format("2006-01-02 15:04:05"))
+		fmt.Printf("Updated:      %s\n", item.UpdatedAt.Format("2006-01-02 15:04:05"))
+		if len(item.Tags) > 0 {
+			fmt.Printf("Tags:         %s\n", strings.Join(item.Tags, ", "))
+		}
+
+		if len(item.Attributes) > 0 {
+			fmt.Println("\nAttributes:")
+			for _, attr := range item.Attributes {
+				fmt.Printf("  %s = %s (%s)\n", attr.Key, attr.Value, attr.Type)
+			}
+		}
+
+		// Display health and assets
+		fmt.Printf("\nDocumentation Health: %s\n", getHealthEmoji(itemWithAssets.Health))
+
+		if len(itemWithAssets.Assets) > 0 {
+			fmt.Printf("\nAssets (%d files):\n", len(itemWithAssets.Assets))
+			for _, asset := range itemWithAssets.Assets {
+				fmt.Printf("  [%d] %s (%s) - %s\n", asset.ID, asset.Name, asset.Type, formatFileSize(asset.FileSize))
+			}
+		} else {
+			fmt.Println("\nNo assets attached.")
+		}
+
+		fmt.Println()
+	})
+}
+
+func runItemUpdate(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	id, err := parseID(args[0], "item")
+	if err != nil {
+		return err
+	}
+
+	// Get existing item
+	item, err := backpackService.GetItem(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get item: %w", err)
+	}
+
+	// Without any change given as flag, ask for the new values
+	if !itemFieldFlagsChanged(cmd) && !cmd.Flags().Changed("attr") && !cmd.Flags().Changed("tag") && !cmd.Flags().Changed("untag") && !structuredOutput() {
+		reader := bufio.NewReader(os.Stdin)
+
+		fmt.Printf("\n=== Update Item #%d ===\n\n", id)
+		fmt.Println("Press Enter to keep current value, or type new value:")
+		fmt.Println()
+
+		fmt.Printf("Name [%s]: ", item.Name)
+		if input, _ := reader.ReadString('\n'); strings.TrimSpace(input) != "" {
+			item.Name = strings.TrimSpace(input)
+		}
+
+		fmt.Printf("Category [%s]: ", item.Category)
+		if input, _ := reader.ReadString('\n'); strings.TrimSpace(input) != "" {
+			item.Category = strings.TrimSpace(input)
+		}
+
+		fmt.Printf("Brand [%s]: ", item.Brand)
+		if input, _ := reader.ReadString('\n'); strings.TrimSpace(input) != "" {
+			item.Brand = strings.TrimSpace(input)
+		}
+
+		fmt.Printf("Model [%s]: ", item.Model)
+		if input, _ := reader.ReadString('\n'); strings.TrimSpace(input) != "" {
+			item.Model = strings.TrimSpace(input)
+		}
+
+		fmt.Printf("Serial Number [%s]: ", item.SerialNumber)
+		if input, _ := reader.ReadString('\n'); strings.TrimSpace(input) != "" {
+			item.SerialNumber = strings.TrimSpace(input)
+		}
+
+		fmt.Printf("Notes [%s]: ", item.Notes)
+		if input, _ := reader.ReadString('\n'); strings.TrimSpace(input) != "" {
+			item.Notes = strings.TrimSpace(input)
+		}
+	} else if err := applyItemFieldFlags(cmd, item); err != nil {
+		return err
+	}
+
+	// Attributes given as flags replace those with the same key
+	attributes, err := parseAttributeFlags(cmd)
+	if err != nil {
+		return err
+	}
+	for _, attr := range attributes {
+		replaced := false
+		for i := range item.Attributes {
+			if item.Attributes[i].Key == attr.Key {
+				item.Attributes[i] = attr
+				replaced = true
+			}
+		}
+		if !replaced {
+			item.Attributes = append(item.Attributes, attr)
+		}
+	}
+
+	added, _ := cmd.Flags().GetStringArray("tag")
+	removed, _ := cmd.Flags().GetStringArray("untag")
+	tags := append(item.Tags, added...)
+	item.Tags = make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if !containsFold(removed, tag) {
+			item.Tags = append(item.Tags, tag)
+		}
+	}
+
+	if err := backpackService.UpdateItem(ctx, item); err != nil {
+		return fmt.Errorf("failed to update item: %w", err)
+	}
+
+	return printResult(item, func() {
+		fmt.Printf("\n✓ Item #%d updated successfully\n", id)
+	})
+}
+
+func runItemDelete(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	id, err := parseID(args[0], "item")
+	if err != nil {
+		return err
+	}
+
+	// Get item to show what will be deleted
+	item, err := backpackService.GetItem(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get item: %w", err)
+	}
+
+	if yes, _ := cmd.Flags().GetBool("yes"); !yes && !structuredOutput() {
+		fmt.Printf("\n=== Delete Item #%d ===\n\n", id)
+		fmt.Printf("Name: %s\n", item.Name)
+		fmt.Printf("Brand: %s %s\n", item.Brand, item.Model)
+		fmt.Println()
+	}
+
+	confirmed, err := confirm(cmd, "Are you sure you want to delete this item?")
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		fmt.Println("Deletion cancelled.")
+		return nil
+	}
+
+	if err := backpackService.DeleteItem(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete item: %w", err)
+	}
+
+	return printResult(actionResult{Action: "deleted", ID: id}, func() {
+		fmt.Printf("\n✓ Item #%d deleted successfully\n", id)
+	})
+}
+
+func runItemSearch(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	query := args[0]
+
+	filter, err := parseItemFilterFlags(cmd)
+	if err != nil {
+		return err
+	}
+	filter.Query = query
+
+	items, err := backpackService.FilterItems(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to search items: %w", err)
+	}
+
+	return printResult(items, func() {
+		if len(items) == 0 {
+			fmt.Printf("\nNo items found matching '%s'.\n", query)
+			return
+		}
+
+		fmt.Printf("\n=== Search Results for '%s' (%d items) ===\n\n", query, len(items))
+		printItems(items)
+	})
+}
+
+func runItemTemplates(cmd *cobra.Command, args []string) error {
+	templates, err := backpackService.GetAttributeTemplates(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to get attribute templates: %w", err)
+	}
+
+	return printResult(templates, func() {
+		fmt.Printf("\n=== Attribute Templates (%d categories) ===\n\n", len(templates))
+
+		for _, template := range templates {
+			fmt.Printf("%s\n", template.Category)
+			for _, def := range template.Attributes {
+				fmt.Printf("  %-20s %-8s %s\n", def.Key, def.Type, def.Label)
+			}
+			fmt.Println()
+		}
+	})
+}
+
+// printItems prints a summary of each item
+func printItems(items []models.Item) {
+	for _, item := range items {
+		fmt.Printf("ID: %d | %s\n", item.ID, item.Name)
+		fmt.Printf("  Category: %s | Brand: %s | Model: %s\n", item.Category, item.Brand, item.Model)
+		if item.SerialNumber != "" {
+			fmt.Printf("  Serial: %s\n", item.SerialNumber)
+		}
+		if len(item.Tags) > 0 {
+			fmt.Printf("  Tags: %s\n", strings.Join(item.Tags, ", "))
+		}
+		fmt.Println()
+	}
+}
+
+// itemFields are the flags setting the fields of an item
+var itemFields = []string{"name", "category", "brand", "model", "serial", "purchased", "notes"}
+
+// addItemFieldFlags adds a flag for each field of an item
+func addItemFieldFlags(cmd *cobra.Command) {
+	cmd.Flags().String("name", "", "Name of the item")
+	cmd.Flags().StringP("category", "c", "", "Category of the item")
+	cmd.Flags().StringP("brand", "b", "", "Brand of the item")
+	cmd.Flags().StringP("model", "m", "", "Model of the item")
+	cmd.Flags().String("serial", "", "Serial number of the item")
+	cmd.Flags().String("purchased", "", "Purchase date of the item (YYYY-MM-DD), empty to clear it")
+	cmd.Flags().StringP("notes", "n", "", "Notes on the item")
+}
+
+// itemFieldFlagsChanged tells whether any field of the item was given as flag
+func itemFieldFlagsChanged(cmd *cobra.Command) bool {
+	for _, name := range itemFields {
+		if cmd.Flags().Changed(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyItemFieldFlags sets the fields of item given as flags, keeping the others
+func applyItemFieldFlags(cmd *cobra.Command, item *models.Item) error {
+	fields := map[string]*string{
+		"name":     &item.Name,
+		"category": &item.Category,
+		"brand":    &item.Brand,
+		"model":    &item.Model,
+		"serial":   &item.SerialNumber,
+		"notes":    &item.Notes,
+	}
+	for name, field := range fields {
+		if cmd.Flags().Changed(name) {
+			value, _ := cmd.Flags().GetString(name)
+			*field = strings.TrimSpace(value)
+		}
+	}
+
+	if cmd.Flags().Changed("purchased") {
+		purchased, _ := cmd.Flags().GetString("purchased")
+		if purchased == "" {
+			item.PurchaseDate = nil
+			return nil
+		}
+
+		purchaseDate, err := time.ParseInLocation("2006-01-02", purchased, time.Local)
+		if err != nil {
+			return usageError{fmt.Errorf("invalid date %q (expected YYYY-MM-DD): %w", purchased, err)}
+		}
+		item.PurchaseDate = &purchaseDate
+	}
+
+	return nil
+}
+
+// parseAttributeFlags reads the --attr flags, given as key=value or key:type=value
+func parseAttributeFlags(cmd *cobra.Command) ([]models.Attribute, error) {
+	values, _ := cmd.Flags().GetStringArray("attr")
+
+	attributes := make([]models.Attribute, 0, len(values))
+	for _, value := range values {
+		key, val, ok := strings.Cut(value, "=")
+		if !ok {
+			return nil, usageError{fmt.Errorf("invalid attribute %q: expected key=value", value)}
+		}
+
+		key, attrType, _ := strings.Cut(strings.TrimSpace(key), ":")
+		attributes = append(attributes, models.Attribute{
+			Key:   key,
+			Type:  models.AttributeType(attrType),
+			Value: val,
+		})
+	}
+
+	return attributes, nil
+}
+
+// parseItemFilterFlags reads the --category, --tag and --attr filter flags
+func parseItemFilterFlags(cmd *cobra.Command) (models.ItemFilter, error) {
+	var filter models.ItemFilter
+	filter.Category, _ = cmd.Flags().GetString("category")
+	filter.Tags, _ = cmd.Flags().GetStringArray("tag")
+
+	if cmd.Flags().Lookup("attr") == nil {
+		return filter, nil
+	}
+
+	exprs, _ := cmd.Flags().GetStringArray("attr")
+	filter.Attributes = make([]models.AttributeFilter, len(exprs))
+	for i, expr := range exprs {
+		attrFilter, err := services.ParseAttributeFilter(expr)
+		if err != nil {
+			return filter, err
+		}
+		filter.Attributes[i] = attrFilter
+	}
+
+	return filter, nil
+}
+
+func hasAttribute(attributes []models.Attribute, key string) bool {
+	for _, attr := range attributes {
+		if attr.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Asset commands implementation
+
+func runAssetAdd(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	itemID, err := parseID(args[0], "item")
+	if err != nil {
+		return err
+	}
+
+	filePath := args[1]
+
+	// Check if file exists
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		return fmt.Errorf("file does not exist: %s", filePath)
 	}
 
 	// Get flags
 	assetType, _ := cmd.Flags().GetString("type")
-	assetName, _ := cmd.Flags().GetString("name")
+	assetName, _ := cmd.Flags().GetString("name")
+
+	// Default name to filename if not provided
+	if assetName == "" {
+		assetName = filepath.Base(filePath)
+	}
+
+	versionLabel, _ := cmd.Flags().GetString("version-label")
+	releaseDate, err := parseReleaseDate(cmd)
+	if err != nil {
+		return err
+	}
+
+	var asset *models.Asset
+	if shared, _ := cmd.Flags().GetBool("shared"); shared {
+		item, err := backpackService.GetItem(ctx, itemID)
+		if err != nil {
+			return fmt.Errorf("failed to get item: %w", err)
+		}
+		if item.ProductModelID == nil {
+			return fmt.Errorf("item #%d has no product model: set its brand and model first", itemID)
+		}
+
+		printProgress("\nAdding asset to the product model of item #%d...\n", itemID)
+		asset, err = backpackService.AddProductModelAsset(ctx, *item.ProductModelID, models.AssetType(assetType), assetName, filePath)
+	} else {
+		printProgress("\nAdding asset to item #%d...\n", itemID)
+		asset, err = backpackService.AddAsset(ctx, itemID, models.AssetType(assetType), assetName, filePath)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to add asset: %w", err)
+	}
+
+	if versionLabel != "" || releaseDate != nil {
+		if err := backpackService.SetAssetVersion(ctx, asset.ID, versionLabel, releaseDate); err != nil {
+			return fmt.Errorf("failed to set asset version: %w", err)
+		}
+		asset.VersionLabel = versionLabel
+		asset.ReleaseDate = releaseDate
+	}
+
+	return printResult(asset, func() {
+		fmt.Printf("\n✓ Asset added successfully\n")
+		fmt.Printf("  ID: %d\n", asset.ID)
+		fmt.Printf("  Name: %s\n", asset.Name)
+		fmt.Printf("  Type: %s\n", asset.Type)
+		fmt.Printf("  Size: %s\n", formatFileSize(asset.FileSize))
+		fmt.Printf("  Hash: %s\n", asset.FileHash[:16]+"...")
+	})
+}
+
+func runAssetList(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	itemID, err := parseID(args[0], "item")
+	if err != nil {
+		return err
+	}
+
+	// Get item info
+	item, err := backpackService.GetItem(ctx, itemID)
+	if err != nil {
+		return fmt.Errorf("failed to get item: %w", err)
+	}
+
+	// Get assets
+	assets, err := backpackService.GetItemAssets(ctx, itemID)
+	if err != nil {
+		return fmt.Errorf("failed to get assets: %w", err)
+	}
+
+	return printResult(assets, func() {
+		fmt.Printf("\n=== Assets for Item #%d: %s ===\n\n", itemID, item.Name)
+
+		if len(assets) == 0 {
+			fmt.Println("No assets attached to this item.")
+			return
+		}
+
+		fmt.Printf("Total: %d file(s)\n\n", len(assets))
+
+		totalSize := int64(0)
+		for _, asset := range assets {
+			fmt.Printf("ID: %d\n", asset.ID)
+			fmt.Printf("  Name: %s\n", asset.Name)
+			fmt.Printf("  Type: %s\n", asset.Type)
+			if asset.VersionLabel != "" {
+				fmt.Printf("  Version: %s\n", asset.VersionLabel)
+			}
+			if !asset.IsCurrent {
+				fmt.Printf("  Status: superseded\n")
+			}
+			if asset.ProductModelID != nil {
+				fmt.Printf("  Shared: product model #%d\n", *asset.ProductModelID)
+			}
+			fmt.Printf("  Size: %s\n", formatFileSize(asset.FileSize))
+			fmt.Printf("  Path: %s\n", asset.FilePath)
+			fmt.Printf("  Hash: %s\n", asset.FileHash[:16]+"...")
+			fmt.Printf("  Added: %s\n", asset.CreatedAt.Format("2006-01-02 15:04:05"))
+			fmt.Println()
+
+			totalSize += asset.FileSize
+		}
+
+		fmt.Printf("Total size: %s\n", formatFileSize(totalSize))
+	})
+}
+
+func runAssetDelete(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	assetID, err := parseID(args[0], "asset")
+	if err != nil {
+		return err
+	}
+
+	if yes, _ := cmd.Flags().GetBool("yes"); !yes && !structuredOutput() {
+		fmt.Printf("\n=== Delete Asset #%d ===\n\n", assetID)
+	}
+
+	confirmed, err := confirm(cmd, "Are you sure you want to delete this asset?")
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		fmt.Println("Deletion cancelled.")
+		return nil
+	}
+
+	if err := backpackService.DeleteAsset(ctx, assetID); err != nil {
+		return fmt.Errorf("failed to delete asset: %w", err)
+	}
+
+	return printResult(actionResult{Action: "deleted", ID: assetID}, func() {
+		fmt.Printf("\n✓ Asset #%d deleted successfully\n", assetID)
+	})
+}
+
+func runAssetRevise(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	previousID, err := parseID(args[0], "asset")
+	if err != nil {
+		return err
+	}
+
+	filePath := args[1]
+
+	// Check if file exists
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		return fmt.Errorf("file does not exist: %s", filePath)
+	}
+
+	name, _ := cmd.Flags().GetString("name")
+	versionLabel, _ := cmd.Flags().GetString("version-label")
+	releaseDate, err := parseReleaseDate(cmd)
+	if err != nil {
+		return err
+	}
+
+	printProgress("\nAdding revision of asset #%d...\n", previousID)
+
+	asset, err := backpackService.AddAssetRevision(ctx, previousID, name, filePath, versionLabel, releaseDate)
+	if err != nil {
+		return fmt.Errorf("failed to add revision: %w", err)
+	}
+
+	return printResult(asset, func() {
+		fmt.Printf("\n✓ Revision added successfully\n")
+		fmt.Printf("  ID: %d\n", asset.ID)
+		fmt.Printf("  Name: %s\n", asset.Name)
+		fmt.Printf("  Type: %s\n", asset.Type)
+		if asset.VersionLabel != "" {
+			fmt.Printf("  Version: %s\n", asset.VersionLabel)
+		}
+		fmt.Printf("  Supersedes: #%d\n", previousID)
+	})
+}
+
+func runAssetHistory(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	assetID, err := parseID(args[0], "asset")
+	if err != nil {
+		return err
+	}
+
+	history, err := backpackService.GetAssetHistory(ctx, assetID)
+	if err != nil {
+		return fmt.Errorf("failed to get asset history: %w", err)
+	}
+
+	return printResult(history, func() {
+		fmt.Printf("\n=== Revisions of Asset #%d ===\n\n", assetID)
+
+		for _, asset := range history {
+			marker := " "
+			if asset.IsCurrent {
+				marker = "*"
+			}
+
+			version := asset.VersionLabel
+			if version == "" {
+				version = "-"
+			}
+
+			released := "-"
+			if asset.ReleaseDate != nil {
+				released = asset.ReleaseDate.Format("2006-01-02")
+			}
+
+			fmt.Printf("%s #%-5d %-10s %-12s %s\n", marker, asset.ID, version, released, asset.Name)
+		}
+
+		fmt.Println("\n* current revision")
+	})
+}
+
+func runAssetSetCurrent(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	assetID, err := parseID(args[0], "asset")
+	if err != nil {
+		return err
+	}
+
+	if err := backpackService.SetCurrentRevision(ctx, assetID); err != nil {
+		return fmt.Errorf("failed to set current revision: %w", err)
+	}
+
+	return printResult(actionResult{Action: "current", ID: assetID}, func() {
+		fmt.Printf("\n✓ Asset #%d is now the current revision\n", assetID)
+	})
+}
+
+// parseReleaseDate reads the optional --release-date flag
+func parseReleaseDate(cmd *cobra.Command) (*time.Time, error) {
+	value, _ := cmd.Flags().GetString("release-date")
+	if value == "" {
+		return nil, nil
+	}
+
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, usageError{fmt.Errorf("invalid release date %q (expected YYYY-MM-DD): %w", value, err)}
+	}
+
+	return &date, nil
+}
+
+// Photo commands implementation
+
+func runPhotoAdd(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	itemID, err := parseID(args[0], "item")
+	if err != nil {
+		return err
+	}
+
+	filePath := args[1]
+
+	// Check if file exists
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		return fmt.Errorf("file does not exist: %s", filePath)
+	}
+
+	photo, err := backpackService.AddPhoto(ctx, itemID, filePath)
+	if err != nil {
+		return fmt.Errorf("failed to add photo: %w", err)
+	}
+
+	return printResult(photo, func() {
+		fmt.Printf("\n✓ Photo added successfully\n")
+		fmt.Printf("  ID: %d\n", photo.ID)
+		fmt.Printf("  Name: %s\n", photo.Name)
+		fmt.Printf("  Size: %s\n", formatFileSize(photo.FileSize))
+	})
+}
+
+func runPhotoList(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	itemID, err := parseID(args[0], "item")
+	if err != nil {
+		return err
+	}
+
+	photos, err := backpackService.GetItemPhotos(ctx, itemID)
+	if err != nil {
+		return fmt.Errorf("failed to get photos: %w", err)
+	}
+
+	return printResult(photos, func() {
+		fmt.Printf("\n=== Photos for Item #%d ===\n\n", itemID)
+
+		if len(photos) == 0 {
+			fmt.Println("No photos attached to this item.")
+			return
+		}
+
+		for _, photo := range photos {
+			fmt.Printf("ID: %d\n", photo.ID)
+			fmt.Printf("  Name: %s\n", photo.Name)
+			fmt.Printf("  Size: %s\n", formatFileSize(photo.FileSize))
+			fmt.Printf("  Path: %s\n", photo.FilePath)
+			fmt.Println()
+		}
+	})
+}
+
+func runPhotoImportLegacy(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	imported, err := backpackService.ImportLegacyPhotos(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to import photos: %w", err)
+	}
+
+	result := struct {
+		Imported int `json:"imported"`
+	}{imported}
+	return printResult(result, func() {
+		fmt.Printf("\n✓ %d photo(s) imported into the asset store\n", imported)
+	})
+}
+
+// Asset type commands implementation
+
+func runAssetTypeList(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	types, err := backpackService.GetAssetTypes(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get asset types: %w", err)
+	}
+
+	return printResult(types, func() {
+		fmt.Printf("\n=== Asset Types (%d) ===\n\n", len(types))
+
+		for _, t := range types {
+			fmt.Printf("%s - %s\n", t.Name, t.Label)
+			if len(t.MimePatterns) > 0 {
+				fmt.Printf("  MIME:    %s\n", strings.Join(t.MimePatterns, ", "))
+			}
+			if !t.CountsForHealth {
+				fmt.Printf("  Health:  not counted\n")
+			}
+			if t.IsBuiltin {
+				fmt.Printf("  Built-in\n")
+			}
+		}
+	})
+}
+
+func runAssetTypeAdd(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	mimePatterns, _ := cmd.Flags().GetStringSlice("mime")
+	noHealth, _ := cmd.Flags().GetBool("no-health")
+
+	def := &models.AssetTypeDefinition{
+		Name:            models.AssetType(args[0]),
+		Label:           args[1],
+		MimePatterns:    mimePatterns,
+		CountsForHealth: !noHealth,
+	}
+
+	if err := backpackService.SaveAssetType(ctx, def); err != nil {
+		return fmt.Errorf("failed to save asset type: %w", err)
+	}
+
+	return printResult(def, func() {
+		fmt.Printf("\n✓ Asset type '%s' saved\n", def.Name)
+	})
+}
+
+func runAssetTypeRemove(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	if err := backpackService.DeleteAssetType(ctx, models.AssetType(args[0])); err != nil {
+		return fmt.Errorf("failed to remove asset type: %w", err)
+	}
+
+	return printResult(actionResult{Action: "removed", Name: args[0]}, func() {
+		fmt.Printf("\n✓ Asset type '%s' removed\n", args[0])
+	})
+}
+
+// Category commands implementation
+
+func runCategoryList(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	categories, err := backpackService.GetCategories(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get categories: %w", err)
+	}
+
+	return printResult(categories, func() {
+		fmt.Printf("\n=== Categories (%d) ===\n\n", len(categories))
+
+		children := make(map[int64][]models.Category)
+		var roots []models.Category
+		for _, c := range categories {
+			if c.ParentID == nil {
+				roots = append(roots, c)
+			} else {
+				children[*c.ParentID] = append(children[*c.ParentID], c)
+			}
+		}
+
+		var printTree func(categories []models.Category, depth int)
+		printTree = func(categories []models.Category, depth int) {
+			for _, c := range categories {
+				fmt.Printf("%s%s", strings.Repeat("  ", depth), c.Name)
+				if len(c.Synonyms) > 0 {
+					fmt.Printf(" (aka %s)", strings.Join(c.Synonyms, ", "))
+				}
+				fmt.Println()
+				printTree(children[c.ID], depth+1)
+			}
+		}
+		printTree(roots, 0)
+	})
+}
+
+func runCategoryAdd(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	parent, _ := cmd.Flags().GetString("parent")
+
+	category, err := backpackService.CreateCategory(ctx, args[0], parent)
+	if err != nil {
+		return fmt.Errorf("failed to add category: %w", err)
+	}
+
+	return printResult(category, func() {
+		fmt.Printf("\n✓ Category '%s' added\n", category.Name)
+	})
+}
+
+func runCategoryMove(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	parent := ""
+	if len(args) > 1 {
+		parent = args[1]
+	}
+
+	if err := backpackService.SetCategoryParent(ctx, args[0], parent); err != nil {
+		return fmt.Errorf("failed to move category: %w", err)
+	}
+
+	return printResult(actionResult{Action: "moved", Name: args[0]}, func() {
+		if parent == "" {
+			fmt.Printf("\n✓ Category '%s' moved to the top level\n", args[0])
+		} else {
+			fmt.Printf("\n✓ Category '%s' moved under '%s'\n", args[0], parent)
+		}
+	})
+}
+
+func runCategoryRename(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	if err := backpackService.RenameCategory(ctx, args[0], args[1]); err != nil {
+		return fmt.Errorf("failed to rename category: %w", err)
+	}
+
+	return printResult(actionResult{Action: "renamed", Name: args[1]}, func() {
+		fmt.Printf("\n✓ Category '%s' renamed to '%s'\n", args[0], args[1])
+	})
+}
+
+func runCategoryAlias(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	if err := backpackService.AddCategorySynonym(ctx, args[0], args[1]); err != nil {
+		return fmt.Errorf("failed to add synonym: %w", err)
+	}
+
+	return printResult(actionResult{Action: "aliased", Name: args[1]}, func() {
+		fmt.Printf("\n✓ '%s' now resolves to category '%s'\n", args[1], args[0])
+	})
+}
+
+func runCategoryUnalias(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	if err := backpackService.RemoveCategorySynonym(ctx, args[0]); err != nil {
+		return fmt.Errorf("failed to remove synonym: %w", err)
+	}
+
+	return printResult(actionResult{Action: "unaliased", Name: args[0]}, func() {
+		fmt.Printf("\n✓ Synonym '%s' removed\n", args[0])
+	})
+}
+
+func runCategoryMerge(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	if err := backpackService.MergeCategory(ctx, args[0], args[1]); err != nil {
+		return fmt.Errorf("failed to merge category: %w", err)
+	}
+
+	return printResult(actionResult{Action: "merged", Name: args[0]}, func() {
+		fmt.Printf("\n✓ Category '%s' merged into '%s'\n", args[0], args[1])
+	})
+}
+
+func runCategoryRemove(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	if err := backpackService.DeleteCategory(ctx, args[0]); err != nil {
+		return fmt.Errorf("failed to remove category: %w", err)
+	}
+
+	return printResult(actionResult{Action: "removed", Name: args[0]}, func() {
+		fmt.Printf("\n✓ Category '%s' removed\n", args[0])
+	})
+}
+
+func runAssetShare(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	assetID, err := parseID(args[0], "asset")
+	if err != nil {
+		return err
+	}
+
+	asset, err := backpackService.ShareAsset(ctx, assetID)
+	if err != nil {
+		return fmt.Errorf("failed to share asset: %w", err)
+	}
+
+	return printResult(asset, func() {
+		fmt.Printf("\n✓ Asset '%s' shared with product model #%d\n", asset.Name, *asset.ProductModelID)
+	})
+}
+
+func runAssetSave(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	assetID, err := parseID(args[0], "asset")
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(args[1])
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	asset, err := backpackService.SaveAsset(ctx, assetID, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(args[1])
+		return fmt.Errorf("failed to save asset: %w", err)
+	}
+
+	result := struct {
+		Asset *models.Asset `json:"asset"`
+		Path  string        `json:"path"`
+	}{asset, args[1]}
+
+	return printResult(result, func() {
+		fmt.Printf("✓ Asset '%s' saved to %s\n", asset.Name, args[1])
+	})
+}
+
+// Tag commands implementation
+
+func runTagList(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	tags, err := backpackService.GetTags(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get tags: %w", err)
+	}
+
+	return printResult(tags, func() {
+		if len(tags) == 0 {
+			fmt.Println("No tags in use.")
+			return
+		}
+
+		fmt.Printf("\n=== Tags (%d) ===\n\n", len(tags))
+		for _, tag := range tags {
+			fmt.Println(tag)
+		}
+	})
+}
+
+// Product model commands implementation
+
+func runModelList(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	productModels, err := backpackService.GetProductModels(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get product models: %w", err)
+	}
+
+	return printResult(productModels, func() {
+		if len(productModels) == 0 {
+			fmt.Println("No product models yet. Set the brand and model of an item to create one.")
+			return
+		}
+
+		fmt.Printf("\n=== Product Models (%d) ===\n\n", len(productModels))
+		for _, m := range productModels {
+			fmt.Printf("#%-5d %s %s\n", m.ID, m.Brand, m.Model)
+			for _, alias := range m.Aliases {
+				fmt.Printf("       also: %s %s\n", alias.Brand, alias.Model)
+			}
+		}
+	})
+}
+
+func runModelShow(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	id, err := parseID(args[0], "product model")
+	if err != nil {
+		return err
+	}
+
+	productModel, err := backpackService.GetProductModel(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get product model: %w", err)
+	}
+
+	return printResult(productModel, func() {
+		m := productModel.ProductModel
+		fmt.Printf("\n=== Product Model #%d: %s %s ===\n\n", m.ID, m.Brand, m.Model)
+		for _, alias := range m.Aliases {
+			fmt.Printf("Alias: %s %s\n", alias.Brand, alias.Model)
+		}
+		fmt.Printf("Items: %d\n", productModel.ItemCount)
+
+		fmt.Printf("\nShared documentation (%d):\n", len(productModel.Assets))
+		for _, asset := range productModel.Assets {
+			status := ""
+			if !asset.IsCurrent {
+				status = " (superseded)"
+			}
+			fmt.Printf("  #%-5d %-15s %s%s\n", asset.ID, asset.Type, asset.Name, status)
+		}
+	})
+}
+
+func runModelAlias(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	id, err := parseID(args[0], "product model")
+	if err != nil {
+		return err
+	}
+
+	if err := backpackService.AddProductModelAlias(ctx, id, args[1], args[2]); err != nil {
+		return fmt.Errorf("failed to add alias: %w", err)
+	}
+
+	return printResult(actionResult{Action: "aliased", ID: id}, func() {
+		fmt.Printf("\n✓ '%s %s' now resolves to product model #%d\n", args[1], args[2], id)
+	})
+}
+
+func runModelUnalias(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	if err := backpackService.RemoveProductModelAlias(ctx, args[0], args[1]); err != nil {
+		return fmt.Errorf("failed to remove alias: %w", err)
+	}
+
+	return printResult(actionResult{Action: "unaliased", Name: args[0] + " " + args[1]}, func() {
+		fmt.Printf("\n✓ Alias '%s %s' removed\n", args[0], args[1])
+	})
+}
+
+// Repair log commands implementation
+
+func runRepairAdd(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	itemID, err := parseID(args[0], "item")
+	if err != nil {
+		return err
+	}
+
+	event := models.RepairEvent{ItemID: itemID}
+	event.Diagnosis, _ = cmd.Flags().GetString("diagnosis")
+	event.Action, _ = cmd.Flags().GetString("action")
+	event.PartsReplaced, _ = cmd.Flags().GetString("parts")
+	event.PerformedBy, _ = cmd.Flags().GetString("by")
+	event.AssetIDs, _ = cmd.Flags().GetInt64Slice("asset")
+
+	if cost, _ := cmd.Flags().GetString("cost"); cost != "" {
+		event.CostCents, err = parseCost(cost)
+		if err != nil {
+			return err
+		}
+	}
+
+	if date, _ := cmd.Flags().GetString("date"); date != "" {
+		event.PerformedAt, err = time.ParseInLocation("2006-01-02", date, time.Local)
+		if err != nil {
+			return usageError{fmt.Errorf("invalid date %q (expected YYYY-MM-DD): %w", date, err)}
+		}
+	}
+
+	if err := backpackService.AddRepairEvent(ctx, &event); err != nil {
+		return fmt.Errorf("failed to record repair: %w", err)
+	}
+
+	return printResult(event, func() {
+		fmt.Printf("\n✓ Repair #%d recorded for item #%d\n", event.ID, itemID)
+	})
+}
+
+func runRepairList(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	itemID, err := parseID(args[0], "item")
+	if err != nil {
+		return err
+	}
+
+	repairs, err := backpackService.GetItemRepairs(ctx, itemID)
+	if err != nil {
+		return fmt.Errorf("failed to get repairs: %w", err)
+	}
+
+	return printResult(repairs, func() {
+		if len(repairs) == 0 {
+			fmt.Println("No repairs recorded for this item.")
+			return
+		}
+
+		var total int64
+		fmt.Printf("\n=== Repairs of Item #%d (%d) ===\n", itemID, len(repairs))
+		for _, repair := range repairs {
+			fmt.Printf("\n#%-5d %s", repair.ID, repair.PerformedAt.Format("2006-01-02"))
+			if repair.PerformedBy != "" {
+				fmt.Printf(" by %s", repair.PerformedBy)
+			}
+			fmt.Println()
+
+			if repair.Diagnosis != "" {
+				fmt.Printf("       Diagnosis: %s\n", repair.Diagnosis)
+			}
+			if repair.Action != "" {
+				fmt.Printf("       Action:    %s\n", repair.Action)
+			}
+			if repair.PartsReplaced != "" {
+				fmt.Printf("       Parts:     %s\n", repair.PartsReplaced)
+			}
+			if repair.CostCents > 0 {
+				fmt.Printf("       Cost:      %s\n", formatCost(repair.CostCents))
+			}
+			if len(repair.AssetIDs) > 0 {
+				ids := make([]string, len(repair.AssetIDs))
+				for i, id := range repair.AssetIDs {
+					ids[i] = fmt.Sprintf("#%d", id)
+				}
+				fmt.Printf("       Assets:    %s\n", strings.Join(ids, ", "))
+			}
+			total += repair.CostCents
+		}
+
+		fmt.Printf("\nTotal cost: %s\n", formatCost(total))
+	})
+}
+
+func runRepairDelete(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	id, err := parseID(args[0], "repair")
+	if err != nil {
+		return err
+	}
+
+	if err := backpackService.DeleteRepairEvent(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete repair: %w", err)
+	}
+
+	return printResult(actionResult{Action: "deleted", ID: id}, func() {
+		fmt.Printf("\n✓ Repair #%d deleted\n", id)
+	})
+}
+
+// parseCost reads an amount such as 12.50 or 12,50 as cents
+func parseCost(value string) (int64, error) {
+	amount, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(value), ",", "."), 64)
+	if err != nil || amount < 0 {
+		return 0, usageError{fmt.Errorf("invalid cost %q", value)}
+	}
+	return int64(math.Round(amount * 100)), nil
+}
+
+// Maintenance commands implementation
+
+func runMaintenanceAdd(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	itemID, err := parseID(args[0], "item")
+	if err != nil {
+		return err
+	}
+
+	schedule := models.MaintenanceSchedule{ItemID: itemID, Task: args[1]}
+	schedule.IntervalDays, _ = cmd.Flags().GetInt64("every-days")
+	schedule.IntervalUsage, _ = cmd.Flags().GetInt64("every-usage")
+	schedule.UsageUnit, _ = cmd.Flags().GetString("unit")
+
+	if err := backpackService.AddMaintenanceSchedule(ctx, &schedule); err != nil {
+		return fmt.Errorf("failed to schedule maintenance: %w", err)
+	}
+
+	return printResult(schedule, func() {
+		fmt.Printf("\n✓ Maintenance #%d scheduled: %s (%s)\n", schedule.ID, schedule.Task, formatMaintenanceInterval(schedule))
+	})
+}
+
+func runMaintenanceList(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	itemID, err := parseID(args[0], "item")
+	if err != nil {
+		return err
+	}
+
+	tasks, err := backpackService.GetItemMaintenance(ctx, itemID)
+	if err != nil {
+		return fmt.Errorf("failed to get maintenance: %w", err)
+	}
+
+	return printResult(tasks, func() {
+		if len(tasks) == 0 {
+			fmt.Println("No maintenance scheduled for this item.")
+			return
+		}
+
+		fmt.Printf("\n=== Maintenance of Item #%d (%d) ===\n\n", itemID, len(tasks))
+		for _, task := range tasks {
+			printMaintenanceTask(task)
+		}
+
+		fmt.Println("\n! overdue")
+	})
+}
+
+func runMaintenanceDue(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	within, _ := cmd.Flags().GetInt64("within")
+	tasks, err := backpackService.GetDueMaintenance(ctx, time.Duration(within)*24*time.Hour)
+	if err != nil {
+		return fmt.Errorf("failed to get due maintenance: %w", err)
+	}
+
+	return printResult(tasks, func() {
+		if len(tasks) == 0 {
+			fmt.Println("Nothing to do, all maintenance is up to date.")
+			return
+		}
+
+		fmt.Printf("\n=== Maintenance Due (%d) ===\n\n", len(tasks))
+		for _, task := range tasks {
+			fmt.Printf("Item #%d %s\n", task.Schedule.ItemID, task.ItemName)
+			printMaintenanceTask(task)
+		}
+
+		fmt.Println("\n! overdue")
+	})
+}
+
+func runMaintenanceDone(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	id, err := parseID(args[0], "maintenance schedule")
+	if err != nil {
+		return err
+	}
+
+	var doneAt time.Time
+	if date, _ := cmd.Flags().GetString("date"); date != "" {
+		doneAt, err = time.ParseInLocation("2006-01-02", date, time.Local)
+		if err != nil {
+			return usageError{fmt.Errorf("invalid date %q (expected YYYY-MM-DD): %w", date, err)}
+		}
+	}
+
+	task, err := backpackService.CompleteMaintenance(ctx, id, doneAt)
+	if err != nil {
+		return fmt.Errorf("failed to complete maintenance: %w", err)
+	}
+
+	return printResult(task, func() {
+		fmt.Printf("\n✓ '%s' done\n", task.Schedule.Task)
+		if task.NextDueAt != nil {
+			fmt.Printf("  Next: %s\n", task.NextDueAt.Format("2006-01-02"))
+		}
+		if task.NextDueUsage != nil {
+			fmt.Printf("  Next: at %d %s\n", *task.NextDueUsage, task.Schedule.UsageUnit)
+		}
+	})
+}
+
+func runMaintenanceUsage(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	itemID, err := parseID(args[0], "item")
+	if err != nil {
+		return err
+	}
+
+	value, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return usageError{fmt.Errorf("invalid usage value: %w", err)}
+	}
+
+	unit, _ := cmd.Flags().GetString("unit")
+	if err := backpackService.RecordUsage(ctx, itemID, unit, value); err != nil {
+		return fmt.Errorf("failed to record usage: %w", err)
+	}
+
+	usage := struct {
+		ItemID int64  `json:"item_id"`
+		Unit   string `json:"unit"`
+		Value  int64  `json:"value"`
+	}{itemID, unit, value}
+
+	return printResult(usage, func() {
+		fmt.Printf("\n✓ Item #%d is at %d %s\n", itemID, value, unit)
+	})
+}
+
+func runMaintenanceRemove(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	id, err := parseID(args[0], "maintenance schedule")
+	if err != nil {
+		return err
+	}
+
+	if err := backpackService.DeleteMaintenanceSchedule(ctx, id); err != nil {
+		return fmt.Errorf("failed to remove maintenance: %w", err)
+	}
+
+	return printResult(actionResult{Action: "removed", ID: id}, func() {
+		fmt.Printf("\n✓ Maintenance #%d removed\n", id)
+	})
+}
+
+func printMaintenanceTask(task models.MaintenanceTask) {
+	marker := " "
+	if task.Due {
+		marker = "!"
+	}
+
+	fmt.Printf("%s #%-5d %s (%s)\n", marker, task.Schedule.ID, task.Schedule.Task, formatMaintenanceInterval(task.Schedule))
+	if task.NextDueAt != nil {
+		fmt.Printf("         Next: %s\n", task.NextDueAt.Format("2006-01-02"))
+	}
+	if task.NextDueUsage != nil {
+		fmt.Printf("         Next: at %d %s (now %d)\n", *task.NextDueUsage, task.Schedule.UsageUnit, task.CurrentUsage)
+	}
+}
+
+func formatMaintenanceInterval(schedule models.MaintenanceSchedule) string {
+	intervals := []string{}
+	if schedule.IntervalDays > 0 {
+		intervals = append(intervals, fmt.Sprintf("every %d days", schedule.IntervalDays))
+	}
+	if schedule.IntervalUsage > 0 {
+		intervals = append(intervals, fmt.Sprintf("every %d %s", schedule.IntervalUsage, schedule.UsageUnit))
+	}
+	return strings.Join(intervals, " or ")
+}
+
+// Warranty commands implementation
+
+func runWarrantySet(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	itemID, err := parseID(args[0], "item")
+	if err != nil {
+		return err
+	}
+
+	warranty := &models.Warranty{}
+	warranty.DurationMonths, _ = cmd.Flags().GetInt64("months")
+	warranty.Provider, _ = cmd.Flags().GetString("provider")
+	if receipt, _ := cmd.Flags().GetInt64("receipt"); receipt != 0 {
+		warranty.ReceiptAssetID = &receipt
+	}
+	if warranty.DurationMonths <= 0 {
+		return usageError{fmt.Errorf("invalid warranty duration %d (use --months)", warranty.DurationMonths)}
+	}
+
+	if purchased, _ := cmd.Flags().GetString("purchased"); purchased != "" {
+		purchaseDate, err := time.ParseInLocation("2006-01-02", purchased, time.Local)
+		if err != nil {
+			return usageError{fmt.Errorf("invalid date %q (expected YYYY-MM-DD): %w", purchased, err)}
+		}
+
+		// Store the purchase date along with the warranty
+		item, err := backpackService.GetItem(ctx, itemID)
+		if err != nil {
+			return fmt.Errorf("failed to get item: %w", err)
+		}
+		item.PurchaseDate = &purchaseDate
+		item.Warranty = warranty
+		if err := backpackService.UpdateItem(ctx, item); err != nil {
+			return fmt.Errorf("failed to set warranty: %w", err)
+		}
+		warranty = item.Warranty
+	} else if err := backpackService.SetItemWarranty(ctx, itemID, warranty); err != nil {
+		return fmt.Errorf("failed to set warranty: %w", err)
+	}
+
+	return printResult(warranty, func() {
+		fmt.Printf("\n✓ Warranty of item #%d: %s\n", itemID, formatWarranty(warranty))
+	})
+}
+
+func runWarrantyExpiring(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	within, _ := cmd.Flags().GetInt64("within")
+	items, err := backpackService.GetExpiringWarranties(ctx, time.Duration(within)*24*time.Hour)
+	if err != nil {
+		return fmt.Errorf("failed to get expiring warranties: %w", err)
+	}
+
+	return printResult(items, func() {
+		if len(items) == 0 {
+			fmt.Printf("No warranty expires in the next %d days.\n", within)
+			return
+		}
+
+		fmt.Printf("\n=== Warranties Expiring Within %d Days (%d) ===\n\n", within, len(items))
+		for _, item := range items {
+			fmt.Printf("#%-5d %s\n", item.ID, item.Name)
+			fmt.Printf("       %s\n", formatWarranty(item.Warranty))
+		}
+	})
+}
+
+func runWarrantyRemove(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	itemID, err := parseID(args[0], "item")
+	if err != nil {
+		return err
+	}
+
+	if err := backpackService.SetItemWarranty(ctx, itemID, nil); err != nil {
+		return fmt.Errorf("failed to remove warranty: %w", err)
+	}
+
+	return printResult(actionResult{Action: "removed", ID: itemID}, func() {
+		fmt.Printf("\n✓ Warranty of item #%d removed\n", itemID)
+	})
+}
+
+func formatWarranty(warranty *models.Warranty) string {
+	text := fmt.Sprintf("%d months", warranty.DurationMonths)
+	if warranty.Provider != "" {
+		text += fmt.Sprintf(" (%s)", warranty.Provider)
+	}
+
+	switch {
+	case warranty.ExpiresAt == nil:
+		text += ", no purchase date"
+	case *warranty.DaysLeft < 0:
+		text += fmt.Sprintf(", expired on %s", warranty.ExpiresAt.Format("2006-01-02"))
+	default:
+		text += fmt.Sprintf(", until %s (%d days left)", warranty.ExpiresAt.Format("2006-01-02"), *warranty.DaysLeft)
+	}
+
+	if warranty.ReceiptAssetID != nil {
+		text += fmt.Sprintf(", receipt: asset #%d", *warranty.ReceiptAssetID)
+	}
+	return text
+}
+
+// Help announcement commands implementation
+
+func runAnnouncementPost(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	announcement := models.Announcement{Kind: models.AnnouncementKind(args[0]), Title: args[1]}
+	announcement.Body, _ = cmd.Flags().GetString("body")
+	announcement.MaxHops, _ = cmd.Flags().GetInt64("hops")
+	days, _ := cmd.Flags().GetInt64("days")
+
+	if err := gossipService.PublishAnnouncement(ctx, &announcement, time.Duration(days)*24*time.Hour); err != nil {
+		return fmt.Errorf("failed to publish announcement: %w", err)
+	}
+
+	return printResult(announcement, func() {
+		fmt.Printf("\n✓ Announcement #%d published: %s\n", announcement.ID, announcement.Title)
+		fmt.Printf("  Spread by the peers until %s, through at most %d peers\n", announcement.ExpiresAt.Local().Format("2006-01-02"), announcement.MaxHops)
+	})
+}
+
+func runAnnouncementList(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	kind, _ := cmd.Flags().GetString("kind")
+	announcements, err := gossipService.GetAnnouncements(ctx, models.AnnouncementKind(kind))
+	if err != nil {
+		return fmt.Errorf("failed to get announcements: %w", err)
+	}
+
+	return printResult(announcements, func() {
+		if len(announcements) == 0 {
+			fmt.Println("No running announcements.")
+			return
+		}
+
+		fmt.Printf("\n=== Announcements (%d) ===\n", len(announcements))
+		for _, announcement := range announcements {
+			label := "Offer"
+			if announcement.Kind == models.AnnouncementRequest {
+				label = "Request"
+			}
+
+			fmt.Printf("\n#%-5d [%s] %s\n", announcement.ID, label, announcement.Title)
+			if announcement.Body != "" {
+				fmt.Printf("       %s\n", announcement.Body)
+			}
+			if announcement.Own {
+				fmt.Printf("       Published here, until %s\n", announcement.ExpiresAt.Local().Format("2006-01-02"))
+			} else {
+				fmt.Printf("       By %s, %d hop(s) away, until %s\n", announcement.AuthorName, announcement.Hops, announcement.ExpiresAt.Local().Format("2006-01-02"))
+			}
+		}
+	})
+}
+
+func runAnnouncementWithdraw(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	id, err := parseID(args[0], "announcement")
+	if err != nil {
+		return err
+	}
+
+	if err := gossipService.WithdrawAnnouncement(ctx, id); err != nil {
+		return fmt.Errorf("failed to withdraw announcement: %w", err)
+	}
+
+	return printResult(actionResult{Action: "withdrawn", ID: id}, func() {
+		fmt.Printf("\n✓ Announcement #%d withdrawn\n", id)
+	})
+}
+
+// Loan commands implementation
+
+func runLoanOut(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	itemID, err := parseID(args[0], "item")
+	if err != nil {
+		return err
+	}
+
+	loan := models.Loan{ItemID: &itemID}
+	if len(args) > 1 {
+		loan.BorrowerName = args[1]
+	}
+	loan.BorrowerPeerID, _ = cmd.Flags().GetString("peer")
+	loan.Notes, _ = cmd.Flags().GetString("notes")
+	if loan.BorrowerName == "" && loan.BorrowerPeerID == "" {
+		return usageError{errors.New("a borrower name or --peer is required")}
+	}
+
+	due, _ := cmd.Flags().GetString("due")
+	days, _ := cmd.Flags().GetInt64("days")
+	switch {
+	case due != "":
+		dueAt, err := time.ParseInLocation("2006-01-02", due, time.Local)
+		if err != nil {
+			return usageError{fmt.Errorf("invalid date %q (expected YYYY-MM-DD): %w", due, err)}
+		}
+		loan.DueAt = &dueAt
+	case days > 0:
+		dueAt := time.Now().AddDate(0, 0, int(days))
+		loan.DueAt = &dueAt
+	}
+
+	if err := backpackService.LendItem(ctx, &loan); err != nil {
+		return fmt.Errorf("failed to lend item: %w", err)
+	}
+
+	return printResult(loan, func() {
+		fmt.Printf("\n✓ Loan #%d: %s\n", loan.ID, formatLoan(&loan))
+		if loan.BorrowerPeerID != "" {
+			fmt.Println("  The borrower's instance will receive it on its next sync")
+		}
+	})
+}
+
+func runLoanReturn(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	id, err := parseID(args[0], "loan")
+	if err != nil {
+		return err
+	}
+
+	loan, err := backpackService.ReturnLoan(ctx, id, time.Time{})
+	if err != nil {
+		return fmt.Errorf("failed to return loan: %w", err)
+	}
+
+	return printResult(loan, func() {
+		fmt.Printf("\n✓ %s returned by %s\n", loan.ItemName, loan.BorrowerName)
+	})
+}
+
+func runLoanList(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	all, _ := cmd.Flags().GetBool("all")
+	overdue, _ := cmd.Flags().GetBool("overdue")
+
+	var loans []models.Loan
+	var err error
+	if overdue {
+		loans, err = backpackService.GetOverdueLoans(ctx)
+	} else {
+		loans, err = backpackService.GetLoans(ctx, all)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get loans: %w", err)
+	}
+
+	return printResult(loans, func() {
+		if len(loans) == 0 {
+			if overdue {
+				fmt.Println("No overdue loans.")
+			} else {
+				fmt.Println("No loans.")
+			}
+			return
+		}
+
+		fmt.Printf("\n=== Loans (%d) ===\n\n", len(loans))
+		for _, loan := range loans {
+			fmt.Printf("#%-5d %s\n", loan.ID, loan.ItemName)
+			fmt.Printf("       %s\n", formatLoan(&loan))
+			if loan.Notes != "" {
+				fmt.Printf("       %s\n", loan.Notes)
+			}
+		}
+	})
+}
+
+func formatLoan(loan *models.Loan) string {
+	var text string
+	if loan.Direction == models.LoanIn {
+		text = fmt.Sprintf("borrowed from %s since %s", loan.LenderName, loan.LentAt.Format("2006-01-02"))
+	} else {
+		text = fmt.Sprintf("lent to %s since %s", loan.BorrowerName, loan.LentAt.Format("2006-01-02"))
+	}
+
+	switch {
+	case loan.ReturnedAt != nil:
+		text += fmt.Sprintf(", returned on %s", loan.ReturnedAt.Format("2006-01-02"))
+	case loan.Overdue:
+		text += fmt.Sprintf(", OVERDUE since %s", loan.DueAt.Format("2006-01-02"))
+	case loan.DueAt != nil:
+		text += fmt.Sprintf(", due on %s", loan.DueAt.Format("2006-01-02"))
+	}
+	return text
+}
+
+// Spare parts commands implementation
+
+func runPartAdd(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	part := models.Part{Name: args[0]}
+	part.Reference, _ = cmd.Flags().GetString("ref")
+	part.Quantity, _ = cmd.Flags().GetInt64("qty")
+	part.MinQuantity, _ = cmd.Flags().GetInt64("min")
+	part.Location, _ = cmd.Flags().GetString("location")
+	part.Notes, _ = cmd.Flags().GetString("notes")
+	part.ItemIDs, _ = cmd.Flags().GetInt64Slice("item")
+	part.ProductModelIDs, _ = cmd.Flags().GetInt64Slice("model")
+	part.AssetIDs, _ = cmd.Flags().GetInt64Slice("stl")
+
+	if err := backpackService.CreatePart(ctx, &part); err != nil {
+		return fmt.Errorf("failed to add part: %w", err)
+	}
+
+	return printResult(part, func() {
+		fmt.Printf("\n✓ Part #%d added: %s (%d in stock)\n", part.ID, part.Name, part.Quantity)
+	})
+}
+
+func runPartList(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	low, _ := cmd.Flags().GetBool("low")
+	itemID, _ := cmd.Flags().GetInt64("item")
+
+	var parts []models.Part
+	var err error
+	switch {
+	case itemID != 0:
+		parts, err = backpackService.GetItemParts(ctx, itemID)
+	case low:
+		parts, err = backpackService.GetLowStockParts(ctx)
+	default:
+		parts, err = backpackService.GetParts(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get parts: %w", err)
+	}
+
+	return printResult(parts, func() {
+		if len(parts) == 0 {
+			fmt.Println("No spare parts found.")
+			return
+		}
+
+		fmt.Printf("\n=== Spare Parts (%d) ===\n\n", len(parts))
+		for _, part := range parts {
+			marker := " "
+			if part.Quantity < part.MinQuantity {
+				marker = "!"
+			}
+
+			fmt.Printf("%s #%-5d %s", marker, part.ID, part.Name)
+			if part.Reference != "" {
+				fmt.Printf(" [%s]", part.Reference)
+			}
+			fmt.Printf(" - %d in stock", part.Quantity)
+			if part.Location != "" {
+				fmt.Printf(", %s", part.Location)
+			}
+			fmt.Println()
+		}
+
+		fmt.Println("\n! low on stock")
+	})
+}
+
+func runPartShow(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	id, err := parseID(args[0], "part")
+	if err != nil {
+		return err
+	}
+
+	part, err := backpackService.GetPart(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get part: %w", err)
+	}
+
+	return printResult(part, func() {
+		fmt.Printf("\n=== Part #%d ===\n", part.ID)
+		fmt.Printf("Name:      %s\n", part.Name)
+		if part.Reference != "" {
+			fmt.Printf("Reference: %s\n", part.Reference)
+		}
+		fmt.Printf("Stock:     %d (min %d)\n", part.Quantity, part.MinQuantity)
+		if part.Location != "" {
+			fmt.Printf("Location:  %s\n", part.Location)
+		}
+		if part.Notes != "" {
+			fmt.Printf("Notes:     %s\n", part.Notes)
+		}
+
+		for _, itemID := range part.ItemIDs {
+			item, err := backpackService.GetItem(ctx, itemID)
+			if err != nil {
+				continue
+			}
+			fmt.Printf("Fits item:  #%d %s\n", item.ID, item.Name)
+		}
+		for _, productModelID := range part.ProductModelIDs {
+			productModel, err := backpackService.GetProductModel(ctx, productModelID)
+			if err != nil {
+				continue
+			}
+			fmt.Printf("Fits model: #%d %s %s\n", productModel.ProductModel.ID, productModel.ProductModel.Brand, productModel.ProductModel.Model)
+		}
+		for _, assetID := range part.AssetIDs {
+			fmt.Printf("STL:        asset #%d\n", assetID)
+		}
+	})
+}
+
+func runPartStock(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	id, err := parseID(args[0], "part")
+	if err != nil {
+		return err
+	}
+
+	delta, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return usageError{fmt.Errorf("invalid quantity: %w", err)}
+	}
+
+	part, err := backpackService.AdjustPartStock(ctx, id, delta)
+	if err != nil {
+		return fmt.Errorf("failed to adjust stock: %w", err)
+	}
+
+	return printResult(part, func() {
+		fmt.Printf("\n✓ %s: %d in stock\n", part.Name, part.Quantity)
+		if part.Quantity < part.MinQuantity {
+			fmt.Printf("  Low on stock (min %d)\n", part.MinQuantity)
+		}
+	})
+}
+
+func runPartRemove(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	id, err := parseID(args[0], "part")
+	if err != nil {
+		return err
+	}
+
+	if err := backpackService.DeletePart(ctx, id); err != nil {
+		return fmt.Errorf("failed to remove part: %w", err)
+	}
+
+	return printResult(actionResult{Action: "removed", ID: id}, func() {
+		fmt.Printf("\n✓ Part #%d removed\n", id)
+	})
+}
+
+// Peer commands implementation
+
+func runPeerList(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	peers, err := gossipService.GetPeers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get peers: %w", err)
+	}
+
+	return printResult(peers, func() {
+		if len(peers) == 0 {
+			fmt.Println("\nNo peers discovered.")
+			return
+		}
+
+		fmt.Printf("\n=== Peers (%d) ===\n\n", len(peers))
+
+		for _, peer := range peers {
+			fmt.Printf("ID: %s\n", peer.ID)
+			fmt.Printf("  Name:      %s\n", peer.Name)
+			fmt.Printf("  Address:   %s\n", peer.Address)
+			fmt.Printf("  Status:    %s\n", peer.Status)
+			if peer.IsTrusted {
+				fmt.Printf("  Trusted:   ✓\n")
+			}
+			if !peer.LastSeen.IsZero() {
+				fmt.Printf("  Last Seen: %s\n", peer.LastSeen.Format("2006-01-02 15:04:05"))
+			}
+			if peer.LastSync != nil {
+				fmt.Printf("  Last Sync: %s\n", peer.LastSync.Format("2006-01-02 15:04:05"))
+			}
+			fmt.Println()
+		}
+	})
+}
+
+func runPeerAdd(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	name := args[0]
+	address := args[1]
+	trusted, _ := cmd.Flags().GetBool("trusted")
+
+	// Generate a unique ID for the peer
+	peerID := fmt.Sprintf("%s.manual", address)
+
+	peer := &models.Peer{
+		ID:        peerID,
+		Name:      name,
+		Address:   address,
+		IsTrusted: trusted,
+	}
+
+	if err := gossipService.AddPeer(ctx, peer); err != nil {
+		return fmt.Errorf("failed to add peer: %w", err)
+	}
+
+	return printResult(peer, func() {
+		fmt.Printf("\n✓ Peer '%s' added successfully\n", name)
+		fmt.Printf("  ID:      %s\n", peerID)
+		fmt.Printf("  Address: %s\n", address)
+		if trusted {
+			fmt.Printf("  Trusted: ✓\n")
+		}
+	})
+}
+
+func runPeerRemove(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	peerID := args[0]
+
+	if yes, _ := cmd.Flags().GetBool("yes"); !yes && !structuredOutput() {
+		fmt.Printf("\n=== Remove Peer ===\n\n")
+		fmt.Printf("Peer ID: %s\n", peerID)
+	}
+
+	confirmed, err := confirm(cmd, "Are you sure you want to remove this peer?")
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		fmt.Println("Removal cancelled.")
+		return nil
+	}
+
+	if err := gossipService.RemovePeer(ctx, peerID); err != nil {
+		return fmt.Errorf("failed to remove peer: %w", err)
+	}
+
+	return printResult(actionResult{Action: "removed", Name: peerID}, func() {
+		fmt.Printf("\n✓ Peer removed successfully\n")
+	})
+}
+
+func runPeerSync(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	peerID := args[0]
+
+	// Get peer info
+	peers, err := gossipService.GetPeers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get peers: %w", err)
+	}
+
+	var peer *models.Peer
+	for _, p := range peers {
+		if p.ID == peerID {
+			peer = &p
+			break
+		}
+	}
+
+	if peer == nil {
+		return fmt.Errorf("peer not found: %s", peerID)
+	}
+
+	printProgress("\n=== Synchronizing with %s ===\n\n", peer.Name)
+
+	result, err := gossipService.SyncPeer(ctx, peerID)
+	if err != nil {
+		return fmt.Errorf("failed to sync with peer: %w", err)
+	}
+
+	return printResult(result, func() {
+		fmt.Printf("✓ Synchronization completed in %d ms\n", result.DurationMs)
+		fmt.Printf("  Items received:         %d\n", result.ItemsReceived)
+		fmt.Printf("  Conflicts:              %d\n", result.Conflicts)
+		fmt.Printf("  Documents received:     %d\n", result.DocumentsReceived)
+		fmt.Printf("  Parts received:         %d\n", result.PartsReceived)
+		fmt.Printf("  Announcements received: %d\n", result.AnnouncementsReceived)
+		fmt.Printf("  Loans received:         %d\n", result.LoansReceived)
+		for _, warning := range result.Warnings {
+			fmt.Printf("  ⚠ %s\n", warning)
+		}
+	})
+}
+
+func runPeerTrust(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	peerID := args[0]
+
+	if err := gossipService.SetPeerTrust(ctx, peerID, true); err != nil {
+		return fmt.Errorf("failed to trust peer: %w", err)
+	}
+
+	return printResult(actionResult{Action: "trusted", Name: peerID}, func() {
+		fmt.Printf("\n✓ Peer '%s' marked as trusted\n", peerID)
+	})
+}
+
+func runPeerUntrust(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	peerID := args[0]
+
+	if err := gossipService.SetPeerTrust(ctx, peerID, false); err != nil {
+		return fmt.Errorf("failed to untrust peer: %w", err)
+	}
+
+	return printResult(actionResult{Action: "untrusted", Name: peerID}, func() {
+		fmt.Printf("\n✓ Trust removed from peer '%s'\n", peerID)
+	})
+}
+
+func runPeerSearch(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	brand, _ := cmd.Flags().GetString("brand")
+	model, _ := cmd.Flags().GetString("model")
+	assetType, _ := cmd.Flags().GetString("type")
+
+	if brand == "" && model == "" {
+		return usageError{errors.New("--brand or --model is required")}
+	}
+
+	results, err := gossipService.SearchPeers(ctx, models.SearchQuery{
+		Brand:     brand,
+		Model:     model,
+		AssetType: models.AssetType(assetType),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to search peers: %w", err)
+	}
+
+	return printResult(results, func() {
+		if len(results) == 0 {
+			fmt.Println("\nNo documentation found on online trusted peers.")
+			return
+		}
+
+		fmt.Printf("\n=== Search Results (%d items) ===\n\n", len(results))
+
+		for _, result := range results {
+			item := result.Item.Item
+			fmt.Printf("Peer: %s (%s)\n", result.PeerName, result.PeerID)
+			fmt.Printf("  Item #%d | %s\n", item.ID, item.Name)
+			fmt.Printf("  Brand: %s | Model: %s\n", item.Brand, item.Model)
+			fmt.Printf("  Health: %s\n", getHealthEmoji(result.Item.Health))
+			for _, asset := range result.Item.Assets {
+				fmt.Printf("    [%d] %s (%s) - %s\n", asset.ID, asset.Name, asset.Type, formatFileSize(asset.FileSize))
+			}
+			fmt.Println()
+		}
+	})
+}
+
+func runPeerFetch(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	peerID := args[0]
+	itemID, err := parseID(args[1], "item")
+	if err != nil {
+		return err
+	}
+
+	printProgress("\nFetching item #%d from %s...\n", itemID, peerID)
+
+	item, err := gossipService.ImportPeerItem(ctx, peerID, itemID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch item: %w", err)
+	}
+
+	return printResult(item, func() {
+		fmt.Printf("\n✓ Item imported with ID: %d\n", item.Item.ID)
+		fmt.Printf("  Name:   %s\n", item.Item.Name)
+		fmt.Printf("  Assets: %d file(s)\n", len(item.Assets))
+	})
+}
+
+// Health commands implementation
+
+func runHealthReport(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	reports, err := backpackService.HealthReport(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to build health report: %w", err)
+	}
+
+	return printResult(reports, func() {
+		if len(reports) == 0 {
+			fmt.Println("\n✓ Every item has its required documents.")
+			return
+		}
+
+		fmt.Printf("\n=== Items Missing Documents (%d) ===\n\n", len(reports))
+
+		for _, report := range reports {
+			fmt.Printf("ID: %d | %s\n", report.Item.ID, report.Item.Name)
+			fmt.Printf("  Category: %s | Brand: %s | Model: %s\n", report.Item.Category, report.Item.Brand, report.Item.Model)
+			fmt.Printf("  Health:   %s\n", getHealthEmoji(report.Health))
+			fmt.Printf("  Missing:  %s\n", joinAssetTypes(report.MissingRequired))
+			if len(report.MissingOptional) > 0 {
+				fmt.Printf("  Optional: %s\n", joinAssetTypes(report.MissingOptional))
+			}
+			fmt.Println()
+		}
+	})
+}
+
+func runHealthRules(cmd *cobra.Command, args []string) error {
+	rules, err := backpackService.GetHealthRules(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to get health rules: %w", err)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].Category < rules[j].Category
+	})
+
+	return printResult(rules, func() {
+		fmt.Printf("\n=== Documentation Rules (%d) ===\n\n", len(rules))
+
+		for _, rule := range rules {
+			category := rule.Category
+			if category == services.DefaultHealthCategory {
+				category = "(default)"
+			}
+			fmt.Printf("%s\n", category)
+			fmt.Printf("  Required: %s\n", joinAssetTypes(rule.Required))
+			if len(rule.Optional) > 0 {
+				fmt.Printf("  Optional: %s\n", joinAssetTypes(rule.Optional))
+			}
+			fmt.Println()
+		}
+	})
+}
+
+// Import commands implementation
+
+func runImportCSV(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	options := models.CSVImportOptions{Columns: make(map[string]string)}
+	options.DryRun, _ = cmd.Flags().GetBool("dry-run")
+
+	mappings, _ := cmd.Flags().GetStringArray("map")
+	for _, mapping := range mappings {
+		field, column, ok := strings.Cut(mapping, "=")
+		if !ok || strings.TrimSpace(field) == "" || strings.TrimSpace(column) == "" {
+			return usageError{fmt.Errorf("invalid mapping %q: expected field=column", mapping)}
+		}
+		options.Columns[strings.TrimSpace(field)] = strings.TrimSpace(column)
+	}
+
+	file, closer, err := openImport(args[0])
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	report, err := backpackService.ImportCSV(ctx, file, options)
+	if err != nil {
+		return fmt.Errorf("failed to import items: %w", err)
+	}
+
+	return printResult(report, func() {
+		fmt.Println()
+		for _, row := range report.Rows {
+			item := row.Item
+			switch row.Action {
+			case models.ImportCreate:
+				fmt.Printf("+ line %-4d %s", row.Line, item.Name)
+				if product := strings.TrimSpace(item.Brand + " " + item.Model); product != "" {
+					fmt.Printf(" (%s)", product)
+				}
+				if item.SerialNumber != "" {
+					fmt.Printf(" S/N %s", item.SerialNumber)
+				}
+				fmt.Println()
+			case models.ImportDuplicate:
+				if row.DuplicateOf != nil {
+					fmt.Printf("= line %-4d %s: already item #%d\n", row.Line, item.Name, *row.DuplicateOf)
+				} else {
+					fmt.Printf("= line %-4d %s: same as line %d\n", row.Line, item.Name, row.DuplicateLine)
+				}
+			case models.ImportInvalid:
+				fmt.Printf("! line %-4d %s: %s\n", row.Line, item.Name, strings.Join(row.Errors, "; "))
+			}
+		}
+
+		if report.DryRun {
+			fmt.Printf("\nDry run: %d item(s) to import, %d duplicate(s), %d invalid row(s)\n", report.Created, report.Duplicates, report.Invalid)
+			return
+		}
+		fmt.Printf("\n✓ %d item(s) imported, %d duplicate(s) and %d invalid row(s) skipped\n", report.Created, report.Duplicates, report.Invalid)
+	})
+}
 
-	// Default name to filename if not provided
-	if assetName == "" {
-		assetName = filepath.Base(filePath)
+// exportEncrypted runs an export through a bundle encrypted with the
+// passphrase of the configuration, or one typed in
+func exportEncrypted(ctx context.Context, w io.Writer, export func(context.Context, io.Writer) (int, error)) (int, error) {
+	bundlePassphrase, err := cfg.Passphrase()
+	if err != nil {
+		return 0, err
+	}
+	if bundlePassphrase == "" {
+		if bundlePassphrase, err = readNewPassphrase(); err != nil {
+			return 0, err
+		}
 	}
 
-	// Validate asset type
-	validTypes := map[string]bool{
-		"manual":         true,
-		"service_manual": true,
-		"exploded_view":  true,
-		"stl":            true,
-		"firmware":       true,
-		"driver":         true,
-		"schematic":      true,
-		"other":          true,
+	bundle, err := services.NewBundleWriter(w, bundlePassphrase)
+	if err != nil {
+		return 0, err
+	}
+	count, err := export(ctx, bundle)
+	if closeErr := bundle.Close(); err == nil {
+		err = closeErr
 	}
+	return count, err
+}
 
-	if !validTypes[assetType] {
-		return fmt.Errorf("invalid asset type: %s", assetType)
+// openImport opens a file to import, decrypting it if it is an encrypted
+// export
+func openImport(path string) (io.Reader, io.Closer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open file: %w", err)
 	}
 
-	fmt.Printf("\nAdding asset to item #%d...\n", itemID)
+	header := make([]byte, 8)
+	n, _ := io.ReadFull(file, header)
+	if !services.IsBundle(header[:n]) {
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			file.Close()
+			return nil, nil, fmt.Errorf("failed to read file: %w", err)
+		}
+		return file, file, nil
+	}
 
-	asset, err := backpackService.AddAsset(ctx, itemID, models.AssetType(assetType), assetName, filePath)
+	info, err := file.Stat()
 	if err != nil {
-		return fmt.Errorf("failed to add asset: %w", err)
+		file.Close()
+		return nil, nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
-	fmt.Printf("\n✓ Asset added successfully\n")
-	fmt.Printf("  ID: %d\n", asset.ID)
-	fmt.Printf("  Name: %s\n", asset.Name)
-	fmt.Printf("  Type: %s\n", asset.Type)
-	fmt.Printf("  Size: %s\n", formatFileSize(asset.FileSize))
-	fmt.Printf("  Hash: %s\n", asset.FileHash[:16]+"...")
+	bundlePassphrase, err := cfg.Passphrase()
+	if err == nil && bundlePassphrase == "" {
+		bundlePassphrase, err = readPassphrase("Passphrase of the export: ")
+	}
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
 
-	return nil
+	reader, err := services.OpenBundle(file, info.Size(), bundlePassphrase)
+	if err != nil {
+		file.Close()
+		return nil, nil, fmt.Errorf("failed to decrypt export: %w", err)
+	}
+	return reader, file, nil
 }
 
-func runAssetList(cmd *cobra.Command, args []string) error {
+func runImportJSON(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
-	itemID, err := strconv.ParseInt(args[0], 10, 64)
+	file, closer, err := openImport(args[0])
 	if err != nil {
-		return fmt.Errorf("invalid item ID: %w", err)
+		return err
 	}
+	defer closer.Close()
 
-	// Get item info
-	item, err := backpackService.GetItem(ctx, itemID)
+	imported, skipped, err := backpackService.ImportJSON(ctx, file)
 	if err != nil {
-		return fmt.Errorf("failed to get item: %w", err)
+		return fmt.Errorf("failed to import items: %w", err)
 	}
 
-	// Get assets
-	assets, err := backpackService.GetItemAssets(ctx, itemID)
-	if err != nil {
-		return fmt.Errorf("failed to get assets: %w", err)
-	}
+	result := struct {
+		Imported int `json:"imported"`
+		Skipped  int `json:"skipped"`
+	}{imported, skipped}
 
-	fmt.Printf("\n=== Assets for Item #%d: %s ===\n\n", itemID, item.Name)
+	return printResult(result, func() {
+		fmt.Printf("✓ %d item(s) imported, %d already in the inventory\n", imported, skipped)
+	})
+}
 
-	if len(assets) == 0 {
-		fmt.Println("No assets attached to this item.")
-		return nil
+// Export commands implementation
+
+func runExport(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	export := backpackService.ExportJSON
+	if cmd.Name() == "csv" {
+		export = backpackService.ExportCSV
 	}
 
-	fmt.Printf("Total: %d file(s)\n\n", len(assets))
+	if encrypt, _ := cmd.Flags().GetBool("encrypt"); encrypt {
+		plain := export
+		export = func(ctx context.Context, w io.Writer) (int, error) {
+			return exportEncrypted(ctx, w, plain)
+		}
+	}
 
-	totalSize := int64(0)
-	for _, asset := range assets {
-		fmt.Printf("ID: %d\n", asset.ID)
-		fmt.Printf("  Name: %s\n", asset.Name)
-		fmt.Printf("  Type: %s\n", asset.Type)
-		fmt.Printf("  Size: %s\n", formatFileSize(asset.FileSize))
-		fmt.Printf("  Path: %s\n", asset.FilePath)
-		fmt.Printf("  Hash: %s\n", asset.FileHash[:16]+"...")
-		fmt.Printf("  Added: %s\n", asset.CreatedAt.Format("2006-01-02 15:04:05"))
-		fmt.Println()
+	// Without file, the export is the output
+	if len(args) == 0 {
+		_, err := export(ctx, os.Stdout)
+		return err
+	}
 
-		totalSize += asset.FileSize
+	file, err := os.Create(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	count, err := export(ctx, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(args[0])
+		return fmt.Errorf("failed to export items: %w", err)
 	}
 
-	fmt.Printf("Total size: %s\n", formatFileSize(totalSize))
+	result := struct {
+		Path  string `json:"path"`
+		Items int    `json:"items"`
+	}{args[0], count}
 
-	return nil
+	return printResult(result, func() {
+		fmt.Printf("✓ %d item(s) exported to %s\n", count, args[0])
+	})
 }
 
-func runAssetDelete(cmd *cobra.Command, args []string) error {
+// Backup commands implementation
+
+func runBackupCreate(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
-	reader := bufio.NewReader(os.Stdin)
 
-	assetID, err := strconv.ParseInt(args[0], 10, 64)
+	backup, err := backpackService.CreateBackup(ctx)
 	if err != nil {
-		return fmt.Errorf("invalid asset ID: %w", err)
+		return fmt.Errorf("failed to create backup: %w", err)
 	}
 
-	fmt.Printf("\n=== Delete Asset #%d ===\n\n", assetID)
-	fmt.Print("Are you sure you want to delete this asset? (yes/no): ")
+	removed, err := backpackService.PruneBackups(ctx)
+	if err != nil {
+		logger.Warn("Failed to prune backups", "error", err)
+	}
 
-	confirmation, _ := reader.ReadString('\n')
-	confirmation = strings.ToLower(strings.TrimSpace(confirmation))
+	return printResult(backup, func() {
+		fmt.Printf("✓ Backup created: %s\n", backup.Path)
+		fmt.Printf("  %d asset file(s), %d copied, %s in total\n", backup.Assets, backup.NewBlobs, formatFileSize(backup.Size))
+		for _, old := range removed {
+			fmt.Printf("  Deleted old backup %s\n", old.Name)
+		}
+	})
+}
 
-	if confirmation != "yes" && confirmation != "y" {
-		fmt.Println("Deletion cancelled.")
-		return nil
-	}
+func runBackupList(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
 
-	if err := backpackService.DeleteAsset(ctx, assetID); err != nil {
-		return fmt.Errorf("failed to delete asset: %w", err)
+	backups, err := backpackService.ListBackups(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list backups: %w", err)
 	}
 
-	fmt.Printf("\n✓ Asset #%d deleted successfully\n", assetID)
+	return printResult(backups, func() {
+		if len(backups) == 0 {
+			fmt.Println("No backups found.")
+			return
+		}
 
-	return nil
+		fmt.Printf("\n=== Backups (%d) ===\n\n", len(backups))
+		for _, backup := range backups {
+			fmt.Printf("  %s  %s  %d asset file(s), %s\n", backup.Name, backup.CreatedAt.Local().Format("2006-01-02 15:04"), backup.Assets, formatFileSize(backup.Size))
+		}
+	})
 }
 
-// Peer commands implementation
-
-func runPeerList(cmd *cobra.Command, args []string) error {
+func runBackupVerify(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
-	peers, err := gossipService.GetPeers(ctx)
+	backup, err := backpackService.VerifyBackup(ctx, args[0])
 	if err != nil {
-		return fmt.Errorf("failed to get peers: %w", err)
+		return err
 	}
 
-	if len(peers) == 0 {
-		fmt.Println("\nNo peers discovered.")
-		return nil
-	}
+	return printResult(backup, func() {
+		fmt.Printf("✓ Backup %s is intact (%d asset file(s), %s)\n", backup.Name, backup.Assets, formatFileSize(backup.Size))
+	})
+}
 
-	fmt.Printf("\n=== Peers (%d) ===\n\n", len(peers))
+func runBackupPrune(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
 
-	for _, peer := range peers {
-		fmt.Printf("ID: %s\n", peer.ID)
-		fmt.Printf("  Name:      %s\n", peer.Name)
-		fmt.Printf("  Address:   %s\n", peer.Address)
-		fmt.Printf("  Status:    %s\n", peer.Status)
-		if peer.IsTrusted {
-			fmt.Printf("  Trusted:   ✓\n")
-		}
-		if !peer.LastSeen.IsZero() {
-			fmt.Printf("  Last Seen: %s\n", peer.LastSeen.Format("2006-01-02 15:04:05"))
+	removed, err := backpackService.PruneBackups(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to prune backups: %w", err)
+	}
+
+	return printResult(removed, func() {
+		if len(removed) == 0 {
+			fmt.Println("No backup to delete.")
+			return
 		}
-		if peer.LastSync != nil {
-			fmt.Printf("  Last Sync: %s\n", peer.LastSync.Format("2006-01-02 15:04:05"))
+		for _, backup := range removed {
+			fmt.Printf("✓ Deleted backup %s\n", backup.Name)
 		}
-		fmt.Println()
+	})
+}
+
+func runBackupStatus(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	status, err := backpackService.BackupStatus(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get backup status: %w", err)
 	}
 
-	return nil
+	return printResult(status, func() {
+		if !status.Enabled {
+			fmt.Println("Scheduled backups are disabled (backup_schedule.interval in config.yaml).")
+		} else if status.Healthy {
+			fmt.Printf("✓ Backups every %s\n", status.Interval)
+		} else {
+			fmt.Printf("⚠ Backups every %s, with problems\n", status.Interval)
+		}
+
+		for _, destination := range status.Destinations {
+			fmt.Printf("\n%s\n", destination.Path)
+			if !destination.Available {
+				fmt.Println("  ⚠ Not available (drive not mounted?)")
+				continue
+			}
+
+			last := "none"
+			if destination.LastBackup != nil {
+				last = fmt.Sprintf("%s (%s)", destination.LastBackup.CreatedAt.Local().Format("2006-01-02 15:04"), destination.LastBackup.Name)
+			}
+			fmt.Printf("  Backups: %d, last: %s\n", destination.Backups, last)
+			if destination.NextBackup != nil {
+				fmt.Printf("  Next: %s\n", destination.NextBackup.Local().Format("2006-01-02 15:04"))
+			}
+			if destination.Overdue {
+				fmt.Println("  ⚠ Overdue")
+			}
+			if destination.LastError != "" {
+				fmt.Printf("  ⚠ Last backup failed on %s: %s\n", destination.LastErrorAt.Local().Format("2006-01-02 15:04"), destination.LastError)
+			}
+		}
+	})
 }
 
-func runPeerAdd(cmd *cobra.Command, args []string) error {
+// runRestore works on the local data only: the database must be closed while
+// it is replaced, which a running server can't do
+func runRestore(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
-	name := args[0]
-	address := args[1]
-	trusted, _ := cmd.Flags().GetBool("trusted")
+	if remoteURL != "" {
+		return usageError{errors.New("restore runs on the data of this machine: run it on the server, with brique-server stopped")}
+	}
 
-	// Generate a unique ID for the peer
-	peerID := fmt.Sprintf("%s.manual", address)
+	// A path to a backup works as well as its name
+	backupsDir, name := cfg.BackupsDir, args[0]
+	if strings.ContainsRune(name, filepath.Separator) {
+		backupsDir, name = filepath.Split(filepath.Clean(name))
+	}
 
-	peer := &models.Peer{
-		ID:        peerID,
-		Name:      name,
-		Address:   address,
-		IsTrusted: trusted,
+	backup, err := services.VerifyBackup(ctx, backupsDir, name)
+	if err != nil {
+		return err
 	}
 
-	if err := gossipService.AddPeer(ctx, peer); err != nil {
-		return fmt.Errorf("failed to add peer: %w", err)
+	ok, err := confirm(cmd, fmt.Sprintf("Replace the current data with backup %s of %s?", backup.Name, backup.CreatedAt.Local().Format("2006-01-02 15:04")))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		fmt.Println("Cancelled.")
+		return nil
 	}
 
-	fmt.Printf("\n✓ Peer '%s' added successfully\n", name)
-	fmt.Printf("  ID:      %s\n", peerID)
-	fmt.Printf("  Address: %s\n", address)
-	if trusted {
-		fmt.Printf("  Trusted: ✓\n")
+	current, err := backpackService.CreateBackup(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to back up the current data: %w", err)
 	}
+	printProgress("Current data saved as backup %s\n", current.Name)
 
-	return nil
+	if err := closeApp(); err != nil {
+		return fmt.Errorf("failed to close database: %w", err)
+	}
+	database = nil
+
+	backup, err = services.RestoreBackup(ctx, backupsDir, name, cfg.DatabasePath, cfg.AssetsDir)
+	if err != nil {
+		return fmt.Errorf("failed to restore backup: %w", err)
+	}
+
+	return printResult(backup, func() {
+		fmt.Printf("✓ Restored backup %s\n", backup.Name)
+	})
 }
 
-func runPeerRemove(cmd *cobra.Command, args []string) error {
+// Encryption commands implementation
+
+// localEncryption returns the service of the local data, the encryption
+// commands rewriting the data in place
+func localEncryption() (*services.BackpackService, error) {
+	local, ok := backpackService.(localBackpack)
+	if remoteURL != "" || !ok {
+		return nil, usageError{errors.New("encryption is managed on the data of this machine: run it on the server, with brique-server stopped")}
+	}
+	return local.BackpackService, nil
+}
+
+func runEncryptionStatus(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
-	reader := bufio.NewReader(os.Stdin)
 
-	peerID := args[0]
+	status, err := backpackService.EncryptionStatus(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get encryption status: %w", err)
+	}
+
+	return printResult(status, func() {
+		if !status.Enabled {
+			fmt.Println("Encryption at rest is disabled")
+			return
+		}
 
-	fmt.Printf("\n=== Remove Peer ===\n\n")
-	fmt.Printf("Peer ID: %s\n", peerID)
-	fmt.Print("Are you sure you want to remove this peer? (yes/no): ")
+		state := "locked"
+		if status.Unlocked {
+			state = "unlocked"
+		}
+		fmt.Printf("Encryption at rest is enabled (%s)\n", state)
+		if status.KeySince != nil {
+			fmt.Printf("  Key #%d since %s\n", status.KeyID, status.KeySince.Local().Format("2006-01-02 15:04"))
+		}
+		if status.OldKeys > 0 {
+			fmt.Printf("  %d former key(s) left by an interrupted rotation: run 'encryption rotate'\n", status.OldKeys)
+		}
+	})
+}
 
-	confirmation, _ := reader.ReadString('\n')
-	confirmation = strings.ToLower(strings.TrimSpace(confirmation))
+func runEncryptionEnable(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
 
-	if confirmation != "yes" && confirmation != "y" {
-		fmt.Println("Removal cancelled.")
-		return nil
+	backpack, err := localEncryption()
+	if err != nil {
+		return err
 	}
 
-	if err := gossipService.RemovePeer(ctx, peerID); err != nil {
-		return fmt.Errorf("failed to remove peer: %w", err)
+	newPassphrase, err := cfg.Passphrase()
+	if err == nil && newPassphrase == "" {
+		newPassphrase, err = readNewPassphrase()
+	}
+	if err != nil {
+		return err
 	}
 
-	fmt.Printf("\n✓ Peer removed successfully\n")
+	report, err := backpack.EnableEncryption(ctx, newPassphrase)
+	if err != nil {
+		return fmt.Errorf("failed to enable encryption: %w", err)
+	}
 
-	return nil
+	return printResult(report, func() {
+		fmt.Printf("✓ Encryption enabled: %d item(s) and %d asset file(s) encrypted\n", report.Items, report.Assets)
+		fmt.Println("  Keep the passphrase safe: the data cannot be read without it")
+	})
 }
 
-func runPeerSync(cmd *cobra.Command, args []string) error {
+func runEncryptionRotate(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
-	peerID := args[0]
-
-	// Get peer info
-	peers, err := gossipService.GetPeers(ctx)
+	backpack, err := localEncryption()
 	if err != nil {
-		return fmt.Errorf("failed to get peers: %w", err)
+		return err
 	}
 
-	var peer *models.Peer
-	for _, p := range peers {
-		if p.ID == peerID {
-			peer = &p
-			break
+	newPassphrase := passphrase
+	if change, _ := cmd.Flags().GetBool("new-passphrase"); change {
+		if newPassphrase, err = readNewPassphrase(); err != nil {
+			return err
 		}
 	}
 
-	if peer == nil {
-		return fmt.Errorf("peer not found: %s", peerID)
+	report, err := backpack.RotateEncryptionKey(ctx, newPassphrase)
+	if err != nil {
+		return fmt.Errorf("failed to rotate encryption key: %w", err)
 	}
 
-	fmt.Printf("\n=== Synchronizing with %s ===\n\n", peer.Name)
-
-	// Note: This is a simplified version. The full sync logic would need
-	// to implement the HTTP sync like in gossip_handlers.go
-	// For now, just show a message
-	fmt.Println("Sync functionality requires running the full application.")
-	fmt.Println("Use the GUI or server mode for synchronization.")
-
-	return nil
+	return printResult(report, func() {
+		fmt.Printf("✓ Key rotated: %d item(s) and %d asset file(s) re-encrypted\n", report.Items, report.Assets)
+	})
 }
 
-func runPeerTrust(cmd *cobra.Command, args []string) error {
+func runEncryptionPassphrase(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
-	peerID := args[0]
+	backpack, err := localEncryption()
+	if err != nil {
+		return err
+	}
 
-	if err := gossipService.SetPeerTrust(ctx, peerID, true); err != nil {
-		return fmt.Errorf("failed to trust peer: %w", err)
+	newPassphrase, err := readNewPassphrase()
+	if err != nil {
+		return err
 	}
 
-	fmt.Printf("\n✓ Peer '%s' marked as trusted\n", peerID)
+	if err := backpack.ChangePassphrase(ctx, newPassphrase); err != nil {
+		return fmt.Errorf("failed to change passphrase: %w", err)
+	}
 
-	return nil
+	return printResult(struct {
+		Changed bool `json:"changed"`
+	}{true}, func() {
+		fmt.Println("✓ Passphrase changed")
+	})
 }
 
-func runPeerUntrust(cmd *cobra.Command, args []string) error {
+func runEncryptionDisable(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
-	peerID := args[0]
+	backpack, err := localEncryption()
+	if err != nil {
+		return err
+	}
 
-	if err := gossipService.SetPeerTrust(ctx, peerID, false); err != nil {
-		return fmt.Errorf("failed to untrust peer: %w", err)
+	ok, err := confirm(cmd, "Decrypt the data and store it in plaintext?")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		fmt.Println("Cancelled.")
+		return nil
 	}
 
-	fmt.Printf("\n✓ Trust removed from peer '%s'\n", peerID)
+	report, err := backpack.DisableEncryption(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to disable encryption: %w", err)
+	}
 
-	return nil
+	return printResult(report, func() {
+		fmt.Printf("✓ Encryption disabled: %d item(s) and %d asset file(s) decrypted\n", report.Items, report.Assets)
+	})
 }
 
 // Helper functions
 
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), strings.TrimSpace(value)) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinAssetTypes(types []models.AssetType) string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = string(t)
+	}
+	return strings.Join(names, ", ")
+}
+
 func getHealthEmoji(health models.DocumentationHealth) string {
 	switch health {
 	case models.HealthSecured:
@@ -780,6 +3874,10 @@ func getHealthEmoji(health models.DocumentationHealth) string {
 	}
 }
 
+func formatCost(cents int64) string {
+	return fmt.Sprintf("%d.%02d", cents/100, cents%100)
+}
+
 func formatFileSize(size int64) string {
 	const (
 		KB = 1024
@@ -798,3 +3896,187 @@ func formatFileSize(size int64) string {
 		return fmt.Sprintf("%d B", size)
 	}
 }
+
+// Config commands implementation
+
+func runConfigShow(cmd *cobra.Command, args []string) error {
+	current, err := config.LoadProfile(profileName)
+	if err != nil {
+		return err
+	}
+
+	settings := current.Settings()
+	return printResult(settings, func() {
+		fmt.Printf("# %s\n", describeConfigFile(current.File()))
+		encodeYAML(os.Stdout, settings)
+	})
+}
+
+// describeConfigFile tells which config file is used, if any
+func describeConfigFile(file string) string {
+	if _, err := os.Stat(file); err != nil {
+		return fmt.Sprintf("defaults, no %s", file)
+	}
+	return file
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) error {
+	file, err := config.File()
+	if err != nil {
+		return err
+	}
+
+	if err := config.Set(file, args[0], args[1]); err != nil {
+		return err
+	}
+
+	result := struct {
+		File  string `json:"file"`
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}{file, args[0], args[1]}
+
+	return printResult(result, func() {
+		fmt.Printf("✓ %s set to %s in %s\n", args[0], args[1], file)
+	})
+}
+
+func runConfigValidate(cmd *cobra.Command, args []string) error {
+	current, err := config.LoadProfile(profileName)
+	if err != nil {
+		return err
+	}
+
+	result := struct {
+		File  string `json:"file"`
+		Valid bool   `json:"valid"`
+	}{current.File(), true}
+
+	return printResult(result, func() {
+		fmt.Printf("✓ Configuration is valid (%s)\n", describeConfigFile(current.File()))
+	})
+}
+
+// Profile commands implementation
+
+// dataDir returns the data directory holding the profiles, whatever the
+// profile in use
+func dataDir() (string, error) {
+	current, err := config.LoadProfile(config.DefaultProfile)
+	if err != nil {
+		return "", err
+	}
+	return current.DataDir, nil
+}
+
+func runProfileList(cmd *cobra.Command, args []string) error {
+	current, err := config.LoadProfile(profileName)
+	if err != nil {
+		return err
+	}
+
+	names, err := config.Profiles(current.DataDir)
+	if err != nil {
+		return err
+	}
+
+	type profile struct {
+		Name    string `json:"name"`
+		Dir     string `json:"dir"`
+		Current bool   `json:"current"`
+	}
+	profiles := make([]profile, len(names))
+	for i, name := range names {
+		dir := current.DataDir
+		if name != config.DefaultProfile {
+			dir = config.ProfileDir(current.DataDir, name)
+		}
+		profiles[i] = profile{Name: name, Dir: dir, Current: name == current.Profile}
+	}
+
+	return printResult(profiles, func() {
+		for _, p := range profiles {
+			marker := " "
+			if p.Current {
+				marker = "*"
+			}
+			fmt.Printf("%s %-20s %s\n", marker, p.Name, p.Dir)
+		}
+	})
+}
+
+func runProfileCreate(cmd *cobra.Command, args []string) error {
+	dir, err := dataDir()
+	if err != nil {
+		return err
+	}
+
+	if err := config.CreateProfile(dir, args[0]); err != nil {
+		if config.ValidateProfileName(args[0]) != nil {
+			return usageError{err}
+		}
+		return err
+	}
+
+	result := struct {
+		Name string `json:"name"`
+		Dir  string `json:"dir"`
+	}{args[0], config.ProfileDir(dir, args[0])}
+
+	return printResult(result, func() {
+		fmt.Printf("✓ Profile %s created in %s\n", result.Name, result.Dir)
+		fmt.Printf("  Use it with --profile %s, or by default with: brique profile use %s\n", result.Name, result.Name)
+	})
+}
+
+func runProfileUse(cmd *cobra.Command, args []string) error {
+	file, err := config.File()
+	if err != nil {
+		return err
+	}
+
+	// The file is only written if the profile exists
+	if err := config.Set(file, "profile", args[0]); err != nil {
+		return err
+	}
+
+	result := struct {
+		Profile string `json:"profile"`
+		File    string `json:"file"`
+	}{args[0], file}
+
+	return printResult(result, func() {
+		fmt.Printf("✓ Profile %s used by default (%s)\n", result.Profile, result.File)
+	})
+}
+
+func runProfileDelete(cmd *cobra.Command, args []string) error {
+	dir, err := dataDir()
+	if err != nil {
+		return err
+	}
+
+	// Neither the profile given by --profile nor the configured one
+	for _, name := range []string{profileName, ""} {
+		if current, err := config.LoadProfile(name); err == nil && current.Profile == args[0] {
+			return usageError{fmt.Errorf("profile %s is in use: switch to another one first, e.g. brique profile use %s", args[0], config.DefaultProfile)}
+		}
+	}
+
+	ok, err := confirm(cmd, fmt.Sprintf("Delete profile %s with its inventory, assets and backups?", args[0]))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		fmt.Println("Cancelled.")
+		return nil
+	}
+
+	if err := config.DeleteProfile(dir, args[0]); err != nil {
+		return err
+	}
+
+	return printResult(actionResult{Action: "deleted", Name: args[0]}, func() {
+		fmt.Printf("✓ Profile %s deleted\n", args[0])
+	})
+}
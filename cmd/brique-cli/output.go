@@ -0,0 +1,252 @@
+package main
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/lhommenul/brique/core/services"
+	"github.com/lhommenul/brique/pkg/config"
+	"github.com/spf13/cobra"
+	"go.yaml.in/yaml/v3"
+)
+
+// Formats of the --output flag
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// Exit codes, for scripts to tell failures apart
+const (
+	exitFailure  = 1 // The command failed
+	exitUsage    = 2 // Invalid arguments or flags
+	exitNotFound = 3 // The requested record does not exist
+)
+
+var outputFormat = outputTable
+
+// usageError marks errors caused by how the command was called
+type usageError struct {
+	err error
+}
+
+func (e usageError) Error() string { return e.err.Error() }
+func (e usageError) Unwrap() error { return e.err }
+
+// actionResult is the structured output of commands that have no record to
+// return, e.g. deletions
+type actionResult struct {
+	Action string `json:"action"`
+	ID     int64  `json:"id,omitempty"`
+	Name   string `json:"name,omitempty"`
+}
+
+// validateOutputFormat checks the --output flag
+func validateOutputFormat() error {
+	switch outputFormat {
+	case outputTable, outputJSON, outputYAML:
+		return nil
+	default:
+		return usageError{fmt.Errorf("invalid output format %q (expected json, yaml or table)", outputFormat)}
+	}
+}
+
+// structuredOutput tells whether results are printed for scripts
+func structuredOutput() bool {
+	return outputFormat == outputJSON || outputFormat == outputYAML
+}
+
+// printResult prints v as JSON or YAML, or calls table to print it for humans
+func printResult(v any, table func()) error {
+	// Empty lists are printed as such rather than null
+	if value := reflect.ValueOf(v); value.Kind() == reflect.Slice && value.IsNil() {
+		v = []any{}
+	}
+
+	switch outputFormat {
+	case outputJSON:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	case outputYAML:
+		return encodeYAML(os.Stdout, v)
+	default:
+		table()
+		return nil
+	}
+}
+
+// printProgress prints a message while a command runs. It goes to stderr with
+// a structured output, for stdout to only hold the result.
+func printProgress(format string, args ...any) {
+	if structuredOutput() {
+		fmt.Fprintf(os.Stderr, format, args...)
+		return
+	}
+	fmt.Printf(format, args...)
+}
+
+// encodeYAML writes v as YAML with the field names of its JSON encoding
+func encodeYAML(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode result: %w", err)
+	}
+
+	// JSON is valid YAML: decoding it into a node keeps the order of the fields
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return fmt.Errorf("failed to encode result: %w", err)
+	}
+	resetYAMLStyle(&node)
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return fmt.Errorf("failed to encode result: %w", err)
+	}
+	return encoder.Close()
+}
+
+// resetYAMLStyle drops the flow and quoting style inherited from JSON
+func resetYAMLStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetYAMLStyle(child)
+	}
+}
+
+// printError reports a failed command on stderr, as an object with a
+// structured output
+func printError(err error) {
+	if !structuredOutput() {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return
+	}
+
+	output := struct {
+		Error string `json:"error"`
+		Code  int    `json:"code"`
+	}{err.Error(), exitCode(err)}
+
+	if outputFormat == outputYAML {
+		encodeYAML(os.Stderr, output)
+		return
+	}
+	json.NewEncoder(os.Stderr).Encode(output)
+}
+
+// exitCode returns the process exit code for a failed command
+func exitCode(err error) int {
+	var usage usageError
+	switch {
+	case errors.As(err, &usage):
+		return exitUsage
+	case errors.Is(err, sql.ErrNoRows), errors.Is(err, services.ErrBackupNotFound), errors.Is(err, config.ErrProfileNotFound):
+		return exitNotFound
+	default:
+		return exitFailure
+	}
+}
+
+// markUsageErrors makes argument errors of cmd and its subcommands usage errors
+func markUsageErrors(cmd *cobra.Command) {
+	if validate := cmd.Args; validate != nil {
+		cmd.Args = func(cmd *cobra.Command, args []string) error {
+			if err := validate(cmd, args); err != nil {
+				return usageError{err}
+			}
+			return nil
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		markUsageErrors(sub)
+	}
+}
+
+// parseID parses the ID of a record given as argument
+func parseID(value, kind string) (int64, error) {
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, usageError{fmt.Errorf("invalid %s ID: %w", kind, err)}
+	}
+	return id, nil
+}
+
+// confirm asks the user to confirm an action, unless --yes was given. A
+// structured output has no one to ask, so --yes is required.
+func confirm(cmd *cobra.Command, question string) (bool, error) {
+	if yes, _ := cmd.Flags().GetBool("yes"); yes {
+		return true, nil
+	}
+	if structuredOutput() {
+		return false, usageError{errors.New("confirmation required: use --yes")}
+	}
+
+	fmt.Printf("%s (yes/no): ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+
+	return answer == "yes" || answer == "y", nil
+}
+
+// errNoPassphrase is returned when there is no terminal to type the passphrase in
+var errNoPassphrase = usageError{errors.New("passphrase required: set BRIQUE_PASSPHRASE or passphrase_file")}
+
+// readPassphrase asks for a passphrase on the terminal, without echoing it.
+// Without a terminal, the passphrase must come from the configuration.
+func readPassphrase(prompt string) (string, error) {
+	info, err := os.Stdin.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return "", errNoPassphrase
+	}
+
+	// Turning echo off is best effort, stty missing on some systems
+	echo := exec.Command("stty", "-echo")
+	echo.Stdin = os.Stdin
+	if echo.Run() == nil {
+		defer func() {
+			restore := exec.Command("stty", "echo")
+			restore.Stdin = os.Stdin
+			restore.Run()
+			fmt.Fprintln(os.Stderr)
+		}()
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", errNoPassphrase
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readNewPassphrase asks for a new passphrase twice
+func readNewPassphrase() (string, error) {
+	first, err := readPassphrase("New passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if first == "" {
+		return "", usageError{errors.New("passphrase cannot be empty")}
+	}
+
+	second, err := readPassphrase("Repeat passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if first != second {
+		return "", usageError{errors.New("passphrases do not match")}
+	}
+	return first, nil
+}
@@ -0,0 +1,848 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lhommenul/brique/core/models"
+)
+
+// remoteClient serves the commands through the REST API of a brique-server
+type remoteClient struct {
+	baseURL string
+	http    *http.Client
+}
+
+// newRemoteClient returns a client for the server at baseURL, e.g.
+// http://nas.local:8080
+func newRemoteClient(baseURL string) (*remoteClient, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, usageError{fmt.Errorf("invalid remote URL %q (expected e.g. http://host:8080)", baseURL)}
+	}
+
+	return &remoteClient{
+		baseURL: strings.TrimSuffix(u.String(), "/"),
+		http:    &http.Client{Timeout: 5 * time.Minute},
+	}, nil
+}
+
+// remoteError is an error reported by the server
+type remoteError struct {
+	status  int
+	message string
+}
+
+func (e *remoteError) Error() string { return e.message }
+
+// Is makes a record missing on the server a missing record, as locally
+func (e *remoteError) Is(target error) bool {
+	return target == sql.ErrNoRows && e.status == http.StatusNotFound
+}
+
+// do sends a request with an optional JSON body and decodes the JSON response
+// into out, unless it is nil
+func (c *remoteClient) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return c.send(req, out)
+}
+
+// upload sends a file along with form fields as a multipart request
+func (c *remoteClient) upload(ctx context.Context, path string, fields map[string]string, filePath string, out any) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	return c.uploadReader(ctx, path, fields, filepath.Base(filePath), file, out)
+}
+
+// uploadReader sends content as a file named name, along with form fields
+func (c *remoteClient) uploadReader(ctx context.Context, path string, fields map[string]string, name string, content io.Reader, out any) error {
+	// Stream the file rather than holding it in memory
+	body, writer := io.Pipe()
+	form := multipart.NewWriter(writer)
+	go func() {
+		writer.CloseWithError(writeUploadForm(form, fields, name, content))
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, body)
+	if err != nil {
+		body.Close()
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	return c.send(req, out)
+}
+
+func writeUploadForm(form *multipart.Writer, fields map[string]string, name string, content io.Reader) error {
+	for field, value := range fields {
+		if err := form.WriteField(field, value); err != nil {
+			return err
+		}
+	}
+
+	part, err := form.CreateFormFile("file", name)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, content); err != nil {
+		return err
+	}
+
+	return form.Close()
+}
+
+// download writes the body of a GET request to w and returns the response
+// headers
+func (c *remoteClient) download(ctx context.Context, path string, w io.Writer) (http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach %s: %w", c.baseURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, readRemoteError(resp)
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return resp.Header, nil
+}
+
+func (c *remoteClient) send(req *http.Request, out any) error {
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", c.baseURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return readRemoteError(resp)
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// readRemoteError reads the {"error": ...} body of a failed request, or its
+// plain text for errors not reported by the handlers
+func readRemoteError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+
+	var body struct {
+		Error string `json:"error"`
+	}
+	message := strings.TrimSpace(string(data))
+	if json.Unmarshal(data, &body) == nil && body.Error != "" {
+		message = body.Error
+	}
+	if message == "" {
+		message = http.StatusText(resp.StatusCode)
+	}
+
+	return &remoteError{status: resp.StatusCode, message: message}
+}
+
+func idPath(format string, id int64) string {
+	return fmt.Sprintf(format, strconv.FormatInt(id, 10))
+}
+
+func namePath(format string, name string) string {
+	return fmt.Sprintf(format, url.PathEscape(name))
+}
+
+func withinDaysQuery(within time.Duration) string {
+	return "?within_days=" + strconv.FormatInt(int64(within/(24*time.Hour)), 10)
+}
+
+func formatReleaseDate(releaseDate *time.Time) string {
+	if releaseDate == nil {
+		return ""
+	}
+	return releaseDate.Format(time.RFC3339)
+}
+
+// Items
+
+func (c *remoteClient) CreateItem(ctx context.Context, item *models.Item) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/items", item, item)
+}
+
+func (c *remoteClient) UpdateItem(ctx context.Context, item *models.Item) error {
+	return c.do(ctx, http.MethodPut, idPath("/api/v1/items/%s", item.ID), item, item)
+}
+
+func (c *remoteClient) DeleteItem(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, idPath("/api/v1/items/%s", id), nil, nil)
+}
+
+func (c *remoteClient) GetItem(ctx context.Context, id int64) (*models.Item, error) {
+	var item models.Item
+	if err := c.do(ctx, http.MethodGet, idPath("/api/v1/items/%s", id), nil, &item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (c *remoteClient) GetItemWithAssets(ctx context.Context, itemID int64) (*models.ItemWithAssets, error) {
+	var item models.ItemWithAssets
+	if err := c.do(ctx, http.MethodGet, idPath("/api/v1/items/%s?assets=true", itemID), nil, &item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (c *remoteClient) FilterItems(ctx context.Context, filter models.ItemFilter) ([]models.Item, error) {
+	query := url.Values{}
+	if filter.Query != "" {
+		query.Set("q", filter.Query)
+	}
+	if filter.Category != "" {
+		query.Set("category", filter.Category)
+	}
+	for _, tag := range filter.Tags {
+		query.Add("tag", tag)
+	}
+	for _, attr := range filter.Attributes {
+		query.Add("attr", attr.Key+attr.Operator+attr.Value)
+	}
+
+	var items []models.Item
+	err := c.do(ctx, http.MethodGet, "/api/v1/items?"+query.Encode(), nil, &items)
+	return items, err
+}
+
+func (c *remoteClient) GetAttributeTemplate(ctx context.Context, category string) (models.AttributeTemplate, error) {
+	var template models.AttributeTemplate
+	err := c.do(ctx, http.MethodGet, "/api/v1/attribute-templates?category="+url.QueryEscape(category), nil, &template)
+	return template, err
+}
+
+func (c *remoteClient) GetAttributeTemplates(ctx context.Context) ([]models.AttributeTemplate, error) {
+	var templates []models.AttributeTemplate
+	err := c.do(ctx, http.MethodGet, "/api/v1/attribute-templates", nil, &templates)
+	return templates, err
+}
+
+func (c *remoteClient) GetTags(ctx context.Context) ([]string, error) {
+	var tags []string
+	err := c.do(ctx, http.MethodGet, "/api/v1/tags", nil, &tags)
+	return tags, err
+}
+
+// Encryption at rest
+
+func (c *remoteClient) EncryptionStatus(ctx context.Context) (*models.EncryptionStatus, error) {
+	var status models.EncryptionStatus
+	if err := c.do(ctx, http.MethodGet, "/api/v1/encryption", nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// Export, import and backup
+
+func (c *remoteClient) ExportJSON(ctx context.Context, w io.Writer) (int, error) {
+	return c.export(ctx, "/api/v1/export/json", w)
+}
+
+func (c *remoteClient) ExportCSV(ctx context.Context, w io.Writer) (int, error) {
+	return c.export(ctx, "/api/v1/export/csv", w)
+}
+
+func (c *remoteClient) export(ctx context.Context, path string, w io.Writer) (int, error) {
+	header, err := c.download(ctx, path, w)
+	if err != nil {
+		return 0, err
+	}
+	count, _ := strconv.Atoi(header.Get("X-Item-Count"))
+	return count, nil
+}
+
+func (c *remoteClient) ImportJSON(ctx context.Context, r io.Reader) (int, int, error) {
+	var result struct {
+		Imported int `json:"imported"`
+		Skipped  int `json:"skipped"`
+	}
+	if err := c.uploadReader(ctx, "/api/v1/import/json", nil, "export.json", r, &result); err != nil {
+		return 0, 0, err
+	}
+	return result.Imported, result.Skipped, nil
+}
+
+func (c *remoteClient) CreateBackup(ctx context.Context) (*models.Backup, error) {
+	var backup models.Backup
+	if err := c.do(ctx, http.MethodPost, "/api/v1/backups", nil, &backup); err != nil {
+		return nil, err
+	}
+	return &backup, nil
+}
+
+func (c *remoteClient) ListBackups(ctx context.Context) ([]models.Backup, error) {
+	var backups []models.Backup
+	err := c.do(ctx, http.MethodGet, "/api/v1/backups", nil, &backups)
+	return backups, err
+}
+
+func (c *remoteClient) VerifyBackup(ctx context.Context, name string) (*models.Backup, error) {
+	var backup models.Backup
+	if err := c.do(ctx, http.MethodPost, namePath("/api/v1/backups/%s/verify", name), nil, &backup); err != nil {
+		return nil, err
+	}
+	return &backup, nil
+}
+
+func (c *remoteClient) BackupStatus(ctx context.Context) (*models.BackupScheduleStatus, error) {
+	var status models.BackupScheduleStatus
+	if err := c.do(ctx, http.MethodGet, "/api/v1/backups/schedule", nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func (c *remoteClient) PruneBackups(ctx context.Context) ([]models.Backup, error) {
+	var removed []models.Backup
+	err := c.do(ctx, http.MethodPost, "/api/v1/backups/prune", nil, &removed)
+	return removed, err
+}
+
+func (c *remoteClient) ImportCSV(ctx context.Context, r io.Reader, options models.CSVImportOptions) (*models.ImportReport, error) {
+	columns, err := json.Marshal(options.Columns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	fields := map[string]string{
+		"columns": string(columns),
+		"dry_run": strconv.FormatBool(options.DryRun),
+	}
+
+	var report models.ImportReport
+	if err := c.uploadReader(ctx, "/api/v1/import/csv", fields, "import.csv", r, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// Assets and photos
+
+func (c *remoteClient) AddAsset(ctx context.Context, itemID int64, assetType models.AssetType, name string, sourcePath string) (*models.Asset, error) {
+	var asset models.Asset
+	fields := map[string]string{"type": string(assetType), "name": name}
+	if err := c.upload(ctx, idPath("/api/v1/items/%s/assets", itemID), fields, sourcePath, &asset); err != nil {
+		return nil, err
+	}
+	return &asset, nil
+}
+
+func (c *remoteClient) AddProductModelAsset(ctx context.Context, productModelID int64, assetType models.AssetType, name string, sourcePath string) (*models.Asset, error) {
+	var asset models.Asset
+	fields := map[string]string{"type": string(assetType), "name": name}
+	if err := c.upload(ctx, idPath("/api/v1/product-models/%s/assets", productModelID), fields, sourcePath, &asset); err != nil {
+		return nil, err
+	}
+	return &asset, nil
+}
+
+func (c *remoteClient) AddAssetRevision(ctx context.Context, previousID int64, name string, sourcePath string, versionLabel string, releaseDate *time.Time) (*models.Asset, error) {
+	var asset models.Asset
+	fields := map[string]string{
+		"name":          name,
+		"version_label": versionLabel,
+		"release_date":  formatReleaseDate(releaseDate),
+	}
+	if err := c.upload(ctx, idPath("/api/v1/assets/%s/revisions", previousID), fields, sourcePath, &asset); err != nil {
+		return nil, err
+	}
+	return &asset, nil
+}
+
+func (c *remoteClient) DeleteAsset(ctx context.Context, assetID int64) error {
+	return c.do(ctx, http.MethodDelete, idPath("/api/v1/assets/%s", assetID), nil, nil)
+}
+
+func (c *remoteClient) GetItemAssets(ctx context.Context, itemID int64) ([]models.Asset, error) {
+	var assets []models.Asset
+	err := c.do(ctx, http.MethodGet, idPath("/api/v1/items/%s/assets", itemID), nil, &assets)
+	return assets, err
+}
+
+func (c *remoteClient) GetAssetHistory(ctx context.Context, assetID int64) ([]models.Asset, error) {
+	var history []models.Asset
+	err := c.do(ctx, http.MethodGet, idPath("/api/v1/assets/%s/history", assetID), nil, &history)
+	return history, err
+}
+
+func (c *remoteClient) SetCurrentRevision(ctx context.Context, assetID int64) error {
+	return c.do(ctx, http.MethodPost, idPath("/api/v1/assets/%s/current", assetID), nil, nil)
+}
+
+func (c *remoteClient) SetAssetVersion(ctx context.Context, assetID int64, versionLabel string, releaseDate *time.Time) error {
+	body := struct {
+		VersionLabel string     `json:"version_label"`
+		ReleaseDate  *time.Time `json:"release_date"`
+	}{versionLabel, releaseDate}
+	return c.do(ctx, http.MethodPut, idPath("/api/v1/assets/%s/version", assetID), body, nil)
+}
+
+func (c *remoteClient) ShareAsset(ctx context.Context, assetID int64) (*models.Asset, error) {
+	var asset models.Asset
+	if err := c.do(ctx, http.MethodPost, idPath("/api/v1/assets/%s/share", assetID), nil, &asset); err != nil {
+		return nil, err
+	}
+	return &asset, nil
+}
+
+func (c *remoteClient) SaveAsset(ctx context.Context, assetID int64, w io.Writer) (*models.Asset, error) {
+	history, err := c.GetAssetHistory(ctx, assetID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.download(ctx, idPath("/api/v1/assets/%s/file", assetID), w); err != nil {
+		return nil, err
+	}
+
+	for i := range history {
+		if history[i].ID == assetID {
+			return &history[i], nil
+		}
+	}
+	return nil, fmt.Errorf("asset %d is not in its history", assetID)
+}
+
+func (c *remoteClient) AddPhoto(ctx context.Context, itemID int64, sourcePath string) (*models.Asset, error) {
+	var photo models.Asset
+	if err := c.upload(ctx, idPath("/api/v1/items/%s/photos", itemID), nil, sourcePath, &photo); err != nil {
+		return nil, err
+	}
+	return &photo, nil
+}
+
+func (c *remoteClient) GetItemPhotos(ctx context.Context, itemID int64) ([]models.Asset, error) {
+	var photos []models.Asset
+	err := c.do(ctx, http.MethodGet, idPath("/api/v1/items/%s/photos", itemID), nil, &photos)
+	return photos, err
+}
+
+func (c *remoteClient) ImportLegacyPhotos(ctx context.Context) (int, error) {
+	var result struct {
+		Imported int `json:"imported"`
+	}
+	err := c.do(ctx, http.MethodPost, "/api/v1/photos/import-legacy", nil, &result)
+	return result.Imported, err
+}
+
+// Asset types
+
+func (c *remoteClient) GetAssetTypes(ctx context.Context) ([]models.AssetTypeDefinition, error) {
+	var types []models.AssetTypeDefinition
+	err := c.do(ctx, http.MethodGet, "/api/v1/asset-types", nil, &types)
+	return types, err
+}
+
+func (c *remoteClient) SaveAssetType(ctx context.Context, def *models.AssetTypeDefinition) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/asset-types", def, def)
+}
+
+func (c *remoteClient) DeleteAssetType(ctx context.Context, name models.AssetType) error {
+	return c.do(ctx, http.MethodDelete, namePath("/api/v1/asset-types/%s", string(name)), nil, nil)
+}
+
+// Categories
+
+func (c *remoteClient) GetCategories(ctx context.Context) ([]models.Category, error) {
+	var categories []models.Category
+	err := c.do(ctx, http.MethodGet, "/api/v1/categories", nil, &categories)
+	return categories, err
+}
+
+func (c *remoteClient) CreateCategory(ctx context.Context, name, parent string) (*models.Category, error) {
+	body := struct {
+		Name   string `json:"name"`
+		Parent string `json:"parent"`
+	}{name, parent}
+
+	var category models.Category
+	if err := c.do(ctx, http.MethodPost, "/api/v1/categories", body, &category); err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (c *remoteClient) RenameCategory(ctx context.Context, name, newName string) error {
+	body := struct {
+		Name string `json:"name"`
+	}{newName}
+	return c.do(ctx, http.MethodPut, namePath("/api/v1/categories/%s", name), body, nil)
+}
+
+func (c *remoteClient) SetCategoryParent(ctx context.Context, name, parent string) error {
+	body := struct {
+		Parent *string `json:"parent"`
+	}{&parent}
+	return c.do(ctx, http.MethodPut, namePath("/api/v1/categories/%s", name), body, nil)
+}
+
+func (c *remoteClient) DeleteCategory(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, namePath("/api/v1/categories/%s", name), nil, nil)
+}
+
+func (c *remoteClient) MergeCategory(ctx context.Context, from, into string) error {
+	body := struct {
+		Into string `json:"into"`
+	}{into}
+	return c.do(ctx, http.MethodPost, namePath("/api/v1/categories/%s/merge", from), body, nil)
+}
+
+func (c *remoteClient) AddCategorySynonym(ctx context.Context, name, synonym string) error {
+	body := struct {
+		Synonym string `json:"synonym"`
+	}{synonym}
+	return c.do(ctx, http.MethodPost, namePath("/api/v1/categories/%s/synonyms", name), body, nil)
+}
+
+// RemoveCategorySynonym looks for the category of the synonym first, the API
+// removing synonyms under their category
+func (c *remoteClient) RemoveCategorySynonym(ctx context.Context, synonym string) error {
+	categories, err := c.GetCategories(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, category := range categories {
+		for _, s := range category.Synonyms {
+			if strings.EqualFold(s, strings.TrimSpace(synonym)) {
+				path := namePath("/api/v1/categories/%s/synonyms/", category.Name) + url.PathEscape(s)
+				return c.do(ctx, http.MethodDelete, path, nil, nil)
+			}
+		}
+	}
+
+	return fmt.Errorf("unknown synonym: %s", synonym)
+}
+
+// Product models
+
+func (c *remoteClient) GetProductModels(ctx context.Context) ([]models.ProductModel, error) {
+	var productModels []models.ProductModel
+	err := c.do(ctx, http.MethodGet, "/api/v1/product-models", nil, &productModels)
+	return productModels, err
+}
+
+func (c *remoteClient) GetProductModel(ctx context.Context, id int64) (*models.ProductModelWithAssets, error) {
+	var productModel models.ProductModelWithAssets
+	if err := c.do(ctx, http.MethodGet, idPath("/api/v1/product-models/%s", id), nil, &productModel); err != nil {
+		return nil, err
+	}
+	return &productModel, nil
+}
+
+func (c *remoteClient) AddProductModelAlias(ctx context.Context, id int64, brand, model string) error {
+	alias := models.ProductModelAlias{Brand: brand, Model: model}
+	return c.do(ctx, http.MethodPost, idPath("/api/v1/product-models/%s/aliases", id), alias, nil)
+}
+
+// RemoveProductModelAlias looks for the product model of the alias first, the
+// API removing aliases under their product model
+func (c *remoteClient) RemoveProductModelAlias(ctx context.Context, brand, model string) error {
+	productModels, err := c.GetProductModels(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, productModel := range productModels {
+		for _, alias := range productModel.Aliases {
+			if strings.EqualFold(alias.Brand, strings.TrimSpace(brand)) && strings.EqualFold(alias.Model, strings.TrimSpace(model)) {
+				query := url.Values{"brand": {alias.Brand}, "model": {alias.Model}}
+				path := idPath("/api/v1/product-models/%s/aliases?", productModel.ID) + query.Encode()
+				return c.do(ctx, http.MethodDelete, path, nil, nil)
+			}
+		}
+	}
+
+	return fmt.Errorf("unknown alias: %s %s", brand, model)
+}
+
+// Repairs
+
+func (c *remoteClient) AddRepairEvent(ctx context.Context, event *models.RepairEvent) error {
+	return c.do(ctx, http.MethodPost, idPath("/api/v1/items/%s/repairs", event.ItemID), event, event)
+}
+
+func (c *remoteClient) GetItemRepairs(ctx context.Context, itemID int64) ([]models.RepairEvent, error) {
+	var repairs []models.RepairEvent
+	err := c.do(ctx, http.MethodGet, idPath("/api/v1/items/%s/repairs", itemID), nil, &repairs)
+	return repairs, err
+}
+
+func (c *remoteClient) DeleteRepairEvent(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, idPath("/api/v1/repairs/%s", id), nil, nil)
+}
+
+// Maintenance
+
+func (c *remoteClient) AddMaintenanceSchedule(ctx context.Context, schedule *models.MaintenanceSchedule) error {
+	return c.do(ctx, http.MethodPost, idPath("/api/v1/items/%s/maintenance", schedule.ItemID), schedule, schedule)
+}
+
+func (c *remoteClient) GetItemMaintenance(ctx context.Context, itemID int64) ([]models.MaintenanceTask, error) {
+	var tasks []models.MaintenanceTask
+	err := c.do(ctx, http.MethodGet, idPath("/api/v1/items/%s/maintenance", itemID), nil, &tasks)
+	return tasks, err
+}
+
+func (c *remoteClient) GetDueMaintenance(ctx context.Context, within time.Duration) ([]models.MaintenanceTask, error) {
+	var tasks []models.MaintenanceTask
+	err := c.do(ctx, http.MethodGet, "/api/v1/maintenance/due"+withinDaysQuery(within), nil, &tasks)
+	return tasks, err
+}
+
+func (c *remoteClient) CompleteMaintenance(ctx context.Context, scheduleID int64, doneAt time.Time) (*models.MaintenanceTask, error) {
+	body := struct {
+		DoneAt time.Time `json:"done_at"`
+	}{doneAt}
+
+	var task models.MaintenanceTask
+	if err := c.do(ctx, http.MethodPost, idPath("/api/v1/maintenance/%s/done", scheduleID), body, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+func (c *remoteClient) DeleteMaintenanceSchedule(ctx context.Context, scheduleID int64) error {
+	return c.do(ctx, http.MethodDelete, idPath("/api/v1/maintenance/%s", scheduleID), nil, nil)
+}
+
+func (c *remoteClient) RecordUsage(ctx context.Context, itemID int64, unit string, value int64) error {
+	usage := models.UsageCounter{Unit: unit, Value: value}
+	return c.do(ctx, http.MethodPut, idPath("/api/v1/items/%s/usage", itemID), usage, nil)
+}
+
+// Warranties
+
+func (c *remoteClient) SetItemWarranty(ctx context.Context, itemID int64, warranty *models.Warranty) error {
+	path := idPath("/api/v1/items/%s/warranty", itemID)
+	if warranty == nil {
+		return c.do(ctx, http.MethodDelete, path, nil, nil)
+	}
+	return c.do(ctx, http.MethodPut, path, warranty, warranty)
+}
+
+func (c *remoteClient) GetExpiringWarranties(ctx context.Context, within time.Duration) ([]models.Item, error) {
+	var items []models.Item
+	err := c.do(ctx, http.MethodGet, "/api/v1/warranties/expiring"+withinDaysQuery(within), nil, &items)
+	return items, err
+}
+
+// Loans
+
+func (c *remoteClient) LendItem(ctx context.Context, loan *models.Loan) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/loans", loan, loan)
+}
+
+func (c *remoteClient) ReturnLoan(ctx context.Context, id int64, returnedAt time.Time) (*models.Loan, error) {
+	body := struct {
+		ReturnedAt time.Time `json:"returned_at"`
+	}{returnedAt}
+
+	var loan models.Loan
+	if err := c.do(ctx, http.MethodPost, idPath("/api/v1/loans/%s/return", id), body, &loan); err != nil {
+		return nil, err
+	}
+	return &loan, nil
+}
+
+func (c *remoteClient) GetLoans(ctx context.Context, includeReturned bool) ([]models.Loan, error) {
+	var loans []models.Loan
+	err := c.do(ctx, http.MethodGet, "/api/v1/loans?all="+strconv.FormatBool(includeReturned), nil, &loans)
+	return loans, err
+}
+
+func (c *remoteClient) GetOverdueLoans(ctx context.Context) ([]models.Loan, error) {
+	var loans []models.Loan
+	err := c.do(ctx, http.MethodGet, "/api/v1/loans?overdue=true", nil, &loans)
+	return loans, err
+}
+
+// Spare parts
+
+func (c *remoteClient) CreatePart(ctx context.Context, part *models.Part) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/parts", part, part)
+}
+
+func (c *remoteClient) GetPart(ctx context.Context, id int64) (*models.Part, error) {
+	var part models.Part
+	if err := c.do(ctx, http.MethodGet, idPath("/api/v1/parts/%s", id), nil, &part); err != nil {
+		return nil, err
+	}
+	return &part, nil
+}
+
+func (c *remoteClient) GetParts(ctx context.Context) ([]models.Part, error) {
+	var parts []models.Part
+	err := c.do(ctx, http.MethodGet, "/api/v1/parts", nil, &parts)
+	return parts, err
+}
+
+func (c *remoteClient) GetLowStockParts(ctx context.Context) ([]models.Part, error) {
+	var parts []models.Part
+	err := c.do(ctx, http.MethodGet, "/api/v1/parts?low_stock=true", nil, &parts)
+	return parts, err
+}
+
+func (c *remoteClient) GetItemParts(ctx context.Context, itemID int64) ([]models.Part, error) {
+	var parts []models.Part
+	err := c.do(ctx, http.MethodGet, idPath("/api/v1/items/%s/parts", itemID), nil, &parts)
+	return parts, err
+}
+
+func (c *remoteClient) AdjustPartStock(ctx context.Context, id int64, delta int64) (*models.Part, error) {
+	body := struct {
+		Delta int64 `json:"delta"`
+	}{delta}
+
+	var part models.Part
+	if err := c.do(ctx, http.MethodPost, idPath("/api/v1/parts/%s/stock", id), body, &part); err != nil {
+		return nil, err
+	}
+	return &part, nil
+}
+
+func (c *remoteClient) DeletePart(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, idPath("/api/v1/parts/%s", id), nil, nil)
+}
+
+// Documentation health
+
+func (c *remoteClient) HealthReport(ctx context.Context) ([]models.HealthReport, error) {
+	var reports []models.HealthReport
+	err := c.do(ctx, http.MethodGet, "/api/v1/health/report", nil, &reports)
+	return reports, err
+}
+
+func (c *remoteClient) GetHealthRules(ctx context.Context) ([]models.HealthRule, error) {
+	var rules []models.HealthRule
+	err := c.do(ctx, http.MethodGet, "/api/v1/health/rules", nil, &rules)
+	return rules, err
+}
+
+// Peers
+
+func (c *remoteClient) GetPeers(ctx context.Context) ([]models.Peer, error) {
+	var peers []models.Peer
+	err := c.do(ctx, http.MethodGet, "/api/v1/gossip/peers", nil, &peers)
+	return peers, err
+}
+
+func (c *remoteClient) AddPeer(ctx context.Context, peer *models.Peer) error {
+	body := struct {
+		Name      string `json:"name"`
+		Address   string `json:"address"`
+		IsTrusted bool   `json:"is_trusted"`
+	}{peer.Name, peer.Address, peer.IsTrusted}
+	return c.do(ctx, http.MethodPost, "/api/v1/gossip/peers", body, peer)
+}
+
+func (c *remoteClient) RemovePeer(ctx context.Context, peerID string) error {
+	return c.do(ctx, http.MethodDelete, namePath("/api/v1/gossip/peers/%s", peerID), nil, nil)
+}
+
+func (c *remoteClient) SetPeerTrust(ctx context.Context, peerID string, trusted bool) error {
+	body := struct {
+		IsTrusted bool `json:"is_trusted"`
+	}{trusted}
+	return c.do(ctx, http.MethodPut, namePath("/api/v1/gossip/peers/%s", peerID), body, nil)
+}
+
+func (c *remoteClient) SyncPeer(ctx context.Context, peerID string) (*models.SyncResult, error) {
+	var result models.SyncResult
+	if err := c.do(ctx, http.MethodPost, namePath("/api/v1/gossip/sync/%s", peerID), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *remoteClient) SearchPeers(ctx context.Context, query models.SearchQuery) ([]models.SearchResult, error) {
+	values := url.Values{}
+	values.Set("brand", query.Brand)
+	values.Set("model", query.Model)
+	values.Set("asset_type", string(query.AssetType))
+
+	var results []models.SearchResult
+	err := c.do(ctx, http.MethodGet, "/api/v1/search?"+values.Encode(), nil, &results)
+	return results, err
+}
+
+func (c *remoteClient) ImportPeerItem(ctx context.Context, peerID string, itemID int64) (*models.ItemWithAssets, error) {
+	body := struct {
+		PeerID string `json:"peer_id"`
+		ItemID int64  `json:"item_id"`
+	}{peerID, itemID}
+
+	var item models.ItemWithAssets
+	if err := c.do(ctx, http.MethodPost, "/api/v1/search/fetch", body, &item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// Help announcements
+
+func (c *remoteClient) GetAnnouncements(ctx context.Context, kind models.AnnouncementKind) ([]models.Announcement, error) {
+	var announcements []models.Announcement
+	err := c.do(ctx, http.MethodGet, "/api/v1/announcements?kind="+url.QueryEscape(string(kind)), nil, &announcements)
+	return announcements, err
+}
+
+func (c *remoteClient) PublishAnnouncement(ctx context.Context, announcement *models.Announcement, ttl time.Duration) error {
+	body := struct {
+		*models.Announcement
+		TTLDays int64 `json:"ttl_days"`
+	}{announcement, int64(ttl / (24 * time.Hour))}
+	return c.do(ctx, http.MethodPost, "/api/v1/announcements", body, announcement)
+}
+
+func (c *remoteClient) WithdrawAnnouncement(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, idPath("/api/v1/announcements/%s", id), nil, nil)
+}
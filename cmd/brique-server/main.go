@@ -1,14 +1,22 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -19,31 +27,47 @@ import (
 )
 
 type Server struct {
-	cfg              *config.Config
-	database         *db.Database
-	backpackService  *services.BackpackService
-	gossipService    *services.GossipService
+	initial         *config.Config // As loaded at startup
+	profile         string         // Given by --profile, if any
+	database        *db.Database
+	backpackService *services.BackpackService
+	gossipService   *services.GossipService
+	backupScheduler *services.BackupScheduler
+	instanceID      string
+	logger          *slog.Logger
+	level           *slog.LevelVar
+	syncChanged     chan struct{} // Wakes the scheduled sync up when reconfigured
+
+	reloadMu sync.Mutex // Serializes reloads
+
+	mu               sync.RWMutex
+	cfg              *config.Config // In effect, reloaded live
+	restartRequired  []string       // Settings changed since startup that need a restart
 	discoveryService *services.DiscoveryService
-	logger           *slog.Logger
 }
 
 func main() {
+	profile := flag.String("profile", "", "Serve this profile's inventory instead of the configured one")
+	flag.Parse()
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Setup logger
+	// Setup logger, at the configured level once the configuration is loaded
+	var level slog.LevelVar
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelInfo,
+		Level: &level,
 	}))
 
 	// Load configuration
-	cfg, err := config.Load()
+	cfg, err := config.LoadProfile(*profile)
 	if err != nil {
 		logger.Error("Failed to load config", "error", err)
 		os.Exit(1)
 	}
+	level.Set(cfg.Level())
 
-	logger.Info("Configuration loaded", "data_dir", cfg.DataDir)
+	logger.Info("Configuration loaded", "data_dir", cfg.DataDir, "profile", cfg.Profile)
 
 	// Initialize database
 	database, err := db.NewDatabase(cfg.DatabasePath, logger)
@@ -53,31 +77,36 @@ func main() {
 	}
 	defer database.Close()
 
-	// Create queries
-	queries := db.New(database.DB)
-
 	// Create backpack service
-	backpackService := services.NewBackpackService(queries, cfg.AssetsDir)
+	backpackService := services.NewBackpackService(database, cfg.AssetsDir)
+	backpackService.SetLogger(logger)
+	backpackService.SetHealthRules(cfg.HealthRules)
+	backpackService.SetAttributeTemplates(cfg.AttributeTemplates)
+
+	// Unlock the data encrypted at rest, with BRIQUE_PASSPHRASE or the
+	// passphrase file
+	if err := unlockEncryption(cfg, backpackService); err != nil {
+		logger.Error("Failed to unlock encrypted data", "error", err)
+		os.Exit(1)
+	}
 
-	// Get port from environment or use default
-	port := 8080
-	if portStr := os.Getenv("BRIQUE_PORT"); portStr != "" {
-		if p, err := strconv.Atoi(portStr); err == nil {
-			port = p
-		}
+	// Finish file operations interrupted by a previous crash
+	if err := backpackService.ProcessFileOutbox(context.Background()); err != nil {
+		logger.Warn("Failed to process file outbox", "error", err)
 	}
 
-	// Create gossip service
-	instanceName := os.Getenv("BRIQUE_INSTANCE_NAME")
-	if instanceName == "" {
-		instanceName = fmt.Sprintf("Brique-%s", os.Getenv("USER"))
-		if instanceName == "Brique-" {
-			instanceName = "Brique-Server"
-		}
+	// Link items created before product models existed
+	if _, err := backpackService.LinkProductModels(context.Background()); err != nil {
+		logger.Warn("Failed to link product models", "error", err)
 	}
 
+	// Create gossip service, served on the port of the API
+	port := cfg.Port
 	gossipAddr := fmt.Sprintf(":%d", port)
-	gossipService := services.NewGossipService(queries, instanceName, gossipAddr)
+	gossipService := services.NewGossipService(database, cfg.InstanceName, gossipAddr)
+	gossipService.SetKeyring(backpackService.Keyring())
+	gossipService.SetLogger(logger)
+	gossipService.SetPeerTimeout(cfg.Gossip.PeerTimeout)
 
 	// Get instance info
 	instanceInfo, err := gossipService.GetInstanceInfo(ctx)
@@ -86,31 +115,40 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Create discovery service
-	discoveryService := services.NewDiscoveryService(
-		instanceInfo.InstanceID,
-		instanceName,
-		port,
+	// Back up on schedule, if configured
+	backupScheduler := services.NewBackupScheduler(
+		backpackService,
+		cfg.BackupSchedule.Interval,
+		cfg.BackupSchedule.Destinations,
+		cfg.BackupRetention,
 		logger,
-		gossipService,
 	)
-
-	// Start discovery (announce and browse)
-	if err := discoveryService.Start(ctx); err != nil {
-		logger.Warn("Failed to start discovery service", "error", err)
-		// Not a fatal error, continue without discovery
-	}
+	go backupScheduler.Run(ctx)
 
 	// Create server
 	srv := &Server{
-		cfg:              cfg,
-		database:         database,
-		backpackService:  backpackService,
-		gossipService:    gossipService,
-		discoveryService: discoveryService,
-		logger:           logger,
+		initial:         cfg,
+		profile:         *profile,
+		cfg:             cfg,
+		database:        database,
+		backpackService: backpackService,
+		gossipService:   gossipService,
+		backupScheduler: backupScheduler,
+		instanceID:      instanceInfo.InstanceID,
+		logger:          logger,
+		level:           &level,
+		syncChanged:     make(chan struct{}, 1),
 	}
 
+	// Start discovery (announce and browse), if enabled
+	srv.startDiscovery(ctx, cfg.Discovery)
+
+	// Sync with peers on schedule, if configured
+	go srv.syncPeersOnSchedule(ctx)
+
+	// Apply changes to the config file, or on SIGHUP
+	srv.watchConfig(ctx)
+
 	// Setup HTTP server
 	mux := http.NewServeMux()
 	srv.setupRoutes(mux)
@@ -148,11 +186,7 @@ func main() {
 	}
 
 	// Stop discovery service
-	if srv.discoveryService != nil {
-		if err := srv.discoveryService.Stop(); err != nil {
-			logger.Error("Failed to stop discovery service", "error", err)
-		}
-	}
+	srv.stopDiscovery()
 
 	logger.Info("Server exited")
 }
@@ -161,20 +195,123 @@ func (s *Server) setupRoutes(mux *http.ServeMux) {
 	// Health check
 	mux.HandleFunc("/health", s.handleHealth)
 
+	// Documentation health report
+	mux.HandleFunc("/api/v1/health/report", s.handleHealthReport)
+	mux.HandleFunc("/api/v1/health/rules", s.handleHealthRules)
+
 	// Items endpoints
 	mux.HandleFunc("/api/v1/items", s.handleItems)
 	mux.HandleFunc("/api/v1/items/", s.handleItemByID)
+	mux.HandleFunc("/api/v1/import/csv", s.withoutDeadlines(s.handleImportCSV))
+	mux.HandleFunc("/api/v1/import/json", s.withoutDeadlines(s.handleImportJSON))
+	mux.HandleFunc("/api/v1/export/json", s.withoutDeadlines(s.handleExport))
+	mux.HandleFunc("/api/v1/export/csv", s.withoutDeadlines(s.handleExport))
+	mux.HandleFunc("/api/v1/backups", s.withoutDeadlines(s.handleBackups))
+	mux.HandleFunc("/api/v1/backups/prune", s.withoutDeadlines(s.handleBackupsPrune))
+	mux.HandleFunc("/api/v1/backups/schedule", s.handleBackupSchedule)
+	mux.HandleFunc("/api/v1/backups/{name}/verify", s.withoutDeadlines(s.handleBackupVerify))
+	mux.HandleFunc("/api/v1/encryption", s.handleEncryption)
 
 	// Assets endpoints
-	mux.HandleFunc("/api/v1/items/{id}/assets", s.handleAssets)
+	mux.HandleFunc("/api/v1/items/{id}/assets", s.withoutDeadlines(s.handleAssets))
+	mux.HandleFunc("/api/v1/items/{id}/photos", s.withoutDeadlines(s.handleItemPhotos))
+	mux.HandleFunc("/api/v1/photos/import-legacy", s.withoutDeadlines(s.handleImportLegacyPhotos))
 	mux.HandleFunc("/api/v1/assets/", s.handleAssetByID)
+	mux.HandleFunc("/api/v1/assets/{id}/history", s.handleAssetHistory)
+	mux.HandleFunc("/api/v1/assets/{id}/current", s.handleAssetCurrent)
+	mux.HandleFunc("/api/v1/assets/{id}/version", s.handleAssetVersion)
+	mux.HandleFunc("/api/v1/assets/{id}/file", s.withoutDeadlines(s.handleAssetFile))
+	mux.HandleFunc("/api/v1/assets/{id}/thumbnail", s.handleAssetThumbnail)
+	mux.HandleFunc("/api/v1/assets/{id}/share", s.handleAssetShare)
+	mux.HandleFunc("/api/v1/assets/{id}/revisions", s.withoutDeadlines(s.handleAssetRevisions))
+
+	// Repair log endpoints
+	mux.HandleFunc("/api/v1/items/{id}/repairs", s.handleItemRepairs)
+	mux.HandleFunc("/api/v1/repairs/{id}", s.handleRepairByID)
+
+	// Maintenance endpoints
+	mux.HandleFunc("/api/v1/items/{id}/maintenance", s.handleItemMaintenance)
+	mux.HandleFunc("/api/v1/items/{id}/usage", s.handleItemUsage)
+	mux.HandleFunc("/api/v1/maintenance/due", s.handleMaintenanceDue)
+	mux.HandleFunc("/api/v1/maintenance/{id}", s.handleMaintenanceByID)
+	mux.HandleFunc("/api/v1/maintenance/{id}/done", s.handleMaintenanceDone)
+
+	// Warranty endpoints
+	mux.HandleFunc("/api/v1/items/{id}/warranty", s.handleItemWarranty)
+	mux.HandleFunc("/api/v1/warranties/expiring", s.handleExpiringWarranties)
+
+	// Loan endpoints
+	mux.HandleFunc("/api/v1/loans", s.handleLoans)
+	mux.HandleFunc("/api/v1/loans/{id}", s.handleLoanByID)
+	mux.HandleFunc("/api/v1/loans/{id}/return", s.handleLoanReturn)
+	mux.HandleFunc("/api/v1/items/{id}/loans", s.handleItemLoans)
+
+	// Help announcement endpoints
+	mux.HandleFunc("/api/v1/announcements", s.handleAnnouncements)
+	mux.HandleFunc("/api/v1/announcements/{id}", s.handleAnnouncementByID)
+
+	// Spare parts endpoints
+	mux.HandleFunc("/api/v1/parts", s.handleParts)
+	mux.HandleFunc("/api/v1/parts/{id}", s.handlePartByID)
+	mux.HandleFunc("/api/v1/parts/{id}/stock", s.handlePartStock)
+	mux.HandleFunc("/api/v1/items/{id}/parts", s.handleItemParts)
+
+	// Asset types endpoints
+	mux.HandleFunc("/api/v1/asset-types", s.handleAssetTypes)
+	mux.HandleFunc("/api/v1/asset-types/", s.handleAssetTypeByName)
+
+	// Attribute templates endpoints
+	mux.HandleFunc("/api/v1/attribute-templates", s.handleAttributeTemplates)
+
+	// Categories and tags endpoints
+	mux.HandleFunc("/api/v1/categories", s.handleCategories)
+	mux.HandleFunc("/api/v1/categories/{name}", s.handleCategoryByName)
+	mux.HandleFunc("/api/v1/categories/{name}/synonyms", s.handleCategorySynonyms)
+	mux.HandleFunc("/api/v1/categories/{name}/synonyms/{synonym}", s.handleCategorySynonym)
+	mux.HandleFunc("/api/v1/categories/{name}/merge", s.handleCategoryMerge)
+	mux.HandleFunc("/api/v1/tags", s.handleTags)
+
+	// Product model endpoints
+	mux.HandleFunc("/api/v1/product-models", s.handleProductModels)
+	mux.HandleFunc("/api/v1/product-models/{id}", s.handleProductModelByID)
+	mux.HandleFunc("/api/v1/product-models/{id}/aliases", s.handleProductModelAliases)
+	mux.HandleFunc("/api/v1/product-models/{id}/assets", s.handleProductModelAssets)
 
 	// Gossip endpoints
 	mux.HandleFunc("/api/v1/gossip/info", s.handleGossipInfo)
 	mux.HandleFunc("/api/v1/gossip/changes", s.handleGossipChanges)
+	mux.HandleFunc("/api/v1/gossip/asset-types", s.handleGossipAssetTypes)
+	mux.HandleFunc("/api/v1/gossip/product-models", s.handleGossipProductModels)
+	mux.HandleFunc("/api/v1/gossip/parts", s.handleGossipParts)
+	mux.HandleFunc("/api/v1/gossip/announcements", s.handleGossipAnnouncements)
+	mux.HandleFunc("/api/v1/gossip/loans", s.handleGossipLoans)
 	mux.HandleFunc("/api/v1/gossip/peers", s.handlePeers)
 	mux.HandleFunc("/api/v1/gossip/peers/", s.handlePeerByID)
-	mux.HandleFunc("/api/v1/gossip/sync/", s.handleSync)
+	mux.HandleFunc("/api/v1/gossip/sync/", s.withoutDeadlines(s.handleSync))
+	mux.HandleFunc("/api/v1/gossip/search", s.handleGossipSearch)
+	mux.HandleFunc("/api/v1/gossip/items/", s.handleGossipItem)
+	mux.HandleFunc("/api/v1/gossip/assets/", s.withoutDeadlines(s.handleGossipAsset))
+	mux.HandleFunc("/api/v1/gossip/thumbnails/", s.handleGossipThumbnail)
+
+	// Federated search endpoints
+	mux.HandleFunc("/api/v1/search", s.handleFederatedSearch)
+	mux.HandleFunc("/api/v1/search/fetch", s.withoutDeadlines(s.handleFetchFromPeer))
+}
+
+// withoutDeadlines lifts the read and write timeouts of the server for a
+// route whose duration grows with the inventory, e.g. a backup or an upload,
+// which would otherwise be cut off partway
+func (s *Server) withoutDeadlines(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rc := http.NewResponseController(w)
+		if err := rc.SetReadDeadline(time.Time{}); err != nil {
+			s.logger.Warn("Failed to lift read deadline", "path", r.URL.Path, "error", err)
+		}
+		if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+			s.logger.Warn("Failed to lift write deadline", "path", r.URL.Path, "error", err)
+		}
+		next(w, r)
+	}
 }
 
 // Middleware
@@ -192,7 +329,12 @@ func (s *Server) loggingMiddleware(next http.Handler) http.Handler {
 
 func (s *Server) corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
+		if origin := s.allowedOrigin(r.Header.Get("Origin")); origin != "" {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			if origin != "*" {
+				w.Header().Add("Vary", "Origin")
+			}
+		}
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
 
@@ -212,10 +354,42 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	json.NewEncoder(w).Encode(map[string]string{
-		"status": "ok",
-		"time":   time.Now().Format(time.RFC3339),
-	})
+	// The service is up even when backups fail, which they report on their own
+	health := map[string]any{
+		"status":  "ok",
+		"time":    time.Now().Format(time.RFC3339),
+		"backups": s.backupScheduler.Status(),
+	}
+	if restart := s.settingsRequiringRestart(); len(restart) > 0 {
+		health["restart_required"] = restart
+	}
+	json.NewEncoder(w).Encode(health)
+}
+
+func (s *Server) handleHealthReport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	reports, err := s.backpackService.HealthReport(ctx)
+	if err != nil {
+		s.jsonError(w, "Failed to build health report", http.StatusInternalServerError)
+		return
+	}
+
+	s.jsonResponse(w, reports)
+}
+
+func (s *Server) handleHealthRules(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.jsonResponse(w, s.backpackService.GetHealthRules())
 }
 
 func (s *Server) handleItems(w http.ResponseWriter, r *http.Request) {
@@ -223,10 +397,27 @@ func (s *Server) handleItems(w http.ResponseWriter, r *http.Request) {
 
 	switch r.Method {
 	case http.MethodGet:
-		// List items
-		items, err := s.backpackService.GetAllItems(ctx)
+		// List items, optionally filtered by text, category subtree, tag and
+		// attribute (?q=bosch&category=Maison&tag=garage&attr=voltage>=220)
+		query := r.URL.Query()
+		filter := models.ItemFilter{
+			Query:      query.Get("q"),
+			Category:   query.Get("category"),
+			Tags:       query["tag"],
+			Attributes: []models.AttributeFilter{},
+		}
+		for _, expr := range query["attr"] {
+			attrFilter, err := services.ParseAttributeFilter(expr)
+			if err != nil {
+				s.jsonError(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			filter.Attributes = append(filter.Attributes, attrFilter)
+		}
+
+		items, err := s.backpackService.FilterItems(ctx, filter)
 		if err != nil {
-			s.jsonError(w, "Failed to list items", http.StatusInternalServerError)
+			s.jsonError(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 		s.jsonResponse(w, items)
@@ -250,6 +441,168 @@ func (s *Server) handleItems(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (s *Server) handleImportCSV(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Multipart form with the CSV file, an optional column mapping as JSON
+	// ({"serial_number": "S/N"}) and dry_run=true to only get the report
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		s.jsonError(w, "CSV file is required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	options := models.CSVImportOptions{DryRun: r.FormValue("dry_run") == "true"}
+	if columns := r.FormValue("columns"); columns != "" {
+		if err := json.Unmarshal([]byte(columns), &options.Columns); err != nil {
+			s.jsonError(w, "Invalid columns", http.StatusBadRequest)
+			return
+		}
+	}
+
+	report, err := s.backpackService.ImportCSV(r.Context(), file, options)
+	if err != nil {
+		s.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.jsonResponse(w, report)
+}
+
+func (s *Server) handleImportJSON(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Multipart form with the file of a JSON export
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		s.jsonError(w, "JSON file is required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	imported, skipped, err := s.backpackService.ImportJSON(r.Context(), file)
+	if err != nil {
+		s.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.jsonResponse(w, map[string]int{"imported": imported, "skipped": skipped})
+}
+
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	export, contentType, extension := s.backpackService.ExportJSON, "application/json", "json"
+	if strings.HasSuffix(r.URL.Path, "/csv") {
+		export, contentType, extension = s.backpackService.ExportCSV, "text/csv; charset=utf-8", "csv"
+	}
+
+	// Buffered for the number of items to go in a header
+	var body bytes.Buffer
+	count, err := export(r.Context(), &body)
+	if err != nil {
+		s.jsonError(w, "Failed to export items", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"brique-export-%s.%s\"", time.Now().Format("2006-01-02"), extension))
+	w.Header().Set("X-Item-Count", strconv.Itoa(count))
+	body.WriteTo(w)
+}
+
+func (s *Server) handleBackups(w http.ResponseWriter, r *http.Request) {
+	cfg := s.config()
+
+	switch r.Method {
+	case http.MethodGet:
+		backups, err := services.ListBackups(cfg.BackupsDir)
+		if err != nil {
+			s.jsonError(w, "Failed to list backups", http.StatusInternalServerError)
+			return
+		}
+		s.jsonResponse(w, backups)
+
+	case http.MethodPost:
+		backup, err := s.backpackService.CreateBackup(r.Context(), cfg.BackupsDir)
+		if err != nil {
+			s.logger.Error("Failed to create backup", "error", err)
+			s.jsonError(w, "Failed to create backup", http.StatusInternalServerError)
+			return
+		}
+		if _, err := services.PruneBackups(r.Context(), cfg.BackupsDir, cfg.BackupRetention); err != nil {
+			s.logger.Warn("Failed to prune backups", "error", err)
+		}
+		s.jsonResponse(w, backup)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleEncryption(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	status, err := s.backpackService.EncryptionStatus(r.Context())
+	if err != nil {
+		s.jsonError(w, "Failed to get encryption status", http.StatusInternalServerError)
+		return
+	}
+	s.jsonResponse(w, status)
+}
+
+func (s *Server) handleBackupSchedule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	s.jsonResponse(w, s.backupScheduler.Status())
+}
+
+func (s *Server) handleBackupsPrune(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	cfg := s.config()
+	removed, err := services.PruneBackups(r.Context(), cfg.BackupsDir, cfg.BackupRetention)
+	if err != nil {
+		s.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.jsonResponse(w, removed)
+}
+
+func (s *Server) handleBackupVerify(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	backup, err := services.VerifyBackup(r.Context(), s.config().BackupsDir, r.PathValue("name"))
+	if errors.Is(err, services.ErrBackupNotFound) {
+		s.jsonError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		s.jsonError(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	s.jsonResponse(w, backup)
+}
+
 func (s *Server) handleItemByID(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -268,7 +621,17 @@ func (s *Server) handleItemByID(w http.ResponseWriter, r *http.Request) {
 
 	switch r.Method {
 	case http.MethodGet:
-		// Get item by ID
+		// Get item by ID, with its assets and documentation health (?assets=true)
+		if r.URL.Query().Get("assets") == "true" {
+			item, err := s.backpackService.GetItemWithAssets(ctx, id)
+			if err != nil {
+				s.jsonError(w, "Item not found", http.StatusNotFound)
+				return
+			}
+			s.jsonResponse(w, item)
+			return
+		}
+
 		item, err := s.backpackService.GetItem(ctx, id)
 		if err != nil {
 			s.jsonError(w, "Item not found", http.StatusNotFound)
@@ -307,11 +670,6 @@ func (s *Server) handleItemByID(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleAssets(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	// Extract item ID from path
 	idStr := r.URL.Path[len("/api/v1/items/"):]
 	idStr = idStr[:len(idStr)-len("/assets")]
@@ -322,12 +680,34 @@ func (s *Server) handleAssets(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	assets, err := s.backpackService.GetItemAssets(ctx, itemID)
-	if err != nil {
-		s.jsonError(w, "Failed to list assets", http.StatusInternalServerError)
-		return
+	switch r.Method {
+	case http.MethodGet:
+		assets, err := s.backpackService.GetItemAssets(ctx, itemID)
+		if err != nil {
+			s.jsonError(w, "Failed to list assets", http.StatusInternalServerError)
+			return
+		}
+		s.jsonResponse(w, assets)
+
+	case http.MethodPost:
+		// Upload a file as multipart form, with its type and an optional name
+		path, err := receiveUpload(r)
+		if err != nil {
+			s.jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer os.RemoveAll(filepath.Dir(path))
+
+		asset, err := s.backpackService.AddAsset(ctx, itemID, models.AssetType(r.FormValue("type")), uploadName(r, path), path)
+		if err != nil {
+			s.jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.jsonResponse(w, asset)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 	}
-	s.jsonResponse(w, assets)
 }
 
 func (s *Server) handleAssetByID(w http.ResponseWriter, r *http.Request) {
@@ -360,7 +740,7 @@ func (s *Server) handleAssetByID(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (s *Server) handleGossipInfo(w http.ResponseWriter, r *http.Request) {
+func (s *Server) handleAssetHistory(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	if r.Method != http.MethodGet {
@@ -368,86 +748,1392 @@ func (s *Server) handleGossipInfo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	info, err := s.gossipService.GetInstanceInfo(ctx)
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
 	if err != nil {
-		s.jsonError(w, "Failed to get instance info", http.StatusInternalServerError)
+		s.jsonError(w, "Invalid asset ID", http.StatusBadRequest)
 		return
 	}
 
-	s.jsonResponse(w, map[string]interface{}{
-		"instance_id":   info.InstanceID,
-		"instance_name": info.InstanceName,
-		"last_sync":     info.LastSync,
-		"item_count":    info.ItemCount,
-	})
+	history, err := s.backpackService.GetAssetHistory(ctx, id)
+	if err != nil {
+		s.jsonError(w, "Asset not found", http.StatusNotFound)
+		return
+	}
+	s.jsonResponse(w, history)
 }
 
-func (s *Server) handleGossipChanges(w http.ResponseWriter, r *http.Request) {
+func (s *Server) handleAssetCurrent(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	if r.Method != http.MethodGet {
+	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	sinceStr := r.URL.Query().Get("since")
-	var since time.Time
-	var err error
-
-	if sinceStr != "" {
-		since, err = time.Parse(time.RFC3339, sinceStr)
-		if err != nil {
-			s.jsonError(w, "Invalid timestamp format", http.StatusBadRequest)
-			return
-		}
-	}
-
-	changes, err := s.gossipService.GetChanges(ctx, since)
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
 	if err != nil {
-		s.jsonError(w, "Failed to get changes", http.StatusInternalServerError)
+		s.jsonError(w, "Invalid asset ID", http.StatusBadRequest)
 		return
 	}
 
-	s.jsonResponse(w, changes)
+	if err := s.backpackService.SetCurrentRevision(ctx, id); err != nil {
+		s.jsonError(w, "Failed to set current revision", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
 }
 
-func (s *Server) handlePeers(w http.ResponseWriter, r *http.Request) {
+func (s *Server) handleAssetVersion(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	switch r.Method {
-	case http.MethodGet:
-		// List all peers
-		peers, err := s.gossipService.GetPeers(ctx)
-		if err != nil {
-			s.jsonError(w, "Failed to get peers", http.StatusInternalServerError)
-			return
-		}
-		s.jsonResponse(w, peers)
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
 
-	case http.MethodPost:
-		// Add a new peer manually
-		var req struct {
-			Name      string `json:"name"`
-			Address   string `json:"address"`
-			IsTrusted bool   `json:"is_trusted"`
-		}
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		s.jsonError(w, "Invalid asset ID", http.StatusBadRequest)
+		return
+	}
 
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			s.jsonError(w, "Invalid request body", http.StatusBadRequest)
-			return
-		}
+	var req struct {
+		VersionLabel string     `json:"version_label"`
+		ReleaseDate  *time.Time `json:"release_date"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.jsonError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
 
-		if req.Name == "" || req.Address == "" {
-			s.jsonError(w, "Name and address are required", http.StatusBadRequest)
-			return
-		}
+	if err := s.backpackService.SetAssetVersion(ctx, id, req.VersionLabel, req.ReleaseDate); err != nil {
+		s.jsonError(w, "Failed to update asset version", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
 
-		// Generate a unique ID for the peer
-		peerID := fmt.Sprintf("%s.manual", req.Address)
+func (s *Server) handleAssetShare(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
 
-		peer := &models.Peer{
-			ID:        peerID,
-			Name:      req.Name,
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		s.jsonError(w, "Invalid asset ID", http.StatusBadRequest)
+		return
+	}
+
+	asset, err := s.backpackService.ShareAsset(r.Context(), id)
+	if err != nil {
+		s.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.jsonResponse(w, asset)
+}
+
+func (s *Server) handleAssetRevisions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	previousID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		s.jsonError(w, "Invalid asset ID", http.StatusBadRequest)
+		return
+	}
+
+	// Upload the new version as multipart form, with optional name,
+	// version_label and release_date (RFC 3339)
+	path, err := receiveUpload(r)
+	if err != nil {
+		s.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer os.RemoveAll(filepath.Dir(path))
+
+	var releaseDate *time.Time
+	if value := r.FormValue("release_date"); value != "" {
+		date, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			s.jsonError(w, "Invalid release_date", http.StatusBadRequest)
+			return
+		}
+		releaseDate = &date
+	}
+
+	asset, err := s.backpackService.AddAssetRevision(r.Context(), previousID, r.FormValue("name"), path, r.FormValue("version_label"), releaseDate)
+	if err != nil {
+		s.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.jsonResponse(w, asset)
+}
+
+func (s *Server) handleItemPhotos(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	itemID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		s.jsonError(w, "Invalid item ID", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		photos, err := s.backpackService.GetItemPhotos(ctx, itemID)
+		if err != nil {
+			s.jsonError(w, "Failed to list photos", http.StatusInternalServerError)
+			return
+		}
+		s.jsonResponse(w, photos)
+
+	case http.MethodPost:
+		// Upload a photo as multipart form, named after its file
+		path, err := receiveUpload(r)
+		if err != nil {
+			s.jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer os.RemoveAll(filepath.Dir(path))
+
+		photo, err := s.backpackService.AddPhoto(ctx, itemID, path)
+		if err != nil {
+			s.jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.jsonResponse(w, photo)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleImportLegacyPhotos(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	imported, err := s.backpackService.ImportLegacyPhotos(r.Context())
+	if err != nil {
+		s.jsonError(w, "Failed to import photos", http.StatusInternalServerError)
+		return
+	}
+	s.jsonResponse(w, map[string]int{"imported": imported})
+}
+
+func (s *Server) handleAssetFile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		s.jsonError(w, "Invalid asset ID", http.StatusBadRequest)
+		return
+	}
+
+	s.serveAssetFile(w, r, id)
+}
+
+func (s *Server) handleAssetThumbnail(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		s.jsonError(w, "Invalid asset ID", http.StatusBadRequest)
+		return
+	}
+
+	s.serveThumbnail(w, r, id)
+}
+
+// unlockEncryption unlocks the data encrypted at rest, if any, with the
+// passphrase of the configuration
+func unlockEncryption(cfg *config.Config, backpackService *services.BackpackService) error {
+	ctx := context.Background()
+
+	status, err := backpackService.EncryptionStatus(ctx)
+	if err != nil || !status.Enabled {
+		return err
+	}
+
+	passphrase, err := cfg.Passphrase()
+	if err != nil {
+		return err
+	}
+	if passphrase == "" {
+		return fmt.Errorf("%w: set BRIQUE_PASSPHRASE or passphrase_file", services.ErrEncryptionLocked)
+	}
+
+	return backpackService.UnlockEncryption(ctx, passphrase)
+}
+
+// serveAssetFile writes the file of an asset, decrypted
+func (s *Server) serveAssetFile(w http.ResponseWriter, r *http.Request, assetID int64) {
+	asset, file, err := s.backpackService.OpenAsset(r.Context(), assetID)
+	if errors.Is(err, sql.ErrNoRows) {
+		s.jsonError(w, "Asset not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		s.logger.Error("Failed to open asset file", "asset", assetID, "error", err)
+		s.jsonError(w, "Asset file not available", http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+
+	http.ServeContent(w, r, filepath.Base(asset.FilePath), asset.CreatedAt, file)
+}
+
+func (s *Server) serveThumbnail(w http.ResponseWriter, r *http.Request, assetID int64) {
+	file, err := s.backpackService.OpenThumbnail(r.Context(), assetID)
+	if err != nil {
+		s.jsonError(w, "Thumbnail not available", http.StatusNotFound)
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", "image/jpeg")
+	http.ServeContent(w, r, "", time.Time{}, file)
+}
+
+func (s *Server) handleItemRepairs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	itemID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		s.jsonError(w, "Invalid item ID", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		// Repair history, most recent first
+		repairs, err := s.backpackService.GetItemRepairs(ctx, itemID)
+		if err != nil {
+			s.jsonError(w, "Item not found", http.StatusNotFound)
+			return
+		}
+		s.jsonResponse(w, repairs)
+
+	case http.MethodPost:
+		var event models.RepairEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			s.jsonError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		event.ItemID = itemID
+
+		if err := s.backpackService.AddRepairEvent(ctx, &event); err != nil {
+			s.jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.jsonResponse(w, event)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleRepairByID(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		s.jsonError(w, "Invalid repair ID", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		event, err := s.backpackService.GetRepairEvent(ctx, id)
+		if err != nil {
+			s.jsonError(w, "Repair not found", http.StatusNotFound)
+			return
+		}
+		s.jsonResponse(w, event)
+
+	case http.MethodPut:
+		// A null asset_ids keeps the linked assets
+		var event models.RepairEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			s.jsonError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		event.ID = id
+
+		if err := s.backpackService.UpdateRepairEvent(ctx, &event); err != nil {
+			s.jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.jsonResponse(w, event)
+
+	case http.MethodDelete:
+		if err := s.backpackService.DeleteRepairEvent(ctx, id); err != nil {
+			s.jsonError(w, "Repair not found", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleItemMaintenance(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	itemID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		s.jsonError(w, "Invalid item ID", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		// Schedules with their next due date or usage
+		tasks, err := s.backpackService.GetItemMaintenance(ctx, itemID)
+		if err != nil {
+			s.jsonError(w, "Item not found", http.StatusNotFound)
+			return
+		}
+		s.jsonResponse(w, tasks)
+
+	case http.MethodPost:
+		var schedule models.MaintenanceSchedule
+		if err := json.NewDecoder(r.Body).Decode(&schedule); err != nil {
+			s.jsonError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		schedule.ItemID = itemID
+
+		if err := s.backpackService.AddMaintenanceSchedule(ctx, &schedule); err != nil {
+			s.jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.jsonResponse(w, schedule)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleItemUsage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	itemID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		s.jsonError(w, "Invalid item ID", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		usage, err := s.backpackService.GetItemUsage(ctx, itemID)
+		if err != nil {
+			s.jsonError(w, "Failed to get usage", http.StatusInternalServerError)
+			return
+		}
+		s.jsonResponse(w, usage)
+
+	case http.MethodPut:
+		var req models.UsageCounter
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.jsonError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		if err := s.backpackService.RecordUsage(ctx, itemID, req.Unit, req.Value); err != nil {
+			s.jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleMaintenanceDue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Overdue tasks, and optionally those due in the next days (?within_days=7)
+	var withinDays int64
+	if value := r.URL.Query().Get("within_days"); value != "" {
+		var err error
+		withinDays, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || withinDays < 0 {
+			s.jsonError(w, "Invalid within_days", http.StatusBadRequest)
+			return
+		}
+	}
+
+	tasks, err := s.backpackService.GetDueMaintenance(r.Context(), time.Duration(withinDays)*24*time.Hour)
+	if err != nil {
+		s.jsonError(w, "Failed to list due maintenance", http.StatusInternalServerError)
+		return
+	}
+	s.jsonResponse(w, tasks)
+}
+
+func (s *Server) handleMaintenanceByID(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		s.jsonError(w, "Invalid maintenance schedule ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.backpackService.DeleteMaintenanceSchedule(r.Context(), id); err != nil {
+		s.jsonError(w, "Maintenance schedule not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleMaintenanceDone(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		s.jsonError(w, "Invalid maintenance schedule ID", http.StatusBadRequest)
+		return
+	}
+
+	// The body is optional, the task is done now by default
+	var req struct {
+		DoneAt time.Time `json:"done_at"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.jsonError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	task, err := s.backpackService.CompleteMaintenance(r.Context(), id, req.DoneAt)
+	if err != nil {
+		s.jsonError(w, "Maintenance schedule not found", http.StatusNotFound)
+		return
+	}
+	s.jsonResponse(w, task)
+}
+
+func (s *Server) handleItemWarranty(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	itemID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		s.jsonError(w, "Invalid item ID", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		item, err := s.backpackService.GetItem(ctx, itemID)
+		if err != nil {
+			s.jsonError(w, "Item not found", http.StatusNotFound)
+			return
+		}
+		if item.Warranty == nil {
+			s.jsonError(w, "No warranty for this item", http.StatusNotFound)
+			return
+		}
+		s.jsonResponse(w, item.Warranty)
+
+	case http.MethodPut:
+		var warranty models.Warranty
+		if err := json.NewDecoder(r.Body).Decode(&warranty); err != nil {
+			s.jsonError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		if err := s.backpackService.SetItemWarranty(ctx, itemID, &warranty); err != nil {
+			s.jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.jsonResponse(w, warranty)
+
+	case http.MethodDelete:
+		if err := s.backpackService.SetItemWarranty(ctx, itemID, nil); err != nil {
+			s.jsonError(w, "Item not found", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleExpiringWarranties(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Warranties running out in the next days, 30 by default (?within_days=90)
+	withinDays := int64(30)
+	if value := r.URL.Query().Get("within_days"); value != "" {
+		var err error
+		withinDays, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || withinDays < 0 {
+			s.jsonError(w, "Invalid within_days", http.StatusBadRequest)
+			return
+		}
+	}
+
+	items, err := s.backpackService.GetExpiringWarranties(r.Context(), time.Duration(withinDays)*24*time.Hour)
+	if err != nil {
+		s.jsonError(w, "Failed to list expiring warranties", http.StatusInternalServerError)
+		return
+	}
+	s.jsonResponse(w, items)
+}
+
+func (s *Server) handleLoans(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	switch r.Method {
+	case http.MethodGet:
+		// Running loans, with returned ones (?all=true) or only overdue ones (?overdue=true)
+		var loans []models.Loan
+		var err error
+		if r.URL.Query().Get("overdue") == "true" {
+			loans, err = s.backpackService.GetOverdueLoans(ctx)
+		} else {
+			loans, err = s.backpackService.GetLoans(ctx, r.URL.Query().Get("all") == "true")
+		}
+		if err != nil {
+			s.jsonError(w, "Failed to list loans", http.StatusInternalServerError)
+			return
+		}
+		s.jsonResponse(w, loans)
+
+	case http.MethodPost:
+		var req struct {
+			models.Loan
+			DueDays int64 `json:"due_days"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.jsonError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		loan := req.Loan
+		if loan.DueAt == nil && req.DueDays > 0 {
+			lentAt := loan.LentAt
+			if lentAt.IsZero() {
+				lentAt = time.Now()
+			}
+			dueAt := lentAt.AddDate(0, 0, int(req.DueDays))
+			loan.DueAt = &dueAt
+		}
+		if err := s.backpackService.LendItem(ctx, &loan); err != nil {
+			s.jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.jsonResponse(w, loan)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleLoanByID(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		s.jsonError(w, "Invalid loan ID", http.StatusBadRequest)
+		return
+	}
+
+	loan, err := s.backpackService.GetLoan(r.Context(), id)
+	if err != nil {
+		s.jsonError(w, "Loan not found", http.StatusNotFound)
+		return
+	}
+	s.jsonResponse(w, loan)
+}
+
+func (s *Server) handleLoanReturn(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		s.jsonError(w, "Invalid loan ID", http.StatusBadRequest)
+		return
+	}
+
+	// The return date is optional and defaults to now
+	var req struct {
+		ReturnedAt time.Time `json:"returned_at"`
+	}
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.jsonError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	loan, err := s.backpackService.ReturnLoan(r.Context(), id, req.ReturnedAt)
+	if err != nil {
+		s.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.jsonResponse(w, loan)
+}
+
+func (s *Server) handleItemLoans(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	itemID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		s.jsonError(w, "Invalid item ID", http.StatusBadRequest)
+		return
+	}
+
+	loans, err := s.backpackService.GetItemLoans(r.Context(), itemID)
+	if err != nil {
+		s.jsonError(w, "Item not found", http.StatusNotFound)
+		return
+	}
+	s.jsonResponse(w, loans)
+}
+
+func (s *Server) handleAnnouncements(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	switch r.Method {
+	case http.MethodGet:
+		// Running announcements, optionally of one kind (?kind=offer)
+		kind := models.AnnouncementKind(r.URL.Query().Get("kind"))
+		announcements, err := s.gossipService.GetAnnouncements(ctx, kind)
+		if err != nil {
+			s.jsonError(w, "Failed to list announcements", http.StatusInternalServerError)
+			return
+		}
+		s.jsonResponse(w, announcements)
+
+	case http.MethodPost:
+		var req struct {
+			models.Announcement
+			TTLDays int64 `json:"ttl_days"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.jsonError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		announcement := req.Announcement
+		ttl := time.Duration(req.TTLDays) * 24 * time.Hour
+		if err := s.gossipService.PublishAnnouncement(ctx, &announcement, ttl); err != nil {
+			s.jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.jsonResponse(w, announcement)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleAnnouncementByID(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		s.jsonError(w, "Invalid announcement ID", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		announcement, err := s.gossipService.GetAnnouncement(ctx, id)
+		if err != nil {
+			s.jsonError(w, "Announcement not found", http.StatusNotFound)
+			return
+		}
+		s.jsonResponse(w, announcement)
+
+	case http.MethodDelete:
+		// Withdraws an announcement of this instance
+		if err := s.gossipService.WithdrawAnnouncement(ctx, id); err != nil {
+			s.jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleParts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	switch r.Method {
+	case http.MethodGet:
+		// All parts, or only those running low (?low_stock=true)
+		var parts []models.Part
+		var err error
+		if r.URL.Query().Get("low_stock") == "true" {
+			parts, err = s.backpackService.GetLowStockParts(ctx)
+		} else {
+			parts, err = s.backpackService.GetParts(ctx)
+		}
+		if err != nil {
+			s.jsonError(w, "Failed to list parts", http.StatusInternalServerError)
+			return
+		}
+		s.jsonResponse(w, parts)
+
+	case http.MethodPost:
+		var part models.Part
+		if err := json.NewDecoder(r.Body).Decode(&part); err != nil {
+			s.jsonError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		if err := s.backpackService.CreatePart(ctx, &part); err != nil {
+			s.jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.jsonResponse(w, part)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handlePartByID(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		s.jsonError(w, "Invalid part ID", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		part, err := s.backpackService.GetPart(ctx, id)
+		if err != nil {
+			s.jsonError(w, "Part not found", http.StatusNotFound)
+			return
+		}
+		s.jsonResponse(w, part)
+
+	case http.MethodPut:
+		// Null item_ids, product_model_ids or asset_ids keep those links
+		var part models.Part
+		if err := json.NewDecoder(r.Body).Decode(&part); err != nil {
+			s.jsonError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		part.ID = id
+
+		if err := s.backpackService.UpdatePart(ctx, &part); err != nil {
+			s.jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.jsonResponse(w, part)
+
+	case http.MethodDelete:
+		if err := s.backpackService.DeletePart(ctx, id); err != nil {
+			s.jsonError(w, "Part not found", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handlePartStock(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		s.jsonError(w, "Invalid part ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Delta int64 `json:"delta"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.jsonError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	part, err := s.backpackService.AdjustPartStock(r.Context(), id, req.Delta)
+	if err != nil {
+		s.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.jsonResponse(w, part)
+}
+
+func (s *Server) handleItemParts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	itemID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		s.jsonError(w, "Invalid item ID", http.StatusBadRequest)
+		return
+	}
+
+	parts, err := s.backpackService.GetItemParts(r.Context(), itemID)
+	if err != nil {
+		s.jsonError(w, "Item not found", http.StatusNotFound)
+		return
+	}
+	s.jsonResponse(w, parts)
+}
+
+func (s *Server) handleAssetTypes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	switch r.Method {
+	case http.MethodGet:
+		// List asset types
+		types, err := s.backpackService.GetAssetTypes(ctx)
+		if err != nil {
+			s.jsonError(w, "Failed to list asset types", http.StatusInternalServerError)
+			return
+		}
+		s.jsonResponse(w, types)
+
+	case http.MethodPost:
+		// Create or update an asset type
+		var def models.AssetTypeDefinition
+		if err := json.NewDecoder(r.Body).Decode(&def); err != nil {
+			s.jsonError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		if err := s.backpackService.SaveAssetType(ctx, &def); err != nil {
+			s.jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.jsonResponse(w, def)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleAssetTypeByName(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Extract name from path
+	name := r.URL.Path[len("/api/v1/asset-types/"):]
+	if name == "" {
+		s.jsonError(w, "Asset type name is required", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		def, err := s.backpackService.GetAssetType(ctx, models.AssetType(name))
+		if err != nil {
+			s.jsonError(w, "Asset type not found", http.StatusNotFound)
+			return
+		}
+		s.jsonResponse(w, def)
+
+	case http.MethodDelete:
+		if err := s.backpackService.DeleteAssetType(ctx, models.AssetType(name)); err != nil {
+			s.jsonError(w, err.Error(), http.StatusConflict)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleAttributeTemplates(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if category := r.URL.Query().Get("category"); category != "" {
+		s.jsonResponse(w, s.backpackService.GetAttributeTemplate(category))
+		return
+	}
+
+	s.jsonResponse(w, s.backpackService.GetAttributeTemplates())
+}
+
+func (s *Server) handleCategories(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	switch r.Method {
+	case http.MethodGet:
+		// List categories with their synonyms
+		categories, err := s.backpackService.GetCategories(ctx)
+		if err != nil {
+			s.jsonError(w, "Failed to list categories", http.StatusInternalServerError)
+			return
+		}
+		s.jsonResponse(w, categories)
+
+	case http.MethodPost:
+		// Create a category, optionally under a parent
+		var req struct {
+			Name   string `json:"name"`
+			Parent string `json:"parent"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.jsonError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		category, err := s.backpackService.CreateCategory(ctx, req.Name, req.Parent)
+		if err != nil {
+			s.jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.jsonResponse(w, category)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleCategoryByName(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	name := r.PathValue("name")
+
+	switch r.Method {
+	case http.MethodPut:
+		// Rename and/or move a category. A null parent keeps it in place, an
+		// empty one moves it to the top level.
+		var req struct {
+			Name   string  `json:"name"`
+			Parent *string `json:"parent"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.jsonError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		if req.Parent != nil {
+			if err := s.backpackService.SetCategoryParent(ctx, name, *req.Parent); err != nil {
+				s.jsonError(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		if req.Name != "" && req.Name != name {
+			if err := s.backpackService.RenameCategory(ctx, name, req.Name); err != nil {
+				s.jsonError(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	case http.MethodDelete:
+		if err := s.backpackService.DeleteCategory(ctx, name); err != nil {
+			s.jsonError(w, err.Error(), http.StatusConflict)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleCategorySynonyms(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Synonym string `json:"synonym"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.jsonError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.backpackService.AddCategorySynonym(r.Context(), r.PathValue("name"), req.Synonym); err != nil {
+		s.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleCategorySynonym(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := s.backpackService.RemoveCategorySynonym(r.Context(), r.PathValue("synonym")); err != nil {
+		s.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleCategoryMerge(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Into string `json:"into"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.jsonError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.backpackService.MergeCategory(r.Context(), r.PathValue("name"), req.Into); err != nil {
+		s.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleTags(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tags, err := s.backpackService.GetTags(r.Context())
+	if err != nil {
+		s.jsonError(w, "Failed to list tags", http.StatusInternalServerError)
+		return
+	}
+	s.jsonResponse(w, tags)
+}
+
+func (s *Server) handleProductModels(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	productModels, err := s.backpackService.GetProductModels(r.Context())
+	if err != nil {
+		s.jsonError(w, "Failed to list product models", http.StatusInternalServerError)
+		return
+	}
+	s.jsonResponse(w, productModels)
+}
+
+func (s *Server) handleProductModelByID(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		s.jsonError(w, "Invalid product model ID", http.StatusBadRequest)
+		return
+	}
+
+	productModel, err := s.backpackService.GetProductModel(r.Context(), id)
+	if err != nil {
+		s.jsonError(w, "Product model not found", http.StatusNotFound)
+		return
+	}
+	s.jsonResponse(w, productModel)
+}
+
+func (s *Server) handleProductModelAliases(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		s.jsonError(w, "Invalid product model ID", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		var req models.ProductModelAlias
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.jsonError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		// An alias naming another product model merges it into this one
+		if err := s.backpackService.AddProductModelAlias(ctx, id, req.Brand, req.Model); err != nil {
+			s.jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	case http.MethodDelete:
+		brand, model := r.URL.Query().Get("brand"), r.URL.Query().Get("model")
+		if err := s.backpackService.RemoveProductModelAlias(ctx, brand, model); err != nil {
+			s.jsonError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleProductModelAssets(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		s.jsonError(w, "Invalid product model ID", http.StatusBadRequest)
+		return
+	}
+
+	// Upload a document shared by every item of the product model, as
+	// multipart form with its type and an optional name
+	path, err := receiveUpload(r)
+	if err != nil {
+		s.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer os.RemoveAll(filepath.Dir(path))
+
+	asset, err := s.backpackService.AddProductModelAsset(r.Context(), id, models.AssetType(r.FormValue("type")), uploadName(r, path), path)
+	if err != nil {
+		s.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.jsonResponse(w, asset)
+}
+
+func (s *Server) handleGossipInfo(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	info, err := s.gossipService.GetInstanceInfo(ctx)
+	if err != nil {
+		s.jsonError(w, "Failed to get instance info", http.StatusInternalServerError)
+		return
+	}
+
+	s.jsonResponse(w, map[string]interface{}{
+		"instance_id":   info.InstanceID,
+		"instance_name": info.InstanceName,
+		"public_key":    info.PublicKey,
+		"last_sync":     info.LastSync,
+		"item_count":    info.ItemCount,
+	})
+}
+
+func (s *Server) handleGossipChanges(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	sinceStr := r.URL.Query().Get("since")
+	var since time.Time
+	var err error
+
+	if sinceStr != "" {
+		since, err = time.Parse(time.RFC3339, sinceStr)
+		if err != nil {
+			s.jsonError(w, "Invalid timestamp format", http.StatusBadRequest)
+			return
+		}
+	}
+
+	changes, err := s.gossipService.GetChanges(ctx, since)
+	if err != nil {
+		s.jsonError(w, "Failed to get changes", http.StatusInternalServerError)
+		return
+	}
+
+	s.jsonResponse(w, changes)
+}
+
+func (s *Server) handleGossipAssetTypes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var since time.Time
+	if sinceStr := r.URL.Query().Get("since"); sinceStr != "" {
+		var err error
+		since, err = time.Parse(time.RFC3339, sinceStr)
+		if err != nil {
+			s.jsonError(w, "Invalid timestamp format", http.StatusBadRequest)
+			return
+		}
+	}
+
+	types, err := s.gossipService.GetAssetTypeChanges(ctx, since)
+	if err != nil {
+		s.jsonError(w, "Failed to get asset type changes", http.StatusInternalServerError)
+		return
+	}
+
+	s.jsonResponse(w, types)
+}
+
+func (s *Server) handleGossipProductModels(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	catalogue, err := s.backpackService.GetProductModelCatalogue(r.Context())
+	if err != nil {
+		s.jsonError(w, "Failed to get product models", http.StatusInternalServerError)
+		return
+	}
+
+	s.jsonResponse(w, catalogue)
+}
+
+func (s *Server) handleGossipParts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	parts, err := s.gossipService.GetSharedParts(r.Context())
+	if err != nil {
+		s.jsonError(w, "Failed to get parts", http.StatusInternalServerError)
+		return
+	}
+
+	s.jsonResponse(w, parts)
+}
+
+func (s *Server) handleGossipAnnouncements(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	announcements, err := s.gossipService.GetSharedAnnouncements(r.Context())
+	if err != nil {
+		s.jsonError(w, "Failed to get announcements", http.StatusInternalServerError)
+		return
+	}
+
+	s.jsonResponse(w, announcements)
+}
+
+func (s *Server) handleGossipLoans(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Loans made to the requesting instance, that signed the request
+	loans, err := s.gossipService.GetSharedLoans(r.Context(), services.ParseLoansRequest(r.URL.Query()))
+	if errors.Is(err, services.ErrUnknownBorrower) {
+		s.jsonError(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		s.jsonError(w, "Failed to get loans", http.StatusInternalServerError)
+		return
+	}
+
+	s.jsonResponse(w, loans)
+}
+
+func (s *Server) handlePeers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	switch r.Method {
+	case http.MethodGet:
+		// List all peers
+		peers, err := s.gossipService.GetPeers(ctx)
+		if err != nil {
+			s.jsonError(w, "Failed to get peers", http.StatusInternalServerError)
+			return
+		}
+		s.jsonResponse(w, peers)
+
+	case http.MethodPost:
+		// Add a new peer manually
+		var req struct {
+			Name      string `json:"name"`
+			Address   string `json:"address"`
+			IsTrusted bool   `json:"is_trusted"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.jsonError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		if req.Name == "" || req.Address == "" {
+			s.jsonError(w, "Name and address are required", http.StatusBadRequest)
+			return
+		}
+
+		// Generate a unique ID for the peer
+		peerID := fmt.Sprintf("%s.manual", req.Address)
+
+		peer := &models.Peer{
+			ID:        peerID,
+			Name:      req.Name,
 			Address:   req.Address,
 			IsTrusted: req.IsTrusted,
 		}
@@ -541,37 +2227,259 @@ func (s *Server) handleSync(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get remote changes
-	var since time.Time
-	if peer.LastSync != nil {
-		since = *peer.LastSync
+	result, err := s.syncPeer(ctx, peer)
+	if errors.Is(err, services.ErrPeerUnreachable) {
+		s.jsonError(w, "Failed to connect to peer", http.StatusBadGateway)
+		return
+	}
+	if err != nil {
+		s.jsonError(w, "Failed to sync", http.StatusInternalServerError)
+		return
 	}
 
-	changesURL := fmt.Sprintf("http://%s/api/v1/gossip/changes?since=%s", peer.Address, since.Format(time.RFC3339))
-	resp, err := http.Get(changesURL)
+	s.jsonResponse(w, result)
+}
+
+// syncPeer syncs with a peer, logging the steps that failed
+func (s *Server) syncPeer(ctx context.Context, peer *models.Peer) (*models.SyncResult, error) {
+	result, err := s.gossipService.SyncPeer(ctx, s.backpackService, peer)
 	if err != nil {
-		s.jsonError(w, "Failed to connect to peer", http.StatusBadGateway)
+		return nil, err
+	}
+	for _, warning := range result.Warnings {
+		s.logger.Warn("Sync step failed", "peer_id", peer.ID, "error", warning)
+	}
+	return result, nil
+}
+
+// syncPeersOnSchedule syncs with the peers chosen by gossip.sync_peers every
+// gossip.sync_interval, until ctx is done. While scheduled syncs are
+// disabled, it waits to be reconfigured.
+func (s *Server) syncPeersOnSchedule(ctx context.Context) {
+	for {
+		var wait <-chan time.Time
+		if interval := s.config().Gossip.SyncInterval; interval > 0 {
+			wait = time.After(interval)
+		}
+
+		select {
+		case <-wait:
+			s.syncPeers(ctx)
+		case <-s.syncChanged:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// syncPeers syncs with the peers chosen by gossip.sync_peers, one at a time
+func (s *Server) syncPeers(ctx context.Context) {
+	peers, err := s.gossipService.GetPeers(ctx)
+	if err != nil {
+		s.logger.Error("Scheduled sync failed", "error", err)
+		return
+	}
+
+	policy := s.config().Gossip.SyncPeers
+	for _, peer := range peers {
+		if policy == config.SyncPeersTrusted && !peer.IsTrusted {
+			continue
+		}
+
+		result, err := s.syncPeer(ctx, &peer)
+		if err != nil {
+			s.logger.Warn("Scheduled sync failed", "peer_id", peer.ID, "peer_name", peer.Name, "error", err)
+			continue
+		}
+		s.logger.Info("Scheduled sync done", "peer_id", peer.ID, "peer_name", peer.Name,
+			"items_received", result.ItemsReceived, "items_sent", result.ItemsSent, "conflicts", result.Conflicts)
+	}
+}
+
+func (s *Server) handleGossipSearch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	defer resp.Body.Close()
 
-	var remoteChanges []models.Item
-	if err := json.NewDecoder(resp.Body).Decode(&remoteChanges); err != nil {
-		s.jsonError(w, "Failed to decode remote changes", http.StatusBadGateway)
+	query := parseSearchQuery(r)
+	if query.Brand == "" && query.Model == "" {
+		s.jsonError(w, "Brand or model is required", http.StatusBadRequest)
 		return
 	}
 
-	// Sync with peer
-	result, err := s.gossipService.SyncWithPeer(ctx, peerID, remoteChanges)
+	items, err := s.backpackService.SearchDocumentation(ctx, query)
 	if err != nil {
-		s.jsonError(w, "Failed to sync", http.StatusInternalServerError)
+		s.jsonError(w, "Failed to search items", http.StatusInternalServerError)
 		return
 	}
 
-	s.jsonResponse(w, result)
+	s.jsonResponse(w, items)
+}
+
+func (s *Server) handleGossipItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract ID from path
+	id, err := strconv.ParseInt(r.URL.Path[len("/api/v1/gossip/items/"):], 10, 64)
+	if err != nil {
+		s.jsonError(w, "Invalid item ID", http.StatusBadRequest)
+		return
+	}
+
+	item, err := s.backpackService.GetItemWithAssets(ctx, id)
+	if err != nil {
+		s.jsonError(w, "Item not found", http.StatusNotFound)
+		return
+	}
+
+	s.jsonResponse(w, item)
+}
+
+func (s *Server) handleGossipAsset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract ID from path
+	id, err := strconv.ParseInt(r.URL.Path[len("/api/v1/gossip/assets/"):], 10, 64)
+	if err != nil {
+		s.jsonError(w, "Invalid asset ID", http.StatusBadRequest)
+		return
+	}
+
+	s.serveAssetFile(w, r, id)
+}
+
+func (s *Server) handleGossipThumbnail(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract ID from path
+	id, err := strconv.ParseInt(r.URL.Path[len("/api/v1/gossip/thumbnails/"):], 10, 64)
+	if err != nil {
+		s.jsonError(w, "Invalid asset ID", http.StatusBadRequest)
+		return
+	}
+
+	s.serveThumbnail(w, r, id)
+}
+
+func (s *Server) handleFederatedSearch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := parseSearchQuery(r)
+	if query.Brand == "" && query.Model == "" {
+		s.jsonError(w, "Brand or model is required", http.StatusBadRequest)
+		return
+	}
+
+	results, err := s.gossipService.SearchPeers(ctx, query)
+	if err != nil {
+		s.jsonError(w, "Failed to search peers", http.StatusInternalServerError)
+		return
+	}
+
+	s.jsonResponse(w, results)
+}
+
+func (s *Server) handleFetchFromPeer(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		PeerID string `json:"peer_id"`
+		ItemID int64  `json:"item_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.jsonError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.PeerID == "" || req.ItemID == 0 {
+		s.jsonError(w, "Peer ID and item ID are required", http.StatusBadRequest)
+		return
+	}
+
+	item, err := s.gossipService.ImportPeerItem(ctx, s.backpackService, req.PeerID, req.ItemID)
+	if err != nil {
+		s.logger.Error("Failed to fetch item from peer", "peer_id", req.PeerID, "item_id", req.ItemID, "error", err)
+		s.jsonError(w, "Failed to fetch item from peer", http.StatusBadGateway)
+		return
+	}
+
+	s.jsonResponse(w, item)
 }
 
 // Helper functions
+func parseSearchQuery(r *http.Request) models.SearchQuery {
+	q := r.URL.Query()
+	return models.SearchQuery{
+		Brand:     q.Get("brand"),
+		Model:     q.Get("model"),
+		AssetType: models.AssetType(q.Get("asset_type")),
+	}
+}
+
+// receiveUpload stores the "file" field of a multipart request in a temporary
+// directory under its original name, which services use for the asset name
+// and extension. The caller removes the directory.
+func receiveUpload(r *http.Request) (string, error) {
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		return "", fmt.Errorf("failed to read uploaded file: %w", err)
+	}
+	defer file.Close()
+
+	dir, err := os.MkdirTemp("", "brique-upload-")
+	if err != nil {
+		return "", fmt.Errorf("failed to store uploaded file: %w", err)
+	}
+
+	path := filepath.Join(dir, filepath.Base(header.Filename))
+	dest, err := os.Create(path)
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("failed to store uploaded file: %w", err)
+	}
+	defer dest.Close()
+
+	if _, err := io.Copy(dest, file); err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("failed to store uploaded file: %w", err)
+	}
+
+	return path, nil
+}
+
+// uploadName returns the asset name given with an upload, or its file name
+func uploadName(r *http.Request, path string) string {
+	if name := r.FormValue("name"); name != "" {
+		return name
+	}
+	return filepath.Base(path)
+}
+
 func (s *Server) jsonResponse(w http.ResponseWriter, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(data)
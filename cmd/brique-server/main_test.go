@@ -0,0 +1,49 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWithoutDeadlines(t *testing.T) {
+	s := &Server{logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+
+	// A route outlasting the server timeouts, as a backup on a large archive
+	slow := func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(300 * time.Millisecond)
+		w.Write([]byte("done"))
+	}
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		served  bool
+	}{
+		{"cut off by the timeouts", slow, false},
+		{"without deadlines", s.withoutDeadlines(slow), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewUnstartedServer(tt.handler)
+			server.Config.ReadTimeout = 100 * time.Millisecond
+			server.Config.WriteTimeout = 100 * time.Millisecond
+			server.Start()
+			defer server.Close()
+
+			resp, err := http.Post(server.URL, "text/plain", nil)
+			var body []byte
+			if err == nil {
+				body, err = io.ReadAll(resp.Body)
+				resp.Body.Close()
+			}
+			if served := err == nil && string(body) == "done"; served != tt.served {
+				t.Errorf("expected served %t, got %q (%v)", tt.served, body, err)
+			}
+		})
+	}
+}
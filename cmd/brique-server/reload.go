@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"reflect"
+	"syscall"
+
+	"github.com/lhommenul/brique/core/services"
+	"github.com/lhommenul/brique/pkg/config"
+)
+
+// restartSettings are read once at startup: their changes are reported, and
+// applied by a restart. The other settings are applied as soon as reloaded.
+var restartSettings = []struct {
+	key   string
+	value func(*config.Config) any
+}{
+	{"profile", func(c *config.Config) any { return c.Profile }},
+	{"data_dir", func(c *config.Config) any { return c.DataDir }},
+	{"database_path", func(c *config.Config) any { return c.DatabasePath }},
+	{"assets_dir", func(c *config.Config) any { return c.AssetsDir }},
+	{"port", func(c *config.Config) any { return c.Port }},
+	{"instance_name", func(c *config.Config) any { return c.InstanceName }},
+	{"passphrase_file", func(c *config.Config) any { return c.PassphraseFile }},
+	{"health_rules", func(c *config.Config) any { return c.HealthRules }},
+	{"attribute_templates", func(c *config.Config) any { return c.AttributeTemplates }},
+}
+
+// config returns the configuration in effect
+func (s *Server) config() *config.Config {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cfg
+}
+
+// settingsRequiringRestart returns the settings changed since startup that
+// are only applied by a restart
+func (s *Server) settingsRequiringRestart() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.restartRequired
+}
+
+// watchConfig reloads the configuration when the config file changes and on
+// SIGHUP, until ctx is done
+func (s *Server) watchConfig(ctx context.Context) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(hangup)
+		for {
+			select {
+			case <-hangup:
+				s.reloadConfig(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	file := s.initial.File()
+	if err := config.Watch(ctx, file, func() { s.reloadConfig(ctx) }); err != nil {
+		s.logger.Warn("Config file not watched: reload it with SIGHUP", "error", err)
+		return
+	}
+	s.logger.Info("Watching config file", "file", file)
+}
+
+// reloadConfig loads the configuration again and applies what can be
+// applied live. An invalid configuration is reported and ignored.
+func (s *Server) reloadConfig(ctx context.Context) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	next, err := config.LoadProfile(s.profile)
+	if err != nil {
+		s.logger.Error("Config not reloaded, keeping the current one", "error", err)
+		return
+	}
+	current := s.config()
+
+	var restart []string
+	for _, setting := range restartSettings {
+		if !reflect.DeepEqual(setting.value(s.initial), setting.value(next)) {
+			restart = append(restart, setting.key)
+		}
+	}
+
+	s.level.Set(next.Level())
+	s.gossipService.SetPeerTimeout(next.Gossip.PeerTimeout)
+	s.backupScheduler.Reconfigure(next.BackupSchedule.Interval, next.BackupSchedule.Destinations, next.BackupRetention)
+	if next.Discovery != current.Discovery {
+		s.stopDiscovery()
+		s.startDiscovery(ctx, next.Discovery)
+	}
+
+	s.mu.Lock()
+	s.cfg = next
+	s.restartRequired = restart
+	s.mu.Unlock()
+
+	if next.Gossip.SyncInterval != current.Gossip.SyncInterval {
+		select {
+		case s.syncChanged <- struct{}{}:
+		default:
+		}
+	}
+
+	s.logger.Info("Config reloaded", "file", next.File(), "log_level", next.LogLevel)
+	if len(restart) > 0 {
+		s.logger.Warn("Config changes need a restart to apply", "settings", restart)
+	}
+}
+
+// startDiscovery announces this instance and browses for peers over mDNS,
+// if enabled. Failing to start is not fatal: the server runs without it.
+func (s *Server) startDiscovery(ctx context.Context, discovery config.Discovery) {
+	if !discovery.Enabled {
+		return
+	}
+
+	service := services.NewDiscoveryService(
+		s.instanceID,
+		s.initial.InstanceName,
+		s.initial.Port,
+		s.logger,
+		s.gossipService,
+	)
+	service.SetBrowseInterval(discovery.BrowseInterval)
+
+	if err := service.Start(ctx); err != nil {
+		s.logger.Warn("Failed to start discovery service", "error", err)
+		return
+	}
+
+	s.mu.Lock()
+	s.discoveryService = service
+	s.mu.Unlock()
+}
+
+// stopDiscovery stops the discovery service, if running
+func (s *Server) stopDiscovery() {
+	s.mu.Lock()
+	service := s.discoveryService
+	s.discoveryService = nil
+	s.mu.Unlock()
+
+	if service == nil {
+		return
+	}
+	if err := service.Stop(); err != nil {
+		s.logger.Error("Failed to stop discovery service", "error", err)
+	}
+}
+
+// allowedOrigin returns the Access-Control-Allow-Origin of a request from
+// the given origin, or an empty string when it is not allowed
+func (s *Server) allowedOrigin(origin string) string {
+	for _, allowed := range s.config().CORSOrigins {
+		if allowed == "*" {
+			return "*"
+		}
+		if origin != "" && allowed == origin {
+			return origin
+		}
+	}
+	return ""
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/lhommenul/brique/core/db"
+	"github.com/lhommenul/brique/core/services"
+	"github.com/lhommenul/brique/pkg/config"
+)
+
+// newTestServer starts a server on a config file of a temporary data
+// directory, as main does, and returns it with the path of its config file
+func newTestServer(t *testing.T, content string) (*Server, string) {
+	t.Helper()
+
+	dataDir := t.TempDir()
+	t.Setenv("BRIQUE_DATA_DIR", dataDir)
+	t.Setenv("BRIQUE_DATABASE_PATH", filepath.Join(dataDir, "brique.db"))
+	t.Setenv("BRIQUE_ASSETS_DIR", filepath.Join(dataDir, "assets"))
+	file := filepath.Join(dataDir, "config.yaml")
+	writeConfig(t, file, content)
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	database, err := db.NewDatabase(cfg.DatabasePath, nil)
+	if err != nil {
+		t.Fatalf("failed to initialize database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	var level slog.LevelVar
+	level.Set(cfg.Level())
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: &level}))
+	backpackService := services.NewBackpackService(database, cfg.AssetsDir)
+
+	return &Server{
+		initial:         cfg,
+		cfg:             cfg,
+		database:        database,
+		backpackService: backpackService,
+		gossipService:   services.NewGossipService(database, cfg.InstanceName, ":0"),
+		backupScheduler: services.NewBackupScheduler(backpackService, cfg.BackupSchedule.Interval, cfg.BackupSchedule.Destinations, cfg.BackupRetention, logger),
+		logger:          logger,
+		level:           &level,
+		syncChanged:     make(chan struct{}, 1),
+	}, file
+}
+
+func writeConfig(t *testing.T, file, content string) {
+	t.Helper()
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+}
+
+func TestReloadConfig(t *testing.T) {
+	const base = "port: 8081\ninstance_name: salon\ndiscovery:\n  enabled: false\n"
+
+	tests := []struct {
+		name    string
+		content string
+		check   func(t *testing.T, s *Server)
+		restart []string
+	}{
+		{
+			name:    "applies live settings",
+			content: base + "log_level: debug\ngossip:\n  sync_interval: 1h\nbackup_schedule:\n  interval: 24h\n",
+			check: func(t *testing.T, s *Server) {
+				if s.level.Level() != slog.LevelDebug || s.config().LogLevel != "debug" {
+					t.Errorf("expected the debug level applied, got %s", s.level.Level())
+				}
+				if status := s.backupScheduler.Status(); !status.Enabled || status.Interval != "24h0m0s" {
+					t.Errorf("expected backups scheduled every day, got %+v", status)
+				}
+				select {
+				case <-s.syncChanged:
+				default:
+					t.Error("expected the scheduled sync to be woken up")
+				}
+			},
+		},
+		{
+			name:    "reports restart-only settings",
+			content: "port: 8082\ninstance_name: atelier\ndiscovery:\n  enabled: false\nlog_level: warn\n",
+			check: func(t *testing.T, s *Server) {
+				if s.initial.Port != 8081 || s.initial.InstanceName != "salon" {
+					t.Errorf("expected the startup settings kept, got %d and %s", s.initial.Port, s.initial.InstanceName)
+				}
+				if s.level.Level() != slog.LevelWarn {
+					t.Errorf("expected the live settings applied meanwhile, got %s", s.level.Level())
+				}
+			},
+			restart: []string{"port", "instance_name"},
+		},
+		{
+			name:    "rejects an invalid file",
+			content: base + "log_level: verbose\n",
+			check: func(t *testing.T, s *Server) {
+				if s.config() != s.initial || s.level.Level() != slog.LevelInfo {
+					t.Errorf("expected the current config kept, got %+v", s.config())
+				}
+			},
+		},
+		{
+			name:    "rejects unknown settings",
+			content: base + "prot: 8082\n",
+			check: func(t *testing.T, s *Server) {
+				if s.config() != s.initial {
+					t.Errorf("expected the current config kept, got %+v", s.config())
+				}
+			},
+		},
+		{
+			name:    "rejects unreadable YAML",
+			content: "port: [8082\n",
+			check: func(t *testing.T, s *Server) {
+				if s.config() != s.initial {
+					t.Errorf("expected the current config kept, got %+v", s.config())
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, file := newTestServer(t, base)
+
+			writeConfig(t, file, tt.content)
+			s.reloadConfig(context.Background())
+
+			tt.check(t, s)
+			if restart := s.settingsRequiringRestart(); !slices.Equal(restart, tt.restart) {
+				t.Errorf("expected %v to need a restart, got %v", tt.restart, restart)
+			}
+		})
+	}
+}
+
+func TestReloadConfigRestartBack(t *testing.T) {
+	const base = "port: 8081\ndiscovery:\n  enabled: false\n"
+	s, file := newTestServer(t, base)
+
+	// Setting a restart-only setting back needs no restart anymore
+	writeConfig(t, file, "port: 8082\ndiscovery:\n  enabled: false\n")
+	s.reloadConfig(context.Background())
+	if restart := s.settingsRequiringRestart(); !slices.Equal(restart, []string{"port"}) {
+		t.Fatalf("expected the port to need a restart, got %v", restart)
+	}
+
+	// An invalid file in between keeps the report
+	writeConfig(t, file, "port: 0\n")
+	s.reloadConfig(context.Background())
+	if restart := s.settingsRequiringRestart(); !slices.Equal(restart, []string{"port"}) {
+		t.Errorf("expected the report kept, got %v", restart)
+	}
+
+	writeConfig(t, file, base)
+	s.reloadConfig(context.Background())
+	if restart := s.settingsRequiringRestart(); len(restart) != 0 {
+		t.Errorf("expected no restart needed, got %v", restart)
+	}
+}
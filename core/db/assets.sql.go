@@ -7,6 +7,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"time"
 )
 
@@ -15,7 +16,7 @@ SELECT COUNT(*) FROM assets
 WHERE item_id = ?
 `
 
-func (q *Queries) CountAssetsByItemID(ctx context.Context, itemID int64) (int64, error) {
+func (q *Queries) CountAssetsByItemID(ctx context.Context, itemID sql.NullInt64) (int64, error) {
 	row := q.db.QueryRowContext(ctx, countAssetsByItemID, itemID)
 	var count int64
 	err := row.Scan(&count)
@@ -28,8 +29,8 @@ WHERE item_id = ? AND type = ?
 `
 
 type CountAssetsByItemIDAndTypeParams struct {
-	ItemID int64  `json:"item_id"`
-	Type   string `json:"type"`
+	ItemID sql.NullInt64 `json:"item_id"`
+	Type   string        `json:"type"`
 }
 
 func (q *Queries) CountAssetsByItemIDAndType(ctx context.Context, arg CountAssetsByItemIDAndTypeParams) (int64, error) {
@@ -39,34 +40,57 @@ func (q *Queries) CountAssetsByItemIDAndType(ctx context.Context, arg CountAsset
 	return count, err
 }
 
+const countAssetsByType = `-- name: CountAssetsByType :one
+SELECT COUNT(*) FROM assets
+WHERE type = ?
+`
+
+func (q *Queries) CountAssetsByType(ctx context.Context, type_ string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countAssetsByType, type_)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createAsset = `-- name: CreateAsset :one
 INSERT INTO assets (
-    item_id, type, name, file_path, file_size, file_hash, created_at
+    item_id, product_model_id, type, name, file_path, file_size, file_hash,
+    created_at, version_label, release_date, supersedes_id, is_current
 ) VALUES (
-    ?, ?, ?, ?, ?, ?, ?
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
 )
-RETURNING id, item_id, type, name, file_path, file_size, file_hash, created_at
+RETURNING id, item_id, type, name, file_path, file_size, file_hash, created_at, version_label, release_date, supersedes_id, superseded_by_id, is_current, product_model_id
 `
 
 type CreateAssetParams struct {
-	ItemID    int64     `json:"item_id"`
-	Type      string    `json:"type"`
-	Name      string    `json:"name"`
-	FilePath  string    `json:"file_path"`
-	FileSize  int64     `json:"file_size"`
-	FileHash  string    `json:"file_hash"`
-	CreatedAt time.Time `json:"created_at"`
+	ItemID         sql.NullInt64 `json:"item_id"`
+	ProductModelID sql.NullInt64 `json:"product_model_id"`
+	Type           string        `json:"type"`
+	Name           string        `json:"name"`
+	FilePath       string        `json:"file_path"`
+	FileSize       int64         `json:"file_size"`
+	FileHash       string        `json:"file_hash"`
+	CreatedAt      time.Time     `json:"created_at"`
+	VersionLabel   string        `json:"version_label"`
+	ReleaseDate    sql.NullTime  `json:"release_date"`
+	SupersedesID   sql.NullInt64 `json:"supersedes_id"`
+	IsCurrent      bool          `json:"is_current"`
 }
 
 func (q *Queries) CreateAsset(ctx context.Context, arg CreateAssetParams) (Asset, error) {
 	row := q.db.QueryRowContext(ctx, createAsset,
 		arg.ItemID,
+		arg.ProductModelID,
 		arg.Type,
 		arg.Name,
 		arg.FilePath,
 		arg.FileSize,
 		arg.FileHash,
 		arg.CreatedAt,
+		arg.VersionLabel,
+		arg.ReleaseDate,
+		arg.SupersedesID,
+		arg.IsCurrent,
 	)
 	var i Asset
 	err := row.Scan(
@@ -78,6 +102,12 @@ func (q *Queries) CreateAsset(ctx context.Context, arg CreateAssetParams) (Asset
 		&i.FileSize,
 		&i.FileHash,
 		&i.CreatedAt,
+		&i.VersionLabel,
+		&i.ReleaseDate,
+		&i.SupersedesID,
+		&i.SupersededByID,
+		&i.IsCurrent,
+		&i.ProductModelID,
 	)
 	return i, err
 }
@@ -92,8 +122,52 @@ func (q *Queries) DeleteAsset(ctx context.Context, id int64) error {
 	return err
 }
 
+const getAllProductModelAssets = `-- name: GetAllProductModelAssets :many
+SELECT id, item_id, type, name, file_path, file_size, file_hash, created_at, version_label, release_date, supersedes_id, superseded_by_id, is_current, product_model_id FROM assets
+WHERE product_model_id IS NOT NULL
+ORDER BY created_at DESC
+`
+
+func (q *Queries) GetAllProductModelAssets(ctx context.Context) ([]Asset, error) {
+	rows, err := q.db.QueryContext(ctx, getAllProductModelAssets)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Asset{}
+	for rows.Next() {
+		var i Asset
+		if err := rows.Scan(
+			&i.ID,
+			&i.ItemID,
+			&i.Type,
+			&i.Name,
+			&i.FilePath,
+			&i.FileSize,
+			&i.FileHash,
+			&i.CreatedAt,
+			&i.VersionLabel,
+			&i.ReleaseDate,
+			&i.SupersedesID,
+			&i.SupersededByID,
+			&i.IsCurrent,
+			&i.ProductModelID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getAssetByID = `-- name: GetAssetByID :one
-SELECT id, item_id, type, name, file_path, file_size, file_hash, created_at FROM assets
+SELECT id, item_id, type, name, file_path, file_size, file_hash, created_at, version_label, release_date, supersedes_id, superseded_by_id, is_current, product_model_id FROM assets
 WHERE id = ?
 `
 
@@ -109,17 +183,23 @@ func (q *Queries) GetAssetByID(ctx context.Context, id int64) (Asset, error) {
 		&i.FileSize,
 		&i.FileHash,
 		&i.CreatedAt,
+		&i.VersionLabel,
+		&i.ReleaseDate,
+		&i.SupersedesID,
+		&i.SupersededByID,
+		&i.IsCurrent,
+		&i.ProductModelID,
 	)
 	return i, err
 }
 
 const getAssetsByItemID = `-- name: GetAssetsByItemID :many
-SELECT id, item_id, type, name, file_path, file_size, file_hash, created_at FROM assets
+SELECT id, item_id, type, name, file_path, file_size, file_hash, created_at, version_label, release_date, supersedes_id, superseded_by_id, is_current, product_model_id FROM assets
 WHERE item_id = ?
 ORDER BY created_at DESC
 `
 
-func (q *Queries) GetAssetsByItemID(ctx context.Context, itemID int64) ([]Asset, error) {
+func (q *Queries) GetAssetsByItemID(ctx context.Context, itemID sql.NullInt64) ([]Asset, error) {
 	rows, err := q.db.QueryContext(ctx, getAssetsByItemID, itemID)
 	if err != nil {
 		return nil, err
@@ -137,6 +217,196 @@ func (q *Queries) GetAssetsByItemID(ctx context.Context, itemID int64) ([]Asset,
 			&i.FileSize,
 			&i.FileHash,
 			&i.CreatedAt,
+			&i.VersionLabel,
+			&i.ReleaseDate,
+			&i.SupersedesID,
+			&i.SupersededByID,
+			&i.IsCurrent,
+			&i.ProductModelID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAssetsByProductModelID = `-- name: GetAssetsByProductModelID :many
+SELECT id, item_id, type, name, file_path, file_size, file_hash, created_at, version_label, release_date, supersedes_id, superseded_by_id, is_current, product_model_id FROM assets
+WHERE product_model_id = ?
+ORDER BY created_at DESC
+`
+
+func (q *Queries) GetAssetsByProductModelID(ctx context.Context, productModelID sql.NullInt64) ([]Asset, error) {
+	rows, err := q.db.QueryContext(ctx, getAssetsByProductModelID, productModelID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Asset{}
+	for rows.Next() {
+		var i Asset
+		if err := rows.Scan(
+			&i.ID,
+			&i.ItemID,
+			&i.Type,
+			&i.Name,
+			&i.FilePath,
+			&i.FileSize,
+			&i.FileHash,
+			&i.CreatedAt,
+			&i.VersionLabel,
+			&i.ReleaseDate,
+			&i.SupersedesID,
+			&i.SupersededByID,
+			&i.IsCurrent,
+			&i.ProductModelID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const moveProductModelAssets = `-- name: MoveProductModelAssets :exec
+UPDATE assets
+SET product_model_id = ?
+WHERE product_model_id = ?
+`
+
+type MoveProductModelAssetsParams struct {
+	NewProductModelID sql.NullInt64 `json:"new_product_model_id"`
+	OldProductModelID sql.NullInt64 `json:"old_product_model_id"`
+}
+
+func (q *Queries) MoveProductModelAssets(ctx context.Context, arg MoveProductModelAssetsParams) error {
+	_, err := q.db.ExecContext(ctx, moveProductModelAssets, arg.NewProductModelID, arg.OldProductModelID)
+	return err
+}
+
+const setAssetCurrent = `-- name: SetAssetCurrent :exec
+UPDATE assets
+SET is_current = ?
+WHERE id = ?
+`
+
+type SetAssetCurrentParams struct {
+	IsCurrent bool  `json:"is_current"`
+	ID        int64 `json:"id"`
+}
+
+func (q *Queries) SetAssetCurrent(ctx context.Context, arg SetAssetCurrentParams) error {
+	_, err := q.db.ExecContext(ctx, setAssetCurrent, arg.IsCurrent, arg.ID)
+	return err
+}
+
+const setAssetProductModel = `-- name: SetAssetProductModel :exec
+UPDATE assets
+SET item_id = NULL, product_model_id = ?
+WHERE id = ?
+`
+
+type SetAssetProductModelParams struct {
+	ProductModelID sql.NullInt64 `json:"product_model_id"`
+	ID             int64         `json:"id"`
+}
+
+func (q *Queries) SetAssetProductModel(ctx context.Context, arg SetAssetProductModelParams) error {
+	_, err := q.db.ExecContext(ctx, setAssetProductModel, arg.ProductModelID, arg.ID)
+	return err
+}
+
+const setAssetSupersededBy = `-- name: SetAssetSupersededBy :exec
+UPDATE assets
+SET superseded_by_id = ?
+WHERE id = ?
+`
+
+type SetAssetSupersededByParams struct {
+	SupersededByID sql.NullInt64 `json:"superseded_by_id"`
+	ID             int64         `json:"id"`
+}
+
+func (q *Queries) SetAssetSupersededBy(ctx context.Context, arg SetAssetSupersededByParams) error {
+	_, err := q.db.ExecContext(ctx, setAssetSupersededBy, arg.SupersededByID, arg.ID)
+	return err
+}
+
+const setAssetSupersedes = `-- name: SetAssetSupersedes :exec
+UPDATE assets
+SET supersedes_id = ?
+WHERE id = ?
+`
+
+type SetAssetSupersedesParams struct {
+	SupersedesID sql.NullInt64 `json:"supersedes_id"`
+	ID           int64         `json:"id"`
+}
+
+func (q *Queries) SetAssetSupersedes(ctx context.Context, arg SetAssetSupersedesParams) error {
+	_, err := q.db.ExecContext(ctx, setAssetSupersedes, arg.SupersedesID, arg.ID)
+	return err
+}
+
+const updateAssetVersion = `-- name: UpdateAssetVersion :exec
+UPDATE assets
+SET version_label = ?, release_date = ?
+WHERE id = ?
+`
+
+type UpdateAssetVersionParams struct {
+	VersionLabel string       `json:"version_label"`
+	ReleaseDate  sql.NullTime `json:"release_date"`
+	ID           int64        `json:"id"`
+}
+
+func (q *Queries) UpdateAssetVersion(ctx context.Context, arg UpdateAssetVersionParams) error {
+	_, err := q.db.ExecContext(ctx, updateAssetVersion, arg.VersionLabel, arg.ReleaseDate, arg.ID)
+	return err
+}
+
+const getAllAssets = `-- name: GetAllAssets :many
+SELECT id, item_id, type, name, file_path, file_size, file_hash, created_at, version_label, release_date, supersedes_id, superseded_by_id, is_current, product_model_id FROM assets
+ORDER BY id
+`
+
+func (q *Queries) GetAllAssets(ctx context.Context) ([]Asset, error) {
+	rows, err := q.db.QueryContext(ctx, getAllAssets)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Asset{}
+	for rows.Next() {
+		var i Asset
+		if err := rows.Scan(
+			&i.ID,
+			&i.ItemID,
+			&i.Type,
+			&i.Name,
+			&i.FilePath,
+			&i.FileSize,
+			&i.FileHash,
+			&i.CreatedAt,
+			&i.VersionLabel,
+			&i.ReleaseDate,
+			&i.SupersedesID,
+			&i.SupersededByID,
+			&i.IsCurrent,
+			&i.ProductModelID,
 		); err != nil {
 			return nil, err
 		}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log/slog"
@@ -22,8 +23,10 @@ func NewDatabase(dbPath string, logger *slog.Logger) (*Database, error) {
 		logger = slog.Default()
 	}
 
-	// Open database connection
-	db, err := sql.Open("sqlite", dbPath)
+	// Open database connection. Pragmas are set in the DSN so that they apply
+	// to every pooled connection, including those used by transactions.
+	dsn := dbPath + "?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_txlock=immediate"
+	db, err := sql.Open("sqlite", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -33,16 +36,6 @@ func NewDatabase(dbPath string, logger *slog.Logger) (*Database, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	// Enable foreign keys
-	if _, err := db.Exec("PRAGMA foreign_keys = ON"); err != nil {
-		return nil, fmt.Errorf("failed to enable foreign keys: %w", err)
-	}
-
-	// Enable WAL mode for better concurrency
-	if _, err := db.Exec("PRAGMA journal_mode = WAL"); err != nil {
-		return nil, fmt.Errorf("failed to enable WAL mode: %w", err)
-	}
-
 	database := &Database{
 		DB:     db,
 		logger: logger,
@@ -75,6 +68,28 @@ func (d *Database) runMigrations() error {
 	return nil
 }
 
+// WithTx runs fn in a transaction, committing if it returns nil and rolling
+// back otherwise
+func (d *Database) WithTx(ctx context.Context, fn func(q *Queries) error) error {
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := fn(New(d.DB).WithTx(tx)); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			d.logger.Error("Failed to roll back transaction", "error", rbErr)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
 // Close closes the database connection
 func (d *Database) Close() error {
 	if err := d.DB.Close(); err != nil {
@@ -22,35 +22,51 @@ func (q *Queries) CountItems(ctx context.Context) (int64, error) {
 	return count, err
 }
 
+const countItemsByProductModelID = `-- name: CountItemsByProductModelID :one
+SELECT COUNT(*) FROM items
+WHERE product_model_id = ?
+`
+
+func (q *Queries) CountItemsByProductModelID(ctx context.Context, productModelID sql.NullInt64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countItemsByProductModelID, productModelID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createItem = `-- name: CreateItem :one
 INSERT INTO items (
-    name, category, brand, model, serial_number,
+    name, category, category_id, brand, model, product_model_id, serial_number,
     purchase_date, photo_path, notes, created_at, updated_at
 ) VALUES (
-    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
 )
-RETURNING id, name, category, brand, model, serial_number, purchase_date, photo_path, notes, created_at, updated_at, origin_peer_id, sync_version
+RETURNING id, name, category, brand, model, serial_number, purchase_date, photo_path, notes, created_at, updated_at, origin_peer_id, sync_version, category_id, product_model_id
 `
 
 type CreateItemParams struct {
-	Name         string       `json:"name"`
-	Category     string       `json:"category"`
-	Brand        string       `json:"brand"`
-	Model        string       `json:"model"`
-	SerialNumber string       `json:"serial_number"`
-	PurchaseDate sql.NullTime `json:"purchase_date"`
-	PhotoPath    string       `json:"photo_path"`
-	Notes        string       `json:"notes"`
-	CreatedAt    time.Time    `json:"created_at"`
-	UpdatedAt    time.Time    `json:"updated_at"`
+	Name           string        `json:"name"`
+	Category       string        `json:"category"`
+	CategoryID     sql.NullInt64 `json:"category_id"`
+	Brand          string        `json:"brand"`
+	Model          string        `json:"model"`
+	ProductModelID sql.NullInt64 `json:"product_model_id"`
+	SerialNumber   string        `json:"serial_number"`
+	PurchaseDate   sql.NullTime  `json:"purchase_date"`
+	PhotoPath      string        `json:"photo_path"`
+	Notes          string        `json:"notes"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
 }
 
 func (q *Queries) CreateItem(ctx context.Context, arg CreateItemParams) (Item, error) {
 	row := q.db.QueryRowContext(ctx, createItem,
 		arg.Name,
 		arg.Category,
+		arg.CategoryID,
 		arg.Brand,
 		arg.Model,
+		arg.ProductModelID,
 		arg.SerialNumber,
 		arg.PurchaseDate,
 		arg.PhotoPath,
@@ -73,6 +89,8 @@ func (q *Queries) CreateItem(ctx context.Context, arg CreateItemParams) (Item, e
 		&i.UpdatedAt,
 		&i.OriginPeerID,
 		&i.SyncVersion,
+		&i.CategoryID,
+		&i.ProductModelID,
 	)
 	return i, err
 }
@@ -88,7 +106,7 @@ func (q *Queries) DeleteItem(ctx context.Context, id int64) error {
 }
 
 const getAllItems = `-- name: GetAllItems :many
-SELECT id, name, category, brand, model, serial_number, purchase_date, photo_path, notes, created_at, updated_at, origin_peer_id, sync_version FROM items
+SELECT id, name, category, brand, model, serial_number, purchase_date, photo_path, notes, created_at, updated_at, origin_peer_id, sync_version, category_id, product_model_id FROM items
 ORDER BY updated_at DESC
 `
 
@@ -115,6 +133,8 @@ func (q *Queries) GetAllItems(ctx context.Context) ([]Item, error) {
 			&i.UpdatedAt,
 			&i.OriginPeerID,
 			&i.SyncVersion,
+			&i.CategoryID,
+			&i.ProductModelID,
 		); err != nil {
 			return nil, err
 		}
@@ -130,7 +150,7 @@ func (q *Queries) GetAllItems(ctx context.Context) ([]Item, error) {
 }
 
 const getItemByID = `-- name: GetItemByID :one
-SELECT id, name, category, brand, model, serial_number, purchase_date, photo_path, notes, created_at, updated_at, origin_peer_id, sync_version FROM items
+SELECT id, name, category, brand, model, serial_number, purchase_date, photo_path, notes, created_at, updated_at, origin_peer_id, sync_version, category_id, product_model_id FROM items
 WHERE id = ?
 `
 
@@ -151,12 +171,14 @@ func (q *Queries) GetItemByID(ctx context.Context, id int64) (Item, error) {
 		&i.UpdatedAt,
 		&i.OriginPeerID,
 		&i.SyncVersion,
+		&i.CategoryID,
+		&i.ProductModelID,
 	)
 	return i, err
 }
 
 const getItemsModifiedSince = `-- name: GetItemsModifiedSince :many
-SELECT id, name, category, brand, model, serial_number, purchase_date, photo_path, notes, created_at, updated_at, origin_peer_id, sync_version FROM items
+SELECT id, name, category, brand, model, serial_number, purchase_date, photo_path, notes, created_at, updated_at, origin_peer_id, sync_version, category_id, product_model_id FROM items
 WHERE updated_at > ?
 ORDER BY updated_at DESC
 `
@@ -184,6 +206,8 @@ func (q *Queries) GetItemsModifiedSince(ctx context.Context, updatedAt time.Time
 			&i.UpdatedAt,
 			&i.OriginPeerID,
 			&i.SyncVersion,
+			&i.CategoryID,
+			&i.ProductModelID,
 		); err != nil {
 			return nil, err
 		}
@@ -198,9 +222,67 @@ func (q *Queries) GetItemsModifiedSince(ctx context.Context, updatedAt time.Time
 	return items, nil
 }
 
+const reassignCategoryItems = `-- name: ReassignCategoryItems :exec
+UPDATE items
+SET category_id = ?, category = ?, updated_at = ?
+WHERE category_id = ?
+`
+
+type ReassignCategoryItemsParams struct {
+	NewCategoryID sql.NullInt64 `json:"new_category_id"`
+	Category      string        `json:"category"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+	OldCategoryID sql.NullInt64 `json:"old_category_id"`
+}
+
+func (q *Queries) ReassignCategoryItems(ctx context.Context, arg ReassignCategoryItemsParams) error {
+	_, err := q.db.ExecContext(ctx, reassignCategoryItems,
+		arg.NewCategoryID,
+		arg.Category,
+		arg.UpdatedAt,
+		arg.OldCategoryID,
+	)
+	return err
+}
+
+const reassignProductModelItems = `-- name: ReassignProductModelItems :exec
+UPDATE items
+SET product_model_id = ?
+WHERE product_model_id = ?
+`
+
+type ReassignProductModelItemsParams struct {
+	NewProductModelID sql.NullInt64 `json:"new_product_model_id"`
+	OldProductModelID sql.NullInt64 `json:"old_product_model_id"`
+}
+
+func (q *Queries) ReassignProductModelItems(ctx context.Context, arg ReassignProductModelItemsParams) error {
+	_, err := q.db.ExecContext(ctx, reassignProductModelItems, arg.NewProductModelID, arg.OldProductModelID)
+	return err
+}
+
+const renameCategoryItems = `-- name: RenameCategoryItems :exec
+UPDATE items
+SET category = ?, updated_at = ?
+WHERE category_id = ?
+`
+
+type RenameCategoryItemsParams struct {
+	Category   string        `json:"category"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+	CategoryID sql.NullInt64 `json:"category_id"`
+}
+
+func (q *Queries) RenameCategoryItems(ctx context.Context, arg RenameCategoryItemsParams) error {
+	_, err := q.db.ExecContext(ctx, renameCategoryItems, arg.Category, arg.UpdatedAt, arg.CategoryID)
+	return err
+}
+
 const searchItems = `-- name: SearchItems :many
-SELECT id, name, category, brand, model, serial_number, purchase_date, photo_path, notes, created_at, updated_at, origin_peer_id, sync_version FROM items
+SELECT id, name, category, brand, model, serial_number, purchase_date, photo_path, notes, created_at, updated_at, origin_peer_id, sync_version, category_id, product_model_id FROM items
 WHERE name LIKE ? OR brand LIKE ? OR category LIKE ?
+   OR id IN (SELECT item_id FROM item_attributes WHERE value LIKE ?)
+   OR category_id IN (SELECT category_id FROM category_synonyms WHERE synonym LIKE ?)
 ORDER BY updated_at DESC
 `
 
@@ -208,10 +290,68 @@ type SearchItemsParams struct {
 	Name     string `json:"name"`
 	Brand    string `json:"brand"`
 	Category string `json:"category"`
+	Value    string `json:"value"`
+	Synonym  string `json:"synonym"`
 }
 
 func (q *Queries) SearchItems(ctx context.Context, arg SearchItemsParams) ([]Item, error) {
-	rows, err := q.db.QueryContext(ctx, searchItems, arg.Name, arg.Brand, arg.Category)
+	rows, err := q.db.QueryContext(ctx, searchItems,
+		arg.Name,
+		arg.Brand,
+		arg.Category,
+		arg.Value,
+		arg.Synonym,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Item{}
+	for rows.Next() {
+		var i Item
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Category,
+			&i.Brand,
+			&i.Model,
+			&i.SerialNumber,
+			&i.PurchaseDate,
+			&i.PhotoPath,
+			&i.Notes,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.OriginPeerID,
+			&i.SyncVersion,
+			&i.CategoryID,
+			&i.ProductModelID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchItemsByProduct = `-- name: SearchItemsByProduct :many
+SELECT id, name, category, brand, model, serial_number, purchase_date, photo_path, notes, created_at, updated_at, origin_peer_id, sync_version, category_id, product_model_id FROM items
+WHERE brand LIKE ? AND model LIKE ?
+ORDER BY updated_at DESC
+`
+
+type SearchItemsByProductParams struct {
+	Brand string `json:"brand"`
+	Model string `json:"model"`
+}
+
+func (q *Queries) SearchItemsByProduct(ctx context.Context, arg SearchItemsByProductParams) ([]Item, error) {
+	rows, err := q.db.QueryContext(ctx, searchItemsByProduct, arg.Brand, arg.Model)
 	if err != nil {
 		return nil, err
 	}
@@ -233,6 +373,8 @@ func (q *Queries) SearchItems(ctx context.Context, arg SearchItemsParams) ([]Ite
 			&i.UpdatedAt,
 			&i.OriginPeerID,
 			&i.SyncVersion,
+			&i.CategoryID,
+			&i.ProductModelID,
 		); err != nil {
 			return nil, err
 		}
@@ -247,13 +389,47 @@ func (q *Queries) SearchItems(ctx context.Context, arg SearchItemsParams) ([]Ite
 	return items, nil
 }
 
+const setItemProductModel = `-- name: SetItemProductModel :exec
+UPDATE items
+SET product_model_id = ?
+WHERE id = ?
+`
+
+type SetItemProductModelParams struct {
+	ProductModelID sql.NullInt64 `json:"product_model_id"`
+	ID             int64         `json:"id"`
+}
+
+func (q *Queries) SetItemProductModel(ctx context.Context, arg SetItemProductModelParams) error {
+	_, err := q.db.ExecContext(ctx, setItemProductModel, arg.ProductModelID, arg.ID)
+	return err
+}
+
+const touchItem = `-- name: TouchItem :exec
+UPDATE items
+SET updated_at = ?
+WHERE id = ?
+`
+
+type TouchItemParams struct {
+	UpdatedAt time.Time `json:"updated_at"`
+	ID        int64     `json:"id"`
+}
+
+func (q *Queries) TouchItem(ctx context.Context, arg TouchItemParams) error {
+	_, err := q.db.ExecContext(ctx, touchItem, arg.UpdatedAt, arg.ID)
+	return err
+}
+
 const updateItem = `-- name: UpdateItem :exec
 UPDATE items
 SET
     name = ?,
     category = ?,
+    category_id = ?,
     brand = ?,
     model = ?,
+    product_model_id = ?,
     serial_number = ?,
     purchase_date = ?,
     photo_path = ?,
@@ -263,24 +439,28 @@ WHERE id = ?
 `
 
 type UpdateItemParams struct {
-	Name         string       `json:"name"`
-	Category     string       `json:"category"`
-	Brand        string       `json:"brand"`
-	Model        string       `json:"model"`
-	SerialNumber string       `json:"serial_number"`
-	PurchaseDate sql.NullTime `json:"purchase_date"`
-	PhotoPath    string       `json:"photo_path"`
-	Notes        string       `json:"notes"`
-	UpdatedAt    time.Time    `json:"updated_at"`
-	ID           int64        `json:"id"`
+	Name           string        `json:"name"`
+	Category       string        `json:"category"`
+	CategoryID     sql.NullInt64 `json:"category_id"`
+	Brand          string        `json:"brand"`
+	Model          string        `json:"model"`
+	ProductModelID sql.NullInt64 `json:"product_model_id"`
+	SerialNumber   string        `json:"serial_number"`
+	PurchaseDate   sql.NullTime  `json:"purchase_date"`
+	PhotoPath      string        `json:"photo_path"`
+	Notes          string        `json:"notes"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+	ID             int64         `json:"id"`
 }
 
 func (q *Queries) UpdateItem(ctx context.Context, arg UpdateItemParams) error {
 	_, err := q.db.ExecContext(ctx, updateItem,
 		arg.Name,
 		arg.Category,
+		arg.CategoryID,
 		arg.Brand,
 		arg.Model,
+		arg.ProductModelID,
 		arg.SerialNumber,
 		arg.PurchaseDate,
 		arg.PhotoPath,
@@ -290,3 +470,22 @@ func (q *Queries) UpdateItem(ctx context.Context, arg UpdateItemParams) error {
 	)
 	return err
 }
+
+const setItemSecrets = `-- name: SetItemSecrets :exec
+UPDATE items
+SET
+    serial_number = ?,
+    notes = ?
+WHERE id = ?
+`
+
+type SetItemSecretsParams struct {
+	SerialNumber string `json:"serial_number"`
+	Notes        string `json:"notes"`
+	ID           int64  `json:"id"`
+}
+
+func (q *Queries) SetItemSecrets(ctx context.Context, arg SetItemSecretsParams) error {
+	_, err := q.db.ExecContext(ctx, setItemSecrets, arg.SerialNumber, arg.Notes, arg.ID)
+	return err
+}
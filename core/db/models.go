@@ -9,31 +9,195 @@ import (
 	"time"
 )
 
+type Announcement struct {
+	ID           int64     `json:"id"`
+	UID          string    `json:"uid"`
+	Kind         string    `json:"kind"`
+	Title        string    `json:"title"`
+	Body         string    `json:"body"`
+	AuthorName   string    `json:"author_name"`
+	AuthorKey    string    `json:"author_key"`
+	Signature    string    `json:"signature"`
+	MaxHops      int64     `json:"max_hops"`
+	Hops         int64     `json:"hops"`
+	Withdrawn    bool      `json:"withdrawn"`
+	ReceivedFrom string    `json:"received_from"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
 type Asset struct {
-	ID        int64     `json:"id"`
-	ItemID    int64     `json:"item_id"`
-	Type      string    `json:"type"`
-	Name      string    `json:"name"`
-	FilePath  string    `json:"file_path"`
-	FileSize  int64     `json:"file_size"`
-	FileHash  string    `json:"file_hash"`
-	CreatedAt time.Time `json:"created_at"`
+	ID             int64         `json:"id"`
+	ItemID         sql.NullInt64 `json:"item_id"`
+	Type           string        `json:"type"`
+	Name           string        `json:"name"`
+	FilePath       string        `json:"file_path"`
+	FileSize       int64         `json:"file_size"`
+	FileHash       string        `json:"file_hash"`
+	CreatedAt      time.Time     `json:"created_at"`
+	VersionLabel   string        `json:"version_label"`
+	ReleaseDate    sql.NullTime  `json:"release_date"`
+	SupersedesID   sql.NullInt64 `json:"supersedes_id"`
+	SupersededByID sql.NullInt64 `json:"superseded_by_id"`
+	IsCurrent      bool          `json:"is_current"`
+	ProductModelID sql.NullInt64 `json:"product_model_id"`
+}
+
+type AssetType struct {
+	Name            string    `json:"name"`
+	Label           string    `json:"label"`
+	MimePatterns    string    `json:"mime_patterns"`
+	CountsForHealth bool      `json:"counts_for_health"`
+	IsBuiltin       bool      `json:"is_builtin"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+type Category struct {
+	ID        int64         `json:"id"`
+	Name      string        `json:"name"`
+	ParentID  sql.NullInt64 `json:"parent_id"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+type CategorySynonym struct {
+	Synonym    string `json:"synonym"`
+	CategoryID int64  `json:"category_id"`
+}
+
+type EncryptionKey struct {
+	ID         int64     `json:"id"`
+	Salt       []byte    `json:"salt"`
+	KdfTime    int64     `json:"kdf_time"`
+	KdfMemory  int64     `json:"kdf_memory"`
+	KdfThreads int64     `json:"kdf_threads"`
+	WrappedKey []byte    `json:"wrapped_key"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type FileOutbox struct {
+	ID         int64     `json:"id"`
+	Action     string    `json:"action"`
+	Path       string    `json:"path"`
+	TargetPath string    `json:"target_path"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type InstanceIdentity struct {
+	ID         int64     `json:"id"`
+	InstanceID string    `json:"instance_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type InstanceKey struct {
+	ID         int64     `json:"id"`
+	PublicKey  []byte    `json:"public_key"`
+	PrivateKey []byte    `json:"private_key"`
+	CreatedAt  time.Time `json:"created_at"`
 }
 
 type Item struct {
-	ID           int64          `json:"id"`
-	Name         string         `json:"name"`
-	Category     string         `json:"category"`
-	Brand        string         `json:"brand"`
-	Model        string         `json:"model"`
-	SerialNumber string         `json:"serial_number"`
-	PurchaseDate sql.NullTime   `json:"purchase_date"`
-	PhotoPath    string         `json:"photo_path"`
-	Notes        string         `json:"notes"`
-	CreatedAt    time.Time      `json:"created_at"`
-	UpdatedAt    time.Time      `json:"updated_at"`
-	OriginPeerID sql.NullString `json:"origin_peer_id"`
-	SyncVersion  sql.NullInt64  `json:"sync_version"`
+	ID             int64          `json:"id"`
+	Name           string         `json:"name"`
+	Category       string         `json:"category"`
+	Brand          string         `json:"brand"`
+	Model          string         `json:"model"`
+	SerialNumber   string         `json:"serial_number"`
+	PurchaseDate   sql.NullTime   `json:"purchase_date"`
+	PhotoPath      string         `json:"photo_path"`
+	Notes          string         `json:"notes"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	OriginPeerID   sql.NullString `json:"origin_peer_id"`
+	SyncVersion    sql.NullInt64  `json:"sync_version"`
+	CategoryID     sql.NullInt64  `json:"category_id"`
+	ProductModelID sql.NullInt64  `json:"product_model_id"`
+}
+
+type ItemAttribute struct {
+	ItemID int64  `json:"item_id"`
+	Key    string `json:"key"`
+	Type   string `json:"type"`
+	Value  string `json:"value"`
+}
+
+type ItemTag struct {
+	ItemID int64 `json:"item_id"`
+	TagID  int64 `json:"tag_id"`
+}
+
+type ItemUsageCounter struct {
+	ItemID    int64     `json:"item_id"`
+	Unit      string    `json:"unit"`
+	Value     int64     `json:"value"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ItemWarranty struct {
+	ItemID         int64         `json:"item_id"`
+	DurationMonths int64         `json:"duration_months"`
+	Provider       string        `json:"provider"`
+	ReceiptAssetID sql.NullInt64 `json:"receipt_asset_id"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
+
+type Loan struct {
+	ID             int64         `json:"id"`
+	UID            string        `json:"uid"`
+	Direction      string        `json:"direction"`
+	ItemID         sql.NullInt64 `json:"item_id"`
+	ItemName       string        `json:"item_name"`
+	BorrowerPeerID string        `json:"borrower_peer_id"`
+	BorrowerName   string        `json:"borrower_name"`
+	LenderPeerID   string        `json:"lender_peer_id"`
+	LenderName     string        `json:"lender_name"`
+	LentAt         time.Time     `json:"lent_at"`
+	DueAt          sql.NullTime  `json:"due_at"`
+	ReturnedAt     sql.NullTime  `json:"returned_at"`
+	Notes          string        `json:"notes"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
+
+type MaintenanceSchedule struct {
+	ID            int64        `json:"id"`
+	ItemID        int64        `json:"item_id"`
+	Task          string       `json:"task"`
+	IntervalDays  int64        `json:"interval_days"`
+	IntervalUsage int64        `json:"interval_usage"`
+	UsageUnit     string       `json:"usage_unit"`
+	LastDoneAt    sql.NullTime `json:"last_done_at"`
+	LastDoneUsage int64        `json:"last_done_usage"`
+	CreatedAt     time.Time    `json:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+}
+
+type Part struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	Reference   string    `json:"reference"`
+	PartKey     string    `json:"part_key"`
+	Quantity    int64     `json:"quantity"`
+	MinQuantity int64     `json:"min_quantity"`
+	Location    string    `json:"location"`
+	Notes       string    `json:"notes"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type PartAsset struct {
+	PartID  int64 `json:"part_id"`
+	AssetID int64 `json:"asset_id"`
+}
+
+type PartItem struct {
+	PartID int64 `json:"part_id"`
+	ItemID int64 `json:"item_id"`
+}
+
+type PartProductModel struct {
+	PartID         int64 `json:"part_id"`
+	ProductModelID int64 `json:"product_model_id"`
 }
 
 type Peer struct {
@@ -44,6 +208,47 @@ type Peer struct {
 	LastSync  sql.NullTime `json:"last_sync"`
 	IsTrusted sql.NullBool `json:"is_trusted"`
 	CreatedAt sql.NullTime `json:"created_at"`
+	PublicKey string       `json:"public_key"`
+}
+
+type ProductModel struct {
+	ID        int64     `json:"id"`
+	Brand     string    `json:"brand"`
+	Model     string    `json:"model"`
+	ModelKey  string    `json:"model_key"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ProductModelAlias struct {
+	ModelKey       string `json:"model_key"`
+	ProductModelID int64  `json:"product_model_id"`
+	Brand          string `json:"brand"`
+	Model          string `json:"model"`
+}
+
+type RepairEvent struct {
+	ID            int64     `json:"id"`
+	UID           string    `json:"uid"`
+	ItemID        int64     `json:"item_id"`
+	PerformedAt   time.Time `json:"performed_at"`
+	Diagnosis     string    `json:"diagnosis"`
+	Action        string    `json:"action"`
+	PartsReplaced string    `json:"parts_replaced"`
+	CostCents     int64     `json:"cost_cents"`
+	PerformedBy   string    `json:"performed_by"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type RepairEventAsset struct {
+	RepairEventID int64 `json:"repair_event_id"`
+	AssetID       int64 `json:"asset_id"`
+}
+
+type SeenAnnouncement struct {
+	UID       string    `json:"uid"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 type SyncLog struct {
@@ -56,3 +261,8 @@ type SyncLog struct {
 	DurationMs    sql.NullInt64  `json:"duration_ms"`
 	Error         sql.NullString `json:"error"`
 }
+
+type Tag struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
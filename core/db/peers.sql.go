@@ -13,7 +13,7 @@ import (
 const createPeer = `-- name: CreatePeer :one
 INSERT INTO peers (id, name, address, last_seen, is_trusted)
 VALUES (?, ?, ?, ?, ?)
-RETURNING id, name, address, last_seen, last_sync, is_trusted, created_at
+RETURNING id, name, address, last_seen, last_sync, is_trusted, created_at, public_key
 `
 
 type CreatePeerParams struct {
@@ -41,6 +41,7 @@ func (q *Queries) CreatePeer(ctx context.Context, arg CreatePeerParams) (Peer, e
 		&i.LastSync,
 		&i.IsTrusted,
 		&i.CreatedAt,
+		&i.PublicKey,
 	)
 	return i, err
 }
@@ -55,7 +56,7 @@ func (q *Queries) DeletePeer(ctx context.Context, id string) error {
 }
 
 const getAllPeers = `-- name: GetAllPeers :many
-SELECT id, name, address, last_seen, last_sync, is_trusted, created_at FROM peers ORDER BY last_seen DESC
+SELECT id, name, address, last_seen, last_sync, is_trusted, created_at, public_key FROM peers ORDER BY last_seen DESC
 `
 
 func (q *Queries) GetAllPeers(ctx context.Context) ([]Peer, error) {
@@ -75,6 +76,7 @@ func (q *Queries) GetAllPeers(ctx context.Context) ([]Peer, error) {
 			&i.LastSync,
 			&i.IsTrusted,
 			&i.CreatedAt,
+			&i.PublicKey,
 		); err != nil {
 			return nil, err
 		}
@@ -90,7 +92,7 @@ func (q *Queries) GetAllPeers(ctx context.Context) ([]Peer, error) {
 }
 
 const getPeer = `-- name: GetPeer :one
-SELECT id, name, address, last_seen, last_sync, is_trusted, created_at FROM peers WHERE id = ?
+SELECT id, name, address, last_seen, last_sync, is_trusted, created_at, public_key FROM peers WHERE id = ?
 `
 
 func (q *Queries) GetPeer(ctx context.Context, id string) (Peer, error) {
@@ -104,12 +106,13 @@ func (q *Queries) GetPeer(ctx context.Context, id string) (Peer, error) {
 		&i.LastSync,
 		&i.IsTrusted,
 		&i.CreatedAt,
+		&i.PublicKey,
 	)
 	return i, err
 }
 
 const getPeerByAddress = `-- name: GetPeerByAddress :one
-SELECT id, name, address, last_seen, last_sync, is_trusted, created_at FROM peers WHERE address = ?
+SELECT id, name, address, last_seen, last_sync, is_trusted, created_at, public_key FROM peers WHERE address = ?
 `
 
 func (q *Queries) GetPeerByAddress(ctx context.Context, address string) (Peer, error) {
@@ -123,12 +126,13 @@ func (q *Queries) GetPeerByAddress(ctx context.Context, address string) (Peer, e
 		&i.LastSync,
 		&i.IsTrusted,
 		&i.CreatedAt,
+		&i.PublicKey,
 	)
 	return i, err
 }
 
 const getTrustedPeers = `-- name: GetTrustedPeers :many
-SELECT id, name, address, last_seen, last_sync, is_trusted, created_at FROM peers WHERE is_trusted = 1 ORDER BY last_seen DESC
+SELECT id, name, address, last_seen, last_sync, is_trusted, created_at, public_key FROM peers WHERE is_trusted = 1 ORDER BY last_seen DESC
 `
 
 func (q *Queries) GetTrustedPeers(ctx context.Context) ([]Peer, error) {
@@ -148,6 +152,7 @@ func (q *Queries) GetTrustedPeers(ctx context.Context) ([]Peer, error) {
 			&i.LastSync,
 			&i.IsTrusted,
 			&i.CreatedAt,
+			&i.PublicKey,
 		); err != nil {
 			return nil, err
 		}
@@ -162,6 +167,20 @@ func (q *Queries) GetTrustedPeers(ctx context.Context) ([]Peer, error) {
 	return items, nil
 }
 
+const updatePeerKey = `-- name: UpdatePeerKey :exec
+UPDATE peers SET public_key = ? WHERE id = ?
+`
+
+type UpdatePeerKeyParams struct {
+	PublicKey string `json:"public_key"`
+	ID        string `json:"id"`
+}
+
+func (q *Queries) UpdatePeerKey(ctx context.Context, arg UpdatePeerKeyParams) error {
+	_, err := q.db.ExecContext(ctx, updatePeerKey, arg.PublicKey, arg.ID)
+	return err
+}
+
 const updatePeerLastSeen = `-- name: UpdatePeerLastSeen :exec
 UPDATE peers SET last_seen = ? WHERE id = ?
 `
@@ -11,34 +11,165 @@ import (
 )
 
 type Querier interface {
-	CountAssetsByItemID(ctx context.Context, itemID int64) (int64, error)
+	AddItemTag(ctx context.Context, arg AddItemTagParams) error
+	AddPartAsset(ctx context.Context, arg AddPartAssetParams) error
+	AddPartItem(ctx context.Context, arg AddPartItemParams) error
+	AddPartProductModel(ctx context.Context, arg AddPartProductModelParams) error
+	AddRepairEventAsset(ctx context.Context, arg AddRepairEventAssetParams) error
+	CompleteMaintenanceSchedule(ctx context.Context, arg CompleteMaintenanceScheduleParams) error
+	CountAssetsByItemID(ctx context.Context, itemID sql.NullInt64) (int64, error)
 	CountAssetsByItemIDAndType(ctx context.Context, arg CountAssetsByItemIDAndTypeParams) (int64, error)
+	CountAssetsByType(ctx context.Context, type_ string) (int64, error)
 	CountItems(ctx context.Context) (int64, error)
+	CountItemsByCategoryID(ctx context.Context, categoryID sql.NullInt64) (int64, error)
+	CountItemsByProductModelID(ctx context.Context, productModelID sql.NullInt64) (int64, error)
+	CreateAnnouncement(ctx context.Context, arg CreateAnnouncementParams) (Announcement, error)
 	CreateAsset(ctx context.Context, arg CreateAssetParams) (Asset, error)
+	CreateCategory(ctx context.Context, arg CreateCategoryParams) (Category, error)
+	CreateCategorySynonym(ctx context.Context, arg CreateCategorySynonymParams) error
+	CreateEncryptionKey(ctx context.Context, arg CreateEncryptionKeyParams) (EncryptionKey, error)
+	CreateFileOutboxEntry(ctx context.Context, arg CreateFileOutboxEntryParams) error
+	CreateInstanceIdentity(ctx context.Context, arg CreateInstanceIdentityParams) error
+	CreateInstanceKey(ctx context.Context, arg CreateInstanceKeyParams) error
 	CreateItem(ctx context.Context, arg CreateItemParams) (Item, error)
+	CreateItemAttribute(ctx context.Context, arg CreateItemAttributeParams) error
+	CreateLoan(ctx context.Context, arg CreateLoanParams) (Loan, error)
+	CreateMaintenanceSchedule(ctx context.Context, arg CreateMaintenanceScheduleParams) (MaintenanceSchedule, error)
+	CreatePart(ctx context.Context, arg CreatePartParams) (Part, error)
 	CreatePeer(ctx context.Context, arg CreatePeerParams) (Peer, error)
+	CreateProductModel(ctx context.Context, arg CreateProductModelParams) (ProductModel, error)
+	CreateProductModelAlias(ctx context.Context, arg CreateProductModelAliasParams) error
+	CreateRepairEvent(ctx context.Context, arg CreateRepairEventParams) (RepairEvent, error)
 	CreateSyncLog(ctx context.Context, arg CreateSyncLogParams) (SyncLog, error)
+	DeleteAnnouncement(ctx context.Context, id int64) error
 	DeleteAsset(ctx context.Context, id int64) error
+	DeleteAssetType(ctx context.Context, name string) error
+	DeleteCategory(ctx context.Context, id int64) error
+	DeleteCategorySynonym(ctx context.Context, synonym string) error
+	DeleteEncryptionKey(ctx context.Context, id int64) error
+	DeleteExpiredSeenAnnouncements(ctx context.Context, expiresAt time.Time) error
+	DeleteFileOutboxEntry(ctx context.Context, id int64) error
 	DeleteItem(ctx context.Context, id int64) error
+	DeleteItemAttributes(ctx context.Context, itemID int64) error
+	DeleteItemTags(ctx context.Context, itemID int64) error
+	DeleteItemWarranty(ctx context.Context, itemID int64) error
+	DeleteLoan(ctx context.Context, id int64) error
+	DeleteMaintenanceSchedule(ctx context.Context, id int64) error
 	DeleteOldSyncLogs(ctx context.Context, timestamp sql.NullTime) error
+	DeletePart(ctx context.Context, id int64) error
+	DeletePartAssets(ctx context.Context, partID int64) error
+	DeletePartItems(ctx context.Context, partID int64) error
+	DeletePartProductModels(ctx context.Context, partID int64) error
 	DeletePeer(ctx context.Context, id string) error
+	DeleteProductModel(ctx context.Context, id int64) error
+	DeleteProductModelAlias(ctx context.Context, modelKey string) error
+	DeleteRepairEvent(ctx context.Context, id int64) error
+	DeleteRepairEventAssets(ctx context.Context, repairEventID int64) error
+	DeleteUnusedTags(ctx context.Context) error
+	GetActiveLoanByItemID(ctx context.Context, itemID sql.NullInt64) (Loan, error)
+	GetAllAnnouncements(ctx context.Context) ([]Announcement, error)
+	GetAllAssetTypes(ctx context.Context) ([]AssetType, error)
+	GetAllAssets(ctx context.Context) ([]Asset, error)
+	GetAllCategories(ctx context.Context) ([]Category, error)
+	GetAllCategorySynonyms(ctx context.Context) ([]CategorySynonym, error)
+	GetAllItemAttributes(ctx context.Context) ([]ItemAttribute, error)
+	GetAllItemTags(ctx context.Context) ([]GetAllItemTagsRow, error)
+	GetAllItemUsageCounters(ctx context.Context) ([]ItemUsageCounter, error)
+	GetAllItemWarranties(ctx context.Context) ([]ItemWarranty, error)
 	GetAllItems(ctx context.Context) ([]Item, error)
+	GetAllLoans(ctx context.Context) ([]Loan, error)
+	GetAllMaintenanceSchedules(ctx context.Context) ([]MaintenanceSchedule, error)
+	GetAllPartAssets(ctx context.Context) ([]PartAsset, error)
+	GetAllPartItems(ctx context.Context) ([]PartItem, error)
+	GetAllPartProductModels(ctx context.Context) ([]PartProductModel, error)
+	GetAllParts(ctx context.Context) ([]Part, error)
 	GetAllPeers(ctx context.Context) ([]Peer, error)
+	GetAllProductModelAliases(ctx context.Context) ([]ProductModelAlias, error)
+	GetAllProductModelAssets(ctx context.Context) ([]Asset, error)
+	GetAllProductModels(ctx context.Context) ([]ProductModel, error)
+	GetAllRepairEventAssets(ctx context.Context) ([]RepairEventAsset, error)
+	GetAllRepairEvents(ctx context.Context) ([]RepairEvent, error)
+	GetAllTags(ctx context.Context) ([]Tag, error)
+	GetAnnouncementByID(ctx context.Context, id int64) (Announcement, error)
+	GetAnnouncementByUID(ctx context.Context, uid string) (Announcement, error)
 	GetAssetByID(ctx context.Context, id int64) (Asset, error)
-	GetAssetsByItemID(ctx context.Context, itemID int64) ([]Asset, error)
+	GetAssetType(ctx context.Context, name string) (AssetType, error)
+	GetAssetTypesModifiedSince(ctx context.Context, updatedAt time.Time) ([]AssetType, error)
+	GetAssetsByItemID(ctx context.Context, itemID sql.NullInt64) ([]Asset, error)
+	GetAssetsByProductModelID(ctx context.Context, productModelID sql.NullInt64) ([]Asset, error)
+	GetCategoryByID(ctx context.Context, id int64) (Category, error)
+	GetCurrentEncryptionKeyID(ctx context.Context) (int64, error)
+	GetEncryptionKeys(ctx context.Context) ([]EncryptionKey, error)
+	GetFileOutboxEntries(ctx context.Context) ([]FileOutbox, error)
+	GetInstanceIdentity(ctx context.Context) (InstanceIdentity, error)
+	GetInstanceKey(ctx context.Context) (InstanceKey, error)
+	GetItemAttributes(ctx context.Context, itemID int64) ([]ItemAttribute, error)
 	GetItemByID(ctx context.Context, id int64) (Item, error)
+	GetItemTags(ctx context.Context, itemID int64) ([]string, error)
+	GetItemUsageCounters(ctx context.Context, itemID int64) ([]ItemUsageCounter, error)
+	GetItemWarranty(ctx context.Context, itemID int64) (ItemWarranty, error)
 	GetItemsModifiedSince(ctx context.Context, updatedAt time.Time) ([]Item, error)
+	GetLoanByID(ctx context.Context, id int64) (Loan, error)
+	GetLoanByUID(ctx context.Context, uid string) (Loan, error)
+	GetLoansByItemID(ctx context.Context, itemID sql.NullInt64) ([]Loan, error)
+	GetLowStockParts(ctx context.Context) ([]Part, error)
+	GetMaintenanceScheduleByID(ctx context.Context, id int64) (MaintenanceSchedule, error)
+	GetMaintenanceSchedulesByItemID(ctx context.Context, itemID int64) ([]MaintenanceSchedule, error)
+	GetPartByID(ctx context.Context, id int64) (Part, error)
+	GetPartByKey(ctx context.Context, partKey string) (Part, error)
+	GetPartsForItem(ctx context.Context, arg GetPartsForItemParams) ([]Part, error)
 	GetPeer(ctx context.Context, id string) (Peer, error)
 	GetPeerByAddress(ctx context.Context, address string) (Peer, error)
+	GetProductModelAlias(ctx context.Context, modelKey string) (ProductModelAlias, error)
+	GetProductModelByID(ctx context.Context, id int64) (ProductModel, error)
+	GetProductModelByKey(ctx context.Context, modelKey string) (ProductModel, error)
+	GetProductModelsModifiedSince(ctx context.Context, updatedAt time.Time) ([]ProductModel, error)
 	GetRecentSyncLogs(ctx context.Context, limit int64) ([]SyncLog, error)
+	GetRepairEventByID(ctx context.Context, id int64) (RepairEvent, error)
+	GetRepairEventByUID(ctx context.Context, uid string) (RepairEvent, error)
+	GetRepairEventsByItemID(ctx context.Context, itemID int64) ([]RepairEvent, error)
 	GetSyncLog(ctx context.Context, id int64) (SyncLog, error)
 	GetSyncLogsByPeer(ctx context.Context, arg GetSyncLogsByPeerParams) ([]SyncLog, error)
 	GetTrustedPeers(ctx context.Context) ([]Peer, error)
+	IsAnnouncementSeen(ctx context.Context, uid string) (int64, error)
+	MarkAnnouncementSeen(ctx context.Context, arg MarkAnnouncementSeenParams) error
+	MoveCategorySynonyms(ctx context.Context, arg MoveCategorySynonymsParams) error
+	MovePartProductModels(ctx context.Context, arg MovePartProductModelsParams) error
+	MoveProductModelAliases(ctx context.Context, arg MoveProductModelAliasesParams) error
+	MoveProductModelAssets(ctx context.Context, arg MoveProductModelAssetsParams) error
+	ReassignCategoryItems(ctx context.Context, arg ReassignCategoryItemsParams) error
+	ReassignProductModelItems(ctx context.Context, arg ReassignProductModelItemsParams) error
+	RenameCategoryItems(ctx context.Context, arg RenameCategoryItemsParams) error
+	ReparentCategories(ctx context.Context, arg ReparentCategoriesParams) error
 	SearchItems(ctx context.Context, arg SearchItemsParams) ([]Item, error)
+	SearchItemsByProduct(ctx context.Context, arg SearchItemsByProductParams) ([]Item, error)
+	SetAssetCurrent(ctx context.Context, arg SetAssetCurrentParams) error
+	SetAssetProductModel(ctx context.Context, arg SetAssetProductModelParams) error
+	SetAssetSupersededBy(ctx context.Context, arg SetAssetSupersededByParams) error
+	SetAssetSupersedes(ctx context.Context, arg SetAssetSupersedesParams) error
+	SetInstancePrivateKey(ctx context.Context, privateKey []byte) error
+	SetItemProductModel(ctx context.Context, arg SetItemProductModelParams) error
+	SetItemSecrets(ctx context.Context, arg SetItemSecretsParams) error
+	SetItemUsageCounter(ctx context.Context, arg SetItemUsageCounterParams) error
+	SetItemWarranty(ctx context.Context, arg SetItemWarrantyParams) error
+	SetPartQuantity(ctx context.Context, arg SetPartQuantityParams) error
+	TouchItem(ctx context.Context, arg TouchItemParams) error
+	TouchPart(ctx context.Context, arg TouchPartParams) error
+	TouchProductModel(ctx context.Context, arg TouchProductModelParams) error
+	UpdateAnnouncement(ctx context.Context, arg UpdateAnnouncementParams) error
+	UpdateAssetVersion(ctx context.Context, arg UpdateAssetVersionParams) error
+	UpdateCategory(ctx context.Context, arg UpdateCategoryParams) error
+	UpdateEncryptionKeyWrap(ctx context.Context, arg UpdateEncryptionKeyWrapParams) error
 	UpdateItem(ctx context.Context, arg UpdateItemParams) error
+	UpdateLoan(ctx context.Context, arg UpdateLoanParams) error
+	UpdatePart(ctx context.Context, arg UpdatePartParams) error
+	UpdatePeerKey(ctx context.Context, arg UpdatePeerKeyParams) error
 	UpdatePeerLastSeen(ctx context.Context, arg UpdatePeerLastSeenParams) error
 	UpdatePeerLastSync(ctx context.Context, arg UpdatePeerLastSyncParams) error
 	UpdatePeerTrust(ctx context.Context, arg UpdatePeerTrustParams) error
+	UpdateRepairEvent(ctx context.Context, arg UpdateRepairEventParams) error
+	UpsertAssetType(ctx context.Context, arg UpsertAssetTypeParams) (AssetType, error)
+	UpsertTag(ctx context.Context, name string) (Tag, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,64 @@
+-- name: CreateInstanceKey :exec
+INSERT OR IGNORE INTO instance_keys (id, public_key, private_key, created_at)
+VALUES (1, ?, ?, ?);
+
+-- name: GetInstanceKey :one
+SELECT * FROM instance_keys
+WHERE id = 1;
+
+-- name: SetInstancePrivateKey :exec
+UPDATE instance_keys
+SET private_key = ?
+WHERE id = 1;
+
+-- name: CreateAnnouncement :one
+INSERT INTO announcements (
+    uid, kind, title, body, author_name, author_key, signature, max_hops, hops,
+    withdrawn, received_from, created_at, updated_at, expires_at
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetAnnouncementByID :one
+SELECT * FROM announcements
+WHERE id = ?;
+
+-- name: GetAnnouncementByUID :one
+SELECT * FROM announcements
+WHERE uid = ?;
+
+-- name: GetAllAnnouncements :many
+SELECT * FROM announcements
+ORDER BY created_at DESC, id DESC;
+
+-- name: UpdateAnnouncement :exec
+UPDATE announcements
+SET
+    kind = ?,
+    title = ?,
+    body = ?,
+    author_name = ?,
+    signature = ?,
+    max_hops = ?,
+    hops = ?,
+    withdrawn = ?,
+    received_from = ?,
+    updated_at = ?,
+    expires_at = ?
+WHERE id = ?;
+
+-- name: DeleteAnnouncement :exec
+DELETE FROM announcements
+WHERE id = ?;
+
+-- name: MarkAnnouncementSeen :exec
+INSERT OR IGNORE INTO seen_announcements (uid, expires_at)
+VALUES (?, ?);
+
+-- name: IsAnnouncementSeen :one
+SELECT COUNT(*) FROM seen_announcements
+WHERE uid = ?;
+
+-- name: DeleteExpiredSeenAnnouncements :exec
+DELETE FROM seen_announcements
+WHERE expires_at <= ?;
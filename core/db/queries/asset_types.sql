@@ -0,0 +1,21 @@
+-- name: UpsertAssetType :one
+INSERT INTO asset_types (name, label, mime_patterns, counts_for_health, is_builtin, updated_at)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT (name) DO UPDATE SET
+    label = excluded.label,
+    mime_patterns = excluded.mime_patterns,
+    counts_for_health = excluded.counts_for_health,
+    updated_at = excluded.updated_at
+RETURNING *;
+
+-- name: GetAssetType :one
+SELECT * FROM asset_types WHERE name = ?;
+
+-- name: GetAllAssetTypes :many
+SELECT * FROM asset_types ORDER BY is_builtin DESC, label;
+
+-- name: GetAssetTypesModifiedSince :many
+SELECT * FROM asset_types WHERE updated_at > ? ORDER BY updated_at;
+
+-- name: DeleteAssetType :exec
+DELETE FROM asset_types WHERE name = ?;
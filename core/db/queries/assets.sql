@@ -1,8 +1,9 @@
 -- name: CreateAsset :one
 INSERT INTO assets (
-    item_id, type, name, file_path, file_size, file_hash, created_at
+    item_id, product_model_id, type, name, file_path, file_size, file_hash,
+    created_at, version_label, release_date, supersedes_id, is_current
 ) VALUES (
-    ?, ?, ?, ?, ?, ?, ?
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
 )
 RETURNING *;
 
@@ -26,3 +27,51 @@ WHERE item_id = ?;
 -- name: CountAssetsByItemIDAndType :one
 SELECT COUNT(*) FROM assets
 WHERE item_id = ? AND type = ?;
+
+-- name: CountAssetsByType :one
+SELECT COUNT(*) FROM assets
+WHERE type = ?;
+
+-- name: SetAssetCurrent :exec
+UPDATE assets
+SET is_current = ?
+WHERE id = ?;
+
+-- name: SetAssetSupersededBy :exec
+UPDATE assets
+SET superseded_by_id = ?
+WHERE id = ?;
+
+-- name: SetAssetSupersedes :exec
+UPDATE assets
+SET supersedes_id = ?
+WHERE id = ?;
+
+-- name: UpdateAssetVersion :exec
+UPDATE assets
+SET version_label = ?, release_date = ?
+WHERE id = ?;
+
+-- name: GetAssetsByProductModelID :many
+SELECT * FROM assets
+WHERE product_model_id = ?
+ORDER BY created_at DESC;
+
+-- name: GetAllProductModelAssets :many
+SELECT * FROM assets
+WHERE product_model_id IS NOT NULL
+ORDER BY created_at DESC;
+
+-- name: SetAssetProductModel :exec
+UPDATE assets
+SET item_id = NULL, product_model_id = ?
+WHERE id = ?;
+
+-- name: MoveProductModelAssets :exec
+UPDATE assets
+SET product_model_id = sqlc.arg(new_product_model_id)
+WHERE product_model_id = sqlc.arg(old_product_model_id);
+
+-- name: GetAllAssets :many
+SELECT * FROM assets
+ORDER BY id;
@@ -0,0 +1,48 @@
+-- name: CreateCategory :one
+INSERT INTO categories (name, parent_id, updated_at)
+VALUES (?, ?, ?)
+RETURNING *;
+
+-- name: GetCategoryByID :one
+SELECT * FROM categories
+WHERE id = ?;
+
+-- name: GetAllCategories :many
+SELECT * FROM categories
+ORDER BY name;
+
+-- name: UpdateCategory :exec
+UPDATE categories
+SET name = ?, parent_id = ?, updated_at = ?
+WHERE id = ?;
+
+-- name: DeleteCategory :exec
+DELETE FROM categories
+WHERE id = ?;
+
+-- name: CountItemsByCategoryID :one
+SELECT COUNT(*) FROM items
+WHERE category_id = ?;
+
+-- name: ReparentCategories :exec
+UPDATE categories
+SET parent_id = sqlc.arg(new_parent_id), updated_at = sqlc.arg(updated_at)
+WHERE parent_id = sqlc.arg(old_parent_id);
+
+-- name: CreateCategorySynonym :exec
+INSERT INTO category_synonyms (synonym, category_id)
+VALUES (?, ?)
+ON CONFLICT (synonym) DO UPDATE SET category_id = excluded.category_id;
+
+-- name: GetAllCategorySynonyms :many
+SELECT * FROM category_synonyms
+ORDER BY synonym;
+
+-- name: MoveCategorySynonyms :exec
+UPDATE category_synonyms
+SET category_id = sqlc.arg(new_category_id)
+WHERE category_id = sqlc.arg(old_category_id);
+
+-- name: DeleteCategorySynonym :exec
+DELETE FROM category_synonyms
+WHERE synonym = ?;
@@ -0,0 +1,28 @@
+-- name: CreateEncryptionKey :one
+INSERT INTO encryption_keys (
+    salt, kdf_time, kdf_memory, kdf_threads, wrapped_key, created_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: GetEncryptionKeys :many
+SELECT * FROM encryption_keys
+ORDER BY id;
+
+-- name: GetCurrentEncryptionKeyID :one
+SELECT CAST(COALESCE(MAX(id), 0) AS INTEGER) FROM encryption_keys;
+
+-- name: UpdateEncryptionKeyWrap :exec
+UPDATE encryption_keys
+SET
+    salt = ?,
+    kdf_time = ?,
+    kdf_memory = ?,
+    kdf_threads = ?,
+    wrapped_key = ?
+WHERE id = ?;
+
+-- name: DeleteEncryptionKey :exec
+DELETE FROM encryption_keys
+WHERE id = ?;
@@ -0,0 +1,14 @@
+-- name: CreateFileOutboxEntry :exec
+INSERT INTO file_outbox (
+    action, path, target_path, created_at
+) VALUES (
+    ?, ?, ?, ?
+);
+
+-- name: GetFileOutboxEntries :many
+SELECT * FROM file_outbox
+ORDER BY id;
+
+-- name: DeleteFileOutboxEntry :exec
+DELETE FROM file_outbox
+WHERE id = ?;
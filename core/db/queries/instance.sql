@@ -0,0 +1,7 @@
+-- name: CreateInstanceIdentity :exec
+INSERT OR IGNORE INTO instance_identity (id, instance_id, created_at)
+VALUES (1, ?, ?);
+
+-- name: GetInstanceIdentity :one
+SELECT * FROM instance_identity
+WHERE id = 1;
@@ -0,0 +1,16 @@
+-- name: CreateItemAttribute :exec
+INSERT INTO item_attributes (item_id, key, type, value)
+VALUES (?, ?, ?, ?);
+
+-- name: GetItemAttributes :many
+SELECT * FROM item_attributes
+WHERE item_id = ?
+ORDER BY key;
+
+-- name: GetAllItemAttributes :many
+SELECT * FROM item_attributes
+ORDER BY item_id, key;
+
+-- name: DeleteItemAttributes :exec
+DELETE FROM item_attributes
+WHERE item_id = ?;
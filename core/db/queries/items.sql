@@ -1,9 +1,9 @@
 -- name: CreateItem :one
 INSERT INTO items (
-    name, category, brand, model, serial_number,
+    name, category, category_id, brand, model, product_model_id, serial_number,
     purchase_date, photo_path, notes, created_at, updated_at
 ) VALUES (
-    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
 )
 RETURNING *;
 
@@ -20,8 +20,10 @@ UPDATE items
 SET
     name = ?,
     category = ?,
+    category_id = ?,
     brand = ?,
     model = ?,
+    product_model_id = ?,
     serial_number = ?,
     purchase_date = ?,
     photo_path = ?,
@@ -36,6 +38,8 @@ WHERE id = ?;
 -- name: SearchItems :many
 SELECT * FROM items
 WHERE name LIKE ? OR brand LIKE ? OR category LIKE ?
+   OR id IN (SELECT item_id FROM item_attributes WHERE value LIKE ?)
+   OR category_id IN (SELECT category_id FROM category_synonyms WHERE synonym LIKE ?)
 ORDER BY updated_at DESC;
 
 -- name: GetItemsModifiedSince :many
@@ -45,3 +49,44 @@ ORDER BY updated_at DESC;
 
 -- name: CountItems :one
 SELECT COUNT(*) FROM items;
+
+-- name: SearchItemsByProduct :many
+SELECT * FROM items
+WHERE brand LIKE ? AND model LIKE ?
+ORDER BY updated_at DESC;
+
+-- name: RenameCategoryItems :exec
+UPDATE items
+SET category = ?, updated_at = ?
+WHERE category_id = ?;
+
+-- name: ReassignCategoryItems :exec
+UPDATE items
+SET category_id = sqlc.arg(new_category_id), category = sqlc.arg(category), updated_at = sqlc.arg(updated_at)
+WHERE category_id = sqlc.arg(old_category_id);
+
+-- name: SetItemProductModel :exec
+UPDATE items
+SET product_model_id = ?
+WHERE id = ?;
+
+-- name: ReassignProductModelItems :exec
+UPDATE items
+SET product_model_id = sqlc.arg(new_product_model_id)
+WHERE product_model_id = sqlc.arg(old_product_model_id);
+
+-- name: CountItemsByProductModelID :one
+SELECT COUNT(*) FROM items
+WHERE product_model_id = ?;
+
+-- name: TouchItem :exec
+UPDATE items
+SET updated_at = ?
+WHERE id = ?;
+
+-- name: SetItemSecrets :exec
+UPDATE items
+SET
+    serial_number = ?,
+    notes = ?
+WHERE id = ?;
@@ -0,0 +1,47 @@
+-- name: CreateLoan :one
+INSERT INTO loans (
+    uid, direction, item_id, item_name, borrower_peer_id, borrower_name,
+    lender_peer_id, lender_name, lent_at, due_at, returned_at, notes,
+    created_at, updated_at
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetLoanByID :one
+SELECT * FROM loans
+WHERE id = ?;
+
+-- name: GetLoanByUID :one
+SELECT * FROM loans
+WHERE uid = ?;
+
+-- name: GetActiveLoanByItemID :one
+SELECT * FROM loans
+WHERE item_id = ? AND returned_at IS NULL
+LIMIT 1;
+
+-- name: GetLoansByItemID :many
+SELECT * FROM loans
+WHERE item_id = ?
+ORDER BY lent_at DESC, id DESC;
+
+-- name: GetAllLoans :many
+SELECT * FROM loans
+ORDER BY lent_at DESC, id DESC;
+
+-- name: UpdateLoan :exec
+UPDATE loans
+SET
+    item_name = ?,
+    borrower_name = ?,
+    lender_name = ?,
+    lent_at = ?,
+    due_at = ?,
+    returned_at = ?,
+    notes = ?,
+    updated_at = ?
+WHERE id = ?;
+
+-- name: DeleteLoan :exec
+DELETE FROM loans
+WHERE id = ?;
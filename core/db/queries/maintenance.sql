@@ -0,0 +1,44 @@
+-- name: CreateMaintenanceSchedule :one
+INSERT INTO maintenance_schedules (
+    item_id, task, interval_days, interval_usage, usage_unit, last_done_usage,
+    created_at, updated_at
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetMaintenanceScheduleByID :one
+SELECT * FROM maintenance_schedules
+WHERE id = ?;
+
+-- name: GetMaintenanceSchedulesByItemID :many
+SELECT * FROM maintenance_schedules
+WHERE item_id = ?
+ORDER BY id;
+
+-- name: GetAllMaintenanceSchedules :many
+SELECT * FROM maintenance_schedules
+ORDER BY item_id, id;
+
+-- name: CompleteMaintenanceSchedule :exec
+UPDATE maintenance_schedules
+SET last_done_at = ?, last_done_usage = ?, updated_at = ?
+WHERE id = ?;
+
+-- name: DeleteMaintenanceSchedule :exec
+DELETE FROM maintenance_schedules
+WHERE id = ?;
+
+-- name: SetItemUsageCounter :exec
+INSERT INTO item_usage_counters (item_id, unit, value, updated_at)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (item_id, unit) DO UPDATE
+SET value = excluded.value, updated_at = excluded.updated_at;
+
+-- name: GetItemUsageCounters :many
+SELECT * FROM item_usage_counters
+WHERE item_id = ?
+ORDER BY unit;
+
+-- name: GetAllItemUsageCounters :many
+SELECT * FROM item_usage_counters
+ORDER BY item_id, unit;
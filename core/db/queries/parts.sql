@@ -0,0 +1,98 @@
+-- name: CreatePart :one
+INSERT INTO parts (
+    name, reference, part_key, quantity, min_quantity, location, notes,
+    created_at, updated_at
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetPartByID :one
+SELECT * FROM parts
+WHERE id = ?;
+
+-- name: GetPartByKey :one
+SELECT * FROM parts
+WHERE part_key = ?;
+
+-- name: GetAllParts :many
+SELECT * FROM parts
+ORDER BY name, id;
+
+-- name: GetLowStockParts :many
+SELECT * FROM parts
+WHERE quantity < min_quantity
+ORDER BY name, id;
+
+-- name: GetPartsForItem :many
+SELECT * FROM parts
+WHERE id IN (SELECT part_id FROM part_items WHERE item_id = sqlc.arg(item_id))
+   OR id IN (SELECT part_id FROM part_product_models WHERE product_model_id = sqlc.arg(product_model_id))
+ORDER BY name, id;
+
+-- name: UpdatePart :exec
+UPDATE parts
+SET
+    name = ?,
+    reference = ?,
+    part_key = ?,
+    quantity = ?,
+    min_quantity = ?,
+    location = ?,
+    notes = ?,
+    updated_at = ?
+WHERE id = ?;
+
+-- name: SetPartQuantity :exec
+UPDATE parts
+SET quantity = ?, updated_at = ?
+WHERE id = ?;
+
+-- name: TouchPart :exec
+UPDATE parts
+SET updated_at = ?
+WHERE id = ?;
+
+-- name: DeletePart :exec
+DELETE FROM parts
+WHERE id = ?;
+
+-- name: AddPartItem :exec
+INSERT OR IGNORE INTO part_items (part_id, item_id)
+VALUES (?, ?);
+
+-- name: DeletePartItems :exec
+DELETE FROM part_items
+WHERE part_id = ?;
+
+-- name: GetAllPartItems :many
+SELECT * FROM part_items
+ORDER BY part_id, item_id;
+
+-- name: AddPartProductModel :exec
+INSERT OR IGNORE INTO part_product_models (part_id, product_model_id)
+VALUES (?, ?);
+
+-- name: DeletePartProductModels :exec
+DELETE FROM part_product_models
+WHERE part_id = ?;
+
+-- name: GetAllPartProductModels :many
+SELECT * FROM part_product_models
+ORDER BY part_id, product_model_id;
+
+-- name: MovePartProductModels :exec
+UPDATE OR IGNORE part_product_models
+SET product_model_id = sqlc.arg(new_product_model_id)
+WHERE product_model_id = sqlc.arg(old_product_model_id);
+
+-- name: AddPartAsset :exec
+INSERT OR IGNORE INTO part_assets (part_id, asset_id)
+VALUES (?, ?);
+
+-- name: DeletePartAssets :exec
+DELETE FROM part_assets
+WHERE part_id = ?;
+
+-- name: GetAllPartAssets :many
+SELECT * FROM part_assets
+ORDER BY part_id, asset_id;
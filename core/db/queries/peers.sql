@@ -18,6 +18,9 @@ UPDATE peers SET last_seen = ? WHERE id = ?;
 -- name: UpdatePeerLastSync :exec
 UPDATE peers SET last_sync = ? WHERE id = ?;
 
+-- name: UpdatePeerKey :exec
+UPDATE peers SET public_key = ? WHERE id = ?;
+
 -- name: UpdatePeerTrust :exec
 UPDATE peers SET is_trusted = ? WHERE id = ?;
 
@@ -0,0 +1,55 @@
+-- name: CreateProductModel :one
+INSERT INTO product_models (brand, model, model_key, created_at, updated_at)
+VALUES (?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetProductModelByID :one
+SELECT * FROM product_models
+WHERE id = ?;
+
+-- name: GetProductModelByKey :one
+SELECT * FROM product_models
+WHERE model_key = ?;
+
+-- name: GetAllProductModels :many
+SELECT * FROM product_models
+ORDER BY brand, model;
+
+-- name: GetProductModelsModifiedSince :many
+SELECT * FROM product_models
+WHERE updated_at > ?
+ORDER BY brand, model;
+
+-- name: TouchProductModel :exec
+UPDATE product_models
+SET updated_at = ?
+WHERE id = ?;
+
+-- name: DeleteProductModel :exec
+DELETE FROM product_models
+WHERE id = ?;
+
+-- name: CreateProductModelAlias :exec
+INSERT INTO product_model_aliases (model_key, product_model_id, brand, model)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (model_key) DO UPDATE SET
+    product_model_id = excluded.product_model_id,
+    brand = excluded.brand,
+    model = excluded.model;
+
+-- name: GetProductModelAlias :one
+SELECT * FROM product_model_aliases
+WHERE model_key = ?;
+
+-- name: GetAllProductModelAliases :many
+SELECT * FROM product_model_aliases
+ORDER BY brand, model;
+
+-- name: DeleteProductModelAlias :exec
+DELETE FROM product_model_aliases
+WHERE model_key = ?;
+
+-- name: MoveProductModelAliases :exec
+UPDATE product_model_aliases
+SET product_model_id = sqlc.arg(new_product_model_id)
+WHERE product_model_id = sqlc.arg(old_product_model_id);
@@ -0,0 +1,51 @@
+-- name: CreateRepairEvent :one
+INSERT INTO repair_events (
+    uid, item_id, performed_at, diagnosis, action, parts_replaced, cost_cents,
+    performed_by, created_at, updated_at
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetRepairEventByID :one
+SELECT * FROM repair_events
+WHERE id = ?;
+
+-- name: GetRepairEventByUID :one
+SELECT * FROM repair_events
+WHERE uid = ?;
+
+-- name: GetRepairEventsByItemID :many
+SELECT * FROM repair_events
+WHERE item_id = ?
+ORDER BY performed_at DESC, id DESC;
+
+-- name: GetAllRepairEvents :many
+SELECT * FROM repair_events
+ORDER BY item_id, performed_at DESC, id DESC;
+
+-- name: UpdateRepairEvent :exec
+UPDATE repair_events
+SET performed_at = ?,
+    diagnosis = ?,
+    action = ?,
+    parts_replaced = ?,
+    cost_cents = ?,
+    performed_by = ?,
+    updated_at = ?
+WHERE id = ?;
+
+-- name: DeleteRepairEvent :exec
+DELETE FROM repair_events
+WHERE id = ?;
+
+-- name: AddRepairEventAsset :exec
+INSERT OR IGNORE INTO repair_event_assets (repair_event_id, asset_id)
+VALUES (?, ?);
+
+-- name: GetAllRepairEventAssets :many
+SELECT * FROM repair_event_assets
+ORDER BY repair_event_id, asset_id;
+
+-- name: DeleteRepairEventAssets :exec
+DELETE FROM repair_event_assets
+WHERE repair_event_id = ?;
@@ -0,0 +1,32 @@
+-- name: UpsertTag :one
+INSERT INTO tags (name)
+VALUES (?)
+ON CONFLICT (name) DO UPDATE SET name = tags.name
+RETURNING *;
+
+-- name: GetAllTags :many
+SELECT * FROM tags
+ORDER BY name;
+
+-- name: AddItemTag :exec
+INSERT OR IGNORE INTO item_tags (item_id, tag_id)
+VALUES (?, ?);
+
+-- name: GetItemTags :many
+SELECT tags.name FROM tags
+JOIN item_tags ON item_tags.tag_id = tags.id
+WHERE item_tags.item_id = ?
+ORDER BY tags.name;
+
+-- name: GetAllItemTags :many
+SELECT item_tags.item_id, tags.name FROM item_tags
+JOIN tags ON tags.id = item_tags.tag_id
+ORDER BY item_tags.item_id, tags.name;
+
+-- name: DeleteItemTags :exec
+DELETE FROM item_tags
+WHERE item_id = ?;
+
+-- name: DeleteUnusedTags :exec
+DELETE FROM tags
+WHERE id NOT IN (SELECT tag_id FROM item_tags);
@@ -0,0 +1,18 @@
+-- name: SetItemWarranty :exec
+INSERT INTO item_warranties (item_id, duration_months, provider, receipt_asset_id, updated_at)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (item_id) DO UPDATE
+SET duration_months = excluded.duration_months, provider = excluded.provider,
+    receipt_asset_id = excluded.receipt_asset_id, updated_at = excluded.updated_at;
+
+-- name: GetItemWarranty :one
+SELECT * FROM item_warranties
+WHERE item_id = ?;
+
+-- name: GetAllItemWarranties :many
+SELECT * FROM item_warranties
+ORDER BY item_id;
+
+-- name: DeleteItemWarranty :exec
+DELETE FROM item_warranties
+WHERE item_id = ?;
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// AnnouncementKind tells whether an announcement offers or asks for help
+type AnnouncementKind string
+
+const (
+	AnnouncementOffer   AnnouncementKind = "offer"   // "Je prête une perceuse"
+	AnnouncementRequest AnnouncementKind = "request" // "Je cherche un soudeur"
+)
+
+// Announcement is a help announcement spreading from peer to peer. It is
+// signed by the instance that published it and stops spreading after MaxHops
+// peers or once expired.
+type Announcement struct {
+	ID         int64            `json:"id"`
+	UID        string           `json:"uid"`
+	Kind       AnnouncementKind `json:"kind"`
+	Title      string           `json:"title"`
+	Body       string           `json:"body"`
+	AuthorName string           `json:"author_name"` // Instance name of the author
+	AuthorKey  string           `json:"author_key"`  // Ed25519 public key of the author, base64
+	Signature  string           `json:"signature"`   // Signature of the author over the announcement, base64
+	MaxHops    int64            `json:"max_hops"`
+	Hops       int64            `json:"hops"`      // Peers gone through to get here, not signed
+	Withdrawn  bool             `json:"withdrawn"` // Cancelled by its author
+
+	// Local information, never sent to peers
+	ReceivedFrom string `json:"received_from,omitempty"` // Peer it came from
+	Own          bool   `json:"own"`                     // Published by this instance
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
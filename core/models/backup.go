@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// Backup is a consistent copy of the database and assets at a point in time
+type Backup struct {
+	Name      string    `json:"name"`
+	Path      string    `json:"path"` // Directory holding the database and manifest
+	CreatedAt time.Time `json:"created_at"`
+	Size      int64     `json:"size"` // Of the database and assets
+	Assets    int       `json:"assets"`
+
+	// NewBlobs is the number of asset files copied by the backup, the others
+	// being shared with earlier backups. Only set on creation.
+	NewBlobs int `json:"new_blobs,omitempty"`
+}
+
+// BackupManifest lists the content of a backup, to verify and restore it
+type BackupManifest struct {
+	Version   int          `json:"version"`
+	CreatedAt time.Time    `json:"created_at"`
+	Database  BackupFile   `json:"database"`
+	Assets    []BackupFile `json:"assets"`
+}
+
+// BackupFile is a file of a backup. Asset files are stored once for all
+// backups, by hash.
+type BackupFile struct {
+	Path    string    `json:"path"` // Relative to the assets directory for assets
+	Size    int64     `json:"size"`
+	SHA256  string    `json:"sha256"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// BackupRetention is how many backups pruning keeps. The newest KeepLast
+// backups are kept, along with the newest backup of each of the last
+// KeepDaily days, KeepWeekly weeks and KeepMonthly months that have one. Zero
+// for all keeps every backup.
+type BackupRetention struct {
+	KeepLast    int `json:"keep_last" mapstructure:"keep_last"`
+	KeepDaily   int `json:"keep_daily" mapstructure:"keep_daily"`
+	KeepWeekly  int `json:"keep_weekly" mapstructure:"keep_weekly"`
+	KeepMonthly int `json:"keep_monthly" mapstructure:"keep_monthly"`
+}
+
+// BackupScheduleStatus reports on the backups made on a schedule
+type BackupScheduleStatus struct {
+	Enabled      bool                      `json:"enabled"`
+	Interval     string                    `json:"interval,omitempty"` // e.g. "24h0m0s"
+	Healthy      bool                      `json:"healthy"`            // No destination missing, failing or overdue
+	Destinations []BackupDestinationStatus `json:"destinations"`
+}
+
+// BackupDestinationStatus reports on the backups of a destination directory
+type BackupDestinationStatus struct {
+	Path       string     `json:"path"`
+	Available  bool       `json:"available"` // False while e.g. a USB drive is not mounted
+	Backups    int        `json:"backups"`
+	LastBackup *Backup    `json:"last_backup,omitempty"`
+	NextBackup *time.Time `json:"next_backup,omitempty"`
+	Overdue    bool       `json:"overdue"` // No backup for twice the interval
+
+	LastError   string     `json:"last_error,omitempty"` // Of the last scheduled backup, if it failed
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+}
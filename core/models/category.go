@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Category groups items in a hierarchy, e.g. Maison > Électroménager > Aspirateur
+type Category struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	ParentID  *int64    `json:"parent_id,omitempty"`
+	Synonyms  []string  `json:"synonyms"` // Alternative spellings resolved to this category
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ItemFilter narrows down an item listing. Empty fields match every item.
+type ItemFilter struct {
+	Query      string            `json:"query"`    // Name, brand, category, category synonym or attribute value
+	Category   string            `json:"category"` // Includes every subcategory
+	Tags       []string          `json:"tags"`     // Items must carry every tag
+	Attributes []AttributeFilter `json:"attributes"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// EncryptionStatus tells whether the data is encrypted at rest, and whether
+// the keys were unlocked with the passphrase
+type EncryptionStatus struct {
+	Enabled  bool       `json:"enabled"`
+	Unlocked bool       `json:"unlocked"`
+	KeyID    int64      `json:"key_id,omitempty"`    // Key new data is encrypted with
+	KeySince *time.Time `json:"key_since,omitempty"` // Creation of that key
+	OldKeys  int        `json:"old_keys,omitempty"`  // Keys left by an interrupted rotation
+}
+
+// EncryptionReport is the outcome of encrypting, re-encrypting or decrypting
+// the data at rest
+type EncryptionReport struct {
+	Items  int `json:"items"`  // Items whose sensitive fields were rewritten
+	Assets int `json:"assets"` // Asset files rewritten
+}
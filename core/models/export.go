@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// ExportVersion is the version of the JSON export format. Version 1.0 files,
+// written by the GUI of earlier releases, can still be imported.
+const ExportVersion = "2.0"
+
+// Export is the whole inventory as written by a JSON export
+type Export struct {
+	ExportDate time.Time        `json:"export_date"`
+	Version    string           `json:"version"`
+	Items      []ItemWithAssets `json:"items"` // Assets are listed but not imported
+	Stats      ExportStats      `json:"stats"`
+}
+
+// ExportStats sums up an export
+type ExportStats struct {
+	TotalItems int `json:"total_items"`
+}
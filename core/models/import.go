@@ -0,0 +1,56 @@
+package models
+
+// Item fields a CSV column can be imported into. A custom attribute is
+// imported with ImportFieldAttribute followed by its key, e.g. attr.voltage.
+const (
+	ImportFieldName         = "name"
+	ImportFieldCategory     = "category"
+	ImportFieldBrand        = "brand"
+	ImportFieldModel        = "model"
+	ImportFieldSerialNumber = "serial_number"
+	ImportFieldPurchaseDate = "purchase_date"
+	ImportFieldNotes        = "notes"
+	ImportFieldTags         = "tags"
+	ImportFieldAttribute    = "attr."
+)
+
+// CSVImportOptions tune an import of items from a CSV file
+type CSVImportOptions struct {
+	// Columns maps item fields to the header of the column holding them, on
+	// top of the columns recognized by their header (e.g. "Marque" or "brand")
+	Columns map[string]string `json:"columns"`
+	DryRun  bool              `json:"dry_run"` // Report what would be imported without importing it
+}
+
+// ImportAction is what an import does with a row
+type ImportAction string
+
+const (
+	ImportCreate    ImportAction = "create"
+	ImportDuplicate ImportAction = "duplicate" // Skipped, already in the inventory or the file
+	ImportInvalid   ImportAction = "invalid"   // Skipped, see the errors of the row
+)
+
+// ImportRow reports the outcome of one row of an import
+type ImportRow struct {
+	Line   int          `json:"line"` // In the file, the header being line 1
+	Action ImportAction `json:"action"`
+	Item   Item         `json:"item"` // With its ID once created
+
+	// Set on duplicates: the item with the same brand, model and serial
+	// number, or the earlier row holding it
+	DuplicateOf   *int64 `json:"duplicate_of,omitempty"`
+	DuplicateLine int    `json:"duplicate_line,omitempty"`
+
+	Errors []string `json:"errors,omitempty"`
+}
+
+// ImportReport is the outcome of an import, row by row
+type ImportReport struct {
+	DryRun     bool              `json:"dry_run"`
+	Columns    map[string]string `json:"columns"` // Column used for each item field
+	Created    int               `json:"created"`
+	Duplicates int               `json:"duplicates"`
+	Invalid    int               `json:"invalid"`
+	Rows       []ImportRow       `json:"rows"`
+}
@@ -15,18 +15,87 @@ type Item struct {
 	Notes        string    `json:"notes"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
+
+	// Attributes holds typed specs such as voltage or filter reference, Tags
+	// free labels such as "cuisine". A nil slice leaves the stored values
+	// untouched on update.
+	Attributes []Attribute `json:"attributes"`
+	Tags       []string    `json:"tags"`
+
+	// ProductModelID is the local product model matching Brand and Model, set
+	// by the service
+	ProductModelID *int64 `json:"product_model_id,omitempty"`
+
+	// Warranty is only loaded for a single item. A nil warranty leaves the
+	// stored one untouched on update, a zero duration removes it.
+	Warranty *Warranty `json:"warranty,omitempty"`
+
+	// Loan is the current loan of the item, only loaded for a single item
+	Loan *Loan `json:"loan,omitempty"`
+
+	// Repairs is the maintenance history, only loaded for sync. Events are
+	// merged by UID, a nil slice leaves the stored history untouched.
+	Repairs []RepairEvent `json:"repairs,omitempty"`
+}
+
+// AttributeType represents the type of a custom attribute value
+type AttributeType string
+
+const (
+	AttributeTypeText    AttributeType = "text"
+	AttributeTypeNumber  AttributeType = "number"
+	AttributeTypeBoolean AttributeType = "boolean"
+	AttributeTypeDate    AttributeType = "date" // YYYY-MM-DD
+)
+
+// Attribute is a typed custom field of an item
+type Attribute struct {
+	Key   string        `json:"key"`
+	Type  AttributeType `json:"type"`
+	Value string        `json:"value"`
+}
+
+// AttributeDefinition describes an attribute suggested by a category template
+type AttributeDefinition struct {
+	Key   string        `json:"key"`
+	Label string        `json:"label"`
+	Type  AttributeType `json:"type"`
 }
 
-// Asset represents a file associated with an item (PDF, STL, firmware, etc.)
+// AttributeTemplate lists the attributes expected for an item category
+type AttributeTemplate struct {
+	Category   string                `json:"category"`
+	Attributes []AttributeDefinition `json:"attributes"`
+}
+
+// AttributeFilter selects items by attribute, e.g. voltage>=220
+type AttributeFilter struct {
+	Key      string `json:"key"`
+	Operator string `json:"operator"` // =, !=, <, <=, >, >=
+	Value    string `json:"value"`
+}
+
+// Asset represents a file associated with an item (PDF, STL, firmware, etc.),
+// or shared by every item of a product model
 type Asset struct {
 	ID        int64     `json:"id"`
-	ItemID    int64     `json:"item_id"`
+	ItemID    int64     `json:"item_id"` // 0 for product model documentation
 	Type      AssetType `json:"type"`
 	Name      string    `json:"name"`
 	FilePath  string    `json:"file_path"`
 	FileSize  int64     `json:"file_size"`
 	FileHash  string    `json:"file_hash"` // SHA256 for integrity
 	CreatedAt time.Time `json:"created_at"`
+
+	// Set for documentation shared by every item of a product model
+	ProductModelID *int64 `json:"product_model_id,omitempty"`
+
+	// Revision chain: a newer revision supersedes an older one of the same document
+	VersionLabel   string     `json:"version_label"`
+	ReleaseDate    *time.Time `json:"release_date,omitempty"`
+	SupersedesID   *int64     `json:"supersedes_id,omitempty"`
+	SupersededByID *int64     `json:"superseded_by_id,omitempty"`
+	IsCurrent      bool       `json:"is_current"` // Only current revisions count towards health
 }
 
 // AssetType represents the type of asset
@@ -41,8 +110,20 @@ const (
 	AssetTypeDriver         AssetType = "driver"
 	AssetTypeSchematic      AssetType = "schematic"
 	AssetTypeOther          AssetType = "other"
+	AssetTypePhoto          AssetType = "photo" // Item photos, served with thumbnails
 )
 
+// AssetTypeDefinition describes an asset type known to this instance.
+// Built-in types mirror the AssetType constants; users may add their own.
+type AssetTypeDefinition struct {
+	Name            AssetType `json:"name"`
+	Label           string    `json:"label"`
+	MimePatterns    []string  `json:"mime_patterns"`     // e.g. "application/pdf", "image/*"; empty accepts any file
+	CountsForHealth bool      `json:"counts_for_health"` // Whether these assets count towards documentation health
+	IsBuiltin       bool      `json:"is_builtin"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
 // DocumentationHealth represents the completeness of an item's documentation
 type DocumentationHealth string
 
@@ -52,6 +133,22 @@ const (
 	HealthSecured    DocumentationHealth = "secured"
 )
 
+// HealthRule declares which asset types an item category needs to be considered
+// fully documented
+type HealthRule struct {
+	Category string      `json:"category"`
+	Required []AssetType `json:"required"`
+	Optional []AssetType `json:"optional"`
+}
+
+// HealthReport details the documentation an item is missing
+type HealthReport struct {
+	Item            Item                `json:"item"`
+	Health          DocumentationHealth `json:"health"`
+	MissingRequired []AssetType         `json:"missing_required"`
+	MissingOptional []AssetType         `json:"missing_optional"`
+}
+
 // ItemWithAssets is a DTO that includes an item with its associated assets
 type ItemWithAssets struct {
 	Item   Item    `json:"item"`
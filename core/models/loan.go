@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// LoanDirection tells whether an item was lent or borrowed by this instance
+type LoanDirection string
+
+const (
+	LoanOut LoanDirection = "out" // Lent by this instance
+	LoanIn  LoanDirection = "in"  // Borrowed from a peer, as recorded by the lender
+)
+
+// Loan records that an item is lent to someone since LentAt, until it is
+// returned. The lender's instance owns the loan, the borrower's instance
+// receives its status on sync.
+type Loan struct {
+	ID        int64         `json:"id"`
+	UID       string        `json:"uid"`
+	Direction LoanDirection `json:"direction"`
+	ItemID    *int64        `json:"item_id,omitempty"` // Local item, loans out only
+	ItemName  string        `json:"item_name"`
+
+	// BorrowerPeerID is set when the borrower has an instance, for the loan to
+	// reach it. BorrowerName defaults to the name of that peer.
+	BorrowerPeerID string `json:"borrower_peer_id,omitempty"`
+	BorrowerName   string `json:"borrower_name"`
+
+	// Set on loans in
+	LenderPeerID string `json:"lender_peer_id,omitempty"`
+	LenderName   string `json:"lender_name,omitempty"`
+
+	LentAt     time.Time  `json:"lent_at"`
+	DueAt      *time.Time `json:"due_at,omitempty"` // Expected return
+	ReturnedAt *time.Time `json:"returned_at,omitempty"`
+	Notes      string     `json:"notes"`
+	Overdue    bool       `json:"overdue"` // Computed: not returned past its due date
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
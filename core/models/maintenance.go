@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// MaintenanceSchedule is a recurring preventive task on an item, such as
+// descaling every 90 days or changing a filter every 500 hours. It is due
+// every IntervalDays days and/or every IntervalUsage units of the item's
+// usage counter, whichever comes first.
+type MaintenanceSchedule struct {
+	ID            int64      `json:"id"`
+	ItemID        int64      `json:"item_id"`
+	Task          string     `json:"task"`
+	IntervalDays  int64      `json:"interval_days"`  // 0 when only usage-based
+	IntervalUsage int64      `json:"interval_usage"` // 0 when only time-based
+	UsageUnit     string     `json:"usage_unit"`     // Counter the usage interval applies to, e.g. hours
+	LastDoneAt    *time.Time `json:"last_done_at,omitempty"`
+	LastDoneUsage int64      `json:"last_done_usage"` // Counter value when last done
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// MaintenanceTask is a schedule with its next due point
+type MaintenanceTask struct {
+	Schedule     MaintenanceSchedule `json:"schedule"`
+	ItemName     string              `json:"item_name"`
+	NextDueAt    *time.Time          `json:"next_due_at,omitempty"`    // Time-based schedules
+	NextDueUsage *int64              `json:"next_due_usage,omitempty"` // Usage-based schedules
+	CurrentUsage int64               `json:"current_usage"`
+	Due          bool                `json:"due"` // The task should have been done already
+}
+
+// UsageCounter is a usage reading of an item, e.g. 520 operating hours
+type UsageCounter struct {
+	Unit      string    `json:"unit"`
+	Value     int64     `json:"value"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Part is a spare part kept in stock, such as a fuse, a belt or a gasket
+type Part struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Reference   string `json:"reference"`    // Manufacturer part number, e.g. 1195 H7
+	Quantity    int64  `json:"quantity"`     // On hand
+	MinQuantity int64  `json:"min_quantity"` // Low stock below this
+	Location    string `json:"location"`     // Where it is stored, e.g. a drawer
+	Notes       string `json:"notes"`
+
+	// Compatible local items and product models, and STL files to print a
+	// replacement. A nil slice leaves the stored links untouched on update.
+	ItemIDs         []int64 `json:"item_ids"`
+	ProductModelIDs []int64 `json:"product_model_ids"`
+	AssetIDs        []int64 `json:"asset_ids"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SharedPart is what peers learn about a part: the product models it fits.
+// Stock and local links are not shared.
+type SharedPart struct {
+	Name          string              `json:"name"`
+	Reference     string              `json:"reference"`
+	ProductModels []ProductModelAlias `json:"product_models"` // Brand and model of the compatible product models
+}
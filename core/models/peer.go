@@ -25,10 +25,15 @@ type Peer struct {
 
 // SyncResult represents the result of a synchronization
 type SyncResult struct {
-	ItemsReceived int
-	ItemsSent     int
-	Conflicts     int
-	DurationMs    int64
+	ItemsReceived         int
+	ItemsSent             int
+	Conflicts             int
+	DurationMs            int64
+	DocumentsReceived     int // Shared product model documentation
+	PartsReceived         int // Part compatibilities learnt
+	AnnouncementsReceived int // New help announcements
+	LoansReceived         int // New loans made to this instance
+	Warnings              []string `json:",omitempty"` // Steps that failed, the sync going on without them
 }
 
 // SyncLog represents a synchronization log entry
@@ -54,6 +59,21 @@ type ChangeSet struct {
 type SyncInfo struct {
 	InstanceID   string
 	InstanceName string
+	PublicKey    string // Announcement key, that requests are signed with
 	LastSync     *time.Time
 	ItemCount    int
 }
+
+// SearchQuery describes a documentation search sent to peers
+type SearchQuery struct {
+	Brand     string    `json:"brand"`
+	Model     string    `json:"model"`
+	AssetType AssetType `json:"asset_type,omitempty"`
+}
+
+// SearchResult is an item matching a search, along with the peer that holds it
+type SearchResult struct {
+	PeerID   string         `json:"peer_id"`
+	PeerName string         `json:"peer_name"`
+	Item     ItemWithAssets `json:"item"`
+}
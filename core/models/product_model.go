@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// ProductModel is a product line such as Brandt WTC1234. It owns the
+// documentation shared by every unit of that model, and is matched across
+// instances by its normalised brand and model.
+type ProductModel struct {
+	ID        int64               `json:"id"`
+	Brand     string              `json:"brand"`
+	Model     string              `json:"model"`
+	Aliases   []ProductModelAlias `json:"aliases"` // Other names of the same product, e.g. a rebadged model
+	CreatedAt time.Time           `json:"created_at"`
+	UpdatedAt time.Time           `json:"updated_at"`
+}
+
+// ProductModelAlias is another brand and model name resolved to a product model
+type ProductModelAlias struct {
+	Brand string `json:"brand"`
+	Model string `json:"model"`
+}
+
+// ProductModelWithAssets is a product model with its shared documentation
+type ProductModelWithAssets struct {
+	ProductModel ProductModel `json:"product_model"`
+	Assets       []Asset      `json:"assets"`
+	ItemCount    int64        `json:"item_count"` // Local items of this model
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// RepairEvent is an entry of an item's maintenance history: what was wrong,
+// what was done, with which parts, at what cost and by whom
+type RepairEvent struct {
+	ID            int64     `json:"id"`
+	UID           string    `json:"uid"` // Identifies the event across instances
+	ItemID        int64     `json:"item_id"`
+	PerformedAt   time.Time `json:"performed_at"`
+	Diagnosis     string    `json:"diagnosis"`
+	Action        string    `json:"action"`
+	PartsReplaced string    `json:"parts_replaced"`
+	CostCents     int64     `json:"cost_cents"`
+	PerformedBy   string    `json:"performed_by"`
+	AssetIDs      []int64   `json:"asset_ids"` // Local photos, schematics... documenting the repair
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
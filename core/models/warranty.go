@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// Warranty covers an item for DurationMonths from its purchase date. It stays
+// on this instance, the receipt being a local asset.
+type Warranty struct {
+	DurationMonths int64  `json:"duration_months"`
+	Provider       string `json:"provider"`                   // Manufacturer, retailer or insurer
+	ReceiptAssetID *int64 `json:"receipt_asset_id,omitempty"` // Proof of purchase among the item's assets
+
+	// Computed from the purchase date of the item, nil without one
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	DaysLeft  *int64     `json:"days_left,omitempty"` // Negative once expired
+}
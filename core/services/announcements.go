@@ -0,0 +1,510 @@
+package services
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lhommenul/brique/core/db"
+	"github.com/lhommenul/brique/core/models"
+)
+
+// Limits of announcements, applied to those received from peers as well
+const (
+	DefaultAnnouncementTTL     = 14 * 24 * time.Hour
+	MaxAnnouncementTTL         = 90 * 24 * time.Hour
+	DefaultAnnouncementMaxHops = 3
+	MaxAnnouncementHops        = 10
+)
+
+// PublishAnnouncement signs and stores a new announcement of this instance,
+// expiring after ttl (DefaultAnnouncementTTL if zero). It reaches the peers on
+// their next sync.
+func (s *GossipService) PublishAnnouncement(ctx context.Context, announcement *models.Announcement, ttl time.Duration) error {
+	announcement.Title = strings.TrimSpace(announcement.Title)
+	announcement.Body = strings.TrimSpace(announcement.Body)
+
+	if announcement.Kind != models.AnnouncementOffer && announcement.Kind != models.AnnouncementRequest {
+		return fmt.Errorf("invalid announcement kind %q (expected %s or %s)", announcement.Kind, models.AnnouncementOffer, models.AnnouncementRequest)
+	}
+	if announcement.Title == "" {
+		return errors.New("announcement title is required")
+	}
+	if ttl == 0 {
+		ttl = DefaultAnnouncementTTL
+	}
+	if ttl < 0 || ttl > MaxAnnouncementTTL {
+		return fmt.Errorf("announcements last at most %d days", int(MaxAnnouncementTTL.Hours()/24))
+	}
+	if announcement.MaxHops == 0 {
+		announcement.MaxHops = DefaultAnnouncementMaxHops
+	}
+	if announcement.MaxHops < 0 || announcement.MaxHops > MaxAnnouncementHops {
+		return fmt.Errorf("announcements go through at most %d peers", MaxAnnouncementHops)
+	}
+
+	privateKey, err := s.instanceKey(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	announcement.UID = uuid.New().String()
+	announcement.AuthorName = s.instanceName
+	announcement.AuthorKey = base64.StdEncoding.EncodeToString(privateKey.Public().(ed25519.PublicKey))
+	announcement.Hops = 0
+	announcement.Withdrawn = false
+	announcement.ReceivedFrom = ""
+	announcement.CreatedAt = now
+	announcement.UpdatedAt = now
+	announcement.ExpiresAt = now.Add(ttl)
+
+	if err := signAnnouncement(announcement, privateKey); err != nil {
+		return err
+	}
+
+	created, err := s.queries.CreateAnnouncement(ctx, db.CreateAnnouncementParams{
+		UID:          announcement.UID,
+		Kind:         string(announcement.Kind),
+		Title:        announcement.Title,
+		Body:         announcement.Body,
+		AuthorName:   announcement.AuthorName,
+		AuthorKey:    announcement.AuthorKey,
+		Signature:    announcement.Signature,
+		MaxHops:      announcement.MaxHops,
+		Hops:         0,
+		ReceivedFrom: "",
+		CreatedAt:    announcement.CreatedAt,
+		UpdatedAt:    announcement.UpdatedAt,
+		ExpiresAt:    announcement.ExpiresAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create announcement: %w", err)
+	}
+
+	announcement.ID = created.ID
+	announcement.Own = true
+
+	return nil
+}
+
+// GetAnnouncements returns the announcements still running, newest first,
+// optionally of a single kind. Expired announcements are purged.
+func (s *GossipService) GetAnnouncements(ctx context.Context, kind models.AnnouncementKind) ([]models.Announcement, error) {
+	announcements, err := s.activeAnnouncements(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	running := []models.Announcement{}
+	for _, announcement := range announcements {
+		if announcement.Withdrawn || (kind != "" && announcement.Kind != kind) {
+			continue
+		}
+		running = append(running, announcement)
+	}
+
+	return running, nil
+}
+
+// GetAnnouncement returns an announcement
+func (s *GossipService) GetAnnouncement(ctx context.Context, id int64) (*models.Announcement, error) {
+	dbAnnouncement, err := s.queries.GetAnnouncementByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get announcement: %w", err)
+	}
+
+	ownKey, err := s.instancePublicKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	announcement := dbAnnouncementToModel(dbAnnouncement, ownKey)
+	return &announcement, nil
+}
+
+// WithdrawAnnouncement cancels an announcement of this instance. The signed
+// withdrawal spreads like the announcement did, until it expires.
+func (s *GossipService) WithdrawAnnouncement(ctx context.Context, id int64) error {
+	dbAnnouncement, err := s.queries.GetAnnouncementByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("announcement not found: %w", err)
+	}
+
+	privateKey, err := s.instanceKey(ctx)
+	if err != nil {
+		return err
+	}
+
+	ownKey := base64.StdEncoding.EncodeToString(privateKey.Public().(ed25519.PublicKey))
+	if dbAnnouncement.AuthorKey != ownKey {
+		return errors.New("only the author of an announcement can withdraw it")
+	}
+	if dbAnnouncement.Withdrawn {
+		return nil
+	}
+
+	announcement := dbAnnouncementToModel(dbAnnouncement, ownKey)
+	announcement.Withdrawn = true
+	announcement.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+	if !announcement.UpdatedAt.After(dbAnnouncement.UpdatedAt) {
+		announcement.UpdatedAt = dbAnnouncement.UpdatedAt.Add(time.Second)
+	}
+	if err := signAnnouncement(&announcement, privateKey); err != nil {
+		return err
+	}
+
+	return storeAnnouncement(ctx, s.queries, dbAnnouncement.ID, announcement)
+}
+
+// GetSharedAnnouncements returns the announcements peers may take from this
+// instance: those not expired that can go through one more peer. Withdrawn
+// announcements are included for the withdrawal to spread.
+func (s *GossipService) GetSharedAnnouncements(ctx context.Context) ([]models.Announcement, error) {
+	announcements, err := s.activeAnnouncements(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	shared := []models.Announcement{}
+	for _, announcement := range announcements {
+		if announcement.Hops >= announcement.MaxHops {
+			continue
+		}
+		announcement.ID = 0
+		announcement.ReceivedFrom = ""
+		announcement.Own = false
+		shared = append(shared, announcement)
+	}
+
+	return shared, nil
+}
+
+// ApplyAnnouncements stores the announcements received from a peer. Those
+// with an invalid signature, expired or past their hop limit are dropped, and
+// a known announcement is only replaced by a newer version from the same
+// author. Hops are not signed: an announcement is only taken once, even
+// dropped or purged, so that a relay resetting its hops cannot bring it back,
+// and keeps the hops it first came with. It returns how many new running
+// announcements were received.
+func (s *GossipService) ApplyAnnouncements(ctx context.Context, peerID string, announcements []models.Announcement) (int, error) {
+	received := 0
+	now := time.Now()
+
+	err := s.database.WithTx(ctx, func(q *db.Queries) error {
+		for _, remote := range announcements {
+			remote.Hops++
+			remote.ReceivedFrom = peerID
+			if err := verifyAnnouncement(remote, now); err != nil {
+				continue
+			}
+
+			seen, err := q.IsAnnouncementSeen(ctx, remote.UID)
+			if err != nil {
+				return fmt.Errorf("failed to check announcement: %w", err)
+			}
+			if err := q.MarkAnnouncementSeen(ctx, db.MarkAnnouncementSeenParams{
+				UID:       remote.UID,
+				ExpiresAt: remote.ExpiresAt,
+			}); err != nil {
+				return fmt.Errorf("failed to mark announcement: %w", err)
+			}
+
+			local, err := q.GetAnnouncementByUID(ctx, remote.UID)
+			if errors.Is(err, sql.ErrNoRows) {
+				if seen > 0 || remote.Hops > remote.MaxHops {
+					continue
+				}
+				if _, err := q.CreateAnnouncement(ctx, db.CreateAnnouncementParams{
+					UID:          remote.UID,
+					Kind:         string(remote.Kind),
+					Title:        remote.Title,
+					Body:         remote.Body,
+					AuthorName:   remote.AuthorName,
+					AuthorKey:    remote.AuthorKey,
+					Signature:    remote.Signature,
+					MaxHops:      remote.MaxHops,
+					Hops:         remote.Hops,
+					Withdrawn:    remote.Withdrawn,
+					ReceivedFrom: remote.ReceivedFrom,
+					CreatedAt:    remote.CreatedAt,
+					UpdatedAt:    remote.UpdatedAt,
+					ExpiresAt:    remote.ExpiresAt,
+				}); err != nil {
+					return fmt.Errorf("failed to create announcement: %w", err)
+				}
+				if !remote.Withdrawn {
+					received++
+				}
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to get announcement: %w", err)
+			}
+
+			// Never let another key take over an announcement
+			if local.AuthorKey != remote.AuthorKey || !remote.UpdatedAt.After(local.UpdatedAt) {
+				continue
+			}
+			remote.Hops = local.Hops
+			remote.ReceivedFrom = local.ReceivedFrom
+			if err := storeAnnouncement(ctx, q, local.ID, remote); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return received, nil
+}
+
+// SyncAnnouncementsWithPeer fetches the announcements spreading through a
+// peer. It returns how many new running announcements were received.
+func (s *GossipService) SyncAnnouncementsWithPeer(ctx context.Context, peerID string) (int, error) {
+	dbPeer, err := s.queries.GetPeer(ctx, peerID)
+	if err != nil {
+		return 0, fmt.Errorf("peer not found: %w", err)
+	}
+
+	var announcements []models.Announcement
+	if err := s.getPeerJSON(ctx, dbPeer.Address, "/api/v1/gossip/announcements", &announcements); err != nil {
+		return 0, fmt.Errorf("failed to get announcements from peer: %w", err)
+	}
+
+	return s.ApplyAnnouncements(ctx, peerID, announcements)
+}
+
+// activeAnnouncements purges the expired announcements and returns the others
+func (s *GossipService) activeAnnouncements(ctx context.Context) ([]models.Announcement, error) {
+	dbAnnouncements, err := s.queries.GetAllAnnouncements(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get announcements: %w", err)
+	}
+
+	ownKey, err := s.instancePublicKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.queries.DeleteExpiredSeenAnnouncements(ctx, now); err != nil {
+		return nil, fmt.Errorf("failed to purge seen announcements: %w", err)
+	}
+
+	announcements := []models.Announcement{}
+	for _, dbAnnouncement := range dbAnnouncements {
+		if !dbAnnouncement.ExpiresAt.After(now) {
+			if err := s.queries.DeleteAnnouncement(ctx, dbAnnouncement.ID); err != nil {
+				return nil, fmt.Errorf("failed to purge announcement: %w", err)
+			}
+			continue
+		}
+		announcements = append(announcements, dbAnnouncementToModel(dbAnnouncement, ownKey))
+	}
+
+	return announcements, nil
+}
+
+// instanceKey returns the signing key of this instance, created on first use
+func (s *GossipService) instanceKey(ctx context.Context) (ed25519.PrivateKey, error) {
+	key, err := s.queries.GetInstanceKey(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		if err := s.createInstanceKey(ctx); err != nil {
+			return nil, err
+		}
+		key, err = s.queries.GetInstanceKey(ctx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get instance key: %w", err)
+	}
+
+	// Sealed like the item secrets when encryption is enabled
+	privateKey, err := s.keyring.openField(string(key.PrivateKey), instanceKeyAD)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open instance key: %w", err)
+	}
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, errors.New("invalid instance key")
+	}
+
+	return ed25519.PrivateKey(privateKey), nil
+}
+
+// createInstanceKey generates the signing key of this instance. Another
+// process may have created one in the meantime, the first one wins.
+func (s *GossipService) createInstanceKey(ctx context.Context) error {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return fmt.Errorf("failed to generate instance key: %w", err)
+	}
+
+	sealed, err := s.keyring.sealField(ctx, s.queries, string(privateKey), instanceKeyAD)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt instance key: %w", err)
+	}
+
+	if err := s.queries.CreateInstanceKey(ctx, db.CreateInstanceKeyParams{
+		PublicKey:  publicKey,
+		PrivateKey: []byte(sealed),
+		CreatedAt:  time.Now(),
+	}); err != nil {
+		return fmt.Errorf("failed to store instance key: %w", err)
+	}
+
+	return nil
+}
+
+// instancePublicKey returns the public key of this instance, base64 encoded,
+// readable while the keys are locked. It is empty until the signing key is
+// created by the first announcement.
+func (s *GossipService) instancePublicKey(ctx context.Context) (string, error) {
+	key, err := s.queries.GetInstanceKey(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get instance key: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(key.PublicKey), nil
+}
+
+// storeAnnouncement replaces a stored announcement by a newer version
+func storeAnnouncement(ctx context.Context, q *db.Queries, id int64, announcement models.Announcement) error {
+	if err := q.UpdateAnnouncement(ctx, db.UpdateAnnouncementParams{
+		Kind:         string(announcement.Kind),
+		Title:        announcement.Title,
+		Body:         announcement.Body,
+		AuthorName:   announcement.AuthorName,
+		Signature:    announcement.Signature,
+		MaxHops:      announcement.MaxHops,
+		Hops:         announcement.Hops,
+		Withdrawn:    announcement.Withdrawn,
+		ReceivedFrom: announcement.ReceivedFrom,
+		UpdatedAt:    announcement.UpdatedAt,
+		ExpiresAt:    announcement.ExpiresAt,
+		ID:           id,
+	}); err != nil {
+		return fmt.Errorf("failed to update announcement: %w", err)
+	}
+	return nil
+}
+
+// announcementPayload is the part of an announcement covered by the
+// signature of its author. Hops and local fields change on the way.
+type announcementPayload struct {
+	UID        string `json:"uid"`
+	Kind       string `json:"kind"`
+	Title      string `json:"title"`
+	Body       string `json:"body"`
+	AuthorName string `json:"author_name"`
+	AuthorKey  string `json:"author_key"`
+	MaxHops    int64  `json:"max_hops"`
+	Withdrawn  bool   `json:"withdrawn"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+	ExpiresAt  string `json:"expires_at"`
+}
+
+// signedAnnouncementBytes returns the bytes signed by the author
+func signedAnnouncementBytes(announcement models.Announcement) ([]byte, error) {
+	payload, err := json.Marshal(announcementPayload{
+		UID:        announcement.UID,
+		Kind:       string(announcement.Kind),
+		Title:      announcement.Title,
+		Body:       announcement.Body,
+		AuthorName: announcement.AuthorName,
+		AuthorKey:  announcement.AuthorKey,
+		MaxHops:    announcement.MaxHops,
+		Withdrawn:  announcement.Withdrawn,
+		CreatedAt:  announcement.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:  announcement.UpdatedAt.UTC().Format(time.RFC3339),
+		ExpiresAt:  announcement.ExpiresAt.UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode announcement: %w", err)
+	}
+	return payload, nil
+}
+
+// signAnnouncement sets the signature of an announcement of this instance
+func signAnnouncement(announcement *models.Announcement, privateKey ed25519.PrivateKey) error {
+	payload, err := signedAnnouncementBytes(*announcement)
+	if err != nil {
+		return err
+	}
+	announcement.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, payload))
+	return nil
+}
+
+// verifyAnnouncement checks that an announcement received from a peer, its
+// hops already counting that peer, is signed by its author and not expired.
+// Whether it went through too many peers is left to the caller, for the
+// announcement to be remembered as seen.
+func verifyAnnouncement(announcement models.Announcement, now time.Time) error {
+	if announcement.UID == "" || strings.TrimSpace(announcement.Title) == "" {
+		return errors.New("incomplete announcement")
+	}
+	if announcement.Kind != models.AnnouncementOffer && announcement.Kind != models.AnnouncementRequest {
+		return fmt.Errorf("invalid announcement kind %q", announcement.Kind)
+	}
+	if !announcement.ExpiresAt.After(now) || announcement.ExpiresAt.Sub(announcement.CreatedAt) > MaxAnnouncementTTL {
+		return errors.New("announcement expired")
+	}
+	// Hops isn't signed: it must at least count the peer it came from
+	if announcement.Hops < 1 || announcement.MaxHops > MaxAnnouncementHops {
+		return errors.New("announcement went through too many peers")
+	}
+
+	publicKey, err := base64.StdEncoding.DecodeString(announcement.AuthorKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return errors.New("invalid author key")
+	}
+	signature, err := base64.StdEncoding.DecodeString(announcement.Signature)
+	if err != nil {
+		return errors.New("invalid signature")
+	}
+
+	payload, err := signedAnnouncementBytes(announcement)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(ed25519.PublicKey(publicKey), payload, signature) {
+		return errors.New("invalid signature")
+	}
+
+	return nil
+}
+
+// dbAnnouncementToModel converts a DB announcement to a model announcement.
+// ownKey is the public key of this instance.
+func dbAnnouncementToModel(dbAnnouncement db.Announcement, ownKey string) models.Announcement {
+	return models.Announcement{
+		ID:           dbAnnouncement.ID,
+		UID:          dbAnnouncement.UID,
+		Kind:         models.AnnouncementKind(dbAnnouncement.Kind),
+		Title:        dbAnnouncement.Title,
+		Body:         dbAnnouncement.Body,
+		AuthorName:   dbAnnouncement.AuthorName,
+		AuthorKey:    dbAnnouncement.AuthorKey,
+		Signature:    dbAnnouncement.Signature,
+		MaxHops:      dbAnnouncement.MaxHops,
+		Hops:         dbAnnouncement.Hops,
+		Withdrawn:    dbAnnouncement.Withdrawn,
+		ReceivedFrom: dbAnnouncement.ReceivedFrom,
+		Own:          dbAnnouncement.AuthorKey == ownKey,
+		CreatedAt:    dbAnnouncement.CreatedAt,
+		UpdatedAt:    dbAnnouncement.UpdatedAt,
+		ExpiresAt:    dbAnnouncement.ExpiresAt,
+	}
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lhommenul/brique/core/db"
+	"github.com/lhommenul/brique/core/models"
+)
+
+// assetTypeNamePattern restricts asset type names to stable identifiers
+var assetTypeNamePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// GetAssetTypes returns every registered asset type
+func (s *BackpackService) GetAssetTypes(ctx context.Context) ([]models.AssetTypeDefinition, error) {
+	dbTypes, err := s.queries.GetAllAssetTypes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get asset types: %w", err)
+	}
+
+	types := make([]models.AssetTypeDefinition, len(dbTypes))
+	for i, t := range dbTypes {
+		types[i] = dbAssetTypeToModel(t)
+	}
+
+	return types, nil
+}
+
+// GetAssetType returns a registered asset type by name
+func (s *BackpackService) GetAssetType(ctx context.Context, name models.AssetType) (*models.AssetTypeDefinition, error) {
+	dbType, err := s.queries.GetAssetType(ctx, string(name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("unknown asset type: %s", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get asset type: %w", err)
+	}
+
+	def := dbAssetTypeToModel(dbType)
+	return &def, nil
+}
+
+// SaveAssetType creates or updates a user-defined asset type. Built-in types
+// may be relabelled but keep their built-in flag.
+func (s *BackpackService) SaveAssetType(ctx context.Context, def *models.AssetTypeDefinition) error {
+	if !assetTypeNamePattern.MatchString(string(def.Name)) {
+		return fmt.Errorf("invalid asset type name %q: use lowercase letters, digits and underscores", def.Name)
+	}
+
+	if strings.TrimSpace(def.Label) == "" {
+		return fmt.Errorf("asset type label is required")
+	}
+
+	existing, err := s.queries.GetAssetType(ctx, string(def.Name))
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to get asset type: %w", err)
+	}
+
+	saved, err := s.queries.UpsertAssetType(ctx, db.UpsertAssetTypeParams{
+		Name:            string(def.Name),
+		Label:           strings.TrimSpace(def.Label),
+		MimePatterns:    joinMimePatterns(def.MimePatterns),
+		CountsForHealth: def.CountsForHealth,
+		IsBuiltin:       existing.IsBuiltin,
+		UpdatedAt:       time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save asset type: %w", err)
+	}
+
+	*def = dbAssetTypeToModel(saved)
+	return nil
+}
+
+// DeleteAssetType removes a user-defined asset type that no asset uses
+func (s *BackpackService) DeleteAssetType(ctx context.Context, name models.AssetType) error {
+	def, err := s.GetAssetType(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if def.IsBuiltin {
+		return fmt.Errorf("cannot delete built-in asset type: %s", name)
+	}
+
+	count, err := s.queries.CountAssetsByType(ctx, string(name))
+	if err != nil {
+		return fmt.Errorf("failed to count assets: %w", err)
+	}
+
+	if count > 0 {
+		return fmt.Errorf("asset type %s is used by %d asset(s)", name, count)
+	}
+
+	if err := s.queries.DeleteAssetType(ctx, string(name)); err != nil {
+		return fmt.Errorf("failed to delete asset type: %w", err)
+	}
+
+	return nil
+}
+
+// validateAsset checks that the asset type exists and that the file matches
+// its MIME patterns
+func (s *BackpackService) validateAsset(ctx context.Context, assetType models.AssetType, sourcePath string) error {
+	def, err := s.GetAssetType(ctx, assetType)
+	if err != nil {
+		return err
+	}
+
+	if len(def.MimePatterns) == 0 {
+		return nil
+	}
+
+	mimeTypes, err := detectMimeTypes(sourcePath)
+	if err != nil {
+		return err
+	}
+
+	for _, pattern := range def.MimePatterns {
+		for _, mimeType := range mimeTypes {
+			if matchMimePattern(pattern, mimeType) {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("file type %s is not accepted for asset type %s (expected %s)",
+		strings.Join(mimeTypes, ", "), assetType, strings.Join(def.MimePatterns, ", "))
+}
+
+// countsForHealth returns the assets whose type counts towards documentation health
+func (s *BackpackService) countsForHealth(ctx context.Context, assets []models.Asset) ([]models.Asset, error) {
+	dbTypes, err := s.queries.GetAllAssetTypes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get asset types: %w", err)
+	}
+
+	ignored := make(map[models.AssetType]bool)
+	for _, t := range dbTypes {
+		if !t.CountsForHealth {
+			ignored[models.AssetType(t.Name)] = true
+		}
+	}
+
+	counted := make([]models.Asset, 0, len(assets))
+	for _, asset := range assets {
+		if !ignored[asset.Type] {
+			counted = append(counted, asset)
+		}
+	}
+
+	return counted, nil
+}
+
+// detectMimeTypes returns the MIME types suggested by a file's extension and content
+func detectMimeTypes(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open source file: %w", err)
+	}
+	defer file.Close()
+
+	header := make([]byte, 512)
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, fmt.Errorf("failed to read source file: %w", err)
+	}
+
+	var mimeTypes []string
+	if byExt := mime.TypeByExtension(filepath.Ext(path)); byExt != "" {
+		mimeTypes = append(mimeTypes, stripMimeParams(byExt))
+	}
+	mimeTypes = append(mimeTypes, stripMimeParams(http.DetectContentType(header[:n])))
+
+	return mimeTypes, nil
+}
+
+// matchMimePattern matches a MIME type against a pattern such as "image/*"
+func matchMimePattern(pattern, mimeType string) bool {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	if pattern == "*" || pattern == "*/*" {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+		return strings.HasPrefix(mimeType, prefix+"/")
+	}
+	return pattern == mimeType
+}
+
+func stripMimeParams(mimeType string) string {
+	if i := strings.Index(mimeType, ";"); i >= 0 {
+		mimeType = mimeType[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(mimeType))
+}
+
+func joinMimePatterns(patterns []string) string {
+	cleaned := make([]string, 0, len(patterns))
+	for _, p := range patterns {
+		if p = strings.TrimSpace(p); p != "" {
+			cleaned = append(cleaned, p)
+		}
+	}
+	return strings.Join(cleaned, ",")
+}
+
+func splitMimePatterns(patterns string) []string {
+	if patterns == "" {
+		return []string{}
+	}
+	return strings.Split(patterns, ",")
+}
+
+// dbAssetTypeToModel converts a DB asset type to a model definition
+func dbAssetTypeToModel(t db.AssetType) models.AssetTypeDefinition {
+	return models.AssetTypeDefinition{
+		Name:            models.AssetType(t.Name),
+		Label:           t.Label,
+		MimePatterns:    splitMimePatterns(t.MimePatterns),
+		CountsForHealth: t.CountsForHealth,
+		IsBuiltin:       t.IsBuiltin,
+		UpdatedAt:       t.UpdatedAt,
+	}
+}
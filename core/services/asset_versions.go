@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lhommenul/brique/core/db"
+	"github.com/lhommenul/brique/core/models"
+)
+
+// assetRevision carries the version metadata of an asset being stored
+type assetRevision struct {
+	versionLabel string
+	releaseDate  *time.Time
+	supersedesID *int64
+}
+
+// AddAssetRevision stores a new revision of an asset. The new file keeps the
+// type of the previous revision, supersedes it and becomes the current one.
+func (s *BackpackService) AddAssetRevision(ctx context.Context, previousID int64, name string, sourcePath string, versionLabel string, releaseDate *time.Time) (*models.Asset, error) {
+	var asset *models.Asset
+	err := s.inTx(ctx, func(tx *BackpackService) error {
+		previous, err := tx.queries.GetAssetByID(ctx, previousID)
+		if err != nil {
+			return fmt.Errorf("failed to get asset: %w", err)
+		}
+
+		if previous.SupersededByID.Valid {
+			return fmt.Errorf("asset %d is already superseded by asset %d", previousID, previous.SupersededByID.Int64)
+		}
+
+		if name == "" {
+			name = previous.Name
+		}
+
+		history, err := tx.GetAssetHistory(ctx, previousID)
+		if err != nil {
+			return err
+		}
+
+		asset, err = tx.createAsset(ctx, ownerOf(previous), models.AssetType(previous.Type), name, sourcePath, assetRevision{
+			versionLabel: versionLabel,
+			releaseDate:  releaseDate,
+			supersedesID: &previous.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := tx.queries.SetAssetSupersededBy(ctx, db.SetAssetSupersededByParams{
+			SupersededByID: sql.NullInt64{Int64: asset.ID, Valid: true},
+			ID:             previous.ID,
+		}); err != nil {
+			return fmt.Errorf("failed to link asset revision: %w", err)
+		}
+
+		for _, revision := range history {
+			if err := tx.setAssetCurrent(ctx, revision.ID, false); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return asset, nil
+}
+
+// GetAssetHistory returns every revision in the chain of an asset, newest first
+func (s *BackpackService) GetAssetHistory(ctx context.Context, assetID int64) ([]models.Asset, error) {
+	dbAsset, err := s.queries.GetAssetByID(ctx, assetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get asset: %w", err)
+	}
+	asset := s.dbAssetToModel(dbAsset)
+
+	// Revisions always share the owner of the asset
+	assets, err := s.ownerAssets(ctx, ownerOf(dbAsset))
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int64]models.Asset, len(assets))
+	for _, a := range assets {
+		byID[a.ID] = a
+	}
+
+	// Walk forward to the newest revision, then back to the oldest
+	head := *asset
+	for head.SupersededByID != nil {
+		next, ok := byID[*head.SupersededByID]
+		if !ok {
+			break
+		}
+		head = next
+	}
+
+	history := []models.Asset{head}
+	for current := head; current.SupersedesID != nil; {
+		previous, ok := byID[*current.SupersedesID]
+		if !ok {
+			break
+		}
+		history = append(history, previous)
+		current = previous
+	}
+
+	return history, nil
+}
+
+// SetCurrentRevision marks an asset as the current revision of its chain,
+// e.g. to roll back to an older firmware
+func (s *BackpackService) SetCurrentRevision(ctx context.Context, assetID int64) error {
+	return s.inTx(ctx, func(tx *BackpackService) error {
+		history, err := tx.GetAssetHistory(ctx, assetID)
+		if err != nil {
+			return err
+		}
+
+		for _, revision := range history {
+			if err := tx.setAssetCurrent(ctx, revision.ID, revision.ID == assetID); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// SetAssetVersion updates the version label and release date of an asset
+func (s *BackpackService) SetAssetVersion(ctx context.Context, assetID int64, versionLabel string, releaseDate *time.Time) error {
+	params := db.UpdateAssetVersionParams{
+		VersionLabel: versionLabel,
+		ID:           assetID,
+	}
+
+	if releaseDate != nil {
+		params.ReleaseDate.Time = *releaseDate
+		params.ReleaseDate.Valid = true
+	}
+
+	if err := s.queries.UpdateAssetVersion(ctx, params); err != nil {
+		return fmt.Errorf("failed to update asset version: %w", err)
+	}
+
+	return nil
+}
+
+// unlinkRevision removes an asset from its chain before deletion, joining its
+// neighbours and promoting the newest remaining revision if it was current.
+// Must be called within a transaction.
+func (s *BackpackService) unlinkRevision(ctx context.Context, asset db.Asset) error {
+	if !asset.SupersedesID.Valid && !asset.SupersededByID.Valid {
+		return nil
+	}
+
+	if asset.SupersedesID.Valid {
+		if err := s.queries.SetAssetSupersededBy(ctx, db.SetAssetSupersededByParams{
+			SupersededByID: asset.SupersededByID,
+			ID:             asset.SupersedesID.Int64,
+		}); err != nil {
+			return fmt.Errorf("failed to unlink asset revision: %w", err)
+		}
+	}
+
+	if asset.SupersededByID.Valid {
+		if err := s.queries.SetAssetSupersedes(ctx, db.SetAssetSupersedesParams{
+			SupersedesID: asset.SupersedesID,
+			ID:           asset.SupersededByID.Int64,
+		}); err != nil {
+			return fmt.Errorf("failed to unlink asset revision: %w", err)
+		}
+	}
+
+	if !asset.IsCurrent {
+		return nil
+	}
+
+	remaining := asset.SupersededByID
+	if !remaining.Valid {
+		remaining = asset.SupersedesID
+	}
+
+	history, err := s.GetAssetHistory(ctx, remaining.Int64)
+	if err != nil {
+		return err
+	}
+
+	return s.setAssetCurrent(ctx, history[0].ID, true)
+}
+
+// setAssetCurrent flags or unflags an asset as the current revision
+func (s *BackpackService) setAssetCurrent(ctx context.Context, assetID int64, isCurrent bool) error {
+	if err := s.queries.SetAssetCurrent(ctx, db.SetAssetCurrentParams{
+		IsCurrent: isCurrent,
+		ID:        assetID,
+	}); err != nil {
+		return fmt.Errorf("failed to update current revision: %w", err)
+	}
+	return nil
+}
+
+// currentRevisions keeps only the assets that are the current revision of their chain
+func currentRevisions(assets []models.Asset) []models.Asset {
+	current := make([]models.Asset, 0, len(assets))
+	for _, asset := range assets {
+		if asset.IsCurrent {
+			current = append(current, asset)
+		}
+	}
+	return current
+}
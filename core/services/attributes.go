@@ -0,0 +1,316 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lhommenul/brique/core/db"
+	"github.com/lhommenul/brique/core/models"
+)
+
+// attributeKeyPattern restricts attribute keys to stable identifiers
+var attributeKeyPattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// attributeFilterOperators are tried in order, so that two-character
+// operators are matched before their one-character prefix
+var attributeFilterOperators = []string{"!=", "<=", ">=", "=", "<", ">"}
+
+// DefaultAttributeTemplates returns the built-in attribute templates
+func DefaultAttributeTemplates() []models.AttributeTemplate {
+	return []models.AttributeTemplate{
+		{
+			Category: "Électroménager",
+			Attributes: []models.AttributeDefinition{
+				{Key: "voltage", Label: "Tension (V)", Type: models.AttributeTypeNumber},
+				{Key: "power", Label: "Puissance (W)", Type: models.AttributeTypeNumber},
+				{Key: "filter_reference", Label: "Référence du filtre", Type: models.AttributeTypeText},
+			},
+		},
+		{
+			Category: "Imprimante 3D",
+			Attributes: []models.AttributeDefinition{
+				{Key: "nozzle_diameter", Label: "Diamètre de buse (mm)", Type: models.AttributeTypeNumber},
+				{Key: "filament_diameter", Label: "Diamètre du filament (mm)", Type: models.AttributeTypeNumber},
+				{Key: "belt_size", Label: "Taille de courroie", Type: models.AttributeTypeText},
+			},
+		},
+	}
+}
+
+// AttributeTemplates holds the attributes suggested for each item category
+type AttributeTemplates struct {
+	templates map[string]models.AttributeTemplate
+}
+
+// NewAttributeTemplates creates a template set from the built-in templates,
+// overridden by the given templates for matching categories
+func NewAttributeTemplates(overrides []models.AttributeTemplate) *AttributeTemplates {
+	t := &AttributeTemplates{templates: make(map[string]models.AttributeTemplate)}
+
+	for _, template := range DefaultAttributeTemplates() {
+		t.templates[normalizeCategory(template.Category)] = template
+	}
+
+	for _, template := range overrides {
+		t.templates[normalizeCategory(template.Category)] = template
+	}
+
+	return t
+}
+
+// Templates returns every configured template
+func (t *AttributeTemplates) Templates() []models.AttributeTemplate {
+	templates := make([]models.AttributeTemplate, 0, len(t.templates))
+	for _, template := range t.templates {
+		templates = append(templates, template)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Category < templates[j].Category
+	})
+	return templates
+}
+
+// TemplateFor returns the template of a category, empty when it has none
+func (t *AttributeTemplates) TemplateFor(category string) models.AttributeTemplate {
+	if template, ok := t.templates[normalizeCategory(category)]; ok {
+		return template
+	}
+	return models.AttributeTemplate{Category: category, Attributes: []models.AttributeDefinition{}}
+}
+
+// definition returns the template definition of an attribute key, if any
+func (t *AttributeTemplates) definition(category, key string) (models.AttributeDefinition, bool) {
+	for _, def := range t.TemplateFor(category).Attributes {
+		if def.Key == key {
+			return def, true
+		}
+	}
+	return models.AttributeDefinition{}, false
+}
+
+// SetAttributeTemplates replaces the attribute templates, on top of the built-in ones
+func (s *BackpackService) SetAttributeTemplates(templates []models.AttributeTemplate) {
+	s.attributeTemplates = NewAttributeTemplates(templates)
+}
+
+// GetAttributeTemplates returns the attribute templates in use
+func (s *BackpackService) GetAttributeTemplates() []models.AttributeTemplate {
+	return s.attributeTemplates.Templates()
+}
+
+// GetAttributeTemplate returns the attributes suggested for a category
+func (s *BackpackService) GetAttributeTemplate(category string) models.AttributeTemplate {
+	return s.attributeTemplates.TemplateFor(category)
+}
+
+// ParseAttributeFilter parses a filter expression such as "voltage>=220"
+func ParseAttributeFilter(expr string) (models.AttributeFilter, error) {
+	for i := 0; i < len(expr); i++ {
+		for _, op := range attributeFilterOperators {
+			if strings.HasPrefix(expr[i:], op) {
+				key := strings.TrimSpace(expr[:i])
+				if key == "" {
+					return models.AttributeFilter{}, fmt.Errorf("invalid attribute filter %q: missing key", expr)
+				}
+				return models.AttributeFilter{
+					Key:      key,
+					Operator: op,
+					Value:    strings.TrimSpace(expr[i+len(op):]),
+				}, nil
+			}
+		}
+	}
+
+	return models.AttributeFilter{}, fmt.Errorf("invalid attribute filter %q: expected key=value", expr)
+}
+
+// normalizeAttributes validates the attributes of an item and converts their
+// values to a canonical form. The type defaults to the one declared by the
+// category template, then to text. Attributes with an empty value are dropped.
+func (s *BackpackService) normalizeAttributes(category string, attributes []models.Attribute) ([]models.Attribute, error) {
+	normalized := make([]models.Attribute, 0, len(attributes))
+	seen := make(map[string]bool, len(attributes))
+
+	for _, attr := range attributes {
+		key := strings.TrimSpace(attr.Key)
+		if !attributeKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("invalid attribute key %q: use lowercase letters, digits and underscores", attr.Key)
+		}
+
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate attribute: %s", key)
+		}
+		seen[key] = true
+
+		attrType := attr.Type
+		if attrType == "" {
+			attrType = models.AttributeTypeText
+			if def, ok := s.attributeTemplates.definition(category, key); ok && def.Type != "" {
+				attrType = def.Type
+			}
+		}
+
+		if strings.TrimSpace(attr.Value) == "" {
+			continue
+		}
+
+		value, err := normalizeAttributeValue(attrType, attr.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for attribute %s: %w", key, err)
+		}
+
+		normalized = append(normalized, models.Attribute{Key: key, Type: attrType, Value: value})
+	}
+
+	sort.Slice(normalized, func(i, j int) bool {
+		return normalized[i].Key < normalized[j].Key
+	})
+
+	return normalized, nil
+}
+
+// normalizeAttributeValue checks that a value matches its type and returns its
+// canonical form
+func normalizeAttributeValue(attrType models.AttributeType, value string) (string, error) {
+	value = strings.TrimSpace(value)
+
+	switch attrType {
+	case models.AttributeTypeText:
+		return value, nil
+
+	case models.AttributeTypeNumber:
+		n, err := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+		if err != nil {
+			return "", fmt.Errorf("%q is not a number", value)
+		}
+		return strconv.FormatFloat(n, 'f', -1, 64), nil
+
+	case models.AttributeTypeBoolean:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("%q is not a boolean", value)
+		}
+		return strconv.FormatBool(b), nil
+
+	case models.AttributeTypeDate:
+		d, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return "", fmt.Errorf("%q is not a date (expected YYYY-MM-DD)", value)
+		}
+		return d.Format("2006-01-02"), nil
+
+	default:
+		return "", fmt.Errorf("unknown attribute type: %s", attrType)
+	}
+}
+
+// matchAttributeFilters reports whether the attributes satisfy every filter
+func matchAttributeFilters(attributes []models.Attribute, filters []models.AttributeFilter) bool {
+	for _, filter := range filters {
+		matched := false
+		for _, attr := range attributes {
+			if attr.Key == filter.Key && matchAttributeFilter(attr, filter) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// matchAttributeFilter compares an attribute with a filter according to the
+// attribute's type: numerically for numbers, case-insensitively for text
+func matchAttributeFilter(attr models.Attribute, filter models.AttributeFilter) bool {
+	value, err := normalizeAttributeValue(attr.Type, filter.Value)
+	if err != nil {
+		return false
+	}
+
+	var cmp int
+	switch attr.Type {
+	case models.AttributeTypeNumber:
+		a, _ := strconv.ParseFloat(attr.Value, 64)
+		b, _ := strconv.ParseFloat(value, 64)
+		switch {
+		case a < b:
+			cmp = -1
+		case a > b:
+			cmp = 1
+		}
+	case models.AttributeTypeText:
+		cmp = strings.Compare(strings.ToLower(attr.Value), strings.ToLower(value))
+	default:
+		// Canonical booleans and YYYY-MM-DD dates compare as strings
+		cmp = strings.Compare(attr.Value, value)
+	}
+
+	switch filter.Operator {
+	case "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	default:
+		return false
+	}
+}
+
+// loadItemAttributes returns the attributes of every item, by item ID
+func loadItemAttributes(ctx context.Context, q *db.Queries) (map[int64][]models.Attribute, error) {
+	dbAttributes, err := q.GetAllItemAttributes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get item attributes: %w", err)
+	}
+
+	byItem := make(map[int64][]models.Attribute)
+	for _, a := range dbAttributes {
+		byItem[a.ItemID] = append(byItem[a.ItemID], dbAttributeToModel(a))
+	}
+
+	return byItem, nil
+}
+
+// replaceItemAttributes stores the attributes of an item, replacing the
+// previous ones. Must be called within a transaction.
+func replaceItemAttributes(ctx context.Context, q *db.Queries, itemID int64, attributes []models.Attribute) error {
+	if err := q.DeleteItemAttributes(ctx, itemID); err != nil {
+		return fmt.Errorf("failed to delete item attributes: %w", err)
+	}
+
+	for _, attr := range attributes {
+		if err := q.CreateItemAttribute(ctx, db.CreateItemAttributeParams{
+			ItemID: itemID,
+			Key:    attr.Key,
+			Type:   string(attr.Type),
+			Value:  attr.Value,
+		}); err != nil {
+			return fmt.Errorf("failed to save attribute %s: %w", attr.Key, err)
+		}
+	}
+
+	return nil
+}
+
+// dbAttributeToModel converts a DB item attribute to a model
+func dbAttributeToModel(a db.ItemAttribute) models.Attribute {
+	return models.Attribute{
+		Key:   a.Key,
+		Type:  models.AttributeType(a.Type),
+		Value: a.Value,
+	}
+}
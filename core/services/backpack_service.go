@@ -3,10 +3,15 @@ package services
 import (
 	"context"
 	"crypto/sha256"
+	"database/sql"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/lhommenul/brique/core/db"
@@ -15,19 +20,48 @@ import (
 
 // BackpackService manages the inventory (Sac à Dos)
 type BackpackService struct {
-	queries   *db.Queries
-	assetsDir string
+	database           *db.Database
+	queries            *db.Queries
+	assetsDir          string
+	healthRules        *HealthRules
+	attributeTemplates *AttributeTemplates
+	keyring            *Keyring
+	logger             *slog.Logger
+	staged             *[]string // Files staged by the current transaction, nil outside one
 }
 
 // NewBackpackService creates a new backpack service
-func NewBackpackService(queries *db.Queries, assetsDir string) *BackpackService {
+func NewBackpackService(database *db.Database, assetsDir string) *BackpackService {
 	return &BackpackService{
-		queries:   queries,
-		assetsDir: assetsDir,
+		database:           database,
+		queries:            db.New(database.DB),
+		assetsDir:          assetsDir,
+		healthRules:        NewHealthRules(nil),
+		attributeTemplates: NewAttributeTemplates(nil),
+		keyring:            NewKeyring(),
+		logger:             slog.Default(),
 	}
 }
 
-// CreateItem creates a new item in the inventory
+// SetLogger sets where the failures not returned, e.g. of file cleanups, are
+// reported. It defaults to slog's default logger, on stderr.
+func (s *BackpackService) SetLogger(logger *slog.Logger) {
+	s.logger = logger
+}
+
+// SetHealthRules replaces the documentation rules, on top of the built-in ones
+func (s *BackpackService) SetHealthRules(rules []models.HealthRule) {
+	s.healthRules = NewHealthRules(rules)
+}
+
+// GetHealthRules returns the documentation rules in use
+func (s *BackpackService) GetHealthRules() []models.HealthRule {
+	return s.healthRules.Rules()
+}
+
+// CreateItem creates a new item in the inventory, with its attributes and
+// tags. The category is resolved to an existing one, or created, and the item
+// is linked to the product model of its brand and model.
 func (s *BackpackService) CreateItem(ctx context.Context, item *models.Item) error {
 	now := time.Now()
 
@@ -48,18 +82,104 @@ func (s *BackpackService) CreateItem(ctx context.Context, item *models.Item) err
 		params.PurchaseDate.Valid = true
 	}
 
-	created, err := s.queries.CreateItem(ctx, params)
+	var attributes []models.Attribute
+	err := s.inTx(ctx, func(tx *BackpackService) error {
+		var err error
+		params.SerialNumber, params.Notes, err = tx.keyring.sealItemSecrets(ctx, tx.queries, item.SerialNumber, item.Notes)
+		if err != nil {
+			return err
+		}
+
+		params.CategoryID, params.Category, err = resolveCategory(ctx, tx.queries, item.Category)
+		if err != nil {
+			return err
+		}
+
+		attributes, err = tx.normalizeAttributes(params.Category, item.Attributes)
+		if err != nil {
+			return err
+		}
+
+		params.ProductModelID, err = resolveProductModel(ctx, tx.queries, item.Brand, item.Model)
+		if err != nil {
+			return err
+		}
+
+		created, err := tx.queries.CreateItem(ctx, params)
+		if err != nil {
+			return fmt.Errorf("failed to create item: %w", err)
+		}
+
+		item.ID = created.ID
+		item.CreatedAt = created.CreatedAt
+		item.UpdatedAt = created.UpdatedAt
+
+		if err := replaceItemAttributes(ctx, tx.queries, item.ID, attributes); err != nil {
+			return err
+		}
+		if err := replaceItemTags(ctx, tx.queries, item.ID, item.Tags); err != nil {
+			return err
+		}
+
+		if item.Warranty != nil {
+			return replaceItemWarranty(ctx, tx.queries, item.ID, item.Warranty)
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to create item: %w", err)
+		return err
+	}
+
+	item.Category = params.Category
+	item.ProductModelID = nullInt64Ptr(params.ProductModelID)
+	item.Attributes = attributes
+	item.Tags = normalizeTags(item.Tags)
+	if item.Warranty != nil {
+		if item.Warranty.DurationMonths == 0 {
+			item.Warranty = nil
+		} else {
+			computeWarranty(item.Warranty, item.PurchaseDate, now)
+		}
 	}
 
-	item.ID = created.ID
-	item.CreatedAt = created.CreatedAt
-	item.UpdatedAt = created.UpdatedAt
-
 	return nil
 }
 
+// ImportItems creates the given items in a single transaction, skipping those
+// with the brand, model and serial number of an item already in the
+// inventory. Either every item is imported or none is.
+func (s *BackpackService) ImportItems(ctx context.Context, items []models.Item) (imported int, skipped int, err error) {
+	err = s.inTx(ctx, func(tx *BackpackService) error {
+		identities, err := itemIdentities(ctx, tx.queries, tx.keyring)
+		if err != nil {
+			return err
+		}
+
+		imported, skipped = 0, 0
+		for i := range items {
+			identity := itemIdentity(items[i].Brand, items[i].Model, items[i].SerialNumber)
+			if identity != "" && identities[identity] != 0 {
+				skipped++
+				continue
+			}
+
+			if err := tx.CreateItem(ctx, &items[i]); err != nil {
+				return err
+			}
+			if identity != "" {
+				identities[identity] = items[i].ID
+			}
+			imported++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return imported, skipped, nil
+}
+
 // GetItem retrieves an item by ID
 func (s *BackpackService) GetItem(ctx context.Context, id int64) (*models.Item, error) {
 	dbItem, err := s.queries.GetItemByID(ctx, id)
@@ -67,7 +187,37 @@ func (s *BackpackService) GetItem(ctx context.Context, id int64) (*models.Item,
 		return nil, fmt.Errorf("failed to get item: %w", err)
 	}
 
-	return s.dbItemToModel(dbItem), nil
+	dbAttributes, err := s.queries.GetItemAttributes(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get item attributes: %w", err)
+	}
+
+	tags, err := s.queries.GetItemTags(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get item tags: %w", err)
+	}
+
+	item, err := s.dbItemToModel(dbItem)
+	if err != nil {
+		return nil, err
+	}
+	item.Tags = tags
+	item.Attributes = make([]models.Attribute, len(dbAttributes))
+	for i, a := range dbAttributes {
+		item.Attributes[i] = dbAttributeToModel(a)
+	}
+
+	item.Warranty, err = loadItemWarranty(ctx, s.queries, item)
+	if err != nil {
+		return nil, err
+	}
+
+	item.Loan, err = loadItemLoan(ctx, s.queries, item.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
 }
 
 // GetAllItems retrieves all items
@@ -77,15 +227,20 @@ func (s *BackpackService) GetAllItems(ctx context.Context) ([]models.Item, error
 		return nil, fmt.Errorf("failed to get all items: %w", err)
 	}
 
-	items := make([]models.Item, len(dbItems))
-	for i, dbItem := range dbItems {
-		items[i] = *s.dbItemToModel(dbItem)
+	items, err := s.dbItemsToModels(dbItems)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := loadItemDetails(ctx, s.queries, items); err != nil {
+		return nil, err
 	}
 
 	return items, nil
 }
 
-// UpdateItem updates an existing item
+// UpdateItem updates an existing item. Its attributes and tags are replaced
+// unless item.Attributes or item.Tags is nil.
 func (s *BackpackService) UpdateItem(ctx context.Context, item *models.Item) error {
 	now := time.Now()
 
@@ -106,40 +261,100 @@ func (s *BackpackService) UpdateItem(ctx context.Context, item *models.Item) err
 		params.PurchaseDate.Valid = true
 	}
 
-	if err := s.queries.UpdateItem(ctx, params); err != nil {
-		return fmt.Errorf("failed to update item: %w", err)
+	var attributes []models.Attribute
+	err := s.inTx(ctx, func(tx *BackpackService) error {
+		var err error
+		params.SerialNumber, params.Notes, err = tx.keyring.sealItemSecrets(ctx, tx.queries, item.SerialNumber, item.Notes)
+		if err != nil {
+			return err
+		}
+
+		params.CategoryID, params.Category, err = resolveCategory(ctx, tx.queries, item.Category)
+		if err != nil {
+			return err
+		}
+
+		params.ProductModelID, err = resolveProductModel(ctx, tx.queries, item.Brand, item.Model)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.queries.UpdateItem(ctx, params); err != nil {
+			return fmt.Errorf("failed to update item: %w", err)
+		}
+
+		if item.Attributes != nil {
+			attributes, err = tx.normalizeAttributes(params.Category, item.Attributes)
+			if err != nil {
+				return err
+			}
+			if err := replaceItemAttributes(ctx, tx.queries, item.ID, attributes); err != nil {
+				return err
+			}
+		}
+
+		if item.Tags != nil {
+			if err := replaceItemTags(ctx, tx.queries, item.ID, item.Tags); err != nil {
+				return err
+			}
+		}
+
+		if item.Warranty != nil {
+			return replaceItemWarranty(ctx, tx.queries, item.ID, item.Warranty)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
+	item.Category = params.Category
+	item.ProductModelID = nullInt64Ptr(params.ProductModelID)
 	item.UpdatedAt = now
+	if attributes != nil {
+		item.Attributes = attributes
+	}
+	if item.Tags != nil {
+		item.Tags = normalizeTags(item.Tags)
+	}
+	if item.Warranty != nil {
+		if item.Warranty.DurationMonths == 0 {
+			item.Warranty = nil
+		} else {
+			computeWarranty(item.Warranty, item.PurchaseDate, now)
+		}
+	}
 
 	return nil
 }
 
-// DeleteItem deletes an item and all its assets
+// DeleteItem deletes an item and all its assets. The documentation of its
+// product model is kept for the other items of that model.
 func (s *BackpackService) DeleteItem(ctx context.Context, id int64) error {
-	// Get all assets for this item to delete files
-	assets, err := s.queries.GetAssetsByItemID(ctx, id)
-	if err != nil {
-		return fmt.Errorf("failed to get assets: %w", err)
-	}
+	return s.inTx(ctx, func(tx *BackpackService) error {
+		// Get all assets for this item to delete files
+		assets, err := tx.queries.GetAssetsByItemID(ctx, sql.NullInt64{Int64: id, Valid: true})
+		if err != nil {
+			return fmt.Errorf("failed to get assets: %w", err)
+		}
 
-	// Delete asset files from disk
-	for _, asset := range assets {
-		if err := os.Remove(asset.FilePath); err != nil && !os.IsNotExist(err) {
-			// Log error but continue
-			fmt.Printf("Warning: failed to delete asset file %s: %v\n", asset.FilePath, err)
+		// Asset files are deleted from disk once the transaction commits
+		for _, asset := range assets {
+			if err := tx.queueAssetFilesDelete(ctx, asset); err != nil {
+				return err
+			}
 		}
-	}
 
-	// Delete the item (assets will be deleted by CASCADE)
-	if err := s.queries.DeleteItem(ctx, id); err != nil {
-		return fmt.Errorf("failed to delete item: %w", err)
-	}
+		// Delete the item (assets will be deleted by CASCADE)
+		if err := tx.queries.DeleteItem(ctx, id); err != nil {
+			return fmt.Errorf("failed to delete item: %w", err)
+		}
 
-	return nil
+		return nil
+	})
 }
 
-// SearchItems searches for items by query
+// SearchItems searches for items by name, brand, category or attribute value
 func (s *BackpackService) SearchItems(ctx context.Context, query string) ([]models.Item, error) {
 	searchTerm := "%" + query + "%"
 
@@ -147,106 +362,264 @@ func (s *BackpackService) SearchItems(ctx context.Context, query string) ([]mode
 		Name:     searchTerm,
 		Brand:    searchTerm,
 		Category: searchTerm,
+		Value:    searchTerm,
+		Synonym:  searchTerm,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to search items: %w", err)
 	}
 
-	items := make([]models.Item, len(dbItems))
-	for i, dbItem := range dbItems {
-		items[i] = *s.dbItemToModel(dbItem)
+	items, err := s.dbItemsToModels(dbItems)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := loadItemDetails(ctx, s.queries, items); err != nil {
+		return nil, err
 	}
 
 	return items, nil
 }
 
-// AddAsset adds an asset to an item by copying the file to the assets directory
-func (s *BackpackService) AddAsset(ctx context.Context, itemID int64, assetType models.AssetType, name string, sourcePath string) (*models.Asset, error) {
-	// Verify item exists
-	if _, err := s.queries.GetItemByID(ctx, itemID); err != nil {
-		return nil, fmt.Errorf("item not found: %w", err)
+// FilterItems returns the items matching every criterion of the filter
+func (s *BackpackService) FilterItems(ctx context.Context, filter models.ItemFilter) ([]models.Item, error) {
+	var items []models.Item
+	var err error
+	if strings.TrimSpace(filter.Query) != "" {
+		items, err = s.SearchItems(ctx, strings.TrimSpace(filter.Query))
+	} else {
+		items, err = s.GetAllItems(ctx)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	// Open source file
-	sourceFile, err := os.Open(sourcePath)
+	var subtree map[string]bool
+	if strings.TrimSpace(filter.Category) != "" {
+		subtree, err = s.categorySubtree(ctx, filter.Category)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	matching := make([]models.Item, 0, len(items))
+	for _, item := range items {
+		if subtree != nil && !subtree[foldCategoryName(item.Category)] {
+			continue
+		}
+		if !hasTags(item.Tags, filter.Tags) || !matchAttributeFilters(item.Attributes, filter.Attributes) {
+			continue
+		}
+		matching = append(matching, item)
+	}
+
+	return matching, nil
+}
+
+// SearchDocumentation finds items by brand and model, optionally keeping only
+// those that carry an asset of the requested type
+func (s *BackpackService) SearchDocumentation(ctx context.Context, query models.SearchQuery) ([]models.ItemWithAssets, error) {
+	if query.Brand == "" && query.Model == "" {
+		return nil, fmt.Errorf("brand or model is required")
+	}
+
+	dbItems, err := s.queries.SearchItemsByProduct(ctx, db.SearchItemsByProductParams{
+		Brand: "%" + query.Brand + "%",
+		Model: "%" + query.Model + "%",
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to open source file: %w", err)
+		return nil, fmt.Errorf("failed to search items: %w", err)
 	}
-	defer sourceFile.Close()
 
-	// Get file info
-	fileInfo, err := sourceFile.Stat()
+	items, err := s.dbItemsToModels(dbItems)
 	if err != nil {
-		return nil, fmt.Errorf("failed to stat file: %w", err)
+		return nil, err
 	}
 
-	// Calculate file hash
-	hash := sha256.New()
-	if _, err := io.Copy(hash, sourceFile); err != nil {
-		return nil, fmt.Errorf("failed to calculate hash: %w", err)
+	if err := loadItemDetails(ctx, s.queries, items); err != nil {
+		return nil, err
 	}
-	fileHash := fmt.Sprintf("%x", hash.Sum(nil))
 
-	// Reset file pointer
-	if _, err := sourceFile.Seek(0, 0); err != nil {
-		return nil, fmt.Errorf("failed to reset file pointer: %w", err)
+	results := make([]models.ItemWithAssets, 0, len(items))
+	for _, item := range items {
+		assets, err := s.GetItemAssets(ctx, item.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		if query.AssetType != "" && !hasAssetType(assets, query.AssetType) {
+			continue
+		}
+
+		health, err := s.calculateDocumentationHealth(ctx, &item, assets)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, models.ItemWithAssets{
+			Item:   item,
+			Assets: assets,
+			Health: health,
+		})
+	}
+
+	return results, nil
+}
+
+// AddAsset adds an asset to an item by copying the file to the assets directory
+func (s *BackpackService) AddAsset(ctx context.Context, itemID int64, assetType models.AssetType, name string, sourcePath string) (*models.Asset, error) {
+	return s.createAsset(ctx, assetOwner{itemID: itemID}, assetType, name, sourcePath, assetRevision{})
+}
+
+// createAsset copies the file to the assets directory and records it, as the
+// current revision of its chain. The copy is staged and only moved into place
+// once the row is committed.
+func (s *BackpackService) createAsset(ctx context.Context, owner assetOwner, assetType models.AssetType, name string, sourcePath string, revision assetRevision) (*models.Asset, error) {
+	var asset *models.Asset
+	err := s.inTx(ctx, func(tx *BackpackService) error {
+		// Verify the owner exists
+		if err := owner.check(ctx, tx.queries); err != nil {
+			return err
+		}
+
+		// Verify the type is registered and accepts this file
+		if err := tx.validateAsset(ctx, assetType, sourcePath); err != nil {
+			return err
+		}
+
+		stagedPath, fileSize, fileHash, err := tx.stageFile(ctx, sourcePath)
+		if err != nil {
+			return err
+		}
+
+		// Generate destination path
+		ownerDir := filepath.Join(tx.assetsDir, owner.dir())
+		destPath := filepath.Join(ownerDir, fmt.Sprintf("%s_%d%s", assetType, time.Now().UnixNano(), filepath.Ext(sourcePath)))
+
+		// Create asset in database
+		params := db.CreateAssetParams{
+			ItemID:         owner.itemIDParam(),
+			ProductModelID: owner.productModelIDParam(),
+			Type:           string(assetType),
+			Name:           name,
+			FilePath:       destPath,
+			FileSize:       fileSize,
+			FileHash:       fileHash,
+			CreatedAt:      time.Now(),
+			VersionLabel:   revision.versionLabel,
+			IsCurrent:      true,
+		}
+
+		if revision.releaseDate != nil {
+			params.ReleaseDate.Time = *revision.releaseDate
+			params.ReleaseDate.Valid = true
+		}
+
+		if revision.supersedesID != nil {
+			params.SupersedesID.Int64 = *revision.supersedesID
+			params.SupersedesID.Valid = true
+		}
+
+		dbAsset, err := tx.queries.CreateAsset(ctx, params)
+		if err != nil {
+			return fmt.Errorf("failed to create asset: %w", err)
+		}
+
+		if err := tx.queueFileMove(ctx, stagedPath, destPath); err != nil {
+			return err
+		}
+
+		asset = tx.dbAssetToModel(dbAsset)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Create item directory in assets
-	itemDir := filepath.Join(s.assetsDir, fmt.Sprintf("item_%d", itemID))
-	if err := os.MkdirAll(itemDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create item directory: %w", err)
+	return asset, nil
+}
+
+// stageFile copies a source file to the staging directory, encrypted when
+// encryption is enabled, returning the staged path, and the size and SHA256
+// hash of the source. Must be called within a transaction.
+func (s *BackpackService) stageFile(ctx context.Context, sourcePath string) (string, int64, string, error) {
+	// Open source file
+	sourceFile, err := os.Open(sourcePath)
+	if err != nil {
+		return "", 0, "", fmt.Errorf("failed to open source file: %w", err)
 	}
+	defer sourceFile.Close()
 
-	// Generate destination path
-	ext := filepath.Ext(sourcePath)
-	destPath := filepath.Join(itemDir, fmt.Sprintf("%s_%d%s", assetType, time.Now().Unix(), ext))
+	if err := os.MkdirAll(s.stagingDir(), 0755); err != nil {
+		return "", 0, "", fmt.Errorf("failed to create staging directory: %w", err)
+	}
 
-	// Copy file to assets directory
-	destFile, err := os.Create(destPath)
+	stagedFile, err := os.CreateTemp(s.stagingDir(), "asset-*"+filepath.Ext(sourcePath))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create destination file: %w", err)
+		return "", 0, "", fmt.Errorf("failed to create destination file: %w", err)
 	}
-	defer destFile.Close()
+	*s.staged = append(*s.staged, stagedFile.Name())
 
-	if _, err := io.Copy(destFile, sourceFile); err != nil {
-		os.Remove(destPath)
-		return nil, fmt.Errorf("failed to copy file: %w", err)
+	dest, err := s.encryptTo(ctx, stagedFile)
+	if err != nil {
+		stagedFile.Close()
+		return "", 0, "", err
 	}
 
-	// Create asset in database
-	now := time.Now()
-	params := db.CreateAssetParams{
-		ItemID:    itemID,
-		Type:      string(assetType),
-		Name:      name,
-		FilePath:  destPath,
-		FileSize:  fileInfo.Size(),
-		FileHash:  fileHash,
-		CreatedAt: now,
+	// Copy the file and calculate its hash in one pass
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(dest, hash), sourceFile)
+	if err != nil {
+		dest.Close()
+		return "", 0, "", fmt.Errorf("failed to copy file: %w", err)
+	}
+	if err := dest.Close(); err != nil {
+		return "", 0, "", fmt.Errorf("failed to write file: %w", err)
 	}
 
-	dbAsset, err := s.queries.CreateAsset(ctx, params)
+	return stagedFile.Name(), size, fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
+
+// GetAsset retrieves an asset by ID
+func (s *BackpackService) GetAsset(ctx context.Context, assetID int64) (*models.Asset, error) {
+	dbAsset, err := s.queries.GetAssetByID(ctx, assetID)
 	if err != nil {
-		os.Remove(destPath)
-		return nil, fmt.Errorf("failed to create asset: %w", err)
+		return nil, fmt.Errorf("failed to get asset: %w", err)
 	}
 
 	return s.dbAssetToModel(dbAsset), nil
 }
 
-// GetItemAssets retrieves all assets for an item
+// GetItemAssets retrieves all assets for an item, including the documentation
+// shared by its product model, newest first
 func (s *BackpackService) GetItemAssets(ctx context.Context, itemID int64) ([]models.Asset, error) {
-	dbAssets, err := s.queries.GetAssetsByItemID(ctx, itemID)
+	dbItem, err := s.queries.GetItemByID(ctx, itemID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get item: %w", err)
+	}
+
+	dbAssets, err := s.queries.GetAssetsByItemID(ctx, sql.NullInt64{Int64: itemID, Valid: true})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get assets: %w", err)
 	}
 
+	if dbItem.ProductModelID.Valid {
+		shared, err := s.queries.GetAssetsByProductModelID(ctx, dbItem.ProductModelID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get product model assets: %w", err)
+		}
+		dbAssets = append(dbAssets, shared...)
+	}
+
 	assets := make([]models.Asset, len(dbAssets))
 	for i, dbAsset := range dbAssets {
 		assets[i] = *s.dbAssetToModel(dbAsset)
 	}
 
+	sort.SliceStable(assets, func(i, j int) bool {
+		return assets[i].CreatedAt.After(assets[j].CreatedAt)
+	})
+
 	return assets, nil
 }
 
@@ -262,7 +635,10 @@ func (s *BackpackService) GetItemWithAssets(ctx context.Context, itemID int64) (
 		return nil, err
 	}
 
-	health := s.calculateDocumentationHealth(assets)
+	health, err := s.calculateDocumentationHealth(ctx, item, assets)
+	if err != nil {
+		return nil, err
+	}
 
 	return &models.ItemWithAssets{
 		Item:   *item,
@@ -273,56 +649,138 @@ func (s *BackpackService) GetItemWithAssets(ctx context.Context, itemID int64) (
 
 // DeleteAsset deletes an asset and its file
 func (s *BackpackService) DeleteAsset(ctx context.Context, assetID int64) error {
-	// Get asset to get file path
-	dbAsset, err := s.queries.GetAssetByID(ctx, assetID)
-	if err != nil {
-		return fmt.Errorf("failed to get asset: %w", err)
+	return s.inTx(ctx, func(tx *BackpackService) error {
+		// Get asset to get file path
+		dbAsset, err := tx.queries.GetAssetByID(ctx, assetID)
+		if err != nil {
+			return fmt.Errorf("failed to get asset: %w", err)
+		}
+
+		// Keep the revision chain linked without this asset
+		if err := tx.unlinkRevision(ctx, dbAsset); err != nil {
+			return err
+		}
+
+		if err := tx.queueAssetFilesDelete(ctx, dbAsset); err != nil {
+			return err
+		}
+
+		// Delete from database
+		if err := tx.queries.DeleteAsset(ctx, assetID); err != nil {
+			return fmt.Errorf("failed to delete asset: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// queueAssetFilesDelete schedules the deletion of an asset file and its thumbnail
+func (s *BackpackService) queueAssetFilesDelete(ctx context.Context, asset db.Asset) error {
+	if err := s.queueFileDelete(ctx, asset.FilePath); err != nil {
+		return err
 	}
+	return s.queueFileDelete(ctx, s.thumbnailPath(asset.ID))
+}
 
-	// Delete file from disk
-	if err := os.Remove(dbAsset.FilePath); err != nil && !os.IsNotExist(err) {
-		fmt.Printf("Warning: failed to delete asset file %s: %v\n", dbAsset.FilePath, err)
+// HealthReport lists every item that is missing a document required by its category rule
+func (s *BackpackService) HealthReport(ctx context.Context) ([]models.HealthReport, error) {
+	items, err := s.GetAllItems(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	// Delete from database
-	if err := s.queries.DeleteAsset(ctx, assetID); err != nil {
-		return fmt.Errorf("failed to delete asset: %w", err)
+	reports := []models.HealthReport{}
+	for _, item := range items {
+		assets, err := s.GetItemAssets(ctx, item.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		report, err := s.evaluateHealth(ctx, &item, assets)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(report.MissingRequired) > 0 {
+			reports = append(reports, report)
+		}
 	}
 
-	return nil
+	return reports, nil
 }
 
 // calculateDocumentationHealth determines the health status based on assets
-func (s *BackpackService) calculateDocumentationHealth(assets []models.Asset) models.DocumentationHealth {
-	if len(assets) == 0 {
-		return models.HealthIncomplete
+// and the rule of the item's category
+func (s *BackpackService) calculateDocumentationHealth(ctx context.Context, item *models.Item, assets []models.Asset) (models.DocumentationHealth, error) {
+	report, err := s.evaluateHealth(ctx, item, assets)
+	if err != nil {
+		return "", err
 	}
+	return report.Health, nil
+}
 
-	hasManual := false
-	hasServiceManual := false
+// evaluateHealth applies the category rule to the current revisions whose
+// type counts towards documentation health
+func (s *BackpackService) evaluateHealth(ctx context.Context, item *models.Item, assets []models.Asset) (models.HealthReport, error) {
+	counted, err := s.countsForHealth(ctx, currentRevisions(assets))
+	if err != nil {
+		return models.HealthReport{}, err
+	}
+	return s.healthRules.Evaluate(*item, counted), nil
+}
 
+// hasAssetType reports whether any of the assets is of the given type
+func hasAssetType(assets []models.Asset, assetType models.AssetType) bool {
 	for _, asset := range assets {
-		switch asset.Type {
-		case models.AssetTypeManual:
-			hasManual = true
-		case models.AssetTypeServiceManual:
-			hasServiceManual = true
+		if asset.Type == assetType {
+			return true
 		}
 	}
+	return false
+}
 
-	if hasManual && hasServiceManual {
-		return models.HealthSecured
+// loadItemDetails loads the attributes and tags of the given items
+func loadItemDetails(ctx context.Context, q *db.Queries, items []models.Item) error {
+	attributes, err := loadItemAttributes(ctx, q)
+	if err != nil {
+		return err
 	}
 
-	if hasManual || len(assets) > 0 {
-		return models.HealthPartial
+	tags, err := loadItemTags(ctx, q)
+	if err != nil {
+		return err
 	}
 
-	return models.HealthIncomplete
+	for i := range items {
+		items[i].Attributes = attributes[items[i].ID]
+		if items[i].Attributes == nil {
+			items[i].Attributes = []models.Attribute{}
+		}
+
+		items[i].Tags = tags[items[i].ID]
+		if items[i].Tags == nil {
+			items[i].Tags = []string{}
+		}
+	}
+
+	return nil
 }
 
-// dbItemToModel converts a DB item to a model item
-func (s *BackpackService) dbItemToModel(dbItem db.Item) *models.Item {
+// dbItemsToModels converts DB items to model items
+func (s *BackpackService) dbItemsToModels(dbItems []db.Item) ([]models.Item, error) {
+	items := make([]models.Item, len(dbItems))
+	for i, dbItem := range dbItems {
+		item, err := s.dbItemToModel(dbItem)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = *item
+	}
+	return items, nil
+}
+
+// dbItemToModel converts a DB item to a model item, decrypting its sensitive fields
+func (s *BackpackService) dbItemToModel(dbItem db.Item) (*models.Item, error) {
 	item := &models.Item{
 		ID:           dbItem.ID,
 		Name:         dbItem.Name,
@@ -340,19 +798,43 @@ func (s *BackpackService) dbItemToModel(dbItem db.Item) *models.Item {
 		item.PurchaseDate = &dbItem.PurchaseDate.Time
 	}
 
-	return item
+	item.ProductModelID = nullInt64Ptr(dbItem.ProductModelID)
+
+	if err := s.keyring.openItemSecrets(item); err != nil {
+		return nil, err
+	}
+
+	return item, nil
 }
 
 // dbAssetToModel converts a DB asset to a model asset
 func (s *BackpackService) dbAssetToModel(dbAsset db.Asset) *models.Asset {
-	return &models.Asset{
-		ID:        dbAsset.ID,
-		ItemID:    dbAsset.ItemID,
-		Type:      models.AssetType(dbAsset.Type),
-		Name:      dbAsset.Name,
-		FilePath:  dbAsset.FilePath,
-		FileSize:  dbAsset.FileSize,
-		FileHash:  dbAsset.FileHash,
-		CreatedAt: dbAsset.CreatedAt,
+	asset := &models.Asset{
+		ID:           dbAsset.ID,
+		ItemID:       dbAsset.ItemID.Int64,
+		Type:         models.AssetType(dbAsset.Type),
+		Name:         dbAsset.Name,
+		FilePath:     dbAsset.FilePath,
+		FileSize:     dbAsset.FileSize,
+		FileHash:     dbAsset.FileHash,
+		CreatedAt:    dbAsset.CreatedAt,
+		VersionLabel: dbAsset.VersionLabel,
+		IsCurrent:    dbAsset.IsCurrent,
+	}
+
+	if dbAsset.ReleaseDate.Valid {
+		asset.ReleaseDate = &dbAsset.ReleaseDate.Time
+	}
+
+	if dbAsset.SupersedesID.Valid {
+		asset.SupersedesID = &dbAsset.SupersedesID.Int64
 	}
+
+	if dbAsset.SupersededByID.Valid {
+		asset.SupersededByID = &dbAsset.SupersededByID.Int64
+	}
+
+	asset.ProductModelID = nullInt64Ptr(dbAsset.ProductModelID)
+
+	return asset
 }
@@ -1,10 +1,21 @@
 package services_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -34,8 +45,7 @@ func setupTestService(t *testing.T) (*services.BackpackService, func()) {
 		t.Fatalf("failed to initialize database: %v", err)
 	}
 
-	queries := db.New(database.DB)
-	service := services.NewBackpackService(queries, assetsDir)
+	service := services.NewBackpackService(database, assetsDir)
 
 	cleanup := func() {
 		database.Close()
@@ -314,3 +324,2622 @@ func TestDocumentationHealth(t *testing.T) {
 		t.Errorf("expected health 'secured', got '%s'", itemWithAssets.Health)
 	}
 }
+
+func TestSearchDocumentation(t *testing.T) {
+	service, cleanup := setupTestService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	items := []models.Item{
+		{Name: "Lave-Linge", Category: "Électroménager", Brand: "Brandt", Model: "WTC1234"},
+		{Name: "Lave-Linge Cave", Category: "Électroménager", Brand: "Brandt", Model: "WTC1234"},
+		{Name: "Perceuse", Category: "Outils", Brand: "Bosch", Model: "PSB500"},
+	}
+
+	for i := range items {
+		if err := service.CreateItem(ctx, &items[i]); err != nil {
+			t.Fatalf("failed to create item: %v", err)
+		}
+	}
+
+	tempFile := filepath.Join(t.TempDir(), "service.pdf")
+	if err := os.WriteFile(tempFile, []byte("service manual"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	if _, err := service.AddAsset(ctx, items[1].ID, models.AssetTypeServiceManual, "Service Manual", tempFile); err != nil {
+		t.Fatalf("failed to add asset: %v", err)
+	}
+
+	results, err := service.SearchDocumentation(ctx, models.SearchQuery{Brand: "Brandt", Model: "WTC"})
+	if err != nil {
+		t.Fatalf("failed to search documentation: %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Errorf("expected 2 results for Brandt WTC, got %d", len(results))
+	}
+
+	// Restrict to items holding a service manual
+	results, err = service.SearchDocumentation(ctx, models.SearchQuery{Brand: "Brandt", AssetType: models.AssetTypeServiceManual})
+	if err != nil {
+		t.Fatalf("failed to search documentation: %v", err)
+	}
+
+	if len(results) != 1 || results[0].Item.ID != items[1].ID {
+		t.Fatalf("expected only item %d, got %+v", items[1].ID, results)
+	}
+
+	if len(results[0].Assets) != 1 {
+		t.Errorf("expected 1 asset, got %d", len(results[0].Assets))
+	}
+
+	// An empty query is rejected
+	if _, err := service.SearchDocumentation(ctx, models.SearchQuery{}); err == nil {
+		t.Error("expected an error for an empty query")
+	}
+}
+
+func TestHealthRulesPerCategory(t *testing.T) {
+	service, cleanup := setupTestService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	service.SetHealthRules([]models.HealthRule{
+		{Category: "vélo", Required: []models.AssetType{models.AssetTypeExplodedView}},
+	})
+
+	bike := &models.Item{Name: "Vélo cargo", Category: "Vélo", Brand: "Douze", Model: "G4"}
+	printer := &models.Item{Name: "Prusa", Category: "Imprimante 3D", Brand: "Prusa", Model: "MK4"}
+
+	for _, item := range []*models.Item{bike, printer} {
+		if err := service.CreateItem(ctx, item); err != nil {
+			t.Fatalf("failed to create item: %v", err)
+		}
+	}
+
+	tempFile := filepath.Join(t.TempDir(), "file.bin")
+	if err := os.WriteFile(tempFile, []byte("content"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	// A single exploded view secures the bike, whatever the case of its category
+	if _, err := service.AddAsset(ctx, bike.ID, models.AssetTypeExplodedView, "Vue éclatée", tempFile); err != nil {
+		t.Fatalf("failed to add asset: %v", err)
+	}
+
+	bikeWithAssets, err := service.GetItemWithAssets(ctx, bike.ID)
+	if err != nil {
+		t.Fatalf("failed to get item with assets: %v", err)
+	}
+
+	if bikeWithAssets.Health != models.HealthSecured {
+		t.Errorf("expected health 'secured' for the bike, got '%s'", bikeWithAssets.Health)
+	}
+
+	// A manual is not enough for a 3D printer, which needs STL and firmware
+	if _, err := service.AddAsset(ctx, printer.ID, models.AssetTypeManual, "Manual", tempFile); err != nil {
+		t.Fatalf("failed to add asset: %v", err)
+	}
+
+	reports, err := service.HealthReport(ctx)
+	if err != nil {
+		t.Fatalf("failed to build health report: %v", err)
+	}
+
+	if len(reports) != 1 || reports[0].Item.ID != printer.ID {
+		t.Fatalf("expected only the printer in the report, got %+v", reports)
+	}
+
+	if reports[0].Health != models.HealthPartial {
+		t.Errorf("expected health 'partial' for the printer, got '%s'", reports[0].Health)
+	}
+
+	if len(reports[0].MissingRequired) != 2 {
+		t.Errorf("expected 2 missing documents, got %v", reports[0].MissingRequired)
+	}
+
+	// Rules are listed in a stable order: the default one, then by category
+	var categories []string
+	for _, rule := range service.GetHealthRules() {
+		categories = append(categories, rule.Category)
+	}
+	expected := []string{"*", "Imprimante 3D", "Informatique", "vélo"}
+	if strings.Join(categories, "|") != strings.Join(expected, "|") {
+		t.Errorf("expected rules for %v, got %v", expected, categories)
+	}
+}
+
+func TestAssetTypeRegistry(t *testing.T) {
+	service, cleanup := setupTestService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// Built-in types are seeded and cannot be removed
+	if err := service.DeleteAssetType(ctx, models.AssetTypeManual); err == nil {
+		t.Error("expected error when deleting a built-in asset type")
+	}
+
+	warranty := &models.AssetTypeDefinition{
+		Name:         "warranty",
+		Label:        "Garantie",
+		MimePatterns: []string{"application/pdf"},
+	}
+	if err := service.SaveAssetType(ctx, warranty); err != nil {
+		t.Fatalf("failed to save asset type: %v", err)
+	}
+
+	item := &models.Item{Name: "Perceuse", Category: "Outillage", Brand: "Bosch", Model: "PSB"}
+	if err := service.CreateItem(ctx, item); err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+
+	textFile := filepath.Join(t.TempDir(), "warranty.txt")
+	if err := os.WriteFile(textFile, []byte("plain text"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	if _, err := service.AddAsset(ctx, item.ID, "warranty", "Garantie", textFile); err == nil {
+		t.Error("expected error when the file does not match the MIME patterns")
+	}
+
+	if _, err := service.AddAsset(ctx, item.ID, "unknown", "Inconnu", textFile); err == nil {
+		t.Error("expected error for an unregistered asset type")
+	}
+
+	pdfFile := filepath.Join(t.TempDir(), "warranty.pdf")
+	if err := os.WriteFile(pdfFile, []byte("%PDF-1.4\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	if _, err := service.AddAsset(ctx, item.ID, "warranty", "Garantie", pdfFile); err != nil {
+		t.Fatalf("failed to add asset: %v", err)
+	}
+
+	// A type excluded from health does not count as documentation
+	itemWithAssets, err := service.GetItemWithAssets(ctx, item.ID)
+	if err != nil {
+		t.Fatalf("failed to get item with assets: %v", err)
+	}
+
+	if itemWithAssets.Health != models.HealthIncomplete {
+		t.Errorf("expected health 'incomplete', got '%s'", itemWithAssets.Health)
+	}
+
+	if err := service.DeleteAssetType(ctx, "warranty"); err == nil {
+		t.Error("expected error when deleting an asset type in use")
+	}
+}
+
+func TestAssetRevisions(t *testing.T) {
+	service, cleanup := setupTestService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	service.SetHealthRules([]models.HealthRule{
+		{Category: "Réseau", Required: []models.AssetType{models.AssetTypeFirmware}},
+	})
+
+	router := &models.Item{Name: "Routeur", Category: "Réseau", Brand: "Turris", Model: "Omnia"}
+	if err := service.CreateItem(ctx, router); err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+
+	tempFile := filepath.Join(t.TempDir(), "firmware.bin")
+	if err := os.WriteFile(tempFile, []byte("firmware"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	v12, err := service.AddAsset(ctx, router.ID, models.AssetTypeFirmware, "Firmware", tempFile)
+	if err != nil {
+		t.Fatalf("failed to add asset: %v", err)
+	}
+
+	if err := service.SetAssetVersion(ctx, v12.ID, "v1.2", nil); err != nil {
+		t.Fatalf("failed to set asset version: %v", err)
+	}
+
+	v13, err := service.AddAssetRevision(ctx, v12.ID, "", tempFile, "v1.3", nil)
+	if err != nil {
+		t.Fatalf("failed to add revision: %v", err)
+	}
+
+	if v13.Type != models.AssetTypeFirmware || v13.Name != "Firmware" {
+		t.Errorf("expected the revision to keep type and name, got %s %q", v13.Type, v13.Name)
+	}
+
+	// Only the newest revision can be superseded
+	if _, err := service.AddAssetRevision(ctx, v12.ID, "", tempFile, "v1.2.1", nil); err == nil {
+		t.Error("expected error when revising a superseded asset")
+	}
+
+	history, err := service.GetAssetHistory(ctx, v12.ID)
+	if err != nil {
+		t.Fatalf("failed to get asset history: %v", err)
+	}
+
+	if len(history) != 2 || history[0].ID != v13.ID || history[1].VersionLabel != "v1.2" {
+		t.Fatalf("expected history [v1.3, v1.2], got %+v", history)
+	}
+
+	if !history[0].IsCurrent || history[1].IsCurrent {
+		t.Error("expected v1.3 to be the only current revision")
+	}
+
+	// Roll back to v1.2, then drop it: v1.3 becomes current again
+	if err := service.SetCurrentRevision(ctx, v12.ID); err != nil {
+		t.Fatalf("failed to set current revision: %v", err)
+	}
+
+	if err := service.DeleteAsset(ctx, v12.ID); err != nil {
+		t.Fatalf("failed to delete asset: %v", err)
+	}
+
+	remaining, err := service.GetAsset(ctx, v13.ID)
+	if err != nil {
+		t.Fatalf("failed to get asset: %v", err)
+	}
+
+	if !remaining.IsCurrent || remaining.SupersedesID != nil {
+		t.Errorf("expected v1.3 to be current and unlinked, got %+v", remaining)
+	}
+
+	itemWithAssets, err := service.GetItemWithAssets(ctx, router.ID)
+	if err != nil {
+		t.Fatalf("failed to get item with assets: %v", err)
+	}
+
+	if itemWithAssets.Health != models.HealthSecured {
+		t.Errorf("expected health 'secured', got '%s'", itemWithAssets.Health)
+	}
+}
+
+func TestItemPhotos(t *testing.T) {
+	service, cleanup := setupTestService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	item := &models.Item{Name: "Cafetière", Category: "Cuisine", Brand: "Moka", Model: "Express"}
+	if err := service.CreateItem(ctx, item); err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+
+	// A 640x480 PNG photo, referenced the legacy way
+	img := image.NewRGBA(image.Rect(0, 0, 640, 480))
+	for x := 0; x < 640; x++ {
+		img.Set(x, x%480, color.RGBA{R: 200, A: 255})
+	}
+
+	photoFile := filepath.Join(t.TempDir(), "cafetiere.png")
+	f, err := os.Create(photoFile)
+	if err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	if err := png.Encode(f, img); err != nil {
+		t.Fatalf("failed to encode test image: %v", err)
+	}
+	f.Close()
+
+	item.PhotoPath = photoFile
+	if err := service.UpdateItem(ctx, item); err != nil {
+		t.Fatalf("failed to update item: %v", err)
+	}
+
+	imported, err := service.ImportLegacyPhotos(ctx)
+	if err != nil {
+		t.Fatalf("failed to import legacy photos: %v", err)
+	}
+
+	if imported != 1 {
+		t.Fatalf("expected 1 imported photo, got %d", imported)
+	}
+
+	photos, err := service.GetItemPhotos(ctx, item.ID)
+	if err != nil {
+		t.Fatalf("failed to get photos: %v", err)
+	}
+
+	if len(photos) != 1 {
+		t.Fatalf("expected 1 photo, got %d", len(photos))
+	}
+
+	thumbPath, err := service.GetThumbnail(ctx, photos[0].ID)
+	if err != nil {
+		t.Fatalf("failed to get thumbnail: %v", err)
+	}
+
+	thumbFile, err := os.Open(thumbPath)
+	if err != nil {
+		t.Fatalf("failed to open thumbnail: %v", err)
+	}
+	defer thumbFile.Close()
+
+	thumb, err := jpeg.Decode(thumbFile)
+	if err != nil {
+		t.Fatalf("failed to decode thumbnail: %v", err)
+	}
+
+	if thumb.Bounds().Dx() != 320 || thumb.Bounds().Dy() != 240 {
+		t.Errorf("expected a 320x240 thumbnail, got %v", thumb.Bounds())
+	}
+
+	// Photos are not documentation, and only images are accepted
+	itemWithAssets, err := service.GetItemWithAssets(ctx, item.ID)
+	if err != nil {
+		t.Fatalf("failed to get item with assets: %v", err)
+	}
+
+	if itemWithAssets.Item.PhotoPath != "" {
+		t.Errorf("expected the legacy photo path to be cleared, got %q", itemWithAssets.Item.PhotoPath)
+	}
+
+	if itemWithAssets.Health != models.HealthIncomplete {
+		t.Errorf("expected health 'incomplete', got '%s'", itemWithAssets.Health)
+	}
+
+	textFile := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(textFile, []byte("not an image"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	if _, err := service.AddPhoto(ctx, item.ID, textFile); err == nil {
+		t.Error("expected error when adding a non-image photo")
+	}
+
+	// An image declaring a huge size is refused before being decoded
+	var huge bytes.Buffer
+	if err := png.Encode(&huge, image.NewRGBA(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatalf("failed to encode test image: %v", err)
+	}
+	header := huge.Bytes()
+	binary.BigEndian.PutUint32(header[16:], 60000) // IHDR width
+	binary.BigEndian.PutUint32(header[20:], 60000) // IHDR height
+	binary.BigEndian.PutUint32(header[29:], crc32.ChecksumIEEE(header[12:29]))
+	hugeFile := filepath.Join(t.TempDir(), "huge.png")
+	if err := os.WriteFile(hugeFile, header, 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	if _, err := service.AddPhoto(ctx, item.ID, hugeFile); err == nil || !strings.Contains(err.Error(), "too large") {
+		t.Errorf("expected a 60000x60000 image to be refused, got %v", err)
+	}
+
+	if err := service.DeleteAsset(ctx, photos[0].ID); err != nil {
+		t.Fatalf("failed to delete photo: %v", err)
+	}
+
+	if _, err := os.Stat(thumbPath); !os.IsNotExist(err) {
+		t.Error("expected the thumbnail to be deleted with the photo")
+	}
+}
+
+func TestFileOutboxRecovery(t *testing.T) {
+	tempDir := t.TempDir()
+	assetsDir := filepath.Join(tempDir, "assets")
+
+	database, err := db.NewDatabase(filepath.Join(tempDir, "test.db"), slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelError,
+	})))
+	if err != nil {
+		t.Fatalf("failed to initialize database: %v", err)
+	}
+	defer database.Close()
+
+	service := services.NewBackpackService(database, assetsDir)
+	ctx := context.Background()
+
+	item := &models.Item{Name: "Aspirateur", Category: "Ménage", Brand: "Dyson", Model: "V8"}
+	if err := service.CreateItem(ctx, item); err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+
+	tempFile := filepath.Join(t.TempDir(), "manual.pdf")
+	if err := os.WriteFile(tempFile, []byte("manual"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	asset, err := service.AddAsset(ctx, item.ID, models.AssetTypeManual, "Manuel", tempFile)
+	if err != nil {
+		t.Fatalf("failed to add asset: %v", err)
+	}
+
+	if _, err := os.Stat(asset.FilePath); err != nil {
+		t.Fatalf("expected the asset file to be moved into place: %v", err)
+	}
+
+	// Simulate a crash: one committed move that was never applied, and one
+	// staged file whose transaction never committed
+	stagingDir := filepath.Join(assetsDir, "staging")
+	committed := filepath.Join(stagingDir, "committed.pdf")
+	orphan := filepath.Join(stagingDir, "orphan.pdf")
+	for _, path := range []string{committed, orphan} {
+		if err := os.WriteFile(path, []byte("staged"), 0644); err != nil {
+			t.Fatalf("failed to create staged file: %v", err)
+		}
+	}
+
+	target := filepath.Join(assetsDir, "item_1", "recovered.pdf")
+	if err := db.New(database.DB).CreateFileOutboxEntry(ctx, db.CreateFileOutboxEntryParams{
+		Action:     "move",
+		Path:       committed,
+		TargetPath: target,
+		CreatedAt:  time.Now(),
+	}); err != nil {
+		t.Fatalf("failed to create outbox entry: %v", err)
+	}
+
+	if err := service.ProcessFileOutbox(ctx); err != nil {
+		t.Fatalf("failed to process file outbox: %v", err)
+	}
+
+	if _, err := os.Stat(target); err != nil {
+		t.Errorf("expected the committed move to be applied: %v", err)
+	}
+
+	if _, err := os.Stat(orphan); !os.IsNotExist(err) {
+		t.Error("expected the orphan staged file to be removed")
+	}
+
+	// Deleting the item removes its files once the transaction commits
+	if err := service.DeleteItem(ctx, item.ID); err != nil {
+		t.Fatalf("failed to delete item: %v", err)
+	}
+
+	if _, err := os.Stat(asset.FilePath); !os.IsNotExist(err) {
+		t.Error("expected the asset file to be deleted with the item")
+	}
+}
+
+func TestImportItemsIsAtomic(t *testing.T) {
+	service, cleanup := setupTestService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	existing := &models.Item{Name: "Four", Category: "Cuisine", Brand: "Bosch", Model: "HBA", SerialNumber: "SN-1"}
+	if err := service.CreateItem(ctx, existing); err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+
+	imported, skipped, err := service.ImportItems(ctx, []models.Item{
+		{Name: "Four", Category: "Cuisine", Brand: "Bosch", Model: "HBA", SerialNumber: "SN-1"},
+		{Name: "Hotte", Category: "Cuisine", Brand: "Bosch", Model: "DWB"},
+	})
+	if err != nil {
+		t.Fatalf("failed to import items: %v", err)
+	}
+
+	if imported != 1 || skipped != 1 {
+		t.Errorf("expected 1 imported and 1 skipped, got %d and %d", imported, skipped)
+	}
+
+	items, err := service.GetAllItems(ctx)
+	if err != nil {
+		t.Fatalf("failed to get items: %v", err)
+	}
+
+	if len(items) != 2 {
+		t.Errorf("expected 2 items, got %d", len(items))
+	}
+}
+
+func TestImportCSV(t *testing.T) {
+	service, cleanup := setupTestService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	existing := &models.Item{Name: "Four", Category: "Cuisine", Brand: "Bosch", Model: "HBA", SerialNumber: "SN-1"}
+	if err := service.CreateItem(ctx, existing); err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+
+	// A spreadsheet export: semicolons, French headers and an unknown column
+	csvData := "\ufeffNom;Marque;Modèle;Numéro de série;Rayon;Date d'achat;voltage\n" +
+		"Four;bosch;HBA;SN-1;Cuisine;;\n" + // Already in the inventory
+		"Four du chalet;Siemens;HBA;SN-1;Cuisine;2023-05-02;\n" + // Same serial, other brand
+		"Hotte;Bosch;DWB;SN-2;Cuisine;02/01/2024;230\n" +
+		"Hotte bis;Bosch;DWB;sn-2;Cuisine;;\n" + // Same as the row above
+		";Bosch;DWB;;;;\n" +
+		"Radiateur;Atlantic;F17;;Électroménager;demain;abc\n"
+
+	options := models.CSVImportOptions{
+		Columns: map[string]string{models.ImportFieldCategory: "Rayon"},
+		DryRun:  true,
+	}
+
+	report, err := service.ImportCSV(ctx, strings.NewReader(csvData), options)
+	if err != nil {
+		t.Fatalf("failed to preview import: %v", err)
+	}
+
+	if report.Created != 2 || report.Duplicates != 2 || report.Invalid != 2 {
+		t.Errorf("expected 2 created, 2 duplicates and 2 invalid, got %d, %d and %d", report.Created, report.Duplicates, report.Invalid)
+	}
+	if len(report.Rows) != 6 {
+		t.Fatalf("expected 6 rows, got %d", len(report.Rows))
+	}
+
+	if row := report.Rows[0]; row.Action != models.ImportDuplicate || row.DuplicateOf == nil || *row.DuplicateOf != existing.ID {
+		t.Errorf("expected line 2 to duplicate item #%d, got %+v", existing.ID, row)
+	}
+	if row := report.Rows[3]; row.Action != models.ImportDuplicate || row.DuplicateLine != 4 {
+		t.Errorf("expected line 5 to duplicate line 4, got %+v", row)
+	}
+	if row := report.Rows[5]; row.Line != 7 || len(row.Errors) != 2 {
+		t.Errorf("expected 2 errors on line 7 (date and voltage), got %+v", row)
+	}
+
+	hood := report.Rows[2].Item
+	if hood.Category != "Cuisine" || hood.PurchaseDate == nil || hood.PurchaseDate.Format("2006-01-02") != "2024-01-02" {
+		t.Errorf("expected the hood in Cuisine bought on 2024-01-02, got %+v", hood)
+	}
+	if len(hood.Attributes) != 1 || hood.Attributes[0].Key != "voltage" {
+		t.Errorf("expected the voltage column imported as attribute, got %+v", hood.Attributes)
+	}
+
+	items, err := service.GetAllItems(ctx)
+	if err != nil {
+		t.Fatalf("failed to get items: %v", err)
+	}
+	if len(items) != 1 {
+		t.Errorf("expected a dry run to import nothing, got %d items", len(items))
+	}
+
+	options.DryRun = false
+	report, err = service.ImportCSV(ctx, strings.NewReader(csvData), options)
+	if err != nil {
+		t.Fatalf("failed to import: %v", err)
+	}
+	if report.Rows[2].Item.ID == 0 {
+		t.Error("expected imported rows to carry the ID of their item")
+	}
+
+	items, err = service.GetAllItems(ctx)
+	if err != nil {
+		t.Fatalf("failed to get items: %v", err)
+	}
+	if len(items) != 3 {
+		t.Errorf("expected 3 items, got %d", len(items))
+	}
+
+	// Every row is now in the inventory
+	report, err = service.ImportCSV(ctx, strings.NewReader(csvData), options)
+	if err != nil {
+		t.Fatalf("failed to import again: %v", err)
+	}
+	if report.Created != 0 || report.Duplicates != 4 {
+		t.Errorf("expected 4 duplicates on a second import, got %d created and %d duplicates", report.Created, report.Duplicates)
+	}
+
+	if _, err := service.ImportCSV(ctx, strings.NewReader("Marque,Modèle\nBosch,HBA\n"), models.CSVImportOptions{}); err == nil {
+		t.Error("expected an import without name column to fail")
+	}
+}
+
+func TestExportImportAndBackup(t *testing.T) {
+	service, cleanup := setupTestService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	purchased := time.Date(2023, 5, 2, 0, 0, 0, 0, time.Local)
+	item := &models.Item{
+		Name:         "Perceuse",
+		Category:     "Outillage",
+		Brand:        "Bosch",
+		Model:        "PSB500",
+		SerialNumber: "SN-1",
+		PurchaseDate: &purchased,
+		Tags:         []string{"garage"},
+		Attributes:   []models.Attribute{{Key: "voltage", Value: "18"}},
+	}
+	if err := service.CreateItem(ctx, item); err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+
+	var export bytes.Buffer
+	if count, err := service.ExportJSON(ctx, &export); err != nil || count != 1 {
+		t.Fatalf("expected 1 item exported, got %d (%v)", count, err)
+	}
+
+	// Into another inventory, then again into the same one
+	other, cleanupOther := setupTestService(t)
+	defer cleanupOther()
+
+	if imported, skipped, err := other.ImportJSON(ctx, bytes.NewReader(export.Bytes())); err != nil || imported != 1 || skipped != 0 {
+		t.Fatalf("expected 1 item imported, got %d imported and %d skipped (%v)", imported, skipped, err)
+	}
+	if imported, skipped, err := other.ImportJSON(ctx, bytes.NewReader(export.Bytes())); err != nil || imported != 0 || skipped != 1 {
+		t.Errorf("expected the item skipped on a second import, got %d imported and %d skipped (%v)", imported, skipped, err)
+	}
+
+	items, err := other.GetAllItems(ctx)
+	if err != nil || len(items) != 1 {
+		t.Fatalf("expected 1 item, got %d (%v)", len(items), err)
+	}
+	copied := items[0]
+	if copied.SerialNumber != "SN-1" || copied.PurchaseDate == nil || !copied.PurchaseDate.Equal(purchased) ||
+		len(copied.Tags) != 1 || len(copied.Attributes) != 1 {
+		t.Errorf("expected the item imported with its date, tags and attributes, got %+v", copied)
+	}
+
+	// Files written by the GUI of earlier releases
+	legacy := `{"version": "1.0", "items": [{"item": {"name": "Scie", "brand": "Makita", "serialNumber": "SN-2",
+		"purchaseDate": "2022-01-15", "tags": ["atelier"], "attributes": [{"key": "voltage", "value": "230"}]}}]}`
+	if imported, _, err := other.ImportJSON(ctx, strings.NewReader(legacy)); err != nil || imported != 1 {
+		t.Errorf("expected a version 1.0 export to be imported, got %d (%v)", imported, err)
+	}
+	if _, _, err := other.ImportJSON(ctx, strings.NewReader(`{"version": "9"}`)); err == nil {
+		t.Error("expected an unknown export version to be rejected")
+	}
+
+	// The CSV export can be imported back
+	var csvExport bytes.Buffer
+	if count, err := service.ExportCSV(ctx, &csvExport); err != nil || count != 1 {
+		t.Fatalf("expected 1 item exported as CSV, got %d (%v)", count, err)
+	}
+	report, err := service.ImportCSV(ctx, &csvExport, models.CSVImportOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("failed to read the CSV export: %v", err)
+	}
+	if report.Duplicates != 1 || len(report.Rows[0].Item.Attributes) != 1 {
+		t.Errorf("expected the exported item recognized with its attribute, got %+v", report.Rows)
+	}
+
+	backup, err := service.CreateBackup(ctx, filepath.Join(t.TempDir(), "backups"))
+	if err != nil {
+		t.Fatalf("failed to create backup: %v", err)
+	}
+	for _, name := range []string{"brique.db", "manifest.json"} {
+		if _, err := os.Stat(filepath.Join(backup.Path, name)); err != nil {
+			t.Errorf("expected %s in the backup: %v", name, err)
+		}
+	}
+
+	restored, err := db.NewDatabase(filepath.Join(backup.Path, "brique.db"), nil)
+	if err != nil {
+		t.Fatalf("failed to open the backup: %v", err)
+	}
+	defer restored.Close()
+	items, err = services.NewBackpackService(restored, t.TempDir()).GetAllItems(ctx)
+	if err != nil || len(items) != 1 {
+		t.Errorf("expected the backup to hold 1 item, got %d (%v)", len(items), err)
+	}
+}
+
+func TestBackups(t *testing.T) {
+	service, cleanup := setupTestService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	backupsDir := filepath.Join(t.TempDir(), "backups")
+
+	item := &models.Item{Name: "Perceuse", Brand: "Bosch", Model: "PSB500"}
+	if err := service.CreateItem(ctx, item); err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+	manualPath := filepath.Join(t.TempDir(), "manual.pdf")
+	if err := os.WriteFile(manualPath, []byte("%PDF-1.4 manual"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if _, err := service.AddAsset(ctx, item.ID, models.AssetTypeManual, "Manuel", manualPath); err != nil {
+		t.Fatalf("failed to add asset: %v", err)
+	}
+
+	first, err := service.CreateBackup(ctx, backupsDir)
+	if err != nil {
+		t.Fatalf("failed to create backup: %v", err)
+	}
+	if first.Assets != 1 || first.NewBlobs != 1 {
+		t.Errorf("expected 1 asset copied, got %d assets and %d copied", first.Assets, first.NewBlobs)
+	}
+
+	// Only new files are copied by the next backups
+	second, err := service.CreateBackup(ctx, backupsDir)
+	if err != nil {
+		t.Fatalf("failed to create backup: %v", err)
+	}
+	if second.Name == first.Name || second.Assets != 1 || second.NewBlobs != 0 {
+		t.Errorf("expected a new backup sharing the asset, got %+v", second)
+	}
+
+	backups, err := services.ListBackups(backupsDir)
+	if err != nil || len(backups) != 2 || backups[0].Name != second.Name {
+		t.Fatalf("expected 2 backups, newest first, got %+v (%v)", backups, err)
+	}
+	if _, err := services.VerifyBackup(ctx, backupsDir, first.Name); err != nil {
+		t.Errorf("expected the backup to verify: %v", err)
+	}
+
+	// Changes after the backup are undone by the restore
+	if err := service.DeleteItem(ctx, item.ID); err != nil {
+		t.Fatalf("failed to delete item: %v", err)
+	}
+
+	removed, err := services.PruneBackups(ctx, backupsDir, models.BackupRetention{KeepLast: 1})
+	if err != nil || len(removed) != 1 || removed[0].Name != first.Name {
+		t.Fatalf("expected the oldest backup pruned, got %+v (%v)", removed, err)
+	}
+	if _, err := services.VerifyBackup(ctx, backupsDir, second.Name); err != nil {
+		t.Errorf("expected the kept backup to keep its asset files: %v", err)
+	}
+
+	dataDir := t.TempDir()
+	dbPath := filepath.Join(dataDir, "brique.db")
+	assetsDir := filepath.Join(dataDir, "assets")
+	if _, err := services.RestoreBackup(ctx, backupsDir, second.Name, dbPath, assetsDir); err != nil {
+		t.Fatalf("failed to restore backup: %v", err)
+	}
+
+	restoredDB, err := db.NewDatabase(dbPath, nil)
+	if err != nil {
+		t.Fatalf("failed to open restored database: %v", err)
+	}
+	defer restoredDB.Close()
+	restored := services.NewBackpackService(restoredDB, assetsDir)
+
+	itemWithAssets, err := restored.GetItemWithAssets(ctx, item.ID)
+	if err != nil {
+		t.Fatalf("expected the item back: %v", err)
+	}
+	if len(itemWithAssets.Assets) != 1 {
+		t.Fatalf("expected 1 asset, got %d", len(itemWithAssets.Assets))
+	}
+	rel, err := filepath.Rel(filepath.Dir(filepath.Dir(itemWithAssets.Assets[0].FilePath)), itemWithAssets.Assets[0].FilePath)
+	if err != nil {
+		t.Fatalf("unexpected asset path: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(assetsDir, rel)); err != nil || string(data) != "%PDF-1.4 manual" {
+		t.Errorf("expected the asset file restored, got %q (%v)", data, err)
+	}
+
+	// A damaged backup is not restored
+	blobs, _ := filepath.Glob(filepath.Join(backupsDir, "blobs", "*", "*"))
+	if len(blobs) != 1 {
+		t.Fatalf("expected 1 stored asset file, got %d", len(blobs))
+	}
+	if err := os.WriteFile(blobs[0], []byte("damaged"), 0644); err != nil {
+		t.Fatalf("failed to damage backup: %v", err)
+	}
+	if _, err := services.RestoreBackup(ctx, backupsDir, second.Name, dbPath, assetsDir); err == nil {
+		t.Error("expected a damaged backup to be refused")
+	}
+}
+
+func TestBackupsLock(t *testing.T) {
+	service, cleanup := setupTestService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	backupsDir := t.TempDir()
+	retention := models.BackupRetention{KeepLast: 1}
+
+	// Backups wait for another process to be done with the directory
+	lockPath := filepath.Join(backupsDir, ".lock")
+	if err := os.WriteFile(lockPath, nil, 0644); err != nil {
+		t.Fatalf("failed to write lock: %v", err)
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+	if _, err := service.CreateBackup(waitCtx, backupsDir); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the backup to wait for the lock, got %v", err)
+	}
+	if _, err := services.PruneBackups(waitCtx, backupsDir, retention); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the pruning to wait for the lock, got %v", err)
+	}
+
+	// A lock left by a process that died is taken over
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(lockPath, old, old); err != nil {
+		t.Fatalf("failed to age lock: %v", err)
+	}
+	if _, err := service.CreateBackup(ctx, backupsDir); err != nil {
+		t.Fatalf("failed to create backup: %v", err)
+	}
+	if _, err := os.Stat(lockPath); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected the lock released, got %v", err)
+	}
+
+	// Concurrent backups and pruning keep every kept backup whole
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, err := service.CreateBackup(ctx, backupsDir)
+			errs <- err
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := services.PruneBackups(ctx, backupsDir, retention)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	backups, err := services.ListBackups(backupsDir)
+	if err != nil || len(backups) == 0 {
+		t.Fatalf("expected backups, got %+v (%v)", backups, err)
+	}
+	for _, backup := range backups {
+		if _, err := services.VerifyBackup(ctx, backupsDir, backup.Name); err != nil {
+			t.Errorf("expected the backup to verify: %v", err)
+		}
+	}
+}
+
+func TestRestoreBackupClearsFileOutbox(t *testing.T) {
+	ctx := context.Background()
+	dataDir := t.TempDir()
+	dbPath := filepath.Join(dataDir, "brique.db")
+	assetsDir := filepath.Join(dataDir, "assets")
+	backupsDir := filepath.Join(dataDir, "backups")
+
+	database, err := db.NewDatabase(dbPath, nil)
+	if err != nil {
+		t.Fatalf("failed to initialize database: %v", err)
+	}
+	service := services.NewBackpackService(database, assetsDir)
+
+	// A file operation still pending when the snapshot is taken
+	stagedPath := filepath.Join(assetsDir, "staging", "asset-1.pdf")
+	if _, err := database.DB.Exec("INSERT INTO file_outbox (action, path, target_path) VALUES ('move', ?, ?)", stagedPath, filepath.Join(assetsDir, "1", "asset.pdf")); err != nil {
+		t.Fatalf("failed to queue file operation: %v", err)
+	}
+	backup, err := service.CreateBackup(ctx, backupsDir)
+	if err != nil {
+		t.Fatalf("failed to create backup: %v", err)
+	}
+	database.Close()
+
+	if _, err := services.RestoreBackup(ctx, backupsDir, backup.Name, dbPath, assetsDir); err != nil {
+		t.Fatalf("failed to restore backup: %v", err)
+	}
+	restoredDB, err := db.NewDatabase(dbPath, nil)
+	if err != nil {
+		t.Fatalf("failed to open restored database: %v", err)
+	}
+	defer restoredDB.Close()
+
+	var pending int
+	if err := restoredDB.DB.QueryRow("SELECT COUNT(*) FROM file_outbox").Scan(&pending); err != nil {
+		t.Fatalf("failed to read file outbox: %v", err)
+	}
+	if pending != 0 {
+		t.Errorf("expected the file outbox of the restored database cleared, got %d entries", pending)
+	}
+}
+
+func TestRestoreBackupRejectsUnsafeManifest(t *testing.T) {
+	ctx := context.Background()
+	dataDir := t.TempDir()
+	dbPath := filepath.Join(dataDir, "brique.db")
+	assetsDir := filepath.Join(dataDir, "assets")
+	backupsDir := filepath.Join(dataDir, "backups")
+
+	database, err := db.NewDatabase(dbPath, nil)
+	if err != nil {
+		t.Fatalf("failed to initialize database: %v", err)
+	}
+	defer database.Close()
+	service := services.NewBackpackService(database, assetsDir)
+
+	item := &models.Item{Name: "Perceuse"}
+	if err := service.CreateItem(ctx, item); err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+	manualPath := filepath.Join(t.TempDir(), "manual.pdf")
+	if err := os.WriteFile(manualPath, []byte("%PDF-1.4 manual"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if _, err := service.AddAsset(ctx, item.ID, models.AssetTypeManual, "Manuel", manualPath); err != nil {
+		t.Fatalf("failed to add asset: %v", err)
+	}
+	backup, err := service.CreateBackup(ctx, backupsDir)
+	if err != nil {
+		t.Fatalf("failed to create backup: %v", err)
+	}
+	manifestPath := filepath.Join(backup.Path, "manifest.json")
+	original, err := os.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatalf("failed to read manifest: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		change func(*models.BackupFile)
+	}{
+		{"path out of the assets", func(f *models.BackupFile) { f.Path = "../escaped.pdf" }},
+		{"absolute path", func(f *models.BackupFile) { f.Path = filepath.Join(dataDir, "escaped.pdf") }},
+		{"hash out of the blobs", func(f *models.BackupFile) { f.SHA256 = "../../../escaped.pdf" }},
+		{"hash not in hex", func(f *models.BackupFile) { f.SHA256 = strings.Repeat("z", 64) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var manifest models.BackupManifest
+			if err := json.Unmarshal(original, &manifest); err != nil || len(manifest.Assets) != 1 {
+				t.Fatalf("expected a manifest with 1 asset, got %+v (%v)", manifest, err)
+			}
+			tt.change(&manifest.Assets[0])
+			data, err := json.Marshal(manifest)
+			if err != nil {
+				t.Fatalf("failed to encode manifest: %v", err)
+			}
+			if err := os.WriteFile(manifestPath, data, 0644); err != nil {
+				t.Fatalf("failed to write manifest: %v", err)
+			}
+
+			if _, err := services.RestoreBackup(ctx, backupsDir, backup.Name, dbPath, assetsDir); err == nil {
+				t.Error("expected the backup to be rejected")
+			}
+			if _, err := os.Stat(filepath.Join(dataDir, "escaped.pdf")); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("expected no file written out of the assets, got %v", err)
+			}
+		})
+	}
+}
+
+func TestBackupScheduler(t *testing.T) {
+	service, cleanup := setupTestService(t)
+	defer cleanup()
+
+	usbDrive := t.TempDir()
+	unmounted := filepath.Join(t.TempDir(), "usb")
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	scheduler := services.NewBackupScheduler(service, time.Hour, []string{usbDrive, unmounted}, models.BackupRetention{KeepDaily: 7}, logger)
+
+	status := scheduler.Status()
+	if !status.Enabled || status.Healthy || len(status.Destinations) != 2 {
+		t.Fatalf("expected an enabled schedule unhealthy with a missing destination, got %+v", status)
+	}
+	if status.Destinations[1].Available {
+		t.Error("expected the missing destination to be unavailable")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		scheduler.Run(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		backups, err := services.ListBackups(usbDrive)
+		if err != nil {
+			t.Fatalf("failed to list backups: %v", err)
+		}
+		if len(backups) == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected a backup to be made right away")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	// Missing destinations are not created
+	if _, err := os.Stat(unmounted); !os.IsNotExist(err) {
+		t.Errorf("expected the missing destination to stay missing, got %v", err)
+	}
+
+	destination := scheduler.Status().Destinations[0]
+	if destination.LastBackup == nil || destination.NextBackup == nil || destination.Overdue || destination.LastError != "" {
+		t.Fatalf("expected a backup and the next one planned, got %+v", destination)
+	}
+	if next := destination.LastBackup.CreatedAt.Add(time.Hour); !destination.NextBackup.Equal(next) {
+		t.Errorf("expected the next backup an hour after the last, got %v", destination.NextBackup)
+	}
+
+	disabled := services.NewBackupScheduler(service, 0, []string{usbDrive}, models.BackupRetention{}, logger).Status()
+	if disabled.Enabled || !disabled.Healthy || disabled.Destinations[0].NextBackup != nil || disabled.Destinations[0].Backups != 1 {
+		t.Errorf("expected a disabled schedule to only report the backups, got %+v", disabled)
+	}
+}
+
+func TestBackupSchedulerReconfigure(t *testing.T) {
+	service, cleanup := setupTestService(t)
+	defer cleanup()
+
+	usbDrive := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	// A disabled scheduler keeps running, waiting to be enabled
+	scheduler := services.NewBackupScheduler(service, 0, []string{usbDrive}, models.BackupRetention{}, logger)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		scheduler.Run(ctx)
+		close(done)
+	}()
+
+	scheduler.Reconfigure(time.Hour, []string{usbDrive}, models.BackupRetention{KeepLast: 2})
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		backups, err := services.ListBackups(usbDrive)
+		if err != nil {
+			t.Fatalf("failed to list backups: %v", err)
+		}
+		if len(backups) == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected a backup to be made once enabled")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if status := scheduler.Status(); !status.Enabled || status.Interval != time.Hour.String() {
+		t.Errorf("expected the new schedule in the status, got %+v", status)
+	}
+	scheduler.Reconfigure(0, []string{usbDrive}, models.BackupRetention{})
+	if status := scheduler.Status(); status.Enabled {
+		t.Errorf("expected the schedule to be disabled, got %+v", status)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("expected the scheduler to stop with its context")
+	}
+}
+
+func TestItemAttributes(t *testing.T) {
+	service, cleanup := setupTestService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	vacuum := &models.Item{
+		Name:     "Aspirateur",
+		Category: "Électroménager",
+		Brand:    "Dyson",
+		Model:    "V8",
+		Attributes: []models.Attribute{
+			{Key: "voltage", Value: "230"}, // Type comes from the category template
+			{Key: "filter_reference", Value: "HF-12"},
+			{Key: "cordless", Type: models.AttributeTypeBoolean, Value: "1"},
+		},
+	}
+	if err := service.CreateItem(ctx, vacuum); err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+
+	kettle := &models.Item{
+		Name:       "Bouilloire",
+		Category:   "Électroménager",
+		Brand:      "Moulinex",
+		Model:      "BY",
+		Attributes: []models.Attribute{{Key: "voltage", Value: "110"}},
+	}
+	if err := service.CreateItem(ctx, kettle); err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+
+	invalid := &models.Item{
+		Name:       "Radiateur",
+		Category:   "Électroménager",
+		Attributes: []models.Attribute{{Key: "voltage", Value: "beaucoup"}},
+	}
+	if err := service.CreateItem(ctx, invalid); err == nil {
+		t.Error("expected a non-numeric voltage to be rejected")
+	}
+
+	item, err := service.GetItem(ctx, vacuum.ID)
+	if err != nil {
+		t.Fatalf("failed to get item: %v", err)
+	}
+
+	if len(item.Attributes) != 3 {
+		t.Fatalf("expected 3 attributes, got %d", len(item.Attributes))
+	}
+
+	for _, attr := range item.Attributes {
+		switch attr.Key {
+		case "voltage":
+			if attr.Type != models.AttributeTypeNumber {
+				t.Errorf("expected voltage to be a number, got %s", attr.Type)
+			}
+		case "cordless":
+			if attr.Value != "true" {
+				t.Errorf("expected cordless to be normalized to true, got %s", attr.Value)
+			}
+		}
+	}
+
+	// Updating without attributes keeps them
+	item.Attributes = nil
+	item.Notes = "Batterie changée"
+	if err := service.UpdateItem(ctx, item); err != nil {
+		t.Fatalf("failed to update item: %v", err)
+	}
+
+	filter, err := services.ParseAttributeFilter("voltage>=220")
+	if err != nil {
+		t.Fatalf("failed to parse filter: %v", err)
+	}
+
+	items, err := service.FilterItems(ctx, models.ItemFilter{Attributes: []models.AttributeFilter{filter}})
+	if err != nil {
+		t.Fatalf("failed to filter items: %v", err)
+	}
+
+	if len(items) != 1 || items[0].ID != vacuum.ID {
+		t.Errorf("expected only the vacuum to match voltage>=220, got %d items", len(items))
+	}
+
+	items, err = service.SearchItems(ctx, "HF-12")
+	if err != nil {
+		t.Fatalf("failed to search items: %v", err)
+	}
+
+	if len(items) != 1 || items[0].ID != vacuum.ID {
+		t.Errorf("expected the search to find the vacuum by its filter reference, got %d items", len(items))
+	}
+}
+
+func TestCategoriesAndTags(t *testing.T) {
+	service, cleanup := setupTestService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	if _, err := service.CreateCategory(ctx, "Maison", ""); err != nil {
+		t.Fatalf("failed to create category: %v", err)
+	}
+	if _, err := service.CreateCategory(ctx, "Électroménager", "Maison"); err != nil {
+		t.Fatalf("failed to create category: %v", err)
+	}
+	if _, err := service.CreateCategory(ctx, "electromenager", ""); err == nil {
+		t.Error("expected a case and accent variant to be refused")
+	}
+	if err := service.AddCategorySynonym(ctx, "Électroménager", "Appliance"); err != nil {
+		t.Fatalf("failed to add synonym: %v", err)
+	}
+
+	// Variants and synonyms resolve to the canonical category
+	vacuum := &models.Item{Name: "Aspirateur", Category: "ELECTROMENAGER", Tags: []string{"garage", " Garage ", "à réviser"}}
+	if err := service.CreateItem(ctx, vacuum); err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+	if vacuum.Category != "Électroménager" {
+		t.Errorf("expected canonical category, got %q", vacuum.Category)
+	}
+	if len(vacuum.Tags) != 2 {
+		t.Errorf("expected duplicate tags to be merged, got %v", vacuum.Tags)
+	}
+
+	kettle := &models.Item{Name: "Bouilloire", Category: "appliance", Tags: []string{"cuisine"}}
+	if err := service.CreateItem(ctx, kettle); err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+	if kettle.Category != "Électroménager" {
+		t.Errorf("expected synonym to resolve, got %q", kettle.Category)
+	}
+
+	// Unknown categories are created at the top level
+	drill := &models.Item{Name: "Perceuse", Category: "Outillage", Tags: []string{"garage"}}
+	if err := service.CreateItem(ctx, drill); err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+
+	items, err := service.FilterItems(ctx, models.ItemFilter{Category: "Maison"})
+	if err != nil {
+		t.Fatalf("failed to filter items: %v", err)
+	}
+	if len(items) != 2 {
+		t.Errorf("expected the Maison subtree to hold 2 items, got %d", len(items))
+	}
+
+	items, err = service.FilterItems(ctx, models.ItemFilter{Tags: []string{"GARAGE"}})
+	if err != nil {
+		t.Fatalf("failed to filter items: %v", err)
+	}
+	if len(items) != 2 {
+		t.Errorf("expected 2 items tagged garage, got %d", len(items))
+	}
+
+	items, err = service.SearchItems(ctx, "Appliance")
+	if err != nil {
+		t.Fatalf("failed to search items: %v", err)
+	}
+	if len(items) != 2 {
+		t.Errorf("expected search by synonym to find 2 items, got %d", len(items))
+	}
+
+	// Merging moves the items and keeps the old name as a synonym
+	if err := service.MergeCategory(ctx, "Outillage", "Maison"); err != nil {
+		t.Fatalf("failed to merge categories: %v", err)
+	}
+
+	item, err := service.GetItem(ctx, drill.ID)
+	if err != nil {
+		t.Fatalf("failed to get item: %v", err)
+	}
+	if item.Category != "Maison" {
+		t.Errorf("expected merged item in Maison, got %q", item.Category)
+	}
+
+	other := &models.Item{Name: "Scie", Category: "outillage"}
+	if err := service.CreateItem(ctx, other); err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+	if other.Category != "Maison" {
+		t.Errorf("expected merged name to resolve to Maison, got %q", other.Category)
+	}
+
+	if err := service.DeleteCategory(ctx, "Maison"); err == nil {
+		t.Error("expected deleting a used category to fail")
+	}
+
+	// Nil tags keep the existing ones on update
+	item.Tags = nil
+	if err := service.UpdateItem(ctx, item); err != nil {
+		t.Fatalf("failed to update item: %v", err)
+	}
+	item, err = service.GetItem(ctx, drill.ID)
+	if err != nil {
+		t.Fatalf("failed to get item: %v", err)
+	}
+	if len(item.Tags) != 1 || item.Tags[0] != "garage" {
+		t.Errorf("expected tags to be kept, got %v", item.Tags)
+	}
+}
+
+func TestProductModels(t *testing.T) {
+	service, cleanup := setupTestService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// Brand and model variants resolve to the same product model
+	mine := &models.Item{Name: "Lave-Linge", Category: "Électroménager", Brand: "Brandt", Model: "WTC 1234"}
+	if err := service.CreateItem(ctx, mine); err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+	theirs := &models.Item{Name: "Lave-linge cave", Category: "Électroménager", Brand: "brandt", Model: "wtc-1234"}
+	if err := service.CreateItem(ctx, theirs); err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+	if mine.ProductModelID == nil || theirs.ProductModelID == nil || *mine.ProductModelID != *theirs.ProductModelID {
+		t.Fatalf("expected both items to share a product model, got %v and %v", mine.ProductModelID, theirs.ProductModelID)
+	}
+
+	noModel := &models.Item{Name: "Tournevis", Category: "Outillage"}
+	if err := service.CreateItem(ctx, noModel); err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+	if noModel.ProductModelID != nil {
+		t.Error("items without brand and model should have no product model")
+	}
+
+	// Documentation added to the model is seen by every item of that model
+	manualFile := filepath.Join(t.TempDir(), "manual.pdf")
+	if err := os.WriteFile(manualFile, []byte("manual"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	if _, err := service.AddProductModelAsset(ctx, *mine.ProductModelID, models.AssetTypeManual, "Manual", manualFile); err != nil {
+		t.Fatalf("failed to add product model asset: %v", err)
+	}
+
+	serviceFile := filepath.Join(t.TempDir(), "service.pdf")
+	if err := os.WriteFile(serviceFile, []byte("service manual"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	asset, err := service.AddAsset(ctx, mine.ID, models.AssetTypeServiceManual, "Service Manual", serviceFile)
+	if err != nil {
+		t.Fatalf("failed to add asset: %v", err)
+	}
+
+	withAssets, err := service.GetItemWithAssets(ctx, theirs.ID)
+	if err != nil {
+		t.Fatalf("failed to get item with assets: %v", err)
+	}
+	if len(withAssets.Assets) != 1 || withAssets.Health != models.HealthPartial {
+		t.Errorf("expected the shared manual only, got %d assets and health %s", len(withAssets.Assets), withAssets.Health)
+	}
+
+	// Sharing an item asset moves it to the model
+	if _, err := service.ShareAsset(ctx, asset.ID); err != nil {
+		t.Fatalf("failed to share asset: %v", err)
+	}
+
+	withAssets, err = service.GetItemWithAssets(ctx, theirs.ID)
+	if err != nil {
+		t.Fatalf("failed to get item with assets: %v", err)
+	}
+	if withAssets.Health != models.HealthSecured {
+		t.Errorf("expected shared documentation to secure the item, got %s", withAssets.Health)
+	}
+
+	// Deleting an item keeps the documentation of its model
+	if err := service.DeleteItem(ctx, mine.ID); err != nil {
+		t.Fatalf("failed to delete item: %v", err)
+	}
+	productModel, err := service.GetProductModel(ctx, *theirs.ProductModelID)
+	if err != nil {
+		t.Fatalf("failed to get product model: %v", err)
+	}
+	if len(productModel.Assets) != 2 || productModel.ItemCount != 1 {
+		t.Errorf("expected 2 shared assets and 1 item, got %d and %d", len(productModel.Assets), productModel.ItemCount)
+	}
+
+	// A rebadged model added as an alias is merged with its documentation
+	rebadged := &models.Item{Name: "Lave-linge location", Brand: "Vedette", Model: "VLT1234"}
+	if err := service.CreateItem(ctx, rebadged); err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+	if err := service.AddProductModelAlias(ctx, *theirs.ProductModelID, "Vedette", "VLT 1234"); err != nil {
+		t.Fatalf("failed to add alias: %v", err)
+	}
+
+	rebadgedAssets, err := service.GetItemAssets(ctx, rebadged.ID)
+	if err != nil {
+		t.Fatalf("failed to get item assets: %v", err)
+	}
+	if len(rebadgedAssets) != 2 {
+		t.Errorf("expected the merged model documentation, got %d assets", len(rebadgedAssets))
+	}
+
+	productModels, err := service.GetProductModels(ctx)
+	if err != nil {
+		t.Fatalf("failed to get product models: %v", err)
+	}
+	if len(productModels) != 1 || len(productModels[0].Aliases) != 1 {
+		t.Errorf("expected 1 product model with 1 alias, got %+v", productModels)
+	}
+
+	// New items named after the alias are linked to the model
+	another := &models.Item{Name: "Lave-linge atelier", Brand: "VEDETTE", Model: "vlt1234"}
+	if err := service.CreateItem(ctx, another); err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+	if another.ProductModelID == nil || *another.ProductModelID != *theirs.ProductModelID {
+		t.Errorf("expected the alias to resolve to the product model, got %v", another.ProductModelID)
+	}
+}
+
+func TestRepairLog(t *testing.T) {
+	service, cleanup := setupTestService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	item := &models.Item{Name: "Lave-Linge", Category: "Électroménager", Brand: "Brandt", Model: "WTC1234"}
+	if err := service.CreateItem(ctx, item); err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+	other := &models.Item{Name: "Aspirateur", Category: "Électroménager"}
+	if err := service.CreateItem(ctx, other); err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+
+	schematicFile := filepath.Join(t.TempDir(), "belt.pdf")
+	if err := os.WriteFile(schematicFile, []byte("schematic"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	schematic, err := service.AddAsset(ctx, item.ID, models.AssetTypeSchematic, "Schéma courroie", schematicFile)
+	if err != nil {
+		t.Fatalf("failed to add asset: %v", err)
+	}
+	otherFile := filepath.Join(t.TempDir(), "other.pdf")
+	if err := os.WriteFile(otherFile, []byte("other"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	otherAsset, err := service.AddAsset(ctx, other.ID, models.AssetTypeManual, "Manual", otherFile)
+	if err != nil {
+		t.Fatalf("failed to add asset: %v", err)
+	}
+
+	// Events need a diagnosis or an action, and assets of the repaired item
+	if err := service.AddRepairEvent(ctx, &models.RepairEvent{ItemID: item.ID}); err == nil {
+		t.Error("expected an empty repair event to be rejected")
+	}
+	if err := service.AddRepairEvent(ctx, &models.RepairEvent{ItemID: item.ID, Action: "Nettoyage", AssetIDs: []int64{otherAsset.ID}}); err == nil {
+		t.Error("expected an asset of another item to be rejected")
+	}
+
+	older := &models.RepairEvent{
+		ItemID:      item.ID,
+		PerformedAt: time.Now().AddDate(-1, 0, 0),
+		Diagnosis:   "Fuite",
+		Action:      "Joint de hublot remplacé",
+	}
+	if err := service.AddRepairEvent(ctx, older); err != nil {
+		t.Fatalf("failed to add repair event: %v", err)
+	}
+
+	recent := &models.RepairEvent{
+		ItemID:        item.ID,
+		Diagnosis:     "Tambour bloqué",
+		Action:        "Courroie remplacée",
+		PartsReplaced: "Courroie 1195 H7",
+		CostCents:     1250,
+		PerformedBy:   "Repair Café",
+		AssetIDs:      []int64{schematic.ID, schematic.ID},
+	}
+	if err := service.AddRepairEvent(ctx, recent); err != nil {
+		t.Fatalf("failed to add repair event: %v", err)
+	}
+	if recent.UID == "" || len(recent.AssetIDs) != 1 {
+		t.Errorf("expected a UID and 1 linked asset, got %q and %v", recent.UID, recent.AssetIDs)
+	}
+
+	repairs, err := service.GetItemRepairs(ctx, item.ID)
+	if err != nil {
+		t.Fatalf("failed to get repairs: %v", err)
+	}
+	if len(repairs) != 2 || repairs[0].ID != recent.ID || len(repairs[0].AssetIDs) != 1 {
+		t.Fatalf("expected 2 repairs, most recent first with its asset, got %+v", repairs)
+	}
+
+	// Updating without assets keeps the links
+	update := *recent
+	update.AssetIDs = nil
+	update.CostCents = 1500
+	if err := service.UpdateRepairEvent(ctx, &update); err != nil {
+		t.Fatalf("failed to update repair event: %v", err)
+	}
+	updated, err := service.GetRepairEvent(ctx, recent.ID)
+	if err != nil {
+		t.Fatalf("failed to get repair event: %v", err)
+	}
+	if updated.CostCents != 1500 || len(updated.AssetIDs) != 1 {
+		t.Errorf("expected the new cost and the kept asset, got %d and %v", updated.CostCents, updated.AssetIDs)
+	}
+
+	// The history travels with the item to another instance, by UID
+	shared, err := service.GetItem(ctx, item.ID)
+	if err != nil {
+		t.Fatalf("failed to get item: %v", err)
+	}
+	shared.Repairs = repairs
+
+	peerDB, err := db.NewDatabase(filepath.Join(t.TempDir(), "peer.db"), slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError})))
+	if err != nil {
+		t.Fatalf("failed to initialize database: %v", err)
+	}
+	defer peerDB.Close()
+
+	gossip := services.NewGossipService(peerDB, "repair-cafe", ":0")
+	peerBackpack := services.NewBackpackService(peerDB, t.TempDir())
+	if err := gossip.AddPeer(ctx, &models.Peer{ID: "home", Name: "home", Address: "127.0.0.1:1", IsTrusted: true}); err != nil {
+		t.Fatalf("failed to add peer: %v", err)
+	}
+
+	if _, err := gossip.SyncWithPeer(ctx, "home", []models.Item{*shared}); err != nil {
+		t.Fatalf("failed to sync: %v", err)
+	}
+	received, err := peerBackpack.GetItemRepairs(ctx, item.ID)
+	if err != nil {
+		t.Fatalf("failed to get repairs: %v", err)
+	}
+	if len(received) != 2 || received[0].UID != recent.UID || len(received[0].AssetIDs) != 0 {
+		t.Fatalf("expected the 2 repairs without local asset links, got %+v", received)
+	}
+
+	// Repairs are merged even when the peer's version of the item is newer
+	if err := peerBackpack.AddRepairEvent(ctx, &models.RepairEvent{ItemID: item.ID, Action: "Contrôle annuel"}); err != nil {
+		t.Fatalf("failed to add repair event: %v", err)
+	}
+	shared.Repairs = []models.RepairEvent{*updated}
+	result, err := gossip.SyncWithPeer(ctx, "home", []models.Item{*shared})
+	if err != nil {
+		t.Fatalf("failed to sync: %v", err)
+	}
+	if result.Conflicts != 1 {
+		t.Errorf("expected the item itself to conflict, got %d conflicts", result.Conflicts)
+	}
+	received, err = peerBackpack.GetItemRepairs(ctx, item.ID)
+	if err != nil {
+		t.Fatalf("failed to get repairs: %v", err)
+	}
+	if len(received) != 3 {
+		t.Fatalf("expected 3 repairs, got %d", len(received))
+	}
+	for _, repair := range received {
+		if repair.UID == recent.UID && repair.CostCents != 1500 {
+			t.Errorf("expected the updated cost to be merged, got %d", repair.CostCents)
+		}
+	}
+
+	// Deleting an event removes it from the history
+	if err := service.DeleteRepairEvent(ctx, older.ID); err != nil {
+		t.Fatalf("failed to delete repair event: %v", err)
+	}
+	repairs, err = service.GetItemRepairs(ctx, item.ID)
+	if err != nil {
+		t.Fatalf("failed to get repairs: %v", err)
+	}
+	if len(repairs) != 1 {
+		t.Errorf("expected 1 repair left, got %d", len(repairs))
+	}
+}
+
+func TestMaintenanceSchedules(t *testing.T) {
+	service, cleanup := setupTestService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	item := &models.Item{Name: "Cafetière", Category: "Petit Électroménager"}
+	if err := service.CreateItem(ctx, item); err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+
+	if err := service.AddMaintenanceSchedule(ctx, &models.MaintenanceSchedule{ItemID: item.ID, Task: "Détartrage"}); err == nil {
+		t.Error("expected a schedule without interval to be rejected")
+	}
+
+	descaling := &models.MaintenanceSchedule{ItemID: item.ID, Task: "Détartrage", IntervalDays: 90}
+	if err := service.AddMaintenanceSchedule(ctx, descaling); err != nil {
+		t.Fatalf("failed to add maintenance schedule: %v", err)
+	}
+	cleaning := &models.MaintenanceSchedule{ItemID: item.ID, Task: "Nettoyage du réservoir", IntervalDays: 20}
+	if err := service.AddMaintenanceSchedule(ctx, cleaning); err != nil {
+		t.Fatalf("failed to add maintenance schedule: %v", err)
+	}
+
+	// Usage-based schedules count from the current counter
+	if err := service.RecordUsage(ctx, item.ID, "Hours", 100); err != nil {
+		t.Fatalf("failed to record usage: %v", err)
+	}
+	filter := &models.MaintenanceSchedule{ItemID: item.ID, Task: "Changer le filtre", IntervalUsage: 500}
+	if err := service.AddMaintenanceSchedule(ctx, filter); err != nil {
+		t.Fatalf("failed to add maintenance schedule: %v", err)
+	}
+	if filter.UsageUnit != "hours" || filter.LastDoneUsage != 100 {
+		t.Errorf("expected the filter to count from 100 hours, got %d %s", filter.LastDoneUsage, filter.UsageUnit)
+	}
+
+	due, err := service.GetDueMaintenance(ctx, 0)
+	if err != nil {
+		t.Fatalf("failed to get due maintenance: %v", err)
+	}
+	if len(due) != 0 {
+		t.Fatalf("expected nothing due yet, got %+v", due)
+	}
+
+	// Descaling last done 100 days ago, and the filter used for 550 hours
+	if _, err := service.CompleteMaintenance(ctx, descaling.ID, time.Now().AddDate(0, 0, -100)); err != nil {
+		t.Fatalf("failed to complete maintenance: %v", err)
+	}
+	if err := service.RecordUsage(ctx, item.ID, "hours", 650); err != nil {
+		t.Fatalf("failed to record usage: %v", err)
+	}
+
+	due, err = service.GetDueMaintenance(ctx, 0)
+	if err != nil {
+		t.Fatalf("failed to get due maintenance: %v", err)
+	}
+	if len(due) != 2 || due[0].Schedule.ID != descaling.ID || due[1].Schedule.ID != filter.ID || due[0].ItemName != item.Name {
+		t.Fatalf("expected descaling then filter to be due, got %+v", due)
+	}
+
+	soon, err := service.GetDueMaintenance(ctx, 30*24*time.Hour)
+	if err != nil {
+		t.Fatalf("failed to get due maintenance: %v", err)
+	}
+	if len(soon) != 3 || soon[2].Schedule.ID != cleaning.ID || soon[2].Due {
+		t.Errorf("expected the cleaning to be due soon, got %+v", soon)
+	}
+
+	// Doing the task moves its next due point
+	task, err := service.CompleteMaintenance(ctx, filter.ID, time.Time{})
+	if err != nil {
+		t.Fatalf("failed to complete maintenance: %v", err)
+	}
+	if task.Due || task.NextDueUsage == nil || *task.NextDueUsage != 1150 {
+		t.Errorf("expected the filter to be due at 1150 hours, got %+v", task)
+	}
+
+	tasks, err := service.GetItemMaintenance(ctx, item.ID)
+	if err != nil {
+		t.Fatalf("failed to get item maintenance: %v", err)
+	}
+	if len(tasks) != 3 {
+		t.Errorf("expected 3 schedules, got %d", len(tasks))
+	}
+}
+
+func TestSpareParts(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	database, err := db.NewDatabase(filepath.Join(t.TempDir(), "home.db"), logger)
+	if err != nil {
+		t.Fatalf("failed to initialize database: %v", err)
+	}
+	defer database.Close()
+
+	service := services.NewBackpackService(database, t.TempDir())
+	ctx := context.Background()
+
+	washer := &models.Item{Name: "Lave-Linge", Category: "Électroménager", Brand: "Brandt", Model: "WTC1234"}
+	if err := service.CreateItem(ctx, washer); err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+	if washer.ProductModelID == nil {
+		t.Fatal("expected the item to get a product model")
+	}
+	dryer := &models.Item{Name: "Sèche-Linge", Category: "Électroménager"}
+	if err := service.CreateItem(ctx, dryer); err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+
+	stlFile := filepath.Join(t.TempDir(), "knob.stl")
+	if err := os.WriteFile(stlFile, []byte("solid knob"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	stl, err := service.AddAsset(ctx, washer.ID, models.AssetTypeSTL, "Bouton", stlFile)
+	if err != nil {
+		t.Fatalf("failed to add asset: %v", err)
+	}
+	manualFile := filepath.Join(t.TempDir(), "manual.pdf")
+	if err := os.WriteFile(manualFile, []byte("manual"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	manual, err := service.AddAsset(ctx, washer.ID, models.AssetTypeManual, "Manual", manualFile)
+	if err != nil {
+		t.Fatalf("failed to add asset: %v", err)
+	}
+
+	// Only STL files can be attached to a part
+	if err := service.CreatePart(ctx, &models.Part{Name: "Bouton", AssetIDs: []int64{manual.ID}}); err == nil {
+		t.Error("expected a non-STL asset to be rejected")
+	}
+
+	belt := &models.Part{
+		Name:            "Courroie",
+		Reference:       "1195 H7",
+		Quantity:        1,
+		MinQuantity:     1,
+		Location:        "Tiroir 3",
+		ProductModelIDs: []int64{*washer.ProductModelID},
+	}
+	if err := service.CreatePart(ctx, belt); err != nil {
+		t.Fatalf("failed to create part: %v", err)
+	}
+	if err := service.CreatePart(ctx, &models.Part{Name: "Courroie plate", Reference: "1195-h7"}); err == nil {
+		t.Error("expected a part with the same reference to be rejected")
+	}
+
+	knob := &models.Part{Name: "Bouton", ItemIDs: []int64{dryer.ID}, AssetIDs: []int64{stl.ID}}
+	if err := service.CreatePart(ctx, knob); err != nil {
+		t.Fatalf("failed to create part: %v", err)
+	}
+	if len(knob.AssetIDs) != 1 || len(knob.ProductModelIDs) != 0 {
+		t.Errorf("expected 1 STL file and no product model, got %+v", knob)
+	}
+
+	// Parts fit items directly or through their product model
+	parts, err := service.GetItemParts(ctx, washer.ID)
+	if err != nil {
+		t.Fatalf("failed to get item parts: %v", err)
+	}
+	if len(parts) != 1 || parts[0].ID != belt.ID {
+		t.Errorf("expected the belt to fit the washer, got %+v", parts)
+	}
+	parts, err = service.GetItemParts(ctx, dryer.ID)
+	if err != nil {
+		t.Fatalf("failed to get item parts: %v", err)
+	}
+	if len(parts) != 1 || parts[0].ID != knob.ID {
+		t.Errorf("expected the knob to fit the dryer, got %+v", parts)
+	}
+
+	// Using the last belt brings it below its minimum
+	if _, err := service.AdjustPartStock(ctx, belt.ID, -2); err == nil {
+		t.Error("expected the stock to stay positive")
+	}
+	used, err := service.AdjustPartStock(ctx, belt.ID, -1)
+	if err != nil {
+		t.Fatalf("failed to adjust stock: %v", err)
+	}
+	if used.Quantity != 0 {
+		t.Errorf("expected 0 belts left, got %d", used.Quantity)
+	}
+	low, err := service.GetLowStockParts(ctx)
+	if err != nil {
+		t.Fatalf("failed to get low stock parts: %v", err)
+	}
+	if len(low) != 1 || low[0].ID != belt.ID {
+		t.Errorf("expected the belt to be low on stock, got %+v", low)
+	}
+
+	// Updating without links keeps them
+	update := *belt
+	update.ItemIDs, update.ProductModelIDs, update.AssetIDs = nil, nil, nil
+	update.Location = "Atelier"
+	if err := service.UpdatePart(ctx, &update); err != nil {
+		t.Fatalf("failed to update part: %v", err)
+	}
+	if update.Location != "Atelier" || update.Quantity != belt.Quantity || len(update.ProductModelIDs) != 1 {
+		t.Errorf("expected the new location and the kept product model, got %+v", update)
+	}
+
+	// Only the product model compatibilities are shared with peers
+	peerDB, err := db.NewDatabase(filepath.Join(t.TempDir(), "peer.db"), logger)
+	if err != nil {
+		t.Fatalf("failed to initialize database: %v", err)
+	}
+	defer peerDB.Close()
+
+	shared, err := services.NewGossipService(database, "home", ":0").GetSharedParts(ctx)
+	if err != nil {
+		t.Fatalf("failed to get shared parts: %v", err)
+	}
+	if len(shared) != 1 || shared[0].Reference != "1195 H7" {
+		t.Fatalf("expected only the belt to be shared, got %+v", shared)
+	}
+
+	gossip := services.NewGossipService(peerDB, "repair-cafe", ":0")
+	peerBackpack := services.NewBackpackService(peerDB, t.TempDir())
+	learnt, err := gossip.ApplySharedParts(ctx, shared)
+	if err != nil {
+		t.Fatalf("failed to apply shared parts: %v", err)
+	}
+	if learnt != 1 {
+		t.Errorf("expected 1 compatibility learnt, got %d", learnt)
+	}
+	if learnt, _ := gossip.ApplySharedParts(ctx, shared); learnt != 0 {
+		t.Errorf("expected nothing new on the second sync, got %d", learnt)
+	}
+
+	peerWasher := &models.Item{Name: "Machine", Brand: "brandt", Model: "WTC 1234"}
+	if err := peerBackpack.CreateItem(ctx, peerWasher); err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+	parts, err = peerBackpack.GetItemParts(ctx, peerWasher.ID)
+	if err != nil {
+		t.Fatalf("failed to get item parts: %v", err)
+	}
+	if len(parts) != 1 || parts[0].Quantity != 0 || parts[0].Location != "" {
+		t.Errorf("expected the belt to fit without local stock, got %+v", parts)
+	}
+	low, err = peerBackpack.GetLowStockParts(ctx)
+	if err != nil {
+		t.Fatalf("failed to get low stock parts: %v", err)
+	}
+	if len(low) != 0 {
+		t.Errorf("expected learnt parts not to be low on stock, got %+v", low)
+	}
+}
+
+func TestWarranties(t *testing.T) {
+	service, cleanup := setupTestService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	purchased := time.Now().AddDate(-2, 1, 0)
+	fridge := &models.Item{
+		Name:         "Réfrigérateur",
+		Category:     "Électroménager",
+		PurchaseDate: &purchased,
+		Warranty:     &models.Warranty{DurationMonths: 24, Provider: "Darty"},
+	}
+	if err := service.CreateItem(ctx, fridge); err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+	if fridge.Warranty.ExpiresAt == nil || *fridge.Warranty.DaysLeft < 27 || *fridge.Warranty.DaysLeft > 31 {
+		t.Errorf("expected the warranty to expire in about a month, got %+v", fridge.Warranty)
+	}
+
+	oldPurchase := time.Now().AddDate(-3, 0, 0)
+	kettle := &models.Item{Name: "Bouilloire", Category: "Électroménager", PurchaseDate: &oldPurchase}
+	if err := service.CreateItem(ctx, kettle); err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+	if err := service.SetItemWarranty(ctx, kettle.ID, &models.Warranty{DurationMonths: 24}); err != nil {
+		t.Fatalf("failed to set warranty: %v", err)
+	}
+
+	drill := &models.Item{Name: "Perceuse", Category: "Outillage"}
+	if err := service.CreateItem(ctx, drill); err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+	drillWarranty := &models.Warranty{DurationMonths: 36}
+	if err := service.SetItemWarranty(ctx, drill.ID, drillWarranty); err != nil {
+		t.Fatalf("failed to set warranty: %v", err)
+	}
+	if drillWarranty.ExpiresAt != nil {
+		t.Errorf("expected no expiry without a purchase date, got %v", drillWarranty.ExpiresAt)
+	}
+
+	// The receipt must be an asset of the item
+	receiptFile := filepath.Join(t.TempDir(), "facture.pdf")
+	if err := os.WriteFile(receiptFile, []byte("receipt"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	receipt, err := service.AddAsset(ctx, fridge.ID, models.AssetTypeOther, "Facture", receiptFile)
+	if err != nil {
+		t.Fatalf("failed to add asset: %v", err)
+	}
+	if err := service.SetItemWarranty(ctx, drill.ID, &models.Warranty{DurationMonths: 36, ReceiptAssetID: &receipt.ID}); err == nil {
+		t.Error("expected the receipt of another item to be rejected")
+	}
+	if err := service.SetItemWarranty(ctx, fridge.ID, &models.Warranty{DurationMonths: 24, Provider: "Darty", ReceiptAssetID: &receipt.ID}); err != nil {
+		t.Fatalf("failed to set warranty: %v", err)
+	}
+
+	// The remaining warranty is part of the item
+	withAssets, err := service.GetItemWithAssets(ctx, fridge.ID)
+	if err != nil {
+		t.Fatalf("failed to get item: %v", err)
+	}
+	warranty := withAssets.Item.Warranty
+	if warranty == nil || warranty.Provider != "Darty" || warranty.ReceiptAssetID == nil || *warranty.ReceiptAssetID != receipt.ID || warranty.DaysLeft == nil {
+		t.Fatalf("expected the warranty with its receipt and days left, got %+v", warranty)
+	}
+
+	// Only warranties still running out within the window are listed
+	expiring, err := service.GetExpiringWarranties(ctx, 60*24*time.Hour)
+	if err != nil {
+		t.Fatalf("failed to get expiring warranties: %v", err)
+	}
+	if len(expiring) != 1 || expiring[0].ID != fridge.ID || expiring[0].Warranty == nil {
+		t.Fatalf("expected only the fridge, got %+v", expiring)
+	}
+	expiring, err = service.GetExpiringWarranties(ctx, 7*24*time.Hour)
+	if err != nil {
+		t.Fatalf("failed to get expiring warranties: %v", err)
+	}
+	if len(expiring) != 0 {
+		t.Errorf("expected nothing expiring within a week, got %+v", expiring)
+	}
+
+	// Updating an item without a warranty keeps it, a zero duration removes it
+	fridge.Warranty = nil
+	fridge.Notes = "Joint changé"
+	if err := service.UpdateItem(ctx, fridge); err != nil {
+		t.Fatalf("failed to update item: %v", err)
+	}
+	kept, err := service.GetItem(ctx, fridge.ID)
+	if err != nil {
+		t.Fatalf("failed to get item: %v", err)
+	}
+	if kept.Warranty == nil {
+		t.Error("expected the warranty to be kept")
+	}
+
+	kept.Warranty = &models.Warranty{}
+	if err := service.UpdateItem(ctx, kept); err != nil {
+		t.Fatalf("failed to update item: %v", err)
+	}
+	removed, err := service.GetItem(ctx, fridge.ID)
+	if err != nil {
+		t.Fatalf("failed to get item: %v", err)
+	}
+	if removed.Warranty != nil {
+		t.Errorf("expected the warranty to be removed, got %+v", removed.Warranty)
+	}
+}
+
+func TestAnnouncements(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	newInstance := func(name string) *services.GossipService {
+		database, err := db.NewDatabase(filepath.Join(t.TempDir(), name+".db"), logger)
+		if err != nil {
+			t.Fatalf("failed to initialize database: %v", err)
+		}
+		t.Cleanup(func() { database.Close() })
+		return services.NewGossipService(database, name, ":0")
+	}
+	home, neighbour, village := newInstance("home"), newInstance("neighbour"), newInstance("village")
+
+	if err := home.PublishAnnouncement(ctx, &models.Announcement{Kind: "sale", Title: "Vélo"}, 0); err == nil {
+		t.Error("expected an unknown kind to be rejected")
+	}
+	if err := home.PublishAnnouncement(ctx, &models.Announcement{Kind: models.AnnouncementOffer, Title: "Vélo"}, 365*24*time.Hour); err == nil {
+		t.Error("expected a too long TTL to be rejected")
+	}
+
+	drill := &models.Announcement{Kind: models.AnnouncementOffer, Title: "Je prête une perceuse", MaxHops: 2}
+	if err := home.PublishAnnouncement(ctx, drill, 0); err != nil {
+		t.Fatalf("failed to publish announcement: %v", err)
+	}
+	welder := &models.Announcement{Kind: models.AnnouncementRequest, Title: "Je cherche un soudeur", MaxHops: 1}
+	if err := home.PublishAnnouncement(ctx, welder, 0); err != nil {
+		t.Fatalf("failed to publish announcement: %v", err)
+	}
+	if !drill.Own || drill.Signature == "" || drill.ExpiresAt.Sub(drill.CreatedAt) != services.DefaultAnnouncementTTL {
+		t.Errorf("expected a signed announcement with the default TTL, got %+v", drill)
+	}
+
+	// Announcements spread hop by hop, up to their limit
+	shared, err := home.GetSharedAnnouncements(ctx)
+	if err != nil {
+		t.Fatalf("failed to get shared announcements: %v", err)
+	}
+	received, err := neighbour.ApplyAnnouncements(ctx, "home", shared)
+	if err != nil {
+		t.Fatalf("failed to apply announcements: %v", err)
+	}
+	if received != 2 {
+		t.Errorf("expected 2 announcements received, got %d", received)
+	}
+
+	shared, err = neighbour.GetSharedAnnouncements(ctx)
+	if err != nil {
+		t.Fatalf("failed to get shared announcements: %v", err)
+	}
+	if len(shared) != 1 || shared[0].UID != drill.UID || shared[0].Hops != 1 {
+		t.Fatalf("expected only the drill to go further, got %+v", shared)
+	}
+	if _, err := village.ApplyAnnouncements(ctx, "neighbour", shared); err != nil {
+		t.Fatalf("failed to apply announcements: %v", err)
+	}
+	offers, err := village.GetAnnouncements(ctx, models.AnnouncementOffer)
+	if err != nil {
+		t.Fatalf("failed to get announcements: %v", err)
+	}
+	if len(offers) != 1 || offers[0].Hops != 2 || offers[0].AuthorName != "home" || offers[0].ReceivedFrom != "neighbour" || offers[0].Own {
+		t.Fatalf("expected the drill 2 hops away, got %+v", offers)
+	}
+	if shared, _ := village.GetSharedAnnouncements(ctx); len(shared) != 0 {
+		t.Errorf("expected the drill to stop at its hop limit, got %+v", shared)
+	}
+
+	// Tampered announcements are dropped, and only the author may update one
+	forged := offers[0]
+	forged.Hops = 0
+	forged.Title = "Je donne une perceuse"
+	forged.UpdatedAt = forged.UpdatedAt.Add(time.Hour)
+	if _, err := neighbour.ApplyAnnouncements(ctx, "village", []models.Announcement{forged}); err != nil {
+		t.Fatalf("failed to apply announcements: %v", err)
+	}
+	offers, err = neighbour.GetAnnouncements(ctx, models.AnnouncementOffer)
+	if err != nil {
+		t.Fatalf("failed to get announcements: %v", err)
+	}
+	if len(offers) != 1 || offers[0].Title != drill.Title || offers[0].ReceivedFrom != "home" {
+		t.Errorf("expected the forged announcement to be dropped, got %+v", offers)
+	}
+	villageOffers, err := village.GetAnnouncements(ctx, models.AnnouncementOffer)
+	if err != nil {
+		t.Fatalf("failed to get announcements: %v", err)
+	}
+	if err := village.WithdrawAnnouncement(ctx, villageOffers[0].ID); err == nil {
+		t.Error("expected only the author to be able to withdraw")
+	}
+
+	// The withdrawal spreads like the announcement did
+	if err := home.WithdrawAnnouncement(ctx, drill.ID); err != nil {
+		t.Fatalf("failed to withdraw announcement: %v", err)
+	}
+	shared, err = home.GetSharedAnnouncements(ctx)
+	if err != nil {
+		t.Fatalf("failed to get shared announcements: %v", err)
+	}
+	if _, err := neighbour.ApplyAnnouncements(ctx, "home", shared); err != nil {
+		t.Fatalf("failed to apply announcements: %v", err)
+	}
+	running, err := neighbour.GetAnnouncements(ctx, "")
+	if err != nil {
+		t.Fatalf("failed to get announcements: %v", err)
+	}
+	if len(running) != 1 || running[0].UID != welder.UID {
+		t.Errorf("expected only the welder request left, got %+v", running)
+	}
+}
+
+func TestLoans(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	newDatabase := func(name string) *db.Database {
+		database, err := db.NewDatabase(filepath.Join(t.TempDir(), name+".db"), logger)
+		if err != nil {
+			t.Fatalf("failed to initialize database: %v", err)
+		}
+		t.Cleanup(func() { database.Close() })
+		return database
+	}
+	homeDB, neighbourDB := newDatabase("home"), newDatabase("neighbour")
+	service := services.NewBackpackService(homeDB, t.TempDir())
+	home := services.NewGossipService(homeDB, "home", ":0")
+	neighbourService := services.NewBackpackService(neighbourDB, t.TempDir())
+	neighbour := services.NewGossipService(neighbourDB, "neighbour", ":0")
+
+	if err := home.AddPeer(ctx, &models.Peer{ID: "neighbour-peer", Name: "neighbour", Address: "127.0.0.1:1"}); err != nil {
+		t.Fatalf("failed to add peer: %v", err)
+	}
+	if err := neighbour.AddPeer(ctx, &models.Peer{ID: "home-peer", Name: "home", Address: "127.0.0.1:1"}); err != nil {
+		t.Fatalf("failed to add peer: %v", err)
+	}
+
+	drill := &models.Item{Name: "Perceuse", Category: "Outillage"}
+	if err := service.CreateItem(ctx, drill); err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+	ladder := &models.Item{Name: "Échelle", Category: "Outillage"}
+	if err := service.CreateItem(ctx, ladder); err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+
+	if err := service.LendItem(ctx, &models.Loan{ItemID: &drill.ID}); err == nil {
+		t.Error("expected a loan without borrower to be rejected")
+	}
+	lentAt := time.Now().AddDate(0, 0, -10)
+	dueAt := lentAt.AddDate(0, 0, 7)
+	drillLoan := &models.Loan{ItemID: &drill.ID, BorrowerPeerID: "neighbour-peer", LentAt: lentAt, DueAt: &dueAt}
+	if err := service.LendItem(ctx, drillLoan); err != nil {
+		t.Fatalf("failed to lend item: %v", err)
+	}
+	if drillLoan.BorrowerName != "neighbour" || drillLoan.Direction != models.LoanOut || !drillLoan.Overdue {
+		t.Errorf("expected an overdue loan to the neighbour, got %+v", drillLoan)
+	}
+	if err := service.LendItem(ctx, &models.Loan{ItemID: &drill.ID, BorrowerName: "Paul"}); err == nil {
+		t.Error("expected an item already lent to be rejected")
+	}
+	ladderLoan := &models.Loan{ItemID: &ladder.ID, BorrowerName: "Paul"}
+	if err := service.LendItem(ctx, ladderLoan); err != nil {
+		t.Fatalf("failed to lend item: %v", err)
+	}
+
+	item, err := service.GetItem(ctx, drill.ID)
+	if err != nil {
+		t.Fatalf("failed to get item: %v", err)
+	}
+	if item.Loan == nil || item.Loan.ID != drillLoan.ID {
+		t.Errorf("expected the item to carry its current loan, got %+v", item.Loan)
+	}
+
+	overdue, err := service.GetOverdueLoans(ctx)
+	if err != nil {
+		t.Fatalf("failed to get overdue loans: %v", err)
+	}
+	if len(overdue) != 1 || overdue[0].ID != drillLoan.ID {
+		t.Errorf("expected the drill loan to be overdue, got %+v", overdue)
+	}
+
+	// The instances learn the key of each other on sync
+	homeInfo, err := home.GetInstanceInfo(ctx)
+	if err != nil {
+		t.Fatalf("failed to get instance info: %v", err)
+	}
+	neighbourInfo, err := neighbour.GetInstanceInfo(ctx)
+	if err != nil {
+		t.Fatalf("failed to get instance info: %v", err)
+	}
+	if err := home.UpdatePeerKey(ctx, "neighbour-peer", neighbourInfo.PublicKey); err != nil {
+		t.Fatalf("failed to update peer key: %v", err)
+	}
+
+	// Only the loans made to the borrower's instance are shared with it, on
+	// a request it signed
+	request, err := neighbour.NewLoansRequest(ctx, homeInfo.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to sign loans request: %v", err)
+	}
+	shared, err := home.GetSharedLoans(ctx, services.ParseLoansRequest(request.Query()))
+	if err != nil {
+		t.Fatalf("failed to get shared loans: %v", err)
+	}
+	if len(shared) != 1 || shared[0].UID != drillLoan.UID || shared[0].ItemID != nil {
+		t.Fatalf("expected only the drill loan to be shared, got %+v", shared)
+	}
+	received, err := neighbour.ApplyLoans(ctx, "home-peer", shared)
+	if err != nil {
+		t.Fatalf("failed to apply loans: %v", err)
+	}
+	if received != 1 {
+		t.Errorf("expected 1 loan received, got %d", received)
+	}
+
+	borrowed, err := neighbourService.GetOverdueLoans(ctx)
+	if err != nil {
+		t.Fatalf("failed to get overdue loans: %v", err)
+	}
+	if len(borrowed) != 1 || borrowed[0].Direction != models.LoanIn || borrowed[0].LenderName != "home" || borrowed[0].ItemName != "Perceuse" {
+		t.Fatalf("expected the drill to be borrowed from home, got %+v", borrowed)
+	}
+	if _, err := neighbourService.ReturnLoan(ctx, borrowed[0].ID, time.Time{}); err == nil {
+		t.Error("expected the borrower not to be able to record the return")
+	}
+
+	// Another instance cannot ask for the loans of the borrower
+	stranger := services.NewGossipService(newDatabase("stranger"), "neighbour", ":0")
+	forged, err := stranger.NewLoansRequest(ctx, homeInfo.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to sign loans request: %v", err)
+	}
+	if _, err := home.GetSharedLoans(ctx, *forged); !errors.Is(err, services.ErrUnknownBorrower) {
+		t.Errorf("expected a request from an unknown key to be rejected, got %v", err)
+	}
+	forged.BorrowerKey = neighbourInfo.PublicKey
+	if _, err := home.GetSharedLoans(ctx, *forged); !errors.Is(err, services.ErrUnknownBorrower) {
+		t.Errorf("expected a request signed by another key to be rejected, got %v", err)
+	}
+	stale := *request
+	stale.SignedAt = stale.SignedAt.Add(-time.Hour)
+	if _, err := home.GetSharedLoans(ctx, stale); !errors.Is(err, services.ErrUnknownBorrower) {
+		t.Errorf("expected an old request to be rejected, got %v", err)
+	}
+	if _, err := neighbour.GetSharedLoans(ctx, *request); !errors.Is(err, services.ErrUnknownBorrower) {
+		t.Errorf("expected a request signed for another lender to be rejected, got %v", err)
+	}
+
+	// The return reaches the borrower on the next sync
+	if _, err := service.ReturnLoan(ctx, drillLoan.ID, time.Time{}); err != nil {
+		t.Fatalf("failed to return loan: %v", err)
+	}
+	if _, err := service.ReturnLoan(ctx, drillLoan.ID, time.Time{}); err == nil {
+		t.Error("expected a loan to be returned only once")
+	}
+	shared, err = home.GetSharedLoans(ctx, *request)
+	if err != nil {
+		t.Fatalf("failed to get shared loans: %v", err)
+	}
+	if received, err := neighbour.ApplyLoans(ctx, "home-peer", shared); err != nil || received != 0 {
+		t.Fatalf("expected the return to update the loan, got %d, %v", received, err)
+	}
+
+	running, err := neighbourService.GetLoans(ctx, false)
+	if err != nil {
+		t.Fatalf("failed to get loans: %v", err)
+	}
+	if len(running) != 0 {
+		t.Errorf("expected no running loan on the borrower, got %+v", running)
+	}
+	history, err := service.GetItemLoans(ctx, drill.ID)
+	if err != nil {
+		t.Fatalf("failed to get item loans: %v", err)
+	}
+	if len(history) != 1 || history[0].ReturnedAt == nil || history[0].Overdue {
+		t.Errorf("expected the returned loan in the history, got %+v", history)
+	}
+}
+
+func TestEncryption(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	database, err := db.NewDatabase(filepath.Join(t.TempDir(), "atelier.db"), logger)
+	if err != nil {
+		t.Fatalf("failed to initialize database: %v", err)
+	}
+	defer database.Close()
+
+	assetsDir := t.TempDir()
+	service := services.NewBackpackService(database, assetsDir)
+	ctx := context.Background()
+
+	drill := &models.Item{Name: "Perceuse", Category: "Outillage", Brand: "Bosch", Model: "PSB 500", SerialNumber: "BSH-0042", Notes: "Code alarme 1234"}
+	if err := service.CreateItem(ctx, drill); err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+	receiptFile := filepath.Join(t.TempDir(), "facture.pdf")
+	receipt := []byte("Facture n°42, Perceuse Bosch")
+	if err := os.WriteFile(receiptFile, receipt, 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	asset, err := service.AddAsset(ctx, drill.ID, models.AssetTypeOther, "Facture", receiptFile)
+	if err != nil {
+		t.Fatalf("failed to add asset: %v", err)
+	}
+
+	report, err := service.EnableEncryption(ctx, "correct horse")
+	if err != nil {
+		t.Fatalf("failed to enable encryption: %v", err)
+	}
+	if report.Items != 1 || report.Assets != 1 {
+		t.Errorf("expected 1 item and 1 asset encrypted, got %+v", report)
+	}
+	if _, err := service.EnableEncryption(ctx, "correct horse"); err != services.ErrEncryptionEnabled {
+		t.Errorf("expected encryption to be enabled only once, got %v", err)
+	}
+
+	// Neither the database nor the asset file hold the plaintext
+	var serialNumber, notes string
+	if err := database.DB.QueryRow("SELECT serial_number, notes FROM items WHERE id = ?", drill.ID).Scan(&serialNumber, &notes); err != nil {
+		t.Fatalf("failed to read item: %v", err)
+	}
+	if strings.Contains(serialNumber, "BSH") || strings.Contains(notes, "1234") {
+		t.Errorf("expected the sensitive fields to be encrypted, got %q, %q", serialNumber, notes)
+	}
+	stored, err := os.ReadFile(asset.FilePath)
+	if err != nil {
+		t.Fatalf("failed to read asset file: %v", err)
+	}
+	if bytes.Contains(stored, []byte("Facture")) {
+		t.Error("expected the asset file to be encrypted")
+	}
+
+	// The service reads them back in plain, including new data
+	item, err := service.GetItem(ctx, drill.ID)
+	if err != nil {
+		t.Fatalf("failed to get item: %v", err)
+	}
+	if item.SerialNumber != "BSH-0042" || item.Notes != "Code alarme 1234" {
+		t.Errorf("expected the sensitive fields in plain, got %q, %q", item.SerialNumber, item.Notes)
+	}
+	sander := &models.Item{Name: "Ponceuse", Category: "Outillage", SerialNumber: "MKT-7"}
+	if err := service.CreateItem(ctx, sander); err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+	var saved bytes.Buffer
+	if _, err := service.SaveAsset(ctx, asset.ID, &saved); err != nil {
+		t.Fatalf("failed to save asset: %v", err)
+	}
+	if !bytes.Equal(saved.Bytes(), receipt) {
+		t.Errorf("expected the asset file in plain, got %q", saved.String())
+	}
+
+	// After a rotation, only the new passphrase unlocks the data
+	if _, err := service.RotateEncryptionKey(ctx, "battery staple"); err != nil {
+		t.Fatalf("failed to rotate key: %v", err)
+	}
+	status, err := service.EncryptionStatus(ctx)
+	if err != nil {
+		t.Fatalf("failed to get encryption status: %v", err)
+	}
+	if !status.Enabled || !status.Unlocked || status.OldKeys != 0 {
+		t.Errorf("expected a single unlocked key, got %+v", status)
+	}
+
+	restarted := services.NewBackpackService(database, assetsDir)
+	if _, err := restarted.GetItem(ctx, drill.ID); err == nil {
+		t.Error("expected the data to be locked before the passphrase is given")
+	}
+	if err := restarted.UnlockEncryption(ctx, "correct horse"); err != services.ErrWrongPassphrase {
+		t.Errorf("expected the former passphrase to be rejected, got %v", err)
+	}
+	if err := restarted.UnlockEncryption(ctx, "battery staple"); err != nil {
+		t.Fatalf("failed to unlock: %v", err)
+	}
+	item, err = restarted.GetItem(ctx, sander.ID)
+	if err != nil {
+		t.Fatalf("failed to get item: %v", err)
+	}
+	if item.SerialNumber != "MKT-7" {
+		t.Errorf("expected the serial number in plain, got %q", item.SerialNumber)
+	}
+
+	// An encrypted export only imports with its passphrase
+	var bundle bytes.Buffer
+	writer, err := services.NewBundleWriter(&bundle, "export")
+	if err != nil {
+		t.Fatalf("failed to create bundle: %v", err)
+	}
+	if _, err := restarted.ExportJSON(ctx, writer); err != nil {
+		t.Fatalf("failed to export: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("failed to close bundle: %v", err)
+	}
+	if !services.IsBundle(bundle.Bytes()) || bytes.Contains(bundle.Bytes(), []byte("BSH-0042")) {
+		t.Error("expected the export to be encrypted")
+	}
+	data := bytes.NewReader(bundle.Bytes())
+	if _, err := services.OpenBundle(data, data.Size(), "wrong"); err != services.ErrWrongPassphrase {
+		t.Errorf("expected a wrong passphrase to be rejected, got %v", err)
+	}
+
+	// Argon2id parameters out of bounds are refused before deriving the key
+	for _, tampered := range []struct {
+		name   string
+		offset int // In the header: magic, salt, time, memory, threads
+		value  []byte
+	}{
+		{"time", 21, []byte{0, 0, 0x10, 0}},
+		{"memory", 25, []byte{0xff, 0xff, 0xff, 0xff}},
+		{"no memory", 25, []byte{0, 0, 0, 0}},
+		{"threads", 29, []byte{0xff}},
+	} {
+		crafted := bytes.Clone(bundle.Bytes())
+		copy(crafted[tampered.offset:], tampered.value)
+		if _, err := services.OpenBundle(bytes.NewReader(crafted), int64(len(crafted)), "export"); err == nil || !strings.Contains(err.Error(), "invalid bundle parameters") {
+			t.Errorf("expected the bundle with a tampered %s refused, got %v", tampered.name, err)
+		}
+	}
+	export, err := services.OpenBundle(data, data.Size(), "export")
+	if err != nil {
+		t.Fatalf("failed to open bundle: %v", err)
+	}
+	other, cleanup := setupTestService(t)
+	defer cleanup()
+	if imported, _, err := other.ImportJSON(ctx, export); err != nil || imported != 2 {
+		t.Fatalf("expected 2 items imported from the bundle, got %d, %v", imported, err)
+	}
+
+	// Disabling encryption stores the data in plain again
+	if _, err := restarted.DisableEncryption(ctx); err != nil {
+		t.Fatalf("failed to disable encryption: %v", err)
+	}
+	if err := database.DB.QueryRow("SELECT serial_number FROM items WHERE id = ?", drill.ID).Scan(&serialNumber); err != nil {
+		t.Fatalf("failed to read item: %v", err)
+	}
+	if serialNumber != "BSH-0042" {
+		t.Errorf("expected the serial number in plain, got %q", serialNumber)
+	}
+	if stored, err := os.ReadFile(asset.FilePath); err != nil || !bytes.Equal(stored, receipt) {
+		t.Errorf("expected the asset file in plain, got %q, %v", stored, err)
+	}
+}
+
+func TestAnnouncementsRelayedWithResetHops(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	newInstance := func(name string) *services.GossipService {
+		database, err := db.NewDatabase(filepath.Join(t.TempDir(), name+".db"), logger)
+		if err != nil {
+			t.Fatalf("failed to initialize database: %v", err)
+		}
+		t.Cleanup(func() { database.Close() })
+		return services.NewGossipService(database, name, ":0")
+	}
+	home, village := newInstance("home"), newInstance("village")
+
+	welder := &models.Announcement{Kind: models.AnnouncementRequest, Title: "Je cherche un soudeur", MaxHops: 1}
+	if err := home.PublishAnnouncement(ctx, welder, 0); err != nil {
+		t.Fatalf("failed to publish announcement: %v", err)
+	}
+	drill := &models.Announcement{Kind: models.AnnouncementOffer, Title: "Je prête une perceuse", MaxHops: 2}
+	if err := home.PublishAnnouncement(ctx, drill, 0); err != nil {
+		t.Fatalf("failed to publish announcement: %v", err)
+	}
+	shared, err := home.GetSharedAnnouncements(ctx)
+	if err != nil {
+		t.Fatalf("failed to get shared announcements: %v", err)
+	}
+
+	// Both reach the village through a neighbour, the welder past its limit
+	relayed := make([]models.Announcement, len(shared))
+	for i, announcement := range shared {
+		announcement.Hops = 1
+		relayed[i] = announcement
+	}
+	received, err := village.ApplyAnnouncements(ctx, "neighbour", relayed)
+	if err != nil {
+		t.Fatalf("failed to apply announcements: %v", err)
+	}
+	if received != 1 {
+		t.Errorf("expected only the drill received, got %d", received)
+	}
+
+	// Another relay resetting the hops cannot bring the welder back
+	for i := range relayed {
+		relayed[i].Hops = 0
+	}
+	if _, err := village.ApplyAnnouncements(ctx, "relay", relayed); err != nil {
+		t.Fatalf("failed to apply announcements: %v", err)
+	}
+	running, err := village.GetAnnouncements(ctx, "")
+	if err != nil {
+		t.Fatalf("failed to get announcements: %v", err)
+	}
+	if len(running) != 1 || running[0].UID != drill.UID || running[0].Hops != 2 || running[0].ReceivedFrom != "neighbour" {
+		t.Fatalf("expected only the drill 2 hops away, got %+v", running)
+	}
+
+	// An update keeps the hops the announcement first came with
+	if err := home.WithdrawAnnouncement(ctx, drill.ID); err != nil {
+		t.Fatalf("failed to withdraw announcement: %v", err)
+	}
+	shared, err = home.GetSharedAnnouncements(ctx)
+	if err != nil {
+		t.Fatalf("failed to get shared announcements: %v", err)
+	}
+	if _, err := village.ApplyAnnouncements(ctx, "home", shared); err != nil {
+		t.Fatalf("failed to apply announcements: %v", err)
+	}
+	if shared, _ := village.GetSharedAnnouncements(ctx); len(shared) != 0 {
+		t.Errorf("expected the withdrawal to stop at the hop limit, got %+v", shared)
+	}
+	if running, _ := village.GetAnnouncements(ctx, ""); len(running) != 0 {
+		t.Errorf("expected the drill withdrawn, got %+v", running)
+	}
+}
+
+func TestAnnouncementKeyEncrypted(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	database, err := db.NewDatabase(filepath.Join(t.TempDir(), "test.db"), logger)
+	if err != nil {
+		t.Fatalf("failed to initialize database: %v", err)
+	}
+	defer database.Close()
+	service := services.NewBackpackService(database, t.TempDir())
+	gossip := services.NewGossipService(database, "home", ":0")
+	gossip.SetKeyring(service.Keyring())
+
+	drill := &models.Announcement{Kind: models.AnnouncementOffer, Title: "Je prête une perceuse"}
+	if err := gossip.PublishAnnouncement(ctx, drill, 0); err != nil {
+		t.Fatalf("failed to publish announcement: %v", err)
+	}
+	if _, err := service.EnableEncryption(ctx, "correct horse"); err != nil {
+		t.Fatalf("failed to enable encryption: %v", err)
+	}
+
+	var privateKey []byte
+	if err := database.DB.QueryRow("SELECT private_key FROM instance_keys").Scan(&privateKey); err != nil {
+		t.Fatalf("failed to read instance key: %v", err)
+	}
+	if !bytes.HasPrefix(privateKey, []byte("enc1:")) {
+		t.Error("expected the signing key to be encrypted")
+	}
+
+	// Announcements are signed with the same key once unlocked only
+	restarted := services.NewBackpackService(database, t.TempDir())
+	gossip.SetKeyring(restarted.Keyring())
+	if err := gossip.PublishAnnouncement(ctx, &models.Announcement{Kind: models.AnnouncementOffer, Title: "Vélo"}, 0); !errors.Is(err, services.ErrEncryptionLocked) {
+		t.Errorf("expected the signing key to be locked, got %v", err)
+	}
+	if running, err := gossip.GetAnnouncements(ctx, ""); err != nil || len(running) != 1 || !running[0].Own {
+		t.Errorf("expected the announcements readable while locked, got %+v, %v", running, err)
+	}
+	if err := restarted.UnlockEncryption(ctx, "correct horse"); err != nil {
+		t.Fatalf("failed to unlock: %v", err)
+	}
+	sander := &models.Announcement{Kind: models.AnnouncementOffer, Title: "Je prête une ponceuse"}
+	if err := gossip.PublishAnnouncement(ctx, sander, 0); err != nil {
+		t.Fatalf("failed to publish announcement: %v", err)
+	}
+	if sander.AuthorKey != drill.AuthorKey {
+		t.Error("expected the same signing key")
+	}
+
+	// Disabling encryption stores it in plain again
+	if _, err := restarted.DisableEncryption(ctx); err != nil {
+		t.Fatalf("failed to disable encryption: %v", err)
+	}
+	if err := database.DB.QueryRow("SELECT private_key FROM instance_keys").Scan(&privateKey); err != nil {
+		t.Fatalf("failed to read instance key: %v", err)
+	}
+	if len(privateKey) != 64 {
+		t.Errorf("expected the signing key in plain, got %d bytes", len(privateKey))
+	}
+}
+
+func TestInstanceIdentity(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	openDatabase := func(path string) *db.Database {
+		database, err := db.NewDatabase(path, logger)
+		if err != nil {
+			t.Fatalf("failed to initialize database: %v", err)
+		}
+		return database
+	}
+	instanceID := func(database *db.Database) string {
+		info, err := services.NewGossipService(database, "home", ":0").GetInstanceInfo(ctx)
+		if err != nil {
+			t.Fatalf("failed to get instance info: %v", err)
+		}
+		if info.InstanceID == "" {
+			t.Fatal("expected an instance ID")
+		}
+		return info.InstanceID
+	}
+
+	// The identity is kept across restarts
+	path := filepath.Join(t.TempDir(), "home.db")
+	database := openDatabase(path)
+	first := instanceID(database)
+	database.Close()
+
+	database = openDatabase(path)
+	defer database.Close()
+	if again := instanceID(database); again != first {
+		t.Errorf("expected the instance ID to be kept, got %s then %s", first, again)
+	}
+
+	// Each database, as each profile has, gets its own
+	other := openDatabase(filepath.Join(t.TempDir(), "cafe.db"))
+	defer other.Close()
+	if instanceID(other) == first {
+		t.Error("expected another database to get another instance ID")
+	}
+}
+
+func TestSyncPeerUnreachable(t *testing.T) {
+	service, cleanup := setupTestService(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	database, err := db.NewDatabase(filepath.Join(t.TempDir(), "home.db"), logger)
+	if err != nil {
+		t.Fatalf("failed to initialize database: %v", err)
+	}
+	defer database.Close()
+
+	gossip := services.NewGossipService(database, "home", ":0")
+	gossip.SetPeerTimeout(time.Second)
+	peer := &models.Peer{ID: "cafe", Name: "cafe", Address: "127.0.0.1:1", IsTrusted: true}
+	if err := gossip.AddPeer(ctx, peer); err != nil {
+		t.Fatalf("failed to add peer: %v", err)
+	}
+
+	if _, err := gossip.SyncPeer(ctx, service, peer); !errors.Is(err, services.ErrPeerUnreachable) {
+		t.Errorf("expected the peer to be unreachable, got %v", err)
+	}
+}
+
+func TestSearchPeersUnreachable(t *testing.T) {
+	ctx := context.Background()
+	database, err := db.NewDatabase(filepath.Join(t.TempDir(), "home.db"), nil)
+	if err != nil {
+		t.Fatalf("failed to initialize database: %v", err)
+	}
+	defer database.Close()
+
+	// Failures are logged, out of the results written on stdout
+	var logs bytes.Buffer
+	gossip := services.NewGossipService(database, "home", ":0")
+	gossip.SetLogger(slog.New(slog.NewTextHandler(&logs, nil)))
+	gossip.SetPeerTimeout(time.Second)
+	if err := gossip.AddPeer(ctx, &models.Peer{ID: "cafe", Name: "cafe", Address: "127.0.0.1:1", IsTrusted: true}); err != nil {
+		t.Fatalf("failed to add peer: %v", err)
+	}
+
+	results, err := gossip.SearchPeers(ctx, models.SearchQuery{Brand: "Bosch"})
+	if err != nil || len(results) != 0 {
+		t.Fatalf("expected no results and no error, got %+v (%v)", results, err)
+	}
+	if !strings.Contains(logs.String(), "Failed to search peer") || !strings.Contains(logs.String(), "peer_id=cafe") {
+		t.Errorf("expected the unreachable peer logged, got %q", logs.String())
+	}
+}
+
+func TestApplyAssetTypesKeepsBuiltins(t *testing.T) {
+	ctx := context.Background()
+	database, err := db.NewDatabase(filepath.Join(t.TempDir(), "home.db"), nil)
+	if err != nil {
+		t.Fatalf("failed to initialize database: %v", err)
+	}
+	defer database.Close()
+	service := services.NewBackpackService(database, t.TempDir())
+	gossip := services.NewGossipService(database, "home", ":0")
+
+	manual, err := service.GetAssetType(ctx, models.AssetTypeManual)
+	if err != nil {
+		t.Fatalf("failed to get asset type: %v", err)
+	}
+
+	// A peer sends a newer manual type accepting anything, not counting for
+	// health, along with a type of its own
+	later := time.Now().Add(time.Hour)
+	applied, err := gossip.ApplyAssetTypes(ctx, []models.AssetTypeDefinition{
+		{Name: models.AssetTypeManual, Label: "Notice", MimePatterns: []string{"*/*"}, CountsForHealth: false, UpdatedAt: later},
+		{Name: "warranty", Label: "Garantie", MimePatterns: []string{"application/pdf"}, UpdatedAt: later},
+	})
+	if err != nil {
+		t.Fatalf("failed to apply asset types: %v", err)
+	}
+	if applied != 1 {
+		t.Errorf("expected only the peer's own type applied, got %d", applied)
+	}
+
+	kept, err := service.GetAssetType(ctx, models.AssetTypeManual)
+	if err != nil {
+		t.Fatalf("failed to get asset type: %v", err)
+	}
+	if !kept.IsBuiltin || kept.Label != manual.Label || !kept.CountsForHealth ||
+		strings.Join(kept.MimePatterns, ",") != strings.Join(manual.MimePatterns, ",") {
+		t.Errorf("expected the built-in manual type kept, got %+v", kept)
+	}
+	if warranty, err := service.GetAssetType(ctx, "warranty"); err != nil || warranty.IsBuiltin || warranty.Label != "Garantie" {
+		t.Errorf("expected the peer's type created, got %+v (%v)", warranty, err)
+	}
+}
@@ -0,0 +1,687 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lhommenul/brique/core/models"
+)
+
+const (
+	// backupManifestVersion is the version of the manifest format
+	backupManifestVersion = 1
+
+	backupManifestFile = "manifest.json"
+	backupDatabaseFile = "brique.db"
+
+	// backupBlobsDir holds the asset files of every backup by hash, for each
+	// file to be copied once
+	backupBlobsDir = "blobs"
+
+	// backupTempPrefix marks backups being written
+	backupTempPrefix = ".tmp-"
+
+	// backupLockFile is held while a backup is written, pruned or restored,
+	// for pruning not to delete the asset files of a backup being written by
+	// another process
+	backupLockFile = ".lock"
+
+	// A held lock is touched every backupLockRefresh. One left untouched for
+	// backupLockStale was left by a process that died, and is taken over.
+	backupLockRefresh = 10 * time.Second
+	backupLockStale   = time.Minute
+)
+
+// ErrBackupNotFound is returned for a backup missing from the backups directory
+var ErrBackupNotFound = errors.New("backup not found")
+
+// CreateBackup writes a consistent snapshot of the database and a copy of the
+// assets into a new backup of backupsDir. Asset files already saved by an
+// earlier backup are not copied again. The backup only shows up in
+// ListBackups once complete.
+func (s *BackpackService) CreateBackup(ctx context.Context, backupsDir string) (*models.Backup, error) {
+	unlock, err := lockBackups(ctx, backupsDir)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	manifest := models.BackupManifest{
+		Version:   backupManifestVersion,
+		CreatedAt: time.Now(),
+		Assets:    []models.BackupFile{},
+	}
+
+	name, err := newBackupName(backupsDir, manifest.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	tempDir := filepath.Join(backupsDir, backupTempPrefix+name)
+	if err := os.MkdirAll(tempDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Unlike copying the file, VACUUM INTO includes the changes still in the
+	// write-ahead log and is consistent while other connections write
+	dbPath := filepath.Join(tempDir, backupDatabaseFile)
+	if _, err := s.database.DB.ExecContext(ctx, "VACUUM INTO ?", dbPath); err != nil {
+		return nil, fmt.Errorf("failed to snapshot database: %w", err)
+	}
+	manifest.Database, err = hashBackupFile(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	manifest.Database.Path = backupDatabaseFile
+
+	// Hashes of unchanged files are taken from the previous backup
+	known := make(map[string]models.BackupFile)
+	if backups, err := ListBackups(backupsDir); err == nil && len(backups) > 0 {
+		if previous, err := readBackupManifest(backups[0].Path); err == nil {
+			for _, file := range previous.Assets {
+				known[file.Path] = file
+			}
+		}
+	}
+
+	newBlobs := 0
+	blobsDir := filepath.Join(backupsDir, backupBlobsDir)
+	err = filepath.WalkDir(s.assetsDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// No assets directory means no asset was ever added
+			if path == s.assetsDir && errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if path == s.stagingDir() {
+			return filepath.SkipDir
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(s.assetsDir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		file, ok := known[rel]
+		if !ok || file.Size != info.Size() || !file.ModTime.Equal(info.ModTime()) || !fileExists(blobPath(blobsDir, file.SHA256)) {
+			if file, err = hashBackupFile(path); err != nil {
+				return err
+			}
+			file.Path = rel
+
+			copied, err := storeBlob(blobsDir, path, file.SHA256)
+			if err != nil {
+				return err
+			}
+			if copied {
+				newBlobs++
+			}
+		}
+
+		manifest.Assets = append(manifest.Assets, file)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to back up assets: %w", err)
+	}
+
+	if err := writeBackupManifest(tempDir, manifest); err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(backupsDir, name)
+	if err := os.Rename(tempDir, path); err != nil {
+		return nil, fmt.Errorf("failed to save backup: %w", err)
+	}
+
+	backup := backupOf(path, manifest)
+	backup.NewBlobs = newBlobs
+	return &backup, nil
+}
+
+// ListBackups returns the complete backups of backupsDir, newest first
+func ListBackups(backupsDir string) ([]models.Backup, error) {
+	entries, err := os.ReadDir(backupsDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []models.Backup{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	backups := []models.Backup{}
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() == backupBlobsDir || strings.HasPrefix(entry.Name(), backupTempPrefix) {
+			continue
+		}
+
+		// Backups of earlier releases have no manifest and can't be verified
+		path := filepath.Join(backupsDir, entry.Name())
+		manifest, err := readBackupManifest(path)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backupOf(path, *manifest))
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+	return backups, nil
+}
+
+// VerifyBackup checks that the database of a backup is intact and that every
+// file matches the hash of its manifest
+func VerifyBackup(ctx context.Context, backupsDir, name string) (*models.Backup, error) {
+	path, err := backupPath(backupsDir, name)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := readBackupManifest(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []string
+	check := func(path string, expected models.BackupFile) {
+		file, err := hashBackupFile(path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			problems = append(problems, fmt.Sprintf("%s is missing", expected.Path))
+		case err != nil:
+			problems = append(problems, err.Error())
+		case file.SHA256 != expected.SHA256:
+			problems = append(problems, fmt.Sprintf("%s is corrupted", expected.Path))
+		}
+	}
+
+	dbPath := filepath.Join(path, backupDatabaseFile)
+	check(dbPath, manifest.Database)
+	if len(problems) == 0 {
+		if err := checkDatabaseIntegrity(ctx, dbPath); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	blobsDir := filepath.Join(backupsDir, backupBlobsDir)
+	for _, file := range manifest.Assets {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		check(blobPath(blobsDir, file.SHA256), file)
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("backup %s is damaged: %s", name, strings.Join(problems, "; "))
+	}
+
+	backup := backupOf(path, *manifest)
+	return &backup, nil
+}
+
+// PruneBackups deletes the backups of backupsDir beyond the retention policy,
+// then the asset files no backup uses anymore. It returns the deleted backups.
+func PruneBackups(ctx context.Context, backupsDir string, retention models.BackupRetention) ([]models.Backup, error) {
+	removed := []models.Backup{}
+	if retention.KeepLast <= 0 && retention.KeepDaily <= 0 && retention.KeepWeekly <= 0 && retention.KeepMonthly <= 0 {
+		return removed, nil
+	}
+
+	unlock, err := lockBackups(ctx, backupsDir)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	backups, err := ListBackups(backupsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	keep := make(map[string]bool)
+	for i, backup := range backups {
+		if i < retention.KeepLast {
+			keep[backup.Name] = true
+		}
+	}
+
+	// The newest backup of each period is kept, for as many periods as asked
+	periods := []struct {
+		keep int
+		of   func(time.Time) string
+	}{
+		{retention.KeepDaily, func(t time.Time) string { return t.Format("2006-01-02") }},
+		{retention.KeepWeekly, func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		}},
+		{retention.KeepMonthly, func(t time.Time) string { return t.Format("2006-01") }},
+	}
+	for _, period := range periods {
+		seen := make(map[string]bool)
+		for _, backup := range backups {
+			key := period.of(backup.CreatedAt.Local())
+			if !seen[key] && len(seen) < period.keep {
+				seen[key] = true
+				keep[backup.Name] = true
+			}
+		}
+	}
+
+	used := make(map[string]bool)
+	for _, backup := range backups {
+		if !keep[backup.Name] {
+			if err := os.RemoveAll(backup.Path); err != nil {
+				return removed, fmt.Errorf("failed to delete backup %s: %w", backup.Name, err)
+			}
+			removed = append(removed, backup)
+			continue
+		}
+
+		manifest, err := readBackupManifest(backup.Path)
+		if err != nil {
+			return removed, err
+		}
+		for _, file := range manifest.Assets {
+			used[file.SHA256] = true
+		}
+	}
+
+	// With the lock held, no backup is being written: the unfinished ones
+	// were left by a process that died
+	entries, err := os.ReadDir(backupsDir)
+	if err != nil {
+		return removed, fmt.Errorf("failed to list backups: %w", err)
+	}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), backupTempPrefix) {
+			if err := os.RemoveAll(filepath.Join(backupsDir, entry.Name())); err != nil {
+				return removed, fmt.Errorf("failed to delete unfinished backup: %w", err)
+			}
+		}
+	}
+
+	err = filepath.WalkDir(filepath.Join(backupsDir, backupBlobsDir), func(path string, entry fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil || entry.IsDir() || used[entry.Name()] {
+			return err
+		}
+		return os.Remove(path)
+	})
+	if err != nil {
+		return removed, fmt.Errorf("failed to delete unused asset files: %w", err)
+	}
+
+	return removed, nil
+}
+
+// RestoreBackup replaces the database and assets with those of a backup, once
+// verified. Nothing may use the database meanwhile: close it first.
+func RestoreBackup(ctx context.Context, backupsDir, name, databasePath, assetsDir string) (*models.Backup, error) {
+	unlock, err := lockBackups(ctx, backupsDir)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	backup, err := VerifyBackup(ctx, backupsDir, name)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := readBackupManifest(backup.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Both are prepared next to their target, then swapped in
+	restoredDB := databasePath + ".restore"
+	restoredAssets := assetsDir + ".restore"
+	defer os.Remove(restoredDB)
+	defer os.RemoveAll(restoredAssets)
+
+	if err := copyFile(filepath.Join(backup.Path, backupDatabaseFile), restoredDB); err != nil {
+		return nil, fmt.Errorf("failed to restore database: %w", err)
+	}
+	if err := clearFileOutbox(ctx, restoredDB); err != nil {
+		return nil, err
+	}
+
+	blobsDir := filepath.Join(backupsDir, backupBlobsDir)
+	if err := os.RemoveAll(restoredAssets); err != nil {
+		return nil, fmt.Errorf("failed to restore assets: %w", err)
+	}
+	for _, file := range manifest.Assets {
+		target := filepath.Join(restoredAssets, filepath.FromSlash(file.Path))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return nil, fmt.Errorf("failed to restore assets: %w", err)
+		}
+		if err := copyFile(blobPath(blobsDir, file.SHA256), target); err != nil {
+			return nil, fmt.Errorf("failed to restore %s: %w", file.Path, err)
+		}
+		// Keeps the next backup from hashing the file again
+		os.Chtimes(target, file.ModTime, file.ModTime)
+	}
+	if err := os.MkdirAll(restoredAssets, 0755); err != nil {
+		return nil, fmt.Errorf("failed to restore assets: %w", err)
+	}
+
+	// The journal of the replaced database must not be applied to the new one
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(databasePath + suffix); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to remove database journal: %w", err)
+		}
+	}
+	if err := os.Rename(restoredDB, databasePath); err != nil {
+		return nil, fmt.Errorf("failed to restore database: %w", err)
+	}
+
+	replacedAssets := assetsDir + ".replaced"
+	if err := os.RemoveAll(replacedAssets); err != nil {
+		return nil, fmt.Errorf("failed to restore assets: %w", err)
+	}
+	if err := os.Rename(assetsDir, replacedAssets); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to restore assets: %w", err)
+	}
+	if err := os.Rename(restoredAssets, assetsDir); err != nil {
+		return nil, fmt.Errorf("failed to restore assets: %w", err)
+	}
+	os.RemoveAll(replacedAssets)
+
+	return backup, nil
+}
+
+// lockBackups waits for the lock of backupsDir, taken by any process, and
+// returns its release
+func lockBackups(ctx context.Context, backupsDir string) (func(), error) {
+	if err := os.MkdirAll(backupsDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create backups directory: %w", err)
+	}
+
+	path := filepath.Join(backupsDir, backupLockFile)
+	for {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			file.Close()
+			break
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("failed to lock backups: %w", err)
+		}
+
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > backupLockStale {
+			os.Remove(path)
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("failed to lock backups: %w", ctx.Err())
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	// Long backups keep the lock fresh
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(backupLockRefresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				now := time.Now()
+				os.Chtimes(path, now, now)
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+		os.Remove(path)
+	}, nil
+}
+
+// clearFileOutbox empties the file outbox of a restored database. Its entries
+// point into the staging directory, left out of backups, or at files of the
+// replaced assets.
+func clearFileOutbox(ctx context.Context, path string) error {
+	conn, err := sql.Open("sqlite", path)
+	if err != nil {
+		return fmt.Errorf("failed to open restored database: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "DELETE FROM file_outbox"); err != nil {
+		return fmt.Errorf("failed to clear file outbox: %w", err)
+	}
+	return conn.Close()
+}
+
+// newBackupName names a backup after its creation time, unique in backupsDir
+func newBackupName(backupsDir string, createdAt time.Time) (string, error) {
+	base := "backup_" + createdAt.Format("2006-01-02_15-04-05")
+	name := base
+	for i := 2; ; i++ {
+		_, err := os.Stat(filepath.Join(backupsDir, name))
+		if errors.Is(err, fs.ErrNotExist) {
+			return name, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to name backup: %w", err)
+		}
+		name = fmt.Sprintf("%s_%d", base, i)
+	}
+}
+
+// backupPath returns the directory of a backup given by name
+func backupPath(backupsDir, name string) (string, error) {
+	if name == "" || name != filepath.Base(name) || name == backupBlobsDir || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("invalid backup name %q", name)
+	}
+
+	path := filepath.Join(backupsDir, name)
+	if !fileExists(filepath.Join(path, backupManifestFile)) {
+		return "", fmt.Errorf("%w: %s", ErrBackupNotFound, name)
+	}
+	return path, nil
+}
+
+func backupOf(path string, manifest models.BackupManifest) models.Backup {
+	backup := models.Backup{
+		Name:      filepath.Base(path),
+		Path:      path,
+		CreatedAt: manifest.CreatedAt,
+		Size:      manifest.Database.Size,
+		Assets:    len(manifest.Assets),
+	}
+	for _, file := range manifest.Assets {
+		backup.Size += file.Size
+	}
+	return backup
+}
+
+func readBackupManifest(path string) (*models.BackupManifest, error) {
+	data, err := os.ReadFile(filepath.Join(path, backupManifestFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrBackupNotFound, filepath.Base(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup manifest: %w", err)
+	}
+
+	var manifest models.BackupManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid backup manifest: %w", err)
+	}
+	if manifest.Version != backupManifestVersion {
+		return nil, fmt.Errorf("unsupported backup manifest version %d", manifest.Version)
+	}
+
+	// Backups may come from elsewhere: their files must stay in the assets
+	// and blobs directories
+	for _, file := range manifest.Assets {
+		if !filepath.IsLocal(filepath.FromSlash(file.Path)) {
+			return nil, fmt.Errorf("invalid backup manifest: unsafe asset path %q", file.Path)
+		}
+		if !isSHA256(file.SHA256) {
+			return nil, fmt.Errorf("invalid backup manifest: invalid hash of %s", file.Path)
+		}
+	}
+	return &manifest, nil
+}
+
+// isSHA256 tells whether s is a SHA-256 digest in lowercase hex
+func isSHA256(s string) bool {
+	if len(s) != 2*sha256.Size {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func writeBackupManifest(path string, manifest models.BackupManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to write backup manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(path, backupManifestFile), data, 0644); err != nil {
+		return fmt.Errorf("failed to write backup manifest: %w", err)
+	}
+	return nil
+}
+
+// checkDatabaseIntegrity runs the integrity check of SQLite on a database
+// file, without writing to it
+func checkDatabaseIntegrity(ctx context.Context, path string) error {
+	conn, err := sql.Open("sqlite", "file:"+path+"?mode=ro&immutable=1")
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer conn.Close()
+
+	var result string
+	if err := conn.QueryRowContext(ctx, "PRAGMA integrity_check").Scan(&result); err != nil {
+		return fmt.Errorf("failed to check database: %w", err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("database is corrupted: %s", result)
+	}
+	return nil
+}
+
+// hashBackupFile returns the size, hash and modification time of a file
+func hashBackupFile(path string) (models.BackupFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return models.BackupFile{}, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return models.BackupFile{}, err
+	}
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return models.BackupFile{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return models.BackupFile{
+		Size:    size,
+		SHA256:  fmt.Sprintf("%x", hash.Sum(nil)),
+		ModTime: info.ModTime(),
+	}, nil
+}
+
+// blobPath is where the asset file with the given hash is stored
+func blobPath(blobsDir, hash string) string {
+	if len(hash) < 2 {
+		return filepath.Join(blobsDir, hash)
+	}
+	return filepath.Join(blobsDir, hash[:2], hash)
+}
+
+// storeBlob copies a file into the blobs directory, unless it is already
+// there, and tells whether it did
+func storeBlob(blobsDir, path, hash string) (bool, error) {
+	target := blobPath(blobsDir, hash)
+	if fileExists(target) {
+		return false, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return false, err
+	}
+	temp := target + ".tmp"
+	if err := copyFile(path, temp); err != nil {
+		os.Remove(temp)
+		return false, err
+	}
+	if err := os.Rename(temp, target); err != nil {
+		os.Remove(temp)
+		return false, err
+	}
+	return true, nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func copyFile(src, dst string) error {
+	sourceFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer sourceFile.Close()
+
+	destFile, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(destFile, sourceFile); err != nil {
+		destFile.Close()
+		return err
+	}
+	if err := destFile.Sync(); err != nil {
+		destFile.Close()
+		return err
+	}
+	return destFile.Close()
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/lhommenul/brique/core/models"
+)
+
+// backupCheckInterval bounds the wait between two checks of the destinations,
+// for a drive plugged back in or a failed backup to be caught up on
+const backupCheckInterval = 10 * time.Minute
+
+// BackupScheduler backs up to each destination directory once per interval.
+// Destinations missing when a backup is due, such as an unmounted USB drive,
+// are backed up once available again.
+type BackupScheduler struct {
+	backpack *BackpackService
+	logger   *slog.Logger
+	changed  chan struct{} // Wakes Run up when reconfigured
+
+	mu           sync.Mutex
+	interval     time.Duration
+	destinations []string
+	retention    models.BackupRetention
+	failures     map[string]backupFailure // By destination
+}
+
+// backupFailure is the error of the last scheduled backup of a destination
+type backupFailure struct {
+	message string
+	at      time.Time
+}
+
+// NewBackupScheduler creates a backup scheduler. A zero interval disables
+// scheduled backups, leaving the status of the destinations.
+func NewBackupScheduler(backpack *BackpackService, interval time.Duration, destinations []string, retention models.BackupRetention, logger *slog.Logger) *BackupScheduler {
+	return &BackupScheduler{
+		backpack:     backpack,
+		logger:       logger,
+		changed:      make(chan struct{}, 1),
+		interval:     interval,
+		destinations: destinations,
+		retention:    retention,
+		failures:     make(map[string]backupFailure),
+	}
+}
+
+// Reconfigure changes the schedule, taking effect right away when Run is running
+func (s *BackupScheduler) Reconfigure(interval time.Duration, destinations []string, retention models.BackupRetention) {
+	s.mu.Lock()
+	s.interval = interval
+	s.destinations = destinations
+	s.retention = retention
+	s.mu.Unlock()
+
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+}
+
+// schedule returns the current interval, destinations and retention
+func (s *BackupScheduler) schedule() (time.Duration, []string, models.BackupRetention) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.interval, s.destinations, s.retention
+}
+
+// Run backs up to the destinations as they fall due, until ctx is done.
+// While scheduled backups are disabled, it waits to be reconfigured.
+func (s *BackupScheduler) Run(ctx context.Context) {
+	if interval, destinations, _ := s.schedule(); interval > 0 {
+		s.logger.Info("Backup scheduler started", "interval", interval, "destinations", destinations)
+	}
+
+	for {
+		var wait <-chan time.Time
+		if interval, _, _ := s.schedule(); interval > 0 {
+			wait = time.After(min(time.Until(s.backUpDue(ctx)), backupCheckInterval))
+		}
+
+		select {
+		case <-wait:
+		case <-s.changed:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// backUpDue backs up to the available destinations that are due, then
+// returns when the next backup falls due
+func (s *BackupScheduler) backUpDue(ctx context.Context) time.Time {
+	interval, destinations, retention := s.schedule()
+	next := time.Now().Add(interval)
+
+	for _, destination := range destinations {
+		status := s.destinationStatus(destination, interval)
+		if !status.Available {
+			continue
+		}
+		if status.NextBackup != nil && status.NextBackup.After(time.Now()) {
+			if status.NextBackup.Before(next) {
+				next = *status.NextBackup
+			}
+			continue
+		}
+
+		backup, err := s.backpack.CreateBackup(ctx, destination)
+		if err != nil {
+			if ctx.Err() != nil {
+				return next
+			}
+			s.logger.Error("Scheduled backup failed", "destination", destination, "error", err)
+			s.setFailure(destination, err)
+			continue
+		}
+		s.setFailure(destination, nil)
+		s.logger.Info("Scheduled backup created", "path", backup.Path, "new_blobs", backup.NewBlobs)
+
+		removed, err := PruneBackups(ctx, destination, retention)
+		if err != nil {
+			s.logger.Warn("Failed to prune backups", "destination", destination, "error", err)
+		}
+		for _, old := range removed {
+			s.logger.Info("Old backup deleted", "path", old.Path)
+		}
+	}
+
+	return next
+}
+
+// Status reports on the backups of each destination
+func (s *BackupScheduler) Status() models.BackupScheduleStatus {
+	interval, destinations, _ := s.schedule()
+	status := models.BackupScheduleStatus{
+		Enabled:      interval > 0,
+		Healthy:      true,
+		Destinations: []models.BackupDestinationStatus{},
+	}
+	if status.Enabled {
+		status.Interval = interval.String()
+	}
+
+	for _, destination := range destinations {
+		destinationStatus := s.destinationStatus(destination, interval)
+		if destinationStatus.Overdue || destinationStatus.LastError != "" || (status.Enabled && !destinationStatus.Available) {
+			status.Healthy = false
+		}
+		status.Destinations = append(status.Destinations, destinationStatus)
+	}
+
+	return status
+}
+
+func (s *BackupScheduler) destinationStatus(destination string, interval time.Duration) models.BackupDestinationStatus {
+	status := models.BackupDestinationStatus{Path: destination}
+
+	// Destinations are not created, for backups not to fill the disk a drive
+	// should have been mounted on
+	info, err := os.Stat(destination)
+	status.Available = err == nil && info.IsDir()
+
+	var backups []models.Backup
+	if status.Available {
+		backups, _ = ListBackups(destination)
+	}
+	status.Backups = len(backups)
+
+	if interval > 0 && status.Available {
+		next := time.Now()
+		if len(backups) > 0 {
+			status.LastBackup = &backups[0]
+			next = backups[0].CreatedAt.Add(interval)
+		}
+		status.NextBackup = &next
+		status.Overdue = time.Since(next) > interval
+	} else if len(backups) > 0 {
+		status.LastBackup = &backups[0]
+	}
+
+	s.mu.Lock()
+	if failure, ok := s.failures[destination]; ok {
+		status.LastError = failure.message
+		status.LastErrorAt = &failure.at
+	}
+	s.mu.Unlock()
+
+	return status
+}
+
+func (s *BackupScheduler) setFailure(destination string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err == nil {
+		delete(s.failures, destination)
+		return
+	}
+	s.failures[destination] = backupFailure{message: err.Error(), at: time.Now()}
+}
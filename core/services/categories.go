@@ -0,0 +1,479 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lhommenul/brique/core/db"
+	"github.com/lhommenul/brique/core/models"
+)
+
+// accentFolder strips the accents found in French category names so that
+// "Électroménager" and "electromenager" resolve to the same category
+var accentFolder = strings.NewReplacer(
+	"à", "a", "â", "a", "ä", "a",
+	"ç", "c",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"î", "i", "ï", "i",
+	"ô", "o", "ö", "o",
+	"ù", "u", "û", "u", "ü", "u",
+	"ÿ", "y", "œ", "oe", "æ", "ae",
+)
+
+// categoryIndex resolves category names and synonyms to categories
+type categoryIndex struct {
+	byID     map[int64]db.Category
+	byName   map[string]int64 // Exact names, case-insensitive
+	byKey    map[string]int64 // Folded names and synonyms
+	synonyms map[int64][]string
+}
+
+// GetCategories returns every category with its synonyms
+func (s *BackpackService) GetCategories(ctx context.Context) ([]models.Category, error) {
+	index, err := loadCategoryIndex(ctx, s.queries)
+	if err != nil {
+		return nil, err
+	}
+
+	dbCategories, err := s.queries.GetAllCategories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categories: %w", err)
+	}
+
+	categories := make([]models.Category, len(dbCategories))
+	for i, c := range dbCategories {
+		categories[i] = dbCategoryToModel(c, index.synonyms[c.ID])
+	}
+
+	return categories, nil
+}
+
+// CreateCategory adds a category, optionally under an existing parent
+func (s *BackpackService) CreateCategory(ctx context.Context, name, parent string) (*models.Category, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("category name is required")
+	}
+
+	index, err := loadCategoryIndex(ctx, s.queries)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing, ok := index.lookup(name); ok {
+		return nil, fmt.Errorf("category %q already exists as %q", name, existing.Name)
+	}
+
+	parentID, err := index.parentID(parent)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.queries.CreateCategory(ctx, db.CreateCategoryParams{
+		Name:      name,
+		ParentID:  parentID,
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create category: %w", err)
+	}
+
+	category := dbCategoryToModel(created, nil)
+	return &category, nil
+}
+
+// SetCategoryParent moves a category under another one, or to the top level
+// when parent is empty
+func (s *BackpackService) SetCategoryParent(ctx context.Context, name, parent string) error {
+	index, err := loadCategoryIndex(ctx, s.queries)
+	if err != nil {
+		return err
+	}
+
+	category, err := index.get(name)
+	if err != nil {
+		return err
+	}
+
+	parentID, err := index.parentID(parent)
+	if err != nil {
+		return err
+	}
+
+	// Refuse to move a category below itself
+	for id := parentID; id.Valid; id = index.byID[id.Int64].ParentID {
+		if id.Int64 == category.ID {
+			return fmt.Errorf("cannot move category %q under its own subcategory %q", category.Name, parent)
+		}
+	}
+
+	if err := s.queries.UpdateCategory(ctx, db.UpdateCategoryParams{
+		Name:      category.Name,
+		ParentID:  parentID,
+		UpdatedAt: time.Now(),
+		ID:        category.ID,
+	}); err != nil {
+		return fmt.Errorf("failed to update category: %w", err)
+	}
+
+	return nil
+}
+
+// RenameCategory renames a category and its items. The old name is kept as a
+// synonym.
+func (s *BackpackService) RenameCategory(ctx context.Context, name, newName string) error {
+	newName = strings.TrimSpace(newName)
+	if newName == "" {
+		return fmt.Errorf("category name is required")
+	}
+
+	return s.inTx(ctx, func(tx *BackpackService) error {
+		index, err := loadCategoryIndex(ctx, tx.queries)
+		if err != nil {
+			return err
+		}
+
+		category, err := index.get(name)
+		if err != nil {
+			return err
+		}
+
+		if existing, ok := index.lookup(newName); ok && existing.ID != category.ID {
+			return fmt.Errorf("category %q already exists as %q, merge them instead", newName, existing.Name)
+		}
+
+		now := time.Now()
+		if err := tx.queries.UpdateCategory(ctx, db.UpdateCategoryParams{
+			Name:      newName,
+			ParentID:  category.ParentID,
+			UpdatedAt: now,
+			ID:        category.ID,
+		}); err != nil {
+			return fmt.Errorf("failed to rename category: %w", err)
+		}
+
+		if err := tx.queries.RenameCategoryItems(ctx, db.RenameCategoryItemsParams{
+			Category:   newName,
+			UpdatedAt:  now,
+			CategoryID: sql.NullInt64{Int64: category.ID, Valid: true},
+		}); err != nil {
+			return fmt.Errorf("failed to rename category items: %w", err)
+		}
+
+		if foldCategoryName(category.Name) == foldCategoryName(newName) {
+			return nil
+		}
+		return tx.addSynonym(ctx, category.ID, category.Name)
+	})
+}
+
+// AddCategorySynonym makes an alternative spelling resolve to a category
+func (s *BackpackService) AddCategorySynonym(ctx context.Context, name, synonym string) error {
+	synonym = strings.TrimSpace(synonym)
+	if synonym == "" {
+		return fmt.Errorf("synonym is required")
+	}
+
+	index, err := loadCategoryIndex(ctx, s.queries)
+	if err != nil {
+		return err
+	}
+
+	category, err := index.get(name)
+	if err != nil {
+		return err
+	}
+
+	if existing, ok := index.lookup(synonym); ok && existing.ID != category.ID {
+		return fmt.Errorf("%q already resolves to category %q, merge them instead", synonym, existing.Name)
+	}
+
+	return s.addSynonym(ctx, category.ID, synonym)
+}
+
+// RemoveCategorySynonym removes an alternative spelling
+func (s *BackpackService) RemoveCategorySynonym(ctx context.Context, synonym string) error {
+	if err := s.queries.DeleteCategorySynonym(ctx, strings.TrimSpace(synonym)); err != nil {
+		return fmt.Errorf("failed to delete synonym: %w", err)
+	}
+	return nil
+}
+
+// MergeCategory moves the items, subcategories and synonyms of a category into
+// another one, then deletes it. Its name becomes a synonym of the target.
+func (s *BackpackService) MergeCategory(ctx context.Context, from, into string) error {
+	return s.inTx(ctx, func(tx *BackpackService) error {
+		index, err := loadCategoryIndex(ctx, tx.queries)
+		if err != nil {
+			return err
+		}
+
+		source, err := index.get(from)
+		if err != nil {
+			return err
+		}
+
+		target, err := index.get(into)
+		if err != nil {
+			return err
+		}
+
+		if source.ID == target.ID {
+			return fmt.Errorf("cannot merge category %q into itself", source.Name)
+		}
+
+		for id := target.ParentID; id.Valid; id = index.byID[id.Int64].ParentID {
+			if id.Int64 == source.ID {
+				return fmt.Errorf("cannot merge category %q into its own subcategory %q", source.Name, target.Name)
+			}
+		}
+
+		now := time.Now()
+		sourceID := sql.NullInt64{Int64: source.ID, Valid: true}
+		targetID := sql.NullInt64{Int64: target.ID, Valid: true}
+
+		if err := tx.queries.ReassignCategoryItems(ctx, db.ReassignCategoryItemsParams{
+			NewCategoryID: targetID,
+			Category:      target.Name,
+			UpdatedAt:     now,
+			OldCategoryID: sourceID,
+		}); err != nil {
+			return fmt.Errorf("failed to move category items: %w", err)
+		}
+
+		if err := tx.queries.ReparentCategories(ctx, db.ReparentCategoriesParams{
+			NewParentID: targetID,
+			UpdatedAt:   now,
+			OldParentID: sourceID,
+		}); err != nil {
+			return fmt.Errorf("failed to move subcategories: %w", err)
+		}
+
+		if err := tx.queries.MoveCategorySynonyms(ctx, db.MoveCategorySynonymsParams{
+			NewCategoryID: target.ID,
+			OldCategoryID: source.ID,
+		}); err != nil {
+			return fmt.Errorf("failed to move synonyms: %w", err)
+		}
+
+		if err := tx.queries.DeleteCategory(ctx, source.ID); err != nil {
+			return fmt.Errorf("failed to delete category: %w", err)
+		}
+
+		return tx.addSynonym(ctx, target.ID, source.Name)
+	})
+}
+
+// DeleteCategory removes a category that no item uses. Its subcategories are
+// moved to its parent.
+func (s *BackpackService) DeleteCategory(ctx context.Context, name string) error {
+	return s.inTx(ctx, func(tx *BackpackService) error {
+		index, err := loadCategoryIndex(ctx, tx.queries)
+		if err != nil {
+			return err
+		}
+
+		category, err := index.get(name)
+		if err != nil {
+			return err
+		}
+
+		count, err := tx.queries.CountItemsByCategoryID(ctx, sql.NullInt64{Int64: category.ID, Valid: true})
+		if err != nil {
+			return fmt.Errorf("failed to count items: %w", err)
+		}
+
+		if count > 0 {
+			return fmt.Errorf("category %s is used by %d item(s), merge it instead", category.Name, count)
+		}
+
+		if err := tx.queries.ReparentCategories(ctx, db.ReparentCategoriesParams{
+			NewParentID: category.ParentID,
+			UpdatedAt:   time.Now(),
+			OldParentID: sql.NullInt64{Int64: category.ID, Valid: true},
+		}); err != nil {
+			return fmt.Errorf("failed to move subcategories: %w", err)
+		}
+
+		if err := tx.queries.DeleteCategory(ctx, category.ID); err != nil {
+			return fmt.Errorf("failed to delete category: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// categorySubtree returns the folded names of a category and all its
+// descendants
+func (s *BackpackService) categorySubtree(ctx context.Context, name string) (map[string]bool, error) {
+	index, err := loadCategoryIndex(ctx, s.queries)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := index.get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[int64][]int64)
+	for _, c := range index.byID {
+		if c.ParentID.Valid {
+			children[c.ParentID.Int64] = append(children[c.ParentID.Int64], c.ID)
+		}
+	}
+
+	subtree := make(map[string]bool)
+	queue := []int64{root.ID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if subtree[foldCategoryName(index.byID[id].Name)] {
+			continue
+		}
+		subtree[foldCategoryName(index.byID[id].Name)] = true
+		queue = append(queue, children[id]...)
+	}
+
+	return subtree, nil
+}
+
+// addSynonym makes a synonym resolve to a category
+func (s *BackpackService) addSynonym(ctx context.Context, categoryID int64, synonym string) error {
+	if err := s.queries.CreateCategorySynonym(ctx, db.CreateCategorySynonymParams{
+		Synonym:    synonym,
+		CategoryID: categoryID,
+	}); err != nil {
+		return fmt.Errorf("failed to add synonym: %w", err)
+	}
+	return nil
+}
+
+// resolveCategory returns the category an item category string refers to,
+// matching names and synonyms regardless of case and accents. Unknown
+// categories are created at the top level. Must be called within a
+// transaction.
+func resolveCategory(ctx context.Context, q *db.Queries, name string) (sql.NullInt64, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return sql.NullInt64{}, "", nil
+	}
+
+	index, err := loadCategoryIndex(ctx, q)
+	if err != nil {
+		return sql.NullInt64{}, "", err
+	}
+
+	if category, ok := index.lookup(name); ok {
+		return sql.NullInt64{Int64: category.ID, Valid: true}, category.Name, nil
+	}
+
+	created, err := q.CreateCategory(ctx, db.CreateCategoryParams{
+		Name:      name,
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		return sql.NullInt64{}, "", fmt.Errorf("failed to create category: %w", err)
+	}
+
+	return sql.NullInt64{Int64: created.ID, Valid: true}, created.Name, nil
+}
+
+// loadCategoryIndex loads every category and synonym
+func loadCategoryIndex(ctx context.Context, q *db.Queries) (*categoryIndex, error) {
+	dbCategories, err := q.GetAllCategories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categories: %w", err)
+	}
+
+	dbSynonyms, err := q.GetAllCategorySynonyms(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category synonyms: %w", err)
+	}
+
+	index := &categoryIndex{
+		byID:     make(map[int64]db.Category, len(dbCategories)),
+		byName:   make(map[string]int64, len(dbCategories)),
+		byKey:    make(map[string]int64, len(dbCategories)+len(dbSynonyms)),
+		synonyms: make(map[int64][]string),
+	}
+
+	for _, c := range dbSynonyms {
+		index.byKey[foldCategoryName(c.Synonym)] = c.CategoryID
+		index.synonyms[c.CategoryID] = append(index.synonyms[c.CategoryID], c.Synonym)
+	}
+
+	// Names take precedence over synonyms
+	for _, c := range dbCategories {
+		index.byID[c.ID] = c
+		index.byName[strings.ToLower(c.Name)] = c.ID
+		index.byKey[foldCategoryName(c.Name)] = c.ID
+	}
+
+	return index, nil
+}
+
+// lookup finds a category by name or synonym. An exact name wins over a
+// folded match, so that accent variants can still be told apart and merged.
+func (i *categoryIndex) lookup(name string) (db.Category, bool) {
+	id, ok := i.byName[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		id, ok = i.byKey[foldCategoryName(name)]
+	}
+	if !ok {
+		return db.Category{}, false
+	}
+	category, ok := i.byID[id]
+	return category, ok
+}
+
+// get finds a category by name or synonym, failing when it is unknown
+func (i *categoryIndex) get(name string) (db.Category, error) {
+	category, ok := i.lookup(name)
+	if !ok {
+		return db.Category{}, fmt.Errorf("unknown category: %s", name)
+	}
+	return category, nil
+}
+
+// parentID resolves an optional parent category name
+func (i *categoryIndex) parentID(parent string) (sql.NullInt64, error) {
+	if strings.TrimSpace(parent) == "" {
+		return sql.NullInt64{}, nil
+	}
+
+	category, err := i.get(parent)
+	if err != nil {
+		return sql.NullInt64{}, fmt.Errorf("parent category: %w", err)
+	}
+
+	return sql.NullInt64{Int64: category.ID, Valid: true}, nil
+}
+
+// foldCategoryName reduces a category name to the key used for matching
+func foldCategoryName(name string) string {
+	return accentFolder.Replace(strings.Join(strings.Fields(strings.ToLower(name)), " "))
+}
+
+// dbCategoryToModel converts a DB category to a model category
+func dbCategoryToModel(c db.Category, synonyms []string) models.Category {
+	category := models.Category{
+		ID:        c.ID,
+		Name:      c.Name,
+		Synonyms:  synonyms,
+		UpdatedAt: c.UpdatedAt,
+	}
+
+	if category.Synonyms == nil {
+		category.Synonyms = []string{}
+	}
+
+	if c.ParentID.Valid {
+		category.ParentID = &c.ParentID.Int64
+	}
+
+	return category
+}
@@ -0,0 +1,371 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lhommenul/brique/core/db"
+	"github.com/lhommenul/brique/core/models"
+)
+
+// importColumnHeaders are the headers recognized for each item field, folded
+// by foldProductName. They include the headers of the CSV export.
+var importColumnHeaders = map[string][]string{
+	models.ImportFieldName:         {"name", "nom"},
+	models.ImportFieldCategory:     {"category", "categorie"},
+	models.ImportFieldBrand:        {"brand", "marque"},
+	models.ImportFieldModel:        {"model", "modele"},
+	models.ImportFieldSerialNumber: {"serialnumber", "serial", "numerodeserie"},
+	models.ImportFieldPurchaseDate: {"purchasedate", "purchased", "datedachat"},
+	models.ImportFieldNotes:        {"notes", "note"},
+	models.ImportFieldTags:         {"tags", "tag"},
+}
+
+// importIgnoredHeaders are the columns of the CSV export that are not
+// imported, folded by foldProductName
+var importIgnoredHeaders = map[string]bool{
+	"id":             true,
+	"createdat":      true,
+	"updatedat":      true,
+	"datedecreation": true,
+}
+
+// importDateLayouts are the accepted formats of purchase dates
+var importDateLayouts = []string{"2006-01-02", "02/01/2006", "2006/01/02"}
+
+// importColumns locates the item fields in the rows of a CSV file
+type importColumns struct {
+	fields     map[string]int // Item field -> column index
+	attributes map[string]int // Attribute key -> column index
+	headers    map[string]string
+}
+
+// csvRecord is a row of a CSV file with its line number
+type csvRecord struct {
+	line   int
+	fields []string
+}
+
+// ImportCSV imports items from a CSV file with a header row. Columns are
+// matched to item fields by their header or by options.Columns, and the other
+// columns named like attribute keys become custom attributes. Rows with the
+// brand, model and serial number of an item already in the inventory, or of
+// an earlier row, are skipped as duplicates. Invalid rows are reported and
+// skipped. The other rows are imported in a single transaction, unless
+// options.DryRun is set.
+func (s *BackpackService) ImportCSV(ctx context.Context, r io.Reader, options models.CSVImportOptions) (*models.ImportReport, error) {
+	records, err := readCSV(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("the CSV file is empty")
+	}
+
+	columns, err := mapImportColumns(records[0].fields, options.Columns)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ImportReport{
+		DryRun:  options.DryRun,
+		Columns: columns.headers,
+		Rows:    []models.ImportRow{},
+	}
+
+	err = s.inTx(ctx, func(tx *BackpackService) error {
+		identities, err := itemIdentities(ctx, tx.queries, tx.keyring)
+		if err != nil {
+			return err
+		}
+		lines := make(map[string]int)
+
+		for _, record := range records[1:] {
+			if isBlankRecord(record.fields) {
+				continue
+			}
+
+			row := models.ImportRow{Line: record.line, Action: models.ImportCreate}
+			row.Item, row.Errors = tx.parseImportRecord(record.fields, columns)
+			identity := itemIdentity(row.Item.Brand, row.Item.Model, row.Item.SerialNumber)
+
+			switch {
+			case len(row.Errors) > 0:
+				row.Action = models.ImportInvalid
+				report.Invalid++
+			case identity != "" && identities[identity] != 0:
+				id := identities[identity]
+				row.Action = models.ImportDuplicate
+				row.DuplicateOf = &id
+				report.Duplicates++
+			case identity != "" && lines[identity] != 0:
+				row.Action = models.ImportDuplicate
+				row.DuplicateLine = lines[identity]
+				report.Duplicates++
+			default:
+				if !options.DryRun {
+					if err := tx.CreateItem(ctx, &row.Item); err != nil {
+						return fmt.Errorf("failed to import line %d: %w", row.Line, err)
+					}
+				}
+				if identity != "" {
+					lines[identity] = row.Line
+				}
+				report.Created++
+			}
+
+			report.Rows = append(report.Rows, row)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// readCSV reads every row of a CSV file. The separator is a comma, a
+// semicolon or a tab, whichever the header uses the most.
+func readCSV(r io.Reader) ([]csvRecord, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV file: %w", err)
+	}
+	data = bytes.TrimPrefix(data, []byte("\ufeff")) // Byte order mark of spreadsheet exports
+
+	header, _, _ := bytes.Cut(data, []byte("\n"))
+	comma := ','
+	for _, candidate := range []rune{';', '\t'} {
+		if bytes.Count(header, []byte(string(candidate))) > bytes.Count(header, []byte(string(comma))) {
+			comma = candidate
+		}
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+
+	var records []csvRecord
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV file: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		records = append(records, csvRecord{line: line, fields: fields})
+	}
+
+	return records, nil
+}
+
+// mapImportColumns locates the item fields in a CSV header. The given columns
+// come first, then the columns recognized by their header.
+func mapImportColumns(header []string, given map[string]string) (importColumns, error) {
+	columns := importColumns{
+		fields:     make(map[string]int),
+		attributes: make(map[string]int),
+		headers:    make(map[string]string),
+	}
+	used := make(map[int]bool)
+
+	for field, column := range given {
+		index := findColumn(header, column)
+		if index < 0 {
+			return columns, fmt.Errorf("column not found: %s", column)
+		}
+
+		if key, ok := strings.CutPrefix(field, models.ImportFieldAttribute); ok {
+			if !attributeKeyPattern.MatchString(key) {
+				return columns, fmt.Errorf("invalid attribute key %q: use lowercase letters, digits and underscores", key)
+			}
+			columns.attributes[key] = index
+		} else if _, ok := importColumnHeaders[field]; ok {
+			columns.fields[field] = index
+		} else {
+			return columns, fmt.Errorf("unknown item field: %s", field)
+		}
+
+		columns.headers[field] = header[index]
+		used[index] = true
+	}
+
+	for index, name := range header {
+		if used[index] {
+			continue
+		}
+
+		folded := foldProductName(name)
+		if importIgnoredHeaders[folded] {
+			continue
+		}
+
+		if field := importFieldOf(folded); field != "" {
+			if _, mapped := columns.fields[field]; !mapped {
+				columns.fields[field] = index
+				columns.headers[field] = name
+			}
+			continue
+		}
+
+		key := strings.TrimSpace(name)
+		if _, mapped := columns.attributes[key]; !mapped && attributeKeyPattern.MatchString(key) {
+			columns.attributes[key] = index
+			columns.headers[models.ImportFieldAttribute+key] = name
+		}
+	}
+
+	if _, ok := columns.fields[models.ImportFieldName]; !ok {
+		return columns, errors.New("no column holds the item name: map one to the name field")
+	}
+
+	return columns, nil
+}
+
+// findColumn returns the index of a column by its header, compared as is then
+// folded, or -1
+func findColumn(header []string, column string) int {
+	for index, name := range header {
+		if strings.TrimSpace(name) == strings.TrimSpace(column) {
+			return index
+		}
+	}
+	for index, name := range header {
+		if foldProductName(name) == foldProductName(column) {
+			return index
+		}
+	}
+	return -1
+}
+
+// importFieldOf returns the item field recognized for a folded header, if any
+func importFieldOf(folded string) string {
+	for field, headers := range importColumnHeaders {
+		for _, header := range headers {
+			if header == folded {
+				return field
+			}
+		}
+	}
+	return ""
+}
+
+// parseImportRecord reads an item from a row, along with the reasons it can't
+// be imported
+func (s *BackpackService) parseImportRecord(fields []string, columns importColumns) (models.Item, []string) {
+	value := func(index int) string {
+		if index < len(fields) {
+			return strings.TrimSpace(fields[index])
+		}
+		return ""
+	}
+	field := func(name string) string {
+		if index, ok := columns.fields[name]; ok {
+			return value(index)
+		}
+		return ""
+	}
+
+	item := models.Item{
+		Name:         field(models.ImportFieldName),
+		Category:     field(models.ImportFieldCategory),
+		Brand:        field(models.ImportFieldBrand),
+		Model:        field(models.ImportFieldModel),
+		SerialNumber: field(models.ImportFieldSerialNumber),
+		Notes:        field(models.ImportFieldNotes),
+		Tags: normalizeTags(strings.FieldsFunc(field(models.ImportFieldTags), func(r rune) bool {
+			return r == ',' || r == ';'
+		})),
+	}
+
+	var errs []string
+	if item.Name == "" {
+		errs = append(errs, "the name is required")
+	}
+
+	if date := field(models.ImportFieldPurchaseDate); date != "" {
+		purchaseDate, err := parseImportDate(date)
+		if err != nil {
+			errs = append(errs, err.Error())
+		} else {
+			item.PurchaseDate = &purchaseDate
+		}
+	}
+
+	var attributes []models.Attribute
+	for key, index := range columns.attributes {
+		if v := value(index); v != "" {
+			attributes = append(attributes, models.Attribute{Key: key, Value: v})
+		}
+	}
+	sort.Slice(attributes, func(i, j int) bool {
+		return attributes[i].Key < attributes[j].Key
+	})
+	normalized, err := s.normalizeAttributes(item.Category, attributes)
+	if err != nil {
+		errs = append(errs, err.Error())
+	} else {
+		item.Attributes = normalized
+	}
+
+	return item, errs
+}
+
+func parseImportDate(value string) (time.Time, error) {
+	for _, layout := range importDateLayouts {
+		if date, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid purchase date %q: expected YYYY-MM-DD", value)
+}
+
+func isBlankRecord(fields []string) bool {
+	for _, field := range fields {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// itemIdentity identifies an item by its brand, model and serial number, the
+// way imports find duplicates. Items without serial number have none.
+func itemIdentity(brand, model, serialNumber string) string {
+	serialNumber = strings.ToLower(strings.TrimSpace(serialNumber))
+	if serialNumber == "" {
+		return ""
+	}
+	return foldProductName(brand) + "/" + foldProductName(model) + "/" + serialNumber
+}
+
+// itemIdentities returns the IDs of the items of the inventory by identity
+func itemIdentities(ctx context.Context, q *db.Queries, keyring *Keyring) (map[string]int64, error) {
+	items, err := q.GetAllItems(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get items: %w", err)
+	}
+
+	identities := make(map[string]int64, len(items))
+	for _, item := range items {
+		serialNumber, err := keyring.openField(item.SerialNumber, serialNumberAD)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt item %d: %w", item.ID, err)
+		}
+		if identity := itemIdentity(item.Brand, item.Model, serialNumber); identity != "" {
+			identities[identity] = item.ID
+		}
+	}
+	return identities, nil
+}
@@ -26,6 +26,7 @@ type DiscoveryService struct {
 	port         int
 	logger       *slog.Logger
 	gossipSvc    *GossipService
+	interval     time.Duration // Between two browses
 
 	server  *mdns.Server
 	browser chan *mdns.ServiceEntry
@@ -40,11 +41,17 @@ func NewDiscoveryService(instanceID, instanceName string, port int, logger *slog
 		port:         port,
 		logger:       logger,
 		gossipSvc:    gossipSvc,
+		interval:     10 * time.Second,
 		browser:      make(chan *mdns.ServiceEntry, 32),
 		stopCh:       make(chan struct{}),
 	}
 }
 
+// SetBrowseInterval sets how often other instances are looked for
+func (d *DiscoveryService) SetBrowseInterval(interval time.Duration) {
+	d.interval = interval
+}
+
 // Start begins the discovery service (announce and browse)
 func (d *DiscoveryService) Start(ctx context.Context) error {
 	// Start announcing this instance
@@ -127,7 +134,7 @@ func (d *DiscoveryService) startAnnouncing() error {
 
 // startBrowsing continuously browses for other Brique instances
 func (d *DiscoveryService) startBrowsing(ctx context.Context) {
-	ticker := time.NewTicker(10 * time.Second) // Browse every 10 seconds
+	ticker := time.NewTicker(d.interval)
 	defer ticker.Stop()
 
 	// Initial browse
@@ -0,0 +1,318 @@
+package services
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Encrypted files are a header followed by chunks of up to
+// encryptedChunkSize bytes, each sealed with XChaCha20-Poly1305 under a nonce
+// made of the random prefix of the header and the chunk number. The header and
+// whether the chunk is the last one are authenticated with each chunk, so that
+// a file cannot be truncated, reordered or moved to another key unnoticed,
+// while any part of it can be read without decrypting the rest.
+const (
+	encryptedChunkSize = 64 * 1024
+	noncePrefixSize    = chacha20poly1305.NonceSizeX - 8
+
+	// Asset files: magic, version, data key ID, nonce prefix
+	encryptedFileMagic      = "BRQE\x01"
+	encryptedFileHeaderSize = len(encryptedFileMagic) + 8 + noncePrefixSize
+
+	// Bundles, exports encrypted with a passphrase: magic, version, Argon2id
+	// salt, time, memory and threads, nonce prefix
+	bundleMagic      = "BRQB\x01"
+	bundleHeaderSize = len(bundleMagic) + kdfSaltSize + 4 + 4 + 1 + noncePrefixSize
+
+	// bundleMaxKDFFactor bounds the Argon2id parameters read from a bundle,
+	// as a multiple of those written, so that a crafted bundle can't exhaust
+	// the memory or CPU before its passphrase is checked
+	bundleMaxKDFFactor = 4
+)
+
+// encryptingWriter encrypts what is written to it. It must be closed for the
+// last chunk to be written.
+type encryptingWriter struct {
+	w       io.Writer
+	closer  io.Closer // Closed along with the writer, if any
+	aead    cipher.AEAD
+	header  []byte
+	buf     []byte
+	counter uint64
+}
+
+// newEncryptingWriter writes the header, completed with a random nonce
+// prefix, and returns a writer encrypting to w
+func newEncryptingWriter(w io.Writer, key []byte, header []byte) (*encryptingWriter, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := make([]byte, noncePrefixSize)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	header = append(header, prefix...)
+
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	return &encryptingWriter{
+		w:      w,
+		aead:   aead,
+		header: header,
+		buf:    make([]byte, 0, encryptedChunkSize),
+	}, nil
+}
+
+func (e *encryptingWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		// A full chunk is only sealed once more data comes, the last chunk
+		// being sealed differently
+		if len(e.buf) == encryptedChunkSize {
+			if err := e.seal(false); err != nil {
+				return written, err
+			}
+		}
+
+		n := copy(e.buf[len(e.buf):encryptedChunkSize], p)
+		e.buf = e.buf[:len(e.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// Close seals the last chunk and closes the underlying file, if any
+func (e *encryptingWriter) Close() error {
+	err := e.seal(true)
+	if e.closer != nil {
+		if closeErr := e.closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+func (e *encryptingWriter) seal(last bool) error {
+	sealed := e.aead.Seal(nil, chunkNonce(e.header, e.counter), e.buf, chunkAD(e.header, last))
+	if _, err := e.w.Write(sealed); err != nil {
+		return err
+	}
+	e.counter++
+	e.buf = e.buf[:0]
+	return nil
+}
+
+// errEncryptedFileTruncated is returned for a file too short to hold its last chunk
+var errEncryptedFileTruncated = errors.New("encrypted file is truncated")
+
+// decryptingReader reads an encrypted file, decrypting a chunk at a time
+type decryptingReader struct {
+	r      io.ReaderAt
+	closer io.Closer // Closed along with the reader, if any
+	aead   cipher.AEAD
+	header []byte
+	body   int64 // Size of the chunks
+	chunks int64
+	size   int64 // Of the plaintext
+	offset int64
+	chunk  int64 // Chunk held in plain, -1 for none
+	plain  []byte
+	sealed []byte
+}
+
+// newDecryptingReader decrypts the file of the given total size, whose
+// header was read. The last chunk is checked up front, for a truncated file
+// or a wrong key to be reported on opening.
+func newDecryptingReader(r io.ReaderAt, total int64, key []byte, header []byte) (*decryptingReader, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, err
+	}
+
+	sealedChunkSize := int64(encryptedChunkSize + aead.Overhead())
+	body := total - int64(len(header))
+	chunks := (body + sealedChunkSize - 1) / sealedChunkSize
+	if chunks == 0 || body-(chunks-1)*sealedChunkSize < int64(aead.Overhead()) {
+		return nil, errEncryptedFileTruncated
+	}
+
+	d := &decryptingReader{
+		r:      r,
+		aead:   aead,
+		header: header,
+		body:   body,
+		chunks: chunks,
+		size:   body - chunks*int64(aead.Overhead()),
+		chunk:  -1,
+		sealed: make([]byte, sealedChunkSize),
+	}
+	if err := d.load(chunks - 1); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Size returns the size of the plaintext
+func (d *decryptingReader) Size() int64 {
+	return d.size
+}
+
+func (d *decryptingReader) Read(p []byte) (int, error) {
+	if d.offset >= d.size {
+		return 0, io.EOF
+	}
+
+	index := d.offset / encryptedChunkSize
+	if index != d.chunk {
+		if err := d.load(index); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, d.plain[d.offset-index*encryptedChunkSize:])
+	d.offset += int64(n)
+	return n, nil
+}
+
+func (d *decryptingReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += d.offset
+	case io.SeekEnd:
+		offset += d.size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	d.offset = offset
+	return offset, nil
+}
+
+func (d *decryptingReader) Close() error {
+	if d.closer != nil {
+		return d.closer.Close()
+	}
+	return nil
+}
+
+// load decrypts a chunk
+func (d *decryptingReader) load(index int64) error {
+	sealedChunkSize := int64(len(d.sealed))
+	start := index * sealedChunkSize
+	sealed := d.sealed[:min(sealedChunkSize, d.body-start)]
+	if _, err := d.r.ReadAt(sealed, int64(len(d.header))+start); err != nil {
+		return fmt.Errorf("failed to read encrypted file: %w", err)
+	}
+
+	plain, err := d.aead.Open(d.plain[:0], chunkNonce(d.header, uint64(index)), sealed, chunkAD(d.header, index == d.chunks-1))
+	if err != nil {
+		d.chunk = -1
+		return fmt.Errorf("failed to decrypt file: %w", err)
+	}
+	d.plain = plain
+	d.chunk = index
+	return nil
+}
+
+// chunkNonce is the nonce prefix ending the header followed by the chunk number
+func chunkNonce(header []byte, counter uint64) []byte {
+	nonce := make([]byte, chacha20poly1305.NonceSizeX)
+	copy(nonce, header[len(header)-noncePrefixSize:])
+	binary.BigEndian.PutUint64(nonce[noncePrefixSize:], counter)
+	return nonce
+}
+
+// chunkAD authenticates the header, and whether the chunk is the last one
+func chunkAD(header []byte, last bool) []byte {
+	ad := append([]byte{}, header...)
+	if last {
+		return append(ad, 1)
+	}
+	return append(ad, 0)
+}
+
+// encryptedFileHeader starts the header of an asset file encrypted with a data key
+func encryptedFileHeader(keyID int64) []byte {
+	header := make([]byte, 0, encryptedFileHeaderSize)
+	header = append(header, encryptedFileMagic...)
+	return binary.BigEndian.AppendUint64(header, uint64(keyID))
+}
+
+// encryptedFileKeyID reads the data key ID from the header of an asset file
+func encryptedFileKeyID(header []byte) int64 {
+	return int64(binary.BigEndian.Uint64(header[len(encryptedFileMagic):]))
+}
+
+// NewBundleWriter returns a writer encrypting to w with a key derived from the
+// passphrase, for exports to be kept or carried around safely. It must be
+// closed for the bundle to be complete.
+func NewBundleWriter(w io.Writer, passphrase string) (io.WriteCloser, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("passphrase is required")
+	}
+
+	salt := make([]byte, kdfSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	header := make([]byte, 0, bundleHeaderSize)
+	header = append(header, bundleMagic...)
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, kdfTime)
+	header = binary.BigEndian.AppendUint32(header, kdfMemory)
+	header = append(header, kdfThreads)
+
+	key := argon2.IDKey([]byte(passphrase), salt, kdfTime, kdfMemory, kdfThreads, chacha20poly1305.KeySize)
+	return newEncryptingWriter(w, key, header)
+}
+
+// IsBundle reports whether data starts like a bundle written by NewBundleWriter
+func IsBundle(data []byte) bool {
+	return len(data) >= len(bundleMagic) && string(data[:len(bundleMagic)]) == bundleMagic
+}
+
+// OpenBundle decrypts a bundle of the given size with its passphrase
+func OpenBundle(r io.ReaderAt, size int64, passphrase string) (io.ReadSeeker, error) {
+	header := make([]byte, bundleHeaderSize)
+	if _, err := r.ReadAt(header, 0); err != nil || !IsBundle(header) {
+		return nil, fmt.Errorf("not an encrypted bundle")
+	}
+
+	offset := len(bundleMagic)
+	salt := header[offset : offset+kdfSaltSize]
+	offset += kdfSaltSize
+	time := binary.BigEndian.Uint32(header[offset:])
+	memory := binary.BigEndian.Uint32(header[offset+4:])
+	threads := header[offset+8]
+	if time == 0 || memory == 0 || threads == 0 ||
+		time > bundleMaxKDFFactor*kdfTime || memory > bundleMaxKDFFactor*kdfMemory || threads > bundleMaxKDFFactor*kdfThreads {
+		return nil, fmt.Errorf("invalid bundle parameters")
+	}
+
+	key := argon2.IDKey([]byte(passphrase), salt, time, memory, threads, chacha20poly1305.KeySize)
+	reader, err := newDecryptingReader(r, size, key, header)
+	if errors.Is(err, errEncryptedFileTruncated) {
+		return nil, err
+	}
+	if err != nil {
+		// The key derived from a wrong passphrase fails like a damaged bundle
+		return nil, ErrWrongPassphrase
+	}
+	return reader, nil
+}
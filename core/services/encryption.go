@@ -0,0 +1,432 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/lhommenul/brique/core/db"
+	"github.com/lhommenul/brique/core/models"
+)
+
+// Keyring returns the keys of the service, to be shared with the gossip
+// service working on the same database
+func (s *BackpackService) Keyring() *Keyring {
+	return s.keyring
+}
+
+// EncryptionStatus tells whether the data is encrypted at rest, and whether
+// its keys are unlocked
+func (s *BackpackService) EncryptionStatus(ctx context.Context) (*models.EncryptionStatus, error) {
+	rows, err := s.queries.GetEncryptionKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get encryption keys: %w", err)
+	}
+
+	status := &models.EncryptionStatus{Enabled: len(rows) > 0, Unlocked: len(rows) > 0}
+	for _, row := range rows {
+		if _, ok := s.keyring.key(row.ID); !ok {
+			status.Unlocked = false
+		}
+	}
+	if status.Enabled {
+		current := rows[len(rows)-1]
+		status.KeyID = current.ID
+		status.KeySince = &current.CreatedAt
+		status.OldKeys = len(rows) - 1
+	}
+
+	return status, nil
+}
+
+// UnlockEncryption unwraps the keys with the passphrase. It is meant to be
+// called at startup, before the encrypted data is used.
+func (s *BackpackService) UnlockEncryption(ctx context.Context, passphrase string) error {
+	return s.keyring.Unlock(ctx, s.queries, passphrase)
+}
+
+// EnableEncryption creates a data key wrapped with the passphrase, then
+// encrypts the sensitive item fields and the asset files with it
+func (s *BackpackService) EnableEncryption(ctx context.Context, passphrase string) (*models.EncryptionReport, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("passphrase is required")
+	}
+
+	status, err := s.EncryptionStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if status.Enabled {
+		return nil, ErrEncryptionEnabled
+	}
+
+	return s.reencrypt(ctx, passphrase)
+}
+
+// RotateEncryptionKey replaces the data keys by a new one wrapped with the
+// passphrase, which may be a new one, and re-encrypts the data with it. The
+// retired keys are deleted.
+func (s *BackpackService) RotateEncryptionKey(ctx context.Context, passphrase string) (*models.EncryptionReport, error) {
+	status, err := s.EncryptionStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !status.Enabled {
+		return nil, ErrEncryptionDisabled
+	}
+	if passphrase == "" {
+		return nil, fmt.Errorf("passphrase is required")
+	}
+
+	return s.reencrypt(ctx, passphrase)
+}
+
+// DisableEncryption decrypts the data and deletes the keys
+func (s *BackpackService) DisableEncryption(ctx context.Context) (*models.EncryptionReport, error) {
+	status, err := s.EncryptionStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !status.Enabled {
+		return nil, ErrEncryptionDisabled
+	}
+
+	return s.reencrypt(ctx, "")
+}
+
+// ChangePassphrase wraps the data keys with a new passphrase, leaving the
+// data encrypted as it is
+func (s *BackpackService) ChangePassphrase(ctx context.Context, passphrase string) error {
+	return s.inTx(ctx, func(tx *BackpackService) error {
+		rows, err := tx.queries.GetEncryptionKeys(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get encryption keys: %w", err)
+		}
+		if len(rows) == 0 {
+			return ErrEncryptionDisabled
+		}
+
+		for _, row := range rows {
+			key, ok := tx.keyring.key(row.ID)
+			if !ok {
+				return ErrEncryptionLocked
+			}
+
+			params, err := wrapDataKey(key, passphrase)
+			if err != nil {
+				return err
+			}
+			params.ID = row.ID
+			if err := tx.queries.UpdateEncryptionKeyWrap(ctx, params); err != nil {
+				return fmt.Errorf("failed to update encryption key: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// reencrypt rewrites the sensitive item fields and the asset files with a
+// new data key wrapped with the passphrase, or in plaintext without one, then
+// deletes the former keys. Asset files are staged and moved into place
+// through the file outbox, so that an interrupted run is completed on the
+// next start. Thumbnails are deleted, to be generated again with the new key.
+func (s *BackpackService) reencrypt(ctx context.Context, passphrase string) (*models.EncryptionReport, error) {
+	report := &models.EncryptionReport{}
+	var keyID int64
+	var key []byte
+
+	err := s.inTx(ctx, func(tx *BackpackService) error {
+		retired, err := tx.queries.GetEncryptionKeys(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get encryption keys: %w", err)
+		}
+		for _, row := range retired {
+			if _, ok := tx.keyring.key(row.ID); !ok {
+				return ErrEncryptionLocked
+			}
+		}
+
+		if passphrase != "" {
+			var params db.CreateEncryptionKeyParams
+			key, params, err = newDataKey(passphrase)
+			if err != nil {
+				return err
+			}
+			created, err := tx.queries.CreateEncryptionKey(ctx, params)
+			if err != nil {
+				return fmt.Errorf("failed to create encryption key: %w", err)
+			}
+			keyID = created.ID
+		}
+
+		items, err := tx.queries.GetAllItems(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get items: %w", err)
+		}
+		for _, item := range items {
+			serialNumber, err := tx.keyring.resealField(item.SerialNumber, serialNumberAD, keyID, key)
+			if err != nil {
+				return fmt.Errorf("failed to encrypt item %d: %w", item.ID, err)
+			}
+			notes, err := tx.keyring.resealField(item.Notes, notesAD, keyID, key)
+			if err != nil {
+				return fmt.Errorf("failed to encrypt item %d: %w", item.ID, err)
+			}
+			if serialNumber == item.SerialNumber && notes == item.Notes {
+				continue
+			}
+
+			// Left out of the sync: the item itself did not change
+			if err := tx.queries.SetItemSecrets(ctx, db.SetItemSecretsParams{
+				SerialNumber: serialNumber,
+				Notes:        notes,
+				ID:           item.ID,
+			}); err != nil {
+				return fmt.Errorf("failed to update item: %w", err)
+			}
+			report.Items++
+		}
+
+		assets, err := tx.queries.GetAllAssets(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get assets: %w", err)
+		}
+		for _, asset := range assets {
+			rewritten, err := tx.reencryptFile(ctx, asset.FilePath, keyID, key)
+			if err != nil {
+				return fmt.Errorf("failed to encrypt asset %d: %w", asset.ID, err)
+			}
+			if rewritten {
+				report.Assets++
+			}
+
+			if asset.Type == string(models.AssetTypePhoto) {
+				if err := tx.queueFileDelete(ctx, tx.thumbnailPath(asset.ID)); err != nil {
+					return err
+				}
+			}
+		}
+
+		// The signing key of the announcements, once created
+		instanceKey, err := tx.queries.GetInstanceKey(ctx)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to get instance key: %w", err)
+		}
+		if err == nil {
+			privateKey, err := tx.keyring.resealField(string(instanceKey.PrivateKey), instanceKeyAD, keyID, key)
+			if err != nil {
+				return fmt.Errorf("failed to encrypt instance key: %w", err)
+			}
+			if err := tx.queries.SetInstancePrivateKey(ctx, []byte(privateKey)); err != nil {
+				return fmt.Errorf("failed to update instance key: %w", err)
+			}
+		}
+
+		for _, row := range retired {
+			if err := tx.queries.DeleteEncryptionKey(ctx, row.ID); err != nil {
+				return fmt.Errorf("failed to delete encryption key: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if key != nil {
+		s.keyring.add(keyID, key)
+	}
+	return report, nil
+}
+
+// reencryptFile stages a copy of an asset file encrypted with the given key,
+// or in plaintext without one, to replace it once the transaction commits.
+// Files already in plaintext are left as they are when decrypting. Must be
+// called within a transaction.
+func (s *BackpackService) reencryptFile(ctx context.Context, path string, keyID int64, key []byte) (bool, error) {
+	source, err := s.openStoredFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		s.logger.Warn("Asset file not found", "path", path)
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer source.Close()
+
+	if _, encrypted := source.(*decryptingReader); !encrypted && key == nil {
+		return false, nil
+	}
+
+	if err := os.MkdirAll(s.stagingDir(), 0755); err != nil {
+		return false, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+
+	stagedFile, err := os.CreateTemp(s.stagingDir(), "asset-*"+filepath.Ext(path))
+	if err != nil {
+		return false, fmt.Errorf("failed to create destination file: %w", err)
+	}
+	*s.staged = append(*s.staged, stagedFile.Name())
+
+	var dest io.WriteCloser = stagedFile
+	if key != nil {
+		writer, err := newEncryptingWriter(stagedFile, key, encryptedFileHeader(keyID))
+		if err != nil {
+			stagedFile.Close()
+			return false, err
+		}
+		writer.closer = stagedFile
+		dest = writer
+	}
+
+	if _, err := io.Copy(dest, source); err != nil {
+		dest.Close()
+		return false, fmt.Errorf("failed to copy file: %w", err)
+	}
+	if err := dest.Close(); err != nil {
+		return false, fmt.Errorf("failed to write file: %w", err)
+	}
+
+	if err := s.queueFileMove(ctx, stagedFile.Name(), path); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// encryptTo returns a writer encrypting to file with the current data key,
+// or file itself when encryption is disabled. Closing the writer closes file.
+func (s *BackpackService) encryptTo(ctx context.Context, file *os.File) (io.WriteCloser, error) {
+	keyID, key, err := s.keyring.currentKey(ctx, s.queries)
+	if err != nil || key == nil {
+		return file, err
+	}
+
+	writer, err := newEncryptingWriter(file, key, encryptedFileHeader(keyID))
+	if err != nil {
+		return nil, err
+	}
+	writer.closer = file
+	return writer, nil
+}
+
+// openStoredFile opens a file of the assets directory, decrypting it when it
+// was written encrypted
+func (s *BackpackService) openStoredFile(path string) (io.ReadSeekCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, encryptedFileHeaderSize)
+	if _, err := io.ReadFull(file, header); err != nil || string(header[:len(encryptedFileMagic)]) != encryptedFileMagic {
+		// Written before encryption was enabled
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			file.Close()
+			return nil, err
+		}
+		return file, nil
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	key, ok := s.keyring.key(encryptedFileKeyID(header))
+	if !ok {
+		file.Close()
+		return nil, ErrEncryptionLocked
+	}
+
+	reader, err := newDecryptingReader(file, info.Size(), key, header)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	reader.closer = file
+	return reader, nil
+}
+
+// OpenAsset opens the file of an asset, decrypted. The caller must close it.
+func (s *BackpackService) OpenAsset(ctx context.Context, assetID int64) (*models.Asset, io.ReadSeekCloser, error) {
+	asset, err := s.GetAsset(ctx, assetID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := s.openStoredFile(asset.FilePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open asset file: %w", err)
+	}
+	return asset, file, nil
+}
+
+// OpenThumbnail opens the JPEG thumbnail of a photo, decrypted, generating it
+// when missing. The caller must close it.
+func (s *BackpackService) OpenThumbnail(ctx context.Context, assetID int64) (io.ReadSeekCloser, error) {
+	path, err := s.GetThumbnail(ctx, assetID)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := s.openStoredFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open thumbnail: %w", err)
+	}
+	return file, nil
+}
+
+// SaveAsset writes the decrypted file of an asset to w
+func (s *BackpackService) SaveAsset(ctx context.Context, assetID int64, w io.Writer) (*models.Asset, error) {
+	asset, file, err := s.OpenAsset(ctx, assetID)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(w, file); err != nil {
+		return nil, fmt.Errorf("failed to copy asset file: %w", err)
+	}
+	return asset, nil
+}
+
+// sealItemSecrets encrypts the sensitive fields of an item with the current
+// key, when encryption is enabled
+func (k *Keyring) sealItemSecrets(ctx context.Context, q *db.Queries, serialNumber, notes string) (string, string, error) {
+	serialNumber, err := k.sealField(ctx, q, serialNumber, serialNumberAD)
+	if err != nil {
+		return "", "", err
+	}
+	notes, err = k.sealField(ctx, q, notes, notesAD)
+	if err != nil {
+		return "", "", err
+	}
+	return serialNumber, notes, nil
+}
+
+// openItemSecrets decrypts the sensitive fields of an item read from the database
+func (k *Keyring) openItemSecrets(item *models.Item) error {
+	var err error
+	if item.SerialNumber, err = k.openField(item.SerialNumber, serialNumberAD); err != nil {
+		return fmt.Errorf("failed to decrypt item %d: %w", item.ID, err)
+	}
+	if item.Notes, err = k.openField(item.Notes, notesAD); err != nil {
+		return fmt.Errorf("failed to decrypt item %d: %w", item.ID, err)
+	}
+	return nil
+}
+
+// resealField decrypts a field value and encrypts it again with the given
+// key, or leaves it in plaintext without one
+func (k *Keyring) resealField(value, ad string, keyID int64, key []byte) (string, error) {
+	plaintext, err := k.openField(value, ad)
+	if err != nil || plaintext == "" || key == nil {
+		return plaintext, err
+	}
+	return sealFieldWith(keyID, key, plaintext, ad)
+}
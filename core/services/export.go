@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lhommenul/brique/core/models"
+)
+
+// csvExportHeader are the fixed columns of the CSV export, before one column
+// per custom attribute key
+var csvExportHeader = []string{"ID", "Nom", "Catégorie", "Marque", "Modèle", "Numéro de série", "Date d'achat", "Notes", "Date de création", "Tags"}
+
+// ExportJSON writes the whole inventory as JSON, items with their assets, and
+// returns the number of items exported
+func (s *BackpackService) ExportJSON(ctx context.Context, w io.Writer) (int, error) {
+	items, err := s.GetAllItems(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	export := models.Export{
+		ExportDate: time.Now(),
+		Version:    models.ExportVersion,
+		Items:      make([]models.ItemWithAssets, 0, len(items)),
+		Stats:      models.ExportStats{TotalItems: len(items)},
+	}
+	for _, item := range items {
+		itemWithAssets, err := s.GetItemWithAssets(ctx, item.ID)
+		if err != nil {
+			return 0, err
+		}
+		export.Items = append(export.Items, *itemWithAssets)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(export); err != nil {
+		return 0, fmt.Errorf("failed to write export: %w", err)
+	}
+
+	return len(items), nil
+}
+
+// ExportCSV writes the items as CSV, one row per item and one column per
+// custom attribute key, and returns the number of items exported
+func (s *BackpackService) ExportCSV(ctx context.Context, w io.Writer) (int, error) {
+	items, err := s.GetAllItems(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	attributeKeys := []string{}
+	seenKeys := make(map[string]bool)
+	for _, item := range items {
+		for _, attr := range item.Attributes {
+			if !seenKeys[attr.Key] {
+				seenKeys[attr.Key] = true
+				attributeKeys = append(attributeKeys, attr.Key)
+			}
+		}
+	}
+	sort.Strings(attributeKeys)
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(append(append([]string{}, csvExportHeader...), attributeKeys...)); err != nil {
+		return 0, fmt.Errorf("failed to write export: %w", err)
+	}
+
+	for _, item := range items {
+		purchaseDate := ""
+		if item.PurchaseDate != nil {
+			purchaseDate = item.PurchaseDate.Format("2006-01-02")
+		}
+
+		row := []string{
+			strconv.FormatInt(item.ID, 10),
+			item.Name,
+			item.Category,
+			item.Brand,
+			item.Model,
+			item.SerialNumber,
+			purchaseDate,
+			item.Notes,
+			item.CreatedAt.Format("2006-01-02 15:04:05"),
+			strings.Join(item.Tags, ", "),
+		}
+
+		values := make(map[string]string, len(item.Attributes))
+		for _, attr := range item.Attributes {
+			values[attr.Key] = attr.Value
+		}
+		for _, key := range attributeKeys {
+			row = append(row, values[key])
+		}
+
+		if err := writer.Write(row); err != nil {
+			return 0, fmt.Errorf("failed to write export: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return 0, fmt.Errorf("failed to write export: %w", err)
+	}
+
+	return len(items), nil
+}
+
+// ImportJSON imports the items of a JSON export, skipping those already in
+// the inventory as ImportItems does. Assets are not imported since their files
+// are not part of the export.
+func (s *BackpackService) ImportJSON(ctx context.Context, r io.Reader) (imported int, skipped int, err error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read export: %w", err)
+	}
+
+	var header struct {
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return 0, 0, fmt.Errorf("invalid export: %w", err)
+	}
+
+	var items []models.Item
+	switch header.Version {
+	case models.ExportVersion:
+		var export models.Export
+		if err := json.Unmarshal(data, &export); err != nil {
+			return 0, 0, fmt.Errorf("invalid export: %w", err)
+		}
+		for _, item := range export.Items {
+			items = append(items, item.Item)
+		}
+	case "1.0":
+		if items, err = readLegacyExport(data); err != nil {
+			return 0, 0, err
+		}
+	default:
+		return 0, 0, fmt.Errorf("unsupported export version %q", header.Version)
+	}
+
+	// IDs and linked records belong to the exporting inventory
+	for i := range items {
+		items[i].ID = 0
+		items[i].ProductModelID = nil
+		items[i].Warranty = nil
+		items[i].Loan = nil
+		items[i].Repairs = nil
+	}
+
+	return s.ImportItems(ctx, items)
+}
+
+// readLegacyExport reads the items of a version 1.0 export, which used the
+// field names of the GUI
+func readLegacyExport(data []byte) ([]models.Item, error) {
+	var export struct {
+		Items []struct {
+			Item struct {
+				Name         string             `json:"name"`
+				Category     string             `json:"category"`
+				Brand        string             `json:"brand"`
+				Model        string             `json:"model"`
+				SerialNumber string             `json:"serialNumber"`
+				PurchaseDate *string            `json:"purchaseDate"`
+				PhotoPath    string             `json:"photoPath"`
+				Notes        string             `json:"notes"`
+				Tags         []string           `json:"tags"`
+				Attributes   []models.Attribute `json:"attributes"`
+			} `json:"item"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("invalid export: %w", err)
+	}
+
+	items := make([]models.Item, len(export.Items))
+	for i, entry := range export.Items {
+		items[i] = models.Item{
+			Name:         entry.Item.Name,
+			Category:     entry.Item.Category,
+			Brand:        entry.Item.Brand,
+			Model:        entry.Item.Model,
+			SerialNumber: entry.Item.SerialNumber,
+			PhotoPath:    entry.Item.PhotoPath,
+			Notes:        entry.Item.Notes,
+			Tags:         entry.Item.Tags,
+			Attributes:   entry.Item.Attributes,
+		}
+		if entry.Item.PurchaseDate != nil {
+			purchaseDate, err := time.ParseInLocation("2006-01-02", *entry.Item.PurchaseDate, time.Local)
+			if err != nil {
+				return nil, fmt.Errorf("invalid purchase date of %s: %w", entry.Item.Name, err)
+			}
+			items[i].PurchaseDate = &purchaseDate
+		}
+	}
+
+	return items, nil
+}
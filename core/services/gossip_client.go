@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lhommenul/brique/core/models"
+)
+
+// peerRequestTimeout bounds every HTTP call made to a peer
+const peerRequestTimeout = 30 * time.Second
+
+// BuildPeerURL constructs the full URL for a peer endpoint
+// If the address already contains http:// or https://, use it as-is
+// Otherwise, detect if it's a .traefik.me domain or port 443 for HTTPS, else use HTTP
+func BuildPeerURL(address, path string) string {
+	if strings.HasPrefix(address, "http://") || strings.HasPrefix(address, "https://") {
+		return fmt.Sprintf("%s%s", address, path)
+	}
+
+	// Detect if HTTPS should be used
+	useHTTPS := strings.Contains(address, ".traefik.me") ||
+		strings.HasSuffix(address, ":443") ||
+		!strings.Contains(address, ":") // No port specified, assume HTTPS
+
+	protocol := "http"
+	if useHTTPS {
+		protocol = "https"
+	}
+
+	return fmt.Sprintf("%s://%s%s", protocol, address, path)
+}
+
+// searchPeer runs a documentation search against a single peer
+func (s *GossipService) searchPeer(ctx context.Context, peer models.Peer, query models.SearchQuery) ([]models.ItemWithAssets, error) {
+	params := url.Values{}
+	params.Set("brand", query.Brand)
+	params.Set("model", query.Model)
+	if query.AssetType != "" {
+		params.Set("asset_type", string(query.AssetType))
+	}
+
+	var items []models.ItemWithAssets
+	if err := s.getPeerJSON(ctx, peer.Address, "/api/v1/gossip/search?"+params.Encode(), &items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// getPeerJSON performs a GET request against a peer and decodes the JSON body
+func (s *GossipService) getPeerJSON(ctx context.Context, address, path string, out interface{}) error {
+	resp, err := s.getPeer(ctx, address, path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode peer response: %w", err)
+	}
+
+	return nil
+}
+
+// FetchPeerInfo retrieves the identity of the instance at a peer address
+func (s *GossipService) FetchPeerInfo(ctx context.Context, address string) (*models.SyncInfo, error) {
+	var info struct {
+		InstanceID   string `json:"instance_id"`
+		InstanceName string `json:"instance_name"`
+		PublicKey    string `json:"public_key"`
+		ItemCount    int    `json:"item_count"`
+	}
+	if err := s.getPeerJSON(ctx, address, "/api/v1/gossip/info", &info); err != nil {
+		return nil, err
+	}
+
+	return &models.SyncInfo{
+		InstanceID:   info.InstanceID,
+		InstanceName: info.InstanceName,
+		PublicKey:    info.PublicKey,
+		ItemCount:    info.ItemCount,
+	}, nil
+}
+
+// downloadPeerAsset downloads an asset file from a peer into dir and checks
+// its hash against the advertised one
+func (s *GossipService) downloadPeerAsset(ctx context.Context, address string, asset models.Asset, dir string) (string, error) {
+	resp, err := s.getPeer(ctx, address, fmt.Sprintf("/api/v1/gossip/assets/%d", asset.ID))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	destPath := filepath.Join(dir, fmt.Sprintf("asset_%d%s", asset.ID, filepath.Ext(asset.FilePath)))
+	destFile, err := os.Create(destPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create file: %w", err)
+	}
+	defer destFile.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(destFile, hash), resp.Body); err != nil {
+		return "", fmt.Errorf("failed to download file: %w", err)
+	}
+
+	if fileHash := fmt.Sprintf("%x", hash.Sum(nil)); asset.FileHash != "" && fileHash != asset.FileHash {
+		return "", fmt.Errorf("hash mismatch: expected %s, got %s", asset.FileHash, fileHash)
+	}
+
+	return destPath, nil
+}
+
+// FetchPeerChanges gets the items changed on a peer since the given time
+func (s *GossipService) FetchPeerChanges(ctx context.Context, address string, since time.Time) ([]models.Item, error) {
+	var changes []models.Item
+	path := "/api/v1/gossip/changes?since=" + url.QueryEscape(since.Format(time.RFC3339))
+	if err := s.getPeerJSON(ctx, address, path, &changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// downloadPeerAssets downloads the files of the given assets into dir, in the
+// same order. Assets whose hash is in known are skipped, leaving an empty path.
+func (s *GossipService) downloadPeerAssets(ctx context.Context, address string, assets []models.Asset, known map[string]int64, dir string) ([]string, error) {
+	files := make([]string, len(assets))
+	for i, asset := range assets {
+		if _, ok := known[asset.FileHash]; ok && asset.FileHash != "" {
+			continue
+		}
+
+		var err error
+		files[i], err = s.downloadPeerAsset(ctx, address, asset, dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download asset %q: %w", asset.Name, err)
+		}
+	}
+	return files, nil
+}
+
+// getPeer performs a GET request against a peer and checks the status code
+func (s *GossipService) getPeer(ctx context.Context, address, path string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, BuildPeerURL(address, path), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.httpClient.Load().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to peer: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("peer returned status %d", resp.StatusCode)
+	}
+
+	return resp, nil
+}
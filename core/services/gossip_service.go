@@ -2,8 +2,19 @@ package services
 
 import (
 	"context"
+	"crypto/ed25519"
 	"database/sql"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,40 +24,104 @@ import (
 
 // GossipService handles peer discovery and synchronization
 type GossipService struct {
+	database     *db.Database
 	queries      *db.Queries
-	instanceID   string
 	instanceName string
 	listenAddr   string
+	httpClient   atomic.Pointer[http.Client]
+	keyring      *Keyring
+	logger       *slog.Logger
 }
 
 // NewGossipService creates a new GossipService
-func NewGossipService(queries *db.Queries, instanceName, listenAddr string) *GossipService {
-	// Generate or load instance ID
-	instanceID := uuid.New().String()
-
-	return &GossipService{
-		queries:      queries,
-		instanceID:   instanceID,
+func NewGossipService(database *db.Database, instanceName, listenAddr string) *GossipService {
+	s := &GossipService{
+		database:     database,
+		queries:      db.New(database.DB),
 		instanceName: instanceName,
 		listenAddr:   listenAddr,
+		keyring:      NewKeyring(),
+		logger:       slog.Default(),
 	}
+	s.httpClient.Store(&http.Client{Timeout: peerRequestTimeout})
+	return s
+}
+
+// SetKeyring shares the keys of the backpack service, for the items
+// encrypted at rest to be synced in plaintext with peers
+func (s *GossipService) SetKeyring(keyring *Keyring) {
+	s.keyring = keyring
+}
+
+// SetLogger sets where the failures not returned, e.g. of a peer during a
+// search, are reported. It defaults to slog's default logger, on stderr.
+func (s *GossipService) SetLogger(logger *slog.Logger) {
+	s.logger = logger
+}
+
+// SetPeerTimeout bounds every request made to a peer. It may be changed
+// while requests are running, which keep their timeout.
+func (s *GossipService) SetPeerTimeout(timeout time.Duration) {
+	s.httpClient.Store(&http.Client{Timeout: timeout})
 }
 
 // GetInstanceInfo returns information about this instance
 func (s *GossipService) GetInstanceInfo(ctx context.Context) (*models.SyncInfo, error) {
+	instanceID, err := s.instanceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Created here for peers to learn it before anything is signed with it.
+	// With encryption enabled, it waits for the keys to be unlocked.
+	publicKey, err := s.instancePublicKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if publicKey == "" {
+		privateKey, err := s.instanceKey(ctx)
+		if err == nil {
+			publicKey = base64.StdEncoding.EncodeToString(privateKey.Public().(ed25519.PublicKey))
+		} else if !errors.Is(err, ErrEncryptionLocked) {
+			return nil, err
+		}
+	}
+
 	count, err := s.queries.CountItems(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to count items: %w", err)
 	}
 
 	return &models.SyncInfo{
-		InstanceID:   s.instanceID,
+		InstanceID:   instanceID,
 		InstanceName: s.instanceName,
+		PublicKey:    publicKey,
 		LastSync:     nil, // Will be set per-peer
 		ItemCount:    int(count),
 	}, nil
 }
 
+// instanceID returns the ID of this instance, created on first use and kept
+// in the database
+func (s *GossipService) instanceID(ctx context.Context) (string, error) {
+	identity, err := s.queries.GetInstanceIdentity(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Another process may create one in the meantime, the first one wins
+		if err := s.queries.CreateInstanceIdentity(ctx, db.CreateInstanceIdentityParams{
+			InstanceID: uuid.New().String(),
+			CreatedAt:  time.Now(),
+		}); err != nil {
+			return "", fmt.Errorf("failed to store instance ID: %w", err)
+		}
+		identity, err = s.queries.GetInstanceIdentity(ctx)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get instance ID: %w", err)
+	}
+
+	return identity.InstanceID, nil
+}
+
 // AddPeer adds a new peer to the list
 func (s *GossipService) AddPeer(ctx context.Context, peer *models.Peer) error {
 	// Check if peer already exists
@@ -131,7 +206,8 @@ func (s *GossipService) RemovePeer(ctx context.Context, peerID string) error {
 	return s.queries.DeletePeer(ctx, peerID)
 }
 
-// GetChanges returns items modified since a given timestamp
+// GetChanges returns items modified since a given timestamp, with their
+// attributes, tags and repair history
 func (s *GossipService) GetChanges(ctx context.Context, since time.Time) ([]models.Item, error) {
 	dbItems, err := s.queries.GetItemsModifiedSince(ctx, since)
 	if err != nil {
@@ -141,11 +217,111 @@ func (s *GossipService) GetChanges(ctx context.Context, since time.Time) ([]mode
 	items := make([]models.Item, len(dbItems))
 	for i, dbItem := range dbItems {
 		items[i] = s.dbItemToModel(dbItem)
+		if err := s.keyring.openItemSecrets(&items[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := loadItemDetails(ctx, s.queries, items); err != nil {
+		return nil, err
+	}
+
+	repairs, err := loadItemRepairs(ctx, s.queries)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		items[i].Repairs = repairs[items[i].ID]
 	}
 
 	return items, nil
 }
 
+// GetAssetTypeChanges returns asset types modified since a given timestamp
+func (s *GossipService) GetAssetTypeChanges(ctx context.Context, since time.Time) ([]models.AssetTypeDefinition, error) {
+	dbTypes, err := s.queries.GetAssetTypesModifiedSince(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get asset type changes: %w", err)
+	}
+
+	types := make([]models.AssetTypeDefinition, len(dbTypes))
+	for i, t := range dbTypes {
+		types[i] = dbAssetTypeToModel(t)
+	}
+
+	return types, nil
+}
+
+// ApplyAssetTypes merges asset types received from a peer (Last-Write-Wins)
+// and returns how many were created or updated. Built-in types are kept as
+// they are: a peer must not change what counts for health here.
+func (s *GossipService) ApplyAssetTypes(ctx context.Context, types []models.AssetTypeDefinition) (int, error) {
+	applied := 0
+	err := s.database.WithTx(ctx, func(q *db.Queries) error {
+		for _, remote := range types {
+			if !assetTypeNamePattern.MatchString(string(remote.Name)) || remote.Label == "" {
+				continue
+			}
+
+			local, err := q.GetAssetType(ctx, string(remote.Name))
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("failed to get asset type: %w", err)
+			}
+
+			if err == nil && (local.IsBuiltin || !remote.UpdatedAt.After(local.UpdatedAt)) {
+				// Local version is built in, newer or identical
+				continue
+			}
+
+			if _, err := q.UpsertAssetType(ctx, db.UpsertAssetTypeParams{
+				Name:            string(remote.Name),
+				Label:           remote.Label,
+				MimePatterns:    joinMimePatterns(remote.MimePatterns),
+				CountsForHealth: remote.CountsForHealth,
+				IsBuiltin:       false,
+				UpdatedAt:       remote.UpdatedAt,
+			}); err != nil {
+				return fmt.Errorf("failed to save asset type: %w", err)
+			}
+
+			applied++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return applied, nil
+}
+
+// SyncAssetTypesWithPeer pulls the asset types a peer changed since the last
+// sync, so that items received from it can be validated locally
+func (s *GossipService) SyncAssetTypesWithPeer(ctx context.Context, peerID string) (int, error) {
+	dbPeer, err := s.queries.GetPeer(ctx, peerID)
+	if err != nil {
+		return 0, fmt.Errorf("peer not found: %w", err)
+	}
+
+	var since time.Time
+	if dbPeer.LastSync.Valid {
+		since = dbPeer.LastSync.Time
+	}
+
+	return s.pullAssetTypes(ctx, dbPeer.Address, since)
+}
+
+// pullAssetTypes fetches asset types from a peer and applies them
+func (s *GossipService) pullAssetTypes(ctx context.Context, address string, since time.Time) (int, error) {
+	var types []models.AssetTypeDefinition
+	path := fmt.Sprintf("/api/v1/gossip/asset-types?since=%s", url.QueryEscape(since.Format(time.RFC3339)))
+	if err := s.getPeerJSON(ctx, address, path, &types); err != nil {
+		return 0, fmt.Errorf("failed to get asset types from peer: %w", err)
+	}
+
+	return s.ApplyAssetTypes(ctx, types)
+}
+
 // LogSync logs a synchronization event
 func (s *GossipService) LogSync(ctx context.Context, log *models.SyncLog) error {
 	_, err := s.queries.CreateSyncLog(ctx, db.CreateSyncLogParams{
@@ -199,6 +375,98 @@ func (s *GossipService) GetRecentSyncHistory(ctx context.Context, limit int) ([]
 	return logs, nil
 }
 
+// ErrPeerUnreachable is returned when the changes of a peer cannot be fetched
+var ErrPeerUnreachable = errors.New("failed to get changes from peer")
+
+// SyncPeer runs a whole sync with a peer: it learns the key of the peer and
+// pulls its asset types and changed items, then exchanges the documentation,
+// parts, announcements and loans shared with it. Only the items are required:
+// the other steps are reported in the warnings of the result when they fail.
+func (s *GossipService) SyncPeer(ctx context.Context, backpack *BackpackService, peer *models.Peer) (*models.SyncResult, error) {
+	var warnings []string
+	warn := func(step string, err error) {
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("failed to sync %s: %v", step, err))
+		}
+	}
+
+	// Keep the key of the peer, that it signs its requests for loans with
+	err := s.SyncPeerKey(ctx, peer.ID)
+	warn("peer key", err)
+
+	// Pull asset types first so that remote items reference known types
+	_, err = s.SyncAssetTypesWithPeer(ctx, peer.ID)
+	warn("asset types", err)
+
+	// Get remote changes since the last sync
+	var since time.Time
+	if peer.LastSync != nil {
+		since = *peer.LastSync
+	}
+	remoteChanges, err := s.FetchPeerChanges(ctx, peer.Address, since)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPeerUnreachable, err)
+	}
+
+	result, err := s.SyncWithPeer(ctx, peer.ID, remoteChanges)
+	if err != nil {
+		return nil, err
+	}
+
+	// Then exchange the documentation shared by product models
+	result.DocumentsReceived, err = s.SyncProductModelsWithPeer(ctx, backpack, peer.ID)
+	warn("product models", err)
+
+	// And the spare parts known to fit those product models
+	result.PartsReceived, err = s.SyncPartsWithPeer(ctx, peer.ID)
+	warn("parts", err)
+
+	// Help announcements spread hop by hop
+	result.AnnouncementsReceived, err = s.SyncAnnouncementsWithPeer(ctx, peer.ID)
+	warn("announcements", err)
+
+	// Loans made to this instance, kept up to date by their lender
+	result.LoansReceived, err = s.SyncLoansWithPeer(ctx, peer.ID)
+	warn("loans", err)
+
+	result.Warnings = warnings
+	return result, nil
+}
+
+// SyncPeerKey stores the announcement key a peer gives, replacing the one it
+// gave before. The key is only taken from the address of the peer.
+func (s *GossipService) SyncPeerKey(ctx context.Context, peerID string) error {
+	dbPeer, err := s.queries.GetPeer(ctx, peerID)
+	if err != nil {
+		return fmt.Errorf("peer not found: %w", err)
+	}
+
+	info, err := s.FetchPeerInfo(ctx, dbPeer.Address)
+	if err != nil {
+		return fmt.Errorf("failed to get info from peer: %w", err)
+	}
+
+	return s.UpdatePeerKey(ctx, peerID, info.PublicKey)
+}
+
+// UpdatePeerKey stores the announcement key of a peer, base64 encoded
+func (s *GossipService) UpdatePeerKey(ctx context.Context, peerID, publicKey string) error {
+	if publicKey == "" {
+		return nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(publicKey); err != nil || len(key) != ed25519.PublicKeySize {
+		return errors.New("invalid peer key")
+	}
+
+	if err := s.queries.UpdatePeerKey(ctx, db.UpdatePeerKeyParams{
+		PublicKey: publicKey,
+		ID:        peerID,
+	}); err != nil {
+		return fmt.Errorf("failed to update peer key: %w", err)
+	}
+	return nil
+}
+
 // SyncWithPeer synchronizes with a remote peer
 func (s *GossipService) SyncWithPeer(ctx context.Context, peerID string, remoteChanges []models.Item) (*models.SyncResult, error) {
 	startTime := time.Now()
@@ -220,59 +488,113 @@ func (s *GossipService) SyncWithPeer(ctx context.Context, peerID string, remoteC
 		return nil, fmt.Errorf("failed to get local changes: %w", err)
 	}
 
-	// Apply remote changes with conflict resolution
+	// Apply remote changes with conflict resolution. The whole batch and the
+	// last sync timestamp are committed together, or not at all.
 	conflicts := 0
-	for _, remoteItem := range remoteChanges {
-		// Check if item exists locally
-		localItem, err := s.queries.GetItemByID(ctx, remoteItem.ID)
+	err = s.database.WithTx(ctx, func(q *db.Queries) error {
+		for _, remoteItem := range remoteChanges {
+			// Map the remote category onto the local hierarchy
+			categoryID, category, err := resolveCategory(ctx, q, remoteItem.Category)
+			if err != nil {
+				return err
+			}
 
-		if err != nil {
-			// Item doesn't exist, create it
-			_, err = s.queries.CreateItem(ctx, db.CreateItemParams{
-				Name:         remoteItem.Name,
-				Category:     remoteItem.Category,
-				Brand:        remoteItem.Brand,
-				Model:        remoteItem.Model,
-				SerialNumber: remoteItem.SerialNumber,
-				PurchaseDate: sql.NullTime{Time: *remoteItem.PurchaseDate, Valid: remoteItem.PurchaseDate != nil},
-				PhotoPath:    remoteItem.PhotoPath,
-				Notes:        remoteItem.Notes,
-				CreatedAt:    remoteItem.CreatedAt,
-				UpdatedAt:    remoteItem.UpdatedAt,
-			})
+			productModelID, err := resolveProductModel(ctx, q, remoteItem.Brand, remoteItem.Model)
 			if err != nil {
-				return nil, fmt.Errorf("failed to create item: %w", err)
+				return err
 			}
-		} else {
-			// Item exists, check for conflict
-			if localItem.UpdatedAt.After(remoteItem.UpdatedAt) {
-				// Local version is newer, skip (Last-Write-Wins)
-				conflicts++
-				continue
+
+			serialNumber, notes, err := s.keyring.sealItemSecrets(ctx, q, remoteItem.SerialNumber, remoteItem.Notes)
+			if err != nil {
+				return err
 			}
 
-			// Remote version is newer or same, update
-			err = s.queries.UpdateItem(ctx, db.UpdateItemParams{
-				Name:         remoteItem.Name,
-				Category:     remoteItem.Category,
-				Brand:        remoteItem.Brand,
-				Model:        remoteItem.Model,
-				SerialNumber: remoteItem.SerialNumber,
-				PurchaseDate: sql.NullTime{Time: *remoteItem.PurchaseDate, Valid: remoteItem.PurchaseDate != nil},
-				PhotoPath:    remoteItem.PhotoPath,
-				Notes:        remoteItem.Notes,
-				UpdatedAt:    remoteItem.UpdatedAt,
-				ID:           remoteItem.ID,
-			})
+			// Check if item exists locally
+			localItem, err := q.GetItemByID(ctx, remoteItem.ID)
+
+			itemID := remoteItem.ID
 			if err != nil {
-				return nil, fmt.Errorf("failed to update item: %w", err)
+				// Item doesn't exist, create it
+				created, err := q.CreateItem(ctx, db.CreateItemParams{
+					Name:           remoteItem.Name,
+					Category:       category,
+					CategoryID:     categoryID,
+					Brand:          remoteItem.Brand,
+					Model:          remoteItem.Model,
+					ProductModelID: productModelID,
+					SerialNumber:   serialNumber,
+					PurchaseDate:   nullTime(remoteItem.PurchaseDate),
+					PhotoPath:      remoteItem.PhotoPath,
+					Notes:          notes,
+					CreatedAt:      remoteItem.CreatedAt,
+					UpdatedAt:      remoteItem.UpdatedAt,
+				})
+				if err != nil {
+					return fmt.Errorf("failed to create item: %w", err)
+				}
+				itemID = created.ID
+			} else {
+				// Item exists, check for conflict
+				if localItem.UpdatedAt.After(remoteItem.UpdatedAt) {
+					// Local version is newer, skip (Last-Write-Wins). The
+					// repair history is merged all the same.
+					conflicts++
+					if err := mergeRepairEvents(ctx, q, itemID, remoteItem.Repairs); err != nil {
+						return err
+					}
+					continue
+				}
+
+				// Remote version is newer or same, update
+				err = q.UpdateItem(ctx, db.UpdateItemParams{
+					Name:           remoteItem.Name,
+					Category:       category,
+					CategoryID:     categoryID,
+					Brand:          remoteItem.Brand,
+					Model:          remoteItem.Model,
+					ProductModelID: productModelID,
+					SerialNumber:   serialNumber,
+					PurchaseDate:   nullTime(remoteItem.PurchaseDate),
+					PhotoPath:      remoteItem.PhotoPath,
+					Notes:          notes,
+					UpdatedAt:      remoteItem.UpdatedAt,
+					ID:             remoteItem.ID,
+				})
+				if err != nil {
+					return fmt.Errorf("failed to update item: %w", err)
+				}
+			}
+
+			// Peers that predate attributes send none: keep the local ones
+			if remoteItem.Attributes != nil {
+				if err := replaceItemAttributes(ctx, q, itemID, remoteItem.Attributes); err != nil {
+					return err
+				}
+			}
+
+			if remoteItem.Tags != nil {
+				if err := replaceItemTags(ctx, q, itemID, remoteItem.Tags); err != nil {
+					return err
+				}
+			}
+
+			if err := mergeRepairEvents(ctx, q, itemID, remoteItem.Repairs); err != nil {
+				return err
 			}
 		}
-	}
 
-	// Update peer's last sync timestamp
-	if err := s.UpdatePeerLastSync(ctx, peerID); err != nil {
-		return nil, fmt.Errorf("failed to update peer last sync: %w", err)
+		// Update peer's last sync timestamp
+		if err := q.UpdatePeerLastSync(ctx, db.UpdatePeerLastSyncParams{
+			LastSync: sql.NullTime{Time: time.Now(), Valid: true},
+			ID:       peerID,
+		}); err != nil {
+			return fmt.Errorf("failed to update peer last sync: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Calculate result
@@ -296,12 +618,496 @@ func (s *GossipService) SyncWithPeer(ctx context.Context, peerID string, remoteC
 
 	if err := s.LogSync(ctx, syncLog); err != nil {
 		// Log error but don't fail the sync
-		fmt.Printf("failed to log sync: %v\n", err)
+		s.logger.Warn("Failed to log sync", "peer_id", peerID, "error", err)
 	}
 
 	return result, nil
 }
 
+// SearchPeers fans a documentation search out to every online trusted peer
+// and merges the results, tagging each item with the peer that holds it
+func (s *GossipService) SearchPeers(ctx context.Context, query models.SearchQuery) ([]models.SearchResult, error) {
+	if query.Brand == "" && query.Model == "" {
+		return nil, fmt.Errorf("brand or model is required")
+	}
+
+	peers, err := s.GetTrustedPeers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = []models.SearchResult{}
+	)
+
+	for _, peer := range peers {
+		if peer.Status != models.PeerStatusOnline {
+			continue
+		}
+
+		wg.Add(1)
+		go func(peer models.Peer) {
+			defer wg.Done()
+
+			items, err := s.searchPeer(ctx, peer, query)
+			if err != nil {
+				// An unreachable peer must not fail the whole search
+				s.logger.Warn("Failed to search peer", "peer_id", peer.ID, "error", err)
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			for _, item := range items {
+				results = append(results, models.SearchResult{
+					PeerID:   peer.ID,
+					PeerName: peer.Name,
+					Item:     item,
+				})
+			}
+		}(peer)
+	}
+
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].PeerName != results[j].PeerName {
+			return results[i].PeerName < results[j].PeerName
+		}
+		return results[i].Item.Item.Name < results[j].Item.Item.Name
+	})
+
+	return results, nil
+}
+
+// FetchPeerItem retrieves a single item and its asset metadata from a trusted peer
+func (s *GossipService) FetchPeerItem(ctx context.Context, peerID string, itemID int64) (*models.ItemWithAssets, error) {
+	peer, err := s.getTrustedPeer(ctx, peerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var item models.ItemWithAssets
+	if err := s.getPeerJSON(ctx, peer.Address, fmt.Sprintf("/api/v1/gossip/items/%d", itemID), &item); err != nil {
+		return nil, fmt.Errorf("failed to fetch item from peer: %w", err)
+	}
+
+	return &item, nil
+}
+
+// ImportPeerItem copies an item found on a trusted peer, together with its
+// asset files, into the local inventory. The documentation the peer shares
+// for the product model goes to the local product model, without the files
+// it already has.
+func (s *GossipService) ImportPeerItem(ctx context.Context, backpack *BackpackService, peerID string, itemID int64) (*models.ItemWithAssets, error) {
+	remote, err := s.FetchPeerItem(ctx, peerID, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	peer, err := s.getTrustedPeer(ctx, peerID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Make sure every asset type of the peer is known locally
+	if _, err := s.pullAssetTypes(ctx, peer.Address, time.Time{}); err != nil {
+		return nil, err
+	}
+
+	// Download every asset before touching the inventory
+	tempDir, err := os.MkdirTemp("", "brique-fetch-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	var itemAssets, sharedAssets []models.Asset
+	for _, asset := range remote.Assets {
+		if asset.ProductModelID != nil {
+			sharedAssets = append(sharedAssets, asset)
+		} else {
+			itemAssets = append(itemAssets, asset)
+		}
+	}
+
+	known, err := productModelHashes(ctx, backpack.queries, remote.Item.Brand, remote.Item.Model)
+	if err != nil {
+		return nil, err
+	}
+
+	itemFiles, err := s.downloadPeerAssets(ctx, peer.Address, itemAssets, nil, tempDir)
+	if err != nil {
+		return nil, err
+	}
+
+	sharedFiles, err := s.downloadPeerAssets(ctx, peer.Address, sharedAssets, known, tempDir)
+	if err != nil {
+		return nil, err
+	}
+
+	item := remote.Item
+	item.ID = 0
+	item.ProductModelID = nil
+	item.PhotoPath = "" // Remote paths are meaningless locally
+
+	// The item and all its assets are imported in a single transaction
+	err = backpack.inTx(ctx, func(tx *BackpackService) error {
+		if err := tx.CreateItem(ctx, &item); err != nil {
+			return err
+		}
+		if err := importPeerAssets(ctx, tx, assetOwner{itemID: item.ID}, itemAssets, itemFiles, nil); err != nil {
+			return err
+		}
+		if item.ProductModelID == nil || len(sharedAssets) == 0 {
+			return nil
+		}
+
+		// Hashes are looked up again as the model may have been created above
+		known, err := productModelHashes(ctx, tx.queries, item.Brand, item.Model)
+		if err != nil {
+			return err
+		}
+		return importPeerAssets(ctx, tx, assetOwner{productModelID: *item.ProductModelID}, sharedAssets, sharedFiles, known)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return backpack.GetItemWithAssets(ctx, item.ID)
+}
+
+// SyncProductModelsWithPeer pulls the product model catalogue of a peer.
+// Unknown models and aliases are added, and the documentation shared for the
+// models held in the local inventory is downloaded, so that owners of the same
+// model exchange it even when they share no item. It returns how many
+// documents were imported.
+func (s *GossipService) SyncProductModelsWithPeer(ctx context.Context, backpack *BackpackService, peerID string) (int, error) {
+	dbPeer, err := s.queries.GetPeer(ctx, peerID)
+	if err != nil {
+		return 0, fmt.Errorf("peer not found: %w", err)
+	}
+
+	var catalogue []models.ProductModelWithAssets
+	if err := s.getPeerJSON(ctx, dbPeer.Address, "/api/v1/gossip/product-models", &catalogue); err != nil {
+		return 0, fmt.Errorf("failed to get product models from peer: %w", err)
+	}
+
+	// Make sure every asset type of the peer is known locally
+	if _, err := s.pullAssetTypes(ctx, dbPeer.Address, time.Time{}); err != nil {
+		return 0, err
+	}
+
+	tempDir, err := os.MkdirTemp("", "brique-models-*")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	imported := 0
+	for _, remote := range catalogue {
+		n, err := s.importPeerProductModel(ctx, backpack, dbPeer.Address, remote, tempDir)
+		if err != nil {
+			return imported, fmt.Errorf("failed to import product model %s %s: %w", remote.ProductModel.Brand, remote.ProductModel.Model, err)
+		}
+		imported += n
+	}
+
+	return imported, nil
+}
+
+// importPeerProductModel merges a product model of a peer into the local
+// catalogue and imports its documentation when local items use it. Aliases
+// that already resolve to a local model are skipped: merging models is left
+// to the user.
+func (s *GossipService) importPeerProductModel(ctx context.Context, backpack *BackpackService, address string, remote models.ProductModelWithAssets, dir string) (int, error) {
+	var productModelID sql.NullInt64
+	var itemCount int64
+	err := backpack.inTx(ctx, func(tx *BackpackService) error {
+		var err error
+		productModelID, err = resolveProductModel(ctx, tx.queries, remote.ProductModel.Brand, remote.ProductModel.Model)
+		if err != nil || !productModelID.Valid {
+			return err
+		}
+
+		for _, alias := range remote.ProductModel.Aliases {
+			key := productModelKey(alias.Brand, alias.Model)
+			if key == "" {
+				continue
+			}
+
+			if _, ok, err := lookupProductModel(ctx, tx.queries, key); err != nil {
+				return err
+			} else if ok {
+				continue
+			}
+
+			if err := tx.queries.CreateProductModelAlias(ctx, db.CreateProductModelAliasParams{
+				ModelKey:       key,
+				ProductModelID: productModelID.Int64,
+				Brand:          alias.Brand,
+				Model:          alias.Model,
+			}); err != nil {
+				return fmt.Errorf("failed to add alias: %w", err)
+			}
+		}
+
+		itemCount, err = tx.queries.CountItemsByProductModelID(ctx, productModelID)
+		if err != nil {
+			return fmt.Errorf("failed to count items: %w", err)
+		}
+		return nil
+	})
+	if err != nil || !productModelID.Valid || itemCount == 0 {
+		return 0, err
+	}
+
+	known, err := productModelHashes(ctx, backpack.queries, remote.ProductModel.Brand, remote.ProductModel.Model)
+	if err != nil {
+		return 0, err
+	}
+
+	missing := 0
+	for _, asset := range remote.Assets {
+		if _, ok := known[asset.FileHash]; !ok || asset.FileHash == "" {
+			missing++
+		}
+	}
+	if missing == 0 {
+		return 0, nil
+	}
+
+	files, err := s.downloadPeerAssets(ctx, address, remote.Assets, known, dir)
+	if err != nil {
+		return 0, err
+	}
+
+	err = backpack.inTx(ctx, func(tx *BackpackService) error {
+		if err := importPeerAssets(ctx, tx, assetOwner{productModelID: productModelID.Int64}, remote.Assets, files, known); err != nil {
+			return err
+		}
+		return touchProductModel(ctx, tx.queries, productModelID.Int64)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return missing, nil
+}
+
+// GetSharedParts returns the parts known to fit at least one product model,
+// with the brand and model of those product models
+func (s *GossipService) GetSharedParts(ctx context.Context) ([]models.SharedPart, error) {
+	parts, err := s.queries.GetAllParts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parts: %w", err)
+	}
+
+	links, err := s.queries.GetAllPartProductModels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get part product models: %w", err)
+	}
+
+	productModels, err := s.queries.GetAllProductModels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product models: %w", err)
+	}
+	byID := make(map[int64]db.ProductModel, len(productModels))
+	for _, m := range productModels {
+		byID[m.ID] = m
+	}
+
+	fits := make(map[int64][]models.ProductModelAlias)
+	for _, link := range links {
+		m := byID[link.ProductModelID]
+		fits[link.PartID] = append(fits[link.PartID], models.ProductModelAlias{Brand: m.Brand, Model: m.Model})
+	}
+
+	shared := []models.SharedPart{}
+	for _, part := range parts {
+		if len(fits[part.ID]) == 0 {
+			continue
+		}
+		shared = append(shared, models.SharedPart{
+			Name:          part.Name,
+			Reference:     part.Reference,
+			ProductModels: fits[part.ID],
+		})
+	}
+
+	return shared, nil
+}
+
+// ApplySharedParts merges the part compatibilities learnt from a peer. Unknown
+// parts are added with nothing in stock, and compatibilities are only ever
+// added. It returns how many compatibilities were new.
+func (s *GossipService) ApplySharedParts(ctx context.Context, parts []models.SharedPart) (int, error) {
+	learnt := 0
+	err := s.database.WithTx(ctx, func(q *db.Queries) error {
+		links, err := q.GetAllPartProductModels(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get part product models: %w", err)
+		}
+		known := make(map[db.PartProductModel]bool, len(links))
+		for _, link := range links {
+			known[link] = true
+		}
+
+		now := time.Now()
+		for _, remote := range parts {
+			key := partKey(remote.Name, remote.Reference)
+			if key == "" {
+				continue
+			}
+
+			part, err := q.GetPartByKey(ctx, key)
+			if errors.Is(err, sql.ErrNoRows) {
+				part, err = q.CreatePart(ctx, db.CreatePartParams{
+					Name:      strings.TrimSpace(remote.Name),
+					Reference: strings.TrimSpace(remote.Reference),
+					PartKey:   key,
+					CreatedAt: now,
+					UpdatedAt: now,
+				})
+				if err != nil {
+					return fmt.Errorf("failed to create part: %w", err)
+				}
+			} else if err != nil {
+				return fmt.Errorf("failed to get part: %w", err)
+			}
+
+			added := false
+			for _, fit := range remote.ProductModels {
+				productModelID, err := resolveProductModel(ctx, q, fit.Brand, fit.Model)
+				if err != nil {
+					return err
+				}
+				link := db.PartProductModel{PartID: part.ID, ProductModelID: productModelID.Int64}
+				if !productModelID.Valid || known[link] {
+					continue
+				}
+
+				if err := q.AddPartProductModel(ctx, db.AddPartProductModelParams(link)); err != nil {
+					return fmt.Errorf("failed to link product model: %w", err)
+				}
+				known[link] = true
+				added = true
+				learnt++
+			}
+
+			if added {
+				if err := q.TouchPart(ctx, db.TouchPartParams{UpdatedAt: now, ID: part.ID}); err != nil {
+					return fmt.Errorf("failed to update part: %w", err)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return learnt, nil
+}
+
+// SyncPartsWithPeer pulls the part compatibilities known to a peer and returns
+// how many were new
+func (s *GossipService) SyncPartsWithPeer(ctx context.Context, peerID string) (int, error) {
+	dbPeer, err := s.queries.GetPeer(ctx, peerID)
+	if err != nil {
+		return 0, fmt.Errorf("peer not found: %w", err)
+	}
+
+	var parts []models.SharedPart
+	if err := s.getPeerJSON(ctx, dbPeer.Address, "/api/v1/gossip/parts", &parts); err != nil {
+		return 0, fmt.Errorf("failed to get parts from peer: %w", err)
+	}
+
+	return s.ApplySharedParts(ctx, parts)
+}
+
+// importPeerAssets adds the downloaded files to a local item or product model,
+// oldest first, so that the revision chains of the peer are rebuilt with local
+// IDs. Assets whose hash is in known are already stored locally and are only
+// used to link the chains. Must be called within a transaction.
+func importPeerAssets(ctx context.Context, backpack *BackpackService, owner assetOwner, assets []models.Asset, files []string, known map[string]int64) error {
+	localIDs := make(map[int64]int64, len(assets))
+	current := []int64{}
+
+	for i := len(assets) - 1; i >= 0; i-- {
+		asset := assets[i]
+
+		if id, ok := known[asset.FileHash]; ok && asset.FileHash != "" {
+			localIDs[asset.ID] = id
+			continue
+		}
+
+		previousID, ok := localIDOf(localIDs, asset.SupersedesID)
+		if ok {
+			// A local chain may already have moved past the known revision
+			previous, err := backpack.queries.GetAssetByID(ctx, previousID)
+			if err != nil {
+				return fmt.Errorf("failed to get asset: %w", err)
+			}
+			ok = !previous.SupersededByID.Valid
+		}
+
+		var local *models.Asset
+		var err error
+		if ok {
+			local, err = backpack.AddAssetRevision(ctx, previousID, asset.Name, files[i], asset.VersionLabel, asset.ReleaseDate)
+		} else {
+			local, err = backpack.createAsset(ctx, owner, asset.Type, asset.Name, files[i], assetRevision{})
+			if err == nil && (asset.VersionLabel != "" || asset.ReleaseDate != nil) {
+				err = backpack.SetAssetVersion(ctx, local.ID, asset.VersionLabel, asset.ReleaseDate)
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		localIDs[asset.ID] = local.ID
+		if asset.IsCurrent {
+			current = append(current, local.ID)
+		}
+	}
+
+	// Restore the revisions the peer had marked as current
+	for _, id := range current {
+		if err := backpack.SetCurrentRevision(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// localIDOf maps a remote asset reference to the ID of its local copy
+func localIDOf(localIDs map[int64]int64, remoteID *int64) (int64, bool) {
+	if remoteID == nil {
+		return 0, false
+	}
+	id, ok := localIDs[*remoteID]
+	return id, ok
+}
+
+// getTrustedPeer loads a peer and ensures it is trusted
+func (s *GossipService) getTrustedPeer(ctx context.Context, peerID string) (models.Peer, error) {
+	dbPeer, err := s.queries.GetPeer(ctx, peerID)
+	if err != nil {
+		return models.Peer{}, fmt.Errorf("peer not found: %w", err)
+	}
+
+	peer := s.dbPeerToModel(dbPeer)
+	if !peer.IsTrusted {
+		return models.Peer{}, fmt.Errorf("peer %s is not trusted", peerID)
+	}
+
+	return peer, nil
+}
+
 // Helper functions to convert DB models to domain models
 
 func (s *GossipService) dbPeerToModel(dbPeer db.Peer) models.Peer {
@@ -385,3 +1191,11 @@ func (s *GossipService) dbItemToModel(dbItem db.Item) models.Item {
 
 	return item
 }
+
+// nullTime converts an optional time to its SQL representation
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
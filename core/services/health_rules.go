@@ -0,0 +1,126 @@
+package services
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/lhommenul/brique/core/models"
+)
+
+// DefaultHealthCategory is the rule category applied to items whose category
+// has no dedicated rule
+const DefaultHealthCategory = "*"
+
+// DefaultHealthRules returns the built-in documentation rules
+func DefaultHealthRules() []models.HealthRule {
+	return []models.HealthRule{
+		{
+			Category: DefaultHealthCategory,
+			Required: []models.AssetType{models.AssetTypeManual, models.AssetTypeServiceManual},
+			Optional: []models.AssetType{models.AssetTypeExplodedView, models.AssetTypeSchematic},
+		},
+		{
+			Category: "Imprimante 3D",
+			Required: []models.AssetType{models.AssetTypeSTL, models.AssetTypeFirmware},
+			Optional: []models.AssetType{models.AssetTypeManual, models.AssetTypeSchematic},
+		},
+		{
+			Category: "Informatique",
+			Required: []models.AssetType{models.AssetTypeDriver},
+			Optional: []models.AssetType{models.AssetTypeManual, models.AssetTypeFirmware},
+		},
+	}
+}
+
+// HealthRules evaluates documentation health according to per-category rules
+type HealthRules struct {
+	rules map[string]models.HealthRule
+}
+
+// NewHealthRules creates a rule set from the built-in rules, overridden by the
+// given rules for matching categories
+func NewHealthRules(overrides []models.HealthRule) *HealthRules {
+	h := &HealthRules{rules: make(map[string]models.HealthRule)}
+
+	for _, rule := range DefaultHealthRules() {
+		h.rules[normalizeCategory(rule.Category)] = rule
+	}
+
+	for _, rule := range overrides {
+		h.rules[normalizeCategory(rule.Category)] = rule
+	}
+
+	return h
+}
+
+// Rules returns every configured rule, the default one first, then by
+// category
+func (h *HealthRules) Rules() []models.HealthRule {
+	categories := make([]string, 0, len(h.rules))
+	for category := range h.rules {
+		categories = append(categories, category)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[i] == DefaultHealthCategory || categories[j] == DefaultHealthCategory {
+			return categories[i] == DefaultHealthCategory && categories[j] != DefaultHealthCategory
+		}
+		return categories[i] < categories[j]
+	})
+
+	rules := make([]models.HealthRule, len(categories))
+	for i, category := range categories {
+		rules[i] = h.rules[category]
+	}
+	return rules
+}
+
+// RuleFor returns the rule that applies to a category
+func (h *HealthRules) RuleFor(category string) models.HealthRule {
+	if rule, ok := h.rules[normalizeCategory(category)]; ok {
+		return rule
+	}
+	return h.rules[DefaultHealthCategory]
+}
+
+// Evaluate computes the health of an item and lists its missing documents
+func (h *HealthRules) Evaluate(item models.Item, assets []models.Asset) models.HealthReport {
+	rule := h.RuleFor(item.Category)
+
+	present := make(map[models.AssetType]bool)
+	for _, asset := range assets {
+		present[asset.Type] = true
+	}
+
+	report := models.HealthReport{
+		Item:            item,
+		MissingRequired: missingAssetTypes(rule.Required, present),
+		MissingOptional: missingAssetTypes(rule.Optional, present),
+	}
+
+	switch {
+	case len(assets) == 0:
+		report.Health = models.HealthIncomplete
+	case len(report.MissingRequired) == 0:
+		report.Health = models.HealthSecured
+	default:
+		report.Health = models.HealthPartial
+	}
+
+	return report
+}
+
+// missingAssetTypes returns the wanted types that are not present
+func missingAssetTypes(wanted []models.AssetType, present map[models.AssetType]bool) []models.AssetType {
+	missing := []models.AssetType{}
+	for _, assetType := range wanted {
+		if !present[assetType] {
+			missing = append(missing, assetType)
+		}
+	}
+	return missing
+}
+
+// normalizeCategory makes category lookups case and whitespace insensitive
+func normalizeCategory(category string) string {
+	return strings.ToLower(strings.TrimSpace(category))
+}
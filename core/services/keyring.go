@@ -0,0 +1,270 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lhommenul/brique/core/db"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Argon2id parameters of the keys derived from the passphrase, sized for a
+// Raspberry Pi to unlock in about a second
+const (
+	kdfTime     = 3
+	kdfMemory   = 64 * 1024 // KiB
+	kdfThreads  = 4
+	kdfSaltSize = 16
+)
+
+// sealedFieldPrefix marks an encrypted field, stored as
+// enc1:<key id>:<base64 of the nonce and ciphertext>
+const sealedFieldPrefix = "enc1:"
+
+// Associated data binding the sealed values to their use
+const (
+	dataKeyAD      = "brique data key"
+	serialNumberAD = "items.serial_number"
+	notesAD        = "items.notes"
+	instanceKeyAD  = "instance_keys.private_key"
+)
+
+var (
+	// ErrEncryptionLocked is returned when encrypted data is read or written
+	// before the keys are unlocked with the passphrase
+	ErrEncryptionLocked = errors.New("encryption keys are locked: the passphrase is required")
+
+	// ErrWrongPassphrase is returned when the passphrase does not unwrap the keys
+	ErrWrongPassphrase = errors.New("wrong passphrase")
+
+	// ErrEncryptionDisabled is returned by operations that need encryption enabled
+	ErrEncryptionDisabled = errors.New("encryption is not enabled")
+
+	// ErrEncryptionEnabled is returned when enabling encryption twice
+	ErrEncryptionEnabled = errors.New("encryption is already enabled")
+)
+
+// Keyring holds the data keys once unwrapped with the passphrase. The keys
+// themselves are stored wrapped in the database, so that backups carry them.
+// New data is encrypted with the latest key, as found in the database at the
+// time, for a node whose keys were rotated by another process to stop writing
+// with a retired key.
+type Keyring struct {
+	mu   sync.RWMutex
+	keys map[int64][]byte // Unwrapped data keys by ID
+}
+
+// NewKeyring creates a locked keyring
+func NewKeyring() *Keyring {
+	return &Keyring{keys: make(map[int64][]byte)}
+}
+
+// Unlock unwraps the data keys stored in the database with the passphrase
+func (k *Keyring) Unlock(ctx context.Context, q *db.Queries, passphrase string) error {
+	rows, err := q.GetEncryptionKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get encryption keys: %w", err)
+	}
+	if len(rows) == 0 {
+		return ErrEncryptionDisabled
+	}
+
+	keys := make(map[int64][]byte, len(rows))
+	for _, row := range rows {
+		key, err := unwrapDataKey(row, passphrase)
+		if err != nil {
+			return err
+		}
+		keys[row.ID] = key
+	}
+
+	k.mu.Lock()
+	k.keys = keys
+	k.mu.Unlock()
+	return nil
+}
+
+// Lock forgets the unwrapped keys
+func (k *Keyring) Lock() {
+	k.mu.Lock()
+	k.keys = make(map[int64][]byte)
+	k.mu.Unlock()
+}
+
+// key returns the data key of the given ID, if unlocked
+func (k *Keyring) key(id int64) ([]byte, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	key, ok := k.keys[id]
+	return key, ok
+}
+
+// add makes a newly created data key available
+func (k *Keyring) add(id int64, key []byte) {
+	k.mu.Lock()
+	k.keys[id] = key
+	k.mu.Unlock()
+}
+
+// currentKey returns the key new data is encrypted with, or a zero ID when
+// encryption is disabled
+func (k *Keyring) currentKey(ctx context.Context, q *db.Queries) (int64, []byte, error) {
+	id, err := q.GetCurrentEncryptionKeyID(ctx)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to get encryption key: %w", err)
+	}
+	if id == 0 {
+		return 0, nil, nil
+	}
+
+	key, ok := k.key(id)
+	if !ok {
+		return 0, nil, ErrEncryptionLocked
+	}
+	return id, key, nil
+}
+
+// sealField encrypts a field value with the current key. Empty values and
+// values written while encryption is disabled are stored as they are.
+func (k *Keyring) sealField(ctx context.Context, q *db.Queries, value, ad string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	id, key, err := k.currentKey(ctx, q)
+	if err != nil || id == 0 {
+		return value, err
+	}
+	return sealFieldWith(id, key, value, ad)
+}
+
+// openField decrypts a field value sealed by sealField. Plaintext values,
+// written before encryption was enabled, are returned as they are.
+func (k *Keyring) openField(value, ad string) (string, error) {
+	if !strings.HasPrefix(value, sealedFieldPrefix) {
+		return value, nil
+	}
+
+	idText, encoded, ok := strings.Cut(strings.TrimPrefix(value, sealedFieldPrefix), ":")
+	id, err := strconv.ParseInt(idText, 10, 64)
+	if !ok || err != nil {
+		return "", fmt.Errorf("malformed encrypted field")
+	}
+
+	key, ok := k.key(id)
+	if !ok {
+		return "", ErrEncryptionLocked
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < chacha20poly1305.NonceSizeX {
+		return "", fmt.Errorf("malformed encrypted field")
+	}
+
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := aead.Open(nil, sealed[:chacha20poly1305.NonceSizeX], sealed[chacha20poly1305.NonceSizeX:], []byte(ad))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt field: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// sealFieldWith encrypts a field value with the given key
+func sealFieldWith(id int64, key []byte, value, ad string) (string, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, chacha20poly1305.NonceSizeX, chacha20poly1305.NonceSizeX+len(value)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(value), []byte(ad))
+
+	return fmt.Sprintf("%s%d:%s", sealedFieldPrefix, id, base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+// newDataKey generates a data key and wraps it with the passphrase
+func newDataKey(passphrase string) ([]byte, db.CreateEncryptionKeyParams, error) {
+	key := make([]byte, chacha20poly1305.KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, db.CreateEncryptionKeyParams{}, fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	wrap, err := wrapDataKey(key, passphrase)
+	if err != nil {
+		return nil, db.CreateEncryptionKeyParams{}, err
+	}
+
+	return key, db.CreateEncryptionKeyParams{
+		Salt:       wrap.Salt,
+		KdfTime:    wrap.KdfTime,
+		KdfMemory:  wrap.KdfMemory,
+		KdfThreads: wrap.KdfThreads,
+		WrappedKey: wrap.WrappedKey,
+		CreatedAt:  time.Now(),
+	}, nil
+}
+
+// wrapDataKey encrypts a data key with a key derived from the passphrase
+func wrapDataKey(key []byte, passphrase string) (db.UpdateEncryptionKeyWrapParams, error) {
+	if passphrase == "" {
+		return db.UpdateEncryptionKeyWrapParams{}, fmt.Errorf("passphrase is required")
+	}
+
+	salt := make([]byte, kdfSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return db.UpdateEncryptionKeyWrapParams{}, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	aead, err := chacha20poly1305.NewX(argon2.IDKey([]byte(passphrase), salt, kdfTime, kdfMemory, kdfThreads, chacha20poly1305.KeySize))
+	if err != nil {
+		return db.UpdateEncryptionKeyWrapParams{}, err
+	}
+
+	nonce := make([]byte, chacha20poly1305.NonceSizeX, chacha20poly1305.NonceSizeX+len(key)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return db.UpdateEncryptionKeyWrapParams{}, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return db.UpdateEncryptionKeyWrapParams{
+		Salt:       salt,
+		KdfTime:    kdfTime,
+		KdfMemory:  kdfMemory,
+		KdfThreads: kdfThreads,
+		WrappedKey: aead.Seal(nonce, nonce, key, []byte(dataKeyAD)),
+	}, nil
+}
+
+// unwrapDataKey decrypts a stored data key with the passphrase
+func unwrapDataKey(row db.EncryptionKey, passphrase string) ([]byte, error) {
+	if row.KdfTime <= 0 || row.KdfMemory <= 0 || row.KdfThreads <= 0 || row.KdfThreads > 255 {
+		return nil, fmt.Errorf("invalid parameters for encryption key %d", row.ID)
+	}
+
+	aead, err := chacha20poly1305.NewX(argon2.IDKey([]byte(passphrase), row.Salt, uint32(row.KdfTime), uint32(row.KdfMemory), uint8(row.KdfThreads), chacha20poly1305.KeySize))
+	if err != nil {
+		return nil, err
+	}
+	if len(row.WrappedKey) < chacha20poly1305.NonceSizeX {
+		return nil, fmt.Errorf("malformed encryption key %d", row.ID)
+	}
+
+	key, err := aead.Open(nil, row.WrappedKey[:chacha20poly1305.NonceSizeX], row.WrappedKey[chacha20poly1305.NonceSizeX:], []byte(dataKeyAD))
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return key, nil
+}
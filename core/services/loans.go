@@ -0,0 +1,471 @@
+package services
+
+import (
+	"context"
+	"crypto/ed25519"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lhommenul/brique/core/db"
+	"github.com/lhommenul/brique/core/models"
+)
+
+// LendItem records that an item is lent. A borrower with an instance is given
+// by its peer ID and receives the loan on its next sync with this instance,
+// once this instance synced with it to learn its key.
+func (s *BackpackService) LendItem(ctx context.Context, loan *models.Loan) error {
+	loan.BorrowerName = strings.TrimSpace(loan.BorrowerName)
+	loan.Notes = strings.TrimSpace(loan.Notes)
+
+	if loan.ItemID == nil {
+		return errors.New("the lent item is required")
+	}
+
+	now := time.Now()
+	if loan.LentAt.IsZero() {
+		loan.LentAt = now
+	}
+	if loan.DueAt != nil && !loan.DueAt.After(loan.LentAt) {
+		return errors.New("the expected return must be after the loan")
+	}
+
+	var created db.Loan
+	err := s.inTx(ctx, func(tx *BackpackService) error {
+		item, err := tx.queries.GetItemByID(ctx, *loan.ItemID)
+		if err != nil {
+			return fmt.Errorf("item not found: %w", err)
+		}
+
+		itemID := sql.NullInt64{Int64: item.ID, Valid: true}
+		if current, err := tx.queries.GetActiveLoanByItemID(ctx, itemID); err == nil {
+			return fmt.Errorf("%s is already lent to %s", item.Name, current.BorrowerName)
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to get loan: %w", err)
+		}
+
+		if loan.BorrowerPeerID != "" {
+			peer, err := tx.queries.GetPeer(ctx, loan.BorrowerPeerID)
+			if err != nil {
+				return fmt.Errorf("peer not found: %w", err)
+			}
+			if loan.BorrowerName == "" {
+				loan.BorrowerName = peer.Name
+			}
+		}
+		if loan.BorrowerName == "" {
+			return errors.New("the borrower is required")
+		}
+
+		created, err = tx.queries.CreateLoan(ctx, db.CreateLoanParams{
+			UID:            uuid.New().String(),
+			Direction:      string(models.LoanOut),
+			ItemID:         itemID,
+			ItemName:       item.Name,
+			BorrowerPeerID: loan.BorrowerPeerID,
+			BorrowerName:   loan.BorrowerName,
+			LentAt:         loan.LentAt,
+			DueAt:          nullTime(loan.DueAt),
+			Notes:          loan.Notes,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create loan: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	*loan = dbLoanToModel(created, now)
+	return nil
+}
+
+// ReturnLoan records that a lent item came back, at returnedAt or now if zero.
+// Only the lender records returns.
+func (s *BackpackService) ReturnLoan(ctx context.Context, id int64, returnedAt time.Time) (*models.Loan, error) {
+	now := time.Now()
+	if returnedAt.IsZero() {
+		returnedAt = now
+	}
+
+	var returned db.Loan
+	err := s.inTx(ctx, func(tx *BackpackService) error {
+		var err error
+		returned, err = tx.queries.GetLoanByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("loan not found: %w", err)
+		}
+		if returned.Direction != string(models.LoanOut) {
+			return errors.New("only the lender can record the return of a loan")
+		}
+		if returned.ReturnedAt.Valid {
+			return fmt.Errorf("%s was already returned", returned.ItemName)
+		}
+
+		returned.ReturnedAt = sql.NullTime{Time: returnedAt, Valid: true}
+		returned.UpdatedAt = now
+		return updateLoan(ctx, tx.queries, returned)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	loan := dbLoanToModel(returned, now)
+	return &loan, nil
+}
+
+// GetLoan retrieves a loan by ID
+func (s *BackpackService) GetLoan(ctx context.Context, id int64) (*models.Loan, error) {
+	dbLoan, err := s.queries.GetLoanByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("loan not found: %w", err)
+	}
+
+	loan := dbLoanToModel(dbLoan, time.Now())
+	return &loan, nil
+}
+
+// GetLoans returns the loans in both directions, most recent first. Returned
+// loans are left out unless includeReturned is set.
+func (s *BackpackService) GetLoans(ctx context.Context, includeReturned bool) ([]models.Loan, error) {
+	dbLoans, err := s.queries.GetAllLoans(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loans: %w", err)
+	}
+
+	now := time.Now()
+	loans := []models.Loan{}
+	for _, dbLoan := range dbLoans {
+		if dbLoan.ReturnedAt.Valid && !includeReturned {
+			continue
+		}
+		loans = append(loans, dbLoanToModel(dbLoan, now))
+	}
+
+	return loans, nil
+}
+
+// GetOverdueLoans returns the loans not returned past their expected return,
+// lent items to chase as well as borrowed ones to give back
+func (s *BackpackService) GetOverdueLoans(ctx context.Context) ([]models.Loan, error) {
+	loans, err := s.GetLoans(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	overdue := []models.Loan{}
+	for _, loan := range loans {
+		if loan.Overdue {
+			overdue = append(overdue, loan)
+		}
+	}
+
+	return overdue, nil
+}
+
+// GetItemLoans returns the loan history of an item, most recent first
+func (s *BackpackService) GetItemLoans(ctx context.Context, itemID int64) ([]models.Loan, error) {
+	if _, err := s.queries.GetItemByID(ctx, itemID); err != nil {
+		return nil, fmt.Errorf("item not found: %w", err)
+	}
+
+	dbLoans, err := s.queries.GetLoansByItemID(ctx, sql.NullInt64{Int64: itemID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loans: %w", err)
+	}
+
+	now := time.Now()
+	loans := make([]models.Loan, len(dbLoans))
+	for i, dbLoan := range dbLoans {
+		loans[i] = dbLoanToModel(dbLoan, now)
+	}
+
+	return loans, nil
+}
+
+// LoansRequest asks a lender for the loans made to the requesting instance.
+// It is signed with the announcement key of the borrower, that the lender
+// learned from the borrower's address, so that an instance cannot ask for the
+// loans of another by giving its name.
+type LoansRequest struct {
+	BorrowerKey string    // Announcement key of the borrower
+	LenderKey   string    // Announcement key of the lender asked
+	SignedAt    time.Time // Requests are only accepted for loansRequestTTL
+	Signature   string
+}
+
+// loansRequestTTL bounds how long a signed loans request is accepted
+const loansRequestTTL = 5 * time.Minute
+
+// ErrUnknownBorrower is returned for a loans request not signed by a peer
+var ErrUnknownBorrower = errors.New("loans request not signed by a known peer")
+
+// ParseLoansRequest reads a loans request from the query of its URL
+func ParseLoansRequest(query url.Values) LoansRequest {
+	request := LoansRequest{
+		BorrowerKey: query.Get("borrower"),
+		LenderKey:   query.Get("lender"),
+		Signature:   query.Get("signature"),
+	}
+	if signedAt, err := strconv.ParseInt(query.Get("at"), 10, 64); err == nil {
+		request.SignedAt = time.Unix(signedAt, 0)
+	}
+	return request
+}
+
+// Query returns the URL query carrying the request
+func (r LoansRequest) Query() url.Values {
+	return url.Values{
+		"borrower":  {r.BorrowerKey},
+		"lender":    {r.LenderKey},
+		"at":        {strconv.FormatInt(r.SignedAt.Unix(), 10)},
+		"signature": {r.Signature},
+	}
+}
+
+// signedBytes returns the bytes signed by the borrower
+func (r LoansRequest) signedBytes() []byte {
+	return []byte(fmt.Sprintf("brique loans %s %s %d", r.BorrowerKey, r.LenderKey, r.SignedAt.Unix()))
+}
+
+// NewLoansRequest signs a request for the loans made to this instance by the
+// peer of the given announcement key
+func (s *GossipService) NewLoansRequest(ctx context.Context, lenderKey string) (*LoansRequest, error) {
+	privateKey, err := s.instanceKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	request := &LoansRequest{
+		BorrowerKey: base64.StdEncoding.EncodeToString(privateKey.Public().(ed25519.PublicKey)),
+		LenderKey:   lenderKey,
+		SignedAt:    time.Now(),
+	}
+	request.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, request.signedBytes()))
+	return request, nil
+}
+
+// GetSharedLoans returns the loans out to the peer that signed the request,
+// returned ones included for their borrower to learn about the return. The
+// request must be signed for this instance, recently, with the key the peer
+// gave on its last sync.
+func (s *GossipService) GetSharedLoans(ctx context.Context, request LoansRequest) ([]models.Loan, error) {
+	ownKey, err := s.instancePublicKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if ownKey == "" || request.LenderKey != ownKey {
+		return nil, ErrUnknownBorrower
+	}
+	if age := time.Since(request.SignedAt); age > loansRequestTTL || age < -loansRequestTTL {
+		return nil, ErrUnknownBorrower
+	}
+	publicKey, err := base64.StdEncoding.DecodeString(request.BorrowerKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return nil, ErrUnknownBorrower
+	}
+	signature, err := base64.StdEncoding.DecodeString(request.Signature)
+	if err != nil || !ed25519.Verify(ed25519.PublicKey(publicKey), request.signedBytes(), signature) {
+		return nil, ErrUnknownBorrower
+	}
+
+	dbPeers, err := s.queries.GetAllPeers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get peers: %w", err)
+	}
+	matching := make(map[string]bool)
+	for _, peer := range dbPeers {
+		if peer.PublicKey == request.BorrowerKey {
+			matching[peer.ID] = true
+		}
+	}
+	if len(matching) == 0 {
+		return nil, ErrUnknownBorrower
+	}
+
+	dbLoans, err := s.queries.GetAllLoans(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loans: %w", err)
+	}
+
+	now := time.Now()
+	loans := []models.Loan{}
+	for _, dbLoan := range dbLoans {
+		if dbLoan.Direction != string(models.LoanOut) || !matching[dbLoan.BorrowerPeerID] {
+			continue
+		}
+
+		loan := dbLoanToModel(dbLoan, now)
+		loan.ID = 0
+		loan.ItemID = nil
+		loan.BorrowerPeerID = ""
+		loans = append(loans, loan)
+	}
+
+	return loans, nil
+}
+
+// ApplyLoans stores the loans a peer made to this instance. The lender's
+// version wins when it is newer. It returns how many new loans were received.
+func (s *GossipService) ApplyLoans(ctx context.Context, peerID string, loans []models.Loan) (int, error) {
+	received := 0
+
+	err := s.database.WithTx(ctx, func(q *db.Queries) error {
+		peer, err := q.GetPeer(ctx, peerID)
+		if err != nil {
+			return fmt.Errorf("peer not found: %w", err)
+		}
+
+		for _, remote := range loans {
+			if remote.UID == "" || remote.Direction != models.LoanOut {
+				continue
+			}
+
+			local, err := q.GetLoanByUID(ctx, remote.UID)
+			if errors.Is(err, sql.ErrNoRows) {
+				if _, err := q.CreateLoan(ctx, db.CreateLoanParams{
+					UID:          remote.UID,
+					Direction:    string(models.LoanIn),
+					ItemName:     remote.ItemName,
+					BorrowerName: remote.BorrowerName,
+					LenderPeerID: peerID,
+					LenderName:   peer.Name,
+					LentAt:       remote.LentAt,
+					DueAt:        nullTime(remote.DueAt),
+					ReturnedAt:   nullTime(remote.ReturnedAt),
+					Notes:        remote.Notes,
+					CreatedAt:    remote.CreatedAt,
+					UpdatedAt:    remote.UpdatedAt,
+				}); err != nil {
+					return fmt.Errorf("failed to create loan: %w", err)
+				}
+				if remote.ReturnedAt == nil {
+					received++
+				}
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to get loan: %w", err)
+			}
+
+			// Only the lender's instance updates a loan
+			if local.Direction != string(models.LoanIn) || local.LenderPeerID != peerID || !remote.UpdatedAt.After(local.UpdatedAt) {
+				continue
+			}
+
+			local.ItemName = remote.ItemName
+			local.BorrowerName = remote.BorrowerName
+			local.LenderName = peer.Name
+			local.LentAt = remote.LentAt
+			local.DueAt = nullTime(remote.DueAt)
+			local.ReturnedAt = nullTime(remote.ReturnedAt)
+			local.Notes = remote.Notes
+			local.UpdatedAt = remote.UpdatedAt
+			if err := updateLoan(ctx, q, local); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return received, nil
+}
+
+// SyncLoansWithPeer fetches the loans a peer made to this instance. It returns
+// how many new loans were received.
+func (s *GossipService) SyncLoansWithPeer(ctx context.Context, peerID string) (int, error) {
+	dbPeer, err := s.queries.GetPeer(ctx, peerID)
+	if err != nil {
+		return 0, fmt.Errorf("peer not found: %w", err)
+	}
+
+	// Signed for the key the lender gave on the last sync
+	if dbPeer.PublicKey == "" {
+		return 0, errors.New("the key of the peer is not known yet")
+	}
+	request, err := s.NewLoansRequest(ctx, dbPeer.PublicKey)
+	if err != nil {
+		return 0, err
+	}
+
+	var loans []models.Loan
+	path := "/api/v1/gossip/loans?" + request.Query().Encode()
+	if err := s.getPeerJSON(ctx, dbPeer.Address, path, &loans); err != nil {
+		return 0, fmt.Errorf("failed to get loans from peer: %w", err)
+	}
+
+	return s.ApplyLoans(ctx, peerID, loans)
+}
+
+// loadItemLoan returns the current loan of an item, or nil if it isn't lent
+func loadItemLoan(ctx context.Context, q *db.Queries, itemID int64) (*models.Loan, error) {
+	dbLoan, err := q.GetActiveLoanByItemID(ctx, sql.NullInt64{Int64: itemID, Valid: true})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loan: %w", err)
+	}
+
+	loan := dbLoanToModel(dbLoan, time.Now())
+	return &loan, nil
+}
+
+// updateLoan stores the mutable fields of a loan
+func updateLoan(ctx context.Context, q *db.Queries, loan db.Loan) error {
+	if err := q.UpdateLoan(ctx, db.UpdateLoanParams{
+		ItemName:     loan.ItemName,
+		BorrowerName: loan.BorrowerName,
+		LenderName:   loan.LenderName,
+		LentAt:       loan.LentAt,
+		DueAt:        loan.DueAt,
+		ReturnedAt:   loan.ReturnedAt,
+		Notes:        loan.Notes,
+		UpdatedAt:    loan.UpdatedAt,
+		ID:           loan.ID,
+	}); err != nil {
+		return fmt.Errorf("failed to update loan: %w", err)
+	}
+	return nil
+}
+
+// dbLoanToModel converts a DB loan to a model loan, overdue as of now
+func dbLoanToModel(dbLoan db.Loan, now time.Time) models.Loan {
+	loan := models.Loan{
+		ID:             dbLoan.ID,
+		UID:            dbLoan.UID,
+		Direction:      models.LoanDirection(dbLoan.Direction),
+		ItemID:         nullInt64Ptr(dbLoan.ItemID),
+		ItemName:       dbLoan.ItemName,
+		BorrowerPeerID: dbLoan.BorrowerPeerID,
+		BorrowerName:   dbLoan.BorrowerName,
+		LenderPeerID:   dbLoan.LenderPeerID,
+		LenderName:     dbLoan.LenderName,
+		LentAt:         dbLoan.LentAt,
+		Notes:          dbLoan.Notes,
+		CreatedAt:      dbLoan.CreatedAt,
+		UpdatedAt:      dbLoan.UpdatedAt,
+	}
+
+	if dbLoan.DueAt.Valid {
+		loan.DueAt = &dbLoan.DueAt.Time
+	}
+	if dbLoan.ReturnedAt.Valid {
+		loan.ReturnedAt = &dbLoan.ReturnedAt.Time
+	}
+	loan.Overdue = loan.ReturnedAt == nil && loan.DueAt != nil && loan.DueAt.Before(now)
+
+	return loan
+}
@@ -0,0 +1,327 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lhommenul/brique/core/db"
+	"github.com/lhommenul/brique/core/models"
+)
+
+// defaultUsageUnit is the counter of usage-based schedules that don't name one
+const defaultUsageUnit = "hours"
+
+// AddMaintenanceSchedule attaches a preventive maintenance task to an item.
+// Usage-based schedules start counting from the current value of the item's
+// counter.
+func (s *BackpackService) AddMaintenanceSchedule(ctx context.Context, schedule *models.MaintenanceSchedule) error {
+	schedule.Task = strings.TrimSpace(schedule.Task)
+	schedule.UsageUnit = normalizeUsageUnit(schedule.UsageUnit)
+
+	if schedule.Task == "" {
+		return errors.New("maintenance task is required")
+	}
+	if schedule.IntervalDays < 0 || schedule.IntervalUsage < 0 {
+		return errors.New("maintenance intervals can't be negative")
+	}
+	if schedule.IntervalDays == 0 && schedule.IntervalUsage == 0 {
+		return errors.New("a maintenance schedule needs an interval in days or in usage")
+	}
+	if schedule.IntervalUsage == 0 {
+		schedule.UsageUnit = ""
+	}
+
+	now := time.Now()
+	var created db.MaintenanceSchedule
+	err := s.inTx(ctx, func(tx *BackpackService) error {
+		if _, err := tx.queries.GetItemByID(ctx, schedule.ItemID); err != nil {
+			return fmt.Errorf("item not found: %w", err)
+		}
+
+		usage, err := itemUsage(ctx, tx.queries, schedule.ItemID)
+		if err != nil {
+			return err
+		}
+
+		created, err = tx.queries.CreateMaintenanceSchedule(ctx, db.CreateMaintenanceScheduleParams{
+			ItemID:        schedule.ItemID,
+			Task:          schedule.Task,
+			IntervalDays:  schedule.IntervalDays,
+			IntervalUsage: schedule.IntervalUsage,
+			UsageUnit:     schedule.UsageUnit,
+			LastDoneUsage: usage[schedule.UsageUnit],
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create maintenance schedule: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	*schedule = dbMaintenanceScheduleToModel(created)
+	return nil
+}
+
+// GetItemMaintenance returns the maintenance schedules of an item with their
+// next due point
+func (s *BackpackService) GetItemMaintenance(ctx context.Context, itemID int64) ([]models.MaintenanceTask, error) {
+	item, err := s.queries.GetItemByID(ctx, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("item not found: %w", err)
+	}
+
+	schedules, err := s.queries.GetMaintenanceSchedulesByItemID(ctx, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get maintenance schedules: %w", err)
+	}
+
+	usage, err := itemUsage(ctx, s.queries, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	tasks := make([]models.MaintenanceTask, len(schedules))
+	for i, schedule := range schedules {
+		tasks[i] = maintenanceTask(dbMaintenanceScheduleToModel(schedule), item.Name, usage, now)
+	}
+
+	return tasks, nil
+}
+
+// GetDueMaintenance returns the maintenance tasks that are due, or will be
+// within the given duration, overdue ones first then by due date
+func (s *BackpackService) GetDueMaintenance(ctx context.Context, within time.Duration) ([]models.MaintenanceTask, error) {
+	schedules, err := s.queries.GetAllMaintenanceSchedules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get maintenance schedules: %w", err)
+	}
+
+	counters, err := s.queries.GetAllItemUsageCounters(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get usage counters: %w", err)
+	}
+	usage := make(map[int64]map[string]int64)
+	for _, counter := range counters {
+		if usage[counter.ItemID] == nil {
+			usage[counter.ItemID] = make(map[string]int64)
+		}
+		usage[counter.ItemID][counter.Unit] = counter.Value
+	}
+
+	names := make(map[int64]string)
+	now := time.Now()
+	horizon := now.Add(within)
+
+	tasks := []models.MaintenanceTask{}
+	for _, schedule := range schedules {
+		name, ok := names[schedule.ItemID]
+		if !ok {
+			item, err := s.queries.GetItemByID(ctx, schedule.ItemID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get item: %w", err)
+			}
+			name = item.Name
+			names[schedule.ItemID] = name
+		}
+
+		task := maintenanceTask(dbMaintenanceScheduleToModel(schedule), name, usage[schedule.ItemID], now)
+		if task.Due || (task.NextDueAt != nil && !task.NextDueAt.After(horizon)) {
+			tasks = append(tasks, task)
+		}
+	}
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].Due != tasks[j].Due {
+			return tasks[i].Due
+		}
+		if tasks[i].NextDueAt == nil || tasks[j].NextDueAt == nil {
+			return tasks[i].NextDueAt != nil
+		}
+		return tasks[i].NextDueAt.Before(*tasks[j].NextDueAt)
+	})
+
+	return tasks, nil
+}
+
+// CompleteMaintenance records that a maintenance task was done, at doneAt or
+// now if zero, and returns its next due point
+func (s *BackpackService) CompleteMaintenance(ctx context.Context, scheduleID int64, doneAt time.Time) (*models.MaintenanceTask, error) {
+	if doneAt.IsZero() {
+		doneAt = time.Now()
+	}
+
+	var task models.MaintenanceTask
+	err := s.inTx(ctx, func(tx *BackpackService) error {
+		dbSchedule, err := tx.queries.GetMaintenanceScheduleByID(ctx, scheduleID)
+		if err != nil {
+			return fmt.Errorf("maintenance schedule not found: %w", err)
+		}
+
+		item, err := tx.queries.GetItemByID(ctx, dbSchedule.ItemID)
+		if err != nil {
+			return fmt.Errorf("item not found: %w", err)
+		}
+
+		usage, err := itemUsage(ctx, tx.queries, dbSchedule.ItemID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		dbSchedule.LastDoneAt = sql.NullTime{Time: doneAt, Valid: true}
+		dbSchedule.LastDoneUsage = usage[dbSchedule.UsageUnit]
+		dbSchedule.UpdatedAt = now
+
+		if err := tx.queries.CompleteMaintenanceSchedule(ctx, db.CompleteMaintenanceScheduleParams{
+			LastDoneAt:    dbSchedule.LastDoneAt,
+			LastDoneUsage: dbSchedule.LastDoneUsage,
+			UpdatedAt:     now,
+			ID:            scheduleID,
+		}); err != nil {
+			return fmt.Errorf("failed to complete maintenance: %w", err)
+		}
+
+		task = maintenanceTask(dbMaintenanceScheduleToModel(dbSchedule), item.Name, usage, now)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &task, nil
+}
+
+// DeleteMaintenanceSchedule removes a maintenance schedule
+func (s *BackpackService) DeleteMaintenanceSchedule(ctx context.Context, scheduleID int64) error {
+	if _, err := s.queries.GetMaintenanceScheduleByID(ctx, scheduleID); err != nil {
+		return fmt.Errorf("maintenance schedule not found: %w", err)
+	}
+
+	if err := s.queries.DeleteMaintenanceSchedule(ctx, scheduleID); err != nil {
+		return fmt.Errorf("failed to delete maintenance schedule: %w", err)
+	}
+
+	return nil
+}
+
+// RecordUsage sets a usage counter of an item, e.g. its operating hours
+func (s *BackpackService) RecordUsage(ctx context.Context, itemID int64, unit string, value int64) error {
+	if value < 0 {
+		return errors.New("usage can't be negative")
+	}
+
+	if _, err := s.queries.GetItemByID(ctx, itemID); err != nil {
+		return fmt.Errorf("item not found: %w", err)
+	}
+
+	if err := s.queries.SetItemUsageCounter(ctx, db.SetItemUsageCounterParams{
+		ItemID:    itemID,
+		Unit:      normalizeUsageUnit(unit),
+		Value:     value,
+		UpdatedAt: time.Now(),
+	}); err != nil {
+		return fmt.Errorf("failed to record usage: %w", err)
+	}
+
+	return nil
+}
+
+// GetItemUsage returns the usage counters of an item
+func (s *BackpackService) GetItemUsage(ctx context.Context, itemID int64) ([]models.UsageCounter, error) {
+	counters, err := s.queries.GetItemUsageCounters(ctx, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get usage counters: %w", err)
+	}
+
+	usage := make([]models.UsageCounter, len(counters))
+	for i, counter := range counters {
+		usage[i] = models.UsageCounter{Unit: counter.Unit, Value: counter.Value, UpdatedAt: counter.UpdatedAt}
+	}
+
+	return usage, nil
+}
+
+// maintenanceTask computes the next due point of a schedule. The time interval
+// runs from the last time the task was done, or from the creation of the
+// schedule.
+func maintenanceTask(schedule models.MaintenanceSchedule, itemName string, usage map[string]int64, now time.Time) models.MaintenanceTask {
+	task := models.MaintenanceTask{
+		Schedule:     schedule,
+		ItemName:     itemName,
+		CurrentUsage: usage[schedule.UsageUnit],
+	}
+
+	if schedule.IntervalDays > 0 {
+		since := schedule.CreatedAt
+		if schedule.LastDoneAt != nil {
+			since = *schedule.LastDoneAt
+		}
+		nextDueAt := since.AddDate(0, 0, int(schedule.IntervalDays))
+		task.NextDueAt = &nextDueAt
+		task.Due = !now.Before(nextDueAt)
+	}
+
+	if schedule.IntervalUsage > 0 {
+		nextDueUsage := schedule.LastDoneUsage + schedule.IntervalUsage
+		task.NextDueUsage = &nextDueUsage
+		task.Due = task.Due || task.CurrentUsage >= nextDueUsage
+	}
+
+	return task
+}
+
+// itemUsage returns the usage counters of an item, by unit
+func itemUsage(ctx context.Context, q *db.Queries, itemID int64) (map[string]int64, error) {
+	counters, err := q.GetItemUsageCounters(ctx, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get usage counters: %w", err)
+	}
+
+	usage := make(map[string]int64, len(counters))
+	for _, counter := range counters {
+		usage[counter.Unit] = counter.Value
+	}
+
+	return usage, nil
+}
+
+// normalizeUsageUnit lowercases a usage unit, defaulting to hours
+func normalizeUsageUnit(unit string) string {
+	unit = strings.ToLower(strings.TrimSpace(unit))
+	if unit == "" {
+		return defaultUsageUnit
+	}
+	return unit
+}
+
+// dbMaintenanceScheduleToModel converts a DB maintenance schedule to a model
+// maintenance schedule
+func dbMaintenanceScheduleToModel(dbSchedule db.MaintenanceSchedule) models.MaintenanceSchedule {
+	schedule := models.MaintenanceSchedule{
+		ID:            dbSchedule.ID,
+		ItemID:        dbSchedule.ItemID,
+		Task:          dbSchedule.Task,
+		IntervalDays:  dbSchedule.IntervalDays,
+		IntervalUsage: dbSchedule.IntervalUsage,
+		UsageUnit:     dbSchedule.UsageUnit,
+		LastDoneUsage: dbSchedule.LastDoneUsage,
+		CreatedAt:     dbSchedule.CreatedAt,
+		UpdatedAt:     dbSchedule.UpdatedAt,
+	}
+
+	if dbSchedule.LastDoneAt.Valid {
+		schedule.LastDoneAt = &dbSchedule.LastDoneAt.Time
+	}
+
+	return schedule
+}
@@ -0,0 +1,358 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lhommenul/brique/core/db"
+	"github.com/lhommenul/brique/core/models"
+)
+
+// CreatePart adds a spare part to the inventory, with its compatible items and
+// product models and its STL files
+func (s *BackpackService) CreatePart(ctx context.Context, part *models.Part) error {
+	if err := normalizePart(part); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	err := s.inTx(ctx, func(tx *BackpackService) error {
+		key := partKey(part.Name, part.Reference)
+		if _, err := tx.queries.GetPartByKey(ctx, key); err == nil {
+			return fmt.Errorf("part %q already exists", part.Name)
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to get part: %w", err)
+		}
+
+		created, err := tx.queries.CreatePart(ctx, db.CreatePartParams{
+			Name:        part.Name,
+			Reference:   part.Reference,
+			PartKey:     key,
+			Quantity:    part.Quantity,
+			MinQuantity: part.MinQuantity,
+			Location:    part.Location,
+			Notes:       part.Notes,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create part: %w", err)
+		}
+
+		part.ID = created.ID
+		return replacePartLinks(ctx, tx.queries, part)
+	})
+	if err != nil {
+		return err
+	}
+
+	part.CreatedAt = now
+	part.UpdatedAt = now
+	initPartLinks(part)
+
+	return nil
+}
+
+// GetParts returns every spare part with its links
+func (s *BackpackService) GetParts(ctx context.Context) ([]models.Part, error) {
+	dbParts, err := s.queries.GetAllParts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parts: %w", err)
+	}
+
+	return s.partsWithLinks(ctx, dbParts)
+}
+
+// GetPart returns a spare part with its links
+func (s *BackpackService) GetPart(ctx context.Context, id int64) (*models.Part, error) {
+	dbPart, err := s.queries.GetPartByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get part: %w", err)
+	}
+
+	parts, err := s.partsWithLinks(ctx, []db.Part{dbPart})
+	if err != nil {
+		return nil, err
+	}
+
+	return &parts[0], nil
+}
+
+// GetLowStockParts returns the parts whose quantity on hand fell below their
+// minimum
+func (s *BackpackService) GetLowStockParts(ctx context.Context) ([]models.Part, error) {
+	dbParts, err := s.queries.GetLowStockParts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get low stock parts: %w", err)
+	}
+
+	return s.partsWithLinks(ctx, dbParts)
+}
+
+// GetItemParts returns the parts that fit an item, directly or through its
+// product model
+func (s *BackpackService) GetItemParts(ctx context.Context, itemID int64) ([]models.Part, error) {
+	item, err := s.queries.GetItemByID(ctx, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("item not found: %w", err)
+	}
+
+	dbParts, err := s.queries.GetPartsForItem(ctx, db.GetPartsForItemParams{
+		ItemID:         item.ID,
+		ProductModelID: item.ProductModelID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parts: %w", err)
+	}
+
+	return s.partsWithLinks(ctx, dbParts)
+}
+
+// UpdatePart updates a spare part. Its links are replaced unless the
+// corresponding slice is nil.
+func (s *BackpackService) UpdatePart(ctx context.Context, part *models.Part) error {
+	if err := normalizePart(part); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	err := s.inTx(ctx, func(tx *BackpackService) error {
+		if _, err := tx.queries.GetPartByID(ctx, part.ID); err != nil {
+			return fmt.Errorf("part not found: %w", err)
+		}
+
+		key := partKey(part.Name, part.Reference)
+		if existing, err := tx.queries.GetPartByKey(ctx, key); err == nil && existing.ID != part.ID {
+			return fmt.Errorf("part %q already exists", existing.Name)
+		} else if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to get part: %w", err)
+		}
+
+		if err := tx.queries.UpdatePart(ctx, db.UpdatePartParams{
+			Name:        part.Name,
+			Reference:   part.Reference,
+			PartKey:     key,
+			Quantity:    part.Quantity,
+			MinQuantity: part.MinQuantity,
+			Location:    part.Location,
+			Notes:       part.Notes,
+			UpdatedAt:   now,
+			ID:          part.ID,
+		}); err != nil {
+			return fmt.Errorf("failed to update part: %w", err)
+		}
+
+		return replacePartLinks(ctx, tx.queries, part)
+	})
+	if err != nil {
+		return err
+	}
+
+	updated, err := s.GetPart(ctx, part.ID)
+	if err != nil {
+		return err
+	}
+	*part = *updated
+
+	return nil
+}
+
+// AdjustPartStock adds delta to the quantity on hand of a part, e.g. -1 when
+// one is used in a repair
+func (s *BackpackService) AdjustPartStock(ctx context.Context, id int64, delta int64) (*models.Part, error) {
+	err := s.inTx(ctx, func(tx *BackpackService) error {
+		current, err := tx.queries.GetPartByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("part not found: %w", err)
+		}
+
+		quantity := current.Quantity + delta
+		if quantity < 0 {
+			return fmt.Errorf("only %d of %q in stock", current.Quantity, current.Name)
+		}
+
+		if err := tx.queries.SetPartQuantity(ctx, db.SetPartQuantityParams{
+			Quantity:  quantity,
+			UpdatedAt: time.Now(),
+			ID:        id,
+		}); err != nil {
+			return fmt.Errorf("failed to update stock: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetPart(ctx, id)
+}
+
+// DeletePart removes a spare part. Its STL files are kept.
+func (s *BackpackService) DeletePart(ctx context.Context, id int64) error {
+	if _, err := s.queries.GetPartByID(ctx, id); err != nil {
+		return fmt.Errorf("part not found: %w", err)
+	}
+
+	if err := s.queries.DeletePart(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete part: %w", err)
+	}
+
+	return nil
+}
+
+// normalizePart trims the text fields of a part and checks them
+func normalizePart(part *models.Part) error {
+	part.Name = strings.TrimSpace(part.Name)
+	part.Reference = strings.TrimSpace(part.Reference)
+	part.Location = strings.TrimSpace(part.Location)
+
+	if part.Name == "" {
+		return errors.New("part name is required")
+	}
+	if partKey(part.Name, part.Reference) == "" {
+		return fmt.Errorf("invalid part name %q", part.Name)
+	}
+	if part.Quantity < 0 || part.MinQuantity < 0 {
+		return errors.New("part quantities can't be negative")
+	}
+
+	return nil
+}
+
+// replacePartLinks stores the compatible items and product models and the STL
+// files of a part, for each non-nil slice
+func replacePartLinks(ctx context.Context, q *db.Queries, part *models.Part) error {
+	if part.ItemIDs != nil {
+		if err := q.DeletePartItems(ctx, part.ID); err != nil {
+			return fmt.Errorf("failed to clear part items: %w", err)
+		}
+		part.ItemIDs = uniqueIDs(part.ItemIDs)
+		for _, itemID := range part.ItemIDs {
+			if _, err := q.GetItemByID(ctx, itemID); err != nil {
+				return fmt.Errorf("item %d not found: %w", itemID, err)
+			}
+			if err := q.AddPartItem(ctx, db.AddPartItemParams{PartID: part.ID, ItemID: itemID}); err != nil {
+				return fmt.Errorf("failed to link item: %w", err)
+			}
+		}
+	}
+
+	if part.ProductModelIDs != nil {
+		if err := q.DeletePartProductModels(ctx, part.ID); err != nil {
+			return fmt.Errorf("failed to clear part product models: %w", err)
+		}
+		part.ProductModelIDs = uniqueIDs(part.ProductModelIDs)
+		for _, productModelID := range part.ProductModelIDs {
+			if _, err := q.GetProductModelByID(ctx, productModelID); err != nil {
+				return fmt.Errorf("product model %d not found: %w", productModelID, err)
+			}
+			if err := q.AddPartProductModel(ctx, db.AddPartProductModelParams{PartID: part.ID, ProductModelID: productModelID}); err != nil {
+				return fmt.Errorf("failed to link product model: %w", err)
+			}
+		}
+	}
+
+	if part.AssetIDs != nil {
+		if err := q.DeletePartAssets(ctx, part.ID); err != nil {
+			return fmt.Errorf("failed to clear part assets: %w", err)
+		}
+		part.AssetIDs = uniqueIDs(part.AssetIDs)
+		for _, assetID := range part.AssetIDs {
+			asset, err := q.GetAssetByID(ctx, assetID)
+			if err != nil {
+				return fmt.Errorf("asset %d not found: %w", assetID, err)
+			}
+			if asset.Type != string(models.AssetTypeSTL) {
+				return fmt.Errorf("asset %d is not an STL file", assetID)
+			}
+			if err := q.AddPartAsset(ctx, db.AddPartAssetParams{PartID: part.ID, AssetID: assetID}); err != nil {
+				return fmt.Errorf("failed to link asset: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// partsWithLinks converts DB parts to model parts with their links
+func (s *BackpackService) partsWithLinks(ctx context.Context, dbParts []db.Part) ([]models.Part, error) {
+	items, err := s.queries.GetAllPartItems(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get part items: %w", err)
+	}
+
+	productModels, err := s.queries.GetAllPartProductModels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get part product models: %w", err)
+	}
+
+	assets, err := s.queries.GetAllPartAssets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get part assets: %w", err)
+	}
+
+	itemIDs := make(map[int64][]int64)
+	for _, link := range items {
+		itemIDs[link.PartID] = append(itemIDs[link.PartID], link.ItemID)
+	}
+	productModelIDs := make(map[int64][]int64)
+	for _, link := range productModels {
+		productModelIDs[link.PartID] = append(productModelIDs[link.PartID], link.ProductModelID)
+	}
+	assetIDs := make(map[int64][]int64)
+	for _, link := range assets {
+		assetIDs[link.PartID] = append(assetIDs[link.PartID], link.AssetID)
+	}
+
+	parts := make([]models.Part, len(dbParts))
+	for i, dbPart := range dbParts {
+		parts[i] = dbPartToModel(dbPart)
+		parts[i].ItemIDs = itemIDs[dbPart.ID]
+		parts[i].ProductModelIDs = productModelIDs[dbPart.ID]
+		parts[i].AssetIDs = assetIDs[dbPart.ID]
+		initPartLinks(&parts[i])
+	}
+
+	return parts, nil
+}
+
+// initPartLinks replaces nil links with empty slices
+func initPartLinks(part *models.Part) {
+	if part.ItemIDs == nil {
+		part.ItemIDs = []int64{}
+	}
+	if part.ProductModelIDs == nil {
+		part.ProductModelIDs = []int64{}
+	}
+	if part.AssetIDs == nil {
+		part.AssetIDs = []int64{}
+	}
+}
+
+// partKey identifies a part across instances: its normalised reference, or
+// its normalised name without one
+func partKey(name, reference string) string {
+	if key := foldProductName(reference); key != "" {
+		return key
+	}
+	return foldProductName(name)
+}
+
+// dbPartToModel converts a DB part to a model part
+func dbPartToModel(dbPart db.Part) models.Part {
+	return models.Part{
+		ID:          dbPart.ID,
+		Name:        dbPart.Name,
+		Reference:   dbPart.Reference,
+		Quantity:    dbPart.Quantity,
+		MinQuantity: dbPart.MinQuantity,
+		Location:    dbPart.Location,
+		Notes:       dbPart.Notes,
+		CreatedAt:   dbPart.CreatedAt,
+		UpdatedAt:   dbPart.UpdatedAt,
+	}
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/lhommenul/brique/core/models"
+)
+
+// AddPhoto imports an image into the managed asset store as a photo of the
+// item and generates its thumbnail
+func (s *BackpackService) AddPhoto(ctx context.Context, itemID int64, sourcePath string) (*models.Asset, error) {
+	asset, err := s.AddAsset(ctx, itemID, models.AssetTypePhoto, filepath.Base(sourcePath), sourcePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.writeThumbnailFrom(ctx, sourcePath, s.thumbnailPath(asset.ID)); err != nil {
+		if delErr := s.DeleteAsset(ctx, asset.ID); delErr != nil {
+			s.logger.Warn("Failed to remove photo", "asset_id", asset.ID, "error", delErr)
+		}
+		return nil, err
+	}
+
+	return asset, nil
+}
+
+// GetItemPhotos retrieves the photos of an item
+func (s *BackpackService) GetItemPhotos(ctx context.Context, itemID int64) ([]models.Asset, error) {
+	assets, err := s.GetItemAssets(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	photos := []models.Asset{}
+	for _, asset := range assets {
+		if asset.Type == models.AssetTypePhoto {
+			photos = append(photos, asset)
+		}
+	}
+
+	return photos, nil
+}
+
+// GetThumbnail returns the path of a photo's thumbnail, generating it when
+// missing (e.g. for photos received from a peer)
+func (s *BackpackService) GetThumbnail(ctx context.Context, assetID int64) (string, error) {
+	asset, err := s.GetAsset(ctx, assetID)
+	if err != nil {
+		return "", err
+	}
+
+	if asset.Type != models.AssetTypePhoto {
+		return "", fmt.Errorf("asset %d is not a photo", assetID)
+	}
+
+	path := s.thumbnailPath(assetID)
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	source, err := s.openStoredFile(asset.FilePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open image: %w", err)
+	}
+	defer source.Close()
+
+	if err := s.writeThumbnail(ctx, source, path); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+// ImportLegacyPhotos moves the photos referenced by Item.PhotoPath into the
+// managed asset store and clears the path. Items whose file is gone are kept
+// as they are.
+func (s *BackpackService) ImportLegacyPhotos(ctx context.Context) (int, error) {
+	items, err := s.GetAllItems(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	imported := 0
+	for _, item := range items {
+		if item.PhotoPath == "" {
+			continue
+		}
+
+		if _, err := os.Stat(item.PhotoPath); err != nil {
+			s.logger.Warn("Photo of item not found", "item_id", item.ID, "path", item.PhotoPath)
+			continue
+		}
+
+		if _, err := s.AddPhoto(ctx, item.ID, item.PhotoPath); err != nil {
+			return imported, fmt.Errorf("failed to import photo of item %d: %w", item.ID, err)
+		}
+
+		item.PhotoPath = ""
+		if err := s.UpdateItem(ctx, &item); err != nil {
+			return imported, err
+		}
+
+		imported++
+	}
+
+	return imported, nil
+}
+
+// writeThumbnailFrom generates the thumbnail of an image file
+func (s *BackpackService) writeThumbnailFrom(ctx context.Context, sourcePath, path string) error {
+	source, err := os.Open(sourcePath)
+	if err != nil {
+		return fmt.Errorf("failed to open image: %w", err)
+	}
+	defer source.Close()
+
+	return s.writeThumbnail(ctx, source, path)
+}
+
+// writeThumbnail generates the thumbnail of an image, written encrypted when
+// encryption is enabled
+func (s *BackpackService) writeThumbnail(ctx context.Context, source io.Reader, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create thumbnail directory: %w", err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create thumbnail: %w", err)
+	}
+
+	dest, err := s.encryptTo(ctx, file)
+	if err != nil {
+		file.Close()
+		os.Remove(path)
+		return err
+	}
+
+	err = generateThumbnail(source, dest)
+	if closeErr := dest.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	return nil
+}
+
+// thumbnailPath is where the thumbnail of an asset is cached
+func (s *BackpackService) thumbnailPath(assetID int64) string {
+	return filepath.Join(s.assetsDir, "thumbnails", fmt.Sprintf("asset_%d.jpg", assetID))
+}
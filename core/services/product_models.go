@@ -0,0 +1,540 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/lhommenul/brique/core/db"
+	"github.com/lhommenul/brique/core/models"
+)
+
+// assetOwner is the item or the product model an asset belongs to. Exactly
+// one of the IDs is set.
+type assetOwner struct {
+	itemID         int64
+	productModelID int64
+}
+
+// ownerOf returns the owner of a stored asset
+func ownerOf(asset db.Asset) assetOwner {
+	return assetOwner{itemID: asset.ItemID.Int64, productModelID: asset.ProductModelID.Int64}
+}
+
+// check verifies that the owner exists
+func (o assetOwner) check(ctx context.Context, q *db.Queries) error {
+	if o.productModelID != 0 {
+		if _, err := q.GetProductModelByID(ctx, o.productModelID); err != nil {
+			return fmt.Errorf("product model not found: %w", err)
+		}
+		return nil
+	}
+
+	if _, err := q.GetItemByID(ctx, o.itemID); err != nil {
+		return fmt.Errorf("item not found: %w", err)
+	}
+	return nil
+}
+
+// dir returns the directory of the owner's files, relative to the assets directory
+func (o assetOwner) dir() string {
+	if o.productModelID != 0 {
+		return fmt.Sprintf("model_%d", o.productModelID)
+	}
+	return fmt.Sprintf("item_%d", o.itemID)
+}
+
+func (o assetOwner) itemIDParam() sql.NullInt64 {
+	return sql.NullInt64{Int64: o.itemID, Valid: o.productModelID == 0}
+}
+
+func (o assetOwner) productModelIDParam() sql.NullInt64 {
+	return sql.NullInt64{Int64: o.productModelID, Valid: o.productModelID != 0}
+}
+
+// ownerAssets returns the assets stored for an owner only, newest first
+func (s *BackpackService) ownerAssets(ctx context.Context, owner assetOwner) ([]models.Asset, error) {
+	var dbAssets []db.Asset
+	var err error
+	if owner.productModelID != 0 {
+		dbAssets, err = s.queries.GetAssetsByProductModelID(ctx, owner.productModelIDParam())
+	} else {
+		dbAssets, err = s.queries.GetAssetsByItemID(ctx, owner.itemIDParam())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assets: %w", err)
+	}
+
+	assets := make([]models.Asset, len(dbAssets))
+	for i, dbAsset := range dbAssets {
+		assets[i] = *s.dbAssetToModel(dbAsset)
+	}
+
+	return assets, nil
+}
+
+// GetProductModels returns every product model with its aliases
+func (s *BackpackService) GetProductModels(ctx context.Context) ([]models.ProductModel, error) {
+	dbModels, err := s.queries.GetAllProductModels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product models: %w", err)
+	}
+
+	aliases, err := loadProductModelAliases(ctx, s.queries)
+	if err != nil {
+		return nil, err
+	}
+
+	productModels := make([]models.ProductModel, len(dbModels))
+	for i, m := range dbModels {
+		productModels[i] = dbProductModelToModel(m, aliases[m.ID])
+	}
+
+	return productModels, nil
+}
+
+// GetProductModel returns a product model with its shared documentation and
+// the number of local items of that model
+func (s *BackpackService) GetProductModel(ctx context.Context, id int64) (*models.ProductModelWithAssets, error) {
+	dbModel, err := s.queries.GetProductModelByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product model: %w", err)
+	}
+
+	aliases, err := loadProductModelAliases(ctx, s.queries)
+	if err != nil {
+		return nil, err
+	}
+
+	assets, err := s.ownerAssets(ctx, assetOwner{productModelID: id})
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.queries.CountItemsByProductModelID(ctx, sql.NullInt64{Int64: id, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count items: %w", err)
+	}
+
+	return &models.ProductModelWithAssets{
+		ProductModel: dbProductModelToModel(dbModel, aliases[id]),
+		Assets:       assets,
+		ItemCount:    count,
+	}, nil
+}
+
+// GetProductModelCatalogue returns every product model with its shared
+// documentation, as exchanged with peers
+func (s *BackpackService) GetProductModelCatalogue(ctx context.Context) ([]models.ProductModelWithAssets, error) {
+	productModels, err := s.GetProductModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	dbAssets, err := s.queries.GetAllProductModelAssets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product model assets: %w", err)
+	}
+
+	byModel := make(map[int64][]models.Asset)
+	for _, a := range dbAssets {
+		byModel[a.ProductModelID.Int64] = append(byModel[a.ProductModelID.Int64], *s.dbAssetToModel(a))
+	}
+
+	catalogue := make([]models.ProductModelWithAssets, len(productModels))
+	for i, m := range productModels {
+		catalogue[i] = models.ProductModelWithAssets{ProductModel: m, Assets: byModel[m.ID]}
+		if catalogue[i].Assets == nil {
+			catalogue[i].Assets = []models.Asset{}
+		}
+	}
+
+	return catalogue, nil
+}
+
+// AddProductModelAlias makes another brand and model resolve to a product
+// model. When the alias is the name of another product model, both turn out
+// to be the same product: the other model is merged into this one, with its
+// items, documentation and aliases.
+func (s *BackpackService) AddProductModelAlias(ctx context.Context, id int64, brand, model string) error {
+	brand, model = strings.TrimSpace(brand), strings.TrimSpace(model)
+	key := productModelKey(brand, model)
+	if key == "" {
+		return fmt.Errorf("brand and model are required")
+	}
+
+	return s.inTx(ctx, func(tx *BackpackService) error {
+		target, err := tx.queries.GetProductModelByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get product model: %w", err)
+		}
+
+		if key == target.ModelKey {
+			return fmt.Errorf("%s %s is already the name of this product model", brand, model)
+		}
+
+		alias, err := tx.queries.GetProductModelAlias(ctx, key)
+		if err == nil && alias.ProductModelID != id {
+			return fmt.Errorf("%s %s is already an alias of product model %d", brand, model, alias.ProductModelID)
+		} else if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to get product model alias: %w", err)
+		}
+
+		source, err := tx.queries.GetProductModelByKey(ctx, key)
+		if err == nil {
+			if err := mergeProductModel(ctx, tx.queries, source, target); err != nil {
+				return err
+			}
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to get product model: %w", err)
+		}
+
+		if err := tx.queries.CreateProductModelAlias(ctx, db.CreateProductModelAliasParams{
+			ModelKey:       key,
+			ProductModelID: id,
+			Brand:          brand,
+			Model:          model,
+		}); err != nil {
+			return fmt.Errorf("failed to add alias: %w", err)
+		}
+
+		return touchProductModel(ctx, tx.queries, id)
+	})
+}
+
+// RemoveProductModelAlias removes an alias. Items already linked through it
+// stay linked until their brand or model changes.
+func (s *BackpackService) RemoveProductModelAlias(ctx context.Context, brand, model string) error {
+	key := productModelKey(brand, model)
+
+	return s.inTx(ctx, func(tx *BackpackService) error {
+		alias, err := tx.queries.GetProductModelAlias(ctx, key)
+		if err != nil {
+			return fmt.Errorf("unknown alias: %s %s", brand, model)
+		}
+
+		if err := tx.queries.DeleteProductModelAlias(ctx, key); err != nil {
+			return fmt.Errorf("failed to remove alias: %w", err)
+		}
+
+		return touchProductModel(ctx, tx.queries, alias.ProductModelID)
+	})
+}
+
+// AddProductModelAsset adds a document shared by every item of a product model
+func (s *BackpackService) AddProductModelAsset(ctx context.Context, productModelID int64, assetType models.AssetType, name string, sourcePath string) (*models.Asset, error) {
+	var asset *models.Asset
+	err := s.inTx(ctx, func(tx *BackpackService) error {
+		var err error
+		asset, err = tx.createAsset(ctx, assetOwner{productModelID: productModelID}, assetType, name, sourcePath, assetRevision{})
+		if err != nil {
+			return err
+		}
+		return touchProductModel(ctx, tx.queries, productModelID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return asset, nil
+}
+
+// ShareAsset moves an item asset, with its whole revision chain, to the
+// product model of the item so that every item of that model gets it
+func (s *BackpackService) ShareAsset(ctx context.Context, assetID int64) (*models.Asset, error) {
+	err := s.inTx(ctx, func(tx *BackpackService) error {
+		dbAsset, err := tx.queries.GetAssetByID(ctx, assetID)
+		if err != nil {
+			return fmt.Errorf("failed to get asset: %w", err)
+		}
+
+		if !dbAsset.ItemID.Valid {
+			return fmt.Errorf("asset %d is already shared", assetID)
+		}
+
+		dbItem, err := tx.queries.GetItemByID(ctx, dbAsset.ItemID.Int64)
+		if err != nil {
+			return fmt.Errorf("failed to get item: %w", err)
+		}
+
+		if !dbItem.ProductModelID.Valid {
+			return fmt.Errorf("item %d has no product model: set its brand and model first", dbItem.ID)
+		}
+
+		history, err := tx.GetAssetHistory(ctx, assetID)
+		if err != nil {
+			return err
+		}
+
+		for _, revision := range history {
+			if err := tx.queries.SetAssetProductModel(ctx, db.SetAssetProductModelParams{
+				ProductModelID: dbItem.ProductModelID,
+				ID:             revision.ID,
+			}); err != nil {
+				return fmt.Errorf("failed to share asset: %w", err)
+			}
+		}
+
+		return touchProductModel(ctx, tx.queries, dbItem.ProductModelID.Int64)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetAsset(ctx, assetID)
+}
+
+// LinkProductModels links every item to the product model of its brand and
+// model, e.g. for items created before product models existed. It returns
+// how many items were relinked.
+func (s *BackpackService) LinkProductModels(ctx context.Context) (int, error) {
+	linked := 0
+	err := s.inTx(ctx, func(tx *BackpackService) error {
+		linked = 0
+		dbItems, err := tx.queries.GetAllItems(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get items: %w", err)
+		}
+
+		for _, dbItem := range dbItems {
+			productModelID, err := resolveProductModel(ctx, tx.queries, dbItem.Brand, dbItem.Model)
+			if err != nil {
+				return err
+			}
+
+			if productModelID == dbItem.ProductModelID {
+				continue
+			}
+
+			if err := tx.queries.SetItemProductModel(ctx, db.SetItemProductModelParams{
+				ProductModelID: productModelID,
+				ID:             dbItem.ID,
+			}); err != nil {
+				return fmt.Errorf("failed to link item %d: %w", dbItem.ID, err)
+			}
+			linked++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return linked, nil
+}
+
+// resolveProductModel returns the product model of a brand and model,
+// matching names and aliases regardless of case, accents and punctuation.
+// Unknown models are created. Items without a brand or a model have none.
+// Must be called within a transaction.
+func resolveProductModel(ctx context.Context, q *db.Queries, brand, model string) (sql.NullInt64, error) {
+	key := productModelKey(brand, model)
+	if key == "" {
+		return sql.NullInt64{}, nil
+	}
+
+	existing, ok, err := lookupProductModel(ctx, q, key)
+	if err != nil {
+		return sql.NullInt64{}, err
+	}
+	if ok {
+		return sql.NullInt64{Int64: existing.ID, Valid: true}, nil
+	}
+
+	now := time.Now()
+	created, err := q.CreateProductModel(ctx, db.CreateProductModelParams{
+		Brand:     strings.TrimSpace(brand),
+		Model:     strings.TrimSpace(model),
+		ModelKey:  key,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		return sql.NullInt64{}, fmt.Errorf("failed to create product model: %w", err)
+	}
+
+	return sql.NullInt64{Int64: created.ID, Valid: true}, nil
+}
+
+// lookupProductModel finds a product model by normalised name, then by alias
+func lookupProductModel(ctx context.Context, q *db.Queries, key string) (db.ProductModel, bool, error) {
+	existing, err := q.GetProductModelByKey(ctx, key)
+	if err == nil {
+		return existing, true, nil
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return db.ProductModel{}, false, fmt.Errorf("failed to get product model: %w", err)
+	}
+
+	alias, err := q.GetProductModelAlias(ctx, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return db.ProductModel{}, false, nil
+	} else if err != nil {
+		return db.ProductModel{}, false, fmt.Errorf("failed to get product model alias: %w", err)
+	}
+
+	existing, err = q.GetProductModelByID(ctx, alias.ProductModelID)
+	if err != nil {
+		return db.ProductModel{}, false, fmt.Errorf("failed to get product model: %w", err)
+	}
+
+	return existing, true, nil
+}
+
+// productModelHashes returns the IDs of the documents stored for the product
+// model of a brand and model, by file hash. It is empty for unknown models.
+func productModelHashes(ctx context.Context, q *db.Queries, brand, model string) (map[string]int64, error) {
+	hashes := make(map[string]int64)
+
+	key := productModelKey(brand, model)
+	if key == "" {
+		return hashes, nil
+	}
+
+	existing, ok, err := lookupProductModel(ctx, q, key)
+	if err != nil || !ok {
+		return hashes, err
+	}
+
+	dbAssets, err := q.GetAssetsByProductModelID(ctx, sql.NullInt64{Int64: existing.ID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product model assets: %w", err)
+	}
+
+	for _, a := range dbAssets {
+		if a.FileHash != "" {
+			hashes[a.FileHash] = a.ID
+		}
+	}
+
+	return hashes, nil
+}
+
+// mergeProductModel moves the items, documentation, aliases and compatible
+// parts of a product model to another one and deletes it. Must be called
+// within a transaction.
+func mergeProductModel(ctx context.Context, q *db.Queries, source, target db.ProductModel) error {
+	sourceID := sql.NullInt64{Int64: source.ID, Valid: true}
+	targetID := sql.NullInt64{Int64: target.ID, Valid: true}
+
+	if err := q.ReassignProductModelItems(ctx, db.ReassignProductModelItemsParams{
+		NewProductModelID: targetID,
+		OldProductModelID: sourceID,
+	}); err != nil {
+		return fmt.Errorf("failed to move items: %w", err)
+	}
+
+	if err := q.MoveProductModelAssets(ctx, db.MoveProductModelAssetsParams{
+		NewProductModelID: targetID,
+		OldProductModelID: sourceID,
+	}); err != nil {
+		return fmt.Errorf("failed to move documentation: %w", err)
+	}
+
+	if err := q.MoveProductModelAliases(ctx, db.MoveProductModelAliasesParams{
+		NewProductModelID: target.ID,
+		OldProductModelID: source.ID,
+	}); err != nil {
+		return fmt.Errorf("failed to move aliases: %w", err)
+	}
+
+	if err := q.MovePartProductModels(ctx, db.MovePartProductModelsParams{
+		NewProductModelID: target.ID,
+		OldProductModelID: source.ID,
+	}); err != nil {
+		return fmt.Errorf("failed to move part compatibilities: %w", err)
+	}
+
+	if err := q.DeleteProductModel(ctx, source.ID); err != nil {
+		return fmt.Errorf("failed to delete product model: %w", err)
+	}
+
+	return nil
+}
+
+// touchProductModel bumps the modification time of a product model, so that
+// peers pick up the change
+func touchProductModel(ctx context.Context, q *db.Queries, id int64) error {
+	if err := q.TouchProductModel(ctx, db.TouchProductModelParams{
+		UpdatedAt: time.Now(),
+		ID:        id,
+	}); err != nil {
+		return fmt.Errorf("failed to update product model: %w", err)
+	}
+	return nil
+}
+
+// loadProductModelAliases returns the aliases of every product model, by ID
+func loadProductModelAliases(ctx context.Context, q *db.Queries) (map[int64][]models.ProductModelAlias, error) {
+	dbAliases, err := q.GetAllProductModelAliases(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product model aliases: %w", err)
+	}
+
+	byModel := make(map[int64][]models.ProductModelAlias)
+	for _, a := range dbAliases {
+		byModel[a.ProductModelID] = append(byModel[a.ProductModelID], models.ProductModelAlias{
+			Brand: a.Brand,
+			Model: a.Model,
+		})
+	}
+
+	for _, aliases := range byModel {
+		sort.Slice(aliases, func(i, j int) bool {
+			return aliases[i].Brand+" "+aliases[i].Model < aliases[j].Brand+" "+aliases[j].Model
+		})
+	}
+
+	return byModel, nil
+}
+
+// productModelKey normalises a brand and model so that "Brandt WTC 1234" and
+// "brandt wtc-1234" match. It is empty unless both are set.
+func productModelKey(brand, model string) string {
+	brand, model = foldProductName(brand), foldProductName(model)
+	if brand == "" || model == "" {
+		return ""
+	}
+	return brand + "/" + model
+}
+
+// foldProductName lowercases a name, strips its accents and drops everything
+// but letters and digits
+func foldProductName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, accentFolder.Replace(strings.ToLower(name)))
+}
+
+// nullInt64Ptr converts an optional SQL integer to a pointer
+func nullInt64Ptr(n sql.NullInt64) *int64 {
+	if !n.Valid {
+		return nil
+	}
+	return &n.Int64
+}
+
+// dbProductModelToModel converts a DB product model to a model product model
+func dbProductModelToModel(m db.ProductModel, aliases []models.ProductModelAlias) models.ProductModel {
+	productModel := models.ProductModel{
+		ID:        m.ID,
+		Brand:     m.Brand,
+		Model:     m.Model,
+		Aliases:   aliases,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+
+	if productModel.Aliases == nil {
+		productModel.Aliases = []models.ProductModelAlias{}
+	}
+
+	return productModel
+}
@@ -0,0 +1,357 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lhommenul/brique/core/db"
+	"github.com/lhommenul/brique/core/models"
+)
+
+// AddRepairEvent records a repair in the history of an item, with the assets
+// documenting it. The item is marked as modified so that the event reaches
+// the peers on the next sync.
+func (s *BackpackService) AddRepairEvent(ctx context.Context, event *models.RepairEvent) error {
+	if err := normalizeRepairEvent(event); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if event.PerformedAt.IsZero() {
+		event.PerformedAt = now
+	}
+
+	var created db.RepairEvent
+	err := s.inTx(ctx, func(tx *BackpackService) error {
+		if _, err := tx.queries.GetItemByID(ctx, event.ItemID); err != nil {
+			return fmt.Errorf("item not found: %w", err)
+		}
+
+		var err error
+		created, err = tx.queries.CreateRepairEvent(ctx, db.CreateRepairEventParams{
+			UID:           uuid.New().String(),
+			ItemID:        event.ItemID,
+			PerformedAt:   event.PerformedAt,
+			Diagnosis:     event.Diagnosis,
+			Action:        event.Action,
+			PartsReplaced: event.PartsReplaced,
+			CostCents:     event.CostCents,
+			PerformedBy:   event.PerformedBy,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create repair event: %w", err)
+		}
+
+		if err := linkRepairAssets(ctx, tx.queries, created, event.AssetIDs); err != nil {
+			return err
+		}
+
+		return touchItem(ctx, tx.queries, event.ItemID, now)
+	})
+	if err != nil {
+		return err
+	}
+
+	assetIDs := event.AssetIDs
+	*event = dbRepairEventToModel(created)
+	event.AssetIDs = uniqueIDs(assetIDs)
+
+	return nil
+}
+
+// GetRepairEvent returns a repair event with its assets
+func (s *BackpackService) GetRepairEvent(ctx context.Context, id int64) (*models.RepairEvent, error) {
+	dbEvent, err := s.queries.GetRepairEventByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repair event: %w", err)
+	}
+
+	links, err := loadRepairAssets(ctx, s.queries)
+	if err != nil {
+		return nil, err
+	}
+
+	event := dbRepairEventToModel(dbEvent)
+	event.AssetIDs = links[event.ID]
+	if event.AssetIDs == nil {
+		event.AssetIDs = []int64{}
+	}
+
+	return &event, nil
+}
+
+// GetItemRepairs returns the repair history of an item, most recent first
+func (s *BackpackService) GetItemRepairs(ctx context.Context, itemID int64) ([]models.RepairEvent, error) {
+	if _, err := s.queries.GetItemByID(ctx, itemID); err != nil {
+		return nil, fmt.Errorf("item not found: %w", err)
+	}
+
+	dbEvents, err := s.queries.GetRepairEventsByItemID(ctx, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repair events: %w", err)
+	}
+
+	links, err := loadRepairAssets(ctx, s.queries)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]models.RepairEvent, len(dbEvents))
+	for i, dbEvent := range dbEvents {
+		events[i] = dbRepairEventToModel(dbEvent)
+		events[i].AssetIDs = links[dbEvent.ID]
+		if events[i].AssetIDs == nil {
+			events[i].AssetIDs = []int64{}
+		}
+	}
+
+	return events, nil
+}
+
+// UpdateRepairEvent updates a repair event. Its assets are replaced unless
+// event.AssetIDs is nil.
+func (s *BackpackService) UpdateRepairEvent(ctx context.Context, event *models.RepairEvent) error {
+	if err := normalizeRepairEvent(event); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	return s.inTx(ctx, func(tx *BackpackService) error {
+		current, err := tx.queries.GetRepairEventByID(ctx, event.ID)
+		if err != nil {
+			return fmt.Errorf("repair event not found: %w", err)
+		}
+
+		if event.PerformedAt.IsZero() {
+			event.PerformedAt = current.PerformedAt
+		}
+
+		if err := tx.queries.UpdateRepairEvent(ctx, db.UpdateRepairEventParams{
+			PerformedAt:   event.PerformedAt,
+			Diagnosis:     event.Diagnosis,
+			Action:        event.Action,
+			PartsReplaced: event.PartsReplaced,
+			CostCents:     event.CostCents,
+			PerformedBy:   event.PerformedBy,
+			UpdatedAt:     now,
+			ID:            event.ID,
+		}); err != nil {
+			return fmt.Errorf("failed to update repair event: %w", err)
+		}
+
+		if event.AssetIDs != nil {
+			if err := tx.queries.DeleteRepairEventAssets(ctx, event.ID); err != nil {
+				return fmt.Errorf("failed to clear repair event assets: %w", err)
+			}
+			if err := linkRepairAssets(ctx, tx.queries, current, event.AssetIDs); err != nil {
+				return err
+			}
+			event.AssetIDs = uniqueIDs(event.AssetIDs)
+		}
+
+		event.UID = current.UID
+		event.ItemID = current.ItemID
+		event.CreatedAt = current.CreatedAt
+		event.UpdatedAt = now
+
+		return touchItem(ctx, tx.queries, current.ItemID, now)
+	})
+}
+
+// DeleteRepairEvent removes a repair event from the history of its item. The
+// documenting assets are kept.
+func (s *BackpackService) DeleteRepairEvent(ctx context.Context, id int64) error {
+	return s.inTx(ctx, func(tx *BackpackService) error {
+		current, err := tx.queries.GetRepairEventByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("repair event not found: %w", err)
+		}
+
+		if err := tx.queries.DeleteRepairEvent(ctx, id); err != nil {
+			return fmt.Errorf("failed to delete repair event: %w", err)
+		}
+
+		return touchItem(ctx, tx.queries, current.ItemID, time.Now())
+	})
+}
+
+// normalizeRepairEvent trims the text fields and checks that the event says
+// something
+func normalizeRepairEvent(event *models.RepairEvent) error {
+	event.Diagnosis = strings.TrimSpace(event.Diagnosis)
+	event.Action = strings.TrimSpace(event.Action)
+	event.PartsReplaced = strings.TrimSpace(event.PartsReplaced)
+	event.PerformedBy = strings.TrimSpace(event.PerformedBy)
+
+	if event.Diagnosis == "" && event.Action == "" {
+		return errors.New("a repair event needs a diagnosis or an action")
+	}
+	if event.CostCents < 0 {
+		return errors.New("repair cost can't be negative")
+	}
+
+	return nil
+}
+
+// linkRepairAssets attaches assets to a repair event. An asset must belong to
+// the repaired item or to its product model.
+func linkRepairAssets(ctx context.Context, q *db.Queries, event db.RepairEvent, assetIDs []int64) error {
+	if len(assetIDs) == 0 {
+		return nil
+	}
+
+	item, err := q.GetItemByID(ctx, event.ItemID)
+	if err != nil {
+		return fmt.Errorf("item not found: %w", err)
+	}
+
+	for _, assetID := range assetIDs {
+		asset, err := q.GetAssetByID(ctx, assetID)
+		if err != nil {
+			return fmt.Errorf("asset %d not found: %w", assetID, err)
+		}
+
+		ownedByItem := asset.ItemID.Valid && asset.ItemID.Int64 == item.ID
+		ownedByModel := asset.ProductModelID.Valid && item.ProductModelID.Valid && asset.ProductModelID.Int64 == item.ProductModelID.Int64
+		if !ownedByItem && !ownedByModel {
+			return fmt.Errorf("asset %d doesn't document item %d", assetID, item.ID)
+		}
+
+		if err := q.AddRepairEventAsset(ctx, db.AddRepairEventAssetParams{
+			RepairEventID: event.ID,
+			AssetID:       assetID,
+		}); err != nil {
+			return fmt.Errorf("failed to link asset to repair event: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// mergeRepairEvents applies the repair history received from a peer to a
+// local item. Events are matched by UID and the most recently updated version
+// wins. Asset links stay local.
+func mergeRepairEvents(ctx context.Context, q *db.Queries, itemID int64, events []models.RepairEvent) error {
+	for _, remote := range events {
+		if remote.UID == "" {
+			continue
+		}
+
+		local, err := q.GetRepairEventByUID(ctx, remote.UID)
+		if errors.Is(err, sql.ErrNoRows) {
+			if _, err := q.CreateRepairEvent(ctx, db.CreateRepairEventParams{
+				UID:           remote.UID,
+				ItemID:        itemID,
+				PerformedAt:   remote.PerformedAt,
+				Diagnosis:     remote.Diagnosis,
+				Action:        remote.Action,
+				PartsReplaced: remote.PartsReplaced,
+				CostCents:     remote.CostCents,
+				PerformedBy:   remote.PerformedBy,
+				CreatedAt:     remote.CreatedAt,
+				UpdatedAt:     remote.UpdatedAt,
+			}); err != nil {
+				return fmt.Errorf("failed to create repair event: %w", err)
+			}
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get repair event: %w", err)
+		}
+
+		if !remote.UpdatedAt.After(local.UpdatedAt) {
+			continue
+		}
+
+		if err := q.UpdateRepairEvent(ctx, db.UpdateRepairEventParams{
+			PerformedAt:   remote.PerformedAt,
+			Diagnosis:     remote.Diagnosis,
+			Action:        remote.Action,
+			PartsReplaced: remote.PartsReplaced,
+			CostCents:     remote.CostCents,
+			PerformedBy:   remote.PerformedBy,
+			UpdatedAt:     remote.UpdatedAt,
+			ID:            local.ID,
+		}); err != nil {
+			return fmt.Errorf("failed to update repair event: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// loadItemRepairs returns the repair history of every item, by item ID. Asset
+// links are left out.
+func loadItemRepairs(ctx context.Context, q *db.Queries) (map[int64][]models.RepairEvent, error) {
+	dbEvents, err := q.GetAllRepairEvents(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repair events: %w", err)
+	}
+
+	byItem := make(map[int64][]models.RepairEvent)
+	for _, dbEvent := range dbEvents {
+		byItem[dbEvent.ItemID] = append(byItem[dbEvent.ItemID], dbRepairEventToModel(dbEvent))
+	}
+
+	return byItem, nil
+}
+
+// loadRepairAssets returns the assets of every repair event, by event ID
+func loadRepairAssets(ctx context.Context, q *db.Queries) (map[int64][]int64, error) {
+	rows, err := q.GetAllRepairEventAssets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repair event assets: %w", err)
+	}
+
+	byEvent := make(map[int64][]int64)
+	for _, row := range rows {
+		byEvent[row.RepairEventID] = append(byEvent[row.RepairEventID], row.AssetID)
+	}
+
+	return byEvent, nil
+}
+
+// touchItem marks an item as modified, for its history to be synced
+func touchItem(ctx context.Context, q *db.Queries, itemID int64, now time.Time) error {
+	if err := q.TouchItem(ctx, db.TouchItemParams{UpdatedAt: now, ID: itemID}); err != nil {
+		return fmt.Errorf("failed to update item: %w", err)
+	}
+	return nil
+}
+
+// uniqueIDs returns the IDs without duplicates, in their original order
+func uniqueIDs(ids []int64) []int64 {
+	unique := make([]int64, 0, len(ids))
+	seen := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// dbRepairEventToModel converts a DB repair event to a model repair event
+func dbRepairEventToModel(dbEvent db.RepairEvent) models.RepairEvent {
+	return models.RepairEvent{
+		ID:            dbEvent.ID,
+		UID:           dbEvent.UID,
+		ItemID:        dbEvent.ItemID,
+		PerformedAt:   dbEvent.PerformedAt,
+		Diagnosis:     dbEvent.Diagnosis,
+		Action:        dbEvent.Action,
+		PartsReplaced: dbEvent.PartsReplaced,
+		CostCents:     dbEvent.CostCents,
+		PerformedBy:   dbEvent.PerformedBy,
+		CreatedAt:     dbEvent.CreatedAt,
+		UpdatedAt:     dbEvent.UpdatedAt,
+	}
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lhommenul/brique/core/db"
+)
+
+// GetTags returns every tag carried by at least one item
+func (s *BackpackService) GetTags(ctx context.Context) ([]string, error) {
+	dbTags, err := s.queries.GetAllTags(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+
+	tags := make([]string, len(dbTags))
+	for i, t := range dbTags {
+		tags[i] = t.Name
+	}
+
+	return tags, nil
+}
+
+// normalizeTags trims tags and removes blanks and case-insensitive duplicates
+func normalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+
+	for _, tag := range tags {
+		tag = strings.Join(strings.Fields(tag), " ")
+		if tag == "" || seen[strings.ToLower(tag)] {
+			continue
+		}
+		seen[strings.ToLower(tag)] = true
+		normalized = append(normalized, tag)
+	}
+
+	sort.Slice(normalized, func(i, j int) bool {
+		return strings.ToLower(normalized[i]) < strings.ToLower(normalized[j])
+	})
+
+	return normalized
+}
+
+// hasTags reports whether an item carries every wanted tag
+func hasTags(tags []string, wanted []string) bool {
+	for _, w := range wanted {
+		found := false
+		for _, tag := range tags {
+			if strings.EqualFold(tag, strings.TrimSpace(w)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// loadItemTags returns the tags of every item, by item ID
+func loadItemTags(ctx context.Context, q *db.Queries) (map[int64][]string, error) {
+	rows, err := q.GetAllItemTags(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get item tags: %w", err)
+	}
+
+	byItem := make(map[int64][]string)
+	for _, row := range rows {
+		byItem[row.ItemID] = append(byItem[row.ItemID], row.Name)
+	}
+
+	return byItem, nil
+}
+
+// replaceItemTags stores the tags of an item, replacing the previous ones, and
+// drops tags no item carries anymore. Must be called within a transaction.
+func replaceItemTags(ctx context.Context, q *db.Queries, itemID int64, tags []string) error {
+	if err := q.DeleteItemTags(ctx, itemID); err != nil {
+		return fmt.Errorf("failed to delete item tags: %w", err)
+	}
+
+	for _, name := range normalizeTags(tags) {
+		tag, err := q.UpsertTag(ctx, name)
+		if err != nil {
+			return fmt.Errorf("failed to save tag %s: %w", name, err)
+		}
+
+		if err := q.AddItemTag(ctx, db.AddItemTagParams{ItemID: itemID, TagID: tag.ID}); err != nil {
+			return fmt.Errorf("failed to tag item: %w", err)
+		}
+	}
+
+	if err := q.DeleteUnusedTags(ctx); err != nil {
+		return fmt.Errorf("failed to delete unused tags: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/png" // Register the PNG decoder for image.Decode
+	"io"
+)
+
+const (
+	// thumbnailSize bounds the width and height of generated thumbnails
+	thumbnailSize = 320
+	// thumbnailQuality is the JPEG quality of generated thumbnails
+	thumbnailQuality = 80
+	// thumbnailMaxPixels bounds the images decoded for a thumbnail, above
+	// camera photos: decoding takes up to 4 bytes per pixel, 200 MB here
+	thumbnailMaxPixels = 50_000_000
+)
+
+// generateThumbnail decodes a JPEG or PNG image and writes a downscaled JPEG
+// copy. Images larger than thumbnailMaxPixels are refused before decoding.
+func generateThumbnail(source io.Reader, dest io.Writer) error {
+	// The header read for the size is decoded again with the rest
+	var header bytes.Buffer
+	config, _, err := image.DecodeConfig(io.TeeReader(source, &header))
+	if err != nil {
+		return fmt.Errorf("failed to decode image: %w", err)
+	}
+	if int64(config.Width)*int64(config.Height) > thumbnailMaxPixels {
+		return fmt.Errorf("image too large: %dx%d pixels", config.Width, config.Height)
+	}
+
+	img, _, err := image.Decode(io.MultiReader(&header, source))
+	if err != nil {
+		return fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	if err := jpeg.Encode(dest, resizeToFit(img, thumbnailSize), &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+		return fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+
+	return nil
+}
+
+// resizeToFit downscales an image so that it fits in a size x size box,
+// averaging the source pixels covered by each destination pixel
+func resizeToFit(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	dstWidth, dstHeight := size, size
+	if width <= size && height <= size {
+		dstWidth, dstHeight = width, height
+	} else if width > height {
+		dstHeight = max(1, height*size/width)
+	} else {
+		dstWidth = max(1, width*size/height)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < dstHeight; y++ {
+		y0 := bounds.Min.Y + y*height/dstHeight
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*height/dstHeight)
+
+		for x := 0; x < dstWidth; x++ {
+			x0 := bounds.Min.X + x*width/dstWidth
+			x1 := max(x0+1, bounds.Min.X+(x+1)*width/dstWidth)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := img.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa)
+					n++
+				}
+			}
+
+			// JPEG has no alpha channel: flatten transparent pixels onto white
+			white := 0xffff*n - a
+			dst.Set(x, y, color.RGBA64{
+				R: uint16((r + white) / n),
+				G: uint16((g + white) / n),
+				B: uint16((b + white) / n),
+				A: 0xffff,
+			})
+		}
+	}
+
+	return dst
+}
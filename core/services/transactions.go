@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/lhommenul/brique/core/db"
+)
+
+// File outbox actions. Filesystem side effects are recorded in the same
+// transaction as the rows they belong to and applied once it is committed, so
+// that a crash never leaves rows pointing at missing files or the reverse.
+const (
+	outboxActionMove   = "move"   // Move a staged file to its final location
+	outboxActionDelete = "delete" // Delete a file that is no longer referenced
+)
+
+// inTx runs fn with a copy of the service bound to a transaction, then applies
+// the file outbox. Nested calls reuse the outer transaction.
+func (s *BackpackService) inTx(ctx context.Context, fn func(tx *BackpackService) error) error {
+	if s.staged != nil {
+		return fn(s)
+	}
+
+	staged := []string{}
+	err := s.database.WithTx(ctx, func(q *db.Queries) error {
+		tx := *s
+		tx.queries = q
+		tx.staged = &staged
+		return fn(&tx)
+	})
+	if err != nil {
+		// Staged files were never referenced by a committed row
+		for _, path := range staged {
+			os.Remove(path)
+		}
+		return err
+	}
+
+	s.flushOutbox(ctx)
+	return nil
+}
+
+// ProcessFileOutbox applies the file operations left over by an interrupted
+// run and removes staged files that no committed row refers to. It is meant
+// to be called at startup.
+func (s *BackpackService) ProcessFileOutbox(ctx context.Context) error {
+	s.flushOutbox(ctx)
+
+	entries, err := s.queries.GetFileOutboxEntries(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get file outbox: %w", err)
+	}
+
+	pending := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		pending[entry.Path] = true
+	}
+
+	files, err := os.ReadDir(s.stagingDir())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read staging directory: %w", err)
+	}
+
+	for _, file := range files {
+		path := filepath.Join(s.stagingDir(), file.Name())
+		if !pending[path] {
+			if err := os.Remove(path); err != nil {
+				s.logger.Warn("Failed to delete staged file", "path", path, "error", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// queueFileMove records that a staged file must be moved to its final path
+func (s *BackpackService) queueFileMove(ctx context.Context, stagedPath, targetPath string) error {
+	return s.queueFileOperation(ctx, outboxActionMove, stagedPath, targetPath)
+}
+
+// queueFileDelete records that a file must be deleted
+func (s *BackpackService) queueFileDelete(ctx context.Context, path string) error {
+	return s.queueFileOperation(ctx, outboxActionDelete, path, "")
+}
+
+func (s *BackpackService) queueFileOperation(ctx context.Context, action, path, targetPath string) error {
+	if err := s.queries.CreateFileOutboxEntry(ctx, db.CreateFileOutboxEntryParams{
+		Action:     action,
+		Path:       path,
+		TargetPath: targetPath,
+		CreatedAt:  time.Now(),
+	}); err != nil {
+		return fmt.Errorf("failed to queue file %s: %w", action, err)
+	}
+	return nil
+}
+
+// flushOutbox applies the pending file operations. Failed operations stay in
+// the outbox and are retried by the next flush.
+func (s *BackpackService) flushOutbox(ctx context.Context) {
+	entries, err := s.queries.GetFileOutboxEntries(ctx)
+	if err != nil {
+		s.logger.Warn("Failed to get file outbox", "error", err)
+		return
+	}
+
+	for _, entry := range entries {
+		if err := applyFileOperation(entry); err != nil {
+			s.logger.Warn("Failed to apply file operation", "action", entry.Action, "path", entry.Path, "error", err)
+			continue
+		}
+
+		if err := s.queries.DeleteFileOutboxEntry(ctx, entry.ID); err != nil {
+			s.logger.Warn("Failed to clear file outbox entry", "id", entry.ID, "error", err)
+		}
+	}
+}
+
+// applyFileOperation performs an outbox entry. Operations are idempotent so
+// that an entry applied just before a crash can safely be replayed.
+func applyFileOperation(entry db.FileOutbox) error {
+	switch entry.Action {
+	case outboxActionMove:
+		if err := os.MkdirAll(filepath.Dir(entry.TargetPath), 0755); err != nil {
+			return err
+		}
+		err := os.Rename(entry.Path, entry.TargetPath)
+		if errors.Is(err, os.ErrNotExist) {
+			if _, statErr := os.Stat(entry.TargetPath); statErr == nil {
+				return nil // Already moved
+			}
+		}
+		return err
+
+	case outboxActionDelete:
+		if err := os.Remove(entry.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown file outbox action: %s", entry.Action)
+	}
+}
+
+// stagingDir holds asset files copied before their transaction commits
+func (s *BackpackService) stagingDir() string {
+	return filepath.Join(s.assetsDir, "staging")
+}
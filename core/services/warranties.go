@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lhommenul/brique/core/db"
+	"github.com/lhommenul/brique/core/models"
+)
+
+// SetItemWarranty sets the warranty of an item, or removes it when warranty
+// is nil or has no duration
+func (s *BackpackService) SetItemWarranty(ctx context.Context, itemID int64, warranty *models.Warranty) error {
+	var purchaseDate *time.Time
+	err := s.inTx(ctx, func(tx *BackpackService) error {
+		item, err := tx.queries.GetItemByID(ctx, itemID)
+		if err != nil {
+			return fmt.Errorf("item not found: %w", err)
+		}
+		if item.PurchaseDate.Valid {
+			purchaseDate = &item.PurchaseDate.Time
+		}
+
+		if warranty == nil {
+			warranty = &models.Warranty{}
+		}
+		return replaceItemWarranty(ctx, tx.queries, itemID, warranty)
+	})
+	if err != nil {
+		return err
+	}
+
+	computeWarranty(warranty, purchaseDate, time.Now())
+	return nil
+}
+
+// GetExpiringWarranties returns the items whose warranty runs out within the
+// given duration, soonest first. Expired warranties and items without a
+// purchase date are left out.
+func (s *BackpackService) GetExpiringWarranties(ctx context.Context, within time.Duration) ([]models.Item, error) {
+	warranties, err := s.queries.GetAllItemWarranties(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get warranties: %w", err)
+	}
+
+	now := time.Now()
+	horizon := now.Add(within)
+
+	items := []models.Item{}
+	for _, dbWarranty := range warranties {
+		dbItem, err := s.queries.GetItemByID(ctx, dbWarranty.ItemID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get item: %w", err)
+		}
+
+		item, err := s.dbItemToModel(dbItem)
+		if err != nil {
+			return nil, err
+		}
+		item.Warranty = dbWarrantyToModel(dbWarranty)
+		computeWarranty(item.Warranty, item.PurchaseDate, now)
+
+		expiresAt := item.Warranty.ExpiresAt
+		if expiresAt == nil || expiresAt.Before(now) || expiresAt.After(horizon) {
+			continue
+		}
+		items = append(items, *item)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Warranty.ExpiresAt.Before(*items[j].Warranty.ExpiresAt)
+	})
+
+	return items, nil
+}
+
+// replaceItemWarranty stores the warranty of an item, removing it when it has
+// no duration. The receipt must be one of the item's assets.
+func replaceItemWarranty(ctx context.Context, q *db.Queries, itemID int64, warranty *models.Warranty) error {
+	warranty.Provider = strings.TrimSpace(warranty.Provider)
+
+	if warranty.DurationMonths < 0 {
+		return errors.New("warranty duration can't be negative")
+	}
+	if warranty.DurationMonths == 0 {
+		if err := q.DeleteItemWarranty(ctx, itemID); err != nil {
+			return fmt.Errorf("failed to remove warranty: %w", err)
+		}
+		return nil
+	}
+
+	var receiptAssetID sql.NullInt64
+	if warranty.ReceiptAssetID != nil {
+		asset, err := q.GetAssetByID(ctx, *warranty.ReceiptAssetID)
+		if err != nil {
+			return fmt.Errorf("asset %d not found: %w", *warranty.ReceiptAssetID, err)
+		}
+		if !asset.ItemID.Valid || asset.ItemID.Int64 != itemID {
+			return fmt.Errorf("asset %d doesn't belong to item %d", asset.ID, itemID)
+		}
+		receiptAssetID = sql.NullInt64{Int64: asset.ID, Valid: true}
+	}
+
+	if err := q.SetItemWarranty(ctx, db.SetItemWarrantyParams{
+		ItemID:         itemID,
+		DurationMonths: warranty.DurationMonths,
+		Provider:       warranty.Provider,
+		ReceiptAssetID: receiptAssetID,
+		UpdatedAt:      time.Now(),
+	}); err != nil {
+		return fmt.Errorf("failed to set warranty: %w", err)
+	}
+
+	return nil
+}
+
+// loadItemWarranty returns the warranty of an item, or nil without one
+func loadItemWarranty(ctx context.Context, q *db.Queries, item *models.Item) (*models.Warranty, error) {
+	dbWarranty, err := q.GetItemWarranty(ctx, item.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get warranty: %w", err)
+	}
+
+	warranty := dbWarrantyToModel(dbWarranty)
+	computeWarranty(warranty, item.PurchaseDate, time.Now())
+
+	return warranty, nil
+}
+
+// computeWarranty sets the expiry date and the days left of a warranty, when
+// the purchase date is known
+func computeWarranty(warranty *models.Warranty, purchaseDate *time.Time, now time.Time) {
+	warranty.ExpiresAt = nil
+	warranty.DaysLeft = nil
+	if warranty.DurationMonths == 0 || purchaseDate == nil {
+		return
+	}
+
+	expiresAt := purchaseDate.AddDate(0, int(warranty.DurationMonths), 0)
+	daysLeft := int64(math.Floor(expiresAt.Sub(now).Hours() / 24))
+	warranty.ExpiresAt = &expiresAt
+	warranty.DaysLeft = &daysLeft
+}
+
+// dbWarrantyToModel converts a DB item warranty to a model warranty
+func dbWarrantyToModel(dbWarranty db.ItemWarranty) *models.Warranty {
+	return &models.Warranty{
+		DurationMonths: dbWarranty.DurationMonths,
+		Provider:       dbWarranty.Provider,
+		ReceiptAssetID: nullInt64Ptr(dbWarranty.ReceiptAssetID),
+	}
+}
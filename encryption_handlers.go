@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lhommenul/brique/core/services"
+)
+
+// EncryptionStatusDTO is the Data Transfer Object for the encryption at rest
+type EncryptionStatusDTO struct {
+	Enabled  bool    `json:"enabled"`
+	Unlocked bool    `json:"unlocked"`
+	KeySince *string `json:"keySince"`
+}
+
+// GetEncryptionStatus tells whether the data is encrypted at rest, and
+// whether the passphrase must be asked for
+func (a *App) GetEncryptionStatus() (*EncryptionStatusDTO, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	status, err := a.backpackService.EncryptionStatus(a.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	dto := &EncryptionStatusDTO{Enabled: status.Enabled, Unlocked: status.Unlocked}
+	if status.KeySince != nil {
+		keySince := status.KeySince.Format("2006-01-02")
+		dto.KeySince = &keySince
+	}
+	return dto, nil
+}
+
+// UnlockEncryption unlocks the data encrypted at rest with the passphrase
+func (a *App) UnlockEncryption(passphrase string) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if err := a.backpackService.UnlockEncryption(a.ctx, passphrase); err != nil {
+		if errors.Is(err, services.ErrWrongPassphrase) {
+			a.events.Error("Déverrouillage impossible", "Phrase de passe incorrecte")
+		} else {
+			a.events.Error("Déverrouillage impossible", err.Error())
+		}
+		return err
+	}
+
+	a.events.Success("Données déverrouillées", "Les données chiffrées sont accessibles")
+	return nil
+}
+
+// unlockEncryptionAtStartup unlocks the data encrypted at rest with the
+// passphrase of the configuration, if any
+func (a *App) unlockEncryptionAtStartup(ctx context.Context) {
+	status, err := a.backpackService.EncryptionStatus(ctx)
+	if err != nil {
+		a.logger.Warn("Failed to get encryption status", "error", err)
+		return
+	}
+	if !status.Enabled {
+		return
+	}
+
+	passphrase, err := a.cfg.Passphrase()
+	if err != nil {
+		a.logger.Warn("Failed to read passphrase", "error", err)
+	}
+	if passphrase != "" {
+		err := a.backpackService.UnlockEncryption(ctx, passphrase)
+		if err == nil {
+			return
+		}
+		a.logger.Warn("Failed to unlock encrypted data", "error", err)
+	}
+
+	a.events.Warning("Données chiffrées", "Saisissez la phrase de passe pour accéder aux numéros de série, notes et documents")
+}
@@ -6,7 +6,8 @@ import (
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
-// EventEmitter provides methods to emit events to the frontend
+// EventEmitter provides methods to emit events to the frontend. A nil
+// EventEmitter emits nothing, e.g. when there is no frontend in tests.
 type EventEmitter struct {
 	ctx context.Context
 }
@@ -35,19 +36,37 @@ type ProgressData struct {
 
 // EmitNotification sends a notification to the frontend
 func (e *EventEmitter) EmitNotification(notif NotificationData) {
+	if e == nil {
+		return
+	}
 	runtime.EventsEmit(e.ctx, "notification", notif)
 }
 
 // EmitProgress sends a progress update to the frontend
 func (e *EventEmitter) EmitProgress(progress ProgressData) {
+	if e == nil {
+		return
+	}
 	runtime.EventsEmit(e.ctx, "progress", progress)
 }
 
 // EmitProgressComplete signals that an operation is complete
 func (e *EventEmitter) EmitProgressComplete(id string) {
+	if e == nil {
+		return
+	}
 	runtime.EventsEmit(e.ctx, "progress:complete", map[string]string{"id": id})
 }
 
+// EmitProfileChanged signals that another profile is open, for the
+// frontend to load its inventory
+func (e *EventEmitter) EmitProfileChanged(profile string) {
+	if e == nil {
+		return
+	}
+	runtime.EventsEmit(e.ctx, "profile:changed", map[string]string{"profile": profile})
+}
+
 // Convenience methods for common notification types
 func (e *EventEmitter) Success(title, message string) {
 	e.EmitNotification(NotificationData{
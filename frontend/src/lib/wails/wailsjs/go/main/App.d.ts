@@ -6,48 +6,126 @@ import {models} from '../models';
 
 export function AddAsset(arg1:number,arg2:string,arg3:string,arg4:string):Promise<main.AssetDTO>;
 
+export function AddAssetRevision(arg1:number,arg2:string,arg3:string,arg4:string,arg5:string):Promise<main.AssetDTO>;
+
+export function AddMaintenanceSchedule(arg1:number,arg2:string,arg3:number,arg4:number,arg5:string):Promise<void>;
+
 export function AddPeer(arg1:string,arg2:string,arg3:boolean):Promise<void>;
 
+export function AddPhoto(arg1:number):Promise<main.AssetDTO>;
+
+export function AddProductModelAlias(arg1:number,arg2:string,arg3:string):Promise<void>;
+
+export function CompleteMaintenance(arg1:number):Promise<main.MaintenanceTaskDTO>;
+
 export function CreateBackup():Promise<void>;
 
 export function CreateItem(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string):Promise<main.ItemDTO>;
 
+export function CreateProfile(arg1:string):Promise<void>;
+
 export function DeleteAsset(arg1:number):Promise<void>;
 
+export function DeleteAssetType(arg1:string):Promise<void>;
+
 export function DeleteItem(arg1:number):Promise<void>;
 
 export function ExportToCSV():Promise<void>;
 
 export function ExportToJSON():Promise<void>;
 
+export function FetchFromPeer(arg1:string,arg2:number):Promise<main.ItemWithAssetsDTO>;
+
 export function GenerateQRCode(arg1:number):Promise<string>;
 
 export function GetAllItems():Promise<Array<main.ItemDTO>>;
 
+export function GetAnnouncements(arg1:string):Promise<Array<main.AnnouncementDTO>>;
+
+export function GetAssetHistory(arg1:number):Promise<Array<main.AssetDTO>>;
+
+export function GetAssetTypes():Promise<Array<main.AssetTypeDTO>>;
+
 export function GetAssets(arg1:number):Promise<Array<main.AssetDTO>>;
 
+export function GetAttributeTemplate(arg1:string):Promise<Array<main.AttributeDefinitionDTO>>;
+
+export function GetCategories():Promise<Array<main.CategoryDTO>>;
+
+export function GetDueMaintenance(arg1:number):Promise<Array<main.MaintenanceTaskDTO>>;
+
+export function GetEncryptionStatus():Promise<main.EncryptionStatusDTO>;
+
+export function GetExpiringWarranties(arg1:number):Promise<Array<main.ItemDTO>>;
+
 export function GetGossipChanges(arg1:time.Time):Promise<Array<main.ItemDTO>>;
 
 export function GetGossipInfo():Promise<main.GossipInfoResponse>;
 
 export function GetItem(arg1:number):Promise<main.ItemDTO>;
 
+export function GetItemMaintenance(arg1:number):Promise<Array<main.MaintenanceTaskDTO>>;
+
+export function GetItemPhotos(arg1:number):Promise<Array<main.AssetDTO>>;
+
 export function GetItemWithAssets(arg1:number):Promise<main.ItemWithAssetsDTO>;
 
+export function GetLoans(arg1:boolean):Promise<Array<main.LoanDTO>>;
+
+export function GetOverdueLoans():Promise<Array<main.LoanDTO>>;
+
 export function GetPeers():Promise<Array<main.PeerDTO>>;
 
+export function GetProductModels():Promise<Array<main.ProductModelDTO>>;
+
+export function GetProfiles():Promise<Array<main.ProfileDTO>>;
+
 export function GetSyncHistory(arg1:number):Promise<Array<main.SyncLogDTO>>;
 
+export function GetTags():Promise<Array<string>>;
+
+export function ImportFromCSV(arg1:string,arg2:Record<string, string>,arg3:boolean):Promise<main.ImportReportDTO>;
+
 export function ImportFromJSON():Promise<void>;
 
+export function LendItem(arg1:number,arg2:string,arg3:string,arg4:string,arg5:string):Promise<main.LoanDTO>;
+
+export function PublishAnnouncement(arg1:string,arg2:string,arg3:string,arg4:number,arg5:number):Promise<main.AnnouncementDTO>;
+
+export function RecordUsage(arg1:number,arg2:string,arg3:number):Promise<void>;
+
 export function RemovePeer(arg1:string):Promise<void>;
 
+export function ReturnLoan(arg1:number):Promise<main.LoanDTO>;
+
+export function SaveAssetType(arg1:string,arg2:string,arg3:Array<string>,arg4:boolean):Promise<void>;
+
 export function SearchItems(arg1:string):Promise<Array<main.ItemDTO>>;
 
+export function SearchPeers(arg1:string,arg2:string,arg3:string):Promise<Array<main.SearchResultDTO>>;
+
+export function SelectCSVFile():Promise<string>;
+
+export function SetCurrentRevision(arg1:number):Promise<void>;
+
+export function SetItemAttributes(arg1:number,arg2:Array<main.AttributeDTO>):Promise<void>;
+
+export function SetItemTags(arg1:number,arg2:Array<string>):Promise<void>;
+
+export function SetItemWarranty(arg1:number,arg2:number,arg3:string,arg4:number):Promise<main.WarrantyDTO>;
+
 export function SetPeerTrusted(arg1:string,arg2:boolean):Promise<void>;
 
+export function ShareAsset(arg1:number):Promise<main.AssetDTO>;
+
+export function SwitchProfile(arg1:string):Promise<void>;
+
 export function SyncWithPeer(arg1:string):Promise<main.SyncResultDTO>;
 
 export function SyncWithPeerHTTP(arg1:string):Promise<models.SyncResult>;
 
+export function UnlockEncryption(arg1:string):Promise<void>;
+
 export function UpdateItem(arg1:number,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string,arg7:string):Promise<void>;
+
+export function WithdrawAnnouncement(arg1:number):Promise<void>;
@@ -6,10 +6,30 @@ export function AddAsset(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['AddAsset'](arg1, arg2, arg3, arg4);
 }
 
+export function AddAssetRevision(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['AddAssetRevision'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function AddMaintenanceSchedule(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['AddMaintenanceSchedule'](arg1, arg2, arg3, arg4, arg5);
+}
+
 export function AddPeer(arg1, arg2, arg3) {
   return window['go']['main']['App']['AddPeer'](arg1, arg2, arg3);
 }
 
+export function AddPhoto(arg1) {
+  return window['go']['main']['App']['AddPhoto'](arg1);
+}
+
+export function AddProductModelAlias(arg1, arg2, arg3) {
+  return window['go']['main']['App']['AddProductModelAlias'](arg1, arg2, arg3);
+}
+
+export function CompleteMaintenance(arg1) {
+  return window['go']['main']['App']['CompleteMaintenance'](arg1);
+}
+
 export function CreateBackup() {
   return window['go']['main']['App']['CreateBackup']();
 }
@@ -18,10 +38,18 @@ export function CreateItem(arg1, arg2, arg3, arg4, arg5, arg6) {
   return window['go']['main']['App']['CreateItem'](arg1, arg2, arg3, arg4, arg5, arg6);
 }
 
+export function CreateProfile(arg1) {
+  return window['go']['main']['App']['CreateProfile'](arg1);
+}
+
 export function DeleteAsset(arg1) {
   return window['go']['main']['App']['DeleteAsset'](arg1);
 }
 
+export function DeleteAssetType(arg1) {
+  return window['go']['main']['App']['DeleteAssetType'](arg1);
+}
+
 export function DeleteItem(arg1) {
   return window['go']['main']['App']['DeleteItem'](arg1);
 }
@@ -34,6 +62,10 @@ export function ExportToJSON() {
   return window['go']['main']['App']['ExportToJSON']();
 }
 
+export function FetchFromPeer(arg1, arg2) {
+  return window['go']['main']['App']['FetchFromPeer'](arg1, arg2);
+}
+
 export function GenerateQRCode(arg1) {
   return window['go']['main']['App']['GenerateQRCode'](arg1);
 }
@@ -42,10 +74,42 @@ export function GetAllItems() {
   return window['go']['main']['App']['GetAllItems']();
 }
 
+export function GetAnnouncements(arg1) {
+  return window['go']['main']['App']['GetAnnouncements'](arg1);
+}
+
+export function GetAssetHistory(arg1) {
+  return window['go']['main']['App']['GetAssetHistory'](arg1);
+}
+
+export function GetAssetTypes() {
+  return window['go']['main']['App']['GetAssetTypes']();
+}
+
 export function GetAssets(arg1) {
   return window['go']['main']['App']['GetAssets'](arg1);
 }
 
+export function GetAttributeTemplate(arg1) {
+  return window['go']['main']['App']['GetAttributeTemplate'](arg1);
+}
+
+export function GetCategories() {
+  return window['go']['main']['App']['GetCategories']();
+}
+
+export function GetDueMaintenance(arg1) {
+  return window['go']['main']['App']['GetDueMaintenance'](arg1);
+}
+
+export function GetEncryptionStatus() {
+  return window['go']['main']['App']['GetEncryptionStatus']();
+}
+
+export function GetExpiringWarranties(arg1) {
+  return window['go']['main']['App']['GetExpiringWarranties'](arg1);
+}
+
 export function GetGossipChanges(arg1) {
   return window['go']['main']['App']['GetGossipChanges'](arg1);
 }
@@ -58,34 +122,118 @@ export function GetItem(arg1) {
   return window['go']['main']['App']['GetItem'](arg1);
 }
 
+export function GetItemMaintenance(arg1) {
+  return window['go']['main']['App']['GetItemMaintenance'](arg1);
+}
+
+export function GetItemPhotos(arg1) {
+  return window['go']['main']['App']['GetItemPhotos'](arg1);
+}
+
 export function GetItemWithAssets(arg1) {
   return window['go']['main']['App']['GetItemWithAssets'](arg1);
 }
 
+export function GetLoans(arg1) {
+  return window['go']['main']['App']['GetLoans'](arg1);
+}
+
+export function GetOverdueLoans() {
+  return window['go']['main']['App']['GetOverdueLoans']();
+}
+
 export function GetPeers() {
   return window['go']['main']['App']['GetPeers']();
 }
 
+export function GetProductModels() {
+  return window['go']['main']['App']['GetProductModels']();
+}
+
+export function GetProfiles() {
+  return window['go']['main']['App']['GetProfiles']();
+}
+
 export function GetSyncHistory(arg1) {
   return window['go']['main']['App']['GetSyncHistory'](arg1);
 }
 
+export function GetTags() {
+  return window['go']['main']['App']['GetTags']();
+}
+
+export function ImportFromCSV(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ImportFromCSV'](arg1, arg2, arg3);
+}
+
 export function ImportFromJSON() {
   return window['go']['main']['App']['ImportFromJSON']();
 }
 
+export function LendItem(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['LendItem'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function PublishAnnouncement(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['PublishAnnouncement'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function RecordUsage(arg1, arg2, arg3) {
+  return window['go']['main']['App']['RecordUsage'](arg1, arg2, arg3);
+}
+
 export function RemovePeer(arg1) {
   return window['go']['main']['App']['RemovePeer'](arg1);
 }
 
+export function ReturnLoan(arg1) {
+  return window['go']['main']['App']['ReturnLoan'](arg1);
+}
+
+export function SaveAssetType(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['SaveAssetType'](arg1, arg2, arg3, arg4);
+}
+
 export function SearchItems(arg1) {
   return window['go']['main']['App']['SearchItems'](arg1);
 }
 
+export function SearchPeers(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SearchPeers'](arg1, arg2, arg3);
+}
+
+export function SelectCSVFile() {
+  return window['go']['main']['App']['SelectCSVFile']();
+}
+
+export function SetCurrentRevision(arg1) {
+  return window['go']['main']['App']['SetCurrentRevision'](arg1);
+}
+
+export function SetItemAttributes(arg1, arg2) {
+  return window['go']['main']['App']['SetItemAttributes'](arg1, arg2);
+}
+
+export function SetItemTags(arg1, arg2) {
+  return window['go']['main']['App']['SetItemTags'](arg1, arg2);
+}
+
+export function SetItemWarranty(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['SetItemWarranty'](arg1, arg2, arg3, arg4);
+}
+
 export function SetPeerTrusted(arg1, arg2) {
   return window['go']['main']['App']['SetPeerTrusted'](arg1, arg2);
 }
 
+export function ShareAsset(arg1) {
+  return window['go']['main']['App']['ShareAsset'](arg1);
+}
+
+export function SwitchProfile(arg1) {
+  return window['go']['main']['App']['SwitchProfile'](arg1);
+}
+
 export function SyncWithPeer(arg1) {
   return window['go']['main']['App']['SyncWithPeer'](arg1);
 }
@@ -94,6 +242,14 @@ export function SyncWithPeerHTTP(arg1) {
   return window['go']['main']['App']['SyncWithPeerHTTP'](arg1);
 }
 
+export function UnlockEncryption(arg1) {
+  return window['go']['main']['App']['UnlockEncryption'](arg1);
+}
+
 export function UpdateItem(arg1, arg2, arg3, arg4, arg5, arg6, arg7) {
   return window['go']['main']['App']['UpdateItem'](arg1, arg2, arg3, arg4, arg5, arg6, arg7);
 }
+
+export function WithdrawAnnouncement(arg1) {
+  return window['go']['main']['App']['WithdrawAnnouncement'](arg1);
+}
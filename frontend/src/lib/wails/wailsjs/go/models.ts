@@ -1,5 +1,38 @@
 export namespace main {
 	
+	export class AnnouncementDTO {
+	    id: number;
+	    kind: string;
+	    title: string;
+	    body: string;
+	    authorName: string;
+	    hops: number;
+	    maxHops: number;
+	    receivedFrom: string;
+	    own: boolean;
+	    createdAt: string;
+	    expiresAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AnnouncementDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.kind = source["kind"];
+	        this.title = source["title"];
+	        this.body = source["body"];
+	        this.authorName = source["authorName"];
+	        this.hops = source["hops"];
+	        this.maxHops = source["maxHops"];
+	        this.receivedFrom = source["receivedFrom"];
+	        this.own = source["own"];
+	        this.createdAt = source["createdAt"];
+	        this.expiresAt = source["expiresAt"];
+	    }
+	}
+
 	export class AssetDTO {
 	    id: number;
 	    itemId: number;
@@ -9,6 +42,12 @@ export namespace main {
 	    fileSize: number;
 	    fileHash: string;
 	    createdAt: string;
+	    versionLabel: string;
+	    releaseDate?: string;
+	    supersedesId?: number;
+	    supersededById?: number;
+	    isCurrent: boolean;
+	    productModelId?: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new AssetDTO(source);
@@ -24,11 +63,110 @@ export namespace main {
 	        this.fileSize = source["fileSize"];
 	        this.fileHash = source["fileHash"];
 	        this.createdAt = source["createdAt"];
+	        this.versionLabel = source["versionLabel"];
+	        this.releaseDate = source["releaseDate"];
+	        this.supersedesId = source["supersedesId"];
+	        this.supersededById = source["supersededById"];
+	        this.isCurrent = source["isCurrent"];
+	        this.productModelId = source["productModelId"];
+	    }
+	}
+
+	export class AssetTypeDTO {
+	    name: string;
+	    label: string;
+	    mimePatterns: string[];
+	    countsForHealth: boolean;
+	    isBuiltin: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new AssetTypeDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.label = source["label"];
+	        this.mimePatterns = source["mimePatterns"];
+	        this.countsForHealth = source["countsForHealth"];
+	        this.isBuiltin = source["isBuiltin"];
+	    }
+	}
+
+	export class AttributeDTO {
+	    key: string;
+	    type: string;
+	    value: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AttributeDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.type = source["type"];
+	        this.value = source["value"];
+	    }
+	}
+
+	export class AttributeDefinitionDTO {
+	    key: string;
+	    label: string;
+	    type: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AttributeDefinitionDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.label = source["label"];
+	        this.type = source["type"];
+	    }
+	}
+
+	export class CategoryDTO {
+	    id: number;
+	    name: string;
+	    parentId?: number;
+	    synonyms: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new CategoryDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.parentId = source["parentId"];
+	        this.synonyms = source["synonyms"];
+	    }
+	}
+
+	export class EncryptionStatusDTO {
+	    enabled: boolean;
+	    unlocked: boolean;
+	    keySince?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new EncryptionStatusDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.unlocked = source["unlocked"];
+	        this.keySince = source["keySince"];
 	    }
 	}
+
 	export class GossipInfoResponse {
 	    instance_id: string;
 	    instance_name: string;
+	    public_key: string;
 	    last_sync?: time.Time;
 	    item_count: number;
 	
@@ -40,6 +178,7 @@ export namespace main {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.instance_id = source["instance_id"];
 	        this.instance_name = source["instance_name"];
+	        this.public_key = source["public_key"];
 	        this.last_sync = this.convertValues(source["last_sync"], time.Time);
 	        this.item_count = source["item_count"];
 	    }
@@ -62,6 +201,89 @@ export namespace main {
 		    return a;
 		}
 	}
+
+	export class ImportReportDTO {
+	    dryRun: boolean;
+	    columns: Record<string, string>;
+	    created: number;
+	    duplicates: number;
+	    invalid: number;
+	    rows: ImportRowDTO[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportReportDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.dryRun = source["dryRun"];
+	        this.columns = source["columns"];
+	        this.created = source["created"];
+	        this.duplicates = source["duplicates"];
+	        this.invalid = source["invalid"];
+	        this.rows = this.convertValues(source["rows"], ImportRowDTO);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+	export class ImportRowDTO {
+	    line: number;
+	    action: string;
+	    item: ItemDTO;
+	    duplicateOf?: number;
+	    duplicateLine: number;
+	    errors: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportRowDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.line = source["line"];
+	        this.action = source["action"];
+	        this.item = this.convertValues(source["item"], ItemDTO);
+	        this.duplicateOf = source["duplicateOf"];
+	        this.duplicateLine = source["duplicateLine"];
+	        this.errors = source["errors"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
 	export class ItemDTO {
 	    id: number;
 	    name: string;
@@ -74,6 +296,11 @@ export namespace main {
 	    notes: string;
 	    createdAt: string;
 	    updatedAt: string;
+	    attributes: AttributeDTO[];
+	    tags: string[];
+	    productModelId?: number;
+	    warranty?: WarrantyDTO;
+	    loan?: LoanDTO;
 	
 	    static createFrom(source: any = {}) {
 	        return new ItemDTO(source);
@@ -92,8 +319,32 @@ export namespace main {
 	        this.notes = source["notes"];
 	        this.createdAt = source["createdAt"];
 	        this.updatedAt = source["updatedAt"];
+	        this.attributes = this.convertValues(source["attributes"], AttributeDTO);
+	        this.tags = source["tags"];
+	        this.productModelId = source["productModelId"];
+	        this.warranty = this.convertValues(source["warranty"], WarrantyDTO);
+	        this.loan = this.convertValues(source["loan"], LoanDTO);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
+
 	export class ItemWithAssetsDTO {
 	    item: ItemDTO;
 	    assets: AssetDTO[];
@@ -128,6 +379,75 @@ export namespace main {
 		    return a;
 		}
 	}
+
+	export class LoanDTO {
+	    id: number;
+	    direction: string;
+	    itemId?: number;
+	    itemName: string;
+	    borrowerName: string;
+	    lenderName: string;
+	    lentAt: string;
+	    dueAt?: string;
+	    returnedAt?: string;
+	    notes: string;
+	    overdue: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new LoanDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.direction = source["direction"];
+	        this.itemId = source["itemId"];
+	        this.itemName = source["itemName"];
+	        this.borrowerName = source["borrowerName"];
+	        this.lenderName = source["lenderName"];
+	        this.lentAt = source["lentAt"];
+	        this.dueAt = source["dueAt"];
+	        this.returnedAt = source["returnedAt"];
+	        this.notes = source["notes"];
+	        this.overdue = source["overdue"];
+	    }
+	}
+
+	export class MaintenanceTaskDTO {
+	    scheduleId: number;
+	    itemId: number;
+	    itemName: string;
+	    task: string;
+	    intervalDays: number;
+	    intervalUsage: number;
+	    usageUnit: string;
+	    lastDoneAt?: string;
+	    nextDueAt?: string;
+	    nextDueUsage?: number;
+	    currentUsage: number;
+	    due: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new MaintenanceTaskDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.scheduleId = source["scheduleId"];
+	        this.itemId = source["itemId"];
+	        this.itemName = source["itemName"];
+	        this.task = source["task"];
+	        this.intervalDays = source["intervalDays"];
+	        this.intervalUsage = source["intervalUsage"];
+	        this.usageUnit = source["usageUnit"];
+	        this.lastDoneAt = source["lastDoneAt"];
+	        this.nextDueAt = source["nextDueAt"];
+	        this.nextDueUsage = source["nextDueUsage"];
+	        this.currentUsage = source["currentUsage"];
+	        this.due = source["due"];
+	    }
+	}
+
 	export class PeerDTO {
 	    id: string;
 	    name: string;
@@ -152,6 +472,109 @@ export namespace main {
 	        this.status = source["status"];
 	    }
 	}
+
+	export class ProductModelAliasDTO {
+	    brand: string;
+	    model: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProductModelAliasDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.brand = source["brand"];
+	        this.model = source["model"];
+	    }
+	}
+
+	export class ProductModelDTO {
+	    id: number;
+	    brand: string;
+	    model: string;
+	    aliases: ProductModelAliasDTO[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ProductModelDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.brand = source["brand"];
+	        this.model = source["model"];
+	        this.aliases = this.convertValues(source["aliases"], ProductModelAliasDTO);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+	export class ProfileDTO {
+	    name: string;
+	    current: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProfileDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.current = source["current"];
+	    }
+	}
+
+	export class SearchResultDTO {
+	    peerId: string;
+	    peerName: string;
+	    item: ItemWithAssetsDTO;
+	
+	    static createFrom(source: any = {}) {
+	        return new SearchResultDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.peerId = source["peerId"];
+	        this.peerName = source["peerName"];
+	        this.item = this.convertValues(source["item"], ItemWithAssetsDTO);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
 	export class SyncLogDTO {
 	    id: number;
 	    peerName: string;
@@ -178,6 +601,7 @@ export namespace main {
 	        this.error = source["error"];
 	    }
 	}
+
 	export class SyncResultDTO {
 	    itemsReceived: number;
 	    itemsSent: number;
@@ -197,6 +621,27 @@ export namespace main {
 	    }
 	}
 
+	export class WarrantyDTO {
+	    durationMonths: number;
+	    provider: string;
+	    receiptAssetId?: number;
+	    expiresAt?: string;
+	    daysLeft?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new WarrantyDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.durationMonths = source["durationMonths"];
+	        this.provider = source["provider"];
+	        this.receiptAssetId = source["receiptAssetId"];
+	        this.expiresAt = source["expiresAt"];
+	        this.daysLeft = source["daysLeft"];
+	    }
+	}
+
 }
 
 export namespace models {
@@ -206,6 +651,11 @@ export namespace models {
 	    ItemsSent: number;
 	    Conflicts: number;
 	    DurationMs: number;
+	    DocumentsReceived: number;
+	    PartsReceived: number;
+	    AnnouncementsReceived: number;
+	    LoansReceived: number;
+	    Warnings?: string[];
 	
 	    static createFrom(source: any = {}) {
 	        return new SyncResult(source);
@@ -217,6 +667,11 @@ export namespace models {
 	        this.ItemsSent = source["ItemsSent"];
 	        this.Conflicts = source["Conflicts"];
 	        this.DurationMs = source["DurationMs"];
+	        this.DocumentsReceived = source["DocumentsReceived"];
+	        this.PartsReceived = source["PartsReceived"];
+	        this.AnnouncementsReceived = source["AnnouncementsReceived"];
+	        this.LoansReceived = source["LoansReceived"];
+	        this.Warnings = source["Warnings"];
 	    }
 	}
 
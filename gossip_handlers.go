@@ -2,18 +2,22 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-	"strings"
+	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/lhommenul/brique/core/models"
+	"github.com/lhommenul/brique/core/services"
 )
 
 // GossipInfoResponse represents instance information for sync
 type GossipInfoResponse struct {
 	InstanceID   string     `json:"instance_id"`
 	InstanceName string     `json:"instance_name"`
+	PublicKey    string     `json:"public_key"`
 	LastSync     *time.Time `json:"last_sync"`
 	ItemCount    int        `json:"item_count"`
 }
@@ -31,8 +35,18 @@ type SyncResponse struct {
 	Result  *models.SyncResult `json:"result"`
 }
 
+// SearchResultDTO represents an item found on a peer
+type SearchResultDTO struct {
+	PeerID   string            `json:"peerId"`
+	PeerName string            `json:"peerName"`
+	Item     ItemWithAssetsDTO `json:"item"`
+}
+
 // GetGossipInfo returns information about this instance
 func (a *App) GetGossipInfo() (*GossipInfoResponse, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
 	info, err := a.gossipService.GetInstanceInfo(a.ctx)
 	if err != nil {
 		return nil, err
@@ -41,6 +55,7 @@ func (a *App) GetGossipInfo() (*GossipInfoResponse, error) {
 	return &GossipInfoResponse{
 		InstanceID:   info.InstanceID,
 		InstanceName: info.InstanceName,
+		PublicKey:    info.PublicKey,
 		LastSync:     info.LastSync,
 		ItemCount:    info.ItemCount,
 	}, nil
@@ -48,6 +63,9 @@ func (a *App) GetGossipInfo() (*GossipInfoResponse, error) {
 
 // GetGossipChanges returns items modified since a given timestamp
 func (a *App) GetGossipChanges(since time.Time) ([]ItemDTO, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
 	items, err := a.gossipService.GetChanges(a.ctx, since)
 	if err != nil {
 		return nil, err
@@ -63,6 +81,15 @@ func (a *App) GetGossipChanges(since time.Time) ([]ItemDTO, error) {
 
 // SyncWithPeerHTTP performs synchronization with a remote peer via HTTP
 func (a *App) SyncWithPeerHTTP(peerID string) (*models.SyncResult, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.syncWithPeer(peerID)
+}
+
+// syncWithPeer runs the synchronization with a peer, reporting its progress
+// and outcome to the frontend
+func (a *App) syncWithPeer(peerID string) (*models.SyncResult, error) {
 	// Get peer info
 	peers, err := a.gossipService.GetPeers(a.ctx)
 	if err != nil {
@@ -91,86 +118,97 @@ func (a *App) SyncWithPeerHTTP(peerID string) (*models.SyncResult, error) {
 		Total:     100,
 	})
 
-	// Get remote info
-	infoURL := buildPeerURL(peer.Address, "/api/v1/gossip/info")
-	resp, err := http.Get(infoURL)
+	result, err := a.gossipService.SyncPeer(a.ctx, a.backpackService, peer)
 	if err != nil {
 		a.events.EmitProgressComplete(progressID)
-		a.events.Error("Erreur de connexion", fmt.Sprintf("Impossible de contacter %s", peer.Name))
-		return nil, fmt.Errorf("failed to connect to peer: %w", err)
+		if errors.Is(err, services.ErrPeerUnreachable) {
+			a.events.Error("Erreur de connexion", fmt.Sprintf("Impossible de contacter %s", peer.Name))
+		} else {
+			a.events.Error("Erreur de synchronisation", err.Error())
+		}
+		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		a.events.EmitProgressComplete(progressID)
-		a.events.Error("Erreur de synchronisation", "Le serveur distant a renvoyé une erreur")
-		return nil, fmt.Errorf("remote server returned status %d", resp.StatusCode)
+	for _, warning := range result.Warnings {
+		a.logger.Warn("Sync step failed", "peer_id", peerID, "error", warning)
 	}
 
-	var remoteInfo GossipInfoResponse
-	if err := json.NewDecoder(resp.Body).Decode(&remoteInfo); err != nil {
-		a.events.EmitProgressComplete(progressID)
-		a.events.Error("Erreur de synchronisation", "Réponse invalide du serveur")
-		return nil, fmt.Errorf("failed to decode remote info: %w", err)
+	// Complete progress
+	a.events.EmitProgressComplete(progressID)
+
+	// Emit success notification
+	message := fmt.Sprintf("Synchronisé avec %s: %d reçus, %d envoyés", peer.Name, result.ItemsReceived, result.ItemsSent)
+	if result.Conflicts > 0 {
+		message += fmt.Sprintf(", %d conflits résolus", result.Conflicts)
+	}
+	if result.DocumentsReceived > 0 {
+		message += fmt.Sprintf(", %d documents partagés reçus", result.DocumentsReceived)
+	}
+	if result.PartsReceived > 0 {
+		message += fmt.Sprintf(", %d compatibilités de pièces apprises", result.PartsReceived)
 	}
+	if result.AnnouncementsReceived > 0 {
+		message += fmt.Sprintf(", %d annonces d'entraide reçues", result.AnnouncementsReceived)
+	}
+	if result.LoansReceived > 0 {
+		message += fmt.Sprintf(", %d prêts reçus", result.LoansReceived)
+	}
+	a.events.Success("Synchronisation réussie", message)
 
-	// Update progress
-	a.events.EmitProgress(ProgressData{
-		ID:        progressID,
-		Operation: "Récupération des changements",
-		Current:   30,
-		Total:     100,
-	})
+	return result, nil
+}
 
-	// Get remote changes since last sync
-	var since time.Time
-	if peer.LastSync != nil {
-		since = *peer.LastSync
-	}
+// SearchPeers searches online trusted peers for documentation by brand, model and asset type
+func (a *App) SearchPeers(brand, model, assetType string) ([]SearchResultDTO, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
 
-	changesURL := buildPeerURL(peer.Address, fmt.Sprintf("/api/v1/gossip/changes?since=%s", since.Format(time.RFC3339)))
-	resp, err = http.Get(changesURL)
+	results, err := a.gossipService.SearchPeers(a.ctx, models.SearchQuery{
+		Brand:     brand,
+		Model:     model,
+		AssetType: models.AssetType(assetType),
+	})
 	if err != nil {
-		a.events.EmitProgressComplete(progressID)
-		a.events.Error("Erreur de synchronisation", "Impossible de récupérer les changements")
-		return nil, fmt.Errorf("failed to get remote changes: %w", err)
+		a.events.Error("Erreur de recherche", err.Error())
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	var remoteChanges []models.Item
-	if err := json.NewDecoder(resp.Body).Decode(&remoteChanges); err != nil {
-		a.events.EmitProgressComplete(progressID)
-		a.events.Error("Erreur de synchronisation", "Données invalides")
-		return nil, fmt.Errorf("failed to decode remote changes: %w", err)
+	dtos := make([]SearchResultDTO, len(results))
+	for i, result := range results {
+		dtos[i] = SearchResultDTO{
+			PeerID:   result.PeerID,
+			PeerName: result.PeerName,
+			Item:     itemWithAssetsToDTO(&result.Item),
+		}
 	}
 
-	// Update progress
+	return dtos, nil
+}
+
+// FetchFromPeer copies an item found on a peer, with its assets, into the local inventory
+func (a *App) FetchFromPeer(peerID string, itemID int64) (*ItemWithAssetsDTO, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	progressID := fmt.Sprintf("fetch-%s-%d", peerID, itemID)
 	a.events.EmitProgress(ProgressData{
 		ID:        progressID,
-		Operation: "Application des changements",
-		Current:   60,
+		Operation: "Récupération de la documentation",
+		Current:   0,
 		Total:     100,
 	})
 
-	// Sync with peer
-	result, err := a.gossipService.SyncWithPeer(a.ctx, peerID, remoteChanges)
-	if err != nil {
-		a.events.EmitProgressComplete(progressID)
-		a.events.Error("Erreur de synchronisation", err.Error())
-		return nil, err
-	}
+	item, err := a.gossipService.ImportPeerItem(a.ctx, a.backpackService, peerID, itemID)
 
-	// Complete progress
 	a.events.EmitProgressComplete(progressID)
 
-	// Emit success notification
-	message := fmt.Sprintf("Synchronisé avec %s: %d reçus, %d envoyés", peer.Name, result.ItemsReceived, result.ItemsSent)
-	if result.Conflicts > 0 {
-		message += fmt.Sprintf(", %d conflits résolus", result.Conflicts)
+	if err != nil {
+		a.events.Error("Erreur de récupération", err.Error())
+		return nil, err
 	}
-	a.events.Success("Synchronisation réussie", message)
 
-	return result, nil
+	a.events.Success("Documentation récupérée", fmt.Sprintf("'%s' a été ajouté à l'inventaire (%d fichiers)", item.Item.Name, len(item.Assets)))
+	dto := itemWithAssetsToDTO(item)
+	return &dto, nil
 }
 
 // ServeGossipAPI starts the HTTP server for gossip protocol (internal use)
@@ -224,30 +262,193 @@ func ServeGossipAPI(app *App, port int) error {
 		json.NewEncoder(w).Encode(changes)
 	})
 
-	// Start server on specified port
-	addr := fmt.Sprintf(":%d", port)
-	app.logger.Info("Gossip API server starting", "address", addr)
+	// GET /api/v1/gossip/asset-types?since=<timestamp>
+	mux.HandleFunc("/api/v1/gossip/asset-types", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
 
-	return http.ListenAndServe(addr, mux)
-}
+		var since time.Time
+		if sinceStr := r.URL.Query().Get("since"); sinceStr != "" {
+			var err error
+			since, err = time.Parse(time.RFC3339, sinceStr)
+			if err != nil {
+				http.Error(w, "Invalid timestamp format", http.StatusBadRequest)
+				return
+			}
+		}
 
-// buildPeerURL constructs the full URL for a peer endpoint
-// If the address already contains http:// or https://, use it as-is
-// Otherwise, detect if it's a .traefik.me domain or port 443 for HTTPS, else use HTTP
-func buildPeerURL(address, path string) string {
-	if strings.HasPrefix(address, "http://") || strings.HasPrefix(address, "https://") {
-		return fmt.Sprintf("%s%s", address, path)
-	}
+		types, err := app.gossipService.GetAssetTypeChanges(app.ctx, since)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
 
-	// Detect if HTTPS should be used
-	useHTTPS := strings.Contains(address, ".traefik.me") ||
-	           strings.HasSuffix(address, ":443") ||
-	           !strings.Contains(address, ":")  // No port specified, assume HTTPS
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(types)
+	})
 
-	protocol := "http"
-	if useHTTPS {
-		protocol = "https"
-	}
+	// GET /api/v1/gossip/product-models
+	mux.HandleFunc("/api/v1/gossip/product-models", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		catalogue, err := app.backpackService.GetProductModelCatalogue(app.ctx)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(catalogue)
+	})
+
+	// GET /api/v1/gossip/parts
+	mux.HandleFunc("/api/v1/gossip/parts", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		parts, err := app.gossipService.GetSharedParts(app.ctx)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(parts)
+	})
+
+	// GET /api/v1/gossip/announcements
+	mux.HandleFunc("/api/v1/gossip/announcements", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		announcements, err := app.gossipService.GetSharedAnnouncements(app.ctx)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(announcements)
+	})
+
+	// GET /api/v1/gossip/loans?borrower=<key>&lender=<key>&at=<unix time>&signature=<signature>
+	mux.HandleFunc("/api/v1/gossip/loans", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		loans, err := app.gossipService.GetSharedLoans(app.ctx, services.ParseLoansRequest(r.URL.Query()))
+		if errors.Is(err, services.ErrUnknownBorrower) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(loans)
+	})
+
+	// GET /api/v1/gossip/search?brand=<brand>&model=<model>&asset_type=<type>
+	mux.HandleFunc("/api/v1/gossip/search", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		query := models.SearchQuery{
+			Brand:     r.URL.Query().Get("brand"),
+			Model:     r.URL.Query().Get("model"),
+			AssetType: models.AssetType(r.URL.Query().Get("asset_type")),
+		}
+
+		items, err := app.backpackService.SearchDocumentation(app.ctx, query)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(items)
+	})
+
+	// GET /api/v1/gossip/items/<id>
+	mux.HandleFunc("/api/v1/gossip/items/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		id, err := strconv.ParseInt(r.URL.Path[len("/api/v1/gossip/items/"):], 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid item ID", http.StatusBadRequest)
+			return
+		}
+
+		item, err := app.backpackService.GetItemWithAssets(app.ctx, id)
+		if err != nil {
+			http.Error(w, "Item not found", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(item)
+	})
+
+	// GET /api/v1/gossip/assets/<id>
+	mux.HandleFunc("/api/v1/gossip/assets/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		id, err := strconv.ParseInt(r.URL.Path[len("/api/v1/gossip/assets/"):], 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid asset ID", http.StatusBadRequest)
+			return
+		}
+
+		asset, file, err := app.backpackService.OpenAsset(app.ctx, id)
+		if err != nil {
+			http.Error(w, "Asset not found", http.StatusNotFound)
+			return
+		}
+		defer file.Close()
+
+		http.ServeContent(w, r, filepath.Base(asset.FilePath), asset.CreatedAt, file)
+	})
+
+	// GET /api/v1/gossip/thumbnails/<id>
+	mux.HandleFunc("/api/v1/gossip/thumbnails/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		id, err := strconv.ParseInt(r.URL.Path[len("/api/v1/gossip/thumbnails/"):], 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid asset ID", http.StatusBadRequest)
+			return
+		}
+
+		serveThumbnail(app, w, r, id)
+	})
+
+	// Start server on specified port
+	addr := fmt.Sprintf(":%d", port)
+	app.logger.Info("Gossip API server starting", "address", addr)
 
-	return fmt.Sprintf("%s://%s%s", protocol, address, path)
+	return http.ListenAndServe(addr, app.withProfile(mux))
 }
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/lhommenul/brique/core/models"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// ImportRowDTO is the Data Transfer Object for the outcome of a CSV row
+type ImportRowDTO struct {
+	Line          int      `json:"line"`
+	Action        string   `json:"action"`
+	Item          ItemDTO  `json:"item"`
+	DuplicateOf   *int64   `json:"duplicateOf"`
+	DuplicateLine int      `json:"duplicateLine"`
+	Errors        []string `json:"errors"`
+}
+
+// ImportReportDTO is the Data Transfer Object for the outcome of a CSV import
+type ImportReportDTO struct {
+	DryRun     bool              `json:"dryRun"`
+	Columns    map[string]string `json:"columns"`
+	Created    int               `json:"created"`
+	Duplicates int               `json:"duplicates"`
+	Invalid    int               `json:"invalid"`
+	Rows       []ImportRowDTO    `json:"rows"`
+}
+
+// SelectCSVFile asks for the CSV file to import and returns its path
+func (a *App) SelectCSVFile() (string, error) {
+	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Importer un fichier CSV",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "CSV Files (*.csv)", Pattern: "*.csv"},
+			{DisplayName: "All Files (*.*)", Pattern: "*.*"},
+		},
+	})
+	if err != nil || path == "" {
+		return "", fmt.Errorf("import cancelled")
+	}
+	return path, nil
+}
+
+// ImportFromCSV imports the items of a CSV file. columns maps item fields to
+// the header of the column holding them, on top of the recognized headers.
+// With dryRun, it only reports what would be imported, e.g. for a preview.
+func (a *App) ImportFromCSV(path string, columns map[string]string, dryRun bool) (*ImportReportDTO, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	report, err := a.backpackService.ImportCSV(a.ctx, file, models.CSVImportOptions{
+		Columns: columns,
+		DryRun:  dryRun,
+	})
+	if err != nil {
+		a.events.Error("Erreur d'import", err.Error())
+		return nil, err
+	}
+
+	if !dryRun {
+		a.events.Success("Import réussi", fmt.Sprintf("%d objets importés, %d doublons et %d lignes invalides ignorés", report.Created, report.Duplicates, report.Invalid))
+	}
+
+	dto := importReportToDTO(report)
+	return &dto, nil
+}
+
+func importReportToDTO(report *models.ImportReport) ImportReportDTO {
+	dto := ImportReportDTO{
+		DryRun:     report.DryRun,
+		Columns:    report.Columns,
+		Created:    report.Created,
+		Duplicates: report.Duplicates,
+		Invalid:    report.Invalid,
+		Rows:       make([]ImportRowDTO, len(report.Rows)),
+	}
+	for i, row := range report.Rows {
+		dto.Rows[i] = ImportRowDTO{
+			Line:          row.Line,
+			Action:        string(row.Action),
+			Item:          itemToDTO(&row.Item),
+			DuplicateOf:   row.DuplicateOf,
+			DuplicateLine: row.DuplicateLine,
+			Errors:        row.Errors,
+		}
+	}
+	return dto
+}
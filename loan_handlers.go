@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lhommenul/brique/core/models"
+)
+
+// loanCheckInterval is how often overdue loans are looked for
+const loanCheckInterval = 24 * time.Hour
+
+// LoanDTO is the Data Transfer Object for loans
+type LoanDTO struct {
+	ID           int64   `json:"id"`
+	Direction    string  `json:"direction"`
+	ItemID       *int64  `json:"itemId"`
+	ItemName     string  `json:"itemName"`
+	BorrowerName string  `json:"borrowerName"`
+	LenderName   string  `json:"lenderName"`
+	LentAt       string  `json:"lentAt"`
+	DueAt        *string `json:"dueAt"`
+	ReturnedAt   *string `json:"returnedAt"`
+	Notes        string  `json:"notes"`
+	Overdue      bool    `json:"overdue"`
+}
+
+// LendItem records that an item is lent. The borrower is either a known peer,
+// which will receive the loan, or a person given by name. An empty due date
+// (YYYY-MM-DD) leaves the return open.
+func (a *App) LendItem(itemID int64, borrowerPeerID, borrowerName, dueDate, notes string) (*LoanDTO, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	loan := models.Loan{
+		ItemID:         &itemID,
+		BorrowerPeerID: borrowerPeerID,
+		BorrowerName:   borrowerName,
+		Notes:          notes,
+	}
+	if dueDate != "" {
+		dueAt, err := time.ParseInLocation("2006-01-02", dueDate, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("invalid due date: %w", err)
+		}
+		loan.DueAt = &dueAt
+	}
+
+	if err := a.backpackService.LendItem(a.ctx, &loan); err != nil {
+		a.events.Error("Erreur de prêt", err.Error())
+		return nil, err
+	}
+
+	a.events.Success("Prêt enregistré", fmt.Sprintf("%s prêté à %s", loan.ItemName, loan.BorrowerName))
+	dto := loanToDTO(loan)
+	return &dto, nil
+}
+
+// ReturnLoan records that a lent item came back today
+func (a *App) ReturnLoan(id int64) (*LoanDTO, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	loan, err := a.backpackService.ReturnLoan(a.ctx, id, time.Time{})
+	if err != nil {
+		a.events.Error("Erreur", err.Error())
+		return nil, err
+	}
+
+	a.events.Success("Retour enregistré", fmt.Sprintf("%s est rendu", loan.ItemName))
+	dto := loanToDTO(*loan)
+	return &dto, nil
+}
+
+// GetLoans returns the loans in both directions, most recent first
+func (a *App) GetLoans(includeReturned bool) ([]LoanDTO, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	loans, err := a.backpackService.GetLoans(a.ctx, includeReturned)
+	if err != nil {
+		return nil, err
+	}
+	return loansToDTO(loans), nil
+}
+
+// GetOverdueLoans returns the loans not returned past their expected return
+func (a *App) GetOverdueLoans() ([]LoanDTO, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	loans, err := a.backpackService.GetOverdueLoans(a.ctx)
+	if err != nil {
+		return nil, err
+	}
+	return loansToDTO(loans), nil
+}
+
+// watchLoans notifies the frontend when loans become overdue, until the
+// context is cancelled
+func (a *App) watchLoans(ctx context.Context) {
+	ticker := time.NewTicker(loanCheckInterval)
+	defer ticker.Stop()
+
+	a.checkLoans(ctx)
+
+	for {
+		select {
+		case <-ticker.C:
+			a.checkLoans(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// checkLoans emits a warning for each overdue loan, once per run of the
+// application
+func (a *App) checkLoans(ctx context.Context) {
+	loans, err := a.backpackService.GetOverdueLoans(ctx)
+	if err != nil {
+		a.logger.Warn("Failed to check loans", "error", err)
+		return
+	}
+
+	for _, loan := range loans {
+		if _, notified := a.notifiedLoans.LoadOrStore(loan.ID, true); notified {
+			continue
+		}
+		dueAt := loan.DueAt.Format("02/01/2006")
+		if loan.Direction == models.LoanIn {
+			a.events.Warning("Prêt en retard", fmt.Sprintf("%s est à rendre à %s depuis le %s", loan.ItemName, loan.LenderName, dueAt))
+		} else {
+			a.events.Warning("Prêt en retard", fmt.Sprintf("%s devait être rendu par %s le %s", loan.ItemName, loan.BorrowerName, dueAt))
+		}
+	}
+}
+
+func loansToDTO(loans []models.Loan) []LoanDTO {
+	dtos := make([]LoanDTO, len(loans))
+	for i, loan := range loans {
+		dtos[i] = loanToDTO(loan)
+	}
+	return dtos
+}
+
+func loanToDTO(loan models.Loan) LoanDTO {
+	dto := LoanDTO{
+		ID:           loan.ID,
+		Direction:    string(loan.Direction),
+		ItemID:       loan.ItemID,
+		ItemName:     loan.ItemName,
+		BorrowerName: loan.BorrowerName,
+		LenderName:   loan.LenderName,
+		LentAt:       loan.LentAt.Format("2006-01-02"),
+		Notes:        loan.Notes,
+		Overdue:      loan.Overdue,
+	}
+
+	if loan.DueAt != nil {
+		dueAt := loan.DueAt.Format("2006-01-02")
+		dto.DueAt = &dueAt
+	}
+	if loan.ReturnedAt != nil {
+		returnedAt := loan.ReturnedAt.Format("2006-01-02")
+		dto.ReturnedAt = &returnedAt
+	}
+
+	return dto
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"sync"
 
 	"github.com/lhommenul/brique/core/db"
 	"github.com/lhommenul/brique/core/services"
@@ -24,14 +25,27 @@ var assets embed.FS
 
 // App struct
 type App struct {
-	ctx              context.Context
+	ctx    context.Context
+	logger *slog.Logger
+	level  slog.LevelVar
+	events *EventEmitter
+
+	// mu guards the open profile: the bound methods hold it for reading, and
+	// switching profiles for writing, so that no call runs on a closed
+	// database. The watchers read the profile without it: they are stopped
+	// before it changes.
+	mu               sync.RWMutex
 	cfg              *config.Config
 	database         *db.Database
 	backpackService  *services.BackpackService
 	gossipService    *services.GossipService
 	discoveryService *services.DiscoveryService
-	logger           *slog.Logger
-	events           *EventEmitter
+	closeProfile     context.CancelFunc // Stops the watchers of the open profile
+	watchers         sync.WaitGroup     // Watchers of the open profile
+
+	notifiedMaintenance sync.Map // Schedule IDs already notified as due
+	notifiedWarranties  sync.Map // Item IDs already notified as expiring
+	notifiedLoans       sync.Map // Loan IDs already notified as overdue
 }
 
 // NewApp creates a new App application struct
@@ -46,80 +60,148 @@ func (a *App) startup(ctx context.Context) {
 	// Setup event emitter
 	a.events = NewEventEmitter(ctx)
 
-	// Setup logger
+	// Setup logger, at the configured level once the configuration is loaded
 	a.logger = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelInfo,
+		Level: &a.level,
 	}))
 
+	// Open the configured profile
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.openProfile(""); err != nil {
+		a.logger.Error("Failed to open profile", "error", err)
+		a.events.Error("Erreur au démarrage", err.Error())
+		os.Exit(1)
+	}
+
+	a.logger.Info("Application initialized successfully")
+	a.events.Success("Brique démarré", "L'application est prête")
+}
+
+// openProfile loads the configuration of a profile, the configured one if
+// empty, and starts the services on its inventory. a.mu must be held.
+func (a *App) openProfile(profile string) error {
+	ctx, cancel := context.WithCancel(a.ctx)
+
 	// Load configuration
-	var err error
-	a.cfg, err = config.Load()
+	cfg, err := config.LoadProfile(profile)
 	if err != nil {
-		a.logger.Error("Failed to load config", "error", err)
-		a.events.Error("Configuration Error", "Failed to load application configuration")
-		os.Exit(1)
+		cancel()
+		return fmt.Errorf("failed to load config: %w", err)
 	}
+	a.level.Set(cfg.Level())
 
-	a.logger.Info("Configuration loaded", "data_dir", a.cfg.DataDir)
+	a.logger.Info("Configuration loaded", "data_dir", cfg.DataDir, "profile", cfg.Profile)
 
 	// Initialize database
-	a.database, err = db.NewDatabase(a.cfg.DatabasePath, a.logger)
+	database, err := db.NewDatabase(cfg.DatabasePath, a.logger)
 	if err != nil {
-		a.logger.Error("Failed to initialize database", "error", err)
-		a.events.Error("Database Error", "Failed to initialize database")
-		os.Exit(1)
+		cancel()
+		return fmt.Errorf("failed to initialize database: %w", err)
 	}
-
-	// Create queries
-	queries := db.New(a.database.DB)
+	a.cfg = cfg
+	a.database = database
+	a.closeProfile = cancel
 
 	// Create backpack service
-	a.backpackService = services.NewBackpackService(queries, a.cfg.AssetsDir)
+	a.backpackService = services.NewBackpackService(a.database, a.cfg.AssetsDir)
+	a.backpackService.SetLogger(a.logger)
+	a.backpackService.SetHealthRules(a.cfg.HealthRules)
+	a.backpackService.SetAttributeTemplates(a.cfg.AttributeTemplates)
+
+	// Unlock the data encrypted at rest with BRIQUE_PASSPHRASE or the
+	// passphrase file, else the frontend asks for the passphrase
+	a.unlockEncryptionAtStartup(ctx)
+
+	// Finish file operations interrupted by a previous crash
+	if err := a.backpackService.ProcessFileOutbox(ctx); err != nil {
+		a.logger.Warn("Failed to process file outbox", "error", err)
+	}
+
+	// Link items created before product models existed
+	if _, err := a.backpackService.LinkProductModels(ctx); err != nil {
+		a.logger.Warn("Failed to link product models", "error", err)
+	}
 
 	// Create gossip service
-	instanceName := fmt.Sprintf("Brique-%s", os.Getenv("USER"))
-	a.gossipService = services.NewGossipService(queries, instanceName, "localhost:9090")
+	a.gossipService = services.NewGossipService(a.database, a.cfg.InstanceName, fmt.Sprintf("localhost:%d", a.cfg.Gossip.Port))
+	a.gossipService.SetKeyring(a.backpackService.Keyring())
+	a.gossipService.SetLogger(a.logger)
+	a.gossipService.SetPeerTimeout(a.cfg.Gossip.PeerTimeout)
 
-	// Get instance info
+	// Get instance info, each profile having its own identity
 	instanceInfo, err := a.gossipService.GetInstanceInfo(ctx)
 	if err != nil {
-		a.logger.Error("Failed to get instance info", "error", err)
-		os.Exit(1)
+		a.stopProfile()
+		return fmt.Errorf("failed to get instance info: %w", err)
 	}
 
-	// Create discovery service
-	a.discoveryService = services.NewDiscoveryService(
-		instanceInfo.InstanceID,
-		instanceName,
-		9090, // Port for gossip API
-		a.logger,
-		a.gossipService,
-	)
-
-	// Start discovery (announce and browse)
-	if err := a.discoveryService.Start(ctx); err != nil {
-		a.logger.Warn("Failed to start discovery service", "error", err)
-		// Not a fatal error, continue without discovery
+	// Create discovery service, if enabled
+	if a.cfg.Discovery.Enabled {
+		a.discoveryService = services.NewDiscoveryService(
+			instanceInfo.InstanceID,
+			a.cfg.InstanceName,
+			a.cfg.Gossip.Port, // Port for gossip API
+			a.logger,
+			a.gossipService,
+		)
+		a.discoveryService.SetBrowseInterval(a.cfg.Discovery.BrowseInterval)
+
+		// Start discovery (announce and browse)
+		if err := a.discoveryService.Start(ctx); err != nil {
+			a.logger.Warn("Failed to start discovery service", "error", err)
+			// Not a fatal error, continue without discovery
+			a.discoveryService = nil
+		}
 	}
 
-	a.logger.Info("Application initialized successfully")
-	a.events.Success("Brique démarré", "L'application est prête")
+	// Remind the user of due maintenance, expiring warranties and overdue loans
+	for _, watch := range []func(context.Context){a.watchMaintenance, a.watchWarranties, a.watchLoans} {
+		a.watchers.Add(1)
+		go func() {
+			defer a.watchers.Done()
+			watch(ctx)
+		}()
+	}
+
+	return nil
 }
 
-// shutdown is called at application termination
-func (a *App) shutdown(ctx context.Context) {
+// stopProfile stops the services of the open profile and closes its
+// database, once its watchers are done. a.mu must be held.
+func (a *App) stopProfile() {
+	if a.closeProfile != nil {
+		a.closeProfile()
+		a.closeProfile = nil
+	}
+	a.watchers.Wait()
+
 	// Stop discovery service
 	if a.discoveryService != nil {
 		if err := a.discoveryService.Stop(); err != nil {
 			a.logger.Error("Failed to stop discovery service", "error", err)
 		}
+		a.discoveryService = nil
 	}
 
 	// Close database
 	if a.database != nil {
 		a.database.Close()
+		a.database = nil
 	}
 
+	// Reminders are due again in another profile
+	a.notifiedMaintenance.Clear()
+	a.notifiedWarranties.Clear()
+	a.notifiedLoans.Clear()
+}
+
+// shutdown is called at application termination
+func (a *App) shutdown(ctx context.Context) {
+	a.mu.Lock()
+	a.stopProfile()
+	a.mu.Unlock()
+
 	a.logger.Info("Application shutdown")
 }
 
@@ -148,7 +230,8 @@ func main() {
 		MinWidth:  800,
 		MinHeight: 600,
 		AssetServer: &assetserver.Options{
-			Assets: assets,
+			Assets:  assets,
+			Handler: app.thumbnailHandler(),
 		},
 		BackgroundColour: &options.RGBA{R: 255, G: 255, B: 255, A: 255},
 		OnStartup:        app.startup,
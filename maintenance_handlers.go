@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lhommenul/brique/core/models"
+)
+
+// maintenanceCheckInterval is how often due maintenance is looked for
+const maintenanceCheckInterval = time.Hour
+
+// MaintenanceTaskDTO is the Data Transfer Object for maintenance tasks
+type MaintenanceTaskDTO struct {
+	ScheduleID    int64   `json:"scheduleId"`
+	ItemID        int64   `json:"itemId"`
+	ItemName      string  `json:"itemName"`
+	Task          string  `json:"task"`
+	IntervalDays  int64   `json:"intervalDays"`
+	IntervalUsage int64   `json:"intervalUsage"`
+	UsageUnit     string  `json:"usageUnit"`
+	LastDoneAt    *string `json:"lastDoneAt"`
+	NextDueAt     *string `json:"nextDueAt"`
+	NextDueUsage  *int64  `json:"nextDueUsage"`
+	CurrentUsage  int64   `json:"currentUsage"`
+	Due           bool    `json:"due"`
+}
+
+// GetItemMaintenance returns the maintenance schedules of an item
+func (a *App) GetItemMaintenance(itemID int64) ([]MaintenanceTaskDTO, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	tasks, err := a.backpackService.GetItemMaintenance(a.ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	return maintenanceTasksToDTO(tasks), nil
+}
+
+// GetDueMaintenance returns the overdue maintenance tasks and those due in
+// the next days
+func (a *App) GetDueMaintenance(withinDays int) ([]MaintenanceTaskDTO, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	tasks, err := a.backpackService.GetDueMaintenance(a.ctx, time.Duration(withinDays)*24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	return maintenanceTasksToDTO(tasks), nil
+}
+
+// AddMaintenanceSchedule schedules a recurring maintenance task on an item
+func (a *App) AddMaintenanceSchedule(itemID int64, task string, intervalDays, intervalUsage int64, usageUnit string) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	schedule := models.MaintenanceSchedule{
+		ItemID:        itemID,
+		Task:          task,
+		IntervalDays:  intervalDays,
+		IntervalUsage: intervalUsage,
+		UsageUnit:     usageUnit,
+	}
+	if err := a.backpackService.AddMaintenanceSchedule(a.ctx, &schedule); err != nil {
+		a.events.Error("Erreur de planification", err.Error())
+		return err
+	}
+
+	a.events.Success("Entretien planifié", fmt.Sprintf("'%s' a été planifié", schedule.Task))
+	return nil
+}
+
+// CompleteMaintenance marks a maintenance task as done now
+func (a *App) CompleteMaintenance(scheduleID int64) (*MaintenanceTaskDTO, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	task, err := a.backpackService.CompleteMaintenance(a.ctx, scheduleID, time.Time{})
+	if err != nil {
+		a.events.Error("Erreur", "Impossible de marquer l'entretien comme fait")
+		return nil, err
+	}
+
+	a.events.Success("Entretien fait", fmt.Sprintf("'%s' sur %s", task.Schedule.Task, task.ItemName))
+	dto := maintenanceTaskToDTO(*task)
+	return &dto, nil
+}
+
+// RecordUsage sets a usage counter of an item, e.g. its operating hours
+func (a *App) RecordUsage(itemID int64, unit string, value int64) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if err := a.backpackService.RecordUsage(a.ctx, itemID, unit, value); err != nil {
+		a.events.Error("Erreur", err.Error())
+		return err
+	}
+
+	// Usage-based tasks may have become due
+	a.checkMaintenance(a.ctx)
+	return nil
+}
+
+// watchMaintenance notifies the frontend when maintenance tasks become due,
+// until the context is cancelled
+func (a *App) watchMaintenance(ctx context.Context) {
+	ticker := time.NewTicker(maintenanceCheckInterval)
+	defer ticker.Stop()
+
+	a.checkMaintenance(ctx)
+
+	for {
+		select {
+		case <-ticker.C:
+			a.checkMaintenance(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// checkMaintenance emits a warning for each due task not notified yet. A task
+// is notified again once done and due anew.
+func (a *App) checkMaintenance(ctx context.Context) {
+	tasks, err := a.backpackService.GetDueMaintenance(ctx, 0)
+	if err != nil {
+		a.logger.Warn("Failed to check maintenance", "error", err)
+		return
+	}
+
+	due := make(map[int64]bool, len(tasks))
+	for _, task := range tasks {
+		due[task.Schedule.ID] = true
+		if _, notified := a.notifiedMaintenance.LoadOrStore(task.Schedule.ID, true); notified {
+			continue
+		}
+		a.events.Warning("Entretien à faire", fmt.Sprintf("%s : %s", task.ItemName, task.Schedule.Task))
+	}
+
+	// Forget tasks done in the meantime
+	a.notifiedMaintenance.Range(func(key, _ any) bool {
+		if !due[key.(int64)] {
+			a.notifiedMaintenance.Delete(key)
+		}
+		return true
+	})
+}
+
+func maintenanceTasksToDTO(tasks []models.MaintenanceTask) []MaintenanceTaskDTO {
+	dtos := make([]MaintenanceTaskDTO, len(tasks))
+	for i, task := range tasks {
+		dtos[i] = maintenanceTaskToDTO(task)
+	}
+	return dtos
+}
+
+func maintenanceTaskToDTO(task models.MaintenanceTask) MaintenanceTaskDTO {
+	dto := MaintenanceTaskDTO{
+		ScheduleID:    task.Schedule.ID,
+		ItemID:        task.Schedule.ItemID,
+		ItemName:      task.ItemName,
+		Task:          task.Schedule.Task,
+		IntervalDays:  task.Schedule.IntervalDays,
+		IntervalUsage: task.Schedule.IntervalUsage,
+		UsageUnit:     task.Schedule.UsageUnit,
+		NextDueUsage:  task.NextDueUsage,
+		CurrentUsage:  task.CurrentUsage,
+		Due:           task.Due,
+	}
+
+	if task.Schedule.LastDoneAt != nil {
+		lastDoneAt := task.Schedule.LastDoneAt.Format("2006-01-02T15:04:05Z")
+		dto.LastDoneAt = &lastDoneAt
+	}
+	if task.NextDueAt != nil {
+		nextDueAt := task.NextDueAt.Format("2006-01-02T15:04:05Z")
+		dto.NextDueAt = &nextDueAt
+	}
+
+	return dto
+}
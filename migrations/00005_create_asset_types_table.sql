@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS asset_types (
+    name TEXT PRIMARY KEY,
+    label TEXT NOT NULL,
+    mime_patterns TEXT NOT NULL DEFAULT '',
+    counts_for_health BOOLEAN NOT NULL DEFAULT 1,
+    is_builtin BOOLEAN NOT NULL DEFAULT 0,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_asset_types_updated_at ON asset_types(updated_at);
+
+-- Built-in types share a fixed timestamp so they are never seen as local changes
+INSERT INTO asset_types (name, label, mime_patterns, counts_for_health, is_builtin, updated_at) VALUES
+    ('manual', 'Manuel utilisateur', '', 1, 1, '2025-01-01 00:00:00'),
+    ('service_manual', 'Manuel de service', '', 1, 1, '2025-01-01 00:00:00'),
+    ('exploded_view', 'Vue éclatée', '', 1, 1, '2025-01-01 00:00:00'),
+    ('stl', 'Fichier 3D (STL)', '', 1, 1, '2025-01-01 00:00:00'),
+    ('firmware', 'Firmware', '', 1, 1, '2025-01-01 00:00:00'),
+    ('driver', 'Driver', '', 1, 1, '2025-01-01 00:00:00'),
+    ('schematic', 'Schéma', '', 1, 1, '2025-01-01 00:00:00'),
+    ('other', 'Autre', '', 1, 1, '2025-01-01 00:00:00');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_asset_types_updated_at;
+DROP TABLE IF EXISTS asset_types;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Revision chains: an asset may supersede an older revision of the same document
+ALTER TABLE assets ADD COLUMN version_label TEXT NOT NULL DEFAULT '';
+ALTER TABLE assets ADD COLUMN release_date DATETIME;
+ALTER TABLE assets ADD COLUMN supersedes_id INTEGER REFERENCES assets(id) ON DELETE SET NULL;
+ALTER TABLE assets ADD COLUMN superseded_by_id INTEGER REFERENCES assets(id) ON DELETE SET NULL;
+ALTER TABLE assets ADD COLUMN is_current BOOLEAN NOT NULL DEFAULT 1;
+
+CREATE INDEX idx_assets_supersedes_id ON assets(supersedes_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_assets_supersedes_id;
+
+-- Note: SQLite doesn't support DROP COLUMN, so the revision columns are left in place
+-- +goose StatementEnd
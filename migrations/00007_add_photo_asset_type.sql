@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Item photos are managed assets; they never count towards documentation health
+INSERT OR IGNORE INTO asset_types (name, label, mime_patterns, counts_for_health, is_builtin, updated_at) VALUES
+    ('photo', 'Photo', 'image/jpeg,image/png', 0, 1, '2025-01-01 00:00:00');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM asset_types WHERE name = 'photo';
+-- +goose StatementEnd
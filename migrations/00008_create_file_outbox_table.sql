@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Filesystem side effects recorded in the same transaction as the rows they
+-- belong to, applied once the transaction is committed
+CREATE TABLE IF NOT EXISTS file_outbox (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    action TEXT NOT NULL,
+    path TEXT NOT NULL,
+    target_path TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS file_outbox;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Typed custom attributes of an item (voltage, filter reference, belt size...)
+CREATE TABLE IF NOT EXISTS item_attributes (
+    item_id INTEGER NOT NULL,
+    key TEXT NOT NULL,
+    type TEXT NOT NULL DEFAULT 'text',
+    value TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (item_id, key),
+    FOREIGN KEY (item_id) REFERENCES items(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_item_attributes_key ON item_attributes(key);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_item_attributes_key;
+DROP TABLE IF EXISTS item_attributes;
+-- +goose StatementEnd
@@ -0,0 +1,69 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS categories (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+    parent_id INTEGER,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (parent_id) REFERENCES categories(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_categories_parent_id ON categories(parent_id);
+
+-- Alternative spellings resolved to a category, e.g. "electromenager" or "Appliances"
+CREATE TABLE IF NOT EXISTS category_synonyms (
+    synonym TEXT PRIMARY KEY COLLATE NOCASE,
+    category_id INTEGER NOT NULL,
+    FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_category_synonyms_category_id ON category_synonyms(category_id);
+
+CREATE TABLE IF NOT EXISTS tags (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE COLLATE NOCASE
+);
+
+CREATE TABLE IF NOT EXISTS item_tags (
+    item_id INTEGER NOT NULL,
+    tag_id INTEGER NOT NULL,
+    PRIMARY KEY (item_id, tag_id),
+    FOREIGN KEY (item_id) REFERENCES items(id) ON DELETE CASCADE,
+    FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_item_tags_tag_id ON item_tags(tag_id);
+
+-- items.category keeps the canonical category name for display and sync
+ALTER TABLE items ADD COLUMN category_id INTEGER REFERENCES categories(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_items_category_id ON items(category_id);
+
+-- Turn the existing free-text categories into rows, merging case variants
+INSERT OR IGNORE INTO categories (name, updated_at)
+SELECT DISTINCT TRIM(category), CURRENT_TIMESTAMP FROM items
+WHERE TRIM(category) != '';
+
+UPDATE items SET category_id = (
+    SELECT id FROM categories WHERE categories.name = TRIM(items.category)
+);
+
+UPDATE items SET category = (
+    SELECT name FROM categories WHERE categories.id = items.category_id
+)
+WHERE category_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_items_category_id;
+DROP INDEX IF EXISTS idx_item_tags_tag_id;
+DROP TABLE IF EXISTS item_tags;
+DROP TABLE IF EXISTS tags;
+DROP INDEX IF EXISTS idx_category_synonyms_category_id;
+DROP TABLE IF EXISTS category_synonyms;
+DROP INDEX IF EXISTS idx_categories_parent_id;
+DROP TABLE IF EXISTS categories;
+
+-- Note: SQLite can't drop a column used by a foreign key, so items.category_id is left in place
+-- +goose StatementEnd
@@ -0,0 +1,79 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A product model (e.g. Brandt WTC1234) owns the documentation shared by every
+-- unit of that model. model_key is the normalised brand and model, computed by
+-- the application.
+CREATE TABLE IF NOT EXISTS product_models (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    brand TEXT NOT NULL,
+    model TEXT NOT NULL,
+    model_key TEXT NOT NULL UNIQUE,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Other brand and model names of the same product, e.g. a rebadged model
+CREATE TABLE IF NOT EXISTS product_model_aliases (
+    model_key TEXT PRIMARY KEY,
+    product_model_id INTEGER NOT NULL,
+    brand TEXT NOT NULL,
+    model TEXT NOT NULL,
+    FOREIGN KEY (product_model_id) REFERENCES product_models(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_product_model_aliases_product_model_id ON product_model_aliases(product_model_id);
+
+-- Items are linked by the application, from their brand and model
+ALTER TABLE items ADD COLUMN product_model_id INTEGER REFERENCES product_models(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_items_product_model_id ON items(product_model_id);
+
+-- Assets now belong either to an item or to a product model. SQLite can't
+-- relax NOT NULL on item_id, so the table is rebuilt.
+CREATE TABLE assets_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    item_id INTEGER,
+    type TEXT NOT NULL,
+    name TEXT NOT NULL,
+    file_path TEXT NOT NULL,
+    file_size INTEGER NOT NULL DEFAULT 0,
+    file_hash TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    version_label TEXT NOT NULL DEFAULT '',
+    release_date DATETIME,
+    supersedes_id INTEGER REFERENCES assets_new(id) ON DELETE SET NULL,
+    superseded_by_id INTEGER REFERENCES assets_new(id) ON DELETE SET NULL,
+    is_current BOOLEAN NOT NULL DEFAULT 1,
+    product_model_id INTEGER REFERENCES product_models(id) ON DELETE CASCADE,
+    FOREIGN KEY (item_id) REFERENCES items(id) ON DELETE CASCADE,
+    CHECK ((item_id IS NULL) != (product_model_id IS NULL))
+);
+
+INSERT INTO assets_new (
+    id, item_id, type, name, file_path, file_size, file_hash, created_at,
+    version_label, release_date, supersedes_id, superseded_by_id, is_current
+)
+SELECT
+    id, item_id, type, name, file_path, file_size, file_hash, created_at,
+    version_label, release_date, supersedes_id, superseded_by_id, is_current
+FROM assets;
+
+DROP TABLE assets;
+ALTER TABLE assets_new RENAME TO assets;
+
+CREATE INDEX idx_assets_item_id ON assets(item_id);
+CREATE INDEX idx_assets_type ON assets(type);
+CREATE INDEX idx_assets_supersedes_id ON assets(supersedes_id);
+CREATE INDEX idx_assets_product_model_id ON assets(product_model_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_assets_product_model_id;
+DROP INDEX IF EXISTS idx_items_product_model_id;
+DROP INDEX IF EXISTS idx_product_model_aliases_product_model_id;
+DROP TABLE IF EXISTS product_model_aliases;
+
+-- Note: assets.product_model_id and items.product_model_id are left in place,
+-- so product_models is kept as well for the references to stay valid
+-- +goose StatementEnd
@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Maintenance history of an item: what was wrong, what was done, with which
+-- parts, at what cost and by whom. uid identifies an event across instances.
+CREATE TABLE IF NOT EXISTS repair_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    uid TEXT NOT NULL UNIQUE,
+    item_id INTEGER NOT NULL,
+    performed_at DATETIME NOT NULL,
+    diagnosis TEXT NOT NULL DEFAULT '',
+    action TEXT NOT NULL DEFAULT '',
+    parts_replaced TEXT NOT NULL DEFAULT '',
+    cost_cents INTEGER NOT NULL DEFAULT 0,
+    performed_by TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (item_id) REFERENCES items(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_repair_events_item_id ON repair_events(item_id);
+
+-- Photos, schematics... documenting a repair
+CREATE TABLE IF NOT EXISTS repair_event_assets (
+    repair_event_id INTEGER NOT NULL,
+    asset_id INTEGER NOT NULL,
+    PRIMARY KEY (repair_event_id, asset_id),
+    FOREIGN KEY (repair_event_id) REFERENCES repair_events(id) ON DELETE CASCADE,
+    FOREIGN KEY (asset_id) REFERENCES assets(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_repair_event_assets_asset_id ON repair_event_assets(asset_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_repair_event_assets_asset_id;
+DROP TABLE IF EXISTS repair_event_assets;
+DROP INDEX IF EXISTS idx_repair_events_item_id;
+DROP TABLE IF EXISTS repair_events;
+-- +goose StatementEnd
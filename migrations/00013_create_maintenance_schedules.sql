@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Preventive maintenance of an item, due every interval_days days and/or every
+-- interval_usage units of the item's usage counter (e.g. 500 hours)
+CREATE TABLE IF NOT EXISTS maintenance_schedules (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    item_id INTEGER NOT NULL,
+    task TEXT NOT NULL,
+    interval_days INTEGER NOT NULL DEFAULT 0,
+    interval_usage INTEGER NOT NULL DEFAULT 0,
+    usage_unit TEXT NOT NULL DEFAULT '',
+    last_done_at DATETIME,
+    last_done_usage INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (item_id) REFERENCES items(id) ON DELETE CASCADE,
+    CHECK (interval_days > 0 OR interval_usage > 0)
+);
+
+CREATE INDEX idx_maintenance_schedules_item_id ON maintenance_schedules(item_id);
+
+-- Usage counters of items, e.g. operating hours or cycles
+CREATE TABLE IF NOT EXISTS item_usage_counters (
+    item_id INTEGER NOT NULL,
+    unit TEXT NOT NULL,
+    value INTEGER NOT NULL DEFAULT 0,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (item_id, unit),
+    FOREIGN KEY (item_id) REFERENCES items(id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS item_usage_counters;
+DROP INDEX IF EXISTS idx_maintenance_schedules_item_id;
+DROP TABLE IF EXISTS maintenance_schedules;
+-- +goose StatementEnd
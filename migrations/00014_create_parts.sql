@@ -0,0 +1,63 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Spare parts in stock (fuses, belts, gaskets...). part_key is the normalised
+-- reference, or name without one, computed by the application to match parts
+-- across instances.
+CREATE TABLE IF NOT EXISTS parts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    reference TEXT NOT NULL DEFAULT '',
+    part_key TEXT NOT NULL UNIQUE,
+    quantity INTEGER NOT NULL DEFAULT 0,
+    min_quantity INTEGER NOT NULL DEFAULT 0,
+    location TEXT NOT NULL DEFAULT '',
+    notes TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (quantity >= 0 AND min_quantity >= 0)
+);
+
+-- Local items a part fits
+CREATE TABLE IF NOT EXISTS part_items (
+    part_id INTEGER NOT NULL,
+    item_id INTEGER NOT NULL,
+    PRIMARY KEY (part_id, item_id),
+    FOREIGN KEY (part_id) REFERENCES parts(id) ON DELETE CASCADE,
+    FOREIGN KEY (item_id) REFERENCES items(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_part_items_item_id ON part_items(item_id);
+
+-- Product models a part fits, shared with peers
+CREATE TABLE IF NOT EXISTS part_product_models (
+    part_id INTEGER NOT NULL,
+    product_model_id INTEGER NOT NULL,
+    PRIMARY KEY (part_id, product_model_id),
+    FOREIGN KEY (part_id) REFERENCES parts(id) ON DELETE CASCADE,
+    FOREIGN KEY (product_model_id) REFERENCES product_models(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_part_product_models_product_model_id ON part_product_models(product_model_id);
+
+-- STL files to print a replacement
+CREATE TABLE IF NOT EXISTS part_assets (
+    part_id INTEGER NOT NULL,
+    asset_id INTEGER NOT NULL,
+    PRIMARY KEY (part_id, asset_id),
+    FOREIGN KEY (part_id) REFERENCES parts(id) ON DELETE CASCADE,
+    FOREIGN KEY (asset_id) REFERENCES assets(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_part_assets_asset_id ON part_assets(asset_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_part_assets_asset_id;
+DROP TABLE IF EXISTS part_assets;
+DROP INDEX IF EXISTS idx_part_product_models_product_model_id;
+DROP TABLE IF EXISTS part_product_models;
+DROP INDEX IF EXISTS idx_part_items_item_id;
+DROP TABLE IF EXISTS part_items;
+DROP TABLE IF EXISTS parts;
+-- +goose StatementEnd
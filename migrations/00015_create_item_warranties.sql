@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Warranty of an item, running duration_months from its purchase date.
+-- receipt_asset_id points to the proof of purchase among the item's assets.
+CREATE TABLE IF NOT EXISTS item_warranties (
+    item_id INTEGER PRIMARY KEY,
+    duration_months INTEGER NOT NULL,
+    provider TEXT NOT NULL DEFAULT '',
+    receipt_asset_id INTEGER REFERENCES assets(id) ON DELETE SET NULL,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (item_id) REFERENCES items(id) ON DELETE CASCADE,
+    CHECK (duration_months > 0)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS item_warranties;
+-- +goose StatementEnd
@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Signing key of this instance. Announcements are signed with it, its public
+-- half identifying their author across the network.
+CREATE TABLE IF NOT EXISTS instance_keys (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    public_key BLOB NOT NULL,
+    private_key BLOB NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Help announcements ("Je prête une perceuse", "Je cherche un soudeur"),
+-- published here or received from peers. hops counts the peers an
+-- announcement went through to get here, received_from is the last one.
+CREATE TABLE IF NOT EXISTS announcements (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    uid TEXT NOT NULL UNIQUE,
+    kind TEXT NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    author_name TEXT NOT NULL,
+    author_key TEXT NOT NULL,
+    signature TEXT NOT NULL,
+    max_hops INTEGER NOT NULL,
+    hops INTEGER NOT NULL DEFAULT 0,
+    withdrawn BOOLEAN NOT NULL DEFAULT 0,
+    received_from TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL,
+    CHECK (kind IN ('offer', 'request'))
+);
+
+CREATE INDEX idx_announcements_author_key ON announcements(author_key);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_announcements_author_key;
+DROP TABLE IF EXISTS announcements;
+DROP TABLE IF EXISTS instance_keys;
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Items lent by this instance (direction 'out') or borrowed from a peer
+-- (direction 'in', learnt from the lender on sync). The borrower of a loan
+-- out is a peer or a person without an instance.
+CREATE TABLE IF NOT EXISTS loans (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    uid TEXT NOT NULL UNIQUE,
+    direction TEXT NOT NULL,
+    item_id INTEGER,
+    item_name TEXT NOT NULL,
+    borrower_peer_id TEXT NOT NULL DEFAULT '',
+    borrower_name TEXT NOT NULL,
+    lender_peer_id TEXT NOT NULL DEFAULT '',
+    lender_name TEXT NOT NULL DEFAULT '',
+    lent_at DATETIME NOT NULL,
+    due_at DATETIME,
+    returned_at DATETIME,
+    notes TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (item_id) REFERENCES items(id) ON DELETE CASCADE,
+    CHECK (direction IN ('out', 'in')),
+    CHECK (direction = 'in' OR item_id IS NOT NULL)
+);
+
+CREATE INDEX idx_loans_item_id ON loans(item_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_loans_item_id;
+DROP TABLE IF EXISTS loans;
+-- +goose StatementEnd
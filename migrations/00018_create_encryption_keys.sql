@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Data keys encrypting the sensitive item fields and the asset files at rest,
+-- each wrapped with a key derived from the passphrase (Argon2id). New data is
+-- encrypted with the latest key; older ones remain until a rotation is over.
+CREATE TABLE IF NOT EXISTS encryption_keys (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    salt BLOB NOT NULL,
+    kdf_time INTEGER NOT NULL,
+    kdf_memory INTEGER NOT NULL,
+    kdf_threads INTEGER NOT NULL,
+    wrapped_key BLOB NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS encryption_keys;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Identity of this instance on the network, kept across restarts for peers
+-- to recognise it. Each database, hence each profile, has its own.
+CREATE TABLE IF NOT EXISTS instance_identity (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    instance_id TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS instance_identity;
+-- +goose StatementEnd
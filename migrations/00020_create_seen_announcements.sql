@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Announcements received from peers, accepted or not, until they expire. A
+-- relay cannot bring an announcement back once seen: hops are not signed, and
+-- a relay resetting them would otherwise spread it past its hop limit.
+CREATE TABLE IF NOT EXISTS seen_announcements (
+    uid TEXT PRIMARY KEY,
+    expires_at DATETIME NOT NULL
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS seen_announcements;
+-- +goose StatementEnd
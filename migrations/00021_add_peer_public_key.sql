@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Announcement key of the peer, learned from the peer itself on sync, that it
+-- signs its requests for the loans made to it with
+ALTER TABLE peers ADD COLUMN public_key TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Note: SQLite doesn't support DROP COLUMN, so peers.public_key is left in place
+-- +goose StatementEnd
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// thumbnailRoute is served by the Wails asset server so that the frontend can
+// display photos with <img src="/thumbnails/<asset-id>">
+const thumbnailRoute = "/thumbnails/"
+
+// AddPhoto lets the user pick an image and adds it as a photo of the item
+func (a *App) AddPhoto(itemID int64) (*AssetDTO, error) {
+	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Ajouter une photo",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Images (*.jpg, *.jpeg, *.png)", Pattern: "*.jpg;*.jpeg;*.png"},
+		},
+	})
+
+	if err != nil || path == "" {
+		return nil, fmt.Errorf("photo selection cancelled")
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	asset, err := a.backpackService.AddPhoto(a.ctx, itemID, path)
+	if err != nil {
+		a.events.Error("Erreur d'ajout", "Impossible d'ajouter la photo")
+		return nil, err
+	}
+
+	a.events.Success("Photo ajoutée", fmt.Sprintf("'%s' a été ajoutée à l'item", asset.Name))
+	dto := assetToDTO(asset)
+	return &dto, nil
+}
+
+// GetItemPhotos returns the photos of an item
+func (a *App) GetItemPhotos(itemID int64) ([]AssetDTO, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	photos, err := a.backpackService.GetItemPhotos(a.ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	dtos := make([]AssetDTO, len(photos))
+	for i, photo := range photos {
+		dtos[i] = assetToDTO(&photo)
+	}
+
+	return dtos, nil
+}
+
+// thumbnailHandler serves photo thumbnails to the frontend through the Wails
+// asset server
+func (a *App) thumbnailHandler() http.Handler {
+	return a.withProfile(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || !strings.HasPrefix(r.URL.Path, thumbnailRoute) {
+			http.NotFound(w, r)
+			return
+		}
+
+		id, err := strconv.ParseInt(r.URL.Path[len(thumbnailRoute):], 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid asset ID", http.StatusBadRequest)
+			return
+		}
+
+		serveThumbnail(a, w, r, id)
+	}))
+}
+
+// withProfile serves each request on the open profile, which is not switched
+// meanwhile
+func (a *App) withProfile(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.mu.RLock()
+		defer a.mu.RUnlock()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// serveThumbnail writes the JPEG thumbnail of a photo
+func serveThumbnail(app *App, w http.ResponseWriter, r *http.Request, assetID int64) {
+	file, err := app.backpackService.OpenThumbnail(app.ctx, assetID)
+	if err != nil {
+		http.Error(w, "Thumbnail not available", http.StatusNotFound)
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", "image/jpeg")
+	http.ServeContent(w, r, "", time.Time{}, file)
+}
@@ -1,30 +1,188 @@
 package config
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net/url"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"time"
 
+	"github.com/lhommenul/brique/core/models"
 	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
 )
 
+// fileName is the name of the config file, looked for in the data directory
+// given by BRIQUE_DATA_DIR, the default data directory and the working directory
+const fileName = "config.yaml"
+
 // Config holds all configuration for the application
 type Config struct {
 	DataDir      string `mapstructure:"data_dir"`
 	DatabasePath string `mapstructure:"database_path"`
 	AssetsDir    string `mapstructure:"assets_dir"`
-	LogLevel     string `mapstructure:"log_level"`
+	BackupsDir   string `mapstructure:"backups_dir"` // Defaults to the backups directory of DataDir
+	LogLevel     string `mapstructure:"log_level"`   // debug, info, warn or error
 	IsHeadless   bool   `mapstructure:"is_headless"`
+
+	// Profile is the inventory in use. A named profile has its own database,
+	// assets and backups in its directory, instead of DatabasePath, AssetsDir
+	// and BackupsDir, and its own identity on the network.
+	Profile string `mapstructure:"profile"`
+
+	// Port is the HTTP port of brique-server, which serves the API and the
+	// gossip protocol
+	Port int `mapstructure:"port"`
+
+	// InstanceName names this node to its peers. Defaults to Brique-<user>.
+	InstanceName string `mapstructure:"instance_name"`
+
+	// Gossip configures the sync with peers
+	Gossip Gossip `mapstructure:"gossip"`
+
+	// Discovery configures the mDNS discovery of peers on the local network
+	Discovery Discovery `mapstructure:"discovery"`
+
+	// CORSOrigins are the web origins allowed to call the API of
+	// brique-server, "*" allowing any
+	CORSOrigins []string `mapstructure:"cors_origins"`
+
+	// Remote makes the CLI work on a brique-server (e.g. http://host:8080)
+	// instead of the local data
+	Remote string `mapstructure:"remote"`
+
+	// PassphraseFile holds the passphrase unlocking the data encrypted at
+	// rest, for brique-server to start unattended. BRIQUE_PASSPHRASE takes
+	// precedence.
+	PassphraseFile string `mapstructure:"passphrase_file"`
+
+	// BackupRetention is how many backups are kept after each new one
+	BackupRetention models.BackupRetention `mapstructure:"backup_retention"`
+
+	// BackupSchedule makes brique-server back up on its own
+	BackupSchedule BackupSchedule `mapstructure:"backup_schedule"`
+
+	// HealthRules override the built-in documentation rules per item category
+	HealthRules []models.HealthRule `mapstructure:"health_rules"`
+
+	// AttributeTemplates override the built-in custom attributes suggested per item category
+	AttributeTemplates []models.AttributeTemplate `mapstructure:"attribute_templates"`
+
+	file     string         // Config file read, if any
+	settings map[string]any // Settings as loaded, for display
+}
+
+// Gossip configures the sync with peers
+type Gossip struct {
+	// Port is the port of the gossip API of the GUI, announced to peers.
+	// brique-server serves the gossip protocol on Port.
+	Port int `mapstructure:"port"`
+
+	// PeerTimeout bounds every request made to a peer
+	PeerTimeout time.Duration `mapstructure:"peer_timeout"`
+
+	// SyncInterval makes brique-server sync with peers on its own, zero
+	// disabling it
+	SyncInterval time.Duration `mapstructure:"sync_interval"`
+
+	// SyncPeers chooses the peers synced on schedule: SyncPeersTrusted or
+	// SyncPeersAll
+	SyncPeers string `mapstructure:"sync_peers"`
+}
+
+// Peers synced on schedule
+const (
+	SyncPeersTrusted = "trusted" // Only the peers marked as trusted
+	SyncPeersAll     = "all"     // Every known peer, discovered ones included
+)
+
+// Discovery configures the mDNS discovery of peers on the local network
+type Discovery struct {
+	Enabled        bool          `mapstructure:"enabled"`
+	BrowseInterval time.Duration `mapstructure:"browse_interval"` // Between two looks for peers
+}
+
+// BackupSchedule configures the backups made by brique-server on its own
+type BackupSchedule struct {
+	Interval time.Duration `mapstructure:"interval"` // e.g. 24h, zero disables scheduled backups
+
+	// Destinations are the backups directories, e.g. on a mounted USB drive.
+	// Defaults to BackupsDir.
+	Destinations []string `mapstructure:"destinations"`
 }
 
-// Load loads the configuration from environment and defaults
+// Load loads the configuration from environment, config file and defaults,
+// and validates it
 func Load() (*Config, error) {
+	return LoadProfile("")
+}
+
+// LoadProfile loads the configuration of a profile, e.g. given by a
+// --profile flag. An empty profile is the one configured, by BRIQUE_PROFILE
+// or the config file.
+func LoadProfile(profile string) (*Config, error) {
+	v, err := newViper()
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := File()
+	if err != nil {
+		return nil, err
+	}
+
+	// Reading the config file is optional
+	if _, err := os.Stat(file); err == nil {
+		v.SetConfigFile(file)
+		if err := v.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("failed to read config file %s: %w", file, err)
+		}
+	}
+
+	if profile != "" {
+		v.Set("profile", profile)
+	}
+
+	cfg, err := decode(v)
+	if err != nil {
+		return nil, err
+	}
+	cfg.file = file
+
+	// Ensure directories exist
+	if err := ensureDirectories(cfg); err != nil {
+		return nil, fmt.Errorf("failed to create directories: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// newViper sets the defaults and the environment variable overrides, e.g.
+// BRIQUE_PORT or BRIQUE_GOSSIP_PEER_TIMEOUT
+func newViper() (*viper.Viper, error) {
 	v := viper.New()
 
 	// Set defaults
 	v.SetDefault("log_level", "info")
+	v.SetDefault("profile", DefaultProfile)
 	v.SetDefault("is_headless", false)
+	v.SetDefault("remote", "")
+	v.SetDefault("passphrase_file", "")
+	v.SetDefault("port", 8080)
+	v.SetDefault("instance_name", defaultInstanceName())
+	v.SetDefault("gossip.port", 9090)
+	v.SetDefault("gossip.peer_timeout", "30s")
+	v.SetDefault("gossip.sync_interval", "0s")
+	v.SetDefault("gossip.sync_peers", SyncPeersTrusted)
+	v.SetDefault("discovery.enabled", true)
+	v.SetDefault("discovery.browse_interval", "10s")
+	v.SetDefault("backup_schedule.interval", "0s")
+	v.SetDefault("cors_origins", []string{"*"})
 
 	// Determine default data directory based on OS
 	dataDir, err := getDefaultDataDir()
@@ -38,30 +196,312 @@ func Load() (*Config, error) {
 
 	// Allow environment variable overrides with BRIQUE_ prefix
 	v.SetEnvPrefix("BRIQUE")
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	v.AutomaticEnv()
 
-	// Try to read config file if it exists
-	v.SetConfigName("config")
-	v.SetConfigType("yaml")
-	v.AddConfigPath(dataDir)
-	v.AddConfigPath(".")
+	return v, nil
+}
 
-	// Reading the config file is optional
-	_ = v.ReadInConfig()
+// decode builds the configuration from the settings read, rejecting unknown
+// settings and invalid values
+func decode(v *viper.Viper) (*Config, error) {
+	// A named profile has its own paths and instance name, whatever the
+	// settings of the default profile
+	profile := v.GetString("profile")
+	named := profile != DefaultProfile && ValidateProfileName(profile) == nil
+	if named {
+		dir := ProfileDir(v.GetString("data_dir"), profile)
+		v.Set("database_path", filepath.Join(dir, "brique.db"))
+		v.Set("assets_dir", filepath.Join(dir, "assets"))
+		v.Set("backups_dir", filepath.Join(dir, "backups"))
+		v.Set("instance_name", v.GetString("instance_name")+"-"+profile)
+	}
 
-	var cfg Config
-	if err := v.Unmarshal(&cfg); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	// Backups default to the data directory actually configured
+	backupsDir := filepath.Join(v.GetString("data_dir"), "backups")
+	v.SetDefault("backups_dir", backupsDir)
+	v.SetDefault("backup_schedule.destinations", []string{v.GetString("backups_dir")})
+
+	// Scheduled backups of a named profile go apart from those of the others
+	if named {
+		destinations := v.GetStringSlice("backup_schedule.destinations")
+		for i, destination := range destinations {
+			if destination != v.GetString("backups_dir") {
+				destinations[i] = filepath.Join(destination, profilesDir, profile)
+			}
+		}
+		v.Set("backup_schedule.destinations", destinations)
 	}
 
-	// Ensure directories exist
-	if err := ensureDirectories(&cfg); err != nil {
-		return nil, fmt.Errorf("failed to create directories: %w", err)
+	var cfg Config
+	if err := v.UnmarshalExact(&cfg); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
 	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	cfg.settings = v.AllSettings()
 
 	return &cfg, nil
 }
 
+// Validate reports every invalid setting
+func (c *Config) Validate() error {
+	var errs []error
+	invalid := func(key, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+
+	if strings.TrimSpace(c.DataDir) == "" {
+		invalid("data_dir", "is required")
+	}
+	if strings.TrimSpace(c.DatabasePath) == "" {
+		invalid("database_path", "is required")
+	}
+	if strings.TrimSpace(c.AssetsDir) == "" {
+		invalid("assets_dir", "is required")
+	}
+	if c.Profile != DefaultProfile {
+		if err := ValidateProfileName(c.Profile); err != nil {
+			invalid("profile", "%v", err)
+		} else if !profileExists(c.DataDir, c.Profile) {
+			invalid("profile", "%q does not exist in %s", c.Profile, filepath.Join(c.DataDir, profilesDir))
+		}
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		invalid("log_level", "%q is not one of debug, info, warn or error", c.LogLevel)
+	}
+	if c.Port < 1 || c.Port > 65535 {
+		invalid("port", "%d is not between 1 and 65535", c.Port)
+	}
+	if strings.TrimSpace(c.InstanceName) == "" {
+		invalid("instance_name", "is required")
+	}
+	if c.Remote != "" {
+		if u, err := url.Parse(c.Remote); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			invalid("remote", "%q is not an http(s) URL, e.g. http://host:8080", c.Remote)
+		}
+	}
+	if c.PassphraseFile != "" {
+		if _, err := os.Stat(c.PassphraseFile); err != nil {
+			invalid("passphrase_file", "%v", err)
+		}
+	}
+
+	if c.Gossip.Port < 1 || c.Gossip.Port > 65535 {
+		invalid("gossip.port", "%d is not between 1 and 65535", c.Gossip.Port)
+	}
+	if c.Gossip.PeerTimeout <= 0 {
+		invalid("gossip.peer_timeout", "must be positive, e.g. 30s")
+	}
+	if c.Gossip.SyncInterval < 0 || (c.Gossip.SyncInterval > 0 && c.Gossip.SyncInterval < time.Minute) {
+		invalid("gossip.sync_interval", "%s is neither 0 nor at least 1m", c.Gossip.SyncInterval)
+	}
+	if c.Gossip.SyncPeers != SyncPeersTrusted && c.Gossip.SyncPeers != SyncPeersAll {
+		invalid("gossip.sync_peers", "%q is not one of %s or %s", c.Gossip.SyncPeers, SyncPeersTrusted, SyncPeersAll)
+	}
+	if c.Discovery.Enabled && c.Discovery.BrowseInterval < time.Second {
+		invalid("discovery.browse_interval", "%s is shorter than 1s", c.Discovery.BrowseInterval)
+	}
+
+	if c.BackupSchedule.Interval < 0 || (c.BackupSchedule.Interval > 0 && c.BackupSchedule.Interval < time.Minute) {
+		invalid("backup_schedule.interval", "%s is neither 0 nor at least 1m", c.BackupSchedule.Interval)
+	}
+	for _, origin := range c.CORSOrigins {
+		if origin == "*" {
+			continue
+		}
+		if u, err := url.Parse(origin); err != nil || u.Scheme == "" || u.Host == "" || u.Path != "" {
+			invalid("cors_origins", "%q is not an origin, e.g. https://inventaire.local", origin)
+		}
+	}
+	retention := c.BackupRetention
+	if retention.KeepLast < 0 || retention.KeepDaily < 0 || retention.KeepWeekly < 0 || retention.KeepMonthly < 0 {
+		invalid("backup_retention", "counts cannot be negative")
+	}
+	for i, rule := range c.HealthRules {
+		if strings.TrimSpace(rule.Category) == "" {
+			invalid(fmt.Sprintf("health_rules[%d].category", i), "is required")
+		}
+	}
+	for i, template := range c.AttributeTemplates {
+		if strings.TrimSpace(template.Category) == "" {
+			invalid(fmt.Sprintf("attribute_templates[%d].category", i), "is required")
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// Level returns the log level
+func (c *Config) Level() slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		return slog.LevelInfo
+	}
+	return level
+}
+
+// File returns the path of the config file read by Load
+func (c *Config) File() string {
+	return c.file
+}
+
+// Settings returns the settings as loaded, including defaults and
+// environment overrides, by key
+func (c *Config) Settings() map[string]any {
+	return c.settings
+}
+
+// File returns the config file read by Load: the first config.yaml found, or
+// the one of the data directory when there is none yet
+func File() (string, error) {
+	var dirs []string
+	if dir := os.Getenv("BRIQUE_DATA_DIR"); dir != "" {
+		dirs = append(dirs, dir)
+	}
+	dataDir, err := getDefaultDataDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get default data directory: %w", err)
+	}
+	dirs = append(dirs, dataDir, ".")
+
+	for _, dir := range dirs {
+		path := filepath.Join(dir, fileName)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return filepath.Join(dirs[0], fileName), nil
+}
+
+// Set writes a setting to the config file, keeping the rest of the file as
+// it is. The value is read as YAML, e.g. 8080, 24h or [a, b]. The file is
+// only written if the configuration it results in is valid.
+func Set(file, key, value string) error {
+	var doc yaml.Node
+	data, err := os.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to read config file %s: %w", file, err)
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+
+	var parsed yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &parsed); err != nil {
+		return fmt.Errorf("invalid value %q: %w", value, err)
+	}
+	node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str"}
+	if len(parsed.Content) > 0 {
+		node = parsed.Content[0]
+	}
+
+	if err := setNode(doc.Content[0], strings.Split(key, "."), node); err != nil {
+		return fmt.Errorf("cannot set %s: %w", key, err)
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	encoder.Close()
+
+	// Check the resulting configuration before writing it
+	v, err := newViper()
+	if err != nil {
+		return err
+	}
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(bytes.NewReader(buf.Bytes())); err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	if _, err := decode(v); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	temp := file + ".tmp"
+	if err := os.WriteFile(temp, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := os.Rename(temp, file); err != nil {
+		os.Remove(temp)
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// setNode sets the value at the given path of a YAML mapping, creating the
+// intermediate mappings
+func setNode(mapping *yaml.Node, path []string, value *yaml.Node) error {
+	if mapping.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s is not a mapping", path[0])
+	}
+	if path[0] == "" {
+		return fmt.Errorf("empty key")
+	}
+
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != path[0] {
+			continue
+		}
+		if len(path) == 1 {
+			mapping.Content[i+1] = value
+			return nil
+		}
+		return setNode(mapping.Content[i+1], path[1:], value)
+	}
+
+	child := value
+	if len(path) > 1 {
+		child = &yaml.Node{Kind: yaml.MappingNode}
+		if err := setNode(child, path[1:], value); err != nil {
+			return err
+		}
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: path[0]}, child)
+	return nil
+}
+
+// Passphrase returns the passphrase of the data encrypted at rest given by
+// BRIQUE_PASSPHRASE or the passphrase file, or an empty string without either
+func (c *Config) Passphrase() (string, error) {
+	if passphrase := os.Getenv("BRIQUE_PASSPHRASE"); passphrase != "" {
+		return passphrase, nil
+	}
+	if c.PassphraseFile == "" {
+		return "", nil
+	}
+
+	data, err := os.ReadFile(c.PassphraseFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase file: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// defaultInstanceName names the node after the user running it
+func defaultInstanceName() string {
+	for _, name := range []string{"USER", "USERNAME"} {
+		if user := os.Getenv(name); user != "" {
+			return "Brique-" + user
+		}
+	}
+	return "Brique-Server"
+}
+
 // getDefaultDataDir returns the default data directory based on the OS
 func getDefaultDataDir() (string, error) {
 	var baseDir string
@@ -104,6 +544,7 @@ func ensureDirectories(cfg *Config) error {
 	dirs := []string{
 		cfg.DataDir,
 		cfg.AssetsDir,
+		cfg.BackupsDir,
 		filepath.Dir(cfg.DatabasePath),
 	}
 
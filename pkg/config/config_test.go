@@ -0,0 +1,286 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// decodeYAML decodes a config file content over the defaults and the
+// environment, as Load does
+func decodeYAML(t *testing.T, content string) (*Config, error) {
+	t.Helper()
+
+	v, err := newViper()
+	if err != nil {
+		t.Fatalf("failed to set defaults: %v", err)
+	}
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(strings.NewReader(content)); err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+	return decode(v)
+}
+
+func TestValidate(t *testing.T) {
+	dataDir := t.TempDir()
+	t.Setenv("BRIQUE_DATA_DIR", dataDir)
+	passphraseFile := filepath.Join(dataDir, "passphrase")
+	if err := os.WriteFile(passphraseFile, []byte("correct horse\n"), 0600); err != nil {
+		t.Fatalf("failed to write passphrase file: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		change  func(*Config)
+		invalid string // Setting reported, none if valid
+	}{
+		{"defaults", func(c *Config) {}, ""},
+		{"port zero", func(c *Config) { c.Port = 0 }, "port"},
+		{"port too high", func(c *Config) { c.Port = 65536 }, "port"},
+		{"highest port", func(c *Config) { c.Port = 65535 }, ""},
+		{"gossip port", func(c *Config) { c.Gossip.Port = -1 }, "gossip.port"},
+		{"log level", func(c *Config) { c.LogLevel = "verbose" }, "log_level"},
+		{"instance name", func(c *Config) { c.InstanceName = " " }, "instance_name"},
+		{"database path", func(c *Config) { c.DatabasePath = "" }, "database_path"},
+		{"peer timeout", func(c *Config) { c.Gossip.PeerTimeout = 0 }, "gossip.peer_timeout"},
+		{"sync interval too short", func(c *Config) { c.Gossip.SyncInterval = 30 * time.Second }, "gossip.sync_interval"},
+		{"sync interval negative", func(c *Config) { c.Gossip.SyncInterval = -time.Minute }, "gossip.sync_interval"},
+		{"sync interval", func(c *Config) { c.Gossip.SyncInterval = time.Hour }, ""},
+		{"sync peers", func(c *Config) { c.Gossip.SyncPeers = "some" }, "gossip.sync_peers"},
+		{"browse interval", func(c *Config) { c.Discovery.BrowseInterval = time.Millisecond }, "discovery.browse_interval"},
+		{"browse interval without discovery", func(c *Config) {
+			c.Discovery.Enabled = false
+			c.Discovery.BrowseInterval = 0
+		}, ""},
+		{"backup interval", func(c *Config) { c.BackupSchedule.Interval = time.Second }, "backup_schedule.interval"},
+		{"daily backups", func(c *Config) { c.BackupSchedule.Interval = 24 * time.Hour }, ""},
+		{"negative retention", func(c *Config) { c.BackupRetention.KeepWeekly = -1 }, "backup_retention"},
+		{"retention", func(c *Config) { c.BackupRetention.KeepLast = 7 }, ""},
+		{"remote without scheme", func(c *Config) { c.Remote = "host:8080" }, "remote"},
+		{"remote", func(c *Config) { c.Remote = "https://inventaire.local" }, ""},
+		{"cors origin with path", func(c *Config) { c.CORSOrigins = []string{"https://inventaire.local/app"} }, "cors_origins"},
+		{"missing passphrase file", func(c *Config) { c.PassphraseFile = filepath.Join(dataDir, "missing") }, "passphrase_file"},
+		{"passphrase file", func(c *Config) { c.PassphraseFile = passphraseFile }, ""},
+		{"profile name", func(c *Config) { c.Profile = "Atelier" }, "profile"},
+		{"missing profile", func(c *Config) { c.Profile = "atelier" }, "profile"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := decodeYAML(t, "")
+			if err != nil {
+				t.Fatalf("expected the defaults to be valid: %v", err)
+			}
+			tt.change(cfg)
+
+			err = cfg.Validate()
+			if tt.invalid == "" {
+				if err != nil {
+					t.Errorf("expected a valid config, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.invalid+":") {
+				t.Errorf("expected %s to be reported, got %v", tt.invalid, err)
+			}
+		})
+	}
+}
+
+func TestDecodeUnknownKeys(t *testing.T) {
+	t.Setenv("BRIQUE_DATA_DIR", t.TempDir())
+
+	tests := []struct {
+		name    string
+		content string
+		valid   bool
+	}{
+		{"known keys", "port: 8081\ngossip:\n  peer_timeout: 10s\n", true},
+		{"misspelled key", "prot: 8081\n", false},
+		{"misspelled nested key", "gossip:\n  peer_timeot: 10s\n", false},
+		{"value of the wrong type", "port: eighty\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeYAML(t, tt.content)
+			if tt.valid && err != nil {
+				t.Errorf("expected the config to decode, got %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Error("expected the config to be rejected")
+			}
+		})
+	}
+}
+
+func TestDecodeProfile(t *testing.T) {
+	dataDir := t.TempDir()
+	t.Setenv("BRIQUE_DATA_DIR", dataDir)
+	if err := CreateProfile(dataDir, "atelier"); err != nil {
+		t.Fatalf("failed to create profile: %v", err)
+	}
+	usbDrive := t.TempDir()
+	profileDir := ProfileDir(dataDir, "atelier")
+
+	tests := []struct {
+		name         string
+		content      string
+		database     string
+		backups      string
+		instance     string
+		destinations []string
+	}{
+		{
+			name:         "default profile",
+			content:      "instance_name: maison\ndatabase_path: /elsewhere/brique.db\n",
+			database:     "/elsewhere/brique.db",
+			backups:      filepath.Join(dataDir, "backups"),
+			instance:     "maison",
+			destinations: []string{filepath.Join(dataDir, "backups")},
+		},
+		{
+			name:         "named profile",
+			content:      "profile: atelier\ninstance_name: maison\ndatabase_path: /elsewhere/brique.db\n",
+			database:     filepath.Join(profileDir, "brique.db"),
+			backups:      filepath.Join(profileDir, "backups"),
+			instance:     "maison-atelier",
+			destinations: []string{filepath.Join(profileDir, "backups")},
+		},
+		{
+			name:         "named profile backed up on a drive",
+			content:      "profile: atelier\ninstance_name: maison\nbackup_schedule:\n  destinations: [" + usbDrive + "]\n",
+			database:     filepath.Join(profileDir, "brique.db"),
+			backups:      filepath.Join(profileDir, "backups"),
+			instance:     "maison-atelier",
+			destinations: []string{filepath.Join(usbDrive, "profiles", "atelier")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := decodeYAML(t, tt.content)
+			if err != nil {
+				t.Fatalf("failed to decode config: %v", err)
+			}
+			if cfg.DatabasePath != tt.database || cfg.BackupsDir != tt.backups || cfg.InstanceName != tt.instance {
+				t.Errorf("expected %s, %s and %s, got %s, %s and %s", tt.database, tt.backups, tt.instance, cfg.DatabasePath, cfg.BackupsDir, cfg.InstanceName)
+			}
+			if strings.Join(cfg.BackupSchedule.Destinations, ",") != strings.Join(tt.destinations, ",") {
+				t.Errorf("expected the backups in %v, got %v", tt.destinations, cfg.BackupSchedule.Destinations)
+			}
+		})
+	}
+
+	if _, err := decodeYAML(t, "profile: garage\n"); err == nil {
+		t.Error("expected a profile not created to be rejected")
+	}
+}
+
+func TestSet(t *testing.T) {
+	t.Setenv("BRIQUE_DATA_DIR", t.TempDir())
+
+	const original = "# Serveur du salon\nport: 8081\ngossip:\n  port: 9091 # ouvert sur le routeur\n"
+
+	tests := []struct {
+		name  string
+		key   string
+		value string
+		check func(*Config) bool
+		keeps []string // Content of the file kept as it was
+		fails bool
+	}{
+		{
+			name:  "replaces a value",
+			key:   "port",
+			value: "8082",
+			check: func(c *Config) bool { return c.Port == 8082 },
+			keeps: []string{"# Serveur du salon", "port: 9091 # ouvert sur le routeur"},
+		},
+		{
+			name:  "adds to an existing mapping",
+			key:   "gossip.peer_timeout",
+			value: "10s",
+			check: func(c *Config) bool { return c.Gossip.PeerTimeout == 10*time.Second && c.Gossip.Port == 9091 },
+			keeps: []string{"port: 9091 # ouvert sur le routeur"},
+		},
+		{
+			name:  "creates the intermediate mappings",
+			key:   "backup_schedule.interval",
+			value: "24h",
+			check: func(c *Config) bool { return c.BackupSchedule.Interval == 24*time.Hour },
+		},
+		{
+			name:  "reads lists",
+			key:   "cors_origins",
+			value: "[https://a.local, https://b.local]",
+			check: func(c *Config) bool { return strings.Join(c.CORSOrigins, ",") == "https://a.local,https://b.local" },
+		},
+		{
+			name:  "keeps strings as strings",
+			key:   "instance_name",
+			value: "atelier",
+			check: func(c *Config) bool { return c.InstanceName == "atelier" },
+		},
+		{name: "rejects invalid values", key: "port", value: "70000", fails: true},
+		{name: "rejects unknown keys", key: "prot", value: "8082", fails: true},
+		{name: "rejects keys under a value", key: "port.number", value: "8082", fails: true},
+		{name: "rejects empty keys", key: "gossip.", value: "8082", fails: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), fileName)
+			if err := os.WriteFile(file, []byte(original), 0644); err != nil {
+				t.Fatalf("failed to write config file: %v", err)
+			}
+
+			err := Set(file, tt.key, tt.value)
+			data, readErr := os.ReadFile(file)
+			if readErr != nil {
+				t.Fatalf("failed to read config file: %v", readErr)
+			}
+			if tt.fails {
+				if err == nil {
+					t.Error("expected the setting to be rejected")
+				}
+				if string(data) != original {
+					t.Errorf("expected the file left as it was, got %q", data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to set %s: %v", tt.key, err)
+			}
+
+			cfg, err := decodeYAML(t, string(data))
+			if err != nil {
+				t.Fatalf("failed to decode written config: %v", err)
+			}
+			if !tt.check(cfg) {
+				t.Errorf("expected %s set to %s, got %q", tt.key, tt.value, data)
+			}
+			for _, kept := range tt.keeps {
+				if !strings.Contains(string(data), kept) {
+					t.Errorf("expected %q kept, got %q", kept, data)
+				}
+			}
+		})
+	}
+
+	// A missing file is created
+	file := filepath.Join(t.TempDir(), "brique", fileName)
+	if err := Set(file, "gossip.sync_peers", "all"); err != nil {
+		t.Fatalf("failed to set in a new file: %v", err)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("failed to read config file: %v", err)
+	}
+	if cfg, err := decodeYAML(t, string(data)); err != nil || cfg.Gossip.SyncPeers != SyncPeersAll {
+		t.Errorf("expected the setting written, got %q (%v)", data, err)
+	}
+}
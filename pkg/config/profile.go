@@ -0,0 +1,92 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// DefaultProfile is the inventory kept at the root of the data directory.
+// Named profiles keep theirs in the profiles directory of the data directory.
+const DefaultProfile = "default"
+
+// profilesDir holds a directory per named profile, in the data directory
+const profilesDir = "profiles"
+
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// ErrProfileNotFound is returned for a profile that was not created
+var ErrProfileNotFound = errors.New("profile not found")
+
+// ValidateProfileName checks that a profile name can name its directory:
+// lowercase letters, digits, - and _
+func ValidateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("%q is not a profile name: use lowercase letters, digits, - and _", name)
+	}
+	return nil
+}
+
+// ProfileDir returns the directory of a named profile
+func ProfileDir(dataDir, name string) string {
+	return filepath.Join(dataDir, profilesDir, name)
+}
+
+// Profiles returns the default profile and the named profiles of the data
+// directory, sorted by name
+func Profiles(dataDir string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(dataDir, profilesDir))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to list profiles: %w", err)
+	}
+
+	profiles := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() && entry.Name() != DefaultProfile && ValidateProfileName(entry.Name()) == nil {
+			profiles = append(profiles, entry.Name())
+		}
+	}
+	sort.Strings(profiles)
+
+	return append([]string{DefaultProfile}, profiles...), nil
+}
+
+// CreateProfile creates a named profile, empty until it is first used
+func CreateProfile(dataDir, name string) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	if name == DefaultProfile || profileExists(dataDir, name) {
+		return fmt.Errorf("profile %q already exists", name)
+	}
+
+	if err := os.MkdirAll(ProfileDir(dataDir, name), 0755); err != nil {
+		return fmt.Errorf("failed to create profile: %w", err)
+	}
+	return nil
+}
+
+// DeleteProfile deletes a named profile with its database, assets and
+// backups. The default profile cannot be deleted.
+func DeleteProfile(dataDir, name string) error {
+	if name == DefaultProfile {
+		return fmt.Errorf("the %s profile cannot be deleted", DefaultProfile)
+	}
+	if ValidateProfileName(name) != nil || !profileExists(dataDir, name) {
+		return fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+	}
+
+	if err := os.RemoveAll(ProfileDir(dataDir, name)); err != nil {
+		return fmt.Errorf("failed to delete profile: %w", err)
+	}
+	return nil
+}
+
+// profileExists tells whether a named profile was created
+func profileExists(dataDir, name string) bool {
+	info, err := os.Stat(ProfileDir(dataDir, name))
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDelay lets the writes of an editor settle before the file is read
+const watchDelay = 500 * time.Millisecond
+
+// Watch calls onChange once the config file was written, created, replaced
+// or removed, until ctx is done. The directory of the file is watched, for
+// files replaced by a rename, as editors and Set do, to be caught.
+func Watch(ctx context.Context, file string, onChange func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to watch config file: %w", err)
+	}
+	if err := watcher.Add(filepath.Dir(file)); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to watch config file: %w", err)
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		file := filepath.Clean(file)
+		timer := time.NewTimer(watchDelay)
+		timer.Stop()
+
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) == file && !event.Has(fsnotify.Chmod) {
+					timer.Reset(watchDelay)
+				}
+			case <-watcher.Errors:
+			case <-timer.C:
+				onChange()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, fileName)
+	if err := os.WriteFile(file, []byte("port: 8080\n"), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan time.Time, 10)
+	if err := Watch(ctx, file, func() { changes <- time.Now() }); err != nil {
+		t.Fatalf("failed to watch config file: %v", err)
+	}
+
+	// wait returns how many changes were reported once the file settles
+	wait := func() int {
+		time.Sleep(watchDelay + 500*time.Millisecond)
+		return len(changes)
+	}
+	drain := func() {
+		for len(changes) > 0 {
+			<-changes
+		}
+	}
+
+	tests := []struct {
+		name    string
+		write   func() error
+		changes int
+	}{
+		{
+			name: "merges a burst of writes",
+			write: func() error {
+				for i := 0; i < 5; i++ {
+					if err := os.WriteFile(file, []byte("port: 808"+string(rune('1'+i))+"\n"), 0644); err != nil {
+						return err
+					}
+					time.Sleep(watchDelay / 5)
+				}
+				return nil
+			},
+			changes: 1,
+		},
+		{
+			name: "catches a file replaced by a rename",
+			write: func() error {
+				temp := file + ".tmp"
+				if err := os.WriteFile(temp, []byte("port: 8090\n"), 0644); err != nil {
+					return err
+				}
+				return os.Rename(temp, file)
+			},
+			changes: 1,
+		},
+		{
+			name: "ignores other files",
+			write: func() error {
+				return os.WriteFile(filepath.Join(dir, "passphrase"), []byte("correct horse\n"), 0600)
+			},
+			changes: 0,
+		},
+		{
+			name: "ignores permission changes",
+			write: func() error {
+				return os.Chmod(file, 0600)
+			},
+			changes: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			drain()
+			start := time.Now()
+			if err := tt.write(); err != nil {
+				t.Fatalf("failed to write: %v", err)
+			}
+			if changes := wait(); changes != tt.changes {
+				t.Fatalf("expected %d changes reported, got %d", tt.changes, changes)
+			}
+			if tt.changes > 0 {
+				if reported := <-changes; reported.Sub(start) < watchDelay {
+					t.Errorf("expected the change reported once the writes settled, after %s", reported.Sub(start))
+				}
+			}
+		})
+	}
+
+	// Nothing is reported once ctx is done
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+	drain()
+	if err := os.WriteFile(file, []byte("port: 8080\n"), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	if changes := wait(); changes != 0 {
+		t.Errorf("expected no change reported after the watch ended, got %d", changes)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"github.com/lhommenul/brique/pkg/config"
+)
+
+// ProfileDTO is the Data Transfer Object for a profile
+type ProfileDTO struct {
+	Name    string `json:"name"`
+	Current bool   `json:"current"`
+}
+
+// GetProfiles returns the profiles, each with its own inventory
+func (a *App) GetProfiles() ([]ProfileDTO, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	names, err := config.Profiles(a.cfg.DataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make([]ProfileDTO, len(names))
+	for i, name := range names {
+		profiles[i] = ProfileDTO{Name: name, Current: name == a.cfg.Profile}
+	}
+	return profiles, nil
+}
+
+// CreateProfile creates an empty profile, to switch to
+func (a *App) CreateProfile(name string) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if err := config.CreateProfile(a.cfg.DataDir, name); err != nil {
+		a.events.Error("Création impossible", err.Error())
+		return err
+	}
+
+	a.events.Success("Profil créé", name)
+	return nil
+}
+
+// SwitchProfile opens the inventory of another profile, kept as the one
+// opened at the next start
+func (a *App) SwitchProfile(name string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	previous := a.cfg.Profile
+	if name == previous {
+		return nil
+	}
+
+	// Check the profile before leaving the current one
+	if _, err := config.LoadProfile(name); err != nil {
+		a.events.Error("Changement de profil impossible", err.Error())
+		return err
+	}
+
+	a.stopProfile()
+	if err := a.openProfile(name); err != nil {
+		a.logger.Error("Failed to open profile", "profile", name, "error", err)
+		a.events.Error("Changement de profil impossible", err.Error())
+		if err := a.openProfile(previous); err != nil {
+			a.logger.Error("Failed to reopen profile", "profile", previous, "error", err)
+		}
+		return err
+	}
+
+	if err := config.Set(a.cfg.File(), "profile", name); err != nil {
+		a.logger.Warn("Failed to keep the profile for the next start", "error", err)
+	}
+
+	a.events.EmitProfileChanged(name)
+	a.events.Success("Profil ouvert", name)
+	return nil
+}
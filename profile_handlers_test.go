@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/lhommenul/brique/pkg/config"
+)
+
+// newTestApp opens the default profile of a temporary data directory, with
+// no frontend to notify
+func newTestApp(t *testing.T) *App {
+	t.Helper()
+
+	dataDir := t.TempDir()
+	t.Setenv("BRIQUE_DATA_DIR", dataDir)
+	content := fmt.Sprintf("database_path: %s\nassets_dir: %s\ndiscovery:\n  enabled: false\n",
+		filepath.Join(dataDir, "brique.db"), filepath.Join(dataDir, "assets"))
+	if err := os.WriteFile(filepath.Join(dataDir, "config.yaml"), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	a := &App{
+		ctx:    context.Background(),
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.openProfile(""); err != nil {
+		t.Fatalf("failed to open profile: %v", err)
+	}
+	t.Cleanup(func() { a.shutdown(a.ctx) })
+	return a
+}
+
+func TestSwitchProfileWhileInFlight(t *testing.T) {
+	a := newTestApp(t)
+	if err := a.CreateProfile("atelier"); err != nil {
+		t.Fatalf("failed to create profile: %v", err)
+	}
+	if _, err := a.CreateItem("Perceuse", "Outillage", "Bosch", "PSB 500", "", ""); err != nil {
+		t.Fatalf("failed to create item: %v", err)
+	}
+
+	// Calls keep coming from the frontend while the profile is switched
+	stop := make(chan struct{})
+	errs := make(chan error, 100)
+	var calls sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		calls.Add(1)
+		go func() {
+			defer calls.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				var err error
+				switch i {
+				case 0:
+					_, err = a.GetAllItems()
+				case 1:
+					_, err = a.GetProfiles()
+				case 2:
+					_, err = a.GetDueMaintenance(7)
+				case 3:
+					_, err = a.CreateItem("Ponceuse", "Outillage", "Makita", "BO4556", "", "")
+				}
+				if err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 10; i++ {
+		name := "atelier"
+		if i%2 == 1 {
+			name = config.DefaultProfile
+		}
+		if err := a.SwitchProfile(name); err != nil {
+			t.Fatalf("failed to switch to %s: %v", name, err)
+		}
+	}
+	close(stop)
+	calls.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("call failed while switching profiles: %v", err)
+	}
+
+	// The inventory of the profile open at last is served
+	profiles, err := a.GetProfiles()
+	if err != nil {
+		t.Fatalf("failed to get profiles: %v", err)
+	}
+	for _, profile := range profiles {
+		if profile.Current != (profile.Name == config.DefaultProfile) {
+			t.Errorf("expected the default profile open, got %+v", profiles)
+		}
+	}
+	items, err := a.GetAllItems()
+	if err != nil {
+		t.Fatalf("failed to get items: %v", err)
+	}
+	found := false
+	for _, item := range items {
+		found = found || item.Name == "Perceuse"
+	}
+	if !found {
+		t.Errorf("expected the items of the default profile, got %d items", len(items))
+	}
+}
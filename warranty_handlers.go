@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lhommenul/brique/core/models"
+)
+
+const (
+	// warrantyCheckInterval is how often expiring warranties are looked for
+	warrantyCheckInterval = 24 * time.Hour
+
+	// warrantyNoticeDays is how long before its end a warranty is notified
+	warrantyNoticeDays = 30
+)
+
+// WarrantyDTO is the Data Transfer Object for item warranties
+type WarrantyDTO struct {
+	DurationMonths int64   `json:"durationMonths"`
+	Provider       string  `json:"provider"`
+	ReceiptAssetID *int64  `json:"receiptAssetId"`
+	ExpiresAt      *string `json:"expiresAt"`
+	DaysLeft       *int64  `json:"daysLeft"`
+}
+
+// SetItemWarranty sets the warranty of an item. A zero duration removes it,
+// a zero receipt ID leaves the warranty without proof of purchase.
+func (a *App) SetItemWarranty(itemID int64, durationMonths int64, provider string, receiptAssetID int64) (*WarrantyDTO, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	warranty := &models.Warranty{DurationMonths: durationMonths, Provider: provider}
+	if receiptAssetID != 0 {
+		warranty.ReceiptAssetID = &receiptAssetID
+	}
+
+	if err := a.backpackService.SetItemWarranty(a.ctx, itemID, warranty); err != nil {
+		a.events.Error("Erreur de garantie", err.Error())
+		return nil, err
+	}
+
+	if durationMonths == 0 {
+		a.events.Success("Garantie supprimée", "La garantie de l'item a été supprimée")
+		return nil, nil
+	}
+
+	a.events.Success("Garantie enregistrée", fmt.Sprintf("Garantie de %d mois", durationMonths))
+	dto := warrantyToDTO(warranty)
+	return &dto, nil
+}
+
+// GetExpiringWarranties returns the items whose warranty expires in the next
+// days, soonest first
+func (a *App) GetExpiringWarranties(withinDays int) ([]ItemDTO, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	items, err := a.backpackService.GetExpiringWarranties(a.ctx, time.Duration(withinDays)*24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	dtos := make([]ItemDTO, len(items))
+	for i := range items {
+		dtos[i] = itemToDTO(&items[i])
+	}
+	return dtos, nil
+}
+
+// watchWarranties notifies the frontend when warranties are about to expire,
+// until the context is cancelled
+func (a *App) watchWarranties(ctx context.Context) {
+	ticker := time.NewTicker(warrantyCheckInterval)
+	defer ticker.Stop()
+
+	a.checkWarranties(ctx)
+
+	for {
+		select {
+		case <-ticker.C:
+			a.checkWarranties(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// checkWarranties emits a warning for each warranty expiring soon, once per
+// run of the application
+func (a *App) checkWarranties(ctx context.Context) {
+	items, err := a.backpackService.GetExpiringWarranties(ctx, warrantyNoticeDays*24*time.Hour)
+	if err != nil {
+		a.logger.Warn("Failed to check warranties", "error", err)
+		return
+	}
+
+	for _, item := range items {
+		if _, notified := a.notifiedWarranties.LoadOrStore(item.ID, true); notified {
+			continue
+		}
+		a.events.Warning("Fin de garantie", fmt.Sprintf("La garantie de %s expire le %s", item.Name, item.Warranty.ExpiresAt.Format("02/01/2006")))
+	}
+}
+
+func warrantyToDTO(warranty *models.Warranty) WarrantyDTO {
+	dto := WarrantyDTO{
+		DurationMonths: warranty.DurationMonths,
+		Provider:       warranty.Provider,
+		ReceiptAssetID: warranty.ReceiptAssetID,
+		DaysLeft:       warranty.DaysLeft,
+	}
+
+	if warranty.ExpiresAt != nil {
+		expiresAt := warranty.ExpiresAt.Format("2006-01-02")
+		dto.ExpiresAt = &expiresAt
+	}
+
+	return dto
+}